@@ -0,0 +1,195 @@
+// Package audit records security-relevant actions (such as promoting a user to site admin or
+// changing the site configuration) in the append-only audit log.
+package audit
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
+	"github.com/sourcegraph/sourcegraph/internal/actor"
+	"github.com/sourcegraph/sourcegraph/internal/db/dbutil"
+	"github.com/sourcegraph/sourcegraph/internal/jsonc"
+)
+
+type clientKey struct{}
+
+// client is the address of the client that made a request.
+type client struct {
+	ip           string
+	forwardedFor string
+}
+
+// Middleware records the address of the client that made the request, so that audit log entries
+// written while handling the request include it.
+func Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c := client{ip: r.RemoteAddr, forwardedFor: r.Header.Get("X-Forwarded-For")}
+		if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+			c.ip = host
+		}
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), clientKey{}, c)))
+	})
+}
+
+// Event describes an action to record in the audit log.
+type Event struct {
+	Action     string // one of the db.AuditLogAction* values
+	TargetType string // the type of the object that the action was performed on (e.g., "User")
+	TargetID   string // the ID of the object that the action was performed on
+
+	// Before and After describe the target before and after the action. They are marshaled to
+	// JSON and must not contain secrets. Either may be nil.
+	Before, After interface{}
+}
+
+// Transact calls f, which performs an action of the current actor using tx, in a transaction and
+// records the event that f returns in the audit log in the same transaction. This ensures that no
+// action is committed without being recorded. If f returns a nil event, the action is committed
+// without recording it.
+func Transact(ctx context.Context, f func(tx *sql.Tx) (*Event, error)) error {
+	return db.AuditLog.Transact(ctx, entryFunc(ctx, f))
+}
+
+// TransactOn is like Transact, but uses the given DB handle instead of the global DB handle.
+func TransactOn(ctx context.Context, dbh dbutil.DB, f func(tx *sql.Tx) (*Event, error)) error {
+	return db.AuditLog.TransactOn(ctx, dbh, entryFunc(ctx, f))
+}
+
+func entryFunc(ctx context.Context, f func(tx *sql.Tx) (*Event, error)) func(tx *sql.Tx) (*db.AuditLogEntry, error) {
+	return func(tx *sql.Tx) (*db.AuditLogEntry, error) {
+		e, err := f(tx)
+		if err != nil || e == nil {
+			return nil, err
+		}
+		return newEntry(ctx, *e)
+	}
+}
+
+// newEntry returns the audit log entry that records the event.
+func newEntry(ctx context.Context, e Event) (*db.AuditLogEntry, error) {
+	c, _ := ctx.Value(clientKey{}).(client)
+	entry := &db.AuditLogEntry{
+		ActorUserID:  actor.FromContext(ctx).UID,
+		ActorIP:      c.ip,
+		ForwardedFor: c.forwardedFor,
+		Action:       e.Action,
+		TargetType:   e.TargetType,
+		TargetID:     e.TargetID,
+	}
+
+	var err error
+	if entry.Before, err = marshal(e.Before); err != nil {
+		return nil, errors.Wrap(err, "marshal audit log entry")
+	}
+	if entry.After, err = marshal(e.After); err != nil {
+		return nil, errors.Wrap(err, "marshal audit log entry")
+	}
+	return entry, nil
+}
+
+type siteConfigurationChangeKey struct{}
+
+// SiteConfigurationChange describes a change of the site configuration that the current actor
+// makes.
+type SiteConfigurationChange struct {
+	RolledBackTo *int32 // the ID of the revision that the configuration is rolled back to, if any
+}
+
+// WithSiteConfigurationChange returns a copy of the context indicating that writes of the site
+// configuration with it are the given change, which must be recorded in the audit log. The
+// configuration source records it (see SiteConfigurationEvent) in the same transaction in which
+// it saves the configuration.
+func WithSiteConfigurationChange(ctx context.Context, c SiteConfigurationChange) context.Context {
+	return context.WithValue(ctx, siteConfigurationChangeKey{}, c)
+}
+
+// SiteConfigurationEvent returns the event that records the change of the site configuration from
+// the revision with ID before to the revision with ID after, or nil if the context does not
+// indicate a change to record (see WithSiteConfigurationChange). The event refers to the revisions
+// instead of including their contents, which contain secrets.
+func SiteConfigurationEvent(ctx context.Context, before, after int32) *Event {
+	c, ok := ctx.Value(siteConfigurationChangeKey{}).(SiteConfigurationChange)
+	if !ok {
+		return nil
+	}
+
+	type revision struct {
+		ID           int32  `json:"id"`
+		RolledBackTo *int32 `json:"rolledBackTo,omitempty"`
+	}
+	return &Event{
+		Action:     db.AuditLogActionUpdateSiteConfiguration,
+		TargetType: "SiteConfiguration",
+		TargetID:   "site", // the GraphQL ID of the site
+		Before:     revision{ID: before},
+		After:      revision{ID: after, RolledBackTo: c.RolledBackTo},
+	}
+}
+
+func marshal(v interface{}) (json.RawMessage, error) {
+	if v == nil {
+		return nil, nil
+	}
+	return json.Marshal(v)
+}
+
+// redacted replaces secrets in values recorded in the audit log.
+const redacted = "REDACTED"
+
+// RedactedConfig returns the JSONC configuration (such as an external service's configuration)
+// with the values of properties that hold secrets (such as tokens and passwords) and the passwords
+// in URLs replaced, so that it can be recorded in the audit log. It returns nil if the
+// configuration is invalid.
+func RedactedConfig(config string) interface{} {
+	var v interface{}
+	if err := jsonc.Unmarshal(config, &v); err != nil {
+		return nil
+	}
+	return redact(v)
+}
+
+func redact(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for k, vv := range v {
+			if _, ok := vv.(string); ok && isSecretProperty(k) {
+				v[k] = redacted
+			} else {
+				v[k] = redact(vv)
+			}
+		}
+		return v
+	case []interface{}:
+		for i, vv := range v {
+			v[i] = redact(vv)
+		}
+		return v
+	case string:
+		if u, err := url.Parse(v); err == nil && u.User != nil {
+			if _, ok := u.User.Password(); ok {
+				u.User = url.UserPassword(u.User.Username(), redacted)
+				return u.String()
+			}
+		}
+		return v
+	default:
+		return v
+	}
+}
+
+func isSecretProperty(name string) bool {
+	name = strings.ToLower(name)
+	for _, s := range []string{"token", "password", "secret", "key"} {
+		if strings.Contains(name, s) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,37 @@
+package auth
+
+import "context"
+
+// PasswordAuthenticator authenticates users who sign in with the username-password sign-in form
+// against an external directory (such as an LDAP server) instead of the passwords stored by the
+// builtin auth provider.
+type PasswordAuthenticator interface {
+	// Enabled reports whether the authenticator is enabled in site config.
+	Enabled() bool
+
+	// Authenticate returns the ID of the user with the given login (username or email address) and
+	// password, creating the user if needed. If the directory does not recognize the credentials,
+	// it returns a zero user ID and a nil error. Otherwise, if it returns an error, safeErrMsg is a
+	// message that is safe to display to the user.
+	Authenticate(ctx context.Context, login, password string) (userID int32, safeErrMsg string, err error)
+}
+
+var passwordAuthenticators []PasswordAuthenticator
+
+// RegisterPasswordAuthenticators registers additional password authenticators. Currently this is
+// used to register the enterprise-only LDAP auth provider. This should only be called from an init
+// function.
+func RegisterPasswordAuthenticators(a ...PasswordAuthenticator) {
+	passwordAuthenticators = append(passwordAuthenticators, a...)
+}
+
+// PasswordAuthenticators returns the registered password authenticators that are enabled.
+func PasswordAuthenticators() []PasswordAuthenticator {
+	var enabled []PasswordAuthenticator
+	for _, a := range passwordAuthenticators {
+		if a.Enabled() {
+			enabled = append(enabled, a)
+		}
+	}
+	return enabled
+}
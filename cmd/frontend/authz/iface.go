@@ -4,11 +4,17 @@ package authz
 
 import (
 	"context"
+	"errors"
 
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
 	"github.com/sourcegraph/sourcegraph/internal/extsvc"
 )
 
+// ErrUnimplemented is returned by a Provider whose code host cannot answer the
+// request, e.g. FetchRepoPerms of a code host without an API to list the users
+// who have access to a repository.
+var ErrUnimplemented = errors.New("authz provider: unimplemented")
+
 // Provider defines a source of truth of which repositories a user is authorized to view. The
 // user is identified by an extsvc.Account instance. Examples of authz providers include the
 // following:
@@ -73,6 +79,9 @@ type Provider interface {
 	// Because permissions fetching APIs are often expensive, the implementation should
 	// try to return partial but valid results in case of error, and it is up to callers
 	// to decide whether to discard.
+	//
+	// Implementations that cannot list the users of a repository return ErrUnimplemented,
+	// in which case permissions are only synced from the users' side.
 	FetchRepoPerms(ctx context.Context, repo *extsvc.Repository) ([]extsvc.AccountID, error)
 
 	// ServiceType returns the service type (e.g., "gitlab") of this authz provider.
@@ -0,0 +1,133 @@
+package authz
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+
+	"github.com/sourcegraph/sourcegraph/internal/api"
+)
+
+// TokenRestrictions describes the restrictions of a request authenticated with an access token that
+// does not grant full control of its subject user's account, i.e. an access token without the
+// "user:all" scope.
+type TokenRestrictions struct {
+	// Scopes are the scopes of the access token.
+	Scopes []string
+	// RepositoryPatterns are regular expressions matching the names of the repositories the access
+	// token may be used with. If empty, the access token may be used with all repositories.
+	RepositoryPatterns []string
+
+	repositoryPatterns []*regexp.Regexp
+}
+
+// NewTokenRestrictions returns the restrictions of an access token with the given scopes and
+// repository patterns.
+func NewTokenRestrictions(scopes, repositoryPatterns []string) (*TokenRestrictions, error) {
+	r := &TokenRestrictions{Scopes: scopes, RepositoryPatterns: repositoryPatterns}
+	for _, pattern := range repositoryPatterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid repository pattern %q: %s", pattern, err)
+		}
+		r.repositoryPatterns = append(r.repositoryPatterns, re)
+	}
+	return r, nil
+}
+
+// HasScope returns true if the restricted access token has the given scope.
+func (r *TokenRestrictions) HasScope(scope string) bool {
+	for _, s := range r.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// AllowsRepo returns true if the restricted access token may be used with the given repository.
+func (r *TokenRestrictions) AllowsRepo(name api.RepoName) bool {
+	if len(r.repositoryPatterns) == 0 {
+		return true
+	}
+	for _, re := range r.repositoryPatterns {
+		if re.MatchString(string(name)) {
+			return true
+		}
+	}
+	return false
+}
+
+type tokenRestrictionsKey struct{}
+
+// WithTokenRestrictions returns a copy of the context with the given access token restrictions.
+func WithTokenRestrictions(ctx context.Context, r *TokenRestrictions) context.Context {
+	return context.WithValue(ctx, tokenRestrictionsKey{}, r)
+}
+
+// TokenRestrictionsFromContext returns the access token restrictions of the request, or nil if the
+// request is not restricted (e.g. it was authenticated with a session cookie or with an access
+// token with the "user:all" scope).
+func TokenRestrictionsFromContext(ctx context.Context) *TokenRestrictions {
+	r, _ := ctx.Value(tokenRestrictionsKey{}).(*TokenRestrictions)
+	return r
+}
+
+// ErrTokenScope is returned when the access token of a request does not permit an action.
+type ErrTokenScope struct {
+	Scope string
+}
+
+func (e *ErrTokenScope) Error() string {
+	return fmt.Sprintf("access token does not have the required scope %q", e.Scope)
+}
+
+// ErrTokenRepo is returned when the access token of a request may not be used with a repository.
+type ErrTokenRepo struct {
+	Repo api.RepoName
+}
+
+func (e *ErrTokenRepo) Error() string {
+	return fmt.Sprintf("access token may not be used with repository %q", e.Repo)
+}
+
+// ErrTokenRepos is returned when an access token that is restricted to certain repositories is used
+// for an action that is not limited to those repositories.
+type ErrTokenRepos struct{}
+
+func (e *ErrTokenRepos) Error() string {
+	return "access token is restricted to certain repositories and may not be used for this action"
+}
+
+// CheckTokenScope returns an error if the request is restricted by an access token without the given
+// scope.
+//
+// 🚨 SECURITY: Handlers that can be used with restricted access tokens must call this (or ensure it
+// is called) before performing any action.
+func CheckTokenScope(ctx context.Context, scope string) error {
+	if r := TokenRestrictionsFromContext(ctx); r != nil && !r.HasScope(scope) {
+		return &ErrTokenScope{Scope: scope}
+	}
+	return nil
+}
+
+// CheckTokenRepo returns an error if the request is restricted by an access token that may not be
+// used with the given repository.
+func CheckTokenRepo(ctx context.Context, name api.RepoName) error {
+	if r := TokenRestrictionsFromContext(ctx); r != nil && !r.AllowsRepo(name) {
+		return &ErrTokenRepo{Repo: name}
+	}
+	return nil
+}
+
+// CheckTokenAllRepos returns an error if the request is restricted by an access token that may only
+// be used with certain repositories.
+//
+// 🚨 SECURITY: Handlers that return data of any repository without checking each repository with
+// CheckTokenRepo must call this.
+func CheckTokenAllRepos(ctx context.Context) error {
+	if r := TokenRestrictionsFromContext(ctx); r != nil && len(r.repositoryPatterns) > 0 {
+		return &ErrTokenRepos{}
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+package authz
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sourcegraph/sourcegraph/internal/api"
+)
+
+func TestTokenRestrictions(t *testing.T) {
+	if _, err := NewTokenRestrictions([]string{ScopeSearchRead}, []string{"("}); err == nil {
+		t.Error("want error for invalid repository pattern")
+	}
+
+	r, err := NewTokenRestrictions([]string{ScopeSearchRead}, []string{"^github\\.com/foo/", "^gitlab\\.com/bar$"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := WithTokenRestrictions(context.Background(), r)
+
+	if err := CheckTokenScope(ctx, ScopeSearchRead); err != nil {
+		t.Errorf("unexpected error for permitted scope: %s", err)
+	}
+	if err := CheckTokenScope(ctx, ScopeUserAll); err == nil {
+		t.Error("want error for missing scope")
+	}
+
+	for name, want := range map[api.RepoName]bool{
+		"github.com/foo/baz": true,
+		"gitlab.com/bar":     true,
+		"gitlab.com/bar/baz": false,
+		"github.com/qux/foo": false,
+	} {
+		if err := CheckTokenRepo(ctx, name); (err == nil) != want {
+			t.Errorf("%s: got error %v, want allowed=%v", name, err, want)
+		}
+	}
+
+	if err := CheckTokenAllRepos(ctx); err == nil {
+		t.Error("want error for token restricted to certain repositories")
+	}
+	unrestricted, err := NewTokenRestrictions([]string{ScopeSearchRead}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := CheckTokenAllRepos(WithTokenRestrictions(context.Background(), unrestricted)); err != nil {
+		t.Errorf("unexpected error for token without repository restrictions: %s", err)
+	}
+
+	// Unrestricted requests are permitted.
+	if err := CheckTokenAllRepos(context.Background()); err != nil {
+		t.Errorf("unexpected error for unrestricted request: %s", err)
+	}
+	if err := CheckTokenScope(context.Background(), ScopeUserAll); err != nil {
+		t.Errorf("unexpected error for unrestricted request: %s", err)
+	}
+	if err := CheckTokenRepo(context.Background(), "github.com/qux/foo"); err != nil {
+		t.Errorf("unexpected error for unrestricted request: %s", err)
+	}
+}
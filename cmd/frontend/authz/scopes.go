@@ -2,12 +2,27 @@ package authz
 
 const (
 	// Access token scopes.
-	ScopeUserAll       = "user:all"        // Full control of all resources accessible to the user account.
-	ScopeSiteAdminSudo = "site-admin:sudo" // Ability to perform any action as any other user.
+	ScopeUserAll        = "user:all"        // Full control of all resources accessible to the user account.
+	ScopeSiteAdminSudo  = "site-admin:sudo" // Ability to perform any action as any other user.
+	ScopeSearchRead     = "search:read"     // Read-only access to search and repository contents.
+	ScopeLSIFUpload     = "lsif:upload"     // Ability to upload LSIF data.
+	ScopeCampaignsWrite = "campaigns:write" // Ability to view, create and update campaigns.
 )
 
 // AllScopes is a list of all known access token scopes.
 var AllScopes = []string{
 	ScopeUserAll,
 	ScopeSiteAdminSudo,
+	ScopeSearchRead,
+	ScopeLSIFUpload,
+	ScopeCampaignsWrite,
+}
+
+// SubjectScopes is a list of the access token scopes that grant (some of) the privileges of the
+// access token's subject user. An access token must have at least one of these scopes.
+var SubjectScopes = []string{
+	ScopeUserAll,
+	ScopeSearchRead,
+	ScopeLSIFUpload,
+	ScopeCampaignsWrite,
 }
@@ -24,6 +24,28 @@ type AccessToken struct {
 	CreatorUserID int32
 	CreatedAt     time.Time
 	LastUsedAt    *time.Time
+	AccessTokenRestrictions
+}
+
+// AccessTokenRestrictions describes the optional restrictions of an access token in addition to
+// its scopes.
+type AccessTokenRestrictions struct {
+	// ExpiresAt is the time after which the access token is no longer valid. If nil, the access
+	// token does not expire.
+	ExpiresAt *time.Time
+	// RepositoryPatterns are regular expressions matching the names of the repositories the access
+	// token may be used with. If empty, the access token may be used with all repositories.
+	RepositoryPatterns []string
+}
+
+// HasScope returns true if the access token has the given scope.
+func (t *AccessToken) HasScope(scope string) bool {
+	for _, s := range t.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
 }
 
 // ErrAccessTokenNotFound occurs when a database operation expects a specific access token to exist
@@ -46,11 +68,15 @@ type accessTokens struct{}
 // space; also bcrypt is slow and would add noticeable latency to each request that supplied a
 // token.
 //
+// The provided dbh is used as the DB handle to execute the query (if nil, the global DB handle is
+// used).
+//
 // 🚨 SECURITY: The caller must ensure that the actor is permitted to create tokens for the
-// specified user (i.e., that the actor is either the user or a site admin).
-func (s *accessTokens) Create(ctx context.Context, subjectUserID int32, scopes []string, note string, creatorUserID int32) (id int64, token string, err error) {
+// specified user (i.e., that the actor is either the user or a site admin), and that the
+// restrictions are valid for the scopes.
+func (s *accessTokens) Create(ctx context.Context, dbh Handle, subjectUserID int32, scopes []string, note string, creatorUserID int32, restrictions AccessTokenRestrictions) (id int64, token string, err error) {
 	if Mocks.AccessTokens.Create != nil {
-		return Mocks.AccessTokens.Create(subjectUserID, scopes, note, creatorUserID)
+		return Mocks.AccessTokens.Create(subjectUserID, scopes, note, creatorUserID, restrictions)
 	}
 
 	var b [20]byte
@@ -65,7 +91,7 @@ func (s *accessTokens) Create(ctx context.Context, subjectUserID int32, scopes [
 		return 0, "", errors.New("access tokens without scopes are not supported")
 	}
 
-	if err := dbconn.Global.QueryRowContext(ctx,
+	if err := orGlobal(dbh).QueryRowContext(ctx,
 		// Include users table query (with "FOR UPDATE") to ensure that subject/creator users have
 		// not been deleted. If they were deleted, the query will return an error.
 		`
@@ -76,39 +102,46 @@ creator_user AS (
   SELECT id FROM users WHERE id=$5 AND deleted_at IS NULL FOR UPDATE
 ),
 insert_values AS (
-  SELECT subject_user.id AS subject_user_id, $2::text[] AS scopes, $3::bytea AS value_sha256, $4::text AS note, creator_user.id AS creator_user_id
+  SELECT subject_user.id AS subject_user_id, $2::text[] AS scopes, $3::bytea AS value_sha256, $4::text AS note, creator_user.id AS creator_user_id, $6::timestamptz AS expires_at, $7::text[] AS repository_patterns
   FROM subject_user, creator_user
 )
-INSERT INTO access_tokens(subject_user_id, scopes, value_sha256, note, creator_user_id) SELECT * FROM insert_values RETURNING id
+INSERT INTO access_tokens(subject_user_id, scopes, value_sha256, note, creator_user_id, expires_at, repository_patterns) SELECT * FROM insert_values RETURNING id
 `,
-		subjectUserID, pq.Array(scopes), toSHA256Bytes(b[:]), note, creatorUserID,
+		subjectUserID, pq.Array(scopes), toSHA256Bytes(b[:]), note, creatorUserID, restrictions.ExpiresAt, pq.Array(nonNilStrings(restrictions.RepositoryPatterns)),
 	).Scan(&id); err != nil {
 		return 0, "", err
 	}
 	return id, token, nil
 }
 
-// Lookup looks up the access token. If it's valid and contains the required scope, it returns the
-// subject's user ID. Otherwise ErrAccessTokenNotFound is returned.
+// Lookup looks up the access token. If it's valid, has not expired, and contains at least one of
+// the required scopes, it returns the access token. Otherwise ErrAccessTokenNotFound is returned.
+// The caller is responsible for enforcing the scopes and restrictions of the returned access token.
 //
 // Calling Lookup also updates the access token's last-used-at date.
 //
-// 🚨 SECURITY: This returns a user ID if and only if the tokenHexEncoded corresponds to a valid,
-// non-deleted access token.
-func (s *accessTokens) Lookup(ctx context.Context, tokenHexEncoded string, requiredScope string) (subjectUserID int32, err error) {
+// 🚨 SECURITY: This returns an access token if and only if the tokenHexEncoded corresponds to a
+// valid, non-deleted, non-expired access token.
+func (s *accessTokens) Lookup(ctx context.Context, tokenHexEncoded string, requiredScopes ...string) (*AccessToken, error) {
 	if Mocks.AccessTokens.Lookup != nil {
-		return Mocks.AccessTokens.Lookup(tokenHexEncoded, requiredScope)
+		return Mocks.AccessTokens.Lookup(tokenHexEncoded, requiredScopes)
 	}
 
-	if requiredScope == "" {
-		return 0, errors.New("no scope provided in access token lookup")
+	if len(requiredScopes) == 0 {
+		return nil, errors.New("no scope provided in access token lookup")
+	}
+	for _, scope := range requiredScopes {
+		if scope == "" {
+			return nil, errors.New("empty scope provided in access token lookup")
+		}
 	}
 
 	token, err := hex.DecodeString(tokenHexEncoded)
 	if err != nil {
-		return 0, errors.Wrap(err, "AccessTokens.Lookup")
+		return nil, errors.Wrap(err, "AccessTokens.Lookup")
 	}
 
+	var t AccessToken
 	if err := dbconn.Global.QueryRowContext(ctx,
 		// Ensure that subject and creator users still exist.
 		`
@@ -118,18 +151,18 @@ WHERE t.id IN (
 	JOIN users subject_user ON t2.subject_user_id=subject_user.id AND subject_user.deleted_at IS NULL
 	JOIN users creator_user ON t2.creator_user_id=creator_user.id AND creator_user.deleted_at IS NULL
 	WHERE t2.value_sha256=$1 AND t2.deleted_at IS NULL AND
-	$2 = ANY (t2.scopes)
+	(t2.expires_at IS NULL OR t2.expires_at > now()) AND
+	$2 && t2.scopes
 )
-RETURNING t.subject_user_id
-`,
-		toSHA256Bytes(token), requiredScope,
-	).Scan(&subjectUserID); err != nil {
+RETURNING `+accessTokenColumns,
+		toSHA256Bytes(token), pq.Array(requiredScopes),
+	).Scan(accessTokenScanArgs(&t)...); err != nil {
 		if err == sql.ErrNoRows {
-			return 0, ErrAccessTokenNotFound
+			return nil, ErrAccessTokenNotFound
 		}
-		return 0, err
+		return nil, err
 	}
-	return subjectUserID, nil
+	return &t, nil
 }
 
 // GetByID retrieves the access token (if any) given its ID.
@@ -169,6 +202,7 @@ func (o AccessTokensListOptions) sqlConditions() []*sqlf.Query {
 	if o.LastUsedBefore != nil {
 		conds = append(conds, sqlf.Sprintf("last_used_at<%d", o.LastUsedBefore))
 	}
+
 	return conds
 }
 
@@ -182,7 +216,7 @@ func (s *accessTokens) List(ctx context.Context, opt AccessTokensListOptions) ([
 
 func (s *accessTokens) list(ctx context.Context, conds []*sqlf.Query, limitOffset *LimitOffset) ([]*AccessToken, error) {
 	q := sqlf.Sprintf(`
-SELECT id, subject_user_id, scopes, note, creator_user_id, created_at, last_used_at FROM access_tokens
+SELECT `+accessTokenColumns+` FROM access_tokens
 WHERE (%s)
 ORDER BY now() - created_at < interval '5 minutes' DESC, -- show recently created tokens first
 last_used_at DESC NULLS FIRST, -- ensure newly created tokens show first
@@ -201,7 +235,7 @@ created_at DESC
 	var results []*AccessToken
 	for rows.Next() {
 		var t AccessToken
-		if err := rows.Scan(&t.ID, &t.SubjectUserID, pq.Array(&t.Scopes), &t.Note, &t.CreatorUserID, &t.CreatedAt, &t.LastUsedAt); err != nil {
+		if err := rows.Scan(accessTokenScanArgs(&t)...); err != nil {
 			return nil, err
 		}
 		results = append(results, &t)
@@ -259,14 +293,31 @@ func (s *accessTokens) delete(ctx context.Context, cond *sqlf.Query) error {
 	return nil
 }
 
+// accessTokenColumns are the columns of the access_tokens table read into AccessToken values by
+// accessTokenScanArgs.
+const accessTokenColumns = "id, subject_user_id, scopes, note, creator_user_id, created_at, last_used_at, expires_at, repository_patterns"
+
+func accessTokenScanArgs(t *AccessToken) []interface{} {
+	return []interface{}{&t.ID, &t.SubjectUserID, pq.Array(&t.Scopes), &t.Note, &t.CreatorUserID, &t.CreatedAt, &t.LastUsedAt, &t.ExpiresAt, pq.Array(&t.RepositoryPatterns)}
+}
+
+// nonNilStrings returns the given slice, or an empty slice if it is nil, so that it is stored
+// as an empty array instead of NULL.
+func nonNilStrings(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+	return values
+}
+
 func toSHA256Bytes(input []byte) []byte {
 	b := sha256.Sum256(input)
 	return b[:]
 }
 
 type MockAccessTokens struct {
-	Create     func(subjectUserID int32, scopes []string, note string, creatorUserID int32) (id int64, token string, err error)
+	Create     func(subjectUserID int32, scopes []string, note string, creatorUserID int32, restrictions AccessTokenRestrictions) (id int64, token string, err error)
 	DeleteByID func(id int64, subjectUserID int32) error
-	Lookup     func(tokenHexEncoded, requiredScope string) (subjectUserID int32, err error)
+	Lookup     func(tokenHexEncoded string, requiredScopes []string) (*AccessToken, error)
 	GetByID    func(id int64) (*AccessToken, error)
 }
@@ -4,6 +4,7 @@ import (
 	"context"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/sourcegraph/sourcegraph/internal/db/dbtesting"
 )
@@ -34,7 +35,7 @@ func TestAccessTokens_Create(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	tid0, tv0, err := AccessTokens.Create(ctx, subject.ID, []string{"a", "b"}, "n0", creator.ID)
+	tid0, tv0, err := AccessTokens.Create(ctx, nil, subject.ID, []string{"a", "b"}, "n0", creator.ID, AccessTokenRestrictions{})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -53,12 +54,12 @@ func TestAccessTokens_Create(t *testing.T) {
 		t.Errorf("got %q, want %q", got.Note, want)
 	}
 
-	gotSubjectUserID, err := AccessTokens.Lookup(ctx, tv0, "a")
+	gotToken, err := AccessTokens.Lookup(ctx, tv0, "a")
 	if err != nil {
 		t.Fatal(err)
 	}
-	if want := subject.ID; gotSubjectUserID != want {
-		t.Errorf("got %v, want %v", gotSubjectUserID, want)
+	if want := subject.ID; gotToken.SubjectUserID != want {
+		t.Errorf("got %v, want %v", gotToken.SubjectUserID, want)
 	}
 
 	ts, err := AccessTokens.List(ctx, AccessTokensListOptions{SubjectUserID: subject.ID})
@@ -108,11 +109,11 @@ func TestAccessTokens_List(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	_, _, err = AccessTokens.Create(ctx, subject1.ID, []string{"a", "b"}, "n0", subject1.ID)
+	_, _, err = AccessTokens.Create(ctx, nil, subject1.ID, []string{"a", "b"}, "n0", subject1.ID, AccessTokenRestrictions{})
 	if err != nil {
 		t.Fatal(err)
 	}
-	_, _, err = AccessTokens.Create(ctx, subject1.ID, []string{"a", "b"}, "n1", subject1.ID)
+	_, _, err = AccessTokens.Create(ctx, nil, subject1.ID, []string{"a", "b"}, "n1", subject1.ID, AccessTokenRestrictions{})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -187,21 +188,31 @@ func TestAccessTokens_Lookup(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	tid0, tv0, err := AccessTokens.Create(ctx, subject.ID, []string{"a", "b"}, "n0", creator.ID)
+	tid0, tv0, err := AccessTokens.Create(ctx, nil, subject.ID, []string{"a", "b"}, "n0", creator.ID, AccessTokenRestrictions{})
 	if err != nil {
 		t.Fatal(err)
 	}
 
 	for _, scope := range []string{"a", "b"} {
-		gotSubjectUserID, err := AccessTokens.Lookup(ctx, tv0, scope)
+		gotToken, err := AccessTokens.Lookup(ctx, tv0, scope)
 		if err != nil {
 			t.Fatal(err)
 		}
-		if want := subject.ID; gotSubjectUserID != want {
-			t.Errorf("got %v, want %v", gotSubjectUserID, want)
+		if want := subject.ID; gotToken.SubjectUserID != want {
+			t.Errorf("got %v, want %v", gotToken.SubjectUserID, want)
 		}
 	}
 
+	// Lookup with any one of several scopes.
+	if _, err := AccessTokens.Lookup(ctx, tv0, "x", "b"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Lookup with no scopes and ensure it fails.
+	if _, err := AccessTokens.Lookup(ctx, tv0); err == nil {
+		t.Fatal(err)
+	}
+
 	// Lookup with a nonexistent scope and ensure it fails.
 	if _, err := AccessTokens.Lookup(ctx, tv0, "x"); err == nil {
 		t.Fatal(err)
@@ -226,6 +237,52 @@ func TestAccessTokens_Lookup(t *testing.T) {
 	}
 }
 
+// 🚨 SECURITY: This tests that expired access tokens are invalid and that the restrictions of an
+// access token are returned by Lookup.
+func TestAccessTokens_Lookup_restrictions(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+	dbtesting.SetupGlobalTestDB(t)
+	ctx := context.Background()
+
+	subject, err := Users.Create(ctx, NewUser{
+		Email:                 "u1@example.com",
+		Username:              "u1",
+		Password:              "p1",
+		EmailVerificationCode: "c1",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	future := time.Now().Add(time.Hour)
+	restrictions := AccessTokenRestrictions{ExpiresAt: &future, RepositoryPatterns: []string{"^github\\.com/foo/"}}
+	_, tv0, err := AccessTokens.Create(ctx, nil, subject.ID, []string{"a"}, "n0", subject.ID, restrictions)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gotToken, err := AccessTokens.Lookup(ctx, tv0, "a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gotToken.ExpiresAt == nil || !gotToken.ExpiresAt.Equal(future.Truncate(time.Microsecond)) {
+		t.Errorf("got expiry %v, want %v", gotToken.ExpiresAt, future)
+	}
+	if want := restrictions.RepositoryPatterns; !reflect.DeepEqual(gotToken.RepositoryPatterns, want) {
+		t.Errorf("got repository patterns %q, want %q", gotToken.RepositoryPatterns, want)
+	}
+
+	past := time.Now().Add(-time.Hour)
+	_, tv1, err := AccessTokens.Create(ctx, nil, subject.ID, []string{"a"}, "n1", subject.ID, AccessTokenRestrictions{ExpiresAt: &past})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := AccessTokens.Lookup(ctx, tv1, "a"); err != ErrAccessTokenNotFound {
+		t.Fatalf("got error %v, want %v", err, ErrAccessTokenNotFound)
+	}
+}
+
 // 🚨 SECURITY: This tests that deleting the subject or creator user of an access token invalidates
 // the token, and that no new access tokens may be created for deleted users.
 func TestAccessTokens_Lookup_deletedUser(t *testing.T) {
@@ -255,7 +312,7 @@ func TestAccessTokens_Lookup_deletedUser(t *testing.T) {
 			t.Fatal(err)
 		}
 
-		_, tv0, err := AccessTokens.Create(ctx, subject.ID, []string{"a"}, "n0", creator.ID)
+		_, tv0, err := AccessTokens.Create(ctx, nil, subject.ID, []string{"a"}, "n0", creator.ID, AccessTokenRestrictions{})
 		if err != nil {
 			t.Fatal(err)
 		}
@@ -266,7 +323,7 @@ func TestAccessTokens_Lookup_deletedUser(t *testing.T) {
 			t.Fatal("Lookup: want error looking up token for deleted subject user")
 		}
 
-		if _, _, err := AccessTokens.Create(ctx, subject.ID, nil, "n0", creator.ID); err == nil {
+		if _, _, err := AccessTokens.Create(ctx, nil, subject.ID, nil, "n0", creator.ID, AccessTokenRestrictions{}); err == nil {
 			t.Fatal("Create: want error creating token for deleted subject user")
 		}
 	})
@@ -291,7 +348,7 @@ func TestAccessTokens_Lookup_deletedUser(t *testing.T) {
 			t.Fatal(err)
 		}
 
-		_, tv0, err := AccessTokens.Create(ctx, subject.ID, []string{"a"}, "n0", creator.ID)
+		_, tv0, err := AccessTokens.Create(ctx, nil, subject.ID, []string{"a"}, "n0", creator.ID, AccessTokenRestrictions{})
 		if err != nil {
 			t.Fatal(err)
 		}
@@ -302,7 +359,7 @@ func TestAccessTokens_Lookup_deletedUser(t *testing.T) {
 			t.Fatal("Lookup: want error looking up token for deleted creator user")
 		}
 
-		if _, _, err := AccessTokens.Create(ctx, subject.ID, nil, "n0", creator.ID); err == nil {
+		if _, _, err := AccessTokens.Create(ctx, nil, subject.ID, nil, "n0", creator.ID, AccessTokenRestrictions{}); err == nil {
 			t.Fatal("Create: want error creating token for deleted creator user")
 		}
 	})
@@ -0,0 +1,322 @@
+package db
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/keegancsmith/sqlf"
+	"github.com/pkg/errors"
+	"github.com/sourcegraph/sourcegraph/internal/db/dbconn"
+	"github.com/sourcegraph/sourcegraph/internal/db/dbutil"
+	"github.com/sourcegraph/sourcegraph/internal/env"
+)
+
+// AuditLogEntry is an entry in the audit log of security-relevant actions.
+type AuditLogEntry struct {
+	ID           int64
+	ActorUserID  int32  // the user who performed the action
+	ActorIP      string // the IP address of the client that made the request
+	ForwardedFor string // the X-Forwarded-For header of the request, if any
+	Action       string // one of the AuditLogAction* values
+	TargetType   string // the type of the object that the action was performed on (e.g., "User")
+	TargetID     string // the ID of the object that the action was performed on
+	Before       json.RawMessage
+	After        json.RawMessage
+	CreatedAt    time.Time
+	PrevHash     string // the hash of the entry before this one, or "" for the first entry
+	Hash         string // the hash of this entry's contents and PrevHash
+}
+
+// auditLogHMACKey is the key of the audit log entries' hashes. It is kept outside the database, so
+// that someone who can write to the database cannot rewrite entries and compute their hashes again.
+var auditLogHMACKey = []byte(env.Get("AUDIT_LOG_HMAC_KEY", "", "secret key of the audit log's hash chain (the audit log is only tamper-evident if it is set, and it must not change)"))
+
+// AuditLogKeyed reports whether the audit log's hashes are keyed with a secret (AUDIT_LOG_HMAC_KEY).
+// If not, anyone who can write to the database can change entries and compute their hashes again.
+func AuditLogKeyed() bool { return len(auditLogHMACKey) > 0 }
+
+// The actions recorded in the audit log.
+const (
+	AuditLogActionSetSiteAdmin             = "SET_SITE_ADMIN"
+	AuditLogActionCreateSudoAccessToken    = "CREATE_SUDO_ACCESS_TOKEN"
+	AuditLogActionUpdateSiteConfiguration  = "UPDATE_SITE_CONFIGURATION"
+	AuditLogActionAddExternalService       = "ADD_EXTERNAL_SERVICE"
+	AuditLogActionUpdateExternalService    = "UPDATE_EXTERNAL_SERVICE"
+	AuditLogActionDeleteExternalService    = "DELETE_EXTERNAL_SERVICE"
+	AuditLogActionSetRepositoryPermissions = "SET_REPOSITORY_PERMISSIONS"
+)
+
+// computeHash returns the HMAC-SHA256 (keyed with auditLogHMACKey) of the entry's contents
+// (excluding its ID) and PrevHash.
+//
+// Each field is prefixed with its length, so that the boundaries between fields are unambiguous.
+// The same algorithm is documented for auditors who verify exported audit logs.
+func (e *AuditLogEntry) computeHash() string {
+	h := hmac.New(sha256.New, auditLogHMACKey)
+	for _, f := range []string{
+		e.PrevHash,
+		strconv.FormatInt(int64(e.ActorUserID), 10),
+		e.ActorIP,
+		e.ForwardedFor,
+		e.Action,
+		e.TargetType,
+		e.TargetID,
+		string(e.Before),
+		string(e.After),
+		e.CreatedAt.UTC().Format(time.RFC3339Nano),
+	} {
+		fmt.Fprintf(h, "%d:%s", len(f), f)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+type auditLog struct{}
+
+const auditLogColumns = `
+	id, actor_user_id, actor_ip, forwarded_for, action, target_type, target_id,
+	before, after, created_at, prev_hash, hash
+`
+
+func scanAuditLogEntry(s interface{ Scan(...interface{}) error }) (*AuditLogEntry, error) {
+	var e AuditLogEntry
+	var before, after []byte
+	if err := s.Scan(
+		&e.ID, &e.ActorUserID, &e.ActorIP, &e.ForwardedFor, &e.Action, &e.TargetType, &e.TargetID,
+		&before, &after, &e.CreatedAt, &e.PrevHash, &e.Hash,
+	); err != nil {
+		return nil, err
+	}
+	if before != nil {
+		e.Before = json.RawMessage(before)
+	}
+	if after != nil {
+		e.After = json.RawMessage(after)
+	}
+	return &e, nil
+}
+
+// Insert appends the entry to the audit log, setting its ID, CreatedAt, PrevHash and Hash fields.
+func (l *auditLog) Insert(ctx context.Context, e *AuditLogEntry) error {
+	if Mocks.AuditLog.Insert != nil {
+		return Mocks.AuditLog.Insert(ctx, e)
+	}
+	return l.Transact(ctx, func(*sql.Tx) (*AuditLogEntry, error) { return e, nil })
+}
+
+// Transact calls f in a new transaction and appends the entry that f returns to the audit log in
+// the same transaction, so that the changes that f makes with tx are only committed if they are
+// recorded. Nothing is committed if f returns an error. If f returns a nil entry, its changes are
+// committed without recording them.
+func (l *auditLog) Transact(ctx context.Context, f func(tx *sql.Tx) (*AuditLogEntry, error)) error {
+	return l.TransactOn(ctx, dbconn.Global, f)
+}
+
+// TransactOn is like Transact, but uses the given DB handle instead of the global DB handle. If
+// dbh is a transaction, f is called with it and the caller is responsible for committing it.
+func (l *auditLog) TransactOn(ctx context.Context, dbh dbutil.DB, f func(tx *sql.Tx) (*AuditLogEntry, error)) error {
+	if Mocks.AuditLog.Insert != nil {
+		e, err := f(nil)
+		if err != nil || e == nil {
+			return err
+		}
+		return Mocks.AuditLog.Insert(ctx, e)
+	}
+
+	g := func(tx *sql.Tx) error {
+		e, err := f(tx)
+		if err != nil || e == nil {
+			return err
+		}
+		return l.insert(ctx, tx, e)
+	}
+	switch t := dbh.(type) {
+	case *sql.Tx:
+		return g(t)
+	case *sql.DB:
+		return dbutil.Transaction(ctx, t, g)
+	default:
+		return fmt.Errorf("can't open transaction with unknown implementation of dbutil.DB: %T", t)
+	}
+}
+
+func (*auditLog) insert(ctx context.Context, tx *sql.Tx, e *AuditLogEntry) error {
+	if len(e.Before) == 0 {
+		e.Before = nil
+	}
+	if len(e.After) == 0 {
+		e.After = nil
+	}
+
+	// Serialize inserts, so that each entry is chained to the entry that was inserted right
+	// before it. Reads are not blocked.
+	if _, err := tx.ExecContext(ctx, "LOCK TABLE audit_log IN EXCLUSIVE MODE"); err != nil {
+		return errors.Wrap(err, "lock audit log")
+	}
+	e.PrevHash = ""
+	if err := tx.QueryRowContext(ctx, "SELECT hash FROM audit_log ORDER BY id DESC LIMIT 1").Scan(&e.PrevHash); err != nil && err != sql.ErrNoRows {
+		return errors.Wrap(err, "get latest audit log entry")
+	}
+
+	// Postgres stores timestamps with microsecond precision. Truncate so that the hash can be
+	// computed again from the stored entry.
+	e.CreatedAt = time.Now().UTC().Truncate(time.Microsecond)
+	e.Hash = e.computeHash()
+
+	return tx.QueryRowContext(
+		ctx,
+		`INSERT INTO audit_log(actor_user_id, actor_ip, forwarded_for, action, target_type, target_id, before, after, created_at, prev_hash, hash)
+		VALUES($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11) RETURNING id`,
+		e.ActorUserID, e.ActorIP, e.ForwardedFor, e.Action, e.TargetType, e.TargetID,
+		nullJSON(e.Before), nullJSON(e.After), e.CreatedAt, e.PrevHash, e.Hash,
+	).Scan(&e.ID)
+}
+
+func nullJSON(m json.RawMessage) interface{} {
+	if m == nil {
+		return nil
+	}
+	return string(m)
+}
+
+// AuditLogListOptions contains options for listing audit log entries.
+type AuditLogListOptions struct {
+	ActorUserID   int32  // only list entries of actions performed by this user
+	Action        string // only list entries of this action
+	TargetType    string // only list entries with this target type
+	TargetID      string // only list entries with this target ID (usually combined with TargetType)
+	CreatedAfter  *time.Time
+	CreatedBefore *time.Time
+
+	AfterID   int64 // only list entries with an ID greater than this ID
+	Ascending bool  // list the entries in the order they were inserted, instead of most recent first
+
+	*LimitOffset
+}
+
+func (o AuditLogListOptions) sqlConditions() []*sqlf.Query {
+	conds := []*sqlf.Query{sqlf.Sprintf("TRUE")}
+	if o.ActorUserID != 0 {
+		conds = append(conds, sqlf.Sprintf("actor_user_id=%d", o.ActorUserID))
+	}
+	if o.Action != "" {
+		conds = append(conds, sqlf.Sprintf("action=%s", o.Action))
+	}
+	if o.TargetType != "" {
+		conds = append(conds, sqlf.Sprintf("target_type=%s", o.TargetType))
+	}
+	if o.TargetID != "" {
+		conds = append(conds, sqlf.Sprintf("target_id=%s", o.TargetID))
+	}
+	if o.CreatedAfter != nil {
+		conds = append(conds, sqlf.Sprintf("created_at>%s", *o.CreatedAfter))
+	}
+	if o.CreatedBefore != nil {
+		conds = append(conds, sqlf.Sprintf("created_at<%s", *o.CreatedBefore))
+	}
+	if o.AfterID != 0 {
+		conds = append(conds, sqlf.Sprintf("id>%d", o.AfterID))
+	}
+	return conds
+}
+
+// List lists the audit log entries that satisfy the options, most recent first (unless
+// opt.Ascending is set).
+//
+// 🚨 SECURITY: The caller must ensure that the actor is a site admin.
+func (*auditLog) List(ctx context.Context, opt AuditLogListOptions) ([]*AuditLogEntry, error) {
+	if Mocks.AuditLog.List != nil {
+		return Mocks.AuditLog.List(ctx, opt)
+	}
+
+	order := sqlf.Sprintf("id DESC")
+	if opt.Ascending {
+		order = sqlf.Sprintf("id ASC")
+	}
+	q := sqlf.Sprintf(
+		"SELECT "+auditLogColumns+" FROM audit_log WHERE (%s) ORDER BY %s %s",
+		sqlf.Join(opt.sqlConditions(), ") AND ("),
+		order,
+		opt.LimitOffset.SQL(),
+	)
+	rows, err := dbconn.Global.QueryContext(ctx, q.Query(sqlf.PostgresBindVar), q.Args()...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []*AuditLogEntry
+	for rows.Next() {
+		e, err := scanAuditLogEntry(rows)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
+
+// Count counts the audit log entries that satisfy the options (ignoring limit and offset).
+//
+// 🚨 SECURITY: The caller must ensure that the actor is a site admin.
+func (*auditLog) Count(ctx context.Context, opt AuditLogListOptions) (int, error) {
+	if Mocks.AuditLog.Count != nil {
+		return Mocks.AuditLog.Count(ctx, opt)
+	}
+
+	q := sqlf.Sprintf("SELECT COUNT(*) FROM audit_log WHERE (%s)", sqlf.Join(opt.sqlConditions(), ") AND ("))
+	var count int
+	err := dbconn.Global.QueryRowContext(ctx, q.Query(sqlf.PostgresBindVar), q.Args()...).Scan(&count)
+	return count, err
+}
+
+// AuditLogVerification is the result of verifying the hash chain of the audit log.
+type AuditLogVerification struct {
+	EntryCount int  // the number of entries that were verified
+	Keyed      bool // whether the hashes are keyed with a secret (see AuditLogKeyed)
+
+	// FirstInvalidID is the ID of the first entry whose hash does not match its contents or whose
+	// PrevHash does not match the hash of the entry before it, or 0 if the audit log is intact.
+	FirstInvalidID int64
+}
+
+// Verify checks that no audit log entries were changed, deleted or inserted other than by Insert.
+//
+// 🚨 SECURITY: The caller must ensure that the actor is a site admin.
+func (*auditLog) Verify(ctx context.Context) (*AuditLogVerification, error) {
+	if Mocks.AuditLog.Verify != nil {
+		return Mocks.AuditLog.Verify(ctx)
+	}
+
+	rows, err := dbconn.Global.QueryContext(ctx, "SELECT "+auditLogColumns+" FROM audit_log ORDER BY id ASC")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var (
+		v        = AuditLogVerification{Keyed: AuditLogKeyed()}
+		prevHash string
+	)
+	for rows.Next() {
+		e, err := scanAuditLogEntry(rows)
+		if err != nil {
+			return nil, err
+		}
+		v.EntryCount++
+		if v.FirstInvalidID == 0 && (e.PrevHash != prevHash || e.Hash != e.computeHash()) {
+			v.FirstInvalidID = e.ID
+		}
+		prevHash = e.Hash
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return &v, nil
+}
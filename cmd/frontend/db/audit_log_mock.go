@@ -0,0 +1,12 @@
+package db
+
+import "context"
+
+type MockAuditLog struct {
+	// Insert is also called with the entries recorded by Transact, which then calls its function
+	// with a nil transaction.
+	Insert func(ctx context.Context, e *AuditLogEntry) error
+	List   func(ctx context.Context, opt AuditLogListOptions) ([]*AuditLogEntry, error)
+	Count  func(ctx context.Context, opt AuditLogListOptions) (int, error)
+	Verify func(ctx context.Context) (*AuditLogVerification, error)
+}
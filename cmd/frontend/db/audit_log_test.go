@@ -0,0 +1,161 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"strconv"
+	"testing"
+
+	"github.com/sourcegraph/sourcegraph/internal/db/dbconn"
+	"github.com/sourcegraph/sourcegraph/internal/db/dbtesting"
+	"github.com/sourcegraph/sourcegraph/internal/db/dbutil"
+)
+
+func TestAuditLog(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+
+	dbtesting.SetupGlobalTestDB(t)
+	ctx := context.Background()
+
+	var entries []*AuditLogEntry
+	for _, action := range []string{AuditLogActionSetSiteAdmin, AuditLogActionCreateSudoAccessToken, AuditLogActionSetSiteAdmin} {
+		e := &AuditLogEntry{
+			ActorUserID: 1,
+			ActorIP:     "10.0.0.1",
+			Action:      action,
+			TargetType:  "User",
+			TargetID:    "2",
+			After:       json.RawMessage(`{"siteAdmin":true}`),
+		}
+		if err := AuditLog.Insert(ctx, e); err != nil {
+			t.Fatal(err)
+		}
+		entries = append(entries, e)
+	}
+	if entries[0].PrevHash != "" {
+		t.Errorf("have first PrevHash %q, want empty", entries[0].PrevHash)
+	}
+	for i := 1; i < len(entries); i++ {
+		if entries[i].PrevHash != entries[i-1].Hash {
+			t.Errorf("entry %d: have PrevHash %q, want %q", i, entries[i].PrevHash, entries[i-1].Hash)
+		}
+	}
+
+	t.Run("List", func(t *testing.T) {
+		list, err := AuditLog.List(ctx, AuditLogListOptions{Action: AuditLogActionSetSiteAdmin})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(list) != 2 || list[0].ID != entries[2].ID || list[1].ID != entries[0].ID {
+			t.Errorf("have %d entries, want entries %d and %d", len(list), entries[2].ID, entries[0].ID)
+		}
+		if len(list) > 0 && list[0].Hash != entries[2].Hash {
+			t.Errorf("have hash %q, want %q", list[0].Hash, entries[2].Hash)
+		}
+
+		list, err = AuditLog.List(ctx, AuditLogListOptions{AfterID: entries[0].ID, Ascending: true, LimitOffset: &LimitOffset{Limit: 1}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(list) != 1 || list[0].ID != entries[1].ID {
+			t.Errorf("have %d entries, want entry %d", len(list), entries[1].ID)
+		}
+
+		count, err := AuditLog.Count(ctx, AuditLogListOptions{Action: AuditLogActionSetSiteAdmin})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if count != 2 {
+			t.Errorf("have count %d, want 2", count)
+		}
+	})
+
+	t.Run("Verify", func(t *testing.T) {
+		v, err := AuditLog.Verify(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if v.EntryCount != 3 || v.FirstInvalidID != 0 {
+			t.Errorf("have %+v, want 3 valid entries", v)
+		}
+	})
+
+	t.Run("append only", func(t *testing.T) {
+		if _, err := dbconn.Global.ExecContext(ctx, "UPDATE audit_log SET target_id='3'"); err == nil {
+			t.Error("have no error updating the audit log, want error")
+		}
+		if _, err := dbconn.Global.ExecContext(ctx, "DELETE FROM audit_log"); err == nil {
+			t.Error("have no error deleting from the audit log, want error")
+		}
+		if _, err := dbconn.Global.ExecContext(ctx, "TRUNCATE audit_log"); err == nil {
+			t.Error("have no error truncating the audit log, want error")
+		}
+	})
+
+	t.Run("tampering", func(t *testing.T) {
+		// Tampering requires bypassing the trigger, which the superuser running the tests can do.
+		for _, q := range []string{
+			"ALTER TABLE audit_log DISABLE TRIGGER audit_log_append_only",
+			"UPDATE audit_log SET target_id='3' WHERE id=" + strconv.FormatInt(entries[1].ID, 10),
+			"ALTER TABLE audit_log ENABLE TRIGGER audit_log_append_only",
+		} {
+			if _, err := dbconn.Global.ExecContext(ctx, q); err != nil {
+				t.Fatal(err)
+			}
+		}
+		v, err := AuditLog.Verify(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if v.FirstInvalidID != entries[1].ID {
+			t.Errorf("have first invalid ID %d, want %d", v.FirstInvalidID, entries[1].ID)
+		}
+	})
+}
+
+func TestAuditLogEntry_computeHash(t *testing.T) {
+	e := AuditLogEntry{ActorUserID: 1, Action: AuditLogActionSetSiteAdmin, TargetType: "User", TargetID: "12"}
+	hash := e.computeHash()
+	if hash2 := e.computeHash(); hash != hash2 {
+		t.Errorf("hash is not deterministic: %q != %q", hash, hash2)
+	}
+
+	// Moving bytes between fields must change the hash.
+	e2 := e
+	e2.TargetType, e2.TargetID = "User1", "2"
+	if e2.computeHash() == hash {
+		t.Error("have the same hash for entries with different fields")
+	}
+
+	e3 := e
+	e3.PrevHash = hash
+	if e3.computeHash() == hash {
+		t.Error("have the same hash for entries with different PrevHash")
+	}
+
+	// The hash must not be computable without the key.
+	defer func(key []byte) { auditLogHMACKey = key }(auditLogHMACKey)
+	auditLogHMACKey = []byte("other key")
+	if e.computeHash() == hash {
+		t.Error("have the same hash for different keys")
+	}
+}
+
+type fakeDB struct{ dbutil.DB }
+
+func TestAuditLog_TransactOn_unknownDB(t *testing.T) {
+	called := false
+	err := AuditLog.TransactOn(context.Background(), fakeDB{}, func(*sql.Tx) (*AuditLogEntry, error) {
+		called = true
+		return nil, nil
+	})
+	if err == nil {
+		t.Error("have no error, want error for a DB handle that can't open transactions")
+	}
+	if called {
+		t.Error("f was called without a transaction")
+	}
+}
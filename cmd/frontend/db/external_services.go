@@ -30,6 +30,8 @@ type ExternalServicesStore struct {
 	GitHubValidators          []func(*schema.GitHubConnection) error
 	GitLabValidators          []func(*schema.GitLabConnection, []schema.AuthProviders) error
 	BitbucketServerValidators []func(*schema.BitbucketServerConnection) error
+	BitbucketCloudValidators  []func(*schema.BitbucketCloudConnection) error
+	GitoliteValidators        []func(*schema.GitoliteConnection) error
 }
 
 // ExternalServiceKinds contains a map of all supported kinds of
@@ -130,6 +132,20 @@ func (e *ExternalServicesStore) ValidateConfig(kind, config string, ps []schema.
 		}
 		err = e.validateBitbucketServerConnection(&c)
 
+	case "BITBUCKETCLOUD":
+		var c schema.BitbucketCloudConnection
+		if err = json.Unmarshal(normalized, &c); err != nil {
+			return err
+		}
+		err = e.validateBitbucketCloudConnection(&c)
+
+	case "GITOLITE":
+		var c schema.GitoliteConnection
+		if err = json.Unmarshal(normalized, &c); err != nil {
+			return err
+		}
+		err = e.validateGitoliteConnection(&c)
+
 	case "OTHER":
 		var c schema.OtherExternalServiceConnection
 		if err = json.Unmarshal(normalized, &c); err != nil {
@@ -204,6 +220,22 @@ func (e *ExternalServicesStore) validateBitbucketServerConnection(c *schema.Bitb
 	return err.ErrorOrNil()
 }
 
+func (e *ExternalServicesStore) validateBitbucketCloudConnection(c *schema.BitbucketCloudConnection) error {
+	err := new(multierror.Error)
+	for _, validate := range e.BitbucketCloudValidators {
+		err = multierror.Append(err, validate(c))
+	}
+	return err.ErrorOrNil()
+}
+
+func (e *ExternalServicesStore) validateGitoliteConnection(c *schema.GitoliteConnection) error {
+	err := new(multierror.Error)
+	for _, validate := range e.GitoliteValidators {
+		err = multierror.Append(err, validate(c))
+	}
+	return err.ErrorOrNil()
+}
+
 // Create creates a external service.
 //
 // Since this method is used before the configuration server has started
@@ -212,8 +244,11 @@ func (e *ExternalServicesStore) validateBitbucketServerConnection(c *schema.Bitb
 // started, otherwise a panic would occur once pkg/conf's deadlock detector
 // determines a deadlock occurred.
 //
+// The provided dbh is used as the DB handle to execute the query (if nil, the global DB handle is
+// used).
+//
 // 🚨 SECURITY: The caller must ensure that the actor is a site admin.
-func (c *ExternalServicesStore) Create(ctx context.Context, confGet func() *conf.Unified, externalService *types.ExternalService) error {
+func (c *ExternalServicesStore) Create(ctx context.Context, dbh Handle, confGet func() *conf.Unified, externalService *types.ExternalService) error {
 	ps := confGet().AuthProviders
 	if err := c.ValidateConfig(externalService.Kind, externalService.Config, ps); err != nil {
 		return err
@@ -222,7 +257,7 @@ func (c *ExternalServicesStore) Create(ctx context.Context, confGet func() *conf
 	externalService.CreatedAt = time.Now()
 	externalService.UpdatedAt = externalService.CreatedAt
 
-	return dbconn.Global.QueryRowContext(
+	return orGlobal(dbh).QueryRowContext(
 		ctx,
 		"INSERT INTO external_services(kind, display_name, config, created_at, updated_at) VALUES($1, $2, $3, $4, $5) RETURNING id",
 		externalService.Kind, externalService.DisplayName, externalService.Config, externalService.CreatedAt, externalService.UpdatedAt,
@@ -237,8 +272,11 @@ type ExternalServiceUpdate struct {
 
 // Update updates a external service.
 //
+// The provided dbh is used as the DB handle to execute the queries. If nil, they are executed in a
+// new transaction.
+//
 // 🚨 SECURITY: The caller must ensure that the actor is a site admin.
-func (c *ExternalServicesStore) Update(ctx context.Context, ps []schema.AuthProviders, id int64, update *ExternalServiceUpdate) error {
+func (c *ExternalServicesStore) Update(ctx context.Context, dbh Handle, ps []schema.AuthProviders, id int64, update *ExternalServiceUpdate) error {
 	if update.Config != nil {
 		// Query to get the kind (which is immutable) so we can validate the new config.
 		externalService, err := c.GetByID(ctx, id)
@@ -251,7 +289,7 @@ func (c *ExternalServicesStore) Update(ctx context.Context, ps []schema.AuthProv
 		}
 	}
 
-	execUpdate := func(ctx context.Context, tx *sql.Tx, update *sqlf.Query) error {
+	execUpdate := func(ctx context.Context, tx Handle, update *sqlf.Query) error {
 		q := sqlf.Sprintf("UPDATE external_services SET %s, updated_at=now() WHERE id=%d AND deleted_at IS NULL", update, id)
 		res, err := tx.ExecContext(ctx, q.Query(sqlf.PostgresBindVar), q.Args()...)
 		if err != nil {
@@ -266,7 +304,7 @@ func (c *ExternalServicesStore) Update(ctx context.Context, ps []schema.AuthProv
 		}
 		return nil
 	}
-	return dbutil.Transaction(ctx, dbconn.Global, func(tx *sql.Tx) error {
+	execUpdates := func(tx Handle) error {
 		if update.DisplayName != nil {
 			if err := execUpdate(ctx, tx, sqlf.Sprintf("display_name=%s", update.DisplayName)); err != nil {
 				return err
@@ -278,7 +316,11 @@ func (c *ExternalServicesStore) Update(ctx context.Context, ps []schema.AuthProv
 			}
 		}
 		return nil
-	})
+	}
+	if !isNilHandle(dbh) {
+		return execUpdates(dbh)
+	}
+	return dbutil.Transaction(ctx, dbconn.Global, func(tx *sql.Tx) error { return execUpdates(tx) })
 }
 
 type externalServiceNotFoundError struct {
@@ -295,9 +337,12 @@ func (e externalServiceNotFoundError) NotFound() bool {
 
 // Delete deletes an external service.
 //
+// The provided dbh is used as the DB handle to execute the query (if nil, the global DB handle is
+// used).
+//
 // 🚨 SECURITY: The caller must ensure that the actor is a site admin.
-func (*ExternalServicesStore) Delete(ctx context.Context, id int64) error {
-	res, err := dbconn.Global.ExecContext(ctx, "UPDATE external_services SET deleted_at=now() WHERE id=$1 AND deleted_at IS NULL", id)
+func (*ExternalServicesStore) Delete(ctx context.Context, dbh Handle, id int64) error {
+	res, err := orGlobal(dbh).ExecContext(ctx, "UPDATE external_services SET deleted_at=now() WHERE id=$1 AND deleted_at IS NULL", id)
 	if err != nil {
 		return err
 	}
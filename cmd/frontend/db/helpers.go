@@ -1,6 +1,12 @@
 package db
 
-import "github.com/keegancsmith/sqlf"
+import (
+	"context"
+	"database/sql"
+
+	"github.com/keegancsmith/sqlf"
+	"github.com/sourcegraph/sourcegraph/internal/db/dbconn"
+)
 
 // LimitOffset specifies SQL LIMIT and OFFSET counts. A pointer to it is typically embedded in other options
 // structs that need to perform SQL queries with LIMIT and OFFSET.
@@ -16,3 +22,25 @@ func (o *LimitOffset) SQL() *sqlf.Query {
 	}
 	return sqlf.Sprintf("LIMIT %d OFFSET %d", o.Limit, o.Offset)
 }
+
+// Handle is a DB handle that may be either the global DB handle or a transaction. Methods that
+// accept a Handle use the global DB handle if it is nil.
+type Handle interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+func orGlobal(dbh Handle) Handle {
+	if isNilHandle(dbh) {
+		return dbconn.Global
+	}
+	return dbh
+}
+
+// isNilHandle reports whether dbh is nil or a nil *sql.Tx (which AuditLog.Transact passes to its
+// function when it is mocked).
+func isNilHandle(dbh Handle) bool {
+	tx, ok := dbh.(*sql.Tx)
+	return dbh == nil || (ok && tx == nil)
+}
@@ -0,0 +1,25 @@
+package db
+
+import (
+	"database/sql"
+	"testing"
+)
+
+func TestIsNilHandle(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		dbh  Handle
+		want bool
+	}{
+		{name: "nil", dbh: nil, want: true},
+		{name: "nil transaction", dbh: (*sql.Tx)(nil), want: true},
+		{name: "transaction", dbh: &sql.Tx{}, want: false},
+		{name: "DB", dbh: &sql.DB{}, want: false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if have := isNilHandle(tc.dbh); have != tc.want {
+				t.Errorf("have %v, want %v", have, tc.want)
+			}
+		})
+	}
+}
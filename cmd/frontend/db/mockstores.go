@@ -5,6 +5,7 @@ var Mocks MockStores
 // MockStores has a field for each store interface with the concrete mock type (to obviate the need for tedious type assertions in test code).
 type MockStores struct {
 	AccessTokens MockAccessTokens
+	AuditLog     MockAuditLog
 
 	DiscussionThreads         MockDiscussionThreads
 	DiscussionComments        MockDiscussionComments
@@ -14,6 +15,7 @@ type MockStores struct {
 	Orgs          MockOrgs
 	OrgMembers    MockOrgMembers
 	SavedSearches MockSavedSearches
+	SearchExports MockSearchExports
 	Settings      MockSettings
 	Users         MockUsers
 	UserEmails    MockUserEmails
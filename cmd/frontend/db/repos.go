@@ -117,6 +117,51 @@ func (s *repos) GetByIDs(ctx context.Context, ids ...api.RepoID) ([]*types.Repo,
 	return s.getReposBySQL(ctx, true, q)
 }
 
+// GetStarCounts returns the number of stars of the given repositories on
+// their code hosts, as recorded in their metadata. Repositories without a star
+// count in their metadata are omitted.
+func (s *repos) GetStarCounts(ctx context.Context, ids ...api.RepoID) (map[api.RepoID]int, error) {
+	if Mocks.Repos.GetStarCounts != nil {
+		return Mocks.Repos.GetStarCounts(ctx, ids...)
+	}
+
+	counts := make(map[api.RepoID]int, len(ids))
+	if len(ids) == 0 {
+		return counts, nil
+	}
+
+	items := make([]*sqlf.Query, len(ids))
+	for i := range ids {
+		items[i] = sqlf.Sprintf("%d", ids[i])
+	}
+	// GitHub metadata is a github.Repository, GitLab metadata a gitlab.Project.
+	q := sqlf.Sprintf(`
+SELECT id, COALESCE(metadata->>'StargazerCount', metadata->>'star_count')::integer
+FROM repo
+WHERE deleted_at IS NULL
+AND id IN (%s)
+AND COALESCE(metadata->>'StargazerCount', metadata->>'star_count') IS NOT NULL`,
+		sqlf.Join(items, ","),
+	)
+	rows, err := dbconn.Global.QueryContext(ctx, q.Query(sqlf.PostgresBindVar), q.Args()...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			id    api.RepoID
+			count int
+		)
+		if err := rows.Scan(&id, &count); err != nil {
+			return nil, err
+		}
+		counts[id] = count
+	}
+	return counts, rows.Err()
+}
+
 func (s *repos) Count(ctx context.Context, opt ReposListOptions) (int, error) {
 	if Mocks.Repos.Count != nil {
 		return Mocks.Repos.Count(ctx, opt)
@@ -235,6 +235,31 @@ func TestRepos_GetByIDs(t *testing.T) {
 	}
 }
 
+func TestRepos_GetStarCounts(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+
+	dbtesting.SetupGlobalTestDB(t)
+	ctx := context.Background()
+
+	repos := mustCreate(ctx, t, &types.Repo{Name: "github"}, &types.Repo{Name: "gitlab"}, &types.Repo{Name: "other"})
+	for i, metadata := range []string{`{"StargazerCount": 3}`, `{"star_count": 5}`, `{}`} {
+		if _, err := dbconn.Global.ExecContext(ctx, "UPDATE repo SET metadata = $1 WHERE id = $2", metadata, repos[i].ID); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	counts, err := Repos.GetStarCounts(ctx, repos[0].ID, repos[1].ID, repos[2].ID, 404)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[api.RepoID]int{repos[0].ID: 3, repos[1].ID: 5}
+	if !reflect.DeepEqual(counts, want) {
+		t.Errorf("got %v, want %v", counts, want)
+	}
+}
+
 func TestRepos_List(t *testing.T) {
 	if testing.Short() {
 		t.Skip()
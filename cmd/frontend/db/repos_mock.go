@@ -10,11 +10,12 @@ import (
 )
 
 type MockRepos struct {
-	Get       func(ctx context.Context, repo api.RepoID) (*types.Repo, error)
-	GetByName func(ctx context.Context, repo api.RepoName) (*types.Repo, error)
-	GetByIDs  func(ctx context.Context, ids ...api.RepoID) ([]*types.Repo, error)
-	List      func(v0 context.Context, v1 ReposListOptions) ([]*types.Repo, error)
-	Count     func(ctx context.Context, opt ReposListOptions) (int, error)
+	Get           func(ctx context.Context, repo api.RepoID) (*types.Repo, error)
+	GetByName     func(ctx context.Context, repo api.RepoName) (*types.Repo, error)
+	GetByIDs      func(ctx context.Context, ids ...api.RepoID) ([]*types.Repo, error)
+	GetStarCounts func(ctx context.Context, ids ...api.RepoID) (map[api.RepoID]int, error)
+	List          func(v0 context.Context, v1 ReposListOptions) ([]*types.Repo, error)
+	Count         func(ctx context.Context, opt ReposListOptions) (int, error)
 }
 
 func (s *MockRepos) MockGet(t *testing.T, wantRepo api.RepoID) (called *bool) {
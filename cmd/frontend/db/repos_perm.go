@@ -89,6 +89,18 @@ func authzFilter(ctx context.Context, repos []*types.Repo, p authz.Perms) (filte
 		tr.LogFields(fields...)
 	}()
 
+	// 🚨 SECURITY: Requests authenticated with an access token that is restricted to some
+	// repositories can only see those repositories, even if the user is a site admin.
+	if r := authz.TokenRestrictionsFromContext(ctx); r != nil && len(r.RepositoryPatterns) > 0 {
+		allowed := repos[:0]
+		for _, repo := range repos {
+			if r.AllowsRepo(repo.Name) {
+				allowed = append(allowed, repo)
+			}
+		}
+		repos = allowed
+	}
+
 	if isInternalActor(ctx) {
 		return repos, nil
 	}
@@ -3,6 +3,7 @@ package db
 import (
 	"context"
 	"net/url"
+	"reflect"
 	"testing"
 
 	"github.com/keegancsmith/sqlf"
@@ -157,6 +158,35 @@ func (f fakeProvider) FetchRepoPerms(context.Context, *extsvc.Repository) ([]ext
 }
 
 // 🚨 SECURITY: test necessary to ensure security
+func Test_authzFilter_tokenRestrictions(t *testing.T) {
+	Mocks.Users.GetByCurrentAuthUser = func(ctx context.Context) (*types.User, error) {
+		return &types.User{ID: 1, SiteAdmin: true}, nil
+	}
+	defer func() { Mocks.Users.GetByCurrentAuthUser = nil }()
+
+	restrictions, err := authz.NewTokenRestrictions([]string{authz.ScopeSearchRead}, []string{"^github.com/foo/"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := actor.WithActor(context.Background(), &actor.Actor{UID: 1})
+	ctx = authz.WithTokenRestrictions(ctx, restrictions)
+
+	// 🚨 SECURITY: Restricted access tokens only see the repositories they may be used with,
+	// even if the user is a site admin.
+	repos := []*types.Repo{{Name: "github.com/foo/a"}, {Name: "github.com/bar/b"}, {Name: "github.com/foo/c"}}
+	filtered, err := authzFilter(ctx, repos, authz.Read)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []api.RepoName
+	for _, r := range filtered {
+		names = append(names, r.Name)
+	}
+	if want := []api.RepoName{"github.com/foo/a", "github.com/foo/c"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("got repos %v, want %v", names, want)
+	}
+}
+
 func Test_getBySQL_permissionsCheck(t *testing.T) {
 	if testing.Short() {
 		t.Skip()
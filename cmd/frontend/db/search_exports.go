@@ -0,0 +1,369 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/keegancsmith/sqlf"
+	"github.com/pkg/errors"
+	"github.com/sourcegraph/sourcegraph/internal/db/dbconn"
+	"github.com/sourcegraph/sourcegraph/internal/db/dbutil"
+)
+
+// SearchExport is an asynchronous job that exports all results of a search
+// query to a file. The file is stored in chunks, one per page of results.
+type SearchExport struct {
+	ID          int64
+	UserID      int32 // the user whose permissions the search runs with
+	Query       string
+	Version     string
+	PatternType *string
+	Format      string // SearchExportFormatCSV or SearchExportFormatJSONL
+	State       string
+	Error       string
+	Cursor      *string // the pagination cursor of the next page of results
+	ChunkCount  int32
+	ResultCount int32
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	StartedAt   *time.Time
+	FinishedAt  *time.Time
+	ExpiresAt   time.Time
+}
+
+// The states of a SearchExport.
+const (
+	SearchExportStateQueued     = "QUEUED"
+	SearchExportStateProcessing = "PROCESSING"
+	SearchExportStateCompleted  = "COMPLETED"
+	SearchExportStateErrored    = "ERRORED"
+	SearchExportStateCanceled   = "CANCELED"
+)
+
+// The file formats of a SearchExport.
+const (
+	SearchExportFormatCSV   = "CSV"
+	SearchExportFormatJSONL = "JSONL"
+)
+
+// SearchExportTTL is how long a search export is kept after it was created
+// and, once it is completed, after it was completed.
+const SearchExportTTL = 7 * 24 * time.Hour
+
+// ErrSearchExportNotFound occurs when a database operation expects a specific
+// search export to exist but it does not exist.
+var ErrSearchExportNotFound = errors.New("search export not found")
+
+// ErrSearchExportNotProcessing occurs when a worker updates a search export
+// that is not processing anymore, e.g. because it was canceled.
+var ErrSearchExportNotProcessing = errors.New("search export is not processing")
+
+type searchExports struct{}
+
+const searchExportColumns = `
+	id, user_id, query, version, pattern_type, format, state, error, cursor,
+	chunk_count, result_count, created_at, updated_at, started_at, finished_at,
+	expires_at
+`
+
+// Create creates a queued search export.
+//
+// 🚨 SECURITY: The caller must ensure that the actor is the user the search
+// export is created for, since the search runs with that user's permissions.
+func (s *searchExports) Create(ctx context.Context, e *SearchExport) (*SearchExport, error) {
+	if Mocks.SearchExports.Create != nil {
+		return Mocks.SearchExports.Create(ctx, e)
+	}
+
+	q := sqlf.Sprintf(`
+INSERT INTO search_exports (user_id, query, version, pattern_type, format, expires_at)
+VALUES (%s, %s, %s, %s, %s, now() + %s * interval '1 second')
+RETURNING `+searchExportColumns,
+		e.UserID, e.Query, e.Version, e.PatternType, e.Format, SearchExportTTL/time.Second,
+	)
+	return scanSearchExport(dbconn.Global.QueryRowContext(ctx, q.Query(sqlf.PostgresBindVar), q.Args()...))
+}
+
+// GetByID returns the search export with the given ID, or
+// ErrSearchExportNotFound if it doesn't exist or has expired.
+//
+// 🚨 SECURITY: The caller must ensure that the actor may view the search
+// export, i.e. that the actor is its user or a site admin.
+func (s *searchExports) GetByID(ctx context.Context, id int64) (*SearchExport, error) {
+	if Mocks.SearchExports.GetByID != nil {
+		return Mocks.SearchExports.GetByID(ctx, id)
+	}
+
+	q := sqlf.Sprintf(`SELECT `+searchExportColumns+` FROM search_exports WHERE id = %s AND expires_at > now()`, id)
+	e, err := scanSearchExport(dbconn.Global.QueryRowContext(ctx, q.Query(sqlf.PostgresBindVar), q.Args()...))
+	if err == sql.ErrNoRows {
+		return nil, ErrSearchExportNotFound
+	}
+	return e, err
+}
+
+// ListByUser returns the unexpired search exports of the given user, newest
+// first. If limit is 0, all of them are returned.
+//
+// 🚨 SECURITY: The caller must ensure that the actor is the user or a site
+// admin.
+func (s *searchExports) ListByUser(ctx context.Context, userID int32, limit int) ([]*SearchExport, error) {
+	if Mocks.SearchExports.ListByUser != nil {
+		return Mocks.SearchExports.ListByUser(ctx, userID, limit)
+	}
+
+	limitClause := sqlf.Sprintf("")
+	if limit > 0 {
+		limitClause = sqlf.Sprintf("LIMIT %s", limit)
+	}
+	q := sqlf.Sprintf(`
+SELECT `+searchExportColumns+` FROM search_exports
+WHERE user_id = %s AND expires_at > now()
+ORDER BY id DESC
+%s`,
+		userID, limitClause,
+	)
+	rows, err := dbconn.Global.QueryContext(ctx, q.Query(sqlf.PostgresBindVar), q.Args()...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var exports []*SearchExport
+	for rows.Next() {
+		e, err := scanSearchExport(rows)
+		if err != nil {
+			return nil, err
+		}
+		exports = append(exports, e)
+	}
+	return exports, rows.Err()
+}
+
+// CountByUser returns the number of unexpired search exports of the given user.
+func (s *searchExports) CountByUser(ctx context.Context, userID int32) (int, error) {
+	if Mocks.SearchExports.CountByUser != nil {
+		return Mocks.SearchExports.CountByUser(ctx, userID)
+	}
+
+	q := sqlf.Sprintf(`SELECT COUNT(*) FROM search_exports WHERE user_id = %s AND expires_at > now()`, userID)
+	var count int
+	err := dbconn.Global.QueryRowContext(ctx, q.Query(sqlf.PostgresBindVar), q.Args()...).Scan(&count)
+	return count, err
+}
+
+// Cancel cancels a queued or processing search export and deletes the
+// results it exported so far. A worker processing it stops with the next page
+// of results. Canceling an export that has finished is a noop.
+//
+// 🚨 SECURITY: The caller must ensure that the actor is the user of the search
+// export or a site admin.
+func (s *searchExports) Cancel(ctx context.Context, id int64) (*SearchExport, error) {
+	if Mocks.SearchExports.Cancel != nil {
+		return Mocks.SearchExports.Cancel(ctx, id)
+	}
+
+	err := dbutil.Transaction(ctx, dbconn.Global, func(tx *sql.Tx) error {
+		q := sqlf.Sprintf(`
+UPDATE search_exports SET state = %s, cursor = NULL, finished_at = now(), updated_at = now()
+WHERE id = %s AND state IN (%s, %s)`,
+			SearchExportStateCanceled, id, SearchExportStateQueued, SearchExportStateProcessing,
+		)
+		res, err := tx.ExecContext(ctx, q.Query(sqlf.PostgresBindVar), q.Args()...)
+		if err != nil {
+			return err
+		}
+		if n, err := res.RowsAffected(); err != nil || n == 0 {
+			return err
+		}
+
+		q = sqlf.Sprintf(`DELETE FROM search_export_chunks WHERE export_id = %s`, id)
+		_, err = tx.ExecContext(ctx, q.Query(sqlf.PostgresBindVar), q.Args()...)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.GetByID(ctx, id)
+}
+
+// Dequeue marks the oldest queued search export as processing and returns
+// it, or nil if there is none. Concurrent callers never dequeue the same
+// export.
+func (s *searchExports) Dequeue(ctx context.Context) (*SearchExport, error) {
+	q := sqlf.Sprintf(`
+UPDATE search_exports SET state = %s, started_at = COALESCE(started_at, now()), updated_at = now()
+WHERE id = (
+	SELECT id FROM search_exports
+	WHERE state = %s AND expires_at > now()
+	ORDER BY id
+	LIMIT 1
+	FOR UPDATE SKIP LOCKED
+)
+RETURNING `+searchExportColumns,
+		SearchExportStateProcessing, SearchExportStateQueued,
+	)
+	e, err := scanSearchExport(dbconn.Global.QueryRowContext(ctx, q.Query(sqlf.PostgresBindVar), q.Args()...))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return e, err
+}
+
+// AppendChunk appends a chunk of the exported file, which contains the given
+// number of results, to a processing search export and records the cursor of
+// the next page of results. It returns ErrSearchExportNotProcessing if the
+// export is not processing anymore.
+func (s *searchExports) AppendChunk(ctx context.Context, id int64, data []byte, results int32, cursor *string) error {
+	if Mocks.SearchExports.AppendChunk != nil {
+		return Mocks.SearchExports.AppendChunk(ctx, id, data, results, cursor)
+	}
+
+	return dbutil.Transaction(ctx, dbconn.Global, func(tx *sql.Tx) error {
+		q := sqlf.Sprintf(`
+UPDATE search_exports
+SET chunk_count = chunk_count + 1, result_count = result_count + %s, cursor = %s, updated_at = now()
+WHERE id = %s AND state = %s
+RETURNING chunk_count - 1`,
+			results, cursor, id, SearchExportStateProcessing,
+		)
+		var seq int32
+		err := tx.QueryRowContext(ctx, q.Query(sqlf.PostgresBindVar), q.Args()...).Scan(&seq)
+		if err == sql.ErrNoRows {
+			return ErrSearchExportNotProcessing
+		} else if err != nil {
+			return err
+		}
+
+		q = sqlf.Sprintf(`INSERT INTO search_export_chunks (export_id, seq, data) VALUES (%s, %s, %s)`, id, seq, data)
+		_, err = tx.ExecContext(ctx, q.Query(sqlf.PostgresBindVar), q.Args()...)
+		return err
+	})
+}
+
+// Complete marks a processing search export as completed and extends its
+// expiry to SearchExportTTL from now.
+func (s *searchExports) Complete(ctx context.Context, id int64) error {
+	q := sqlf.Sprintf(`
+UPDATE search_exports
+SET state = %s, cursor = NULL, finished_at = now(), updated_at = now(), expires_at = now() + %s * interval '1 second'
+WHERE id = %s AND state = %s`,
+		SearchExportStateCompleted, SearchExportTTL/time.Second, id, SearchExportStateProcessing,
+	)
+	return s.finish(ctx, q)
+}
+
+// Fail marks a processing search export as errored with the given message and
+// deletes the results it exported so far.
+func (s *searchExports) Fail(ctx context.Context, id int64, message string) error {
+	q := sqlf.Sprintf(`
+UPDATE search_exports
+SET state = %s, error = %s, cursor = NULL, finished_at = now(), updated_at = now()
+WHERE id = %s AND state = %s`,
+		SearchExportStateErrored, message, id, SearchExportStateProcessing,
+	)
+	if err := s.finish(ctx, q); err != nil {
+		return err
+	}
+
+	q = sqlf.Sprintf(`DELETE FROM search_export_chunks WHERE export_id = %s`, id)
+	_, err := dbconn.Global.ExecContext(ctx, q.Query(sqlf.PostgresBindVar), q.Args()...)
+	return err
+}
+
+func (s *searchExports) finish(ctx context.Context, q *sqlf.Query) error {
+	res, err := dbconn.Global.ExecContext(ctx, q.Query(sqlf.PostgresBindVar), q.Args()...)
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrSearchExportNotProcessing
+	}
+	return nil
+}
+
+// ResetStalled requeues processing search exports whose worker has not
+// appended a chunk for the given duration, e.g. because its frontend was
+// restarted. The next worker resumes them from their cursor.
+func (s *searchExports) ResetStalled(ctx context.Context, stalledAfter time.Duration) (int64, error) {
+	q := sqlf.Sprintf(`
+UPDATE search_exports SET state = %s, updated_at = now()
+WHERE state = %s AND updated_at < now() - %s * interval '1 second'`,
+		SearchExportStateQueued, SearchExportStateProcessing, stalledAfter/time.Second,
+	)
+	res, err := dbconn.Global.ExecContext(ctx, q.Query(sqlf.PostgresBindVar), q.Args()...)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// DeleteExpired deletes the search exports that have expired, including
+// their exported files.
+func (s *searchExports) DeleteExpired(ctx context.Context) (int64, error) {
+	res, err := dbconn.Global.ExecContext(ctx, `DELETE FROM search_exports WHERE expires_at <= now()`)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// ReadChunks calls f with the chunks of the exported file of a search export,
+// in order.
+//
+// 🚨 SECURITY: The caller must ensure that the actor is the user of the search
+// export or a site admin.
+func (s *searchExports) ReadChunks(ctx context.Context, id int64, f func(data []byte) error) error {
+	if Mocks.SearchExports.ReadChunks != nil {
+		return Mocks.SearchExports.ReadChunks(ctx, id, f)
+	}
+
+	q := sqlf.Sprintf(`SELECT data FROM search_export_chunks WHERE export_id = %s ORDER BY seq`, id)
+	rows, err := dbconn.Global.QueryContext(ctx, q.Query(sqlf.PostgresBindVar), q.Args()...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var data []byte
+		if err := rows.Scan(&data); err != nil {
+			return err
+		}
+		if err := f(data); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+func scanSearchExport(s interface{ Scan(...interface{}) error }) (*SearchExport, error) {
+	var e SearchExport
+	err := s.Scan(
+		&e.ID,
+		&e.UserID,
+		&e.Query,
+		&e.Version,
+		&e.PatternType,
+		&e.Format,
+		&e.State,
+		&dbutil.NullString{S: &e.Error},
+		&e.Cursor,
+		&e.ChunkCount,
+		&e.ResultCount,
+		&e.CreatedAt,
+		&e.UpdatedAt,
+		&e.StartedAt,
+		&e.FinishedAt,
+		&e.ExpiresAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &e, nil
+}
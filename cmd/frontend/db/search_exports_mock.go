@@ -0,0 +1,13 @@
+package db
+
+import "context"
+
+type MockSearchExports struct {
+	Create      func(ctx context.Context, e *SearchExport) (*SearchExport, error)
+	GetByID     func(ctx context.Context, id int64) (*SearchExport, error)
+	ListByUser  func(ctx context.Context, userID int32, limit int) ([]*SearchExport, error)
+	CountByUser func(ctx context.Context, userID int32) (int, error)
+	Cancel      func(ctx context.Context, id int64) (*SearchExport, error)
+	AppendChunk func(ctx context.Context, id int64, data []byte, results int32, cursor *string) error
+	ReadChunks  func(ctx context.Context, id int64, f func(data []byte) error) error
+}
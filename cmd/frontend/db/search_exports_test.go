@@ -0,0 +1,185 @@
+package db
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/sourcegraph/sourcegraph/internal/db/dbconn"
+	"github.com/sourcegraph/sourcegraph/internal/db/dbtesting"
+)
+
+func TestSearchExports(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+
+	dbtesting.SetupGlobalTestDB(t)
+	ctx := context.Background()
+
+	user, err := Users.Create(ctx, NewUser{Username: "u"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	create := func() *SearchExport {
+		t.Helper()
+		e, err := SearchExports.Create(ctx, &SearchExport{
+			UserID:  user.ID,
+			Query:   "repo:foo bar",
+			Version: "V2",
+			Format:  SearchExportFormatCSV,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if e.State != SearchExportStateQueued {
+			t.Fatalf("have state %q, want %q", e.State, SearchExportStateQueued)
+		}
+		return e
+	}
+
+	t.Run("complete", func(t *testing.T) {
+		e := create()
+
+		dequeued, err := SearchExports.Dequeue(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if dequeued == nil || dequeued.ID != e.ID || dequeued.State != SearchExportStateProcessing {
+			t.Fatalf("have dequeued %+v, want processing export %d", dequeued, e.ID)
+		}
+		if again, err := SearchExports.Dequeue(ctx); err != nil || again != nil {
+			t.Fatalf("have dequeued %+v, %v, want nothing", again, err)
+		}
+
+		cursor := "next"
+		if err := SearchExports.AppendChunk(ctx, e.ID, []byte("a,b\n"), 1, &cursor); err != nil {
+			t.Fatal(err)
+		}
+		if err := SearchExports.AppendChunk(ctx, e.ID, []byte("c,d\n"), 2, nil); err != nil {
+			t.Fatal(err)
+		}
+		if err := SearchExports.Complete(ctx, e.ID); err != nil {
+			t.Fatal(err)
+		}
+
+		e, err = SearchExports.GetByID(ctx, e.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if e.State != SearchExportStateCompleted || e.ChunkCount != 2 || e.ResultCount != 3 || e.FinishedAt == nil {
+			t.Errorf("unexpected completed export %+v", e)
+		}
+
+		var data bytes.Buffer
+		err = SearchExports.ReadChunks(ctx, e.ID, func(chunk []byte) error {
+			data.Write(chunk)
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if have, want := data.String(), "a,b\nc,d\n"; have != want {
+			t.Errorf("have data %q, want %q", have, want)
+		}
+
+		// Canceling a completed export is a noop.
+		if e, err = SearchExports.Cancel(ctx, e.ID); err != nil || e.State != SearchExportStateCompleted {
+			t.Errorf("have %+v, %v after cancel, want completed export", e, err)
+		}
+	})
+
+	t.Run("cancel", func(t *testing.T) {
+		e := create()
+		if _, err := SearchExports.Dequeue(ctx); err != nil {
+			t.Fatal(err)
+		}
+		if err := SearchExports.AppendChunk(ctx, e.ID, []byte("a,b\n"), 1, nil); err != nil {
+			t.Fatal(err)
+		}
+
+		e, err := SearchExports.Cancel(ctx, e.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if e.State != SearchExportStateCanceled {
+			t.Errorf("have state %q, want %q", e.State, SearchExportStateCanceled)
+		}
+
+		if err := SearchExports.AppendChunk(ctx, e.ID, []byte("c,d\n"), 1, nil); err != ErrSearchExportNotProcessing {
+			t.Errorf("have error %v, want %v", err, ErrSearchExportNotProcessing)
+		}
+		if err := SearchExports.Complete(ctx, e.ID); err != ErrSearchExportNotProcessing {
+			t.Errorf("have error %v, want %v", err, ErrSearchExportNotProcessing)
+		}
+
+		var chunks int
+		_ = SearchExports.ReadChunks(ctx, e.ID, func([]byte) error { chunks++; return nil })
+		if chunks != 0 {
+			t.Errorf("have %d chunks of canceled export, want 0", chunks)
+		}
+	})
+
+	t.Run("fail", func(t *testing.T) {
+		e := create()
+		if _, err := SearchExports.Dequeue(ctx); err != nil {
+			t.Fatal(err)
+		}
+		if err := SearchExports.Fail(ctx, e.ID, "boom"); err != nil {
+			t.Fatal(err)
+		}
+		e, err := SearchExports.GetByID(ctx, e.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if e.State != SearchExportStateErrored || e.Error != "boom" {
+			t.Errorf("have state %q and error %q, want errored export", e.State, e.Error)
+		}
+	})
+
+	t.Run("reset stalled", func(t *testing.T) {
+		e := create()
+		if _, err := SearchExports.Dequeue(ctx); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := dbconn.Global.Exec(`UPDATE search_exports SET updated_at = now() - interval '1 hour' WHERE id = $1`, e.ID); err != nil {
+			t.Fatal(err)
+		}
+		if n, err := SearchExports.ResetStalled(ctx, time.Minute); err != nil || n != 1 {
+			t.Fatalf("have %d, %v, want 1 reset export", n, err)
+		}
+		dequeued, err := SearchExports.Dequeue(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if dequeued == nil || dequeued.ID != e.ID {
+			t.Fatalf("have dequeued %+v, want export %d", dequeued, e.ID)
+		}
+		if err := SearchExports.Complete(ctx, e.ID); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	t.Run("expire", func(t *testing.T) {
+		e := create()
+		if _, err := dbconn.Global.Exec(`UPDATE search_exports SET expires_at = now() - interval '1 second' WHERE id = $1`, e.ID); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := SearchExports.GetByID(ctx, e.ID); err != ErrSearchExportNotFound {
+			t.Errorf("have error %v, want %v", err, ErrSearchExportNotFound)
+		}
+		if n, err := SearchExports.DeleteExpired(ctx); err != nil || n != 1 {
+			t.Errorf("have %d, %v, want 1 deleted export", n, err)
+		}
+	})
+
+	exports, err := SearchExports.ListByUser(ctx, user.ID, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count, err := SearchExports.CountByUser(ctx, user.ID); err != nil || count != len(exports) || count != 4 {
+		t.Errorf("have count %d, %v and %d listed exports, want 4", count, err, len(exports))
+	}
+}
@@ -2,6 +2,7 @@ package db
 
 var (
 	AccessTokens              = &accessTokens{}
+	AuditLog                  = &auditLog{}
 	ExternalServices          = &ExternalServicesStore{}
 	DefaultRepos              = &defaultRepos{}
 	DiscussionThreads         = &discussionThreads{}
@@ -13,6 +14,7 @@ var (
 	Orgs                      = &orgs{}
 	OrgMembers                = &orgMembers{}
 	SavedSearches             = &savedSearches{}
+	SearchExports             = &searchExports{}
 	Settings                  = &settings{}
 	Users                     = &users{}
 	UserEmails                = &userEmails{}
@@ -452,6 +452,80 @@ func (u *users) Delete(ctx context.Context, id int32) error {
 	return nil
 }
 
+// Deactivate soft-deletes the user and removes them from all organizations. Unlike Delete, it keeps
+// the user's username and email addresses reserved, so that the user can be reactivated with
+// Reactivate. Deactivated users cannot sign in, and their access tokens and external accounts are
+// revoked.
+func (u *users) Deactivate(ctx context.Context, id int32) (err error) {
+	if Mocks.Users.Deactivate != nil {
+		return Mocks.Users.Deactivate(ctx, id)
+	}
+
+	// Wrap in transaction because we update multiple tables.
+	tx, err := dbconn.Global.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			rollErr := tx.Rollback()
+			if rollErr != nil {
+				err = multierror.Append(err, rollErr)
+			}
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	res, err := tx.ExecContext(ctx, "UPDATE users SET deleted_at=now() WHERE id=$1 AND deleted_at IS NULL", id)
+	if err != nil {
+		return err
+	}
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return userNotFoundErr{args: []interface{}{id}}
+	}
+
+	if _, err := tx.ExecContext(ctx, "UPDATE access_tokens SET deleted_at=now() WHERE deleted_at IS NULL AND (subject_user_id=$1 OR creator_user_id=$1)", id); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, "UPDATE user_external_accounts SET deleted_at=now() WHERE user_id=$1 AND deleted_at IS NULL", id); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, "DELETE FROM org_members WHERE user_id=$1", id); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Reactivate reverses Deactivate. Users that were deleted with Delete cannot be reactivated.
+//
+// It restores neither the user's access tokens nor their external accounts, and the user is not
+// added back to their organizations. Users who sign in with an external auth provider have the
+// external account linked again on their next sign-in, by matching its email address against the
+// user's verified email addresses.
+func (u *users) Reactivate(ctx context.Context, id int32) error {
+	if Mocks.Users.Reactivate != nil {
+		return Mocks.Users.Reactivate(ctx, id)
+	}
+
+	res, err := dbconn.Global.ExecContext(ctx, "UPDATE users SET deleted_at=NULL, updated_at=now() WHERE id=$1 AND deleted_at IS NOT NULL AND EXISTS (SELECT 1 FROM names WHERE user_id=$1)", id)
+	if err != nil {
+		return err
+	}
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return userNotFoundErr{args: []interface{}{id}}
+	}
+	return nil
+}
+
 func (u *users) HardDelete(ctx context.Context, id int32) error {
 	if Mocks.Users.HardDelete != nil {
 		return Mocks.Users.HardDelete(ctx, id)
@@ -542,11 +616,13 @@ func (u *users) HardDelete(ctx context.Context, id int32) error {
 	return nil
 }
 
-func (u *users) SetIsSiteAdmin(ctx context.Context, id int32, isSiteAdmin bool) error {
+// SetIsSiteAdmin promotes the user to or demotes the user from site admin. The provided dbh is used
+// as the DB handle to execute the query (if nil, the global DB handle is used).
+func (u *users) SetIsSiteAdmin(ctx context.Context, dbh Handle, id int32, isSiteAdmin bool) error {
 	if Mocks.Users.SetIsSiteAdmin != nil {
 		return Mocks.Users.SetIsSiteAdmin(id, isSiteAdmin)
 	}
-	_, err := dbconn.Global.ExecContext(ctx, "UPDATE users SET site_admin=$1 WHERE id=$2", isSiteAdmin, id)
+	_, err := orGlobal(dbh).ExecContext(ctx, "UPDATE users SET site_admin=$1 WHERE id=$2", isSiteAdmin, id)
 	return err
 }
 
@@ -665,6 +741,12 @@ type UsersListOptions struct {
 
 	Tag string // only include users with this tag
 
+	Username string // only include the user with this username
+
+	// IncludeDeactivated includes users that were deactivated with Deactivate (but not users
+	// that were deleted).
+	IncludeDeactivated bool
+
 	*LimitOffset
 }
 
@@ -690,7 +772,11 @@ func (u *users) List(ctx context.Context, opt *UsersListOptions) (_ []*types.Use
 
 func (*users) listSQL(opt UsersListOptions) (conds []*sqlf.Query) {
 	conds = []*sqlf.Query{sqlf.Sprintf("TRUE")}
-	conds = append(conds, sqlf.Sprintf("deleted_at IS NULL"))
+	if opt.IncludeDeactivated {
+		conds = append(conds, sqlf.Sprintf("(deleted_at IS NULL OR EXISTS (SELECT 1 FROM names WHERE names.user_id=u.id))"))
+	} else {
+		conds = append(conds, sqlf.Sprintf("deleted_at IS NULL"))
+	}
 	if opt.Query != "" {
 		query := "%" + opt.Query + "%"
 		conds = append(conds, sqlf.Sprintf("(username ILIKE %s OR display_name ILIKE %s)", query, query))
@@ -710,6 +796,9 @@ func (*users) listSQL(opt UsersListOptions) (conds []*sqlf.Query) {
 	if opt.Tag != "" {
 		conds = append(conds, sqlf.Sprintf("%s::text = ANY(u.tags)", opt.Tag))
 	}
+	if opt.Username != "" {
+		conds = append(conds, sqlf.Sprintf("u.username=%s", opt.Username))
+	}
 	return conds
 }
 
@@ -726,7 +815,7 @@ func (u *users) getOneBySQL(ctx context.Context, query string, args ...interface
 
 // getBySQL returns users matching the SQL query, if any exist.
 func (*users) getBySQL(ctx context.Context, query string, args ...interface{}) ([]*types.User, error) {
-	rows, err := dbconn.Global.QueryContext(ctx, "SELECT u.id, u.username, u.display_name, u.avatar_url, u.created_at, u.updated_at, u.site_admin, u.passwd IS NOT NULL, u.tags FROM users u "+query, args...)
+	rows, err := dbconn.Global.QueryContext(ctx, "SELECT u.id, u.username, u.display_name, u.avatar_url, u.created_at, u.updated_at, u.site_admin, u.passwd IS NOT NULL, u.tags, u.deleted_at IS NOT NULL FROM users u "+query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -736,7 +825,7 @@ func (*users) getBySQL(ctx context.Context, query string, args ...interface{}) (
 	for rows.Next() {
 		var u types.User
 		var displayName, avatarURL sql.NullString
-		err := rows.Scan(&u.ID, &u.Username, &displayName, &avatarURL, &u.CreatedAt, &u.UpdatedAt, &u.SiteAdmin, &u.BuiltinAuth, pq.Array(&u.Tags))
+		err := rows.Scan(&u.ID, &u.Username, &displayName, &avatarURL, &u.CreatedAt, &u.UpdatedAt, &u.SiteAdmin, &u.BuiltinAuth, pq.Array(&u.Tags), &u.Deactivated)
 		if err != nil {
 			return nil, err
 		}
@@ -16,6 +16,7 @@ import (
 	"github.com/sourcegraph/sourcegraph/internal/db/dbtesting"
 	"github.com/sourcegraph/sourcegraph/internal/db/globalstatedb"
 	"github.com/sourcegraph/sourcegraph/internal/errcode"
+	"github.com/sourcegraph/sourcegraph/internal/extsvc"
 )
 
 // usernamesForTests is a list of test cases containing valid and invalid usernames and org names.
@@ -635,6 +636,144 @@ func TestUsers_Delete(t *testing.T) {
 	}
 }
 
+func TestUsers_DeactivateReactivate(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+	dbtesting.SetupGlobalTestDB(t)
+	ctx := context.Background()
+
+	user, err := Users.Create(ctx, NewUser{Email: "a@a.com", Username: "u", EmailIsVerified: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	org, err := Orgs.Create(ctx, "o", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := OrgMembers.Create(ctx, org.ID, user.ID); err != nil {
+		t.Fatal(err)
+	}
+	_, token, err := AccessTokens.Create(ctx, nil, user.ID, []string{"a"}, "n", user.ID, AccessTokenRestrictions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := Users.Deactivate(ctx, user.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Users.GetByID(ctx, user.ID); !errcode.IsNotFound(err) {
+		t.Errorf("got error %v, want not found", err)
+	}
+	if _, err := AccessTokens.Lookup(ctx, token, "a"); err == nil {
+		t.Error("want error looking up access token of deactivated user")
+	}
+	if members, err := OrgMembers.GetByOrgID(ctx, org.ID); err != nil {
+		t.Fatal(err)
+	} else if len(members) != 0 {
+		t.Errorf("got %d org members, want 0", len(members))
+	}
+
+	// The username and email address remain reserved.
+	if _, err := Users.Create(ctx, NewUser{Username: "u"}); err == nil {
+		t.Error("want error creating user with username of deactivated user")
+	}
+	if _, err := Users.Create(ctx, NewUser{Email: "a@a.com", Username: "u2", EmailIsVerified: true}); err == nil {
+		t.Error("want error creating user with email of deactivated user")
+	}
+
+	// Deactivated users are only listed when requested.
+	users, err := Users.List(ctx, &UsersListOptions{Username: "u"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(users) != 0 {
+		t.Errorf("got %d users, want 0", len(users))
+	}
+	users, err = Users.List(ctx, &UsersListOptions{Username: "u", IncludeDeactivated: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(users) != 1 || !users[0].Deactivated {
+		t.Errorf("got users %+v, want deactivated user", users)
+	}
+
+	if err := Users.Reactivate(ctx, user.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Users.GetByID(ctx, user.ID); err != nil {
+		t.Fatal(err)
+	}
+	if email, _, err := UserEmails.GetPrimaryEmail(ctx, user.ID); err != nil {
+		t.Fatal(err)
+	} else if email != "a@a.com" {
+		t.Errorf("got email %q, want %q", email, "a@a.com")
+	}
+
+	// Deleted users cannot be reactivated.
+	if err := Users.Delete(ctx, user.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := Users.Reactivate(ctx, user.ID); !errcode.IsNotFound(err) {
+		t.Errorf("got error %v, want not found", err)
+	}
+}
+
+func TestUsers_Reactivate_signIn(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+	dbtesting.SetupGlobalTestDB(t)
+	ctx := context.Background()
+
+	spec := extsvc.AccountSpec{
+		ServiceType: "xa",
+		ServiceID:   "xb",
+		ClientID:    "xc",
+		AccountID:   "xd",
+	}
+	userID, err := ExternalAccounts.CreateUserAndSave(ctx, NewUser{Email: "a@a.com", Username: "u", EmailIsVerified: true}, spec, extsvc.AccountData{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, token, err := AccessTokens.Create(ctx, nil, userID, []string{"a"}, "n", userID, AccessTokenRestrictions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := Users.Deactivate(ctx, userID); err != nil {
+		t.Fatal(err)
+	}
+	if err := Users.Reactivate(ctx, userID); err != nil {
+		t.Fatal(err)
+	}
+
+	// Reactivation restores neither access tokens nor external accounts.
+	if _, err := AccessTokens.Lookup(ctx, token, "a"); err == nil {
+		t.Error("want error looking up access token of reactivated user")
+	}
+	if _, err := ExternalAccounts.LookupUserAndSave(ctx, spec, extsvc.AccountData{}); !errcode.IsNotFound(err) {
+		t.Errorf("got error %v, want not found", err)
+	}
+
+	// Signing in again links the external account to the user with the same verified email.
+	user, err := Users.GetByVerifiedEmail(ctx, "a@a.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user.ID != userID {
+		t.Fatalf("got user %d, want %d", user.ID, userID)
+	}
+	if err := ExternalAccounts.AssociateUserAndSave(ctx, user.ID, spec, extsvc.AccountData{}); err != nil {
+		t.Fatal(err)
+	}
+	if gotUserID, err := ExternalAccounts.LookupUserAndSave(ctx, spec, extsvc.AccountData{}); err != nil {
+		t.Fatal(err)
+	} else if gotUserID != userID {
+		t.Errorf("got user %d, want %d", gotUserID, userID)
+	}
+}
+
 func normalizeUsers(users []*types.User) []*types.User {
 	for _, u := range users {
 		u.CreatedAt = u.CreatedAt.Local().Round(time.Second)
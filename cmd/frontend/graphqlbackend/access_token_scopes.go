@@ -0,0 +1,100 @@
+package graphqlbackend
+
+import (
+	"context"
+	"errors"
+
+	"github.com/graph-gophers/graphql-go/trace"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/authz"
+	"github.com/sourcegraph/sourcegraph/internal/api"
+)
+
+// restrictedTokenFields maps the top-level fields that may be resolved by requests authenticated
+// with a restricted access token (i.e., one without the "user:all" scope) to the scope the access
+// token must have. All other top-level fields are denied to restricted access tokens.
+var restrictedTokenFields = map[string]string{
+	"Query.search":             authz.ScopeSearchRead,
+	"Query.repository":         authz.ScopeSearchRead,
+	"Query.repositoryRedirect": authz.ScopeSearchRead,
+
+	"Query.campaigns":                    authz.ScopeCampaignsWrite,
+	"Mutation.createChangesets":          authz.ScopeCampaignsWrite,
+	"Mutation.addChangesetsToCampaign":   authz.ScopeCampaignsWrite,
+	"Mutation.createCampaign":            authz.ScopeCampaignsWrite,
+	"Mutation.createPatchSetFromPatches": authz.ScopeCampaignsWrite,
+	"Mutation.createPatchSetFromRewrite": authz.ScopeCampaignsWrite,
+	"Mutation.updateCampaign":            authz.ScopeCampaignsWrite,
+	"Mutation.retryCampaign":             authz.ScopeCampaignsWrite,
+	"Mutation.deleteCampaign":            authz.ScopeCampaignsWrite,
+	"Mutation.closeCampaign":             authz.ScopeCampaignsWrite,
+	"Mutation.publishCampaign":           authz.ScopeCampaignsWrite,
+	"Mutation.setCampaignRollout":        authz.ScopeCampaignsWrite,
+	"Mutation.pauseCampaignRollout":      authz.ScopeCampaignsWrite,
+	"Mutation.resumeCampaignRollout":     authz.ScopeCampaignsWrite,
+	"Mutation.setCampaignAutoMerge":      authz.ScopeCampaignsWrite,
+	"Mutation.publishChangeset":          authz.ScopeCampaignsWrite,
+	"Mutation.syncChangeset":             authz.ScopeCampaignsWrite,
+}
+
+// tokenScopeTracer wraps a tracer and denies the top-level fields that the access token of the
+// request (if any) does not permit. Nested fields are only reachable through a permitted top-level
+// field, so they are not checked.
+type tokenScopeTracer struct {
+	prometheusTracer
+}
+
+func (t tokenScopeTracer) TraceField(ctx context.Context, label, typeName, fieldName string, trivial bool, args map[string]interface{}) (context.Context, trace.TraceFieldFinishFunc) {
+	ctx, finish := t.prometheusTracer.TraceField(ctx, label, typeName, fieldName, trivial, args)
+	if typeName == "Query" || typeName == "Mutation" {
+		// 🚨 SECURITY: The executor does not call the resolver of a field whose context has an
+		// error, and reports the error for the field instead.
+		if err := checkTokenField(ctx, typeName+"."+fieldName, args); err != nil {
+			return deniedContext{Context: ctx, err: err}, finish
+		}
+	}
+	return ctx, finish
+}
+
+// checkTokenField returns an error if the access token of the request does not permit resolving
+// the given top-level field with the given arguments.
+func checkTokenField(ctx context.Context, field string, args map[string]interface{}) error {
+	restrictions := authz.TokenRestrictionsFromContext(ctx)
+	if restrictions == nil {
+		return nil
+	}
+
+	scope, ok := restrictedTokenFields[field]
+	if !ok {
+		return &authz.ErrTokenScope{Scope: authz.ScopeUserAll}
+	}
+	if err := authz.CheckTokenScope(ctx, scope); err != nil {
+		return err
+	}
+
+	if field == "Query.repository" || field == "Query.repositoryRedirect" {
+		name, ok := args["name"].(string)
+		if !ok {
+			if len(restrictions.RepositoryPatterns) > 0 {
+				return errors.New("access tokens restricted to repositories must look up repositories by name")
+			}
+			return nil
+		}
+		return authz.CheckTokenRepo(ctx, api.RepoName(name))
+	}
+	return nil
+}
+
+var closedChan = func() chan struct{} {
+	c := make(chan struct{})
+	close(c)
+	return c
+}()
+
+// deniedContext is a context that is already done with the given error.
+type deniedContext struct {
+	context.Context
+	err error
+}
+
+func (c deniedContext) Done() <-chan struct{} { return closedChan }
+func (c deniedContext) Err() error            { return c.err }
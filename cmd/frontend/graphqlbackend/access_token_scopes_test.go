@@ -0,0 +1,84 @@
+package graphqlbackend
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/authz"
+	"github.com/sourcegraph/sourcegraph/internal/actor"
+)
+
+func TestCheckTokenField(t *testing.T) {
+	restricted := func(t *testing.T, scopes []string, repositoryPatterns ...string) context.Context {
+		restrictions, err := authz.NewTokenRestrictions(scopes, repositoryPatterns)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return authz.WithTokenRestrictions(context.Background(), restrictions)
+	}
+
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		field   string
+		args    map[string]interface{}
+		wantErr bool
+	}{
+		{name: "unrestricted", ctx: context.Background(), field: "Mutation.deleteUser"},
+		{name: "permitted field", ctx: restricted(t, []string{authz.ScopeSearchRead}), field: "Query.search"},
+		{name: "field requires other scope", ctx: restricted(t, []string{authz.ScopeSearchRead}), field: "Mutation.createCampaign", wantErr: true},
+		{name: "field requires user:all", ctx: restricted(t, []string{authz.ScopeCampaignsWrite}), field: "Query.currentUser", wantErr: true},
+		{
+			name:  "permitted repository",
+			ctx:   restricted(t, []string{authz.ScopeSearchRead}, "^github\\.com/foo/"),
+			field: "Query.repository",
+			args:  map[string]interface{}{"name": "github.com/foo/bar"},
+		},
+		{
+			name:    "denied repository",
+			ctx:     restricted(t, []string{authz.ScopeSearchRead}, "^github\\.com/foo/"),
+			field:   "Query.repository",
+			args:    map[string]interface{}{"name": "github.com/baz/bar"},
+			wantErr: true,
+		},
+		{
+			name:    "repository by clone URL",
+			ctx:     restricted(t, []string{authz.ScopeSearchRead}, "^github\\.com/foo/"),
+			field:   "Query.repository",
+			args:    map[string]interface{}{"cloneURL": "https://github.com/foo/bar"},
+			wantErr: true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := checkTokenField(test.ctx, test.field, test.args)
+			if test.wantErr && err == nil {
+				t.Error("err == nil")
+			} else if !test.wantErr && err != nil {
+				t.Errorf("unexpected error: %s", err)
+			}
+		})
+	}
+}
+
+// 🚨 SECURITY: This tests that the schema does not resolve fields that are not permitted by the
+// access token of the request.
+func TestTokenScopeTracer(t *testing.T) {
+	resetMocks()
+
+	restrictions, err := authz.NewTokenRestrictions([]string{authz.ScopeSearchRead}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := actor.WithActor(context.Background(), &actor.Actor{UID: 1})
+	ctx = authz.WithTokenRestrictions(ctx, restrictions)
+
+	result := mustParseGraphQLSchema(t).Exec(ctx, `mutation { deleteUser(user: "VXNlcjox") { alwaysNil } }`, "", nil)
+	if len(result.Errors) != 1 {
+		t.Fatalf("got %d errors, want 1", len(result.Errors))
+	}
+	want := (&authz.ErrTokenScope{Scope: authz.ScopeUserAll}).Error()
+	if got := result.Errors[0].Message; got != want {
+		t.Errorf("got error %q, want %q", got, want)
+	}
+}
@@ -2,12 +2,17 @@ package graphqlbackend
 
 import (
 	"context"
+	"database/sql"
 	"errors"
 	"fmt"
+	"regexp"
 	"sort"
+	"strconv"
 	"sync"
+	"time"
 
 	graphql "github.com/graph-gophers/graphql-go"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/audit"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/authz"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/backend"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
@@ -17,9 +22,12 @@ import (
 )
 
 type createAccessTokenInput struct {
-	User   graphql.ID
-	Scopes []string
-	Note   string
+	User               graphql.ID
+	Scopes             []string
+	Note               string
+	ExpiresAt          *DateTime
+	Repositories       *[]string
+	RepositoryPatterns *[]string
 }
 
 func (r *schemaResolver) CreateAccessToken(ctx context.Context, args *createAccessTokenInput) (*createAccessTokenResult, error) {
@@ -46,13 +54,13 @@ func (r *schemaResolver) CreateAccessToken(ctx context.Context, args *createAcce
 	}
 
 	// Validate scopes.
-	var hasUserAllScope bool
+	var hasSubjectScope bool
 	seenScope := map[string]struct{}{}
 	sort.Strings(args.Scopes)
 	for _, scope := range args.Scopes {
 		switch scope {
-		case authz.ScopeUserAll:
-			hasUserAllScope = true
+		case authz.ScopeUserAll, authz.ScopeSearchRead, authz.ScopeLSIFUpload, authz.ScopeCampaignsWrite:
+			hasSubjectScope = true
 		case authz.ScopeSiteAdminSudo:
 			// 🚨 SECURITY: Only site admins may create a token with the "site-admin:sudo" scope.
 			if err := backend.CheckCurrentUserIsSiteAdmin(ctx); err != nil {
@@ -67,12 +75,76 @@ func (r *schemaResolver) CreateAccessToken(ctx context.Context, args *createAcce
 		}
 		seenScope[scope] = struct{}{}
 	}
-	if !hasUserAllScope {
-		return nil, fmt.Errorf("all access tokens must have scope %q", authz.ScopeUserAll)
+	if !hasSubjectScope {
+		return nil, fmt.Errorf("all access tokens must have one of the scopes %q", authz.SubjectScopes)
+	}
+	_, hasUserAllScope := seenScope[authz.ScopeUserAll]
+	if _, ok := seenScope[authz.ScopeSiteAdminSudo]; ok && !hasUserAllScope {
+		return nil, fmt.Errorf("access tokens with scope %q must also have scope %q", authz.ScopeSiteAdminSudo, authz.ScopeUserAll)
 	}
 
-	id, token, err := db.AccessTokens.Create(ctx, userID, args.Scopes, args.Note, actor.FromContext(ctx).UID)
-	return &createAccessTokenResult{id: marshalAccessTokenID(id), token: token}, err
+	// Validate restrictions.
+	var restrictions db.AccessTokenRestrictions
+	if args.ExpiresAt != nil {
+		if !args.ExpiresAt.Time.After(time.Now()) {
+			return nil, errors.New("access token expiry must be in the future")
+		}
+		restrictions.ExpiresAt = &args.ExpiresAt.Time
+	}
+	if args.Repositories != nil {
+		for _, name := range *args.Repositories {
+			restrictions.RepositoryPatterns = append(restrictions.RepositoryPatterns, "^"+regexp.QuoteMeta(name)+"$")
+		}
+	}
+	if args.RepositoryPatterns != nil {
+		restrictions.RepositoryPatterns = append(restrictions.RepositoryPatterns, *args.RepositoryPatterns...)
+	}
+	if len(restrictions.RepositoryPatterns) > 0 {
+		for _, scope := range []string{authz.ScopeUserAll, authz.ScopeCampaignsWrite} {
+			if _, ok := seenScope[scope]; ok {
+				return nil, fmt.Errorf("access tokens with scope %q may not be restricted to repositories", scope)
+			}
+		}
+		if _, err := authz.NewTokenRestrictions(args.Scopes, restrictions.RepositoryPatterns); err != nil {
+			return nil, err
+		}
+	}
+
+	// Tokens with the "site-admin:sudo" scope allow acting as any user, so record who created them.
+	if _, ok := seenScope[authz.ScopeSiteAdminSudo]; !ok {
+		id, token, err := db.AccessTokens.Create(ctx, nil, userID, args.Scopes, args.Note, actor.FromContext(ctx).UID, restrictions)
+		if err != nil {
+			return nil, err
+		}
+		return &createAccessTokenResult{id: marshalAccessTokenID(id), token: token}, nil
+	}
+
+	var (
+		id    int64
+		token string
+	)
+	if err := audit.Transact(ctx, func(tx *sql.Tx) (*audit.Event, error) {
+		var err error
+		id, token, err = db.AccessTokens.Create(ctx, tx, userID, args.Scopes, args.Note, actor.FromContext(ctx).UID, restrictions)
+		if err != nil {
+			return nil, err
+		}
+		return &audit.Event{
+			Action:     db.AuditLogActionCreateSudoAccessToken,
+			TargetType: "User",
+			TargetID:   strconv.FormatInt(int64(userID), 10),
+			After: struct {
+				ID        int64      `json:"id"`
+				Scopes    []string   `json:"scopes"`
+				Note      string     `json:"note"`
+				ExpiresAt *time.Time `json:"expiresAt"`
+			}{ID: id, Scopes: args.Scopes, Note: args.Note, ExpiresAt: restrictions.ExpiresAt},
+		}, nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return &createAccessTokenResult{id: marshalAccessTokenID(id), token: token}, nil
 }
 
 type createAccessTokenResult struct {
@@ -129,6 +201,8 @@ func (r *schemaResolver) DeleteAccessToken(ctx context.Context, args *deleteAcce
 
 func (r *siteResolver) AccessTokens(ctx context.Context, args *struct {
 	graphqlutil.ConnectionArgs
+	LastUsedAfter  *DateTime
+	LastUsedBefore *DateTime
 }) (*accessTokenConnectionResolver, error) {
 	// 🚨 SECURITY: Only site admins can list all access tokens.
 	if err := backend.CheckCurrentUserIsSiteAdmin(ctx); err != nil {
@@ -137,6 +211,12 @@ func (r *siteResolver) AccessTokens(ctx context.Context, args *struct {
 
 	var opt db.AccessTokensListOptions
 	args.ConnectionArgs.Set(&opt.LimitOffset)
+	if args.LastUsedAfter != nil {
+		opt.LastUsedAfter = &args.LastUsedAfter.Time
+	}
+	if args.LastUsedBefore != nil {
+		opt.LastUsedBefore = &args.LastUsedBefore.Time
+	}
 	return &accessTokenConnectionResolver{opt: opt}, nil
 }
 
@@ -4,6 +4,7 @@ import (
 	"context"
 	"reflect"
 	"testing"
+	"time"
 
 	graphql "github.com/graph-gophers/graphql-go"
 	"github.com/graph-gophers/graphql-go/gqltesting"
@@ -17,7 +18,7 @@ import (
 // 🚨 SECURITY: This tests that users can't create tokens for users they aren't allowed to do so for.
 func TestMutation_CreateAccessToken(t *testing.T) {
 	mockAccessTokensCreate := func(t *testing.T, wantCreatorUserID int32, wantScopes []string) {
-		db.Mocks.AccessTokens.Create = func(subjectUserID int32, scopes []string, note string, creatorUserID int32) (int64, string, error) {
+		db.Mocks.AccessTokens.Create = func(subjectUserID int32, scopes []string, note string, creatorUserID int32, restrictions db.AccessTokenRestrictions) (int64, string, error) {
 			if want := int32(1); subjectUserID != want {
 				t.Errorf("got %v, want %v", subjectUserID, want)
 			}
@@ -97,6 +98,67 @@ func TestMutation_CreateAccessToken(t *testing.T) {
 		}
 	})
 
+	t.Run("authenticated as user, restricted to repositories", func(t *testing.T) {
+		resetMocks()
+		var gotRestrictions db.AccessTokenRestrictions
+		db.Mocks.AccessTokens.Create = func(subjectUserID int32, scopes []string, note string, creatorUserID int32, restrictions db.AccessTokenRestrictions) (int64, string, error) {
+			if want := []string{authz.ScopeLSIFUpload, authz.ScopeSearchRead}; !reflect.DeepEqual(scopes, want) {
+				t.Errorf("got %q, want %q", scopes, want)
+			}
+			gotRestrictions = restrictions
+			return 1, "t", nil
+		}
+
+		ctx := actor.WithActor(context.Background(), &actor.Actor{UID: 1})
+		expiresAt := time.Now().Add(time.Hour)
+		if _, err := (&schemaResolver{}).CreateAccessToken(ctx, &createAccessTokenInput{
+			User:               uid1GQLID,
+			Scopes:             []string{authz.ScopeSearchRead, authz.ScopeLSIFUpload},
+			Note:               "n",
+			ExpiresAt:          &DateTime{Time: expiresAt},
+			Repositories:       &[]string{"github.com/foo/bar"},
+			RepositoryPatterns: &[]string{"^github\\.com/baz/"},
+		}); err != nil {
+			t.Fatal(err)
+		}
+		if gotRestrictions.ExpiresAt == nil || !gotRestrictions.ExpiresAt.Equal(expiresAt) {
+			t.Errorf("got expiry %v, want %v", gotRestrictions.ExpiresAt, expiresAt)
+		}
+		if want := []string{"^github\\.com/foo/bar$", "^github\\.com/baz/"}; !reflect.DeepEqual(gotRestrictions.RepositoryPatterns, want) {
+			t.Errorf("got repository patterns %q, want %q", gotRestrictions.RepositoryPatterns, want)
+		}
+	})
+
+	t.Run("authenticated as user, using invalid restrictions", func(t *testing.T) {
+		past := DateTime{Time: time.Now().Add(-time.Hour)}
+		for name, input := range map[string]*createAccessTokenInput{
+			"sudo without user:all":                      {Scopes: []string{authz.ScopeSiteAdminSudo}},
+			"expired":                                    {Scopes: []string{authz.ScopeSearchRead}, ExpiresAt: &past},
+			"user:all restricted to repositories":        {Scopes: []string{authz.ScopeUserAll}, Repositories: &[]string{"r"}},
+			"campaigns:write restricted to repositories": {Scopes: []string{authz.ScopeCampaignsWrite}, RepositoryPatterns: &[]string{"r"}},
+			"invalid repository pattern":                 {Scopes: []string{authz.ScopeSearchRead}, RepositoryPatterns: &[]string{"("}},
+		} {
+			t.Run(name, func(t *testing.T) {
+				resetMocks()
+				db.Mocks.Users.GetByCurrentAuthUser = func(ctx context.Context) (*types.User, error) {
+					return &types.User{ID: 1, SiteAdmin: true}, nil
+				}
+				defer func() { db.Mocks.Users.GetByCurrentAuthUser = nil }()
+
+				input.User = uid1GQLID
+				input.Note = "n"
+				ctx := actor.WithActor(context.Background(), &actor.Actor{UID: 1})
+				result, err := (&schemaResolver{}).CreateAccessToken(ctx, input)
+				if err == nil {
+					t.Error("err == nil")
+				}
+				if result != nil {
+					t.Errorf("got result %v, want nil", result)
+				}
+			})
+		}
+	})
+
 	t.Run("authenticated as site admin, using site-admin-only scopes", func(t *testing.T) {
 		resetMocks()
 		mockAccessTokensCreate(t, 1, []string{authz.ScopeSiteAdminSudo, authz.ScopeUserAll})
@@ -104,6 +166,12 @@ func TestMutation_CreateAccessToken(t *testing.T) {
 			return &types.User{ID: 1, SiteAdmin: true}, nil
 		}
 		defer func() { db.Mocks.Users.GetByCurrentAuthUser = nil }()
+		var entry *db.AuditLogEntry
+		db.Mocks.AuditLog.Insert = func(_ context.Context, e *db.AuditLogEntry) error {
+			entry = e
+			return nil
+		}
+		defer func() { db.Mocks.AuditLog.Insert = nil }()
 
 		gqltesting.RunTests(t, []*gqltesting.Test{
 			{
@@ -127,6 +195,15 @@ func TestMutation_CreateAccessToken(t *testing.T) {
 			`,
 			},
 		})
+		if entry == nil {
+			t.Fatal("no audit log entry written")
+		}
+		if entry.Action != db.AuditLogActionCreateSudoAccessToken || entry.ActorUserID != 1 || entry.TargetID != "1" {
+			t.Errorf("got audit log entry %+v", entry)
+		}
+		if want := `{"id":1,"scopes":["site-admin:sudo","user:all"],"note":"n","expiresAt":null}`; string(entry.After) != want {
+			t.Errorf("got audit log entry after %s, want %s", entry.After, want)
+		}
 	})
 
 	t.Run("authenticated as different user who is a site-admin", func(t *testing.T) {
@@ -0,0 +1,229 @@
+package graphqlbackend
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	graphql "github.com/graph-gophers/graphql-go"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/backend"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/graphqlbackend/graphqlutil"
+	"github.com/sourcegraph/sourcegraph/internal/errcode"
+)
+
+func init() {
+	AlertFuncs = append(AlertFuncs, auditLogKeyAlert)
+}
+
+// auditLogKeyAlert warns site admins that the audit log is not tamper-evident if its hashes are not
+// keyed.
+func auditLogKeyAlert(args AlertFuncArgs) []*Alert {
+	if !args.IsSiteAdmin || db.AuditLogKeyed() {
+		return nil
+	}
+	return []*Alert{{
+		TypeValue:    AlertTypeWarning,
+		MessageValue: "The audit log is not tamper-evident, because the `AUDIT_LOG_HMAC_KEY` environment variable is not set. [Set the audit log key](https://docs.sourcegraph.com/admin/audit_log#setting-the-audit-log-key) to detect changes made directly in the database.",
+	}}
+}
+
+func (r *siteResolver) AuditLog(ctx context.Context, args *struct {
+	graphqlutil.ConnectionArgs
+	Actor         *graphql.ID
+	Action        *string
+	TargetType    *string
+	TargetID      *string
+	CreatedAfter  *DateTime
+	CreatedBefore *DateTime
+}) (*auditLogEntryConnectionResolver, error) {
+	// 🚨 SECURITY: Only site admins can view the audit log.
+	if err := backend.CheckCurrentUserIsSiteAdmin(ctx); err != nil {
+		return nil, err
+	}
+
+	var opt db.AuditLogListOptions
+	if args.Actor != nil {
+		var err error
+		opt.ActorUserID, err = UnmarshalUserID(*args.Actor)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if args.Action != nil {
+		opt.Action = *args.Action
+	}
+	if args.TargetType != nil {
+		opt.TargetType = *args.TargetType
+	}
+	if args.TargetID != nil {
+		opt.TargetID = *args.TargetID
+	}
+	if args.CreatedAfter != nil {
+		opt.CreatedAfter = &args.CreatedAfter.Time
+	}
+	if args.CreatedBefore != nil {
+		opt.CreatedBefore = &args.CreatedBefore.Time
+	}
+	args.ConnectionArgs.Set(&opt.LimitOffset)
+	return &auditLogEntryConnectionResolver{opt: opt}, nil
+}
+
+// auditLogEntryConnectionResolver resolves a list of audit log entries.
+//
+// 🚨 SECURITY: When instantiating an auditLogEntryConnectionResolver value, the caller MUST check
+// permissions.
+type auditLogEntryConnectionResolver struct {
+	opt db.AuditLogListOptions
+
+	// cache results because they are used by multiple fields
+	once    sync.Once
+	entries []*db.AuditLogEntry
+	err     error
+}
+
+func (r *auditLogEntryConnectionResolver) compute(ctx context.Context) ([]*db.AuditLogEntry, error) {
+	r.once.Do(func() {
+		opt2 := r.opt
+		if opt2.LimitOffset != nil {
+			tmp := *opt2.LimitOffset
+			opt2.LimitOffset = &tmp
+			opt2.Limit++ // so we can detect if there is a next page
+		}
+
+		r.entries, r.err = db.AuditLog.List(ctx, opt2)
+	})
+	return r.entries, r.err
+}
+
+func (r *auditLogEntryConnectionResolver) Nodes(ctx context.Context) ([]*auditLogEntryResolver, error) {
+	entries, err := r.compute(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if r.opt.LimitOffset != nil && len(entries) > r.opt.Limit {
+		entries = entries[:r.opt.Limit]
+	}
+
+	l := make([]*auditLogEntryResolver, 0, len(entries))
+	for _, entry := range entries {
+		l = append(l, &auditLogEntryResolver{entry: entry})
+	}
+	return l, nil
+}
+
+func (r *auditLogEntryConnectionResolver) TotalCount(ctx context.Context) (int32, error) {
+	count, err := db.AuditLog.Count(ctx, r.opt)
+	return int32(count), err
+}
+
+func (r *auditLogEntryConnectionResolver) PageInfo(ctx context.Context) (*graphqlutil.PageInfo, error) {
+	entries, err := r.compute(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return graphqlutil.HasNextPage(r.opt.LimitOffset != nil && len(entries) > r.opt.Limit), nil
+}
+
+// ExportURL returns the URL of the audit log export (see the httpapi package) with the same
+// filters as the connection.
+func (r *auditLogEntryConnectionResolver) ExportURL() string {
+	q := url.Values{}
+	if r.opt.ActorUserID != 0 {
+		q.Set("actorUserID", strconv.FormatInt(int64(r.opt.ActorUserID), 10))
+	}
+	if r.opt.Action != "" {
+		q.Set("action", r.opt.Action)
+	}
+	if r.opt.TargetType != "" {
+		q.Set("targetType", r.opt.TargetType)
+	}
+	if r.opt.TargetID != "" {
+		q.Set("targetID", r.opt.TargetID)
+	}
+	if r.opt.CreatedAfter != nil {
+		q.Set("createdAfter", r.opt.CreatedAfter.Format(time.RFC3339Nano))
+	}
+	if r.opt.CreatedBefore != nil {
+		q.Set("createdBefore", r.opt.CreatedBefore.Format(time.RFC3339Nano))
+	}
+
+	u := url.URL{Path: "/.api/audit-log/export", RawQuery: q.Encode()}
+	return u.String()
+}
+
+type auditLogEntryResolver struct {
+	entry *db.AuditLogEntry
+}
+
+func (r *auditLogEntryResolver) ID() int32 { return int32(r.entry.ID) }
+
+func (r *auditLogEntryResolver) Actor(ctx context.Context) (*UserResolver, error) {
+	user, err := UserByIDInt32(ctx, r.entry.ActorUserID)
+	if err != nil && errcode.IsNotFound(err) {
+		// Don't throw an error if a user has been deleted.
+		return nil, nil
+	}
+	return user, err
+}
+
+func (r *auditLogEntryResolver) ActorIP() string      { return r.entry.ActorIP }
+func (r *auditLogEntryResolver) ForwardedFor() string { return r.entry.ForwardedFor }
+func (r *auditLogEntryResolver) Action() string       { return r.entry.Action }
+func (r *auditLogEntryResolver) TargetType() string   { return r.entry.TargetType }
+func (r *auditLogEntryResolver) TargetID() string     { return r.entry.TargetID }
+
+func (r *auditLogEntryResolver) Before() (*JSONValue, error) { return auditLogPayload(r.entry.Before) }
+func (r *auditLogEntryResolver) After() (*JSONValue, error)  { return auditLogPayload(r.entry.After) }
+
+func auditLogPayload(m json.RawMessage) (*JSONValue, error) {
+	if m == nil {
+		return nil, nil
+	}
+	var v JSONValue
+	if err := json.Unmarshal(m, &v.Value); err != nil {
+		return nil, err
+	}
+	return &v, nil
+}
+
+func (r *auditLogEntryResolver) CreatedAt() DateTime {
+	return DateTime{Time: r.entry.CreatedAt}
+}
+
+func (r *auditLogEntryResolver) PreviousHash() string { return r.entry.PrevHash }
+func (r *auditLogEntryResolver) Hash() string         { return r.entry.Hash }
+
+func (r *siteResolver) AuditLogVerification(ctx context.Context) (*auditLogVerificationResolver, error) {
+	// 🚨 SECURITY: Only site admins can view the audit log.
+	if err := backend.CheckCurrentUserIsSiteAdmin(ctx); err != nil {
+		return nil, err
+	}
+
+	v, err := db.AuditLog.Verify(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &auditLogVerificationResolver{v: v}, nil
+}
+
+type auditLogVerificationResolver struct {
+	v *db.AuditLogVerification
+}
+
+func (r *auditLogVerificationResolver) Valid() bool { return r.v.FirstInvalidID == 0 }
+
+func (r *auditLogVerificationResolver) EntryCount() int32 { return int32(r.v.EntryCount) }
+
+func (r *auditLogVerificationResolver) Keyed() bool { return r.v.Keyed }
+
+func (r *auditLogVerificationResolver) FirstInvalidEntryID() *int32 {
+	if r.v.FirstInvalidID == 0 {
+		return nil
+	}
+	id := int32(r.v.FirstInvalidID)
+	return &id
+}
@@ -0,0 +1,150 @@
+package graphqlbackend
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/graph-gophers/graphql-go/gqltesting"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/backend"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
+	"github.com/sourcegraph/sourcegraph/internal/actor"
+)
+
+func TestSite_AuditLog(t *testing.T) {
+	t.Run("authenticated as non-admin", func(t *testing.T) {
+		resetMocks()
+		db.Mocks.Users.GetByCurrentAuthUser = func(context.Context) (*types.User, error) {
+			return &types.User{ID: 1}, nil
+		}
+		defer resetMocks()
+
+		ctx := actor.WithActor(context.Background(), &actor.Actor{UID: 1})
+		_, err := (&siteResolver{}).AuditLog(ctx, nil)
+		if want := backend.ErrMustBeSiteAdmin; err != want {
+			t.Errorf("err: want %q but got %v", want, err)
+		}
+		_, err = (&siteResolver{}).AuditLogVerification(ctx)
+		if want := backend.ErrMustBeSiteAdmin; err != want {
+			t.Errorf("err: want %q but got %v", want, err)
+		}
+	})
+
+	resetMocks()
+	db.Mocks.Users.GetByCurrentAuthUser = func(context.Context) (*types.User, error) {
+		return &types.User{ID: 1, SiteAdmin: true}, nil
+	}
+	db.Mocks.Users.GetByID = func(_ context.Context, id int32) (*types.User, error) {
+		return &types.User{ID: id, Username: "alice"}, nil
+	}
+	createdAt := time.Date(2020, 6, 1, 0, 0, 0, 0, time.UTC)
+	var gotOpt db.AuditLogListOptions
+	db.Mocks.AuditLog.List = func(_ context.Context, opt db.AuditLogListOptions) ([]*db.AuditLogEntry, error) {
+		gotOpt = opt
+		return []*db.AuditLogEntry{
+			{ID: 2, ActorUserID: 1, ActorIP: "10.0.0.1", Action: db.AuditLogActionSetSiteAdmin, TargetType: "User", TargetID: "6", Before: json.RawMessage(`{"siteAdmin":false}`), After: json.RawMessage(`{"siteAdmin":true}`), CreatedAt: createdAt, PrevHash: "a", Hash: "b"},
+			{ID: 1, ActorUserID: 1, Action: db.AuditLogActionSetSiteAdmin, TargetType: "User", TargetID: "6", CreatedAt: createdAt, Hash: "a"},
+		}, nil
+	}
+	db.Mocks.AuditLog.Count = func(context.Context, db.AuditLogListOptions) (int, error) {
+		return 2, nil
+	}
+	db.Mocks.AuditLog.Verify = func(context.Context) (*db.AuditLogVerification, error) {
+		return &db.AuditLogVerification{EntryCount: 2}, nil
+	}
+	defer resetMocks()
+
+	gqltesting.RunTests(t, []*gqltesting.Test{
+		{
+			Context: actor.WithActor(context.Background(), &actor.Actor{UID: 1}),
+			Schema:  mustParseGraphQLSchema(t),
+			Query: `
+				{
+					site {
+						auditLog(first: 1, action: SET_SITE_ADMIN, targetType: "User", targetID: "6") {
+							nodes {
+								id
+								actor { username }
+								actorIP
+								action
+								targetType
+								targetID
+								before
+								after
+								createdAt
+								previousHash
+								hash
+							}
+							totalCount
+							pageInfo { hasNextPage }
+							exportURL
+						}
+						auditLogVerification {
+							valid
+							entryCount
+							keyed
+							firstInvalidEntryID
+						}
+					}
+				}
+			`,
+			ExpectedResult: `
+				{
+					"site": {
+						"auditLog": {
+							"nodes": [
+								{
+									"id": 2,
+									"actor": { "username": "alice" },
+									"actorIP": "10.0.0.1",
+									"action": "SET_SITE_ADMIN",
+									"targetType": "User",
+									"targetID": "6",
+									"before": {"siteAdmin": false},
+									"after": {"siteAdmin": true},
+									"createdAt": "2020-06-01T00:00:00Z",
+									"previousHash": "a",
+									"hash": "b"
+								}
+							],
+							"totalCount": 2,
+							"pageInfo": { "hasNextPage": true },
+							"exportURL": "/.api/audit-log/export?action=SET_SITE_ADMIN&targetID=6&targetType=User"
+						},
+						"auditLogVerification": {
+							"valid": true,
+							"entryCount": 2,
+							"keyed": false,
+							"firstInvalidEntryID": null
+						}
+					}
+				}
+			`,
+		},
+	})
+
+	want := db.AuditLogListOptions{
+		Action:      db.AuditLogActionSetSiteAdmin,
+		TargetType:  "User",
+		TargetID:    "6",
+		LimitOffset: &db.LimitOffset{Limit: 2},
+	}
+	if diff := cmp.Diff(want, gotOpt); diff != "" {
+		t.Errorf("list options: %v", diff)
+	}
+}
+
+func TestAuditLogKeyAlert(t *testing.T) {
+	if db.AuditLogKeyed() {
+		t.Skip("AUDIT_LOG_HMAC_KEY is set")
+	}
+	if alerts := auditLogKeyAlert(AlertFuncArgs{IsSiteAdmin: false}); len(alerts) != 0 {
+		t.Errorf("have alerts %+v for a non-site-admin, want none", alerts)
+	}
+	if alerts := auditLogKeyAlert(AlertFuncArgs{IsSiteAdmin: true}); len(alerts) != 1 || alerts[0].TypeValue != AlertTypeWarning {
+		t.Errorf("have alerts %+v for a site admin, want a warning", alerts)
+	}
+}
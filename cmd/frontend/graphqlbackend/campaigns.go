@@ -28,9 +28,22 @@ type CreateCampaignArgs struct {
 		Branch      *string
 		PatchSet    *graphql.ID
 		Draft       *bool
+		Rollout     *CampaignRolloutInput
+		AutoMerge   *CampaignAutoMergeInput
 	}
 }
 
+type CampaignRolloutInput struct {
+	ChangesetsPerWindow int32
+	WindowSeconds       int32
+	Batches             *[][]graphql.ID
+}
+
+type CampaignAutoMergeInput struct {
+	RequiredApprovals int32
+	MergeMethod       *string
+}
+
 type UpdateCampaignArgs struct {
 	Input struct {
 		ID          graphql.ID
@@ -52,6 +65,18 @@ type PatchInput struct {
 	Patch        string
 }
 
+type CreatePatchSetFromRewriteArgs struct {
+	Query   string
+	Rewrite RewriteSpecificationInput
+}
+
+type RewriteSpecificationInput struct {
+	MatchTemplate    string
+	RewriteTemplate  string
+	FileExtension    *string
+	DirectoryExclude *string
+}
+
 type ListCampaignArgs struct {
 	First       *int32
 	State       *string
@@ -83,6 +108,24 @@ type PublishCampaignArgs struct {
 	Campaign graphql.ID
 }
 
+type SetCampaignRolloutArgs struct {
+	Campaign graphql.ID
+	Rollout  *CampaignRolloutInput
+}
+
+type PauseCampaignRolloutArgs struct {
+	Campaign graphql.ID
+}
+
+type ResumeCampaignRolloutArgs struct {
+	Campaign graphql.ID
+}
+
+type SetCampaignAutoMergeArgs struct {
+	Campaign  graphql.ID
+	AutoMerge *CampaignAutoMergeInput
+}
+
 type PublishChangesetArgs struct {
 	Patch graphql.ID
 }
@@ -100,6 +143,10 @@ type CampaignsResolver interface {
 	RetryCampaign(ctx context.Context, args *RetryCampaignArgs) (CampaignResolver, error)
 	CloseCampaign(ctx context.Context, args *CloseCampaignArgs) (CampaignResolver, error)
 	PublishCampaign(ctx context.Context, args *PublishCampaignArgs) (CampaignResolver, error)
+	SetCampaignRollout(ctx context.Context, args *SetCampaignRolloutArgs) (CampaignResolver, error)
+	PauseCampaignRollout(ctx context.Context, args *PauseCampaignRolloutArgs) (CampaignResolver, error)
+	ResumeCampaignRollout(ctx context.Context, args *ResumeCampaignRolloutArgs) (CampaignResolver, error)
+	SetCampaignAutoMerge(ctx context.Context, args *SetCampaignAutoMergeArgs) (CampaignResolver, error)
 	PublishChangeset(ctx context.Context, args *PublishChangesetArgs) (*EmptyResponse, error)
 	SyncChangeset(ctx context.Context, args *SyncChangesetArgs) (*EmptyResponse, error)
 
@@ -110,6 +157,7 @@ type CampaignsResolver interface {
 	AddChangesetsToCampaign(ctx context.Context, args *AddChangesetsToCampaignArgs) (CampaignResolver, error)
 
 	CreatePatchSetFromPatches(ctx context.Context, args CreatePatchSetFromPatchesArgs) (PatchSetResolver, error)
+	CreatePatchSetFromRewrite(ctx context.Context, args CreatePatchSetFromRewriteArgs) (PatchSetResolver, error)
 	PatchSetByID(ctx context.Context, id graphql.ID) (PatchSetResolver, error)
 
 	PatchByID(ctx context.Context, id graphql.ID) (PatchResolver, error)
@@ -151,6 +199,22 @@ func (defaultCampaignsResolver) PublishCampaign(ctx context.Context, args *Publi
 	return nil, campaignsOnlyInEnterprise
 }
 
+func (defaultCampaignsResolver) SetCampaignRollout(ctx context.Context, args *SetCampaignRolloutArgs) (CampaignResolver, error) {
+	return nil, campaignsOnlyInEnterprise
+}
+
+func (defaultCampaignsResolver) PauseCampaignRollout(ctx context.Context, args *PauseCampaignRolloutArgs) (CampaignResolver, error) {
+	return nil, campaignsOnlyInEnterprise
+}
+
+func (defaultCampaignsResolver) ResumeCampaignRollout(ctx context.Context, args *ResumeCampaignRolloutArgs) (CampaignResolver, error) {
+	return nil, campaignsOnlyInEnterprise
+}
+
+func (defaultCampaignsResolver) SetCampaignAutoMerge(ctx context.Context, args *SetCampaignAutoMergeArgs) (CampaignResolver, error) {
+	return nil, campaignsOnlyInEnterprise
+}
+
 func (defaultCampaignsResolver) PublishChangeset(ctx context.Context, args *PublishChangesetArgs) (*EmptyResponse, error) {
 	return nil, campaignsOnlyInEnterprise
 }
@@ -179,6 +243,10 @@ func (defaultCampaignsResolver) CreatePatchSetFromPatches(ctx context.Context, a
 	return nil, campaignsOnlyInEnterprise
 }
 
+func (defaultCampaignsResolver) CreatePatchSetFromRewrite(ctx context.Context, args CreatePatchSetFromRewriteArgs) (PatchSetResolver, error) {
+	return nil, campaignsOnlyInEnterprise
+}
+
 func (defaultCampaignsResolver) PatchSetByID(ctx context.Context, id graphql.ID) (PatchSetResolver, error) {
 	return nil, campaignsOnlyInEnterprise
 }
@@ -218,6 +286,23 @@ type CampaignResolver interface {
 	ClosedAt() *DateTime
 	PublishedAt(ctx context.Context) (*DateTime, error)
 	Patches(ctx context.Context, args *graphqlutil.ConnectionArgs) PatchConnectionResolver
+	Rollout(ctx context.Context) (CampaignRolloutResolver, error)
+	AutoMerge() CampaignAutoMergeResolver
+}
+
+type CampaignAutoMergeResolver interface {
+	RequiredApprovals() int32
+	MergeMethod() string
+}
+
+type CampaignRolloutResolver interface {
+	ChangesetsPerWindow() *int32
+	WindowSeconds() *int32
+	Batches(ctx context.Context) ([][]*RepositoryResolver, error)
+	Paused() bool
+	QueuedCount() int32
+	CurrentBatch() *int32
+	NextReleaseAt() *DateTime
 }
 
 type CampaignsConnectionResolver interface {
@@ -312,6 +397,8 @@ type PatchSetResolver interface {
 	Patches(ctx context.Context, args *graphqlutil.ConnectionArgs) PatchConnectionResolver
 
 	PreviewURL() string
+
+	Status(ctx context.Context) (BackgroundProcessStatus, error)
 }
 
 type PreviewFileDiff interface {
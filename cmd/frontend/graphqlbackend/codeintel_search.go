@@ -0,0 +1,384 @@
+package graphqlbackend
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/sourcegraph/go-langserver/pkg/lsp"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/backend"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/graphqlbackend/graphqlutil"
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/search"
+	"github.com/sourcegraph/sourcegraph/internal/symbols/protocol"
+	"github.com/src-d/enry/v2"
+)
+
+const (
+	// maxSearchBasedDefinitions is the maximum number of symbols requested
+	// from the symbols service when looking for definitions.
+	maxSearchBasedDefinitions = 100
+
+	// maxSearchBasedReferenceFiles is the maximum number of files searched
+	// for references.
+	maxSearchBasedReferenceFiles = 500
+
+	// searchBasedReferencesTimeout limits how long searcher may take to
+	// fetch the repository archive when looking for references.
+	searchBasedReferencesTimeout = 10 * time.Second
+)
+
+// lineCommentPrefixes maps languages (as named by enry) to the prefixes of
+// their line comments. References after a line comment prefix are dropped.
+var lineCommentPrefixes = map[string][]string{
+	"C":           {"//"},
+	"C#":          {"//"},
+	"C++":         {"//"},
+	"Go":          {"//"},
+	"Java":        {"//"},
+	"JavaScript":  {"//"},
+	"Kotlin":      {"//"},
+	"Objective-C": {"//"},
+	"PHP":         {"//", "#"},
+	"Python":      {"#"},
+	"Ruby":        {"#"},
+	"Rust":        {"//"},
+	"Scala":       {"//"},
+	"Shell":       {"#"},
+	"Swift":       {"//"},
+	"TSX":         {"//"},
+	"TypeScript":  {"//"},
+	"Haskell":     {"--"},
+	"Lua":         {"--"},
+	"Clojure":     {";"},
+	"Emacs Lisp":  {";"},
+}
+
+// extraIdentifierRunes maps languages (as named by enry) to the runes besides
+// letters, digits and underscores that their identifiers may contain.
+var extraIdentifierRunes = map[string]string{
+	"JavaScript": "$",
+	"TSX":        "$",
+	"TypeScript": "$",
+	"PHP":        "$",
+	"Clojure":    "-?!*",
+	"Emacs Lisp": "-?!*",
+	"CSS":        "-",
+	"SCSS":       "-",
+}
+
+// searchBasedCodeIntelResolver answers code intelligence queries for a file
+// with search-based heuristics. It is used when no LSIF upload can answer
+// them. Definitions are symbols with the name of the identifier at the queried
+// position, and references are word matches of that name, both in files of the
+// same language in the same repository at the same commit. All locations it
+// returns are imprecise.
+type searchBasedCodeIntelResolver struct {
+	entry    *GitTreeEntryResolver
+	language string
+}
+
+var _ LSIFQueryResolver = &searchBasedCodeIntelResolver{}
+
+// newSearchBasedCodeIntelResolver returns a search-based resolver for the
+// file, or nil if the language of the file is unknown.
+func newSearchBasedCodeIntelResolver(entry *GitTreeEntryResolver) *searchBasedCodeIntelResolver {
+	language, _ := enry.GetLanguageByExtension(entry.Path())
+	if language == "" {
+		return nil
+	}
+	return &searchBasedCodeIntelResolver{entry: entry, language: language}
+}
+
+func (r *searchBasedCodeIntelResolver) Definitions(ctx context.Context, args *LSIFQueryPositionArgs) (LocationConnectionResolver, error) {
+	word, _, err := r.identifierAt(ctx, args)
+	if err != nil || word == "" {
+		return &searchBasedLocationConnectionResolver{}, err
+	}
+
+	symbols, err := r.definitions(ctx, word)
+	if err != nil {
+		return nil, err
+	}
+	locations := make([]LocationResolver, 0, len(symbols))
+	for _, s := range symbols {
+		locations = append(locations, r.location(s.Path, symbolRange(s)))
+	}
+	return &searchBasedLocationConnectionResolver{locations: locations}, nil
+}
+
+func (r *searchBasedCodeIntelResolver) References(ctx context.Context, args *LSIFPagedQueryPositionArgs) (LocationConnectionResolver, error) {
+	offset := 0
+	if args.After != nil {
+		var err error
+		offset, err = strconv.Atoi(*args.After)
+		if err != nil || offset < 0 {
+			return nil, fmt.Errorf("invalid cursor %q", *args.After)
+		}
+	}
+
+	word, _, err := r.identifierAt(ctx, &args.LSIFQueryPositionArgs)
+	if err != nil || word == "" {
+		return &searchBasedLocationConnectionResolver{}, err
+	}
+
+	references, err := r.references(ctx, word)
+	if err != nil {
+		return nil, err
+	}
+
+	if offset > len(references) {
+		offset = len(references)
+	}
+	references = references[offset:]
+	endCursor := ""
+	if args.First != nil && len(references) > int(*args.First) {
+		references = references[:*args.First]
+		endCursor = strconv.Itoa(offset + int(*args.First))
+	}
+
+	locations := make([]LocationResolver, 0, len(references))
+	for _, ref := range references {
+		locations = append(locations, r.location(ref.path, ref.lspRange))
+	}
+	return &searchBasedLocationConnectionResolver{locations: locations, endCursor: endCursor}, nil
+}
+
+func (r *searchBasedCodeIntelResolver) Hover(ctx context.Context, args *LSIFQueryPositionArgs) (HoverResolver, error) {
+	word, wordRange, err := r.identifierAt(ctx, args)
+	if err != nil || word == "" {
+		return nil, err
+	}
+
+	symbols, err := r.definitions(ctx, word)
+	if err != nil || len(symbols) == 0 {
+		return nil, err
+	}
+
+	// The symbols service returns the definition's line as a ctags search
+	// pattern, e.g. "/^func NewClient() *Client {$/".
+	line := strings.TrimSuffix(strings.TrimPrefix(symbols[0].Pattern, "/^"), "$/")
+	line = strings.TrimSpace(strings.Replace(line, `\/`, `/`, -1))
+	if line == "" {
+		return nil, nil
+	}
+
+	return &searchBasedHoverResolver{
+		text:     fmt.Sprintf("```%s\n%s\n```", strings.ToLower(r.language), line),
+		lspRange: wordRange,
+	}, nil
+}
+
+// identifierAt returns the identifier at the position in the file and its
+// range, or "" if there is none.
+func (r *searchBasedCodeIntelResolver) identifierAt(ctx context.Context, args *LSIFQueryPositionArgs) (string, lsp.Range, error) {
+	content, err := r.entry.Content(ctx)
+	if err != nil {
+		return "", lsp.Range{}, err
+	}
+	lines := strings.Split(content, "\n")
+	if args.Line < 0 || int(args.Line) >= len(lines) {
+		return "", lsp.Range{}, nil
+	}
+	line := []rune(lines[args.Line])
+	start, end := identifierBounds(line, int(args.Character), r.language)
+	if start == end {
+		return "", lsp.Range{}, nil
+	}
+	return string(line[start:end]), lsp.Range{
+		Start: lsp.Position{Line: int(args.Line), Character: start},
+		End:   lsp.Position{Line: int(args.Line), Character: end},
+	}, nil
+}
+
+// identifierBounds returns the start and end of the identifier in line that
+// contains the rune at character, or 0 and 0 if there is none.
+func identifierBounds(line []rune, character int, language string) (start, end int) {
+	if character < 0 || character >= len(line) || !isIdentifierRune(line[character], language) {
+		return 0, 0
+	}
+	start, end = character, character
+	for start > 0 && isIdentifierRune(line[start-1], language) {
+		start--
+	}
+	for end < len(line) && isIdentifierRune(line[end], language) {
+		end++
+	}
+	return start, end
+}
+
+func isIdentifierRune(r rune, language string) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' || strings.ContainsRune(extraIdentifierRunes[language], r)
+}
+
+// includePatterns returns the path patterns that match files of the language
+// of the file.
+func (r *searchBasedCodeIntelResolver) includePatterns() ([]string, error) {
+	includePatterns, _, err := langIncludeExcludePatterns([]string{r.language}, nil)
+	return includePatterns, err
+}
+
+// definitions returns the symbols named word, with those in the file first
+// and those in the same directory next.
+func (r *searchBasedCodeIntelResolver) definitions(ctx context.Context, word string) ([]protocol.Symbol, error) {
+	includePatterns, err := r.includePatterns()
+	if err != nil {
+		return nil, err
+	}
+
+	args := search.SymbolsParameters{
+		Repo:            r.entry.Repository().repo.Name,
+		CommitID:        api.CommitID(r.entry.Commit().OID()),
+		Query:           "^" + regexp.QuoteMeta(word) + "$",
+		IsRegExp:        true,
+		IsCaseSensitive: true,
+		IncludePatterns: includePatterns,
+		First:           maxSearchBasedDefinitions,
+	}
+	var symbols []protocol.Symbol
+	if mockListSymbols != nil {
+		symbols, err = mockListSymbols(ctx, args)
+	} else {
+		symbols, err = backend.Symbols.ListTags(ctx, args)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(symbols, func(i, j int) bool {
+		return r.proximity(symbols[i].Path) < r.proximity(symbols[j].Path)
+	})
+	return symbols, nil
+}
+
+// proximity orders paths by how close they are to the file: the file itself
+// first, then files in the same directory, then all others.
+func (r *searchBasedCodeIntelResolver) proximity(p string) int {
+	switch {
+	case p == r.entry.Path():
+		return 0
+	case path.Dir(p) == path.Dir(r.entry.Path()):
+		return 1
+	default:
+		return 2
+	}
+}
+
+type searchBasedReference struct {
+	path     string
+	lspRange lsp.Range
+}
+
+// references returns the word matches of word that aren't in line comments,
+// with those in the file first and those in the same directory next.
+func (r *searchBasedCodeIntelResolver) references(ctx context.Context, word string) ([]searchBasedReference, error) {
+	includePatterns, err := r.includePatterns()
+	if err != nil {
+		return nil, err
+	}
+
+	repo, err := backend.CachedGitRepo(ctx, r.entry.Repository().repo)
+	if err != nil {
+		return nil, err
+	}
+	fileMatches, _, err := textSearch(ctx, search.SearcherURLs(), *repo, api.CommitID(r.entry.Commit().OID()), &search.TextPatternInfo{
+		Pattern:                `\b` + regexp.QuoteMeta(word) + `\b`,
+		IsRegExp:               true,
+		IsCaseSensitive:        true,
+		FileMatchLimit:         maxSearchBasedReferenceFiles,
+		IncludePatterns:        includePatterns,
+		PathPatternsAreRegExps: true,
+		PatternMatchesContent:  true,
+	}, searchBasedReferencesTimeout, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(fileMatches, func(i, j int) bool {
+		pi, pj := r.proximity(fileMatches[i].JPath), r.proximity(fileMatches[j].JPath)
+		if pi != pj {
+			return pi < pj
+		}
+		return fileMatches[i].JPath < fileMatches[j].JPath
+	})
+
+	var references []searchBasedReference
+	for _, fm := range fileMatches {
+		for _, lm := range fm.JLineMatches {
+			for _, ol := range lm.JOffsetAndLengths {
+				if r.inLineComment(lm.JPreview, int(ol[0])) {
+					continue
+				}
+				references = append(references, searchBasedReference{
+					path: fm.JPath,
+					lspRange: lsp.Range{
+						Start: lsp.Position{Line: int(lm.JLineNumber), Character: int(ol[0])},
+						End:   lsp.Position{Line: int(lm.JLineNumber), Character: int(ol[0] + ol[1])},
+					},
+				})
+			}
+		}
+	}
+	return references, nil
+}
+
+// inLineComment reports whether the rune at character of line is in a line
+// comment. String literals aren't taken into account.
+func (r *searchBasedCodeIntelResolver) inLineComment(line string, character int) bool {
+	// Searcher reports offsets in runes.
+	i := 0
+	for n := 0; n < character && i < len(line); n++ {
+		_, size := utf8.DecodeRuneInString(line[i:])
+		i += size
+	}
+	for _, prefix := range lineCommentPrefixes[r.language] {
+		if strings.Contains(line[:i], prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *searchBasedCodeIntelResolver) location(p string, lspRange lsp.Range) LocationResolver {
+	return &locationResolver{
+		resource:  &GitTreeEntryResolver{commit: r.entry.Commit(), stat: CreateFileInfo(p, false)},
+		lspRange:  &lspRange,
+		imprecise: true,
+	}
+}
+
+type searchBasedLocationConnectionResolver struct {
+	locations []LocationResolver
+	endCursor string
+}
+
+var _ LocationConnectionResolver = &searchBasedLocationConnectionResolver{}
+
+func (r *searchBasedLocationConnectionResolver) Nodes(ctx context.Context) ([]LocationResolver, error) {
+	return r.locations, nil
+}
+
+func (r *searchBasedLocationConnectionResolver) PageInfo(ctx context.Context) (*graphqlutil.PageInfo, error) {
+	if r.endCursor != "" {
+		return graphqlutil.NextPageCursor(r.endCursor), nil
+	}
+	return graphqlutil.HasNextPage(false), nil
+}
+
+type searchBasedHoverResolver struct {
+	text     string
+	lspRange lsp.Range
+}
+
+var _ HoverResolver = &searchBasedHoverResolver{}
+
+func (r *searchBasedHoverResolver) Markdown() MarkdownResolver { return NewMarkdownResolver(r.text) }
+
+func (r *searchBasedHoverResolver) Range() RangeResolver { return NewRangeResolver(r.lspRange) }
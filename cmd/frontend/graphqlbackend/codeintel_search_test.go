@@ -0,0 +1,197 @@
+package graphqlbackend
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/gitserver"
+	"github.com/sourcegraph/sourcegraph/internal/search"
+	"github.com/sourcegraph/sourcegraph/internal/symbols/protocol"
+	"github.com/sourcegraph/sourcegraph/internal/vcs/git"
+)
+
+const searchBasedCodeIntelTestFile = `package client
+
+// NewClient returns a client.
+func NewClient() *Client {
+	return &Client{}
+}
+`
+
+func setupSearchBasedCodeIntelTest(t *testing.T) *searchBasedCodeIntelResolver {
+	git.Mocks.ReadFile = func(commit api.CommitID, name string) ([]byte, error) {
+		if commit != "c" || name != "pkg/client/client.go" {
+			t.Errorf("unexpected file %s@%s", name, commit)
+		}
+		return []byte(searchBasedCodeIntelTestFile), nil
+	}
+	mockListSymbols = func(ctx context.Context, args search.SymbolsParameters) ([]protocol.Symbol, error) {
+		if args.Query != "^NewClient$" || !args.IsRegExp || !args.IsCaseSensitive {
+			t.Errorf("unexpected symbols query %+v", args)
+		}
+		if diff := cmp.Diff([]string{`\.go$`}, args.IncludePatterns); diff != "" {
+			t.Errorf("unexpected include patterns (-want +have):\n%s", diff)
+		}
+		return []protocol.Symbol{
+			{Name: "NewClient", Path: "other/client.go", Line: 7, Pattern: `/^func NewClient() *other.Client {$/`},
+			{Name: "NewClient", Path: "pkg/client/client.go", Line: 4, Pattern: `/^func NewClient() *Client {$/`},
+		}, nil
+	}
+	mockTextSearch = func(ctx context.Context, repo gitserver.Repo, commit api.CommitID, p *search.TextPatternInfo, fetchTimeout time.Duration) ([]*FileMatchResolver, bool, error) {
+		if p.Pattern != `\bNewClient\b` || !p.IsRegExp || !p.IsCaseSensitive {
+			t.Errorf("unexpected text search %+v", p)
+		}
+		return []*FileMatchResolver{
+			{JPath: "main.go", JLineMatches: []*lineMatch{
+				{JPreview: "\tc := client.NewClient()", JLineNumber: 9, JOffsetAndLengths: [][2]int32{{13, 9}}},
+			}},
+			{JPath: "pkg/client/client.go", JLineMatches: []*lineMatch{
+				{JPreview: "// NewClient returns a client.", JLineNumber: 2, JOffsetAndLengths: [][2]int32{{3, 9}}},
+				{JPreview: "func NewClient() *Client {", JLineNumber: 3, JOffsetAndLengths: [][2]int32{{5, 9}}},
+			}},
+		}, false, nil
+	}
+
+	return newSearchBasedCodeIntelResolver(&GitTreeEntryResolver{
+		commit: &GitCommitResolver{
+			repo: &RepositoryResolver{repo: &types.Repo{Name: "github.com/foo/bar"}},
+			oid:  "c",
+		},
+		stat: CreateFileInfo("pkg/client/client.go", false),
+	})
+}
+
+func resetSearchBasedCodeIntelMocks() {
+	git.ResetMocks()
+	mockListSymbols = nil
+	mockTextSearch = nil
+}
+
+// locationStrings formats locations as path:line:character-character.
+func locationStrings(t *testing.T, connection LocationConnectionResolver) []string {
+	locations, err := connection.Nodes(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var have []string
+	for _, l := range locations {
+		if !l.Imprecise() {
+			t.Errorf("location %s is not imprecise", l.Resource().Path())
+		}
+		r := l.Range()
+		have = append(have, l.Resource().Path()+":"+r.urlFragment())
+	}
+	return have
+}
+
+func TestSearchBasedCodeIntel_Definitions(t *testing.T) {
+	defer resetSearchBasedCodeIntelMocks()
+	r := setupSearchBasedCodeIntelTest(t)
+
+	// The position of "NewClient" in "func NewClient() *Client {".
+	connection, err := r.Definitions(context.Background(), &LSIFQueryPositionArgs{Line: 3, Character: 7})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"pkg/client/client.go:4:6-4:15", // the same file first
+		"other/client.go:7:6-7:15",
+	}
+	if diff := cmp.Diff(want, locationStrings(t, connection)); diff != "" {
+		t.Errorf("unexpected definitions (-want +have):\n%s", diff)
+	}
+
+	// A position without an identifier has no definitions.
+	connection, err = r.Definitions(context.Background(), &LSIFQueryPositionArgs{Line: 3, Character: 4})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if have := locationStrings(t, connection); len(have) != 0 {
+		t.Errorf("have definitions %v, want none", have)
+	}
+}
+
+func TestSearchBasedCodeIntel_References(t *testing.T) {
+	defer resetSearchBasedCodeIntelMocks()
+	r := setupSearchBasedCodeIntelTest(t)
+
+	first := int32(1)
+	args := &LSIFPagedQueryPositionArgs{LSIFQueryPositionArgs: LSIFQueryPositionArgs{Line: 3, Character: 5}}
+	args.First = &first
+	connection, err := r.References(context.Background(), args)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The match in the comment is dropped.
+	if diff := cmp.Diff([]string{"pkg/client/client.go:4:6-4:15"}, locationStrings(t, connection)); diff != "" {
+		t.Errorf("unexpected references (-want +have):\n%s", diff)
+	}
+	pageInfo, err := connection.PageInfo(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !pageInfo.HasNextPage() || pageInfo.EndCursor() == nil {
+		t.Fatal("want next page")
+	}
+
+	args.After = pageInfo.EndCursor()
+	connection, err = r.References(context.Background(), args)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([]string{"main.go:10:14-10:23"}, locationStrings(t, connection)); diff != "" {
+		t.Errorf("unexpected references (-want +have):\n%s", diff)
+	}
+}
+
+func TestSearchBasedCodeIntel_Hover(t *testing.T) {
+	defer resetSearchBasedCodeIntelMocks()
+	r := setupSearchBasedCodeIntelTest(t)
+
+	hover, err := r.Hover(context.Background(), &LSIFQueryPositionArgs{Line: 3, Character: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hover == nil {
+		t.Fatal("hover == nil")
+	}
+	if want := "```go\nfunc NewClient() *Client {\n```"; hover.Markdown().Text() != want {
+		t.Errorf("have hover %q, want %q", hover.Markdown().Text(), want)
+	}
+	if have := hover.Range().(*rangeResolver).urlFragment(); have != "4:6-4:15" {
+		t.Errorf("have range %s, want 4:6-4:15", have)
+	}
+}
+
+func TestIdentifierBounds(t *testing.T) {
+	tests := []struct {
+		line      string
+		character int
+		language  string
+		want      string
+	}{
+		{line: "x := foo.Bar(baz)", character: 10, language: "Go", want: "Bar"},
+		{line: "x := foo.Bar(baz)", character: 8, language: "Go", want: ""},
+		{line: "const $el = é_x;", character: 7, language: "JavaScript", want: "$el"},
+		{line: "const $el = é_x;", character: 13, language: "JavaScript", want: "é_x"},
+		{line: "(defn valid-name? [x])", character: 9, language: "Clojure", want: "valid-name?"},
+		{line: "x", character: 5, language: "Go", want: ""},
+	}
+	for _, test := range tests {
+		line := []rune(test.line)
+		start, end := identifierBounds(line, test.character, test.language)
+		if have := string(line[start:end]); have != test.want {
+			t.Errorf("%q at %d: have %q, want %q", test.line, test.character, have, test.want)
+		}
+	}
+}
+
+func TestNewSearchBasedCodeIntelResolver_unknownLanguage(t *testing.T) {
+	if r := newSearchBasedCodeIntelResolver(&GitTreeEntryResolver{stat: CreateFileInfo("LICENSE", false)}); r != nil {
+		t.Errorf("have resolver for file of unknown language, want nil")
+	}
+}
@@ -2,6 +2,7 @@ package graphqlbackend
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
 	"os"
 	"strconv"
@@ -11,6 +12,7 @@ import (
 
 	graphql "github.com/graph-gophers/graphql-go"
 	"github.com/pkg/errors"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/audit"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/backend"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/graphqlbackend/graphqlutil"
@@ -44,7 +46,17 @@ func (r *schemaResolver) AddExternalService(ctx context.Context, args *struct {
 		Config:      args.Input.Config,
 	}
 
-	if err := db.ExternalServices.Create(ctx, conf.Get, externalService); err != nil {
+	if err := audit.Transact(ctx, func(tx *sql.Tx) (*audit.Event, error) {
+		if err := db.ExternalServices.Create(ctx, tx, conf.Get, externalService); err != nil {
+			return nil, err
+		}
+		return &audit.Event{
+			Action:     db.AuditLogActionAddExternalService,
+			TargetType: "ExternalService",
+			TargetID:   strconv.FormatInt(externalService.ID, 10),
+			After:      externalServiceAuditState(externalService),
+		}, nil
+	}); err != nil {
 		return nil, err
 	}
 
@@ -80,12 +92,35 @@ func (*schemaResolver) UpdateExternalService(ctx context.Context, args *struct {
 		return nil, fmt.Errorf("blank external service configuration is invalid (must be valid JSONC)")
 	}
 
+	before, err := db.ExternalServices.GetByID(ctx, externalServiceID)
+	if err != nil {
+		return nil, err
+	}
+
 	ps := conf.Get().AuthProviders
 	update := &db.ExternalServiceUpdate{
 		DisplayName: args.Input.DisplayName,
 		Config:      args.Input.Config,
 	}
-	if err := db.ExternalServices.Update(ctx, ps, externalServiceID, update); err != nil {
+	if err := audit.Transact(ctx, func(tx *sql.Tx) (*audit.Event, error) {
+		if err := db.ExternalServices.Update(ctx, tx, ps, externalServiceID, update); err != nil {
+			return nil, err
+		}
+		after := *before
+		if update.DisplayName != nil {
+			after.DisplayName = *update.DisplayName
+		}
+		if update.Config != nil {
+			after.Config = *update.Config
+		}
+		return &audit.Event{
+			Action:     db.AuditLogActionUpdateExternalService,
+			TargetType: "ExternalService",
+			TargetID:   strconv.FormatInt(externalServiceID, 10),
+			Before:     externalServiceAuditState(before),
+			After:      externalServiceAuditState(&after),
+		}, nil
+	}); err != nil {
 		return nil, err
 	}
 
@@ -102,6 +137,16 @@ func (*schemaResolver) UpdateExternalService(ctx context.Context, args *struct {
 	return res, nil
 }
 
+// externalServiceAuditState returns the description of the external service that is recorded in
+// the audit log. Secrets in its configuration are redacted.
+func externalServiceAuditState(svc *types.ExternalService) interface{} {
+	return struct {
+		Kind        string      `json:"kind"`
+		DisplayName string      `json:"displayName"`
+		Config      interface{} `json:"config"`
+	}{Kind: svc.Kind, DisplayName: svc.DisplayName, Config: audit.RedactedConfig(svc.Config)}
+}
+
 // Eagerly trigger a repo-updater sync.
 func syncExternalService(ctx context.Context, svc *types.ExternalService) error {
 	// Only give 5s to validate external service sync. Usually if there is a
@@ -146,7 +191,17 @@ func (*schemaResolver) DeleteExternalService(ctx context.Context, args *struct {
 		return nil, err
 	}
 
-	if err := db.ExternalServices.Delete(ctx, id); err != nil {
+	if err := audit.Transact(ctx, func(tx *sql.Tx) (*audit.Event, error) {
+		if err := db.ExternalServices.Delete(ctx, tx, id); err != nil {
+			return nil, err
+		}
+		return &audit.Event{
+			Action:     db.AuditLogActionDeleteExternalService,
+			TargetType: "ExternalService",
+			TargetID:   strconv.FormatInt(id, 10),
+			Before:     externalServiceAuditState(externalService),
+		}, nil
+	}); err != nil {
 		return nil, err
 	}
 	now := time.Now()
@@ -6,6 +6,7 @@ import (
 
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/backend"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/graphqlbackend/graphqlutil"
+	"github.com/sourcegraph/sourcegraph/internal/gitserver/protocol"
 	"github.com/sourcegraph/sourcegraph/internal/vcs/git"
 )
 
@@ -20,6 +21,10 @@ type gitCommitConnectionResolver struct {
 
 	repo *RepositoryResolver
 
+	// crossRepoBase is the base commit of a cross-repository comparison whose
+	// commits are listed, if any.
+	crossRepoBase protocol.CrossRepoBase
+
 	// cache results because it is used by multiple fields
 	once    sync.Once
 	commits []*git.Commit
@@ -53,7 +58,9 @@ func (r *gitCommitConnectionResolver) compute(ctx context.Context) ([]*git.Commi
 		if err != nil {
 			return nil, err
 		}
-		return git.Commits(ctx, *cachedRepo, git.CommitsOptions{
+		gitRepo := *cachedRepo
+		gitRepo.CrossRepoBase = r.crossRepoBase
+		return git.Commits(ctx, gitRepo, git.CommitsOptions{
 			Range:        r.revisionRange,
 			N:            uint(n),
 			MessageQuery: query,
@@ -236,11 +236,24 @@ func (r *GitTreeEntryResolver) IsSingleChild(ctx context.Context, args *gitTreeE
 
 func (r *GitTreeEntryResolver) LSIF(ctx context.Context) (LSIFQueryResolver, error) {
 	codeIntelRequests.WithLabelValues(trace.RequestOrigin(ctx)).Inc()
-	return EnterpriseResolvers.codeIntelResolver.LSIF(ctx, &LSIFQueryArgs{
+	resolver, err := EnterpriseResolvers.codeIntelResolver.LSIF(ctx, &LSIFQueryArgs{
 		Repository: r.Repository(),
 		Commit:     api.CommitID(r.Commit().OID()),
 		Path:       r.Path(),
 	})
+	if err == codeIntelOnlyInEnterprise {
+		resolver, err = nil, nil
+	}
+	if err != nil || resolver != nil {
+		return resolver, err
+	}
+
+	// Without an LSIF upload, fall back to imprecise search-based code
+	// intelligence.
+	if searchBased := newSearchBasedCodeIntelResolver(r); searchBased != nil {
+		return searchBased, nil
+	}
+	return nil, nil
 }
 
 type fileInfo struct {
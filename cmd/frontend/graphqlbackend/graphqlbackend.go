@@ -352,7 +352,7 @@ func NewSchema(campaigns CampaignsResolver, codeIntel CodeIntelResolver, authz A
 	return graphql.ParseSchema(
 		Schema,
 		resolver,
-		graphql.Tracer(prometheusTracer{}),
+		graphql.Tracer(tokenScopeTracer{prometheusTracer{}}),
 	)
 }
 
@@ -486,6 +486,11 @@ func (r *NodeResolver) ToLSIFUpload() (LSIFUploadResolver, bool) {
 	return n, ok
 }
 
+func (r *NodeResolver) ToSearchExport() (*searchExportResolver, bool) {
+	n, ok := r.Node.(*searchExportResolver)
+	return n, ok
+}
+
 // schemaResolver handles all GraphQL queries for Sourcegraph. To do this, it
 // uses subresolvers which are globals. Enterprise-only resolvers are assigned
 // to a field of EnterpriseResolvers.
@@ -567,6 +572,8 @@ func (r *schemaResolver) nodeByID(ctx context.Context, id graphql.ID) (Node, err
 		return RegistryExtensionByID(ctx, id)
 	case "SavedSearch":
 		return savedSearchByID(ctx, id)
+	case "SearchExport":
+		return searchExportByID(ctx, id)
 	case "Site":
 		return siteByGQLID(ctx, id)
 	case "LSIFUpload":
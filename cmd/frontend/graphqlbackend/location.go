@@ -13,11 +13,16 @@ type LocationResolver interface {
 	Range() *rangeResolver
 	URL(ctx context.Context) (string, error)
 	CanonicalURL() (string, error)
+	Imprecise() bool
 }
 
 type locationResolver struct {
 	resource *GitTreeEntryResolver
 	lspRange *lsp.Range
+
+	// imprecise is whether the location was found with search-based
+	// heuristics instead of precise code intelligence data.
+	imprecise bool
 }
 
 var _ LocationResolver = &locationResolver{}
@@ -38,6 +43,8 @@ func (r *locationResolver) Range() *rangeResolver {
 	return &rangeResolver{*r.lspRange}
 }
 
+func (r *locationResolver) Imprecise() bool { return r.imprecise }
+
 func (r *locationResolver) URL(ctx context.Context) (string, error) {
 	url, err := r.resource.URL(ctx)
 	if err != nil {
@@ -9,11 +9,12 @@ import (
 	"strings"
 	"sync"
 
+	graphql "github.com/graph-gophers/graphql-go"
 	"github.com/sourcegraph/go-diff/diff"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/backend"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/graphqlbackend/graphqlutil"
 	"github.com/sourcegraph/sourcegraph/internal/api"
-	"github.com/sourcegraph/sourcegraph/internal/gitserver"
+	"github.com/sourcegraph/sourcegraph/internal/gitserver/protocol"
 	"github.com/sourcegraph/sourcegraph/internal/vcs/git"
 )
 
@@ -30,6 +31,9 @@ type RepositoryComparisonConnectionResolver interface {
 type RepositoryComparisonInput struct {
 	Base *string
 	Head *string
+	// HeadRepository is the repository of Head. If nil, Head is a revision
+	// of the base repository.
+	HeadRepository *graphql.ID
 }
 
 func NewRepositoryComparison(ctx context.Context, r *RepositoryResolver, args *RepositoryComparisonInput) (*RepositoryComparisonResolver, error) {
@@ -45,34 +49,43 @@ func NewRepositoryComparison(ctx context.Context, r *RepositoryResolver, args *R
 		headRevspec = *args.Head
 	}
 
-	getCommit := func(ctx context.Context, repo gitserver.Repo, revspec string) (*GitCommitResolver, error) {
+	headRepo := r
+	if args.HeadRepository != nil {
+		var err error
+		headRepo, err = repositoryByID(ctx, *args.HeadRepository)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	getCommit := func(ctx context.Context, repo *RepositoryResolver, revspec string) (*GitCommitResolver, error) {
 		if revspec == devNullSHA {
 			return nil, nil
 		}
 
+		grepo, err := backend.CachedGitRepo(ctx, repo.repo)
+		if err != nil {
+			return nil, err
+		}
+
 		// Optimistically fetch using revspec
-		commit, err := git.GetCommit(ctx, repo, nil, api.CommitID(revspec))
+		commit, err := git.GetCommit(ctx, *grepo, nil, api.CommitID(revspec))
 		if err == nil {
-			return toGitCommitResolver(r, commit), nil
+			return toGitCommitResolver(repo, commit), nil
 		}
 
 		// Call ResolveRevision to trigger fetches from remote (in case base/head commits don't
 		// exist).
-		commitID, err := git.ResolveRevision(ctx, repo, nil, revspec, nil)
+		commitID, err := git.ResolveRevision(ctx, *grepo, nil, revspec, nil)
 		if err != nil {
 			return nil, err
 		}
 
-		commit, err = git.GetCommit(ctx, repo, nil, commitID)
+		commit, err = git.GetCommit(ctx, *grepo, nil, commitID)
 		if err != nil {
 			return nil, err
 		}
-		return toGitCommitResolver(r, commit), nil
-	}
-
-	grepo, err := backend.CachedGitRepo(ctx, r.repo)
-	if err != nil {
-		return nil, err
+		return toGitCommitResolver(repo, commit), nil
 	}
 
 	var (
@@ -84,11 +97,11 @@ func NewRepositoryComparison(ctx context.Context, r *RepositoryResolver, args *R
 	wg.Add(2)
 	go func() {
 		defer wg.Done()
-		base, baseErr = getCommit(ctx, *grepo, baseRevspec)
+		base, baseErr = getCommit(ctx, r, baseRevspec)
 	}()
 	go func() {
 		defer wg.Done()
-		head, headErr = getCommit(ctx, *grepo, headRevspec)
+		head, headErr = getCommit(ctx, headRepo, headRevspec)
 	}()
 	wg.Wait()
 	if baseErr != nil {
@@ -103,7 +116,8 @@ func NewRepositoryComparison(ctx context.Context, r *RepositoryResolver, args *R
 		headRevspec: headRevspec,
 		base:        base,
 		head:        head,
-		repo:        r,
+		baseRepo:    r,
+		headRepo:    headRepo,
 	}, nil
 }
 
@@ -114,18 +128,32 @@ func (r *RepositoryResolver) Comparison(ctx context.Context, args *RepositoryCom
 type RepositoryComparisonResolver struct {
 	baseRevspec, headRevspec string
 	base, head               *GitCommitResolver
-	repo                     *RepositoryResolver
+	baseRepo, headRepo       *RepositoryResolver
 }
 
-func (r *RepositoryComparisonResolver) BaseRepository() *RepositoryResolver { return r.repo }
+func (r *RepositoryComparisonResolver) BaseRepository() *RepositoryResolver { return r.baseRepo }
 
-func (r *RepositoryComparisonResolver) HeadRepository() *RepositoryResolver { return r.repo }
+func (r *RepositoryComparisonResolver) HeadRepository() *RepositoryResolver { return r.headRepo }
+
+func (r *RepositoryComparisonResolver) crossRepository() bool {
+	return r.baseRepo.repo.ID != r.headRepo.repo.ID
+}
+
+// crossRepoBase returns the base commit that git commands run in the head
+// repository need, or the zero value if the comparison is not across
+// repositories.
+func (r *RepositoryComparisonResolver) crossRepoBase() protocol.CrossRepoBase {
+	if !r.crossRepository() || r.base == nil {
+		return protocol.CrossRepoBase{}
+	}
+	return protocol.CrossRepoBase{Repo: r.baseRepo.repo.Name, Commit: api.CommitID(r.base.OID())}
+}
 
 func (r *RepositoryComparisonResolver) Range() *gitRevisionRange {
 	return &gitRevisionRange{
 		expr:      r.baseRevspec + "..." + r.headRevspec,
-		base:      &gitRevSpec{expr: &gitRevSpecExpr{expr: r.baseRevspec, repo: r.repo}},
-		head:      &gitRevSpec{expr: &gitRevSpecExpr{expr: r.headRevspec, repo: r.repo}},
+		base:      &gitRevSpec{expr: &gitRevSpecExpr{expr: r.baseRevspec, repo: r.baseRepo}},
+		head:      &gitRevSpec{expr: &gitRevSpecExpr{expr: r.headRevspec, repo: r.headRepo}},
 		mergeBase: nil, // not currently used
 	}
 }
@@ -133,10 +161,19 @@ func (r *RepositoryComparisonResolver) Range() *gitRevisionRange {
 func (r *RepositoryComparisonResolver) Commits(
 	args *graphqlutil.ConnectionArgs,
 ) *gitCommitConnectionResolver {
+	// The commits are listed in the head repository, in which the revspecs of
+	// a cross-repository comparison can't be resolved. The commit IDs can be,
+	// because gitserver makes the base commit available to the command.
+	revisionRange := string(r.baseRevspec) + ".." + string(r.headRevspec)
+	crossRepoBase := r.crossRepoBase()
+	if crossRepoBase.Commit != "" {
+		revisionRange = string(r.base.OID()) + ".." + string(r.head.OID())
+	}
 	return &gitCommitConnectionResolver{
-		revisionRange: string(r.baseRevspec) + ".." + string(r.headRevspec),
+		revisionRange: revisionRange,
 		first:         args.First,
-		repo:          r.repo,
+		repo:          r.headRepo,
+		crossRepoBase: crossRepoBase,
 	}
 }
 
@@ -150,7 +187,7 @@ func (r *RepositoryComparisonResolver) FileDiffs(
 }
 
 type fileDiffConnectionResolver struct {
-	cmp   *RepositoryComparisonResolver // {base,head}{,RevSpec} and repo
+	cmp   *RepositoryComparisonResolver // {base,head}{,RevSpec,Repo}
 	first *int32
 
 	// cache result because it is used by multiple fields
@@ -176,11 +213,15 @@ func (r *fileDiffConnectionResolver) compute(ctx context.Context) ([]*diff.FileD
 			// flags or refer to a file.
 			return nil, fmt.Errorf("invalid diff range argument: %q", rangeSpec)
 		}
-		cachedRepo, err := backend.CachedGitRepo(ctx, r.cmp.repo.repo)
+		// The diff is computed in the head repository, to which gitserver
+		// makes the base commit available if it is from another repository.
+		cachedRepo, err := backend.CachedGitRepo(ctx, r.cmp.headRepo.repo)
 		if err != nil {
 			return nil, err
 		}
-		rdr, err := git.ExecReader(ctx, *cachedRepo, []string{
+		gitRepo := *cachedRepo
+		gitRepo.CrossRepoBase = r.cmp.crossRepoBase()
+		rdr, err := git.ExecReader(ctx, gitRepo, []string{
 			"diff",
 			"--find-renames",
 			"--find-copies",
@@ -291,7 +332,7 @@ func (r *fileDiffConnectionResolver) RawDiff(ctx context.Context) (string, error
 
 type fileDiffResolver struct {
 	fileDiff *diff.FileDiff
-	cmp      *RepositoryComparisonResolver // {base,head}{,RevSpec} and repo
+	cmp      *RepositoryComparisonResolver // {base,head}{,RevSpec,Repo}
 }
 
 func (r *fileDiffResolver) OldPath() *string { return diffPathOrNull(r.fileDiff.OrigName) }
@@ -0,0 +1,65 @@
+package graphqlbackend
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/graphqlbackend/graphqlutil"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
+	"github.com/sourcegraph/sourcegraph/internal/gitserver/protocol"
+)
+
+func TestRepositoryComparisonCommits(t *testing.T) {
+	upstream := &RepositoryResolver{repo: &types.Repo{ID: 1, Name: "upstream"}}
+	fork := &RepositoryResolver{repo: &types.Repo{ID: 2, Name: "fork"}}
+	base := &GitCommitResolver{repo: upstream, oid: "1111111111111111111111111111111111111111"}
+
+	tests := []struct {
+		name              string
+		headRepo          *RepositoryResolver
+		wantRange         string
+		wantCrossRepoBase protocol.CrossRepoBase
+	}{
+		{
+			name:      "same repository",
+			headRepo:  upstream,
+			wantRange: "master..feature",
+		},
+		{
+			name:              "cross-repository",
+			headRepo:          fork,
+			wantRange:         "1111111111111111111111111111111111111111..2222222222222222222222222222222222222222",
+			wantCrossRepoBase: protocol.CrossRepoBase{Repo: "upstream", Commit: "1111111111111111111111111111111111111111"},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			cmp := &RepositoryComparisonResolver{
+				baseRevspec: "master",
+				headRevspec: "feature",
+				base:        base,
+				head:        &GitCommitResolver{repo: tc.headRepo, oid: "2222222222222222222222222222222222222222"},
+				baseRepo:    upstream,
+				headRepo:    tc.headRepo,
+			}
+
+			if have := cmp.HeadRepository(); have != tc.headRepo {
+				t.Errorf("have head repository %q, want %q", have.repo.Name, tc.headRepo.repo.Name)
+			}
+
+			commits := cmp.Commits(&graphqlutil.ConnectionArgs{})
+			if commits.revisionRange != tc.wantRange {
+				t.Errorf("have range %q, want %q", commits.revisionRange, tc.wantRange)
+			}
+			// Commits are listed in the head repository, with the base
+			// commit made available by gitserver instead of being fetched
+			// into the head repository.
+			if commits.repo != tc.headRepo {
+				t.Errorf("have commits repository %q, want %q", commits.repo.repo.Name, tc.headRepo.repo.Name)
+			}
+			if !reflect.DeepEqual(commits.crossRepoBase, tc.wantCrossRepoBase) {
+				t.Errorf("have cross-repo base %+v, want %+v", commits.crossRepoBase, tc.wantCrossRepoBase)
+			}
+		})
+	}
+}
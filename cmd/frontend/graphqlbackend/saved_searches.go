@@ -3,6 +3,10 @@ package graphqlbackend
 import (
 	"context"
 	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
 
 	graphql "github.com/graph-gophers/graphql-go"
 	"github.com/graph-gophers/graphql-go/relay"
@@ -10,6 +14,7 @@ import (
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
 	"github.com/sourcegraph/sourcegraph/cmd/query-runner/queryrunnerapi"
+	"github.com/sourcegraph/sourcegraph/internal/httpcli"
 	"github.com/sourcegraph/sourcegraph/internal/lazyregexp"
 )
 
@@ -59,6 +64,8 @@ func savedSearchByID(ctx context.Context, id graphql.ID) (*savedSearchResolver,
 			UserID:          ss.Config.UserID,
 			OrgID:           ss.Config.OrgID,
 			SlackWebhookURL: ss.Config.SlackWebhookURL,
+			WebhookURL:      ss.Config.WebhookURL,
+			TeamsWebhookURL: ss.Config.TeamsWebhookURL,
 		},
 	}
 	return savedSearch, nil
@@ -97,6 +104,10 @@ func (r savedSearchResolver) OrgID() *graphql.ID {
 }
 func (r savedSearchResolver) SlackWebhookURL() *string { return r.s.SlackWebhookURL }
 
+func (r savedSearchResolver) WebhookURL() *string { return r.s.WebhookURL }
+
+func (r savedSearchResolver) TeamsWebhookURL() *string { return r.s.TeamsWebhookURL }
+
 func toSavedSearchResolver(entry types.SavedSearch) *savedSearchResolver {
 	return &savedSearchResolver{entry}
 }
@@ -142,12 +153,14 @@ func (r *schemaResolver) SendSavedSearchTestNotification(ctx context.Context, ar
 }
 
 func (r *schemaResolver) CreateSavedSearch(ctx context.Context, args *struct {
-	Description string
-	Query       string
-	NotifyOwner bool
-	NotifySlack bool
-	OrgID       *graphql.ID
-	UserID      *graphql.ID
+	Description     string
+	Query           string
+	NotifyOwner     bool
+	NotifySlack     bool
+	WebhookURL      *string
+	TeamsWebhookURL *string
+	OrgID           *graphql.ID
+	UserID          *graphql.ID
 }) (*savedSearchResolver, error) {
 	var userID, orgID *int32
 	// 🚨 SECURITY: Make sure the current user has permission to create a saved search for the specified user or org.
@@ -176,14 +189,19 @@ func (r *schemaResolver) CreateSavedSearch(ctx context.Context, args *struct {
 	if !queryHasPatternType(args.Query) {
 		return nil, errMissingPatternType
 	}
+	if err := validateWebhookURLs(args.WebhookURL, args.TeamsWebhookURL); err != nil {
+		return nil, err
+	}
 
 	ss, err := db.SavedSearches.Create(ctx, &types.SavedSearch{
-		Description: args.Description,
-		Query:       args.Query,
-		Notify:      args.NotifyOwner,
-		NotifySlack: args.NotifySlack,
-		UserID:      userID,
-		OrgID:       orgID,
+		Description:     args.Description,
+		Query:           args.Query,
+		Notify:          args.NotifyOwner,
+		NotifySlack:     args.NotifySlack,
+		UserID:          userID,
+		OrgID:           orgID,
+		WebhookURL:      args.WebhookURL,
+		TeamsWebhookURL: args.TeamsWebhookURL,
 	})
 	if err != nil {
 		return nil, err
@@ -193,13 +211,15 @@ func (r *schemaResolver) CreateSavedSearch(ctx context.Context, args *struct {
 }
 
 func (r *schemaResolver) UpdateSavedSearch(ctx context.Context, args *struct {
-	ID          graphql.ID
-	Description string
-	Query       string
-	NotifyOwner bool
-	NotifySlack bool
-	OrgID       *graphql.ID
-	UserID      *graphql.ID
+	ID              graphql.ID
+	Description     string
+	Query           string
+	NotifyOwner     bool
+	NotifySlack     bool
+	WebhookURL      *string
+	TeamsWebhookURL *string
+	OrgID           *graphql.ID
+	UserID          *graphql.ID
 }) (*savedSearchResolver, error) {
 	var userID, orgID *int32
 	// 🚨 SECURITY: Make sure the current user has permission to update a saved search for the specified user or org.
@@ -233,15 +253,20 @@ func (r *schemaResolver) UpdateSavedSearch(ctx context.Context, args *struct {
 	if !queryHasPatternType(args.Query) {
 		return nil, errMissingPatternType
 	}
+	if err := validateWebhookURLs(args.WebhookURL, args.TeamsWebhookURL); err != nil {
+		return nil, err
+	}
 
 	ss, err := db.SavedSearches.Update(ctx, &types.SavedSearch{
-		ID:          id,
-		Description: args.Description,
-		Query:       args.Query,
-		Notify:      args.NotifyOwner,
-		NotifySlack: args.NotifySlack,
-		UserID:      userID,
-		OrgID:       orgID,
+		ID:              id,
+		Description:     args.Description,
+		Query:           args.Query,
+		Notify:          args.NotifyOwner,
+		NotifySlack:     args.NotifySlack,
+		UserID:          userID,
+		OrgID:           orgID,
+		WebhookURL:      args.WebhookURL,
+		TeamsWebhookURL: args.TeamsWebhookURL,
 	})
 	if err != nil {
 		return nil, err
@@ -286,4 +311,26 @@ func queryHasPatternType(query string) bool {
 	return patternTypeRegexp.Match([]byte(query))
 }
 
+// validateWebhookURLs returns an error if any of the given webhook URLs is
+// set, but is not an absolute HTTP(S) URL of a public host.
+//
+// 🚨 SECURITY: Webhooks must not be used to reach internal services. Hostnames
+// that resolve to internal addresses are refused when the webhook is called.
+func validateWebhookURLs(urls ...*string) error {
+	for _, u := range urls {
+		if u == nil || *u == "" {
+			continue
+		}
+		parsed, err := url.Parse(*u)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Hostname() == "" {
+			return fmt.Errorf("invalid webhook URL %q: must be an absolute http or https URL", *u)
+		}
+		host := parsed.Hostname()
+		if ip := net.ParseIP(host); (ip != nil && !httpcli.IsPublicIP(ip)) || host == "localhost" || strings.HasSuffix(host, ".localhost") {
+			return fmt.Errorf("invalid webhook URL %q: must not be an internal address", *u)
+		}
+	}
+	return nil
+}
+
 var errMissingPatternType error = errors.New("a `patternType:` filter is required in the query for all saved searches. `patternType` can be \"literal\" or \"regexp\"")
@@ -61,12 +61,14 @@ func TestCreateSavedSearch(t *testing.T) {
 	}
 	userID := MarshalUserID(key)
 	savedSearches, err := (&schemaResolver{}).CreateSavedSearch(ctx, &struct {
-		Description string
-		Query       string
-		NotifyOwner bool
-		NotifySlack bool
-		OrgID       *graphql.ID
-		UserID      *graphql.ID
+		Description     string
+		Query           string
+		NotifyOwner     bool
+		NotifySlack     bool
+		WebhookURL      *string
+		TeamsWebhookURL *string
+		OrgID           *graphql.ID
+		UserID          *graphql.ID
 	}{Description: "test query", Query: "test type:diff patternType:regexp", NotifyOwner: true, NotifySlack: false, OrgID: nil, UserID: &userID})
 	if err != nil {
 		t.Fatal(err)
@@ -91,16 +93,54 @@ func TestCreateSavedSearch(t *testing.T) {
 
 	// Ensure create saved search errors when patternType is not provided in the query.
 	_, err = (&schemaResolver{}).CreateSavedSearch(ctx, &struct {
-		Description string
-		Query       string
-		NotifyOwner bool
-		NotifySlack bool
-		OrgID       *graphql.ID
-		UserID      *graphql.ID
+		Description     string
+		Query           string
+		NotifyOwner     bool
+		NotifySlack     bool
+		WebhookURL      *string
+		TeamsWebhookURL *string
+		OrgID           *graphql.ID
+		UserID          *graphql.ID
 	}{Description: "test query", Query: "test type:diff", NotifyOwner: true, NotifySlack: false, OrgID: nil, UserID: &userID})
 	if err == nil {
 		t.Error("Expected error for createSavedSearch when query does not provide a patternType: field.")
 	}
+
+	// Ensure create saved search errors when a webhook URL is not an HTTP(S) URL.
+	webhookURL := "file:///etc/passwd"
+	_, err = (&schemaResolver{}).CreateSavedSearch(ctx, &struct {
+		Description     string
+		Query           string
+		NotifyOwner     bool
+		NotifySlack     bool
+		WebhookURL      *string
+		TeamsWebhookURL *string
+		OrgID           *graphql.ID
+		UserID          *graphql.ID
+	}{Description: "test query", Query: "test type:diff patternType:regexp", WebhookURL: &webhookURL, UserID: &userID})
+	if err == nil {
+		t.Error("Expected error for createSavedSearch when the webhook URL is invalid.")
+	}
+}
+
+func TestValidateWebhookURLs(t *testing.T) {
+	for u, valid := range map[string]bool{
+		"":                                   true,
+		"https://hooks.example.com/abc":      true,
+		"http://8.8.8.8:8080/hook":           true,
+		"file:///etc/passwd":                 false,
+		"https://":                           false,
+		"http://localhost:3080/.api/graphql": false,
+		"http://127.0.0.1/hook":              false,
+		"http://169.254.169.254/latest":      false,
+		"http://[::1]:8080/hook":             false,
+		"http://10.0.0.5/hook":               false,
+	} {
+		u := u
+		if err := validateWebhookURLs(&u); (err == nil) != valid {
+			t.Errorf("%q: have error %v, want valid %v", u, err, valid)
+		}
+	}
 }
 
 func TestUpdateSavedSearch(t *testing.T) {
@@ -119,13 +159,15 @@ func TestUpdateSavedSearch(t *testing.T) {
 	}
 	userID := MarshalUserID(key)
 	savedSearches, err := (&schemaResolver{}).UpdateSavedSearch(ctx, &struct {
-		ID          graphql.ID
-		Description string
-		Query       string
-		NotifyOwner bool
-		NotifySlack bool
-		OrgID       *graphql.ID
-		UserID      *graphql.ID
+		ID              graphql.ID
+		Description     string
+		Query           string
+		NotifyOwner     bool
+		NotifySlack     bool
+		WebhookURL      *string
+		TeamsWebhookURL *string
+		OrgID           *graphql.ID
+		UserID          *graphql.ID
 	}{ID: marshalSavedSearchID(key), Description: "updated query description", Query: "test type:diff patternType:regexp", NotifyOwner: true, NotifySlack: false, OrgID: nil, UserID: &userID})
 	if err != nil {
 		t.Fatal(err)
@@ -151,13 +193,15 @@ func TestUpdateSavedSearch(t *testing.T) {
 
 	// Ensure update saved search errors when patternType is not provided in the query.
 	_, err = (&schemaResolver{}).UpdateSavedSearch(ctx, &struct {
-		ID          graphql.ID
-		Description string
-		Query       string
-		NotifyOwner bool
-		NotifySlack bool
-		OrgID       *graphql.ID
-		UserID      *graphql.ID
+		ID              graphql.ID
+		Description     string
+		Query           string
+		NotifyOwner     bool
+		NotifySlack     bool
+		WebhookURL      *string
+		TeamsWebhookURL *string
+		OrgID           *graphql.ID
+		UserID          *graphql.ID
 	}{ID: marshalSavedSearchID(key), Description: "updated query description", Query: "test type:diff", NotifyOwner: true, NotifySlack: false, OrgID: nil, UserID: &userID})
 	if err == nil {
 		t.Error("Expected error for updateSavedSearch when query does not provide a patternType: field.")
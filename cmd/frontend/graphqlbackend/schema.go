@@ -48,6 +48,19 @@ type Mutation {
         # created from this PatchSet.
         patches: [PatchInput!]!
     ): PatchSet!
+    # Create a patch set whose patches are computed on the server, by applying a structural
+    # rewrite to the default branch of every repository that matches a search query.
+    #
+    # The patches are computed in the background. Use PatchSet.status to track their
+    # progress. A campaign can only be created from the PatchSet once it has completed.
+    #
+    # Only site admins may perform this mutation.
+    createPatchSetFromRewrite(
+        # The search query that selects the repositories to rewrite.
+        query: String!
+        # The rewrite to apply to the files in each repository.
+        rewrite: RewriteSpecificationInput!
+    ): PatchSet!
     # Updates a campaign.
     # Note, updating is not allowed when:
     # The campaign has already been closed.
@@ -83,6 +96,17 @@ type Mutation {
     # update according to the progress of turning the patches into
     # changesets.
     publishCampaign(campaign: ID!): Campaign!
+    # Sets how the changesets of a campaign are published over time.
+    # If rollout is null, the remaining changesets are published without limits.
+    setCampaignRollout(campaign: ID!, rollout: CampaignRolloutInput): Campaign!
+    # Pauses the rollout of a campaign. No further changesets are published
+    # until the rollout is resumed.
+    pauseCampaignRollout(campaign: ID!): Campaign!
+    # Resumes the paused rollout of a campaign.
+    resumeCampaignRollout(campaign: ID!): Campaign!
+    # Sets when the open changesets of a campaign are merged automatically.
+    # If autoMerge is null, changesets are no longer merged automatically.
+    setCampaignAutoMerge(campaign: ID!, autoMerge: CampaignAutoMergeInput): Campaign!
     # Creates an ExternalChangeset on the codehost asynchronously.
     # The Patch has to belong to a PatchSet that has been attached
     # to a Campaign. Otherwise an error is returned.
@@ -203,15 +227,57 @@ type Mutation {
     #
     # - "user:all": Full control of all resources accessible to the user account.
     # - "site-admin:sudo": Ability to perform any action as any other user. (Only site admins may create tokens
-    #   with this scope.)
+    #   with this scope, and they must also have the "user:all" scope.)
+    # - "search:read": Read-only access to search and repository contents.
+    # - "lsif:upload": Ability to upload LSIF data.
+    # - "campaigns:write": Ability to view, create and update campaigns.
+    #
+    # An access token must have at least one of the "user:all", "search:read", "lsif:upload" and
+    # "campaigns:write" scopes. Access tokens without the "user:all" scope may only be used with the API
+    # operations permitted by their scopes.
     #
     # Only the user or site admins may perform this mutation.
-    createAccessToken(user: ID!, scopes: [String!]!, note: String!): CreateAccessTokenResult!
+    createAccessToken(
+        # The user whose privileges the access token grants.
+        user: ID!
+        # The scopes of the access token.
+        scopes: [String!]!
+        # A descriptive note for the access token.
+        note: String!
+        # The time after which the access token is no longer valid. If null, the access token does not expire.
+        expiresAt: DateTime
+        # The names of the repositories the access token may be used with. May only be used with the
+        # "search:read" and "lsif:upload" scopes.
+        repositories: [String!]
+        # Regular expressions matching the names of the repositories the access token may be used with. May
+        # only be used with the "search:read" and "lsif:upload" scopes.
+        repositoryPatterns: [String!]
+    ): CreateAccessTokenResult!
     # Deletes and immediately revokes the specified access token, specified by either its ID or by the token
     # itself.
     #
     # Only site admins or the user who owns the token may perform this mutation.
     deleteAccessToken(byID: ID, byToken: String): EmptyResponse!
+    # Creates a search export, which runs the search query in the background and writes all of its results to a
+    # file that can be downloaded once the export has completed. The search runs with the permissions of the
+    # current user.
+    #
+    # Only file and line match results are exported.
+    createSearchExport(
+        # The search query (such as "repo:myrepo foo").
+        query: String!
+        # The version of the search syntax being used.
+        version: SearchVersion = V1
+        # The search pattern type, if it is not specified in the query string using the patternType: field.
+        patternType: SearchPatternType
+        # The format of the exported file.
+        format: SearchExportFormat!
+    ): SearchExport!
+    # Cancels a queued or processing search export and deletes the results exported so far. Canceling an export
+    # that has already finished has no effect.
+    #
+    # Only site admins or the user who created the export may perform this mutation.
+    cancelSearchExport(id: ID!): SearchExport!
     # Deletes the association between an external account and its Sourcegraph user. It does NOT delete the external
     # account on the external service where it resides.
     #
@@ -352,6 +418,15 @@ type Mutation {
         # with this new value.
         input: String!
     ): Boolean!
+    # Restores the site configuration to a previous revision (see SiteConfiguration.history) by saving the
+    # revision's contents as a new revision. Returns whether or not a restart is required for the update to be
+    # applied. An error is returned if the revision's contents do not pass validation.
+    #
+    # Only site admins may perform this mutation.
+    rollbackSiteConfiguration(
+        # The ID of the site configuration revision to restore.
+        toID: Int!
+    ): Boolean!
     # Manages discussions.
     discussions: DiscussionsMutation
         @deprecated(
@@ -382,6 +457,10 @@ type Mutation {
         query: String!
         notifyOwner: Boolean!
         notifySlack: Boolean!
+        # The URL to POST new results of the saved search to as JSON, if any.
+        webhookURL: String
+        # The Microsoft Teams incoming webhook URL to post new results of the saved search to, if any.
+        teamsWebhookURL: String
         orgID: ID
         userID: ID
     ): SavedSearch!
@@ -392,6 +471,10 @@ type Mutation {
         query: String!
         notifyOwner: Boolean!
         notifySlack: Boolean!
+        # The URL to POST new results of the saved search to as JSON, if any.
+        webhookURL: String
+        # The Microsoft Teams incoming webhook URL to post new results of the saved search to, if any.
+        teamsWebhookURL: String
         orgID: ID
         userID: ID
     ): SavedSearch!
@@ -436,6 +519,21 @@ input PatchInput {
     patch: String!
 }
 
+# A structural rewrite of the files in a repository.
+input RewriteSpecificationInput {
+    # The template pattern that expresses what to match.
+    matchTemplate: String!
+
+    # The template pattern that expresses how matches are rewritten.
+    rewriteTemplate: String!
+
+    # Only files with this file extension are rewritten (e.g., ".go").
+    fileExtension: String
+
+    # Files in directories with this prefix are not rewritten (e.g., "vendor").
+    directoryExclude: String
+}
+
 # Input arguments for creating a campaign.
 input CreateCampaignInput {
     # The ID of the namespace where this campaign is defined.
@@ -464,6 +562,49 @@ input CreateCampaignInput {
     # When a Campaign is created in draft mode, its patches are not
     # created on the codehost, but only when publishing the Campaign.
     draft: Boolean
+
+    # An optional policy for publishing the changesets of the campaign over
+    # time instead of all at once.
+    rollout: CampaignRolloutInput
+
+    # An optional policy for merging the open changesets of the campaign once
+    # their checks have passed and they have been approved.
+    autoMerge: CampaignAutoMergeInput
+}
+
+# Input arguments for the rollout of a campaign.
+input CampaignRolloutInput {
+    # The maximum number of changesets published per window. Must be positive.
+    changesetsPerWindow: Int!
+
+    # The length of the window in seconds. Must be positive.
+    windowSeconds: Int!
+
+    # Optional groups of repository IDs that are published in order. The
+    # changesets of a batch are only published once all changesets of the
+    # previous batches have been created. Changesets of repositories that
+    # aren't in any batch are published last.
+    batches: [[ID!]!]
+}
+
+# Input arguments for the auto-merge policy of a campaign.
+input CampaignAutoMergeInput {
+    # The number of approvals a changeset needs before it is merged. Must not
+    # be negative.
+    requiredApprovals: Int!
+
+    # How changesets are merged. Defaults to MERGE.
+    mergeMethod: ChangesetMergeMethod
+}
+
+# The method used to merge a changeset.
+enum ChangesetMergeMethod {
+    # Merge the changeset with a merge commit.
+    MERGE
+    # Squash the commits of the changeset into a single commit.
+    SQUASH
+    # Rebase the commits of the changeset onto the base branch.
+    REBASE
 }
 
 # Input arguments for updating a campaign.
@@ -499,6 +640,11 @@ type PatchSet implements Node {
 
     # The URL where the PatchSet can be previewed and a campaign can be created from it.
     previewURL: String!
+
+    # The progress of computing the patches of a PatchSet created with createPatchSetFromRewrite,
+    # including the errors encountered in each repository. PatchSets created from patches
+    # computed by the caller are always completed.
+    status: BackgroundProcessStatus!
 }
 
 # A paginated list of repository diffs committed to git.
@@ -622,6 +768,50 @@ type Campaign implements Node {
     # Campaign.status increments with every Patch turned into an
     # ExternalChangeset.
     patches(first: Int): PatchConnection!
+
+    # How the changesets of the campaign are published over time.
+    rollout: CampaignRollout!
+
+    # When the open changesets of the campaign are merged automatically, or
+    # null if they aren't.
+    autoMerge: CampaignAutoMerge
+}
+
+# The rollout of the changesets of a campaign.
+type CampaignRollout {
+    # The maximum number of changesets published per window.
+    # If null, the campaign has no rollout policy and changesets are published without limits.
+    changesetsPerWindow: Int
+
+    # The length of the window in seconds, or null if the campaign has no rollout policy.
+    windowSeconds: Int
+
+    # The groups of repositories whose changesets are published in order.
+    batches: [[Repository!]!]!
+
+    # Whether the rollout is paused.
+    paused: Boolean!
+
+    # The number of changesets that are waiting to be published.
+    queuedCount: Int!
+
+    # The index of the batch whose changesets are being published, or null if
+    # the campaign has no batches or all batches have been published.
+    currentBatch: Int
+
+    # When the next changesets can be published, if the limit of the current window has been reached.
+    nextReleaseAt: DateTime
+}
+
+# The auto-merge policy of a campaign. An open changeset is merged once all
+# of its checks have passed, no reviewer requested changes and it has the
+# required number of approvals.
+type CampaignAutoMerge {
+    # The number of approvals a changeset needs before it is merged.
+    requiredApprovals: Int!
+
+    # How changesets are merged.
+    mergeMethod: ChangesetMergeMethod!
 }
 
 # The counts of changesets in certain states at a specific point in time.
@@ -1625,6 +1815,10 @@ type SavedSearch implements Node {
     orgID: ID
     # The Slack webhook URL associated with this saved search, if any.
     slackWebhookURL: String
+    # The URL that new results of this saved search are POSTed to as JSON, if any.
+    webhookURL: String
+    # The Microsoft Teams incoming webhook URL that new results of this saved search are posted to, if any.
+    teamsWebhookURL: String
 }
 
 # A search query description.
@@ -1882,12 +2076,15 @@ type Repository implements Node & GenericSearchResultInterface {
         # Return Git tags whose names match the query.
         query: String
     ): GitRefConnection!
-    # A Git comparison in this repository between a base and head commit.
+    # A Git comparison between a base commit in this repository and a head commit in this
+    # repository or in headRepository, such as a fork of this repository.
     comparison(
         # The base of the diff ("old" or "left-hand side"), or "HEAD" if not specified.
         base: String
         # The head of the diff ("new" or "right-hand side"), or "HEAD" if not specified.
         head: String
+        # The repository that head is resolved in. Defaults to this repository.
+        headRepository: ID
     ): RepositoryComparison!
     # The repository's contributors.
     contributors(
@@ -2133,14 +2330,13 @@ type PreviewFileDiff {
     internalID: String!
 }
 
-# The differences between two concrete Git commits in a repository.
+# The differences between two concrete Git commits, which may be in different repositories.
 type RepositoryComparison {
     # The repository that is the base (left-hand side) of this comparison.
     baseRepository: Repository!
 
-    # The repository that is the head (right-hand side) of this comparison. Cross-repository
-    # comparisons are not yet supported, so this is always equal to
-    # RepositoryComparison.baseRepository.
+    # The repository that is the head (right-hand side) of this comparison. It differs from
+    # RepositoryComparison.baseRepository for cross-repository comparisons.
     headRepository: Repository!
 
     # The range that this comparison represents.
@@ -2290,6 +2486,10 @@ type Location {
     url: String!
     # The canonical URL to this location (using an immutable revision specifier).
     canonicalURL: String!
+    # Whether this location was found by search-based heuristics instead of precise code
+    # intelligence data (from an LSIF upload). Imprecise locations may be wrong, for example
+    # for symbols with common names.
+    imprecise: Boolean!
 }
 
 # A range inside a file. The start position is inclusive, and the end position is exclusive.
@@ -2816,12 +3016,14 @@ type GitBlob implements TreeEntry & File2 {
     # continue to adjust it for our use cases. Changes will not be documented in the
     # CHANGELOG during this time.
     # A wrapper around LSIF query methods. If no LSIF upload can be used to answer code
-    # intelligence queries for this path-at-revision, this resolves to null.
+    # intelligence queries for this path-at-revision, the queries are answered with
+    # search-based heuristics and all locations are marked imprecise. If the language of
+    # this file is unknown as well, this resolves to null.
     lsif: LSIFQueryResolver
 }
 
 # A wrapper object around LSIF query methods for a particular path-at-revision. When this node is
-# null, no LSIF data is available for containing git blob.
+# null, no code intelligence is available for containing git blob.
 type LSIFQueryResolver {
     # (experimental) The LSIF API may change substantially in the near future as we
     # continue to adjust it for our use cases. Changes will not be documented in the
@@ -3028,6 +3230,13 @@ type User implements Node & SettingsSubject & Namespace {
         # Returns the first n access tokens from the list.
         first: Int
     ): AccessTokenConnection!
+    # The unexpired search exports created by this user, newest first.
+    #
+    # Only the user and site admins can access this field.
+    searchExports(
+        # Returns the first n search exports from the list.
+        first: Int
+    ): SearchExportConnection!
     # A list of external accounts that are associated with the user.
     externalAccounts(
         # Returns the first n external accounts from the list.
@@ -3082,6 +3291,11 @@ type AccessToken implements Node {
     createdAt: DateTime!
     # The date when the access token was last used to authenticate a request.
     lastUsedAt: DateTime
+    # The date after which the access token is no longer valid, or null if it does not expire.
+    expiresAt: DateTime
+    # Regular expressions matching the names of the repositories the access token may be used with. If empty,
+    # the access token may be used with all repositories.
+    repositoryPatterns: [String!]!
 }
 
 # A list of access tokens.
@@ -3095,6 +3309,68 @@ type AccessTokenConnection {
     pageInfo: PageInfo!
 }
 
+# The format of an exported search results file.
+enum SearchExportFormat {
+    # Comma-separated values with a header row.
+    CSV
+    # JSON lines, with one JSON object per line.
+    JSONL
+}
+
+# The state of a search export.
+enum SearchExportState {
+    # The export is waiting to be processed.
+    QUEUED
+    # The search results are being exported.
+    PROCESSING
+    # All search results have been exported and the file can be downloaded.
+    COMPLETED
+    # The export failed. See the error field for details.
+    ERRORED
+    # The export was canceled.
+    CANCELED
+}
+
+# An asynchronous export of all results of a search query to a file. Exports are deleted when they expire.
+type SearchExport implements Node {
+    # The unique ID for the search export.
+    id: ID!
+    # The user who created the export.
+    user: User!
+    # The search query.
+    query: String!
+    # The format of the exported file.
+    format: SearchExportFormat!
+    # The state of the export.
+    state: SearchExportState!
+    # The error message, if the export failed.
+    error: String
+    # The number of results exported so far. Each line match and each file match without line matches is one
+    # result.
+    resultCount: Int!
+    # The URL to download the exported file from, if the export has completed.
+    url: String
+    # The date when the export was created.
+    createdAt: DateTime!
+    # The date when processing of the export started.
+    startedAt: DateTime
+    # The date when the export finished.
+    finishedAt: DateTime
+    # The date when the export and its file will be deleted.
+    expiresAt: DateTime!
+}
+
+# A list of search exports.
+type SearchExportConnection {
+    # A list of search exports.
+    nodes: [SearchExport!]!
+    # The total count of search exports in the connection. This total count may be larger than the number of nodes
+    # in this object when the result is paginated.
+    totalCount: Int!
+    # Pagination information.
+    pageInfo: PageInfo!
+}
+
 # A list of authentication providers.
 type AuthProviderConnection {
     # A list of authentication providers.
@@ -3601,6 +3877,10 @@ type Site implements SettingsSubject {
     accessTokens(
         # Returns the first n access tokens from the list.
         first: Int
+        # Only include access tokens that were last used after this date.
+        lastUsedAfter: DateTime
+        # Only include access tokens that were last used before this date.
+        lastUsedBefore: DateTime
     ): AccessTokenConnection!
     # A list of all authentication providers. This information is visible to all viewers and does not contain any
     # secret information.
@@ -3670,6 +3950,103 @@ type Site implements SettingsSubject {
         # Months of history (based on current UTC time).
         months: Int
     ): CodeIntelUsageStatistics!
+    # The audit log of security-relevant actions (such as promoting a user to site admin or changing the site
+    # configuration), most recent first. Only visible to site admins.
+    auditLog(
+        # Returns the first n entries from the list.
+        first: Int
+        # Include only entries of actions performed by this user.
+        actor: ID
+        # Include only entries of this action.
+        action: AuditLogAction
+        # Include only entries with this target type (for example, "User" or "ExternalService").
+        targetType: String
+        # Include only entries with this target ID (the database ID of the target, for example "42").
+        targetID: String
+        # Include only entries that were created after this date.
+        createdAfter: DateTime
+        # Include only entries that were created before this date.
+        createdBefore: DateTime
+    ): AuditLogEntryConnection!
+    # Verifies that no entries in the audit log were changed, deleted or inserted out of order. Only visible to
+    # site admins.
+    auditLogVerification: AuditLogVerification!
+}
+
+# An action that is recorded in the audit log.
+enum AuditLogAction {
+    # A user was promoted to or demoted from site admin.
+    SET_SITE_ADMIN
+    # An access token with the "site-admin:sudo" scope was created.
+    CREATE_SUDO_ACCESS_TOKEN
+    # The site configuration was changed (or rolled back).
+    UPDATE_SITE_CONFIGURATION
+    # An external service was added.
+    ADD_EXTERNAL_SERVICE
+    # An external service was updated.
+    UPDATE_EXTERNAL_SERVICE
+    # An external service was deleted.
+    DELETE_EXTERNAL_SERVICE
+    # The permissions of a repository were set explicitly.
+    SET_REPOSITORY_PERMISSIONS
+}
+
+# A list of audit log entries.
+type AuditLogEntryConnection {
+    # A list of audit log entries.
+    nodes: [AuditLogEntry!]!
+    # The total count of audit log entries in the connection. This total count may be larger than the number of
+    # nodes in this object when the result is paginated.
+    totalCount: Int!
+    # Pagination information.
+    pageInfo: PageInfo!
+    # The URL to download all audit log entries in the connection (ignoring pagination) as JSON lines, oldest
+    # first.
+    exportURL: String!
+}
+
+# An entry in the audit log. Each entry includes the hash of the entry before it, so that changes to the audit
+# log can be detected.
+type AuditLogEntry {
+    # The unique identifier of this entry. Entries are numbered in the order they were written.
+    id: Int!
+    # The user who performed the action, or null if the user was deleted.
+    actor: User
+    # The IP address of the client that performed the action.
+    actorIP: String!
+    # The X-Forwarded-For header of the request that performed the action, if any.
+    forwardedFor: String!
+    # The action that was performed.
+    action: AuditLogAction!
+    # The type of the object that the action was performed on (for example, "User").
+    targetType: String!
+    # The database ID of the object that the action was performed on.
+    targetID: String!
+    # The state of the target before the action, or null if the target did not exist. Secrets are redacted.
+    before: JSONValue
+    # The state of the target after the action, or null if the target was deleted. Secrets are redacted.
+    after: JSONValue
+    # The date when the action was performed.
+    createdAt: DateTime!
+    # The hash of the entry before this one, or the empty string for the first entry.
+    previousHash: String!
+    # The hash of this entry's contents and previousHash.
+    hash: String!
+}
+
+# The result of verifying the audit log.
+type AuditLogVerification {
+    # Whether all entries in the audit log are intact.
+    valid: Boolean!
+    # The number of entries that were verified.
+    entryCount: Int!
+    # Whether the hashes of the entries are keyed with a secret that is not stored in the database (the
+    # AUDIT_LOG_HMAC_KEY environment variable). If false, anyone who can write to the database can change entries
+    # and compute their hashes again, so the audit log is not tamper-evident.
+    keyed: Boolean!
+    # The ID of the first entry whose contents or previous hash do not match its hash, or null if the audit log
+    # is intact.
+    firstInvalidEntryID: Int
 }
 
 # The configuration for a site.
@@ -3682,6 +4059,44 @@ type SiteConfiguration {
     # This includes both JSON Schema validation problems and other messages that perform more advanced checks
     # on the configuration (that can't be expressed in the JSON Schema).
     validationMessages: [String!]!
+    # The saved revisions of the site configuration, most recent first.
+    history(
+        # Returns the first n revisions from the list.
+        first: Int
+        # Opaque pagination cursor. Pass the 'SiteConfigurationRevisionConnection.pageInfo.endCursor' of a
+        # previous request to fetch the revisions after it.
+        after: String
+    ): SiteConfigurationRevisionConnection!
+}
+
+# A list of site configuration revisions.
+type SiteConfigurationRevisionConnection {
+    # A list of site configuration revisions.
+    nodes: [SiteConfigurationRevision!]!
+    # The total count of site configuration revisions in the connection. This total count may be larger than the
+    # number of nodes in this object when the result is paginated.
+    totalCount: Int!
+    # Pagination information.
+    pageInfo: PageInfo!
+}
+
+# A saved revision of the site configuration.
+type SiteConfigurationRevision {
+    # The unique identifier of this site configuration revision.
+    id: Int!
+    # The user who saved this revision, or null if it is not known (for example, for the default site
+    # configuration or if the user was deleted).
+    author: User
+    # The date when this revision was saved.
+    createdAt: DateTime!
+    # The raw JSON contents of this revision.
+    contents: JSONCString!
+    # A line-by-line diff of the contents of another revision (the old contents) and this revision (the new
+    # contents). Added lines start with "+", removed lines with "-" and unchanged lines with " ".
+    diff(
+        # The ID of the revision to compare against. Defaults to the revision before this one.
+        from: Int
+    ): String!
 }
 
 # The critical configuration for a site.
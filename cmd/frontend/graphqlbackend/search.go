@@ -17,6 +17,7 @@ import (
 	"github.com/neelance/parallel"
 	"github.com/pkg/errors"
 
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/authz"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/envvar"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/internal/goroutine"
@@ -118,6 +119,9 @@ func NewSearchImplementer(args *SearchArgs) (SearchImplementer, error) {
 		if err != nil {
 			return nil, err
 		}
+		if value, _ := queryInfo.StringValue(query.FieldSort); value == query.SortRelevance {
+			return nil, errors.New("Search results can't be sorted by relevance in paginated or stable searches.")
+		}
 	}
 
 	return &searchResolver{
@@ -592,6 +596,11 @@ func resolveRepositories(ctx context.Context, op resolveRepoOp) (repoRevisions,
 		return nil, nil, false, err
 	}
 
+	// 🚨 SECURITY: Only search the repositories the access token of the request may be used with.
+	if restrictions := authz.TokenRestrictionsFromContext(ctx); restrictions != nil && len(restrictions.RepositoryPatterns) > 0 {
+		includePatterns = append(includePatterns, unionRegExps(restrictions.RepositoryPatterns))
+	}
+
 	var defaultRepos []*types.Repo
 	if envvar.SourcegraphDotComMode() && len(includePatterns) == 0 {
 		getIndexedRepos := func(ctx context.Context, revs []*search.RepositoryRevisions) (indexed, unindexed []*search.RepositoryRevisions, err error) {
@@ -80,7 +80,7 @@ func alertForQuery(queryString string, err error) *searchAlert {
 		return &searchAlert{
 			prometheusType: "unsupported_and_or_query",
 			title:          "Unable To Process Query",
-			description:    `I'm having trouble understsanding that query. Your query contains "and" or "or" operators that make me think they apply to filters like "repo:" or "file:". We only support "and" or "or" operators on search patterns for file contents currently. You can help me by putting parentheses around the search pattern.`,
+			description:    fmt.Sprintf(`I'm having trouble understsanding that query. %s. You can help me by putting parentheses around the expressions that "and", "or", and "not" apply to.`, capFirst(err.Error())),
 		}
 	}
 	return &searchAlert{
@@ -17,6 +17,7 @@ import (
 	"github.com/pkg/errors"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
+	"github.com/sourcegraph/sourcegraph/internal/conf"
 	"github.com/sourcegraph/sourcegraph/internal/errcode"
 	"github.com/sourcegraph/sourcegraph/internal/search"
 	"github.com/sourcegraph/sourcegraph/internal/search/query"
@@ -156,7 +157,8 @@ func searchCommitsInRepo(ctx context.Context, op search.CommitParameters) (resul
 
 	// Helper for adding git log flags --grep, --author, and --committer, which all behave similarly.
 	var hasSeenGrepLikeFields, hasSeenInvertedGrepLikeFields bool
-	addGrepLikeFlags := func(args *[]string, gitLogFlag string, field string, extraValues []string, expandUsernames bool) error {
+	var indexedOptions git.IndexedLogDiffSearchOptions
+	addGrepLikeFlags := func(args *[]string, gitLogFlag string, field string, extraValues []string, expandUsernames bool, indexedPatterns *[]string) error {
 		values, minusValues := op.Query.RegexpPatterns(field)
 		values = append(values, extraValues...)
 
@@ -196,16 +198,19 @@ func searchCommitsInRepo(ctx context.Context, op search.CommitParameters) (resul
 			for _, s := range minusValues {
 				*args = append(*args, gitLogFlag+"="+s)
 			}
+			*indexedPatterns = append(*indexedPatterns, values...)
+			*indexedPatterns = append(*indexedPatterns, minusValues...)
+			indexedOptions.InvertPatterns = indexedOptions.InvertPatterns || len(minusValues) > 0
 		}
 		return nil
 	}
-	if err := addGrepLikeFlags(&args, "--grep", query.FieldMessage, op.ExtraMessageValues, false); err != nil {
+	if err := addGrepLikeFlags(&args, "--grep", query.FieldMessage, op.ExtraMessageValues, false, &indexedOptions.MessagePatterns); err != nil {
 		return nil, false, false, err
 	}
-	if err := addGrepLikeFlags(&args, "--author", query.FieldAuthor, nil, true); err != nil {
+	if err := addGrepLikeFlags(&args, "--author", query.FieldAuthor, nil, true, &indexedOptions.AuthorPatterns); err != nil {
 		return nil, false, false, err
 	}
-	if err := addGrepLikeFlags(&args, "--committer", query.FieldCommitter, nil, true); err != nil {
+	if err := addGrepLikeFlags(&args, "--committer", query.FieldCommitter, nil, true, &indexedOptions.CommitterPatterns); err != nil {
 		return nil, false, false, err
 	}
 
@@ -230,9 +235,33 @@ func searchCommitsInRepo(ctx context.Context, op search.CommitParameters) (resul
 		},
 	}
 
-	rawResults, complete, err := git.RawLogDiffSearch(ctx, diffParameters.Repo, diffParameters.Options)
-	if err != nil {
-		return nil, false, false, err
+	var (
+		rawResults []*git.LogCommitSearchResult
+		complete   bool
+		indexed    bool
+	)
+	if conf.CommitIndexEnabled() && onlyDefaultBranch(op.RepoRevs.Revs) {
+		// The commit index only covers the default branch, so it can answer
+		// the same query as `git log` with the arguments above.
+		indexedOptions.Query = diffParameters.Options.Query
+		indexedOptions.Paths = diffParameters.Options.Paths
+		indexedOptions.Diff = diffParameters.Options.Diff
+		indexedOptions.OnlyMatchingHunks = diffParameters.Options.OnlyMatchingHunks
+		indexedOptions.PatternsAreCaseSensitive = op.Query.IsCaseSensitive()
+		indexedOptions.Before = beforeValues
+		indexedOptions.After = afterValues
+		indexedOptions.Limit = maxResults + 1
+		rawResults, complete, indexed, err = git.IndexedLogDiffSearch(ctx, diffParameters.Repo, indexedOptions)
+		if err != nil {
+			return nil, false, false, err
+		}
+		tr.LazyPrintf("indexed=%v", indexed)
+	}
+	if !indexed {
+		rawResults, complete, err = git.RawLogDiffSearch(ctx, diffParameters.Repo, diffParameters.Options)
+		if err != nil {
+			return nil, false, false, err
+		}
 	}
 
 	// if the result is incomplete, git log timed out and the client should be notified of that
@@ -315,6 +344,20 @@ func searchCommitsInRepo(ctx context.Context, op search.CommitParameters) (resul
 	return results, limitHit, timedOut, nil
 }
 
+// onlyDefaultBranch returns true if revs only refer to the default branch
+// (HEAD) of a repository.
+func onlyDefaultBranch(revs []search.RevisionSpecifier) bool {
+	if len(revs) > 1 {
+		return false
+	}
+	for _, rev := range revs {
+		if rev.RefGlob != "" || rev.ExcludeRefGlob != "" || (rev.RevSpec != "" && rev.RevSpec != "HEAD") {
+			return false
+		}
+	}
+	return true
+}
+
 func cleanDiffPreview(highlights []*highlightedRange, rawDiffResult string) (string, []*highlightedRange) {
 	// A map of line number to number of lines that have been ignored before the particular line number.
 	lineByCountIgnored := make(map[int]int32)
@@ -14,9 +14,11 @@ import (
 	//"github.com/google/go-cmp/cmp"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
+	"github.com/sourcegraph/sourcegraph/internal/conf"
 	"github.com/sourcegraph/sourcegraph/internal/search"
 	"github.com/sourcegraph/sourcegraph/internal/search/query"
 	"github.com/sourcegraph/sourcegraph/internal/vcs/git"
+	"github.com/sourcegraph/sourcegraph/schema"
 )
 
 func TestSearchCommitsInRepo(t *testing.T) {
@@ -97,6 +99,87 @@ func TestSearchCommitsInRepo(t *testing.T) {
 	}
 }
 
+func TestSearchCommitsInRepo_commitIndex(t *testing.T) {
+	ctx := context.Background()
+	conf.Mock(&conf.Unified{SiteConfiguration: schema.SiteConfiguration{
+		ExperimentalFeatures: &schema.ExperimentalFeatures{CommitIndex: "enabled"},
+	}})
+	defer conf.Mock(nil)
+	defer git.ResetMocks()
+
+	query, err := query.ParseAndCheck("p -message:m -author:a before:yesterday")
+	if err != nil {
+		t.Fatal(err)
+	}
+	searchRevs := func(revs []search.RevisionSpecifier) []*commitSearchResultResolver {
+		t.Helper()
+		results, _, _, err := searchCommitsInRepo(ctx, search.CommitParameters{
+			RepoRevs:    &search.RepositoryRevisions{Repo: &types.Repo{ID: 1, Name: "repo"}, Revs: revs},
+			PatternInfo: &search.CommitPatternInfo{Pattern: "p", FileMatchLimit: 10},
+			Query:       query,
+			Diff:        true,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return results
+	}
+
+	var calledIndexed, calledRaw bool
+	indexed := true
+	git.Mocks.IndexedLogDiffSearch = func(opt git.IndexedLogDiffSearchOptions) ([]*git.LogCommitSearchResult, bool, bool, error) {
+		calledIndexed = true
+		want := git.IndexedLogDiffSearchOptions{
+			Query:             git.TextSearchOptions{Pattern: "p"},
+			Diff:              true,
+			OnlyMatchingHunks: true,
+			MessagePatterns:   []string{"m"},
+			AuthorPatterns:    []string{"a"},
+			InvertPatterns:    true,
+			Before:            []string{"yesterday"},
+			Limit:             11,
+		}
+		if !reflect.DeepEqual(opt, want) {
+			t.Errorf("got %+v, want %+v", opt, want)
+		}
+		if !indexed {
+			return nil, false, false, nil
+		}
+		return []*git.LogCommitSearchResult{{Commit: git.Commit{ID: "c1"}, Diff: &git.Diff{Raw: "x"}}}, true, true, nil
+	}
+	git.Mocks.RawLogDiffSearch = func(opt git.RawLogDiffSearchOptions) ([]*git.LogCommitSearchResult, bool, error) {
+		calledRaw = true
+		return []*git.LogCommitSearchResult{{Commit: git.Commit{ID: "c2"}, Diff: &git.Diff{Raw: "x"}}}, true, nil
+	}
+
+	// The default branch is searched using the index.
+	if results := searchRevs([]search.RevisionSpecifier{{RevSpec: ""}}); len(results) != 1 || results[0].commit.oid != "c1" {
+		t.Errorf("got %v, want indexed result", results)
+	}
+	if !calledIndexed || calledRaw {
+		t.Errorf("got calledIndexed=%v calledRaw=%v, want only indexed search", calledIndexed, calledRaw)
+	}
+
+	// Other revisions are searched using git log.
+	calledIndexed, calledRaw = false, false
+	if results := searchRevs([]search.RevisionSpecifier{{RevSpec: "rev"}}); len(results) != 1 || results[0].commit.oid != "c2" {
+		t.Errorf("got %v, want git log result", results)
+	}
+	if calledIndexed || !calledRaw {
+		t.Errorf("got calledIndexed=%v calledRaw=%v, want only git log", calledIndexed, calledRaw)
+	}
+
+	// Repositories without an up-to-date index fall back to git log.
+	indexed = false
+	calledIndexed, calledRaw = false, false
+	if results := searchRevs(nil); len(results) != 1 || results[0].commit.oid != "c2" {
+		t.Errorf("got %v, want git log result", results)
+	}
+	if !calledIndexed || !calledRaw {
+		t.Errorf("got calledIndexed=%v calledRaw=%v, want both", calledIndexed, calledRaw)
+	}
+}
+
 func (r *commitSearchResultResolver) String() string {
 	return fmt.Sprintf("{commit: %+v diffPreview: %+v messagePreview: %+v}", r.commit, r.diffPreview, r.messagePreview)
 }
@@ -0,0 +1,210 @@
+package graphqlbackend
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	graphql "github.com/graph-gophers/graphql-go"
+	"github.com/graph-gophers/graphql-go/relay"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/authz"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/backend"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/graphqlbackend/graphqlutil"
+	"github.com/sourcegraph/sourcegraph/internal/actor"
+)
+
+// searchExportResolver resolves a search export.
+type searchExportResolver struct {
+	export db.SearchExport
+}
+
+func searchExportByID(ctx context.Context, id graphql.ID) (*searchExportResolver, error) {
+	exportID, err := unmarshalSearchExportID(id)
+	if err != nil {
+		return nil, err
+	}
+	export, err := db.SearchExports.GetByID(ctx, exportID)
+	if err != nil {
+		return nil, err
+	}
+	// 🚨 SECURITY: Only the user who created the export and site admins may view it.
+	if err := backend.CheckSiteAdminOrSameUser(ctx, export.UserID); err != nil {
+		return nil, err
+	}
+	return &searchExportResolver{export: *export}, nil
+}
+
+func marshalSearchExportID(id int64) graphql.ID { return relay.MarshalID("SearchExport", id) }
+
+func unmarshalSearchExportID(id graphql.ID) (exportID int64, err error) {
+	err = relay.UnmarshalSpec(id, &exportID)
+	return
+}
+
+func (r *searchExportResolver) ID() graphql.ID { return marshalSearchExportID(r.export.ID) }
+
+func (r *searchExportResolver) User(ctx context.Context) (*UserResolver, error) {
+	return UserByIDInt32(ctx, r.export.UserID)
+}
+
+func (r *searchExportResolver) Query() string { return r.export.Query }
+
+func (r *searchExportResolver) Format() string { return r.export.Format }
+
+func (r *searchExportResolver) State() string { return r.export.State }
+
+func (r *searchExportResolver) Error() *string {
+	if r.export.Error == "" {
+		return nil
+	}
+	return &r.export.Error
+}
+
+func (r *searchExportResolver) ResultCount() int32 { return r.export.ResultCount }
+
+func (r *searchExportResolver) URL() *string {
+	if r.export.State != db.SearchExportStateCompleted {
+		return nil
+	}
+	u := fmt.Sprintf("/.api/search/export/%d", r.export.ID)
+	return &u
+}
+
+func (r *searchExportResolver) CreatedAt() DateTime { return DateTime{Time: r.export.CreatedAt} }
+
+func (r *searchExportResolver) StartedAt() *DateTime { return DateTimeOrNil(r.export.StartedAt) }
+
+func (r *searchExportResolver) FinishedAt() *DateTime { return DateTimeOrNil(r.export.FinishedAt) }
+
+func (r *searchExportResolver) ExpiresAt() DateTime { return DateTime{Time: r.export.ExpiresAt} }
+
+type createSearchExportInput struct {
+	Query       string
+	Version     string
+	PatternType *string
+	Format      string
+}
+
+func (r *schemaResolver) CreateSearchExport(ctx context.Context, args *createSearchExportInput) (*searchExportResolver, error) {
+	// 🚨 SECURITY: The export runs the search with the permissions of the
+	// user who creates it, so it is always created for the current user.
+	a := actor.FromContext(ctx)
+	if !a.IsAuthenticated() {
+		return nil, backend.ErrNotAuthenticated
+	}
+	// 🚨 SECURITY: The export is not limited to the repositories that the access token of the
+	// request may be used with.
+	if err := authz.CheckTokenAllRepos(ctx); err != nil {
+		return nil, err
+	}
+
+	// Validate the query up front so that users learn about mistakes
+	// without waiting for the export to fail.
+	first := int32(1)
+	search, err := NewSearchImplementer(&SearchArgs{
+		Version:     args.Version,
+		PatternType: args.PatternType,
+		Query:       args.Query,
+		First:       &first,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if alert, ok := search.(*searchAlert); ok {
+		return nil, errors.New(alert.Title())
+	}
+
+	export, err := db.SearchExports.Create(ctx, &db.SearchExport{
+		UserID:      a.UID,
+		Query:       args.Query,
+		Version:     args.Version,
+		PatternType: args.PatternType,
+		Format:      args.Format,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &searchExportResolver{export: *export}, nil
+}
+
+func (r *schemaResolver) CancelSearchExport(ctx context.Context, args *struct{ ID graphql.ID }) (*searchExportResolver, error) {
+	// 🚨 SECURITY: searchExportByID checks that the current user may view
+	// (and so cancel) the export.
+	export, err := searchExportByID(ctx, args.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	canceled, err := db.SearchExports.Cancel(ctx, export.export.ID)
+	if err != nil {
+		return nil, err
+	}
+	return &searchExportResolver{export: *canceled}, nil
+}
+
+func (r *UserResolver) SearchExports(ctx context.Context, args *struct {
+	graphqlutil.ConnectionArgs
+}) (*searchExportConnectionResolver, error) {
+	// 🚨 SECURITY: Only site admins and the user can list a user's search exports.
+	if err := backend.CheckSiteAdminOrSameUser(ctx, r.user.ID); err != nil {
+		return nil, err
+	}
+
+	return &searchExportConnectionResolver{userID: r.user.ID, first: args.First}, nil
+}
+
+// searchExportConnectionResolver resolves a list of search exports.
+//
+// 🚨 SECURITY: When instantiating a searchExportConnectionResolver value, the
+// caller MUST check permissions.
+type searchExportConnectionResolver struct {
+	userID int32
+	first  *int32
+
+	// cache results because they are used by multiple fields
+	once    sync.Once
+	exports []*db.SearchExport
+	err     error
+}
+
+func (r *searchExportConnectionResolver) compute(ctx context.Context) ([]*db.SearchExport, error) {
+	r.once.Do(func() {
+		limit := 0
+		if r.first != nil {
+			limit = int(*r.first) + 1 // so we can detect if there is a next page
+		}
+		r.exports, r.err = db.SearchExports.ListByUser(ctx, r.userID, limit)
+	})
+	return r.exports, r.err
+}
+
+func (r *searchExportConnectionResolver) Nodes(ctx context.Context) ([]*searchExportResolver, error) {
+	exports, err := r.compute(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if r.first != nil && len(exports) > int(*r.first) {
+		exports = exports[:*r.first]
+	}
+
+	l := make([]*searchExportResolver, 0, len(exports))
+	for _, export := range exports {
+		l = append(l, &searchExportResolver{export: *export})
+	}
+	return l, nil
+}
+
+func (r *searchExportConnectionResolver) TotalCount(ctx context.Context) (int32, error) {
+	count, err := db.SearchExports.CountByUser(ctx, r.userID)
+	return int32(count), err
+}
+
+func (r *searchExportConnectionResolver) PageInfo(ctx context.Context) (*graphqlutil.PageInfo, error) {
+	exports, err := r.compute(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return graphqlutil.HasNextPage(r.first != nil && len(exports) > int(*r.first)), nil
+}
@@ -0,0 +1,139 @@
+package graphqlbackend
+
+import (
+	"context"
+	"testing"
+
+	graphql "github.com/graph-gophers/graphql-go"
+	"github.com/graph-gophers/graphql-go/gqltesting"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/backend"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
+	"github.com/sourcegraph/sourcegraph/internal/actor"
+)
+
+func TestMutation_CreateSearchExport(t *testing.T) {
+	t.Run("authenticated as user", func(t *testing.T) {
+		resetMocks()
+		db.Mocks.SearchExports.Create = func(ctx context.Context, e *db.SearchExport) (*db.SearchExport, error) {
+			// 🚨 SECURITY: The export must run with the permissions of the current user.
+			if e.UserID != 1 {
+				t.Errorf("have user %d, want 1", e.UserID)
+			}
+			if e.Query != "repo:foo bar" || e.Version != "V2" || e.Format != db.SearchExportFormatJSONL {
+				t.Errorf("unexpected export %+v", e)
+			}
+			created := *e
+			created.ID = 1
+			created.State = db.SearchExportStateQueued
+			return &created, nil
+		}
+
+		gqltesting.RunTests(t, []*gqltesting.Test{
+			{
+				Context: actor.WithActor(context.Background(), &actor.Actor{UID: 1}),
+				Schema:  mustParseGraphQLSchema(t),
+				Query: `
+				mutation {
+					createSearchExport(query: "repo:foo bar", version: V2, format: JSONL) {
+						id
+						format
+						state
+						url
+					}
+				}
+			`,
+				ExpectedResult: `
+				{
+					"createSearchExport": {
+						"id": "U2VhcmNoRXhwb3J0OjE=",
+						"format": "JSONL",
+						"state": "QUEUED",
+						"url": null
+					}
+				}
+			`,
+			},
+		})
+	})
+
+	t.Run("not authenticated", func(t *testing.T) {
+		resetMocks()
+		_, err := (&schemaResolver{}).CreateSearchExport(context.Background(), &createSearchExportInput{
+			Query:   "bar",
+			Version: "V2",
+			Format:  db.SearchExportFormatCSV,
+		})
+		if err != backend.ErrNotAuthenticated {
+			t.Errorf("have error %v, want %v", err, backend.ErrNotAuthenticated)
+		}
+	})
+
+	t.Run("invalid query", func(t *testing.T) {
+		resetMocks()
+		db.Mocks.SearchExports.Create = func(ctx context.Context, e *db.SearchExport) (*db.SearchExport, error) {
+			t.Error("export of invalid query created")
+			return nil, nil
+		}
+
+		ctx := actor.WithActor(context.Background(), &actor.Actor{UID: 1})
+		_, err := (&schemaResolver{}).CreateSearchExport(ctx, &createSearchExportInput{
+			Query:   "unknownfield:foo",
+			Version: "V2",
+			Format:  db.SearchExportFormatCSV,
+		})
+		if err == nil {
+			t.Error("err == nil")
+		}
+	})
+}
+
+// 🚨 SECURITY: This tests that users can't view or cancel other users' search exports.
+func TestMutation_CancelSearchExport(t *testing.T) {
+	resetMocks()
+	db.Mocks.SearchExports.GetByID = func(ctx context.Context, id int64) (*db.SearchExport, error) {
+		return &db.SearchExport{ID: id, UserID: 1, State: db.SearchExportStateProcessing}, nil
+	}
+	db.Mocks.SearchExports.Cancel = func(ctx context.Context, id int64) (*db.SearchExport, error) {
+		return &db.SearchExport{ID: id, UserID: 1, State: db.SearchExportStateCanceled}, nil
+	}
+	db.Mocks.Users.GetByCurrentAuthUser = func(ctx context.Context) (*types.User, error) {
+		return &types.User{ID: actor.FromContext(ctx).UID}, nil
+	}
+	db.Mocks.Users.GetByID = func(ctx context.Context, id int32) (*types.User, error) {
+		return &types.User{ID: id, Username: "u"}, nil
+	}
+	defer resetMocks()
+
+	id := marshalSearchExportID(1)
+
+	t.Run("as user", func(t *testing.T) {
+		ctx := actor.WithActor(context.Background(), &actor.Actor{UID: 1})
+		export, err := (&schemaResolver{}).CancelSearchExport(ctx, &struct{ ID graphql.ID }{ID: id})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if export.State() != db.SearchExportStateCanceled {
+			t.Errorf("have state %q, want %q", export.State(), db.SearchExportStateCanceled)
+		}
+	})
+
+	t.Run("as other user", func(t *testing.T) {
+		ctx := actor.WithActor(context.Background(), &actor.Actor{UID: 2})
+		if _, err := (&schemaResolver{}).CancelSearchExport(ctx, &struct{ ID graphql.ID }{ID: id}); err == nil {
+			t.Error("err == nil")
+		}
+	})
+}
+
+func TestSearchExportResolver_URL(t *testing.T) {
+	r := &searchExportResolver{export: db.SearchExport{ID: 3, State: db.SearchExportStateProcessing}}
+	if url := r.URL(); url != nil {
+		t.Errorf("have URL %q for processing export, want nil", *url)
+	}
+
+	r.export.State = db.SearchExportStateCompleted
+	if url := r.URL(); url == nil || *url != "/.api/search/export/3" {
+		t.Errorf("have URL %v, want /.api/search/export/3", url)
+	}
+}
@@ -0,0 +1,295 @@
+package graphqlbackend
+
+import (
+	"context"
+	"math"
+	"regexp"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/inconshreveable/log15"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/backend"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/search"
+	"github.com/sourcegraph/sourcegraph/internal/search/query"
+	"github.com/sourcegraph/sourcegraph/internal/symbols/protocol"
+)
+
+// The weights of the signals that rankResults scores results with. The score
+// of a result is the weighted sum of its signals, so a weight states how much
+// a signal counts compared to the others.
+const (
+	// definitionWeight is added if a line match of a file match is the
+	// definition of a symbol matching the pattern.
+	definitionWeight = 4.0
+
+	// testPathWeight, vendoredPathWeight and generatedPathWeight are
+	// subtracted from results in test, vendored and generated files.
+	testPathWeight      = 2.0
+	vendoredPathWeight  = 3.0
+	generatedPathWeight = 3.0
+
+	// popularityWeight is added per order of magnitude of the number of stars
+	// of the result's repository, up to maxPopularity orders of magnitude.
+	popularityWeight = 0.5
+	maxPopularity    = 5
+
+	// densityWeight is added per doubling of the number of matches in a file,
+	// up to maxDensity doublings.
+	densityWeight = 0.5
+	maxDensity    = 4
+)
+
+const (
+	// maxDefinitionRepos is the number of repositories whose symbols are
+	// searched for definitions. The repositories with the most file matches
+	// are searched.
+	maxDefinitionRepos = 10
+
+	// maxDefinitionsPerRepo is the maximum number of symbols requested from a
+	// repository.
+	maxDefinitionsPerRepo = 500
+
+	// definitionsTimeout limits how long ranking waits for the symbols
+	// service. Symbols are ranking signals only, so results are ranked
+	// without them if the symbols of a repository aren't indexed in time.
+	definitionsTimeout = 2 * time.Second
+)
+
+var (
+	testPath = regexp.MustCompile(`(^|/)(tests?|__tests__|spec|testdata|fixtures?)/|_test\.[^/]+$|\.(test|spec)\.[^/]+$|(^|/)test_[^/]+\.py$`)
+
+	vendoredPath = regexp.MustCompile(`(^|/)(vendor|node_modules|third_party|thirdparty|bower_components|Godeps)/`)
+
+	generatedPath = regexp.MustCompile(`\.pb(\.gw)?\.go$|_pb2(_grpc)?\.py$|\.generated\.[^/]+$|_generated\.[^/]+$|(^|/)generated/|\.min\.(js|css)$|(^|/)bindata\.go$|\.(js|css)\.map$`)
+)
+
+// sortByRelevance reports whether the query asks for results ordered by
+// relevance (sort:relevance) instead of by repository and path.
+func (r *searchResolver) sortByRelevance() bool {
+	value, _ := r.query.StringValue(query.FieldSort)
+	return value == query.SortRelevance
+}
+
+// rankResults sorts results by relevance, most relevant first. Results with
+// the same score keep their order. The pattern is used to find symbol
+// definitions among file matches; it may be nil.
+//
+// Ranking uses these signals:
+//
+//   - whether a file match contains the definition of a symbol matching the
+//     pattern, according to the symbols service
+//   - whether the result is in a test, vendored or generated file
+//   - the number of stars of the result's repository on its code host
+//   - the number of matches in a file
+func rankResults(ctx context.Context, results []SearchResultResolver, pattern *search.TextPatternInfo) {
+	if len(results) < 2 {
+		return
+	}
+
+	var (
+		wg          sync.WaitGroup
+		stars       map[api.RepoID]int
+		definitions map[definitionKey]bool
+	)
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		stars = starCounts(ctx, results)
+	}()
+	go func() {
+		defer wg.Done()
+		definitions = findDefinitions(ctx, results, pattern)
+	}()
+	wg.Wait()
+
+	scores := make(map[SearchResultResolver]float64, len(results))
+	for _, result := range results {
+		scores[result] = relevance(result, stars, definitions)
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		return scores[results[i]] > scores[results[j]]
+	})
+}
+
+// relevance returns the score of a result from its signals.
+func relevance(result SearchResultResolver, stars map[api.RepoID]int, definitions map[definitionKey]bool) float64 {
+	var score float64
+
+	if repo := resultRepo(result); repo != nil {
+		score += popularityWeight * math.Min(math.Log10(1+float64(stars[repo.ID])), maxPopularity)
+	}
+
+	if path := resultPath(result); path != "" {
+		switch {
+		case vendoredPath.MatchString(path):
+			score -= vendoredPathWeight
+		case generatedPath.MatchString(path):
+			score -= generatedPathWeight
+		case testPath.MatchString(path):
+			score -= testPathWeight
+		}
+	}
+
+	if fm, ok := result.ToFileMatch(); ok {
+		matches := fm.MatchCount
+		if matches == 0 {
+			matches = len(fm.JLineMatches)
+		}
+		score += densityWeight * math.Min(math.Log2(1+float64(matches)), maxDensity)
+
+		for _, lm := range fm.JLineMatches {
+			if fm.Repo != nil && definitions[definitionKey{repo: fm.Repo.ID, commit: fm.CommitID, path: fm.JPath, line: lm.JLineNumber}] {
+				score += definitionWeight
+				break
+			}
+		}
+	}
+
+	return score
+}
+
+// resultRepo returns the repository of a result, or nil if it is unknown.
+func resultRepo(result SearchResultResolver) *types.Repo {
+	if r, ok := result.ToRepository(); ok {
+		return r.repo
+	}
+	if fm, ok := result.ToFileMatch(); ok {
+		return fm.Repo
+	}
+	if c, ok := result.ToCommitSearchResult(); ok && c.commit != nil && c.commit.repo != nil {
+		return c.commit.repo.repo
+	}
+	if c, ok := result.ToCodemodResult(); ok && c.commit != nil && c.commit.repo != nil {
+		return c.commit.repo.repo
+	}
+	return nil
+}
+
+// resultPath returns the file path of a result, or "" if it is not a result in
+// a file.
+func resultPath(result SearchResultResolver) string {
+	if fm, ok := result.ToFileMatch(); ok {
+		return fm.JPath
+	}
+	if c, ok := result.ToCodemodResult(); ok {
+		return c.path
+	}
+	return ""
+}
+
+// starCounts returns the star counts of the repositories of results. Errors
+// are logged, since popularity is only one of the ranking signals.
+func starCounts(ctx context.Context, results []SearchResultResolver) map[api.RepoID]int {
+	seen := make(map[api.RepoID]bool)
+	var ids []api.RepoID
+	for _, result := range results {
+		if repo := resultRepo(result); repo != nil && !seen[repo.ID] {
+			seen[repo.ID] = true
+			ids = append(ids, repo.ID)
+		}
+	}
+
+	stars, err := db.Repos.GetStarCounts(ctx, ids...)
+	if err != nil {
+		log15.Warn("ranking search results: getting star counts failed", "error", err)
+	}
+	return stars
+}
+
+// definitionKey identifies a line of a file at a commit.
+type definitionKey struct {
+	repo   api.RepoID
+	commit api.CommitID
+	path   string
+	line   int32 // 0-based, like lineMatch.JLineNumber
+}
+
+var mockListSymbols func(ctx context.Context, args search.SymbolsParameters) ([]protocol.Symbol, error)
+
+// findDefinitions returns the lines of the file matches in results that
+// define symbols matching the pattern. Only the repositories with the most
+// file matches are searched, and errors and timeouts are logged, since
+// definitions are only one of the ranking signals.
+func findDefinitions(ctx context.Context, results []SearchResultResolver, pattern *search.TextPatternInfo) map[definitionKey]bool {
+	if pattern == nil || pattern.Pattern == "" || pattern.IsStructuralPat {
+		return nil
+	}
+
+	type repoCommit struct {
+		repo   api.RepoID
+		name   api.RepoName
+		commit api.CommitID
+	}
+	fileMatches := make(map[repoCommit]int)
+	for _, result := range results {
+		if fm, ok := result.ToFileMatch(); ok && fm.Repo != nil && len(fm.JLineMatches) > 0 {
+			fileMatches[repoCommit{repo: fm.Repo.ID, name: fm.Repo.Name, commit: fm.CommitID}]++
+		}
+	}
+	searched := make([]repoCommit, 0, len(fileMatches))
+	for rc := range fileMatches {
+		searched = append(searched, rc)
+	}
+	sort.Slice(searched, func(i, j int) bool {
+		if fileMatches[searched[i]] != fileMatches[searched[j]] {
+			return fileMatches[searched[i]] > fileMatches[searched[j]]
+		}
+		return searched[i].repo < searched[j].repo
+	})
+	if len(searched) > maxDefinitionRepos {
+		searched = searched[:maxDefinitionRepos]
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, definitionsTimeout)
+	defer cancel()
+
+	var (
+		mu          sync.Mutex
+		wg          sync.WaitGroup
+		definitions = make(map[definitionKey]bool)
+	)
+	for _, rc := range searched {
+		wg.Add(1)
+		go func(rc repoCommit) {
+			defer wg.Done()
+
+			args := search.SymbolsParameters{
+				Repo:            rc.name,
+				CommitID:        rc.commit,
+				Query:           pattern.Pattern,
+				IsRegExp:        pattern.IsRegExp,
+				IsCaseSensitive: pattern.IsCaseSensitive,
+				IncludePatterns: pattern.IncludePatterns,
+				ExcludePattern:  pattern.ExcludePattern,
+				First:           maxDefinitionsPerRepo,
+			}
+			var (
+				symbols []protocol.Symbol
+				err     error
+			)
+			if mockListSymbols != nil {
+				symbols, err = mockListSymbols(ctx, args)
+			} else {
+				symbols, err = backend.Symbols.ListTags(ctx, args)
+			}
+			if err != nil {
+				log15.Debug("ranking search results: searching symbols failed", "repo", rc.name, "error", err)
+				return
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			for _, s := range symbols {
+				// Symbol lines are 1-based.
+				definitions[definitionKey{repo: rc.repo, commit: rc.commit, path: s.Path, line: int32(s.Line - 1)}] = true
+			}
+		}(rc)
+	}
+	wg.Wait()
+
+	return definitions
+}
@@ -0,0 +1,102 @@
+package graphqlbackend
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/search"
+	"github.com/sourcegraph/sourcegraph/internal/symbols/protocol"
+)
+
+func TestRankResults(t *testing.T) {
+	defer func() { db.Mocks.Repos.GetStarCounts = nil; mockListSymbols = nil }()
+
+	popular := &types.Repo{ID: 1, Name: "github.com/popular/repo"}
+	obscure := &types.Repo{ID: 2, Name: "github.com/obscure/repo"}
+	db.Mocks.Repos.GetStarCounts = func(ctx context.Context, ids ...api.RepoID) (map[api.RepoID]int, error) {
+		return map[api.RepoID]int{popular.ID: 10000}, nil
+	}
+	mockListSymbols = func(ctx context.Context, args search.SymbolsParameters) ([]protocol.Symbol, error) {
+		if args.Query != "NewClient" || !args.IsRegExp {
+			t.Errorf("unexpected symbols query %+v", args)
+		}
+		if args.Repo != obscure.Name {
+			return nil, nil
+		}
+		return []protocol.Symbol{{Name: "NewClient", Path: "client.go", Line: 10}}, nil
+	}
+
+	fileMatch := func(repo *types.Repo, path string, lines ...int32) *FileMatchResolver {
+		fm := &FileMatchResolver{JPath: path, Repo: repo, CommitID: "c"}
+		for _, line := range lines {
+			fm.JLineMatches = append(fm.JLineMatches, &lineMatch{JLineNumber: line})
+		}
+		return fm
+	}
+
+	// The input is sorted by repository and path, as by sortResults.
+	results := []SearchResultResolver{
+		fileMatch(obscure, "client.go", 9),
+		fileMatch(obscure, "client_test.go", 3),
+		fileMatch(obscure, "main.go", 20),
+		fileMatch(obscure, "vendor/github.com/other/client.go", 1, 2, 3, 4, 5),
+		fileMatch(popular, "client.go", 30),
+		fileMatch(popular, "client.pb.go", 40),
+		fileMatch(popular, "util.go", 50),
+	}
+	rankResults(context.Background(), results, &search.TextPatternInfo{Pattern: "NewClient", IsRegExp: true})
+
+	var have []string
+	for _, r := range results {
+		repo, path := r.searchResultURIs()
+		have = append(have, repo+"/"+path)
+	}
+	want := []string{
+		"github.com/obscure/repo/client.go", // defines the symbol
+		"github.com/popular/repo/client.go", // popular
+		"github.com/popular/repo/util.go",
+		"github.com/obscure/repo/main.go",
+		"github.com/popular/repo/client.pb.go", // generated
+		"github.com/obscure/repo/client_test.go",
+		"github.com/obscure/repo/vendor/github.com/other/client.go",
+	}
+	if diff := cmp.Diff(want, have); diff != "" {
+		t.Errorf("unexpected ranking (-want +have):\n%s", diff)
+	}
+}
+
+func TestRelevance_paths(t *testing.T) {
+	tests := map[string]float64{
+		"cmd/main.go":                 0,
+		"cmd/main_test.go":            -testPathWeight,
+		"src/__tests__/app.tsx":       -testPathWeight,
+		"src/app.test.ts":             -testPathWeight,
+		"testdata/golden.json":        -testPathWeight,
+		"vendor/github.com/a/b.go":    -vendoredPathWeight,
+		"web/node_modules/x/index.js": -vendoredPathWeight,
+		"api/api.pb.go":               -generatedPathWeight,
+		"dist/app.min.js":             -generatedPathWeight,
+		"migrations/bindata.go":       -generatedPathWeight,
+	}
+	for path, want := range tests {
+		t.Run(path, func(t *testing.T) {
+			// A file match without matches has no other signals.
+			fm := &FileMatchResolver{JPath: path}
+			if have := relevance(fm, nil, nil); have != want {
+				t.Errorf("have score %v, want %v", have, want)
+			}
+		})
+	}
+}
+
+func TestNewSearchImplementer_sortRelevancePaginated(t *testing.T) {
+	first := int32(10)
+	_, err := NewSearchImplementer(&SearchArgs{Query: "sort:relevance foo", Version: "V2", First: &first})
+	if err == nil {
+		t.Error("err == nil, want error for paginated search sorted by relevance")
+	}
+}
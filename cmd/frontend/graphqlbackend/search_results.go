@@ -39,6 +39,7 @@ import (
 	"github.com/sourcegraph/sourcegraph/internal/rcache"
 	"github.com/sourcegraph/sourcegraph/internal/search"
 	"github.com/sourcegraph/sourcegraph/internal/search/query"
+	"github.com/sourcegraph/sourcegraph/internal/symbols/protocol"
 	"github.com/sourcegraph/sourcegraph/internal/trace"
 	"github.com/sourcegraph/sourcegraph/internal/trace/ot"
 	"github.com/sourcegraph/sourcegraph/internal/vcs/git"
@@ -638,244 +639,554 @@ func (r *searchResolver) evaluateLeaf(ctx context.Context) (*SearchResultsResolv
 	return rr, err
 }
 
-// union returns the union of two sets of search results and merges common search data.
-func union(left, right *SearchResultsResolver) *SearchResultsResolver {
-	if right == nil {
-		return left
+// resultKey returns the key that identifies a search result in set operations
+// on results, and the name of the repository the result belongs to. File and
+// symbol matches are identified by their file, commit and diff results by
+// their commit, and repository results by their repository.
+func resultKey(result SearchResultResolver) (key, repo string) {
+	if fm, ok := result.ToFileMatch(); ok {
+		return "file:" + fm.uri, string(fm.Repo.Name)
 	}
-	if left == nil {
-		return right
+	if c, ok := result.ToCommitSearchResult(); ok {
+		repo = c.commit.repo.Name()
+		return "commit:" + repo + "@" + string(c.commit.oid), repo
 	}
-	if left.SearchResults != nil && right.SearchResults != nil {
-		left.SearchResults = append(left.SearchResults, right.SearchResults...)
-		// merge common search data.
-		left.searchResultsCommon.update(right.searchResultsCommon)
-		return left
-	} else if right.SearchResults != nil {
-		return right
+	if c, ok := result.ToCodemodResult(); ok {
+		repo = c.commit.repo.Name()
+		return "codemod:" + repo + "@" + string(c.commit.oid) + "/" + c.path, repo
 	}
-	return left
+	repo, _ = result.searchResultURIs()
+	return "repo:" + repo, repo
 }
 
-// intersect returns the intersection of two sets of search result content
-// matches, based on whether a single file path contains content matches in both
-// sets.
-func intersect(left, right *SearchResultsResolver) (*SearchResultsResolver, error) {
-	if left == nil || right == nil {
-		return nil, nil
+// resultSet indexes a set of search results for set operations.
+type resultSet struct {
+	results map[string]SearchResultResolver
+	repos   map[string]bool // repos that contain a result
+	matched map[string]bool // repos that are themselves a result
+}
+
+func newResultSet(results []SearchResultResolver) *resultSet {
+	s := &resultSet{
+		results: make(map[string]SearchResultResolver, len(results)),
+		repos:   make(map[string]bool),
+		matched: make(map[string]bool),
+	}
+	for _, result := range results {
+		key, repo := resultKey(result)
+		s.results[key] = result
+		s.repos[repo] = true
+		if _, ok := result.ToRepository(); ok {
+			s.matched[repo] = true
+		}
 	}
+	return s
+}
 
-	rFileMatches := make(map[string]*FileMatchResolver)
+// match returns the result in s that corresponds to result, if any. Results
+// of different types correspond when they belong to the same repository and
+// one of them is a repository result, so that a group that only scopes a
+// search (like "(repo:foo or repo:bar)") restricts the results of the
+// expressions it is combined with.
+func (s *resultSet) match(result SearchResultResolver) (SearchResultResolver, bool) {
+	key, repo := resultKey(result)
+	if other, ok := s.results[key]; ok {
+		return other, true
+	}
+	if _, ok := result.ToRepository(); ok && s.repos[repo] {
+		return nil, true
+	}
+	return nil, s.matched[repo]
+}
 
-	for _, r := range right.SearchResults {
-		if fileMatch, ok := r.ToFileMatch(); ok {
-			rFileMatches[fileMatch.uri] = fileMatch
-		}
+// mergeResults merges the matches of right into left when both are file
+// matches of the same file. Other results are identified by their key alone,
+// so left is kept as is.
+func mergeResults(left, right SearchResultResolver) {
+	leftFileMatch, ok := left.ToFileMatch()
+	if !ok || right == nil {
+		return
+	}
+	rightFileMatch, ok := right.ToFileMatch()
+	if !ok || leftFileMatch == rightFileMatch {
+		return
 	}
 
-	var merged []SearchResultResolver
-	for _, ltmp := range left.SearchResults {
-		ltmpFileMatch, ok := ltmp.ToFileMatch()
+	lines := make(map[int32]*lineMatch, len(leftFileMatch.JLineMatches))
+	for _, line := range leftFileMatch.JLineMatches {
+		lines[line.JLineNumber] = line
+	}
+	for _, line := range rightFileMatch.JLineMatches {
+		existing, ok := lines[line.JLineNumber]
 		if !ok {
+			leftFileMatch.JLineMatches = append(leftFileMatch.JLineMatches, line)
+			leftFileMatch.MatchCount += len(line.JOffsetAndLengths)
+			lines[line.JLineNumber] = line
 			continue
 		}
+		for _, offsetAndLength := range line.JOffsetAndLengths {
+			if !containsOffsetAndLength(existing.JOffsetAndLengths, offsetAndLength) {
+				existing.JOffsetAndLengths = append(existing.JOffsetAndLengths, offsetAndLength)
+				leftFileMatch.MatchCount++
+			}
+		}
+		sort.Slice(existing.JOffsetAndLengths, func(i, j int) bool {
+			return existing.JOffsetAndLengths[i][0] < existing.JOffsetAndLengths[j][0]
+		})
+	}
+	sort.SliceStable(leftFileMatch.JLineMatches, func(i, j int) bool {
+		return leftFileMatch.JLineMatches[i].JLineNumber < leftFileMatch.JLineMatches[j].JLineNumber
+	})
+	leftFileMatch.JLimitHit = leftFileMatch.JLimitHit || rightFileMatch.JLimitHit
 
-		rtmpFileMatch := rFileMatches[ltmpFileMatch.uri]
-		if rtmpFileMatch == nil {
-			continue
+	symbols := make(map[protocol.Symbol]bool, len(leftFileMatch.symbols))
+	for _, symbol := range leftFileMatch.symbols {
+		symbols[symbol.symbol] = true
+	}
+	for _, symbol := range rightFileMatch.symbols {
+		if !symbols[symbol.symbol] {
+			leftFileMatch.symbols = append(leftFileMatch.symbols, symbol)
+			symbols[symbol.symbol] = true
 		}
+	}
+}
 
-		ltmpFileMatch.JLineMatches = append(ltmpFileMatch.JLineMatches, rtmpFileMatch.JLineMatches...)
-		merged = append(merged, ltmp)
+func containsOffsetAndLength(offsetAndLengths [][2]int32, offsetAndLength [2]int32) bool {
+	for _, o := range offsetAndLengths {
+		if o == offsetAndLength {
+			return true
+		}
 	}
-	left.SearchResults = merged
+	return false
+}
+
+// combine returns left with its results set to results, merging the common
+// search data of right and recomputing the result count.
+func combine(left, right *SearchResultsResolver, results []SearchResultResolver) *SearchResultsResolver {
+	left.SearchResults = results
 	left.searchResultsCommon.update(right.searchResultsCommon)
-	// for intersect we want the newly computed intersection size.
-	left.searchResultsCommon.resultCount = int32(len(merged))
-	return left, nil
-}
-
-// evaluateAnd performs set intersection on result sets. It collects results for
-// all expressions that are ANDed together by searching for each subexpression
-// and then intersects those results that are in the same repo/file path. To
-// collect N results for count:N, we need to opportunistically ask for more than
-// N results for each subexpression (since intersect can never yield more than N,
-// and likely yields fewer than N results). Thus, we perform a search of 2*N for
-// each expression, and if the intersection does not yield N results, and is not
-// exhaustive for every expression, we rerun the search by doubling count again.
-func (r *searchResolver) evaluateAnd(ctx context.Context, scopeParameters []query.Node, operands []query.Node) (*SearchResultsResolver, error) {
-	if len(operands) == 0 {
-		return nil, nil
+	left.searchResultsCommon.resultCount = left.MatchCount()
+	if left.alert == nil {
+		left.alert = right.alert
 	}
+	return left
+}
 
-	var err error
-	var result *SearchResultsResolver
-	var new *SearchResultsResolver
+// union returns the union of two sets of search results and merges common
+// search data. Results that occur in both sets are merged.
+func union(left, right *SearchResultsResolver) *SearchResultsResolver {
+	if right == nil {
+		return left
+	}
+	if left == nil {
+		return right
+	}
 
-	// The number of results we want. Note that for intersect, this number
-	// corresponds to documents, not line matches. By default, we ask for at
-	// least 5 documents to fill the result page.
-	want := 5
+	seen := make(map[string]SearchResultResolver, len(left.SearchResults))
+	results := make([]SearchResultResolver, 0, len(left.SearchResults)+len(right.SearchResults))
+	for _, result := range left.SearchResults {
+		key, _ := resultKey(result)
+		if existing, ok := seen[key]; ok {
+			mergeResults(existing, result)
+			continue
+		}
+		seen[key] = result
+		results = append(results, result)
+	}
+	for _, result := range right.SearchResults {
+		key, _ := resultKey(result)
+		if existing, ok := seen[key]; ok {
+			mergeResults(existing, result)
+			continue
+		}
+		seen[key] = result
+		results = append(results, result)
+	}
+	return combine(left, right, results)
+}
 
-	var countStr string
-	query.VisitField(scopeParameters, "count", func(value string, _, _ bool) {
-		countStr = value
-	})
-	if countStr != "" {
-		// Override the value of count, if specified.
-		want, _ = strconv.Atoi(countStr) // Invariant: count is validated.
-	} else {
-		scopeParameters = append(scopeParameters, query.Parameter{
-			Field: "count",
-			Value: strconv.FormatInt(int64(want), 10),
-		})
+// intersect returns the intersection of two sets of search results and merges
+// common search data. A result of left is kept if right contains the same
+// result (see resultSet.match), in which case the matches of both are merged.
+func intersect(left, right *SearchResultsResolver) *SearchResultsResolver {
+	if left == nil || right == nil {
+		return nil
 	}
 
-	tryCount := want * 1000     // Opportunistic approximation for the number of results to get for an intersection.
-	maxResultsForRetry := 20000 // When we retry, cap the max search results we request for each expression if search continues to not be exhaustive. Alert if exceeded.
+	rightSet := newResultSet(right.SearchResults)
+	var results []SearchResultResolver
+	for _, result := range left.SearchResults {
+		other, ok := rightSet.match(result)
+		if !ok {
+			continue
+		}
+		mergeResults(result, other)
+		results = append(results, result)
+	}
+	return combine(left, right, results)
+}
 
-	var exhausted bool
-	for {
-		scopeParameters = query.MapParameter(scopeParameters, func(field, value string, negated bool) query.Node {
-			if field == "count" {
-				value = strconv.FormatInt(int64(tryCount), 10)
-			}
-			return query.Parameter{Field: field, Value: value, Negated: negated}
-		})
+// difference returns the results of left that are not in right, and merges
+// common search data. Since results missing from right because it hit a limit
+// may wrongly remain in the difference, the limit of right carries over.
+func difference(left, right *SearchResultsResolver) *SearchResultsResolver {
+	if left == nil || right == nil {
+		return left
+	}
 
-		result, err = r.evaluatePatternExpression(ctx, scopeParameters, operands[0])
-		if err != nil {
-			return nil, err
+	rightSet := newResultSet(right.SearchResults)
+	var results []SearchResultResolver
+	for _, result := range left.SearchResults {
+		if _, ok := rightSet.match(result); !ok {
+			results = append(results, result)
 		}
-		exhausted = !result.limitHit
-		for _, term := range operands[1:] {
-			new, err = r.evaluatePatternExpression(ctx, scopeParameters, term)
-			if err != nil {
-				return nil, err
+	}
+	return combine(left, right, results)
+}
+
+// isPatternParameter returns true if parameter is a search pattern, as opposed
+// to a parameter that scopes the search (e.g., repo:, file:, lang:, type:).
+func isPatternParameter(parameter query.Parameter) bool {
+	return parameter.Field == query.FieldDefault || parameter.Field == query.FieldContent
+}
+
+// containsSearchPattern returns true if node or any of its descendents is a
+// search pattern.
+func containsSearchPattern(node query.Node) bool {
+	var result bool
+	query.VisitParameter([]query.Node{node}, func(field, _ string, _, _ bool) {
+		if field == query.FieldDefault || field == query.FieldContent {
+			result = true
+		}
+	})
+	return result
+}
+
+// scopeOverrideFields are the fields whose value in a nested expression
+// replaces their value in the enclosing scope, instead of further narrowing it
+// like repo: or file: do.
+var scopeOverrideFields = map[string]bool{
+	query.FieldCase:        true,
+	query.FieldType:        true,
+	query.FieldPatternType: true,
+	query.FieldTimeout:     true,
+	query.FieldIndex:       true,
+	query.FieldFork:        true,
+	query.FieldArchived:    true,
+}
+
+// nestScope returns the scope parameters of an expression nested in scope
+// that itself has the scope parameters local.
+func nestScope(scope, local []query.Node) []query.Node {
+	if len(local) == 0 {
+		return scope
+	}
+	overridden := make(map[string]bool)
+	for _, node := range local {
+		if parameter := node.(query.Parameter); scopeOverrideFields[parameter.Field] {
+			overridden[parameter.Field] = true
+		}
+	}
+	nested := make([]query.Node, 0, len(scope)+len(local))
+	for _, node := range scope {
+		if parameter, ok := node.(query.Parameter); ok && overridden[parameter.Field] {
+			continue
+		}
+		nested = append(nested, node)
+	}
+	return append(nested, local...)
+}
+
+// removeParameters returns nodes without the parameters of the given fields.
+// Operators that are left without operands are removed.
+func removeParameters(nodes []query.Node, fields ...string) []query.Node {
+	var result []query.Node
+	for _, node := range nodes {
+		switch term := node.(type) {
+		case query.Parameter:
+			remove := false
+			for _, field := range fields {
+				if term.Field == field {
+					remove = true
+				}
+			}
+			if !remove {
+				result = append(result, term)
 			}
-			if new != nil {
-				exhausted = exhausted && !new.limitHit
-				result, err = intersect(result, new)
+		case query.Operator:
+			operands := removeParameters(term.Operands, fields...)
+			if len(operands) == 1 && term.Kind != query.Concat {
+				result = append(result, operands[0])
+			} else if len(operands) > 0 {
+				result = append(result, query.Operator{Kind: term.Kind, Operands: operands})
 			}
 		}
-		if exhausted {
-			break
+	}
+	return result
+}
+
+// hasIntersection returns true if evaluating nodes intersects or subtracts the
+// results of searches, which may yield fewer results than the searches do.
+func hasIntersection(nodes []query.Node) bool {
+	for _, node := range nodes {
+		term, ok := node.(query.Operator)
+		if !ok || term.Kind == query.Concat {
+			continue
 		}
-		if result.searchResultsCommon.resultCount >= int32(want) {
-			break
+		if term.Kind == query.And {
+			var patterns int
+			for _, operand := range term.Operands {
+				if parameter, ok := operand.(query.Parameter); !ok || isPatternParameter(parameter) {
+					patterns++
+				}
+			}
+			if patterns > 1 {
+				return true
+			}
 		}
-		// If the result size set is not big enough, and we haven't
-		// exhausted search on all expressions, double the tryCount and search more.
-		tryCount *= tryCount
-		if tryCount > maxResultsForRetry {
-			// We've capped out what we're willing to do, throw alert.
-			return &SearchResultsResolver{alert: alertForCappedAndExpression()}, nil
+		if hasIntersection(term.Operands) {
+			return true
 		}
 	}
-	result.limitHit = !exhausted
-	return result, nil
+	return false
 }
 
-// evaluateOr performs set union on result sets. It collects results for all
-// expressions that are ORed together by searching for each subexpression. If
-// the maximum number of results are reached after evaluating a subexpression,
-// we shortcircuit and return results immediately.
-func (r *searchResolver) evaluateOr(ctx context.Context, scopeParameters []query.Node, operands []query.Node) (*SearchResultsResolver, error) {
-	if len(operands) == 0 {
-		return nil, nil
+// leafResolver returns a resolver that performs a single search for the query
+// q. It shares the search backends of r, but not its cached repositories,
+// since q may be scoped to different repositories than other parts of r's
+// query.
+func (r *searchResolver) leafResolver(q []query.Node) *searchResolver {
+	return &searchResolver{
+		query:         &query.AndOrQuery{Query: q},
+		originalQuery: r.originalQuery,
+		patternType:   r.patternType,
+		zoekt:         r.zoekt,
+		searcherURLs:  r.searcherURLs,
 	}
+}
 
-	var countStr string
-	wantCount := defaultMaxSearchResults
-	query.VisitField(scopeParameters, "count", func(value string, _, _ bool) {
-		countStr = value
-	})
-	if countStr != "" {
-		wantCount, _ = strconv.Atoi(countStr) // Invariant: count is validated.
+// evaluateAnd evaluates an and-expression. Operands that are not search
+// patterns, like repo: or file:, scope the evaluation of all other operands,
+// including nested expressions. The results of the remaining operands are
+// intersected, and the results of negated search patterns (as in "not foo")
+// are removed from the intersection.
+func (r *searchResolver) evaluateAnd(ctx context.Context, scopeParameters []query.Node, operands []query.Node) (*SearchResultsResolver, error) {
+	var local, patterns, negated []query.Node
+	for _, node := range operands {
+		parameter, ok := node.(query.Parameter)
+		switch {
+		case !ok:
+			patterns = append(patterns, node)
+		case !isPatternParameter(parameter):
+			local = append(local, parameter)
+		case parameter.Negated:
+			parameter.Negated = false
+			negated = append(negated, parameter)
+		default:
+			patterns = append(patterns, parameter)
+		}
 	}
+	scopeParameters = nestScope(scopeParameters, local)
 
-	result, err := r.evaluatePatternExpression(ctx, scopeParameters, operands[0])
-	if err != nil {
-		return nil, err
+	if len(patterns) == 0 {
+		if len(negated) > 0 {
+			return nil, &query.UnsupportedError{Msg: "cannot evaluate: a negated search pattern must be combined with a search pattern that is not negated"}
+		}
+		// The expression only scopes a search, e.g. "repo:foo".
+		return r.leafResolver(scopeParameters).evaluateLeaf(ctx)
 	}
-	if result.searchResultsCommon.resultCount > int32(wantCount) {
-		result.SearchResults = result.SearchResults[:wantCount]
-		result.searchResultsCommon.resultCount = int32(wantCount)
-		return result, nil
+
+	var result *SearchResultsResolver
+	for _, term := range patterns {
+		termScope := scopeParameters
+		if !containsSearchPattern(term) {
+			// An expression without search patterns, like "(repo:foo or
+			// repo:bar)", restricts the other operands to the results of
+			// the default search for its scope (e.g., repositories), not
+			// to the result types of the other operands.
+			termScope = removeParameters(scopeParameters, query.FieldType)
+		}
+		new, err := r.evaluatePatternExpression(ctx, termScope, term)
+		if err != nil {
+			return nil, err
+		}
+		if new == nil || (new.alert != nil && len(new.SearchResults) == 0) {
+			return new, nil
+		}
+		if result == nil {
+			result = new
+		} else {
+			result = intersect(result, new)
+		}
+		if len(result.SearchResults) == 0 {
+			// Nothing left to intersect with.
+			return result, nil
+		}
 	}
-	var new *SearchResultsResolver
-	for _, term := range operands[1:] {
-		new, err = r.evaluatePatternExpression(ctx, scopeParameters, term)
+	for _, term := range negated {
+		new, err := r.evaluatePatternExpression(ctx, scopeParameters, term)
 		if err != nil {
 			return nil, err
 		}
-		if new != nil {
-			result = union(result, new)
-			if result.searchResultsCommon.resultCount > int32(wantCount) {
-				result.SearchResults = result.SearchResults[:wantCount]
-				result.searchResultsCommon.resultCount = int32(wantCount)
-				return result, nil
-			}
+		if new != nil && new.alert != nil && len(new.SearchResults) == 0 {
+			return new, nil
 		}
+		result = difference(result, new)
 	}
 	return result, nil
 }
 
-func (r *searchResolver) evaluateOperator(ctx context.Context, scopeParameters []query.Node, operator query.Operator) (*SearchResultsResolver, error) {
-	if len(operator.Operands) == 0 {
-		return nil, nil
-	}
+// evaluateOr evaluates an or-expression by performing set union on the result
+// sets of its operands. Each operand is evaluated in its own scope.
+func (r *searchResolver) evaluateOr(ctx context.Context, scopeParameters []query.Node, operands []query.Node) (*SearchResultsResolver, error) {
 	var result *SearchResultsResolver
-	var err error
-	if operator.Kind == query.And {
-		result, err = r.evaluateAnd(ctx, scopeParameters, operator.Operands)
-	} else {
-		result, err = r.evaluateOr(ctx, scopeParameters, operator.Operands)
-	}
-	if err != nil {
-		return nil, err
+	for _, term := range operands {
+		new, err := r.evaluatePatternExpression(ctx, scopeParameters, term)
+		if err != nil {
+			return nil, err
+		}
+		result = union(result, new)
 	}
 	return result, nil
 }
 
-// evaluatePatternExpression evaluates a search pattern containing and/or expressions.
+// evaluatePatternExpression evaluates a node of an and/or query in the scope of
+// scopeParameters.
 func (r *searchResolver) evaluatePatternExpression(ctx context.Context, scopeParameters []query.Node, node query.Node) (*SearchResultsResolver, error) {
 	switch term := node.(type) {
 	case query.Operator:
-		if term.Kind == query.And || term.Kind == query.Or {
-			return r.evaluateOperator(ctx, scopeParameters, term)
-		} else if term.Kind == query.Concat {
-			q := append(scopeParameters, term)
-			r.query = query.AndOrQuery{Query: q}
-			return r.evaluateLeaf(ctx)
+		switch term.Kind {
+		case query.And:
+			return r.evaluateAnd(ctx, scopeParameters, term.Operands)
+		case query.Or:
+			return r.evaluateOr(ctx, scopeParameters, term.Operands)
+		case query.Concat:
+			return r.leafResolver(append(scopeParameters[:len(scopeParameters):len(scopeParameters)], term)).evaluateLeaf(ctx)
 		}
 	case query.Parameter:
-		q := append(scopeParameters, term)
-		r.query = query.AndOrQuery{Query: q}
-		return r.evaluateLeaf(ctx)
+		if isPatternParameter(term) && !term.Negated {
+			return r.leafResolver(append(scopeParameters[:len(scopeParameters):len(scopeParameters)], term)).evaluateLeaf(ctx)
+		}
+		// Scope parameters and negated patterns are evaluated like an
+		// and-expression of one operand.
+		return r.evaluateAnd(ctx, scopeParameters, []query.Node{term})
 	}
 	// Unreachable.
 	return nil, fmt.Errorf("unrecognized type %s in evaluatePatternExpression", reflect.TypeOf(node).String())
 }
 
 // evaluate evaluates all expressions of a search query.
+//
+// To collect N results for count:N, we need to opportunistically ask for more
+// than N results for each search when the query intersects or subtracts
+// results, since those can yield far fewer results than they are given. If
+// that does not yield N results, and the searches were not exhaustive, we rerun
+// them with double the count, up to a limit.
 func (r *searchResolver) evaluate(ctx context.Context, q []query.Node) (*SearchResultsResolver, error) {
-	scopeParameters, pattern, err := query.PartitionSearchPattern(q)
-	if err != nil {
-		return &SearchResultsResolver{alert: alertForQuery("", err)}, nil
+	// The number of results we want. Note that this number corresponds to
+	// documents, not line matches.
+	want := defaultMaxSearchResults
+	intersects := hasIntersection(q)
+	if intersects {
+		// By default, ask for at least 5 documents to fill the result page.
+		want = 5
+	}
+	query.VisitField(q, query.FieldCount, func(value string, _, _ bool) {
+		want, _ = strconv.Atoi(value) // Invariant: count is validated.
+	})
+
+	// Paginated requests are served from the combined results, so every
+	// search must find the results of all pages up to the requested one.
+	var offset int
+	if r.pagination != nil {
+		if r.pagination.cursor != nil {
+			offset = int(r.pagination.cursor.ResultOffset)
+		}
+		want = offset + int(r.pagination.limit)
 	}
-	if pattern == nil {
-		r.query = query.AndOrQuery{Query: scopeParameters}
-		return r.evaluateLeaf(ctx)
+
+	// Count, pagination and sorting apply to the query as a whole, so they
+	// are removed from nested expressions and set on each search below.
+	q = removeParameters(q, query.FieldCount, query.FieldStable, query.FieldSort)
+
+	tryCount := want
+	maxResultsForRetry := 20000 // When we retry, cap the max search results we request for each search if search continues to not be exhaustive. Alert if exceeded.
+	if intersects {
+		tryCount = want * 1000 // Opportunistic approximation for the number of results to get for a set operation.
+		if tryCount > maxResultsForRetry {
+			tryCount = maxResultsForRetry
+		}
 	}
-	result, err := r.evaluatePatternExpression(ctx, scopeParameters, pattern)
-	if err != nil {
-		return nil, err
+
+	var result *SearchResultsResolver
+	for {
+		scopeParameters := []query.Node{query.Parameter{Field: query.FieldCount, Value: strconv.Itoa(tryCount)}}
+		var err error
+		result, err = r.evaluatePatternExpression(ctx, scopeParameters, query.Operator{Kind: query.And, Operands: q})
+		if err != nil {
+			if _, ok := err.(*query.UnsupportedError); ok {
+				return &SearchResultsResolver{alert: alertForQuery("", err)}, nil
+			}
+			return nil, err
+		}
+		if result == nil {
+			result = &SearchResultsResolver{}
+		}
+		if !intersects || !result.limitHit || len(result.SearchResults) >= want || result.alert != nil {
+			break
+		}
+		// If the result set is not big enough, and we haven't exhausted
+		// search on all expressions, double the tryCount and search more.
+		if tryCount >= maxResultsForRetry {
+			// We've capped out what we're willing to do, alert along with
+			// the results we found.
+			result.alert = alertForCappedAndExpression()
+			break
+		}
+		tryCount *= 2
+		if tryCount > maxResultsForRetry {
+			tryCount = maxResultsForRetry
+		}
 	}
+
 	sortResults(result.SearchResults)
+	if r.sortByRelevance() {
+		// The patterns of and/or queries can't be used to find symbol
+		// definitions, so they are ranked by the other signals.
+		rankResults(ctx, result.SearchResults, nil)
+	}
+	if r.pagination != nil {
+		return paginateResults(result, offset, int(r.pagination.limit)), nil
+	}
+	if len(result.SearchResults) > want {
+		result.SearchResults = result.SearchResults[:want]
+		result.limitHit = true
+	}
+	result.searchResultsCommon.resultCount = result.MatchCount()
 	return result, nil
 }
 
+// paginateResults returns the page of limit results after offset of the
+// sorted results of an and/or query.
+func paginateResults(result *SearchResultsResolver, offset, limit int) *SearchResultsResolver {
+	results := result.SearchResults
+	if offset > len(results) {
+		offset = len(results)
+	}
+	end := offset + limit
+	if end > len(results) {
+		end = len(results)
+	}
+	result.SearchResults = results[offset:end]
+	result.cursor = &searchCursor{
+		ResultOffset: int32(end),
+		Finished:     end == len(results) && !result.limitHit,
+	}
+	result.limitHit = !result.cursor.Finished
+	result.searchResultsCommon.resultCount = result.MatchCount()
+	return result
+}
+
 func (r *searchResolver) Results(ctx context.Context) (*SearchResultsResolver, error) {
 	switch q := r.query.(type) {
 	case *query.OrdinaryQuery:
@@ -1470,6 +1781,7 @@ func (r *searchResolver) doResults(ctx context.Context, forceOnlyResultType stri
 					common.update(*repoCommon)
 					commonMu.Unlock()
 				}
+				sendSearchEvent(ctx, newSearchEvent(repoResults, repoCommon))
 			})
 		case "symbol":
 			wg := waitGroup(len(resultTypes) == 1)
@@ -1502,6 +1814,7 @@ func (r *searchResolver) doResults(ctx context.Context, forceOnlyResultType stri
 					common.update(*symbolsCommon)
 					commonMu.Unlock()
 				}
+				sendSearchEvent(ctx, newSearchEvent(fileMatchesToSearchResults(symbolFileMatches), symbolsCommon))
 			})
 		case "file", "path":
 			if searchedFileContentsOrPaths {
@@ -1558,6 +1871,8 @@ func (r *searchResolver) doResults(ctx context.Context, forceOnlyResultType stri
 					common.update(*fileCommon)
 					commonMu.Unlock()
 				}
+				// searchFilesInRepos sends its results to streaming searches
+				// itself, as soon as each repository is searched.
 			})
 		case "diff":
 			wg := waitGroup(len(resultTypes) == 1)
@@ -1597,6 +1912,7 @@ func (r *searchResolver) doResults(ctx context.Context, forceOnlyResultType stri
 					common.update(*diffCommon)
 					commonMu.Unlock()
 				}
+				sendSearchEvent(ctx, newSearchEvent(diffResults, diffCommon))
 			})
 		case "commit":
 			wg := waitGroup(len(resultTypes) == 1)
@@ -1637,6 +1953,7 @@ func (r *searchResolver) doResults(ctx context.Context, forceOnlyResultType stri
 					common.update(*commitCommon)
 					commonMu.Unlock()
 				}
+				sendSearchEvent(ctx, newSearchEvent(commitResults, commitCommon))
 			})
 		case "codemod":
 			wg := waitGroup(true)
@@ -1661,6 +1978,7 @@ func (r *searchResolver) doResults(ctx context.Context, forceOnlyResultType stri
 					common.update(*codemodCommon)
 					commonMu.Unlock()
 				}
+				sendSearchEvent(ctx, newSearchEvent(codemodResults, codemodCommon))
 			})
 		}
 	}
@@ -1702,6 +2020,9 @@ func (r *searchResolver) doResults(ctx context.Context, forceOnlyResultType stri
 	}
 
 	sortResults(results)
+	if r.sortByRelevance() {
+		rankResults(ctx, results, p)
+	}
 
 	resultsResolver := SearchResultsResolver{
 		start:               start,
@@ -1455,7 +1455,7 @@ func Test_SearchResultsResolver_ApproximateResultCount(t *testing.T) {
 }
 
 func TestSearchResolver_evaluateWarning(t *testing.T) {
-	q, _ := query.ProcessAndOr("file:foo or file:bar")
+	q, _ := query.ProcessAndOr("not bar or file:foo")
 	wantPrefix := "I'm having trouble understsanding that query."
 	andOrQuery, _ := q.(*query.AndOrQuery)
 	got, _ := (&searchResolver{}).evaluate(context.Background(), andOrQuery.Query)
@@ -1473,3 +1473,187 @@ func TestSearchResolver_evaluateWarning(t *testing.T) {
 		}
 	})
 }
+
+func TestSearchResultsSetOperations(t *testing.T) {
+	repoA := &types.Repo{ID: 1, Name: "a"}
+	repoB := &types.Repo{ID: 2, Name: "b"}
+	fileMatch := func(repo *types.Repo, path string, lines ...int32) *FileMatchResolver {
+		fm := &FileMatchResolver{JPath: path, uri: fileMatchURI(repo.Name, "", path), Repo: repo}
+		for _, line := range lines {
+			fm.JLineMatches = append(fm.JLineMatches, &lineMatch{JLineNumber: line, JOffsetAndLengths: [][2]int32{{0, 1}}})
+			fm.MatchCount++
+		}
+		return fm
+	}
+	commit := func(repo *types.Repo, oid string) *commitSearchResultResolver {
+		return &commitSearchResultResolver{commit: &GitCommitResolver{repo: &RepositoryResolver{repo: repo}, oid: GitObjectID(oid)}}
+	}
+	resolver := func(results ...SearchResultResolver) *SearchResultsResolver {
+		return &SearchResultsResolver{SearchResults: results}
+	}
+	describe := func(r *SearchResultsResolver) []string {
+		var descriptions []string
+		for _, result := range r.SearchResults {
+			switch m := result.(type) {
+			case *RepositoryResolver:
+				descriptions = append(descriptions, fmt.Sprintf("repo:%s", m.repo.Name))
+			case *FileMatchResolver:
+				var lines []string
+				for _, line := range m.JLineMatches {
+					lines = append(lines, fmt.Sprint(line.JLineNumber))
+				}
+				descriptions = append(descriptions, fmt.Sprintf("%s/%s:%s", m.Repo.Name, m.JPath, strings.Join(lines, ",")))
+			case *commitSearchResultResolver:
+				descriptions = append(descriptions, fmt.Sprintf("%s@%s", m.commit.repo.Name(), m.commit.oid))
+			}
+		}
+		return descriptions
+	}
+
+	tests := []struct {
+		name      string
+		got       func() *SearchResultsResolver
+		want      []string
+		wantCount int32
+	}{
+		{
+			name: "union merges line matches of the same file",
+			got: func() *SearchResultsResolver {
+				return union(
+					resolver(fileMatch(repoA, "x.go", 1, 2), commit(repoA, "c1")),
+					resolver(fileMatch(repoA, "x.go", 2, 3), fileMatch(repoB, "y.go", 1), commit(repoA, "c1")),
+				)
+			},
+			want:      []string{"a/x.go:1,2,3", "a@c1", "b/y.go:1"},
+			wantCount: 5,
+		},
+		{
+			name: "intersect files",
+			got: func() *SearchResultsResolver {
+				return intersect(
+					resolver(fileMatch(repoA, "x.go", 1), fileMatch(repoB, "y.go", 1)),
+					resolver(fileMatch(repoA, "x.go", 5), fileMatch(repoA, "y.go", 1)),
+				)
+			},
+			want:      []string{"a/x.go:1,5"},
+			wantCount: 2,
+		},
+		{
+			name: "intersect commits",
+			got: func() *SearchResultsResolver {
+				return intersect(
+					resolver(commit(repoA, "c1"), commit(repoA, "c2"), commit(repoB, "c1")),
+					resolver(commit(repoA, "c2"), commit(repoB, "c1")),
+				)
+			},
+			want:      []string{"a@c2", "b@c1"},
+			wantCount: 2,
+		},
+		{
+			name: "intersect with repository results scopes other results",
+			got: func() *SearchResultsResolver {
+				return intersect(
+					resolver(fileMatch(repoA, "x.go", 1), commit(repoB, "c1")),
+					resolver(&RepositoryResolver{repo: repoB}),
+				)
+			},
+			want:      []string{"b@c1"},
+			wantCount: 1,
+		},
+		{
+			name: "difference",
+			got: func() *SearchResultsResolver {
+				return difference(
+					resolver(fileMatch(repoA, "x.go", 1), fileMatch(repoA, "y.go", 1), &RepositoryResolver{repo: repoB}),
+					resolver(fileMatch(repoA, "y.go", 7), fileMatch(repoB, "z.go", 1)),
+				)
+			},
+			want:      []string{"a/x.go:1"},
+			wantCount: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.got()
+			if diff := cmp.Diff(tt.want, describe(got)); diff != "" {
+				t.Errorf("unexpected results (-want +got):\n%s", diff)
+			}
+			if got.resultCount != tt.wantCount {
+				t.Errorf("got result count %d, want %d", got.resultCount, tt.wantCount)
+			}
+		})
+	}
+}
+
+func TestSearchResolver_andOrScopes(t *testing.T) {
+	parse := func(input string) []query.Node {
+		q, err := query.ProcessAndOr(input)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return q.(*query.AndOrQuery).Query
+	}
+
+	intersections := map[string]bool{
+		"a or b":                        false,
+		"repo:foo a and b":              true,
+		"(repo:foo a) or (repo:bar b)":  false,
+		"(repo:foo and a) or (b and c)": true,
+		"a and not b":                   true,
+		"type:commit a or type:diff b":  false,
+	}
+	for input, want := range intersections {
+		if got := hasIntersection(parse(input)); got != want {
+			t.Errorf("hasIntersection(%q) = %v, want %v", input, got, want)
+		}
+	}
+
+	scope := []query.Node{
+		query.Parameter{Field: "repo", Value: "foo"},
+		query.Parameter{Field: "type", Value: "commit"},
+	}
+	local := []query.Node{
+		query.Parameter{Field: "repo", Value: "bar"},
+		query.Parameter{Field: "type", Value: "diff"},
+	}
+	var got []string
+	for _, node := range nestScope(scope, local) {
+		got = append(got, node.String())
+	}
+	if diff := cmp.Diff([]string{`"repo:foo"`, `"repo:bar"`, `"type:diff"`}, got); diff != "" {
+		t.Errorf("unexpected nested scope (-want +got):\n%s", diff)
+	}
+
+	got = nil
+	for _, node := range removeParameters(parse("(repo:foo count:10 a) or b"), "count") {
+		got = append(got, node.String())
+	}
+	if diff := cmp.Diff([]string{`(or (and "repo:foo" "a") "b")`}, got); diff != "" {
+		t.Errorf("unexpected query without count (-want +got):\n%s", diff)
+	}
+}
+
+func TestPaginateResults(t *testing.T) {
+	results := func(n int) *SearchResultsResolver {
+		r := &SearchResultsResolver{}
+		for i := 0; i < n; i++ {
+			r.SearchResults = append(r.SearchResults, &RepositoryResolver{repo: &types.Repo{Name: api.RepoName(fmt.Sprint(i))}})
+		}
+		return r
+	}
+
+	page := paginateResults(results(5), 0, 2)
+	if len(page.SearchResults) != 2 || page.cursor.ResultOffset != 2 || page.cursor.Finished || !page.limitHit {
+		t.Errorf("unexpected first page: %d results, cursor %+v", len(page.SearchResults), page.cursor)
+	}
+	page = paginateResults(results(5), 4, 2)
+	if len(page.SearchResults) != 1 || page.cursor.ResultOffset != 5 || !page.cursor.Finished || page.limitHit {
+		t.Errorf("unexpected last page: %d results, cursor %+v", len(page.SearchResults), page.cursor)
+	}
+	limited := results(5)
+	limited.limitHit = true
+	page = paginateResults(limited, 4, 2)
+	if page.cursor.Finished {
+		t.Error("want unfinished cursor when the results hit a limit")
+	}
+}
@@ -0,0 +1,248 @@
+package graphqlbackend
+
+import (
+	"context"
+	"sync"
+
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/search/query"
+)
+
+// SearchEvent is an incremental update sent by a streaming search.
+type SearchEvent struct {
+	// Results are results found since the previous event. Results for the
+	// same file may be split over several events, for example once for
+	// symbol matches and once for line matches.
+	Results []SearchResultResolver
+
+	// Progress describes which repositories were covered by the part of the
+	// search that produced this event.
+	Progress SearchProgress
+}
+
+// SearchProgress describes the repositories covered by a SearchEvent.
+type SearchProgress struct {
+	Searched []api.RepoName // repos that were searched
+	Indexed  []api.RepoName // repos that were searched using an index
+	Cloning  []api.RepoName // repos that could not be searched because they are still being cloned
+	Missing  []api.RepoName // repos that could not be searched because they do not exist
+	Timedout []api.RepoName // repos that could not be searched in time
+	LimitHit bool           // whether a limit on results was hit
+}
+
+// SearchStream receives the events of a streaming search. Implementations
+// must be safe for concurrent use.
+type SearchStream interface {
+	Send(SearchEvent)
+}
+
+// StreamSearch runs search and sends results to stream as they are found,
+// instead of only once the whole search is done. The returned results are
+// the same as those returned by search.Results and can be used to report
+// alerts and the final result count.
+//
+// Queries that need all results before any can be returned, such as and/or
+// and paginated queries, are sent to stream in a single event at the end.
+//
+// At most as many results as the count: of the query are sent. The returned
+// results are the results that were sent, so that the final result count
+// agrees with them.
+func StreamSearch(ctx context.Context, search SearchImplementer, stream SearchStream) (*SearchResultsResolver, error) {
+	if r, ok := search.(*searchResolver); ok && r.canStream() {
+		limited := newLimitedStream(stream, int(r.maxResults()))
+		results, err := r.Results(withSearchStream(ctx, limited))
+		if err != nil {
+			return nil, err
+		}
+		limited.finish(results)
+		return results, nil
+	}
+
+	results, err := search.Results(ctx)
+	if err != nil {
+		return nil, err
+	}
+	stream.Send(SearchEvent{
+		Results:  results.SearchResults,
+		Progress: searchProgress(&results.searchResultsCommon),
+	})
+	return results, nil
+}
+
+// canStream returns true if results of r can be sent as soon as they are
+// found. That is not the case for queries which combine or reorder results
+// from several searches.
+func (r *searchResolver) canStream() bool {
+	if _, ok := r.query.(*query.OrdinaryQuery); !ok {
+		return false
+	}
+	return r.pagination == nil && !r.query.BoolValue(query.FieldStable) && !r.sortByRelevance()
+}
+
+// limitedStream sends at most limit distinct results to a stream. Results
+// after the limit are dropped and reported as a hit limit.
+type limitedStream struct {
+	stream SearchStream
+	limit  int
+
+	mu      sync.Mutex
+	sent    map[interface{}]SearchResultResolver // result key -> first result sent with that key
+	order   []interface{}                        // keys of sent, in the order they were sent
+	dropped bool
+}
+
+func newLimitedStream(stream SearchStream, limit int) *limitedStream {
+	return &limitedStream{stream: stream, limit: limit, sent: map[interface{}]SearchResultResolver{}}
+}
+
+// streamResultKey returns the key that identifies result when counting
+// results. Matches in the same file are merged into one result, and may be
+// sent in several events (for example, symbol and line matches).
+func streamResultKey(result SearchResultResolver) interface{} {
+	if fm, ok := result.ToFileMatch(); ok {
+		return fm.uri
+	}
+	return result
+}
+
+func (s *limitedStream) Send(ev SearchEvent) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	results := ev.Results[:0:0]
+	for _, result := range ev.Results {
+		key := streamResultKey(result)
+		if _, ok := s.sent[key]; !ok {
+			if len(s.sent) >= s.limit {
+				s.dropped = true
+				continue
+			}
+			s.sent[key] = result
+			s.order = append(s.order, key)
+		}
+		results = append(results, result)
+	}
+	ev.Results = results
+	if s.dropped {
+		ev.Progress.LimitHit = true
+	}
+	// Send while holding the lock, so that the stream sees events in the
+	// order they were counted.
+	s.stream.Send(ev)
+}
+
+// finish sends the results of the completed search that were not sent yet
+// (subject to the limit) and replaces the results with the results that were
+// sent.
+func (s *limitedStream) finish(results *SearchResultsResolver) {
+	final := make(map[interface{}]SearchResultResolver, len(results.SearchResults))
+	var unsent []SearchResultResolver
+	s.mu.Lock()
+	for _, result := range results.SearchResults {
+		key := streamResultKey(result)
+		final[key] = result
+		if _, ok := s.sent[key]; !ok {
+			unsent = append(unsent, result)
+		}
+	}
+	s.mu.Unlock()
+	if len(unsent) > 0 {
+		s.Send(SearchEvent{Results: unsent})
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sent := make([]SearchResultResolver, 0, len(s.order))
+	for _, key := range s.order {
+		// Prefer the final result, which includes all matches that were
+		// merged into it.
+		if result, ok := final[key]; ok {
+			sent = append(sent, result)
+		} else {
+			sent = append(sent, s.sent[key])
+		}
+	}
+	sortResults(sent)
+	results.SearchResults = sent
+	if s.dropped {
+		results.limitHit = true
+	}
+}
+
+type searchStreamKey struct{}
+
+func withSearchStream(ctx context.Context, stream SearchStream) context.Context {
+	return context.WithValue(ctx, searchStreamKey{}, stream)
+}
+
+// hasSearchStream returns true if ctx is part of a streaming search.
+func hasSearchStream(ctx context.Context) bool {
+	_, ok := ctx.Value(searchStreamKey{}).(SearchStream)
+	return ok
+}
+
+type fileMatchSinkKey struct{}
+
+// withFileMatchSink returns a context for searching a single repository in
+// which searchFilesInRepo passes matches to sink as soon as searcher finds
+// them.
+func withFileMatchSink(ctx context.Context, sink func([]*FileMatchResolver)) context.Context {
+	return context.WithValue(ctx, fileMatchSinkKey{}, sink)
+}
+
+func fileMatchSinkFromContext(ctx context.Context) func([]*FileMatchResolver) {
+	sink, _ := ctx.Value(fileMatchSinkKey{}).(func([]*FileMatchResolver))
+	return sink
+}
+
+// sendSearchEvent sends ev to the SearchStream of ctx. It is a no-op if ctx
+// is not part of a streaming search.
+func sendSearchEvent(ctx context.Context, ev SearchEvent) {
+	stream, ok := ctx.Value(searchStreamKey{}).(SearchStream)
+	if !ok {
+		return
+	}
+	stream.Send(ev)
+}
+
+// newSearchEvent returns the event for results and common. common may be
+// nil.
+func newSearchEvent(results []SearchResultResolver, common *searchResultsCommon) SearchEvent {
+	ev := SearchEvent{Results: results}
+	if common != nil {
+		ev.Progress = searchProgress(common)
+	}
+	return ev
+}
+
+// searchProgress converts common to the progress reported by a SearchEvent.
+func searchProgress(common *searchResultsCommon) SearchProgress {
+	names := func(repos []*types.Repo) []api.RepoName {
+		if len(repos) == 0 {
+			return nil
+		}
+		names := make([]api.RepoName, len(repos))
+		for i, r := range repos {
+			names[i] = r.Name
+		}
+		return names
+	}
+	return SearchProgress{
+		Searched: names(common.searched),
+		Indexed:  names(common.indexed),
+		Cloning:  names(common.cloning),
+		Missing:  names(common.missing),
+		Timedout: names(common.timedout),
+		LimitHit: common.limitHit,
+	}
+}
+
+// fileMatchesToSearchResults converts matches to SearchResultResolvers.
+func fileMatchesToSearchResults(matches []*FileMatchResolver) []SearchResultResolver {
+	results := make([]SearchResultResolver, len(matches))
+	for i, m := range matches {
+		results[i] = m
+	}
+	return results
+}
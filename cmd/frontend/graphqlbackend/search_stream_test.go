@@ -0,0 +1,197 @@
+package graphqlbackend
+
+import (
+	"context"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/zoekt"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/endpoint"
+	"github.com/sourcegraph/sourcegraph/internal/gitserver"
+	"github.com/sourcegraph/sourcegraph/internal/search"
+	searchbackend "github.com/sourcegraph/sourcegraph/internal/search/backend"
+	"github.com/sourcegraph/sourcegraph/internal/search/query"
+	"github.com/sourcegraph/sourcegraph/internal/vcs"
+)
+
+type collectStream struct {
+	mu     sync.Mutex
+	events []SearchEvent
+}
+
+func (s *collectStream) Send(ev SearchEvent) {
+	s.mu.Lock()
+	s.events = append(s.events, ev)
+	s.mu.Unlock()
+}
+
+func TestSearchFilesInRepos_stream(t *testing.T) {
+	mockSearchFilesInRepo = func(ctx context.Context, repo *types.Repo, gitserverRepo gitserver.Repo, rev string, info *search.TextPatternInfo, fetchTimeout time.Duration) (matches []*FileMatchResolver, limitHit bool, err error) {
+		switch repo.Name {
+		case "foo/one":
+			return []*FileMatchResolver{{uri: "git://foo/one#main.go", Repo: repo}}, false, nil
+		case "foo/cloning":
+			return nil, false, &vcs.RepoNotExistError{Repo: repo.Name, CloneInProgress: true}
+		default:
+			panic("unexpected repo")
+		}
+	}
+	defer func() { mockSearchFilesInRepo = nil }()
+
+	q, err := query.ParseAndCheck("foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	args := &search.TextParameters{
+		PatternInfo: &search.TextPatternInfo{
+			FileMatchLimit: defaultMaxSearchResults,
+			Pattern:        "foo",
+		},
+		Repos:        makeRepositoryRevisions("foo/one", "foo/cloning"),
+		Query:        q,
+		Zoekt:        &searchbackend.Zoekt{Client: &fakeSearcher{repos: &zoekt.RepoList{}}},
+		SearcherURLs: endpoint.Static("test"),
+	}
+
+	stream := &collectStream{}
+	results, _, err := searchFilesInRepos(withSearchStream(context.Background(), stream), args)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 1 {
+		t.Fatalf("expected one result, got %d", len(results))
+	}
+
+	var (
+		streamed         []string
+		searched, clones []api.RepoName
+	)
+	for _, ev := range stream.events {
+		for _, r := range ev.Results {
+			fm, _ := r.ToFileMatch()
+			streamed = append(streamed, fm.uri)
+		}
+		searched = append(searched, ev.Progress.Searched...)
+		clones = append(clones, ev.Progress.Cloning...)
+	}
+	sort.Slice(searched, func(i, j int) bool { return searched[i] < searched[j] })
+
+	if want := []string{"git://foo/one#main.go"}; !reflect.DeepEqual(streamed, want) {
+		t.Errorf("got streamed results %v, want %v", streamed, want)
+	}
+	if want := []api.RepoName{"foo/cloning", "foo/one"}; !reflect.DeepEqual(searched, want) {
+		t.Errorf("got searched %v, want %v", searched, want)
+	}
+	if want := []api.RepoName{"foo/cloning"}; !reflect.DeepEqual(clones, want) {
+		t.Errorf("got cloning %v, want %v", clones, want)
+	}
+}
+
+func TestReadSearcherStream(t *testing.T) {
+	body := `{"Matches":[{"Path":"a.go","LineMatches":[{"Preview":"foo","LineNumber":1,"OffsetAndLengths":[[0,3]]}]}]}
+{"Matches":[{"Path":"b.go"}]}
+{"Done":true,"LimitHit":true}
+`
+	var batches [][]string
+	matches, limitHit, err := readSearcherStream(context.Background(), strings.NewReader(body), func(matches []*FileMatchResolver) {
+		var paths []string
+		for _, m := range matches {
+			paths = append(paths, m.JPath)
+		}
+		batches = append(batches, paths)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !limitHit {
+		t.Error("expected limitHit")
+	}
+	var paths []string
+	for _, m := range matches {
+		paths = append(paths, m.JPath)
+	}
+	if want := []string{"a.go", "b.go"}; !reflect.DeepEqual(paths, want) {
+		t.Errorf("got %v, want %v", paths, want)
+	}
+	if want := [][]string{{"a.go"}, {"b.go"}}; !reflect.DeepEqual(batches, want) {
+		t.Errorf("got batches %v, want %v", batches, want)
+	}
+
+	_, _, err = readSearcherStream(context.Background(), strings.NewReader(`{"Done":true,"DeadlineHit":true}`), nil)
+	if err != context.DeadlineExceeded {
+		t.Errorf("got %v, want deadline exceeded", err)
+	}
+
+	// A stream which ends before the done event is invalid.
+	if _, _, err := readSearcherStream(context.Background(), strings.NewReader(`{"Matches":[]}`), nil); err == nil {
+		t.Error("expected error for truncated stream")
+	}
+}
+
+func TestReadSearcherStream_incremental(t *testing.T) {
+	pr, pw := io.Pipe()
+	received := make(chan string)
+	go func() {
+		_, _ = io.WriteString(pw, `{"Matches":[{"Path":"a.go"}]}`+"\n")
+		// The first match must be received before the stream is done.
+		<-received
+		_, _ = io.WriteString(pw, `{"Done":true}`+"\n")
+		pw.Close()
+	}()
+
+	_, _, err := readSearcherStream(context.Background(), pr, func(matches []*FileMatchResolver) {
+		received <- matches[0].JPath
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLimitedStream(t *testing.T) {
+	repo := &types.Repo{Name: "foo/one"}
+	fm := func(path string) *FileMatchResolver {
+		return &FileMatchResolver{uri: "git://foo/one#" + path, JPath: path, Repo: repo}
+	}
+
+	stream := &collectStream{}
+	limited := newLimitedStream(stream, 2)
+	limited.Send(SearchEvent{Results: []SearchResultResolver{fm("a.go"), fm("b.go")}})
+	// Another part of an already sent file is not a new result.
+	limited.Send(SearchEvent{Results: []SearchResultResolver{fm("a.go"), fm("c.go")}})
+
+	var streamed []string
+	for _, ev := range stream.events {
+		for _, r := range ev.Results {
+			m, _ := r.ToFileMatch()
+			streamed = append(streamed, m.JPath)
+		}
+	}
+	if want := []string{"a.go", "b.go", "a.go"}; !reflect.DeepEqual(streamed, want) {
+		t.Errorf("got streamed %v, want %v", streamed, want)
+	}
+	if !stream.events[1].Progress.LimitHit {
+		t.Error("expected limitHit after the limit was reached")
+	}
+
+	// The final results are the results that were sent.
+	results := &SearchResultsResolver{SearchResults: []SearchResultResolver{fm("a.go"), fm("c.go"), fm("d.go")}}
+	limited.finish(results)
+	var final []string
+	for _, r := range results.SearchResults {
+		m, _ := r.ToFileMatch()
+		final = append(final, m.JPath)
+	}
+	if want := []string{"a.go", "b.go"}; !reflect.DeepEqual(final, want) {
+		t.Errorf("got final results %v, want %v", final, want)
+	}
+	if !results.LimitHit() {
+		t.Error("expected final limitHit")
+	}
+}
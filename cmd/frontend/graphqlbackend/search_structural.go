@@ -72,6 +72,59 @@ func StructuralPatToRegexpQuery(pattern string, shortcircuit bool) (zoektquery.Q
 	return &zoektquery.And{Children: children}, nil
 }
 
+// structuralPatAnchors returns the literal substrings of a comby pattern that
+// every match must contain. Comby matches whitespace and delimiters in the
+// pattern flexibly, so the pieces between holes are split on both. Text from
+// hole syntax we don't recognize onwards is dropped up to the next whitespace,
+// since it need not appear literally in a match.
+//
+// Example:
+// "ParseInt(:[args]) if err != nil" -> ["ParseInt", "if", "err", "!=", "nil"]
+func structuralPatAnchors(pattern string) []string {
+	var anchors []string
+	seen := map[string]bool{}
+	for _, piece := range matchHoleRegexp.Split(pattern, -1) {
+		for _, field := range strings.Fields(piece) {
+			if i := strings.Index(field, ":["); i >= 0 {
+				// Possibly a hole, such as :[x~regexp].
+				field = field[:i]
+			}
+			for _, a := range strings.FieldsFunc(field, isDelimiter) {
+				if !seen[a] {
+					seen[a] = true
+					anchors = append(anchors, a)
+				}
+			}
+		}
+	}
+	return anchors
+}
+
+func isDelimiter(r rune) bool {
+	return strings.ContainsRune("()[]{}", r)
+}
+
+// StructuralPatToAnchorQuery converts a comby pattern to a Zoekt query that
+// finds the files containing all literal anchors of the pattern. It is
+// cheaper than StructuralPatToRegexpQuery, since substring queries are
+// answered from the trigram index, and it does not miss files that comby
+// would match.
+func StructuralPatToAnchorQuery(pattern string) zoektquery.Q {
+	anchors := structuralPatAnchors(pattern)
+	if len(anchors) == 0 {
+		return &zoektquery.Const{Value: true}
+	}
+	children := make([]zoektquery.Q, 0, len(anchors))
+	for _, a := range anchors {
+		children = append(children, &zoektquery.Substring{
+			Pattern:       a,
+			CaseSensitive: true,
+			Content:       true,
+		})
+	}
+	return zoektquery.NewAnd(children...)
+}
+
 func HandleFilePathPatterns(query *search.TextPatternInfo) (zoektquery.Q, error) {
 	var and []zoektquery.Q
 
@@ -98,14 +151,10 @@ func HandleFilePathPatterns(query *search.TextPatternInfo) (zoektquery.Q, error)
 	return zoektquery.NewAnd(and...), nil
 }
 
-func buildQuery(args *search.TextParameters, newRepoSet *zoektquery.RepoSet, filePathPatterns zoektquery.Q, shortcircuit bool) (zoektquery.Q, error) {
-	q, err := StructuralPatToRegexpQuery(args.PatternInfo.Pattern, shortcircuit)
-	if err != nil {
-		return nil, err
-	}
+func buildQuery(args *search.TextParameters, newRepoSet *zoektquery.RepoSet, filePathPatterns zoektquery.Q) zoektquery.Q {
+	q := StructuralPatToAnchorQuery(args.PatternInfo.Pattern)
 	q = zoektquery.NewAnd(newRepoSet, filePathPatterns, q)
-	q = zoektquery.Simplify(q)
-	return q, nil
+	return zoektquery.Simplify(q)
 }
 
 // zoektSearchHEADOnlyFiles searches repositories using zoekt, returning only the file paths containing
@@ -164,10 +213,7 @@ func zoektSearchHEADOnlyFiles(ctx context.Context, args *search.TextParameters,
 	}
 
 	t0 := time.Now()
-	q, err := buildQuery(args, newRepoSet, filePathPatterns, true)
-	if err != nil {
-		return nil, false, nil, err
-	}
+	q := buildQuery(args, newRepoSet, filePathPatterns)
 	resp, err := args.Zoekt.Client.Search(ctx, q, &searchOpts)
 	if err != nil {
 		return nil, false, nil, err
@@ -176,22 +222,9 @@ func zoektSearchHEADOnlyFiles(ctx context.Context, args *search.TextParameters,
 		return nil, false, nil, errNoResultsInTimeout
 	}
 
-	// We always return approximate results (limitHit true) unless we run the branch to perform a more complete search.
-	limitHit = true
-	// If the previous indexed search did not return a substantial number of matching file candidates or count was
-	// manually specified, run a more complete and expensive search.
-	if resp.FileCount < 10 || args.PatternInfo.FileMatchLimit != defaultMaxSearchResults {
-		q, err = buildQuery(args, newRepoSet, filePathPatterns, false)
-		resp, err = args.Zoekt.Client.Search(ctx, q, &searchOpts)
-		if err != nil {
-			return nil, false, nil, err
-		}
-		if since(t0) >= searchOpts.MaxWallTime {
-			return nil, false, nil, errNoResultsInTimeout
-		}
-		// This is the only place limitHit can be set false, meaning we covered everything.
-		limitHit = resp.FilesSkipped+resp.ShardsSkipped > 0
-	}
+	// The anchor query finds every file comby could match, so results are
+	// only incomplete if Zoekt skipped files or shards.
+	limitHit = resp.FilesSkipped+resp.ShardsSkipped > 0
 
 	if len(resp.Files) == 0 {
 		return nil, false, nil, nil
@@ -85,3 +85,50 @@ func TestStructuralPatToZoektQuery(t *testing.T) {
 		})
 	}
 }
+
+func TestStructuralPatToAnchorQuery(t *testing.T) {
+	cases := []struct {
+		Name    string
+		Pattern string
+		Want    string
+	}{
+		{
+			Name:    "Just a hole",
+			Pattern: ":[1]",
+			Want:    `TRUE`,
+		},
+		{
+			Name:    "Substring between holes",
+			Pattern: ":[1] substring :[2]",
+			Want:    `(and case_content_substr:"substring")`,
+		},
+		{
+			Name:    "Whitespace and delimiters split anchors",
+			Pattern: "ParseInt(:[stuff], :[x])\n  if err != nil {",
+			Want:    `(and case_content_substr:"ParseInt" case_content_substr:"," case_content_substr:"if" case_content_substr:"err" case_content_substr:"!=" case_content_substr:"nil")`,
+		},
+		{
+			Name:    "Repeated anchors",
+			Pattern: "foo(:[1]) foo(:[2])",
+			Want:    `(and case_content_substr:"foo")`,
+		},
+		{
+			Name:    "Substrings covering all hole kinds.",
+			Pattern: `1. :[1] 2. :[[2]] 3. :[3.] 4. :[4\n] 5. :[ ] 6. :[ 6] done.`,
+			Want:    `(and case_content_substr:"1." case_content_substr:"2." case_content_substr:"3." case_content_substr:"4." case_content_substr:"5." case_content_substr:"6." case_content_substr:"done.")`,
+		},
+		{
+			Name:    "Unrecognized holes are not anchors",
+			Pattern: `strconv.Atoi(:[x~\d+])`,
+			Want:    `(and case_content_substr:"strconv.Atoi")`,
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.Name, func(t *testing.T) {
+			got := StructuralPatToAnchorQuery(tt.Pattern).String()
+			if got != tt.Want {
+				t.Fatalf("mismatched queries\ngot  %s\nwant %s", got, tt.Want)
+			}
+		})
+	}
+}
@@ -10,11 +10,13 @@ import (
 
 	graphql "github.com/graph-gophers/graphql-go"
 	"github.com/graph-gophers/graphql-go/relay"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/audit"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/backend"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/internal/pkg/siteid"
 	"github.com/sourcegraph/sourcegraph/internal/api"
 	"github.com/sourcegraph/sourcegraph/internal/conf"
+	"github.com/sourcegraph/sourcegraph/internal/conf/conftypes"
 	"github.com/sourcegraph/sourcegraph/internal/env"
 	"github.com/sourcegraph/sourcegraph/internal/version"
 
@@ -176,12 +178,19 @@ func (r *schemaResolver) UpdateSiteConfiguration(ctx context.Context, args *stru
 	prev := globals.ConfigurationServerFrontendOnly.Raw()
 	prev.Site = args.Input
 	// TODO(slimsag): future: actually pass lastID through to prevent race conditions
-	if err := globals.ConfigurationServerFrontendOnly.Write(ctx, prev); err != nil {
+	if err := writeSiteConfiguration(ctx, prev, nil); err != nil {
 		return false, err
 	}
 	return globals.ConfigurationServerFrontendOnly.NeedServerRestart(), nil
 }
 
+// writeSiteConfiguration writes the configuration. The configuration source records the change in
+// the audit log in the same transaction in which it saves the configuration.
+func writeSiteConfiguration(ctx context.Context, next conftypes.RawUnified, rolledBackTo *int32) error {
+	ctx = audit.WithSiteConfigurationChange(ctx, audit.SiteConfigurationChange{RolledBackTo: rolledBackTo})
+	return globals.ConfigurationServerFrontendOnly.Write(ctx, next)
+}
+
 type criticalConfigurationResolver struct{}
 
 func (r *criticalConfigurationResolver) ID(ctx context.Context) (int32, error) {
@@ -2,9 +2,12 @@ package graphqlbackend
 
 import (
 	"context"
+	"database/sql"
+	"strconv"
 
 	"github.com/graph-gophers/graphql-go"
 	"github.com/pkg/errors"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/audit"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/authz"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/backend"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
@@ -137,7 +140,27 @@ func (*schemaResolver) SetUserIsSiteAdmin(ctx context.Context, args *struct {
 		return nil, err
 	}
 
-	if err := db.Users.SetIsSiteAdmin(ctx, userID, args.SiteAdmin); err != nil {
+	target, err := db.Users.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	type siteAdminStatus struct {
+		Username  string `json:"username"`
+		SiteAdmin bool   `json:"siteAdmin"`
+	}
+	if err := audit.Transact(ctx, func(tx *sql.Tx) (*audit.Event, error) {
+		if err := db.Users.SetIsSiteAdmin(ctx, tx, userID, args.SiteAdmin); err != nil {
+			return nil, err
+		}
+		return &audit.Event{
+			Action:     db.AuditLogActionSetSiteAdmin,
+			TargetType: "User",
+			TargetID:   strconv.FormatInt(int64(userID), 10),
+			Before:     siteAdminStatus{Username: target.Username, SiteAdmin: target.SiteAdmin},
+			After:      siteAdminStatus{Username: target.Username, SiteAdmin: args.SiteAdmin},
+		}, nil
+	}); err != nil {
 		return nil, err
 	}
 	return &EmptyResponse{}, nil
@@ -165,3 +165,61 @@ func TestDeleteUser(t *testing.T) {
 		})
 	}
 }
+
+func TestSetUserIsSiteAdmin(t *testing.T) {
+	resetMocks()
+	db.Mocks.Users.GetByCurrentAuthUser = func(context.Context) (*types.User, error) {
+		return &types.User{ID: 1, SiteAdmin: true}, nil
+	}
+	db.Mocks.Users.GetByID = func(_ context.Context, id int32) (*types.User, error) {
+		return &types.User{ID: id, Username: "alice"}, nil
+	}
+	db.Mocks.Users.SetIsSiteAdmin = func(id int32, isSiteAdmin bool) error {
+		if id != 6 || !isSiteAdmin {
+			return fmt.Errorf("got SetIsSiteAdmin(%d, %v)", id, isSiteAdmin)
+		}
+		return nil
+	}
+	var entry *db.AuditLogEntry
+	db.Mocks.AuditLog.Insert = func(_ context.Context, e *db.AuditLogEntry) error {
+		entry = e
+		return nil
+	}
+	defer resetMocks()
+
+	gqltesting.RunTests(t, []*gqltesting.Test{
+		{
+			Context: actor.WithActor(context.Background(), &actor.Actor{UID: 1}),
+			Schema:  mustParseGraphQLSchema(t),
+			Query: `
+				mutation {
+					setUserIsSiteAdmin(userID: "VXNlcjo2", siteAdmin: true) {
+						alwaysNil
+					}
+				}
+			`,
+			ExpectedResult: `
+				{
+					"setUserIsSiteAdmin": {
+						"alwaysNil": null
+					}
+				}
+			`,
+		},
+	})
+
+	if entry == nil {
+		t.Fatal("no audit log entry written")
+	}
+	want := &db.AuditLogEntry{
+		ActorUserID: 1,
+		Action:      db.AuditLogActionSetSiteAdmin,
+		TargetType:  "User",
+		TargetID:    "6",
+		Before:      []byte(`{"username":"alice","siteAdmin":false}`),
+		After:       []byte(`{"username":"alice","siteAdmin":true}`),
+	}
+	if diff := cmp.Diff(want, entry); diff != "" {
+		t.Errorf("audit log entry: %v", diff)
+	}
+}
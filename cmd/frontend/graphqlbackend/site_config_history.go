@@ -0,0 +1,211 @@
+package graphqlbackend
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/sergi/go-diff/diffmatchpatch"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/backend"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/globals"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/graphqlbackend/graphqlutil"
+	"github.com/sourcegraph/sourcegraph/internal/conf"
+	"github.com/sourcegraph/sourcegraph/internal/db/confdb"
+)
+
+type siteConfigurationHistoryArgs struct {
+	graphqlutil.ConnectionArgs
+	After *string
+}
+
+func (r *siteConfigurationResolver) History(ctx context.Context, args *siteConfigurationHistoryArgs) (*siteConfigurationRevisionConnectionResolver, error) {
+	// 🚨 SECURITY: The site configuration contains secret tokens and credentials,
+	// so only admins may view it.
+	if err := backend.CheckCurrentUserIsSiteAdmin(ctx); err != nil {
+		return nil, err
+	}
+
+	var beforeID int32
+	if args.After != nil {
+		// The cursor is the ID of the last revision of the previous page.
+		id, err := strconv.ParseInt(*args.After, 10, 32)
+		if err != nil || id <= 0 {
+			return nil, fmt.Errorf("invalid cursor %q", *args.After)
+		}
+		beforeID = int32(id)
+	}
+	return &siteConfigurationRevisionConnectionResolver{first: args.GetFirst(), beforeID: beforeID}, nil
+}
+
+type siteConfigurationRevisionConnectionResolver struct {
+	first    int32
+	beforeID int32
+
+	// cache results because they are used by multiple fields
+	once      sync.Once
+	revisions []*confdb.SiteConfig
+	err       error
+}
+
+func (r *siteConfigurationRevisionConnectionResolver) compute(ctx context.Context) ([]*confdb.SiteConfig, error) {
+	r.once.Do(func() {
+		opt := confdb.SiteListOptions{BeforeID: r.beforeID}
+		if r.first > 0 {
+			opt.Limit = int(r.first) + 1 // so we can detect if there is a next page
+		}
+		r.revisions, r.err = confdb.SiteList(ctx, opt)
+	})
+	return r.revisions, r.err
+}
+
+func (r *siteConfigurationRevisionConnectionResolver) Nodes(ctx context.Context) ([]*siteConfigurationRevisionResolver, error) {
+	revisions, err := r.compute(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if r.first > 0 && len(revisions) > int(r.first) {
+		revisions = revisions[:r.first]
+	}
+
+	l := make([]*siteConfigurationRevisionResolver, 0, len(revisions))
+	for _, revision := range revisions {
+		l = append(l, &siteConfigurationRevisionResolver{revision: revision})
+	}
+	return l, nil
+}
+
+func (r *siteConfigurationRevisionConnectionResolver) TotalCount(ctx context.Context) (int32, error) {
+	count, err := confdb.SiteCount(ctx)
+	return int32(count), err
+}
+
+func (r *siteConfigurationRevisionConnectionResolver) PageInfo(ctx context.Context) (*graphqlutil.PageInfo, error) {
+	revisions, err := r.compute(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if r.first > 0 && len(revisions) > int(r.first) {
+		return graphqlutil.NextPageCursor(strconv.Itoa(int(revisions[r.first-1].ID))), nil
+	}
+	return graphqlutil.HasNextPage(false), nil
+}
+
+type siteConfigurationRevisionResolver struct {
+	revision *confdb.SiteConfig
+}
+
+func (r *siteConfigurationRevisionResolver) ID() int32 { return r.revision.ID }
+
+func (r *siteConfigurationRevisionResolver) Author(ctx context.Context) (*UserResolver, error) {
+	if r.revision.AuthorUserID == nil {
+		return nil, nil
+	}
+	return UserByIDInt32(ctx, *r.revision.AuthorUserID)
+}
+
+func (r *siteConfigurationRevisionResolver) CreatedAt() DateTime {
+	return DateTime{Time: r.revision.CreatedAt}
+}
+
+func (r *siteConfigurationRevisionResolver) Contents() JSONCString {
+	return JSONCString(r.revision.Contents)
+}
+
+func (r *siteConfigurationRevisionResolver) Diff(ctx context.Context, args *struct{ From *int32 }) (string, error) {
+	var old *confdb.SiteConfig
+	if args.From != nil {
+		var err error
+		old, err = confdb.SiteGetByID(ctx, *args.From)
+		if err != nil {
+			return "", err
+		}
+		if old == nil {
+			return "", fmt.Errorf("site configuration revision %d not found", *args.From)
+		}
+	} else {
+		previous, err := confdb.SiteList(ctx, confdb.SiteListOptions{BeforeID: r.revision.ID, Limit: 1})
+		if err != nil {
+			return "", err
+		}
+		if len(previous) > 0 {
+			old = previous[0]
+		}
+	}
+
+	var oldContents string
+	if old != nil {
+		oldContents = old.Contents
+	}
+	return lineDiff(oldContents, r.revision.Contents), nil
+}
+
+// lineDiff returns a line-by-line diff of a and b. Lines only in a are prefixed with "-", lines
+// only in b with "+", and lines in both with " ".
+func lineDiff(a, b string) string {
+	dmp := diffmatchpatch.New()
+	ac, bc, lines := dmp.DiffLinesToChars(a, b)
+	diffs := dmp.DiffCharsToLines(dmp.DiffMain(ac, bc, false), lines)
+
+	var buf strings.Builder
+	for _, d := range diffs {
+		var prefix string
+		switch d.Type {
+		case diffmatchpatch.DiffInsert:
+			prefix = "+"
+		case diffmatchpatch.DiffDelete:
+			prefix = "-"
+		case diffmatchpatch.DiffEqual:
+			prefix = " "
+		}
+		for _, line := range strings.SplitAfter(d.Text, "\n") {
+			if line == "" {
+				continue
+			}
+			buf.WriteString(prefix)
+			buf.WriteString(line)
+			if !strings.HasSuffix(line, "\n") {
+				buf.WriteString("\n")
+			}
+		}
+	}
+	return buf.String()
+}
+
+func (r *schemaResolver) RollbackSiteConfiguration(ctx context.Context, args *struct {
+	ToID int32
+}) (bool, error) {
+	// 🚨 SECURITY: Only site admins may change the site configuration.
+	if err := backend.CheckCurrentUserIsSiteAdmin(ctx); err != nil {
+		return false, err
+	}
+	if os.Getenv("SITE_CONFIG_FILE") != "" && !siteConfigAllowEdits {
+		return false, errors.New("updating site configuration not allowed when using SITE_CONFIG_FILE")
+	}
+
+	revision, err := confdb.SiteGetByID(ctx, args.ToID)
+	if err != nil {
+		return false, err
+	}
+	if revision == nil {
+		return false, fmt.Errorf("site configuration revision %d not found", args.ToID)
+	}
+
+	next := globals.ConfigurationServerFrontendOnly.Raw()
+	next.Site = revision.Contents
+	problems, err := conf.Validate(next)
+	if err != nil {
+		return false, err
+	}
+	if siteProblems := problems.Site(); len(siteProblems) > 0 {
+		return false, fmt.Errorf("site configuration revision %d is invalid: %s", args.ToID, strings.Join(siteProblems.Messages(), "; "))
+	}
+
+	if err := writeSiteConfiguration(ctx, next, &args.ToID); err != nil {
+		return false, err
+	}
+	return globals.ConfigurationServerFrontendOnly.NeedServerRestart(), nil
+}
@@ -0,0 +1,38 @@
+package graphqlbackend
+
+import "testing"
+
+func TestLineDiff(t *testing.T) {
+	tests := map[string]struct {
+		a, b string
+		want string
+	}{
+		"equal": {
+			a:    "{\n  \"a\": 1\n}",
+			b:    "{\n  \"a\": 1\n}",
+			want: " {\n   \"a\": 1\n }\n",
+		},
+		"changed line": {
+			a:    "{\n  \"a\": 1,\n  \"b\": 2\n}\n",
+			b:    "{\n  \"a\": 3,\n  \"b\": 2\n}\n",
+			want: " {\n-  \"a\": 1,\n+  \"a\": 3,\n   \"b\": 2\n }\n",
+		},
+		"from empty": {
+			a:    "",
+			b:    "{}\n",
+			want: "+{}\n",
+		},
+		"to empty": {
+			a:    "{}",
+			b:    "",
+			want: "-{}\n",
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			if got := lineDiff(test.a, test.b); got != test.want {
+				t.Errorf("got diff\n%s\nwant\n%s", got, test.want)
+			}
+		})
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"net/http"
 	"net/url"
@@ -187,9 +188,16 @@ var mockTextSearch func(ctx context.Context, repo gitserver.Repo, commit api.Com
 
 // textSearch searches repo@commit with p.
 // Note: the returned matches do not set fileMatch.uri
-func textSearch(ctx context.Context, searcherURLs *endpoint.Map, repo gitserver.Repo, commit api.CommitID, p *search.TextPatternInfo, fetchTimeout time.Duration) (matches []*FileMatchResolver, limitHit bool, err error) {
+//
+// If onMatches is not nil, it is called with the matches as searcher finds
+// them, before textSearch returns all of them.
+func textSearch(ctx context.Context, searcherURLs *endpoint.Map, repo gitserver.Repo, commit api.CommitID, p *search.TextPatternInfo, fetchTimeout time.Duration, onMatches func([]*FileMatchResolver)) (matches []*FileMatchResolver, limitHit bool, err error) {
 	if mockTextSearch != nil {
-		return mockTextSearch(ctx, repo, commit, p, fetchTimeout)
+		matches, limitHit, err = mockTextSearch(ctx, repo, commit, p, fetchTimeout)
+		if onMatches != nil && len(matches) > 0 {
+			onMatches(matches)
+		}
+		return matches, limitHit, err
 	}
 
 	tr, ctx := trace.New(ctx, "searcher.client", fmt.Sprintf("%s@%s", repo.Name, commit))
@@ -208,6 +216,7 @@ func textSearch(ctx context.Context, searcherURLs *endpoint.Map, repo gitserver.
 		"FetchTimeout":    []string{fetchTimeout.String()},
 		"Languages":       p.Languages,
 		"CombyRule":       []string{p.CombyRule},
+		"FilePaths":       p.FilePaths,
 	}
 	if deadline, ok := ctx.Deadline(); ok {
 		t, err := deadline.MarshalText()
@@ -239,6 +248,9 @@ func textSearch(ctx context.Context, searcherURLs *endpoint.Map, repo gitserver.
 	// these fields from old frontends that do not (and provide a default in the latter case).
 	q.Set("PatternMatchesContent", strconv.FormatBool(p.PatternMatchesContent))
 	q.Set("PatternMatchesPath", strconv.FormatBool(p.PatternMatchesPath))
+	// Ask searcher to stream matches back. Searchers which do not understand
+	// this field reply with a single JSON document, see textSearchURL.
+	q.Set("Stream", "true")
 	rawQuery := q.Encode()
 
 	// Searcher caches the file contents for repo@commit since it is
@@ -252,7 +264,17 @@ func textSearch(ctx context.Context, searcherURLs *endpoint.Map, repo gitserver.
 		excludedSearchURLs = map[string]bool{}
 		attempt            = 0
 		maxAttempts        = 2
+		// Whether onMatches was called. Matches that were sent can't be
+		// taken back, so we don't retry (which could send them twice).
+		sent bool
 	)
+	if onMatches != nil {
+		send := onMatches
+		onMatches = func(matches []*FileMatchResolver) {
+			sent = true
+			send(matches)
+		}
+	}
 	for {
 		attempt++
 
@@ -272,7 +294,7 @@ func textSearch(ctx context.Context, searcherURLs *endpoint.Map, repo gitserver.
 
 		url := searcherURL + "?" + rawQuery
 		tr.LazyPrintf("attempt %d: %s", attempt, url)
-		matches, limitHit, err = textSearchURL(ctx, url)
+		matches, limitHit, err = textSearchURL(ctx, url, onMatches)
 		if err == nil || errcode.IsTimeout(err) {
 			return matches, limitHit, err
 		}
@@ -283,7 +305,7 @@ func textSearch(ctx context.Context, searcherURLs *endpoint.Map, repo gitserver.
 		}
 
 		// If not temporary or our last attempt then don't try again.
-		if !errcode.IsTemporary(err) || attempt == maxAttempts {
+		if sent || !errcode.IsTemporary(err) || attempt == maxAttempts {
 			return nil, false, err
 		}
 
@@ -293,7 +315,7 @@ func textSearch(ctx context.Context, searcherURLs *endpoint.Map, repo gitserver.
 	}
 }
 
-func textSearchURL(ctx context.Context, url string) ([]*FileMatchResolver, bool, error) {
+func textSearchURL(ctx context.Context, url string, onMatches func([]*FileMatchResolver)) ([]*FileMatchResolver, bool, error) {
 	req, err := http.NewRequest("GET", url, nil)
 	if err != nil {
 		return nil, false, err
@@ -326,6 +348,10 @@ func textSearchURL(ctx context.Context, url string) ([]*FileMatchResolver, bool,
 		return nil, false, errors.WithStack(&searcherError{StatusCode: resp.StatusCode, Message: string(body)})
 	}
 
+	if resp.Header.Get("Content-Type") == "application/x-ndjson" {
+		return readSearcherStream(ctx, resp.Body, onMatches)
+	}
+
 	r := struct {
 		Matches     []*FileMatchResolver
 		LimitHit    bool
@@ -338,9 +364,56 @@ func textSearchURL(ctx context.Context, url string) ([]*FileMatchResolver, bool,
 	if r.DeadlineHit {
 		err = context.DeadlineExceeded
 	}
+	if onMatches != nil && len(r.Matches) > 0 {
+		onMatches(r.Matches)
+	}
 	return r.Matches, r.LimitHit, err
 }
 
+// readSearcherStream reads the newline-delimited JSON events of a streaming
+// searcher response (see protocol.StreamEvent) until the final event. If
+// onMatches is not nil, it is called with the matches of each event as soon
+// as the event is read.
+func readSearcherStream(ctx context.Context, body io.Reader, onMatches func([]*FileMatchResolver)) (matches []*FileMatchResolver, limitHit bool, err error) {
+	dec := json.NewDecoder(body)
+	for {
+		var ev struct {
+			Matches     []*FileMatchResolver
+			Done        bool
+			LimitHit    bool
+			DeadlineHit bool
+			Error       string
+		}
+		if err := dec.Decode(&ev); err != nil {
+			// Like for the request itself, report cancellation and
+			// timeouts as just that.
+			if ctx.Err() != nil {
+				return nil, false, ctx.Err()
+			}
+			if err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+			return nil, false, errors.Wrap(err, "searcher response invalid")
+		}
+		if len(ev.Matches) > 0 {
+			matches = append(matches, ev.Matches...)
+			if onMatches != nil {
+				onMatches(ev.Matches)
+			}
+		}
+		if !ev.Done {
+			continue
+		}
+		if ev.Error != "" {
+			return nil, false, errors.WithStack(&searcherError{StatusCode: http.StatusInternalServerError, Message: ev.Error})
+		}
+		if ev.DeadlineHit {
+			err = context.DeadlineExceeded
+		}
+		return matches, ev.LimitHit, err
+	}
+}
+
 type searcherError struct {
 	StatusCode int
 	Message    string
@@ -382,17 +455,32 @@ func searchFilesInRepo(ctx context.Context, searcherURLs *endpoint.Map, repo *ty
 		return nil, false, err
 	}
 
-	matches, limitHit, err = textSearch(ctx, searcherURLs, gitserverRepo, commit, info, fetchTimeout)
+	workspace := fileMatchURI(repo.Name, rev, "")
+	complete := func(matches []*FileMatchResolver) {
+		for _, fm := range matches {
+			fm.uri = workspace + fm.JPath
+			fm.Repo = repo
+			fm.CommitID = commit
+			fm.InputRev = &rev
+		}
+	}
+
+	// When the search is streamed, complete the matches before they are sent,
+	// because they may be read concurrently afterwards.
+	var onMatches func([]*FileMatchResolver)
+	if sink := fileMatchSinkFromContext(ctx); sink != nil {
+		onMatches = func(matches []*FileMatchResolver) {
+			complete(matches)
+			sink(matches)
+		}
+	}
+
+	matches, limitHit, err = textSearch(ctx, searcherURLs, gitserverRepo, commit, info, fetchTimeout, onMatches)
 	if err != nil {
 		return nil, false, err
 	}
-
-	workspace := fileMatchURI(repo.Name, rev, "")
-	for _, fm := range matches {
-		fm.uri = workspace + fm.JPath
-		fm.Repo = repo
-		fm.CommitID = commit
-		fm.InputRev = &rev
+	if onMatches == nil {
+		complete(matches)
 	}
 
 	return matches, limitHit, err
@@ -424,7 +512,7 @@ func repoShouldBeSearched(ctx context.Context, searcherURLs *endpoint.Map, searc
 func repoHasFilesWithNamesMatching(ctx context.Context, searcherURLs *endpoint.Map, include bool, repoHasFileFlag []string, gitserverRepo gitserver.Repo, commit api.CommitID, fetchTimeout time.Duration) (bool, error) {
 	for _, pattern := range repoHasFileFlag {
 		p := search.TextPatternInfo{IsRegExp: true, FileMatchLimit: 1, IncludePatterns: []string{pattern}, PathPatternsAreRegExps: true, PathPatternsAreCaseSensitive: false, PatternMatchesContent: true, PatternMatchesPath: true}
-		matches, _, err := textSearch(ctx, searcherURLs, gitserverRepo, commit, &p, fetchTimeout)
+		matches, _, err := textSearch(ctx, searcherURLs, gitserverRepo, commit, &p, fetchTimeout, nil)
 		if err != nil {
 			return false, err
 		}
@@ -640,8 +728,7 @@ func searchFilesInRepos(ctx context.Context, args *search.TextParameters) (res [
 					if v, ok := searcherReposFilteredFiles[string(repoRev.Repo.Name)]; ok {
 						patternCopy := *args.PatternInfo
 						args.PatternInfo = &patternCopy
-						includePatternsCopy := []string{}
-						args.PatternInfo.IncludePatterns = append(includePatternsCopy, v...)
+						args.PatternInfo.FilePaths = append([]string(nil), v...)
 					}
 				}
 
@@ -650,28 +737,43 @@ func searchFilesInRepos(ctx context.Context, args *search.TextParameters) (res [
 					defer wg.Done()
 					defer done()
 
-					matches, repoLimitHit, err := searchFilesInRepo(ctx, args.SearcherURLs, repoRev.Repo, repoRev.GitserverRepo(), repoRev.RevSpecs()[0], args.PatternInfo, fetchTimeout)
+					// Send matches to streaming searches as searcher finds
+					// them, instead of once the repository is done.
+					repoCtx, streamed := ctx, false
+					if hasSearchStream(ctx) {
+						repoCtx = withFileMatchSink(ctx, func(matches []*FileMatchResolver) {
+							streamed = true
+							sendSearchEvent(ctx, SearchEvent{Results: fileMatchesToSearchResults(matches)})
+						})
+					}
+
+					matches, repoLimitHit, err := searchFilesInRepo(repoCtx, args.SearcherURLs, repoRev.Repo, repoRev.GitserverRepo(), repoRev.RevSpecs()[0], args.PatternInfo, fetchTimeout)
 					if err != nil {
 						tr.LogFields(otlog.String("repo", string(repoRev.Repo.Name)), otlog.Error(err), otlog.Bool("timeout", errcode.IsTimeout(err)), otlog.Bool("temporary", errcode.IsTemporary(err)))
 						log15.Warn("searchFilesInRepo failed", "error", err, "repo", repoRev.Repo.Name)
 					}
 					mu.Lock()
-					defer mu.Unlock()
+					// repoCommon is what this repository contributes to common. It
+					// is kept separately so it can be sent to streaming searches.
+					repoCommon := searchResultsCommon{partial: make(map[api.RepoName]struct{})}
 					if ctx.Err() == nil {
-						common.searched = append(common.searched, repoRev.Repo)
+						repoCommon.searched = append(repoCommon.searched, repoRev.Repo)
 					}
 					if repoLimitHit {
 						// We did not return all results in this repository.
-						common.partial[repoRev.Repo.Name] = struct{}{}
+						repoCommon.partial[repoRev.Repo.Name] = struct{}{}
 					}
 					// non-diff search reports timeout through err, so pass false for timedOut
-					if fatalErr := handleRepoSearchResult(common, repoRev, repoLimitHit, false, err); fatalErr != nil {
+					fatalErr := handleRepoSearchResult(&repoCommon, repoRev, repoLimitHit, false, err)
+					common.update(repoCommon)
+					if fatalErr != nil {
 						if ctx.Err() == context.Canceled {
 							// Our request has been canceled (either because another one of searcherRepos
 							// had a fatal error, or otherwise), so we can just ignore these results. We
 							// handle this here, not in handleRepoSearchResult, because different callers of
 							// handleRepoSearchResult (for different result types) currently all need to
 							// handle cancellations differently.
+							mu.Unlock()
 							return
 						}
 						if searchErr == nil {
@@ -681,6 +783,13 @@ func searchFilesInRepos(ctx context.Context, args *search.TextParameters) (res [
 						}
 					}
 					addMatches(matches)
+					mu.Unlock()
+
+					ev := SearchEvent{Progress: searchProgress(&repoCommon)}
+					if !streamed {
+						ev.Results = fileMatchesToSearchResults(matches)
+					}
+					sendSearchEvent(ctx, ev)
 				}(limitCtx, limitDone) // ends the Go routine for a call to searcher for a repo
 			} // ends the for loop iterating over repo's revs
 		} // ends the for loop iterating over repos
@@ -766,6 +875,23 @@ func searchFilesInRepos(ctx context.Context, args *search.TextParameters) (res [
 			}
 		} else {
 			addMatches(matches)
+
+			zoektCommon := searchResultsCommon{limitHit: limitHit}
+			if ctx.Err() == nil {
+				for _, repo := range zoektRepos {
+					zoektCommon.searched = append(zoektCommon.searched, repo.Repo)
+					zoektCommon.indexed = append(zoektCommon.indexed, repo.Repo)
+				}
+			}
+			if err == errNoResultsInTimeout {
+				for _, repo := range zoektRepos {
+					zoektCommon.timedout = append(zoektCommon.timedout, repo.Repo)
+				}
+			}
+			sendSearchEvent(ctx, SearchEvent{
+				Results:  fileMatchesToSearchResults(matches),
+				Progress: searchProgress(&zoektCommon),
+			})
 		}
 	}()
 
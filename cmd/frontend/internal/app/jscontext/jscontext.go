@@ -109,11 +109,19 @@ func NewJSContextFromRequest(req *http.Request) JSContext {
 
 	// Auth providers
 	var authProviders []authProviderInfo
+	var hasSignInForm bool
 	for _, p := range providers.Providers() {
 		info := p.CachedInfo()
 		if info != nil {
+			// LDAP auth providers use the builtin username-password sign-in form, which is shown
+			// only once.
+			isBuiltin := p.Config().Builtin != nil || p.Config().Ldap != nil
+			if isBuiltin && hasSignInForm {
+				continue
+			}
+			hasSignInForm = hasSignInForm || isBuiltin
 			authProviders = append(authProviders, authProviderInfo{
-				IsBuiltin:         p.Config().Builtin != nil,
+				IsBuiltin:         isBuiltin,
 				DisplayName:       info.DisplayName,
 				AuthenticationURL: info.AuthenticationURL,
 			})
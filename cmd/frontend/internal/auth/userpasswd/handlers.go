@@ -8,6 +8,7 @@ import (
 	"strings"
 
 	"github.com/inconshreveable/log15"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/auth"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/authz"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/backend"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
@@ -196,8 +197,19 @@ func getByEmailOrUsername(ctx context.Context, emailOrUsername string) (*types.U
 
 // HandleSignIn accepts a POST containing username-password credentials and authenticates the
 // current session if the credentials are valid.
+//
+// The credentials are checked against the passwords of the builtin auth provider (if enabled) and
+// then against the enabled password authenticators (such as the LDAP auth provider).
 func HandleSignIn(w http.ResponseWriter, r *http.Request) {
-	if handleEnabledCheck(w) {
+	pc, multiple := getProviderConfig()
+	if multiple {
+		log15.Error("At most 1 builtin auth provider may be set in site config.")
+		http.Error(w, "Misconfigured builtin auth provider.", http.StatusInternalServerError)
+		return
+	}
+	authenticators := auth.PasswordAuthenticators()
+	if pc == nil && len(authenticators) == 0 {
+		http.Error(w, "Builtin auth provider is not enabled.", http.StatusForbidden)
 		return
 	}
 
@@ -213,23 +225,47 @@ func HandleSignIn(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Validate user. Allow login by both email and username (for convenience).
-	usr, err := getByEmailOrUsername(ctx, creds.Email)
-	if err != nil {
-		httpLogAndError(w, "Authentication failed", http.StatusUnauthorized, "err", err)
-		return
+	var userID int32
+	if pc != nil {
+		// Validate user. Allow login by both email and username (for convenience).
+		usr, err := getByEmailOrUsername(ctx, creds.Email)
+		if err != nil && (len(authenticators) == 0 || !errcode.IsNotFound(err)) {
+			httpLogAndError(w, "Authentication failed", http.StatusUnauthorized, "err", err)
+			return
+		}
+		if usr != nil {
+			// 🚨 SECURITY: check password
+			correct, err := db.Users.IsPassword(ctx, usr.ID, creds.Password)
+			if err != nil {
+				httpLogAndError(w, "Error checking password", http.StatusInternalServerError, "err", err)
+				return
+			}
+			if correct {
+				userID = usr.ID
+			}
+		}
 	}
-	// 🚨 SECURITY: check password
-	correct, err := db.Users.IsPassword(ctx, usr.ID, creds.Password)
-	if err != nil {
-		httpLogAndError(w, "Error checking password", http.StatusInternalServerError, "err", err)
-		return
+
+	for _, a := range authenticators {
+		if userID != 0 {
+			break
+		}
+		// 🚨 SECURITY: The authenticator checks the password.
+		id, safeErrMsg, err := a.Authenticate(ctx, creds.Email, creds.Password)
+		if err != nil {
+			if safeErrMsg == "" {
+				safeErrMsg = "Authentication failed"
+			}
+			httpLogAndError(w, safeErrMsg, http.StatusUnauthorized, "err", err)
+			return
+		}
+		userID = id
 	}
-	if !correct {
+	if userID == 0 {
 		httpLogAndError(w, "Authentication failed", http.StatusUnauthorized)
 		return
 	}
-	actor := &actor.Actor{UID: usr.ID}
+	actor := &actor.Actor{UID: userID}
 
 	// Write the session cookie
 	if err := session.SetActor(w, r, actor, 0); err != nil {
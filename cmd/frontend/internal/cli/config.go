@@ -2,6 +2,7 @@ package cli
 
 import (
 	"context"
+	"database/sql"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
@@ -13,9 +14,11 @@ import (
 
 	"github.com/inconshreveable/log15"
 	"github.com/pkg/errors"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/audit"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/globals"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
+	"github.com/sourcegraph/sourcegraph/internal/actor"
 	"github.com/sourcegraph/sourcegraph/internal/api"
 	"github.com/sourcegraph/sourcegraph/internal/conf"
 	"github.com/sourcegraph/sourcegraph/internal/conf/conftypes"
@@ -41,6 +44,15 @@ func printConfigValidation() {
 	}
 }
 
+func printAuditLogKeyWarning() {
+	if db.AuditLogKeyed() {
+		return
+	}
+	log15.Warn("!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!")
+	log15.Warn("⚠️ AUDIT_LOG_HMAC_KEY is not set, so the audit log is not tamper-evident: anyone who can write to the database can change its entries undetected. See https://docs.sourcegraph.com/admin/audit_log#setting-the-audit-log-key")
+	log15.Warn("!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!")
+}
+
 // handleConfigOverrides handles allowing dev environments to forcibly override
 // the configuration in the database upon startup. This is used to e.g. ensure
 // dev environments have a consistent configuration and to load secrets from a
@@ -185,14 +197,14 @@ func handleConfigOverrides() error {
 			// Apply the delta update.
 			for extSvc := range toRemove {
 				log15.Debug("Deleting external service", "id", extSvc.ID, "displayName", extSvc.DisplayName)
-				err := db.ExternalServices.Delete(ctx, extSvc.ID)
+				err := db.ExternalServices.Delete(ctx, nil, extSvc.ID)
 				if err != nil {
 					return errors.Wrap(err, "ExternalServices.Delete")
 				}
 			}
 			for extSvc := range toAdd {
 				log15.Debug("Adding external service", "displayName", extSvc.DisplayName)
-				if err := db.ExternalServices.Create(ctx, confGet, extSvc); err != nil {
+				if err := db.ExternalServices.Create(ctx, nil, confGet, extSvc); err != nil {
 					return errors.Wrap(err, "ExternalServices.Create")
 				}
 			}
@@ -202,7 +214,7 @@ func handleConfigOverrides() error {
 				log15.Debug("Updating external service", "id", id, "displayName", extSvc.DisplayName)
 
 				update := &db.ExternalServiceUpdate{DisplayName: &extSvc.DisplayName, Config: &extSvc.Config}
-				if err := db.ExternalServices.Update(ctx, ps, id, update); err != nil {
+				if err := db.ExternalServices.Update(ctx, nil, ps, id, update); err != nil {
 					return errors.Wrap(err, "ExternalServices.Update")
 				}
 			}
@@ -241,15 +253,26 @@ func (c configurationSource) Write(ctx context.Context, input conftypes.RawUnifi
 		return errors.Wrap(err, "confdb.SiteGetLatest")
 	}
 
-	_, err = confdb.CriticalCreateIfUpToDate(ctx, &critical.ID, input.Critical)
-	if err != nil {
-		return errors.Wrap(err, "confdb.CriticalCreateIfUpToDate")
-	}
-	_, err = confdb.SiteCreateIfUpToDate(ctx, &site.ID, input.Site)
-	if err != nil {
-		return errors.Wrap(err, "confdb.SiteCreateIfUpToDate")
+	// Attribute the new configuration to the current user (if any), so that it shows up in the
+	// configuration history.
+	var authorUserID *int32
+	if a := actor.FromContext(ctx); a.IsAuthenticated() {
+		authorUserID = &a.UID
 	}
-	return nil
+
+	// Save both configurations in a single transaction, in which the change of the site
+	// configuration is also recorded in the audit log (if it was made through the API).
+	return audit.Transact(ctx, func(tx *sql.Tx) (*audit.Event, error) {
+		_, err := confdb.CriticalCreateIfUpToDate(ctx, tx, &critical.ID, authorUserID, input.Critical)
+		if err != nil {
+			return nil, errors.Wrap(err, "confdb.CriticalCreateIfUpToDate")
+		}
+		latest, err := confdb.SiteCreateIfUpToDate(ctx, tx, &site.ID, authorUserID, input.Site)
+		if err != nil {
+			return nil, errors.Wrap(err, "confdb.SiteCreateIfUpToDate")
+		}
+		return audit.SiteConfigurationEvent(ctx, site.ID, latest.ID), nil
+	})
 }
 
 var (
@@ -2,13 +2,16 @@ package cli
 
 import (
 	"net/http"
+	"strconv"
 	"strings"
 
 	"github.com/NYTimes/gziphandler"
 	gcontext "github.com/gorilla/context"
 	"github.com/gorilla/mux"
 	"github.com/graph-gophers/graphql-go"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/audit"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/auth"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/authz"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/globals"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/hooks"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/httpapi"
@@ -29,14 +32,14 @@ import (
 
 // newExternalHTTPHandler creates and returns the HTTP handler that serves the app and API pages to
 // external clients.
-func newExternalHTTPHandler(schema *graphql.Schema, githubWebhook, bitbucketServerWebhook http.Handler, lsifServerProxy *httpapi.LSIFServerProxy) (http.Handler, error) {
+func newExternalHTTPHandler(schema *graphql.Schema, githubWebhook, bitbucketServerWebhook, gitlabWebhook http.Handler, lsifServerProxy *httpapi.LSIFServerProxy) (http.Handler, error) {
 	// Each auth middleware determines on a per-request basis whether it should be enabled (if not, it
 	// immediately delegates the request to the next middleware in the chain).
 	authMiddlewares := auth.AuthMiddleware()
 
 	// HTTP API handler.
 	r := router.New(mux.NewRouter().PathPrefix("/.api/").Subrouter())
-	apiHandler := internalhttpapi.NewHandler(r, schema, githubWebhook, bitbucketServerWebhook, lsifServerProxy)
+	apiHandler := internalhttpapi.NewHandler(r, schema, githubWebhook, bitbucketServerWebhook, gitlabWebhook, lsifServerProxy)
 	apiHandler = authMiddlewares.API(apiHandler) // 🚨 SECURITY: auth middleware
 	// 🚨 SECURITY: The HTTP API should not accept cookies as authentication (except those with the
 	// X-Requested-With header). Doing so would open it up to CSRF attacks.
@@ -53,9 +56,10 @@ func newExternalHTTPHandler(schema *graphql.Schema, githubWebhook, bitbucketServ
 	appHandler = handlerutil.CSRFMiddleware(appHandler, func() bool {
 		return globals.ExternalURL().Scheme == "https"
 	}) // after appAuthMiddleware because SAML IdP posts data to us w/o a CSRF token
-	appHandler = authMiddlewares.App(appHandler)                       // 🚨 SECURITY: auth middleware
-	appHandler = session.CookieMiddleware(appHandler)                  // app accepts cookies
-	appHandler = internalhttpapi.AccessTokenAuthMiddleware(appHandler) // app accepts access tokens
+	appHandler = authMiddlewares.App(appHandler)                                   // 🚨 SECURITY: auth middleware
+	appHandler = session.CookieMiddleware(appHandler)                              // app accepts cookies
+	appHandler = internalhttpapi.RequireTokenScope(authz.ScopeUserAll, appHandler) // 🚨 SECURITY: restricted access tokens may only be used with the API
+	appHandler = internalhttpapi.AccessTokenAuthMiddleware(appHandler)             // app accepts access tokens
 	if hooks.PostAuthMiddleware != nil {
 		// 🚨 SECURITY: These all run after the auth handler so the client is authenticated.
 		appHandler = hooks.PostAuthMiddleware(appHandler)
@@ -75,6 +79,7 @@ func newExternalHTTPHandler(schema *graphql.Schema, githubWebhook, bitbucketServ
 	h = internalauth.OverrideAuthMiddleware(h)
 	h = internalauth.ForbidAllRequestsMiddleware(h)
 	h = tracepkg.HTTPTraceMiddleware(h)
+	h = audit.Middleware(h)
 	h = ot.Middleware(h)
 	h = middleware.SourcegraphComGoGetHandler(h)
 	h = middleware.BlackHole(h)
@@ -112,13 +117,23 @@ func newInternalHTTPHandler(schema *graphql.Schema) http.Handler {
 }
 
 // withInternalActor wraps an existing HTTP handler by setting an internal actor in the HTTP request
-// context.
+// context. Requests with the actor.UIDHeader header are made as that user instead, so that their
+// repository permissions apply.
 //
 // 🚨 SECURITY: This should *never* be called to wrap externally accessible handlers (i.e., only use
 // for the internal endpoint), because internal requests will bypass repository permissions checks.
 func withInternalActor(h http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		rWithActor := r.WithContext(actor.WithActor(r.Context(), &actor.Actor{Internal: true}))
+		a := &actor.Actor{Internal: true}
+		if v := r.Header.Get(actor.UIDHeader); v != "" {
+			uid, err := strconv.ParseInt(v, 10, 32)
+			if err != nil || uid <= 0 {
+				http.Error(w, "invalid "+actor.UIDHeader+" header", http.StatusBadRequest)
+				return
+			}
+			a = actor.FromUser(int32(uid))
+		}
+		rWithActor := r.WithContext(actor.WithActor(r.Context(), a))
 		h.ServeHTTP(w, rWithActor)
 	})
 }
@@ -0,0 +1,126 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/inconshreveable/log15"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/backend"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
+)
+
+// auditLogExportPageSize is the number of audit log entries that are read from the database at a
+// time while exporting.
+const auditLogExportPageSize = 1000
+
+// auditLogExportEntry is the representation of an audit log entry in an export. Its fields are
+// sufficient to verify the hash of the entry.
+type auditLogExportEntry struct {
+	ID           int64           `json:"id"`
+	ActorUserID  int32           `json:"actorUserID"`
+	ActorIP      string          `json:"actorIP"`
+	ForwardedFor string          `json:"forwardedFor"`
+	Action       string          `json:"action"`
+	TargetType   string          `json:"targetType"`
+	TargetID     string          `json:"targetID"`
+	Before       json.RawMessage `json:"before"`
+	After        json.RawMessage `json:"after"`
+	CreatedAt    time.Time       `json:"createdAt"`
+	PrevHash     string          `json:"prevHash"`
+	Hash         string          `json:"hash"`
+}
+
+// serveAuditLogExport writes the audit log entries that match the filters in the query string as
+// JSON lines, oldest first.
+func serveAuditLogExport(w http.ResponseWriter, r *http.Request) {
+	// 🚨 SECURITY: Only site admins can view the audit log.
+	if err := backend.CheckCurrentUserIsSiteAdmin(r.Context()); err != nil {
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
+
+	opt, err := parseAuditLogExportQuery(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	opt.Ascending = true
+	opt.LimitOffset = &db.LimitOffset{Limit: auditLogExportPageSize}
+
+	// Read the first page before writing the response, so that errors can still be reported with
+	// the status code.
+	entries, err := db.AuditLog.List(r.Context(), opt)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.Header().Set("Content-Disposition", `attachment; filename="audit-log.jsonl"`)
+
+	enc := json.NewEncoder(w)
+	for len(entries) > 0 {
+		for _, e := range entries {
+			if err := enc.Encode(auditLogExportEntry{
+				ID:           e.ID,
+				ActorUserID:  e.ActorUserID,
+				ActorIP:      e.ActorIP,
+				ForwardedFor: e.ForwardedFor,
+				Action:       e.Action,
+				TargetType:   e.TargetType,
+				TargetID:     e.TargetID,
+				Before:       e.Before,
+				After:        e.After,
+				CreatedAt:    e.CreatedAt.UTC(),
+				PrevHash:     e.PrevHash,
+				Hash:         e.Hash,
+			}); err != nil {
+				return
+			}
+		}
+		if len(entries) < auditLogExportPageSize {
+			return
+		}
+
+		opt.AfterID = entries[len(entries)-1].ID
+		if entries, err = db.AuditLog.List(r.Context(), opt); err != nil {
+			// The response status was already sent, so the client can only notice a truncated
+			// file.
+			log15.Error("httpapi: writing audit log export failed", "error", err)
+			return
+		}
+	}
+}
+
+// parseAuditLogExportQuery returns the list options for the filters in the query string of an
+// audit log export request. They are the same as the filters of the GraphQL Site.auditLog field.
+func parseAuditLogExportQuery(r *http.Request) (db.AuditLogListOptions, error) {
+	q := r.URL.Query()
+	opt := db.AuditLogListOptions{
+		Action:     q.Get("action"),
+		TargetType: q.Get("targetType"),
+		TargetID:   q.Get("targetID"),
+	}
+	if v := q.Get("actorUserID"); v != "" {
+		id, err := strconv.ParseInt(v, 10, 32)
+		if err != nil {
+			return opt, err
+		}
+		opt.ActorUserID = int32(id)
+	}
+	for name, t := range map[string]**time.Time{
+		"createdAfter":  &opt.CreatedAfter,
+		"createdBefore": &opt.CreatedBefore,
+	} {
+		if v := q.Get(name); v != "" {
+			parsed, err := time.Parse(time.RFC3339Nano, v)
+			if err != nil {
+				return opt, err
+			}
+			*t = &parsed
+		}
+	}
+	return opt, nil
+}
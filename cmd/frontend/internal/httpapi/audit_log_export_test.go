@@ -0,0 +1,81 @@
+package httpapi
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
+	"github.com/sourcegraph/sourcegraph/internal/actor"
+)
+
+func TestServeAuditLogExport(t *testing.T) {
+	const total = auditLogExportPageSize + 1
+	var listCalls int
+	db.Mocks.AuditLog.List = func(_ context.Context, opt db.AuditLogListOptions) ([]*db.AuditLogEntry, error) {
+		listCalls++
+		if !opt.Ascending || opt.Action != db.AuditLogActionSetSiteAdmin || opt.ActorUserID != 1 || opt.CreatedAfter == nil {
+			t.Errorf("unexpected list options %+v", opt)
+		}
+		var entries []*db.AuditLogEntry
+		for id := opt.AfterID + 1; id <= total && len(entries) < opt.Limit; id++ {
+			entries = append(entries, &db.AuditLogEntry{ID: id, ActorUserID: 1, Action: db.AuditLogActionSetSiteAdmin})
+		}
+		return entries, nil
+	}
+	db.Mocks.Users.GetByCurrentAuthUser = func(ctx context.Context) (*types.User, error) {
+		uid := actor.FromContext(ctx).UID
+		return &types.User{ID: uid, SiteAdmin: uid == 1}, nil
+	}
+	defer func() { db.Mocks = db.MockStores{} }()
+
+	serve := func(uid int32) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", "/audit-log/export?actorUserID=1&action=SET_SITE_ADMIN&createdAfter=2020-06-01T00:00:00Z", nil)
+		req = req.WithContext(actor.WithActor(req.Context(), &actor.Actor{UID: uid}))
+		rec := httptest.NewRecorder()
+		serveAuditLogExport(rec, req)
+		return rec
+	}
+
+	t.Run("site admin", func(t *testing.T) {
+		rec := serve(1)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("have status %d, want %d", rec.Code, http.StatusOK)
+		}
+		if have, want := rec.Header().Get("Content-Type"), "application/x-ndjson"; have != want {
+			t.Errorf("have Content-Type %q, want %q", have, want)
+		}
+
+		var ids []int64
+		s := bufio.NewScanner(rec.Body)
+		for s.Scan() {
+			var e auditLogExportEntry
+			if err := json.Unmarshal(s.Bytes(), &e); err != nil {
+				t.Fatal(err)
+			}
+			ids = append(ids, e.ID)
+		}
+		if len(ids) != total {
+			t.Fatalf("have %d entries, want %d", len(ids), total)
+		}
+		for i, id := range ids {
+			if id != int64(i+1) {
+				t.Fatalf("have entry %d at line %d, want entries in ascending order", id, i+1)
+			}
+		}
+		if want := 2; listCalls != want {
+			t.Errorf("have %d list calls, want %d", listCalls, want)
+		}
+	})
+
+	// 🚨 SECURITY: Only site admins may export the audit log.
+	t.Run("non-admin", func(t *testing.T) {
+		if rec := serve(2); rec.Code != http.StatusForbidden {
+			t.Errorf("have status %d, want %d", rec.Code, http.StatusForbidden)
+		}
+	})
+}
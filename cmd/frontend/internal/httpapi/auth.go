@@ -2,12 +2,14 @@ package httpapi
 
 import (
 	"net/http"
+	"strings"
 
 	"github.com/inconshreveable/log15"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/authz"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/backend"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
 	"github.com/sourcegraph/sourcegraph/internal/actor"
+	"github.com/sourcegraph/sourcegraph/internal/api"
 	"github.com/sourcegraph/sourcegraph/internal/conf"
 	"github.com/sourcegraph/sourcegraph/internal/errcode"
 )
@@ -18,6 +20,13 @@ func AccessTokenAuthMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Add("Vary", "Authorization")
 
+		// 🚨 SECURITY: SCIM requests are authenticated by the SCIM handler with the SCIM bearer
+		// token, which must not be treated (or logged) as an access token.
+		if strings.HasPrefix(r.URL.Path, "/.api/scim/") {
+			next.ServeHTTP(w, r)
+			return
+		}
+
 		var sudoUser string
 		token := r.URL.Query().Get("token")
 
@@ -60,19 +69,32 @@ func AccessTokenAuthMiddleware(next http.Handler) http.Handler {
 			// Validate access token.
 			//
 			// 🚨 SECURITY: It's important we check for the correct scopes to know what this token
-			// is allowed to do.
-			var requiredScope string
+			// is allowed to do. Tokens without the "user:all" scope are restricted, and the
+			// restrictions are enforced per route (see RequireTokenScope) and per GraphQL field.
+			var requiredScopes []string
 			if sudoUser == "" {
-				requiredScope = authz.ScopeUserAll
+				requiredScopes = authz.SubjectScopes
 			} else {
-				requiredScope = authz.ScopeSiteAdminSudo
+				requiredScopes = []string{authz.ScopeSiteAdminSudo}
 			}
-			subjectUserID, err := db.AccessTokens.Lookup(r.Context(), token, requiredScope)
+			accessToken, err := db.AccessTokens.Lookup(r.Context(), token, requiredScopes...)
 			if err != nil {
 				log15.Error("Invalid access token.", "token", token, "err", err)
 				http.Error(w, "Invalid access token.", http.StatusUnauthorized)
 				return
 			}
+			subjectUserID := accessToken.SubjectUserID
+
+			ctx := r.Context()
+			if !accessToken.HasScope(authz.ScopeUserAll) {
+				restrictions, err := authz.NewTokenRestrictions(accessToken.Scopes, accessToken.RepositoryPatterns)
+				if err != nil {
+					log15.Error("Invalid access token restrictions.", "tokenID", accessToken.ID, "err", err)
+					http.Error(w, "Invalid access token.", http.StatusUnauthorized)
+					return
+				}
+				ctx = authz.WithTokenRestrictions(ctx, restrictions)
+			}
 
 			// Determine the actor's user ID.
 			var actorUserID int32
@@ -105,9 +127,35 @@ func AccessTokenAuthMiddleware(next http.Handler) http.Handler {
 				log15.Debug("HTTP request used sudo token.", "requestURI", r.URL.RequestURI(), "tokenSubjectUserID", subjectUserID, "actorUserID", actorUserID, "actorUsername", user.Username)
 			}
 
-			r = r.WithContext(actor.WithActor(r.Context(), &actor.Actor{UID: actorUserID}))
+			r = r.WithContext(actor.WithActor(ctx, &actor.Actor{UID: actorUserID}))
 		}
 
 		next.ServeHTTP(w, r)
 	})
 }
+
+// RequireTokenScope wraps the handler so that requests authenticated with a restricted access
+// token (i.e., one without the "user:all" scope) are only served if the access token has the given
+// scope. Requests that are not authenticated with a restricted access token are passed through.
+func RequireTokenScope(scope string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := authz.CheckTokenScope(r.Context(), scope); err != nil {
+			http.Error(w, err.Error(), http.StatusForbidden)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// requireTokenRepoParam wraps the handler so that requests authenticated with a restricted access
+// token are only served if the access token may be used with the repository named by the given
+// query parameter.
+func requireTokenRepoParam(param string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := authz.CheckTokenRepo(r.Context(), api.RepoName(r.URL.Query().Get(param))); err != nil {
+			http.Error(w, err.Error(), http.StatusForbidden)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
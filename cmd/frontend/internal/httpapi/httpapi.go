@@ -12,6 +12,7 @@ import (
 	"github.com/graph-gophers/graphql-go"
 	"github.com/inconshreveable/log15"
 	opentracing "github.com/opentracing/opentracing-go"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/authz"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/backend"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/envvar"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/httpapi"
@@ -30,7 +31,7 @@ import (
 //
 // 🚨 SECURITY: The caller MUST wrap the returned handler in middleware that checks authentication
 // and sets the actor in the request context.
-func NewHandler(m *mux.Router, schema *graphql.Schema, githubWebhook, bitbucketServerWebhook http.Handler, lsifServerProxy *httpapi.LSIFServerProxy) http.Handler {
+func NewHandler(m *mux.Router, schema *graphql.Schema, githubWebhook, bitbucketServerWebhook, gitlabWebhook http.Handler, lsifServerProxy *httpapi.LSIFServerProxy) http.Handler {
 	if m == nil {
 		m = apirouter.New(nil)
 	}
@@ -44,9 +45,9 @@ func NewHandler(m *mux.Router, schema *graphql.Schema, githubWebhook, bitbucketS
 	})
 
 	// Set handlers for the installed routes.
-	m.Get(apirouter.RepoShield).Handler(trace.TraceRoute(handler(serveRepoShield)))
+	m.Get(apirouter.RepoShield).Handler(trace.TraceRoute(RequireTokenScope(authz.ScopeUserAll, handler(serveRepoShield))))
 
-	m.Get(apirouter.RepoRefresh).Handler(trace.TraceRoute(handler(serveRepoRefresh)))
+	m.Get(apirouter.RepoRefresh).Handler(trace.TraceRoute(RequireTokenScope(authz.ScopeUserAll, handler(serveRepoRefresh))))
 
 	if githubWebhook != nil {
 		m.Get(apirouter.GitHubWebhooks).Handler(trace.TraceRoute(githubWebhook))
@@ -56,14 +57,23 @@ func NewHandler(m *mux.Router, schema *graphql.Schema, githubWebhook, bitbucketS
 		m.Get(apirouter.BitbucketServerWebhooks).Handler(trace.TraceRoute(bitbucketServerWebhook))
 	}
 
+	if gitlabWebhook != nil {
+		m.Get(apirouter.GitLabWebhooks).Handler(trace.TraceRoute(gitlabWebhook))
+	}
+
 	if envvar.SourcegraphDotComMode() {
 		m.Path("/updates").Methods("GET", "POST").Name("updatecheck").Handler(trace.TraceRoute(http.HandlerFunc(updatecheck.Handler)))
 	}
 
+	// The scopes of restricted access tokens are checked per field by the GraphQL schema.
 	m.Get(apirouter.GraphQL).Handler(trace.TraceRoute(handler(serveGraphQL(schema))))
 
+	m.Get(apirouter.SearchStream).Handler(trace.TraceRoute(RequireTokenScope(authz.ScopeSearchRead, http.HandlerFunc(serveSearchStream))))
+	m.Get(apirouter.SearchExport).Handler(trace.TraceRoute(RequireTokenScope(authz.ScopeSearchRead, http.HandlerFunc(serveSearchExportDownload))))
+	m.Get(apirouter.AuditLogExport).Handler(trace.TraceRoute(RequireTokenScope(authz.ScopeUserAll, http.HandlerFunc(serveAuditLogExport))))
+
 	if lsifServerProxy != nil {
-		m.Get(apirouter.LSIFUpload).Handler(trace.TraceRoute(lsifServerProxy.UploadHandler))
+		m.Get(apirouter.LSIFUpload).Handler(trace.TraceRoute(RequireTokenScope(authz.ScopeLSIFUpload, requireTokenRepoParam("repository", lsifServerProxy.UploadHandler))))
 	} else {
 		m.Get(apirouter.LSIFUpload).Handler(trace.TraceRoute(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			w.WriteHeader(http.StatusNotFound)
@@ -75,7 +85,14 @@ func NewHandler(m *mux.Router, schema *graphql.Schema, githubWebhook, bitbucketS
 	m.Get(apirouter.SrcCliVersion).Handler(trace.TraceRoute(handler(srcCliVersionServe)))
 	m.Get(apirouter.SrcCliDownload).Handler(trace.TraceRoute(handler(srcCliDownloadServe)))
 
-	m.Get(apirouter.Registry).Handler(trace.TraceRoute(handler(registry.HandleRegistry)))
+	m.Get(apirouter.Registry).Handler(trace.TraceRoute(RequireTokenScope(authz.ScopeUserAll, handler(registry.HandleRegistry))))
+
+	// SCIM provisioning is authenticated by the SCIM handler with its own bearer token.
+	scim := newSCIMServer()
+	m.Get(apirouter.SCIMUsers).Handler(trace.TraceRoute(scim.handler(scim.serveUsers)))
+	m.Get(apirouter.SCIMUser).Handler(trace.TraceRoute(scim.handler(scim.serveUser)))
+	m.Get(apirouter.SCIMGroups).Handler(trace.TraceRoute(scim.handler(scim.serveGroups)))
+	m.Get(apirouter.SCIMGroup).Handler(trace.TraceRoute(scim.handler(scim.serveGroup)))
 
 	m.NotFoundHandler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		log.Printf("API no route: %s %s from %s", r.Method, r.URL, r.Referer())
@@ -7,8 +7,12 @@ import (
 )
 
 const (
-	LSIFUpload = "lsif.upload"
-	GraphQL    = "graphql"
+	LSIFUpload   = "lsif.upload"
+	GraphQL      = "graphql"
+	SearchStream = "search.stream"
+	SearchExport = "search.export"
+
+	AuditLogExport = "auditLog.export"
 
 	SrcCliVersion  = "src-cli.version"
 	SrcCliDownload = "src-cli.download"
@@ -21,6 +25,12 @@ const (
 
 	GitHubWebhooks          = "github.webhooks"
 	BitbucketServerWebhooks = "bitbucketServer.webhooks"
+	GitLabWebhooks          = "gitlab.webhooks"
+
+	SCIMUsers  = "scim.users"
+	SCIMUser   = "scim.user"
+	SCIMGroups = "scim.groups"
+	SCIMGroup  = "scim.group"
 
 	SavedQueriesListAll    = "internal.saved-queries.list-all"
 	SavedQueriesGetInfo    = "internal.saved-queries.get-info"
@@ -64,9 +74,17 @@ func New(base *mux.Router) *mux.Router {
 	addGraphQLRoute(base)
 	base.Path("/github-webhooks").Methods("POST").Name(GitHubWebhooks)
 	base.Path("/bitbucket-server-webhooks").Methods("POST").Name(BitbucketServerWebhooks)
+	base.Path("/gitlab-webhooks").Methods("POST").Name(GitLabWebhooks)
 	base.Path("/lsif/upload").Methods("POST").Name(LSIFUpload)
+	base.Path("/search/stream").Methods("GET").Name(SearchStream)
+	base.Path("/search/export/{id:[0-9]+}").Methods("GET").Name(SearchExport)
+	base.Path("/audit-log/export").Methods("GET").Name(AuditLogExport)
 	base.Path("/src-cli/version").Methods("GET").Name(SrcCliVersion)
 	base.Path("/src-cli/{rest:.*}").Methods("GET").Name(SrcCliDownload)
+	base.Path("/scim/v2/Users").Methods("GET", "POST").Name(SCIMUsers)
+	base.Path("/scim/v2/Users/{id}").Methods("GET", "PUT", "PATCH", "DELETE").Name(SCIMUser)
+	base.Path("/scim/v2/Groups").Methods("GET", "POST").Name(SCIMGroups)
+	base.Path("/scim/v2/Groups/{id}").Methods("GET", "PUT", "PATCH", "DELETE").Name(SCIMGroup)
 
 	// repo contains routes that are NOT specific to a revision. In these routes, the URL may not contain a revspec after the repo (that is, no "github.com/foo/bar@myrevspec").
 	repoPath := `/repos/` + routevar.Repo
@@ -0,0 +1,290 @@
+package httpapi
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/inconshreveable/log15"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/globals"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
+	"github.com/sourcegraph/sourcegraph/internal/conf"
+)
+
+// SCIM 2.0 (RFC 7643 and RFC 7644) schema URNs.
+const (
+	scimSchemaUser         = "urn:ietf:params:scim:schemas:core:2.0:User"
+	scimSchemaGroup        = "urn:ietf:params:scim:schemas:core:2.0:Group"
+	scimSchemaListResponse = "urn:ietf:params:scim:api:messages:2.0:ListResponse"
+	scimSchemaError        = "urn:ietf:params:scim:api:messages:2.0:Error"
+)
+
+const (
+	scimDefaultCount = 100
+	scimMaxCount     = 1000
+)
+
+// scimServer serves the SCIM 2.0 /Users and /Groups endpoints, which enable an identity provider
+// to manage the lifecycle of users and their organization memberships. SCIM users are Sourcegraph
+// users, and SCIM groups are Sourcegraph organizations.
+//
+// 🚨 SECURITY: Requests are authenticated with the bearer token in the "auth.scim" site
+// configuration, not with the actor of the request.
+type scimServer struct {
+	// Users, UserEmails, Orgs and OrgMembers are the subsets of the db stores we use. Declared as
+	// interfaces for testing.
+	Users interface {
+		Create(ctx context.Context, info db.NewUser) (*types.User, error)
+		Update(ctx context.Context, id int32, update db.UserUpdate) error
+		Delete(ctx context.Context, id int32) error
+		HardDelete(ctx context.Context, id int32) error
+		Deactivate(ctx context.Context, id int32) error
+		Reactivate(ctx context.Context, id int32) error
+		List(ctx context.Context, opt *db.UsersListOptions) ([]*types.User, error)
+		Count(ctx context.Context, opt *db.UsersListOptions) (int, error)
+	}
+	UserEmails interface {
+		ListByUser(ctx context.Context, opt db.UserEmailsListOptions) ([]*db.UserEmail, error)
+		Add(ctx context.Context, userID int32, email string, verificationCode *string) error
+		Remove(ctx context.Context, userID int32, email string) error
+		SetVerified(ctx context.Context, userID int32, email string, verified bool) error
+	}
+	Orgs interface {
+		Create(ctx context.Context, name string, displayName *string) (*types.Org, error)
+		GetByID(ctx context.Context, id int32) (*types.Org, error)
+		GetByName(ctx context.Context, name string) (*types.Org, error)
+		GetByUserID(ctx context.Context, userID int32) ([]*types.Org, error)
+		List(ctx context.Context, opt *db.OrgsListOptions) ([]*types.Org, error)
+		Count(ctx context.Context, opt db.OrgsListOptions) (int, error)
+		Update(ctx context.Context, id int32, displayName *string) (*types.Org, error)
+		Delete(ctx context.Context, id int32) error
+	}
+	OrgMembers interface {
+		Create(ctx context.Context, orgID, userID int32) (*types.OrgMembership, error)
+		Remove(ctx context.Context, orgID, userID int32) error
+		GetByOrgID(ctx context.Context, orgID int32) ([]*types.OrgMembership, error)
+	}
+}
+
+func newSCIMServer() *scimServer {
+	return &scimServer{
+		Users:      db.Users,
+		UserEmails: db.UserEmails,
+		Orgs:       db.Orgs,
+		OrgMembers: db.OrgMembers,
+	}
+}
+
+// scimError is an error that is reported to the SCIM client with the given HTTP status code and
+// SCIM error type (see RFC 7644 section 3.12).
+type scimError struct {
+	Status   int
+	SCIMType string
+	Detail   string
+}
+
+func (e *scimError) Error() string { return e.Detail }
+
+func scimErrorf(status int, scimType, format string, args ...interface{}) *scimError {
+	return &scimError{Status: status, SCIMType: scimType, Detail: fmt.Sprintf(format, args...)}
+}
+
+// handler wraps a SCIM handler func with authentication and error reporting.
+func (s *scimServer) handler(h func(w http.ResponseWriter, r *http.Request) error) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		err := s.authenticate(r)
+		if err == nil {
+			err = h(w, r)
+		}
+		if err == nil {
+			return
+		}
+
+		e, ok := err.(*scimError)
+		if !ok {
+			log15.Error("SCIM request failed.", "method", r.Method, "path", r.URL.Path, "err", err)
+			e = &scimError{Status: http.StatusInternalServerError, Detail: "internal error"}
+		}
+		_ = writeSCIM(w, e.Status, map[string]interface{}{
+			"schemas":  []string{scimSchemaError},
+			"status":   strconv.Itoa(e.Status),
+			"scimType": e.SCIMType,
+			"detail":   e.Detail,
+		})
+	})
+}
+
+// authenticate returns an error unless the request carries the SCIM bearer token from the site
+// configuration.
+func (s *scimServer) authenticate(r *http.Request) error {
+	c := conf.Get().AuthScim
+	if c == nil || c.BearerToken == "" {
+		return scimErrorf(http.StatusNotFound, "", "SCIM provisioning is not enabled")
+	}
+
+	const prefix = "bearer "
+	header := r.Header.Get("Authorization")
+	if len(header) <= len(prefix) || !strings.EqualFold(header[:len(prefix)], prefix) {
+		return scimErrorf(http.StatusUnauthorized, "", "missing SCIM bearer token")
+	}
+	// 🚨 SECURITY: Use a constant time comparison to avoid leaking the token through timing.
+	if subtle.ConstantTimeCompare([]byte(header[len(prefix):]), []byte(c.BearerToken)) != 1 {
+		return scimErrorf(http.StatusUnauthorized, "", "invalid SCIM bearer token")
+	}
+	return nil
+}
+
+func writeSCIM(w http.ResponseWriter, status int, v interface{}) error {
+	w.Header().Set("Content-Type", "application/scim+json")
+	w.WriteHeader(status)
+	return json.NewEncoder(w).Encode(v)
+}
+
+func readSCIM(r *http.Request, v interface{}) error {
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		return scimErrorf(http.StatusBadRequest, "invalidSyntax", "invalid request body: %s", err)
+	}
+	return nil
+}
+
+// scimResourceID returns the ID in the URL of the request.
+func scimResourceID(r *http.Request) (int32, error) {
+	id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 32)
+	if err != nil {
+		return 0, scimErrorf(http.StatusNotFound, "", "resource %q not found", mux.Vars(r)["id"])
+	}
+	return int32(id), nil
+}
+
+func scimLocation(resourceType string, id int32) string {
+	return fmt.Sprintf("%s/.api/scim/v2/%s/%d", strings.TrimSuffix(globals.ExternalURL().String(), "/"), resourceType, id)
+}
+
+type scimMeta struct {
+	ResourceType string    `json:"resourceType"`
+	Created      time.Time `json:"created"`
+	LastModified time.Time `json:"lastModified"`
+	Location     string    `json:"location"`
+}
+
+// scimReference is a reference to a user (a group member) or a group (a user's group).
+type scimReference struct {
+	Value   string `json:"value"`
+	Display string `json:"display,omitempty"`
+}
+
+type scimListResponse struct {
+	Schemas      []string      `json:"schemas"`
+	TotalResults int           `json:"totalResults"`
+	StartIndex   int           `json:"startIndex"`
+	ItemsPerPage int           `json:"itemsPerPage"`
+	Resources    []interface{} `json:"Resources"`
+}
+
+// scimListParams are the pagination and filter parameters of a list request.
+type scimListParams struct {
+	startIndex int // 1-based
+	count      int
+
+	// filterAttribute and filterValue are the operands of an "eq" filter, if any.
+	filterAttribute string
+	filterValue     string
+}
+
+var scimEqFilter = regexp.MustCompile(`^\s*([A-Za-z.]+)\s+(?i:eq)\s+"((?:[^"\\]|\\.)*)"\s*$`)
+
+func parseSCIMListParams(r *http.Request) (*scimListParams, error) {
+	p := &scimListParams{startIndex: 1, count: scimDefaultCount}
+	q := r.URL.Query()
+	if v := q.Get("startIndex"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, scimErrorf(http.StatusBadRequest, "invalidValue", "invalid startIndex %q", v)
+		}
+		if n > 1 {
+			p.startIndex = n
+		}
+	}
+	if v := q.Get("count"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, scimErrorf(http.StatusBadRequest, "invalidValue", "invalid count %q", v)
+		}
+		if n < 0 {
+			n = 0
+		}
+		if n > scimMaxCount {
+			n = scimMaxCount
+		}
+		p.count = n
+	}
+	if v := q.Get("filter"); v != "" {
+		m := scimEqFilter.FindStringSubmatch(v)
+		if m == nil {
+			return nil, scimErrorf(http.StatusBadRequest, "invalidFilter", "unsupported filter %q (only \"eq\" filters are supported)", v)
+		}
+		value, err := strconv.Unquote(`"` + m[2] + `"`)
+		if err != nil {
+			return nil, scimErrorf(http.StatusBadRequest, "invalidFilter", "invalid filter value in %q", v)
+		}
+		p.filterAttribute = m[1]
+		p.filterValue = value
+	}
+	return p, nil
+}
+
+func (p *scimListParams) limitOffset() *db.LimitOffset {
+	return &db.LimitOffset{Limit: p.count, Offset: p.startIndex - 1}
+}
+
+// parseSCIMBool parses a boolean SCIM attribute value. Some identity providers send booleans as
+// strings (e.g. "False"), so both forms are accepted.
+func parseSCIMBool(raw json.RawMessage) (bool, error) {
+	var b bool
+	if err := json.Unmarshal(raw, &b); err == nil {
+		return b, nil
+	}
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		if b, err := strconv.ParseBool(strings.ToLower(s)); err == nil {
+			return b, nil
+		}
+	}
+	return false, scimErrorf(http.StatusBadRequest, "invalidValue", "invalid boolean value %s", raw)
+}
+
+// scimPatchRequest is the body of a PATCH request (see RFC 7644 section 3.5.2).
+type scimPatchRequest struct {
+	Operations []scimPatchOperation `json:"Operations"`
+}
+
+type scimPatchOperation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	Value json.RawMessage `json:"value"`
+}
+
+// attributes returns the attributes set by an "add" or "replace" operation, keyed by their lower
+// case path. An operation without a path sets the attributes of its value object.
+func (op scimPatchOperation) attributes() (map[string]json.RawMessage, error) {
+	if op.Path != "" {
+		return map[string]json.RawMessage{strings.ToLower(op.Path): op.Value}, nil
+	}
+	var values map[string]json.RawMessage
+	if err := json.Unmarshal(op.Value, &values); err != nil {
+		return nil, scimErrorf(http.StatusBadRequest, "invalidValue", "operation without a path must have an object value")
+	}
+	attrs := make(map[string]json.RawMessage, len(values))
+	for k, v := range values {
+		attrs[strings.ToLower(k)] = v
+	}
+	return attrs, nil
+}
@@ -0,0 +1,423 @@
+package httpapi
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/auth"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
+)
+
+// scimGroup is the SCIM representation of a Sourcegraph organization. The organization's name is
+// derived from the group's displayName when the group is created.
+type scimGroup struct {
+	Schemas     []string        `json:"schemas"`
+	ID          string          `json:"id,omitempty"`
+	DisplayName string          `json:"displayName"`
+	Members     []scimReference `json:"members"`
+	Meta        *scimMeta       `json:"meta,omitempty"`
+}
+
+func (s *scimServer) serveGroups(w http.ResponseWriter, r *http.Request) error {
+	switch r.Method {
+	case "GET":
+		return s.listGroups(w, r)
+	case "POST":
+		return s.createGroup(w, r)
+	}
+	return scimErrorf(http.StatusMethodNotAllowed, "", "method %s not allowed", r.Method)
+}
+
+func (s *scimServer) serveGroup(w http.ResponseWriter, r *http.Request) error {
+	id, err := scimResourceID(r)
+	if err != nil {
+		return err
+	}
+	org, err := s.getOrg(r.Context(), id)
+	if err != nil {
+		return err
+	}
+
+	switch r.Method {
+	case "GET":
+	case "PUT":
+		var body scimGroup
+		if err := readSCIM(r, &body); err != nil {
+			return err
+		}
+		if err := s.setOrgDisplayName(r.Context(), org, body.DisplayName); err != nil {
+			return err
+		}
+		members, err := scimMemberIDs(body.Members)
+		if err != nil {
+			return err
+		}
+		if err := s.setOrgMembers(r.Context(), org.ID, members); err != nil {
+			return err
+		}
+	case "PATCH":
+		var body scimPatchRequest
+		if err := readSCIM(r, &body); err != nil {
+			return err
+		}
+		if err := s.patchGroup(r.Context(), org, body.Operations); err != nil {
+			return err
+		}
+	case "DELETE":
+		if err := s.Orgs.Delete(r.Context(), org.ID); err != nil {
+			return err
+		}
+		w.WriteHeader(http.StatusNoContent)
+		return nil
+	default:
+		return scimErrorf(http.StatusMethodNotAllowed, "", "method %s not allowed", r.Method)
+	}
+
+	if r.Method != "GET" {
+		if org, err = s.getOrg(r.Context(), id); err != nil {
+			return err
+		}
+	}
+	res, err := s.toSCIMGroup(r.Context(), org)
+	if err != nil {
+		return err
+	}
+	return writeSCIM(w, http.StatusOK, res)
+}
+
+func (s *scimServer) listGroups(w http.ResponseWriter, r *http.Request) error {
+	params, err := parseSCIMListParams(r)
+	if err != nil {
+		return err
+	}
+	res := &scimListResponse{
+		Schemas:    []string{scimSchemaListResponse},
+		StartIndex: params.startIndex,
+		Resources:  []interface{}{},
+	}
+
+	var orgs []*types.Org
+	switch strings.ToLower(params.filterAttribute) {
+	case "":
+		res.TotalResults, err = s.Orgs.Count(r.Context(), db.OrgsListOptions{})
+		if err != nil {
+			return err
+		}
+		if params.count > 0 {
+			orgs, err = s.Orgs.List(r.Context(), &db.OrgsListOptions{LimitOffset: params.limitOffset()})
+			if err != nil {
+				return err
+			}
+		}
+	case "displayname":
+		org, err := s.getOrgByDisplayName(r.Context(), params.filterValue)
+		if err != nil {
+			return err
+		}
+		if org != nil {
+			res.TotalResults = 1
+			if params.startIndex == 1 && params.count > 0 {
+				orgs = []*types.Org{org}
+			}
+		}
+	default:
+		return scimErrorf(http.StatusBadRequest, "invalidFilter", "filtering by %q is not supported", params.filterAttribute)
+	}
+
+	for _, org := range orgs {
+		g, err := s.toSCIMGroup(r.Context(), org)
+		if err != nil {
+			return err
+		}
+		res.Resources = append(res.Resources, g)
+	}
+	res.ItemsPerPage = len(res.Resources)
+	return writeSCIM(w, http.StatusOK, res)
+}
+
+func (s *scimServer) createGroup(w http.ResponseWriter, r *http.Request) error {
+	var body scimGroup
+	if err := readSCIM(r, &body); err != nil {
+		return err
+	}
+	name, err := scimOrgName(body.DisplayName)
+	if err != nil {
+		return err
+	}
+	members, err := scimMemberIDs(body.Members)
+	if err != nil {
+		return err
+	}
+
+	// Organization names share a namespace with usernames. Check both before creating the
+	// organization, so we can report a conflict to the client.
+	if _, err := s.Orgs.GetByName(r.Context(), name); err == nil {
+		return scimErrorf(http.StatusConflict, "uniqueness", "a group with the displayName %q already exists", body.DisplayName)
+	} else if _, ok := err.(*db.OrgNotFoundError); !ok {
+		return err
+	}
+	users, err := s.Users.List(r.Context(), &db.UsersListOptions{Username: name, IncludeDeactivated: true})
+	if err != nil {
+		return err
+	}
+	if len(users) > 0 {
+		return scimErrorf(http.StatusConflict, "uniqueness", "the name %q of the group is already taken by a user", name)
+	}
+
+	org, err := s.Orgs.Create(r.Context(), name, &body.DisplayName)
+	if err != nil {
+		return err
+	}
+	if err := s.setOrgMembers(r.Context(), org.ID, members); err != nil {
+		return err
+	}
+
+	res, err := s.toSCIMGroup(r.Context(), org)
+	if err != nil {
+		return err
+	}
+	w.Header().Set("Location", res.Meta.Location)
+	return writeSCIM(w, http.StatusCreated, res)
+}
+
+// scimOrgName returns the organization name for a group with the given displayName.
+func scimOrgName(displayName string) (string, error) {
+	if displayName == "" {
+		return "", scimErrorf(http.StatusBadRequest, "invalidValue", "displayName is required")
+	}
+	name, err := auth.NormalizeUsername(displayName)
+	if err != nil {
+		return "", scimErrorf(http.StatusBadRequest, "invalidValue", "invalid displayName %q", displayName)
+	}
+	return name, nil
+}
+
+func (s *scimServer) getOrg(ctx context.Context, id int32) (*types.Org, error) {
+	org, err := s.Orgs.GetByID(ctx, id)
+	if err != nil {
+		if _, ok := err.(*db.OrgNotFoundError); ok {
+			return nil, scimErrorf(http.StatusNotFound, "", "group %d not found", id)
+		}
+		return nil, err
+	}
+	return org, nil
+}
+
+// getOrgByDisplayName returns the organization for the group with the given displayName, or nil
+// if there is none.
+func (s *scimServer) getOrgByDisplayName(ctx context.Context, displayName string) (*types.Org, error) {
+	name, err := auth.NormalizeUsername(displayName)
+	if err != nil {
+		return nil, nil
+	}
+	org, err := s.Orgs.GetByName(ctx, name)
+	if err != nil {
+		if _, ok := err.(*db.OrgNotFoundError); ok {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return org, nil
+}
+
+func (s *scimServer) setOrgDisplayName(ctx context.Context, org *types.Org, displayName string) error {
+	if displayName == "" || (org.DisplayName != nil && *org.DisplayName == displayName) {
+		return nil
+	}
+	_, err := s.Orgs.Update(ctx, org.ID, &displayName)
+	return err
+}
+
+func (s *scimServer) orgMemberIDs(ctx context.Context, orgID int32) ([]int32, error) {
+	members, err := s.OrgMembers.GetByOrgID(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]int32, 0, len(members))
+	for _, m := range members {
+		ids = append(ids, m.UserID)
+	}
+	return ids, nil
+}
+
+// setOrgMembers makes the given users the only members of the organization. Deactivated users are
+// not added, because they may not be organization members.
+func (s *scimServer) setOrgMembers(ctx context.Context, orgID int32, userIDs []int32) error {
+	desired := map[int32]bool{}
+	if len(userIDs) > 0 {
+		users, err := s.Users.List(ctx, &db.UsersListOptions{UserIDs: userIDs, IncludeDeactivated: true})
+		if err != nil {
+			return err
+		}
+		found := map[int32]bool{}
+		for _, u := range users {
+			found[u.ID] = true
+			if !u.Deactivated {
+				desired[u.ID] = true
+			}
+		}
+		for _, id := range userIDs {
+			if !found[id] {
+				return scimErrorf(http.StatusBadRequest, "invalidValue", "member %d not found", id)
+			}
+		}
+	}
+
+	current, err := s.orgMemberIDs(ctx, orgID)
+	if err != nil {
+		return err
+	}
+	for _, id := range current {
+		if desired[id] {
+			delete(desired, id)
+			continue
+		}
+		if err := s.OrgMembers.Remove(ctx, orgID, id); err != nil {
+			return err
+		}
+	}
+	add := make([]int32, 0, len(desired))
+	for id := range desired {
+		add = append(add, id)
+	}
+	sort.Slice(add, func(i, j int) bool { return add[i] < add[j] })
+	for _, id := range add {
+		if _, err := s.OrgMembers.Create(ctx, orgID, id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// scimMemberFilterPath matches the path of a PATCH operation that removes a single member, such
+// as `members[value eq "1"]`.
+var scimMemberFilterPath = regexp.MustCompile(`^(?i:members)\[\s*(?i:value)\s+(?i:eq)\s+"([^"]*)"\s*\]$`)
+
+func (s *scimServer) patchGroup(ctx context.Context, org *types.Org, ops []scimPatchOperation) error {
+	current, err := s.orgMemberIDs(ctx, org.ID)
+	if err != nil {
+		return err
+	}
+	members := map[int32]bool{}
+	for _, id := range current {
+		members[id] = true
+	}
+
+	for _, op := range ops {
+		opName := strings.ToLower(op.Op)
+		switch opName {
+		case "add", "replace":
+			attrs, err := op.attributes()
+			if err != nil {
+				return err
+			}
+			for path, value := range attrs {
+				switch path {
+				case "displayname":
+					var displayName string
+					if err := json.Unmarshal(value, &displayName); err != nil {
+						return scimErrorf(http.StatusBadRequest, "invalidValue", "invalid displayName: %s", err)
+					}
+					if err := s.setOrgDisplayName(ctx, org, displayName); err != nil {
+						return err
+					}
+				case "members":
+					ids, err := parseSCIMMembers(value)
+					if err != nil {
+						return err
+					}
+					if opName == "replace" {
+						members = map[int32]bool{}
+					}
+					for _, id := range ids {
+						members[id] = true
+					}
+				}
+			}
+		case "remove":
+			if m := scimMemberFilterPath.FindStringSubmatch(op.Path); m != nil {
+				id, err := strconv.ParseInt(m[1], 10, 32)
+				if err != nil {
+					return scimErrorf(http.StatusBadRequest, "invalidValue", "invalid member %q", m[1])
+				}
+				delete(members, int32(id))
+				continue
+			}
+			if strings.ToLower(op.Path) != "members" {
+				return scimErrorf(http.StatusBadRequest, "invalidPath", "removing %q is not supported", op.Path)
+			}
+			if len(op.Value) == 0 {
+				members = map[int32]bool{}
+				continue
+			}
+			ids, err := parseSCIMMembers(op.Value)
+			if err != nil {
+				return err
+			}
+			for _, id := range ids {
+				delete(members, id)
+			}
+		default:
+			return scimErrorf(http.StatusBadRequest, "invalidSyntax", "unsupported operation %q", op.Op)
+		}
+	}
+
+	ids := make([]int32, 0, len(members))
+	for id := range members {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return s.setOrgMembers(ctx, org.ID, ids)
+}
+
+func parseSCIMMembers(value json.RawMessage) ([]int32, error) {
+	var members []scimReference
+	if err := json.Unmarshal(value, &members); err != nil {
+		return nil, scimErrorf(http.StatusBadRequest, "invalidValue", "invalid members: %s", err)
+	}
+	return scimMemberIDs(members)
+}
+
+func scimMemberIDs(members []scimReference) ([]int32, error) {
+	ids := make([]int32, 0, len(members))
+	for _, m := range members {
+		id, err := strconv.ParseInt(m.Value, 10, 32)
+		if err != nil {
+			return nil, scimErrorf(http.StatusBadRequest, "invalidValue", "invalid member %q", m.Value)
+		}
+		ids = append(ids, int32(id))
+	}
+	return ids, nil
+}
+
+func (s *scimServer) toSCIMGroup(ctx context.Context, org *types.Org) (*scimGroup, error) {
+	ids, err := s.orgMemberIDs(ctx, org.ID)
+	if err != nil {
+		return nil, err
+	}
+	g := &scimGroup{
+		Schemas:     []string{scimSchemaGroup},
+		ID:          strconv.Itoa(int(org.ID)),
+		DisplayName: org.Name,
+		Members:     []scimReference{},
+		Meta: &scimMeta{
+			ResourceType: "Group",
+			Created:      org.CreatedAt,
+			LastModified: org.UpdatedAt,
+			Location:     scimLocation("Groups", org.ID),
+		},
+	}
+	if org.DisplayName != nil && *org.DisplayName != "" {
+		g.DisplayName = *org.DisplayName
+	}
+	for _, id := range ids {
+		g.Members = append(g.Members, scimReference{Value: strconv.Itoa(int(id))})
+	}
+	return g, nil
+}
@@ -0,0 +1,501 @@
+package httpapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/gorilla/mux"
+	"github.com/lib/pq"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/internal/httpapi/router"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
+	"github.com/sourcegraph/sourcegraph/internal/conf"
+	"github.com/sourcegraph/sourcegraph/schema"
+)
+
+const testSCIMToken = "0123456789abcdef0123456789abcdef"
+
+func TestSCIMAuthentication(t *testing.T) {
+	h := newSCIMTestHandler(newFakeSCIMDB().server())
+
+	tests := []struct {
+		name          string
+		config        *schema.AuthScim
+		authorization string
+		wantStatus    int
+	}{
+		{name: "not enabled", authorization: "Bearer " + testSCIMToken, wantStatus: http.StatusNotFound},
+		{name: "no token", config: &schema.AuthScim{BearerToken: testSCIMToken}, wantStatus: http.StatusUnauthorized},
+		{name: "wrong token", config: &schema.AuthScim{BearerToken: testSCIMToken}, authorization: "Bearer x" + testSCIMToken[1:], wantStatus: http.StatusUnauthorized},
+		{name: "access token", config: &schema.AuthScim{BearerToken: testSCIMToken}, authorization: "token " + testSCIMToken, wantStatus: http.StatusUnauthorized},
+		{name: "valid token", config: &schema.AuthScim{BearerToken: testSCIMToken}, authorization: "bearer " + testSCIMToken, wantStatus: http.StatusOK},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			conf.Mock(&conf.Unified{SiteConfiguration: schema.SiteConfiguration{AuthScim: test.config}})
+			defer conf.Mock(nil)
+
+			req := httptest.NewRequest("GET", "/.api/scim/v2/Users", nil)
+			if test.authorization != "" {
+				req.Header.Set("Authorization", test.authorization)
+			}
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+			if rec.Code != test.wantStatus {
+				t.Errorf("got status %d, want %d (body: %s)", rec.Code, test.wantStatus, rec.Body)
+			}
+		})
+	}
+}
+
+func TestSCIMUsers(t *testing.T) {
+	conf.Mock(&conf.Unified{SiteConfiguration: schema.SiteConfiguration{AuthScim: &schema.AuthScim{BearerToken: testSCIMToken}}})
+	defer conf.Mock(nil)
+
+	fake := newFakeSCIMDB()
+	h := newSCIMTestHandler(fake.server())
+
+	var user scimUser
+	doSCIM(t, h, "POST", "/Users", `{
+		"schemas": ["urn:ietf:params:scim:schemas:core:2.0:User"],
+		"userName": "alice@example.com",
+		"name": {"formatted": "Alice Smith"},
+		"emails": [{"value": "alice@work.example.com"}, {"value": "alice@example.com", "primary": true}],
+		"active": true
+	}`, http.StatusCreated, &user)
+	if user.UserName != "alice" || user.DisplayName != "Alice Smith" {
+		t.Errorf("got userName %q and displayName %q", user.UserName, user.DisplayName)
+	}
+	if want := []string{"alice@example.com", "alice@work.example.com"}; !cmp.Equal(fake.verifiedEmails(1), want) {
+		t.Errorf("got verified emails %v, want %v", fake.verifiedEmails(1), want)
+	}
+
+	t.Run("create conflict", func(t *testing.T) {
+		doSCIM(t, h, "POST", "/Users", `{"userName": "bob", "emails": [{"value": "bob@example.com"}, {"value": "alice@work.example.com"}]}`, http.StatusConflict, nil)
+		for _, u := range fake.users {
+			if u.Username == "bob" {
+				t.Errorf("got user %+v after a conflict, want it deleted", u)
+			}
+		}
+	})
+
+	t.Run("filter", func(t *testing.T) {
+		var list scimListResponse
+		doSCIM(t, h, "GET", `/Users?filter=userName+eq+"alice@example.com"`, "", http.StatusOK, &list)
+		if list.TotalResults != 1 || len(list.Resources) != 1 {
+			t.Errorf("got %d results, want 1", list.TotalResults)
+		}
+		doSCIM(t, h, "GET", `/Users?filter=userName+eq+"bob"`, "", http.StatusOK, &list)
+		if list.TotalResults != 0 || len(list.Resources) != 0 {
+			t.Errorf("got %d results, want 0", list.TotalResults)
+		}
+		doSCIM(t, h, "GET", `/Users?filter=emails+co+"example"`, "", http.StatusBadRequest, nil)
+	})
+
+	t.Run("deactivate and reactivate", func(t *testing.T) {
+		doSCIM(t, h, "PATCH", "/Users/1", `{"Operations": [{"op": "Replace", "path": "active", "value": "False"}]}`, http.StatusOK, &user)
+		if user.Active == nil || *user.Active {
+			t.Error("user is active after deactivation")
+		}
+		if !fake.users[1].Deactivated {
+			t.Error("user was not deactivated")
+		}
+
+		// Deactivated users are still listed, so the identity provider can reactivate them.
+		var list scimListResponse
+		doSCIM(t, h, "GET", "/Users", "", http.StatusOK, &list)
+		if list.TotalResults != 1 {
+			t.Errorf("got %d results, want 1", list.TotalResults)
+		}
+
+		doSCIM(t, h, "PATCH", "/Users/1", `{"Operations": [{"op": "replace", "value": {"active": true, "displayName": "Alice Jones"}}]}`, http.StatusOK, &user)
+		if fake.users[1].Deactivated || fake.users[1].DisplayName != "Alice Jones" {
+			t.Errorf("got user %+v, want reactivated user with new display name", fake.users[1])
+		}
+	})
+
+	t.Run("replace", func(t *testing.T) {
+		doSCIM(t, h, "PUT", "/Users/1", `{
+			"userName": "alice.jones",
+			"displayName": "Alice Jones",
+			"emails": [{"value": "alice@jones.example.com", "primary": true}]
+		}`, http.StatusOK, &user)
+		if fake.users[1].Username != "alice.jones" {
+			t.Errorf("got username %q, want %q", fake.users[1].Username, "alice.jones")
+		}
+		if want := []string{"alice@jones.example.com"}; !cmp.Equal(fake.verifiedEmails(1), want) {
+			t.Errorf("got verified emails %v, want %v", fake.verifiedEmails(1), want)
+		}
+	})
+
+	t.Run("site admin", func(t *testing.T) {
+		fake.users[1].SiteAdmin = true
+		defer func() { fake.users[1].SiteAdmin = false }()
+
+		doSCIM(t, h, "GET", "/Users/1", "", http.StatusOK, &user)
+		doSCIM(t, h, "PUT", "/Users/1", `{"userName": "mallory", "emails": [{"value": "mallory@example.com"}]}`, http.StatusForbidden, nil)
+		doSCIM(t, h, "PATCH", "/Users/1", `{"Operations": [{"op": "replace", "path": "active", "value": false}]}`, http.StatusForbidden, nil)
+		doSCIM(t, h, "DELETE", "/Users/1", "", http.StatusForbidden, nil)
+		if u := fake.users[1]; u.Username != "alice.jones" || u.Deactivated {
+			t.Errorf("got site admin %+v, want it unchanged", u)
+		}
+		if want := []string{"alice@jones.example.com"}; !cmp.Equal(fake.verifiedEmails(1), want) {
+			t.Errorf("got verified emails %v, want %v", fake.verifiedEmails(1), want)
+		}
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		doSCIM(t, h, "DELETE", "/Users/1", "", http.StatusNoContent, nil)
+		doSCIM(t, h, "GET", "/Users/1", "", http.StatusNotFound, nil)
+	})
+}
+
+func TestSCIMGroups(t *testing.T) {
+	conf.Mock(&conf.Unified{SiteConfiguration: schema.SiteConfiguration{AuthScim: &schema.AuthScim{BearerToken: testSCIMToken}}})
+	defer conf.Mock(nil)
+
+	fake := newFakeSCIMDB()
+	h := newSCIMTestHandler(fake.server())
+	for _, userName := range []string{"alice", "bob", "carol"} {
+		doSCIM(t, h, "POST", "/Users", fmt.Sprintf(`{"userName": %q}`, userName), http.StatusCreated, nil)
+	}
+
+	var group scimGroup
+	doSCIM(t, h, "POST", "/Groups", `{"displayName": "Engineering", "members": [{"value": "1"}, {"value": "2"}]}`, http.StatusCreated, &group)
+	if group.DisplayName != "Engineering" || fake.orgs[1].Name != "Engineering" {
+		t.Errorf("got group %+v", group)
+	}
+	if want := []int32{1, 2}; !cmp.Equal(fake.memberIDs(1), want) {
+		t.Errorf("got members %v, want %v", fake.memberIDs(1), want)
+	}
+
+	doSCIM(t, h, "POST", "/Groups", `{"displayName": "Engineering"}`, http.StatusConflict, nil)
+	doSCIM(t, h, "POST", "/Groups", `{"displayName": "alice"}`, http.StatusConflict, nil)
+	doSCIM(t, h, "POST", "/Groups", `{"displayName": "Sales", "members": [{"value": "42"}]}`, http.StatusBadRequest, nil)
+
+	var list scimListResponse
+	doSCIM(t, h, "GET", `/Groups?filter=displayName+eq+"Engineering"`, "", http.StatusOK, &list)
+	if list.TotalResults != 1 {
+		t.Errorf("got %d results, want 1", list.TotalResults)
+	}
+
+	doSCIM(t, h, "PATCH", "/Groups/1", `{"Operations": [
+		{"op": "remove", "path": "members[value eq \"1\"]"},
+		{"op": "add", "path": "members", "value": [{"value": "3"}]}
+	]}`, http.StatusOK, &group)
+	if want := []int32{2, 3}; !cmp.Equal(fake.memberIDs(1), want) {
+		t.Errorf("got members %v, want %v", fake.memberIDs(1), want)
+	}
+
+	// Deactivating a user removes them from their organizations.
+	doSCIM(t, h, "PATCH", "/Users/2", `{"Operations": [{"op": "replace", "path": "active", "value": false}]}`, http.StatusOK, nil)
+	if want := []int32{3}; !cmp.Equal(fake.memberIDs(1), want) {
+		t.Errorf("got members %v, want %v", fake.memberIDs(1), want)
+	}
+
+	doSCIM(t, h, "PUT", "/Groups/1", `{"displayName": "Platform", "members": [{"value": "1"}]}`, http.StatusOK, &group)
+	if group.DisplayName != "Platform" {
+		t.Errorf("got displayName %q, want %q", group.DisplayName, "Platform")
+	}
+	if want := []int32{1}; !cmp.Equal(fake.memberIDs(1), want) {
+		t.Errorf("got members %v, want %v", fake.memberIDs(1), want)
+	}
+
+	doSCIM(t, h, "DELETE", "/Groups/1", "", http.StatusNoContent, nil)
+	doSCIM(t, h, "GET", "/Groups/1", "", http.StatusNotFound, nil)
+}
+
+func newSCIMTestHandler(s *scimServer) http.Handler {
+	m := router.New(mux.NewRouter().PathPrefix("/.api/").Subrouter())
+	m.Get(router.SCIMUsers).Handler(s.handler(s.serveUsers))
+	m.Get(router.SCIMUser).Handler(s.handler(s.serveUser))
+	m.Get(router.SCIMGroups).Handler(s.handler(s.serveGroups))
+	m.Get(router.SCIMGroup).Handler(s.handler(s.serveGroup))
+	return m
+}
+
+func doSCIM(t *testing.T, h http.Handler, method, path, body string, wantStatus int, v interface{}) {
+	t.Helper()
+
+	req := httptest.NewRequest(method, "/.api/scim/v2"+path, strings.NewReader(body))
+	req.Header.Set("Authorization", "Bearer "+testSCIMToken)
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != wantStatus {
+		t.Fatalf("%s %s: got status %d, want %d (body: %s)", method, path, rec.Code, wantStatus, rec.Body)
+	}
+	if v != nil {
+		if err := json.Unmarshal(rec.Body.Bytes(), v); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// fakeSCIMDB is an in-memory implementation of the stores used by scimServer.
+type fakeSCIMDB struct {
+	users                 map[int32]*types.User
+	emails                map[int32][]*db.UserEmail
+	orgs                  map[int32]*types.Org
+	members               map[int32]map[int32]bool // org ID -> user IDs
+	nextUserID, nextOrgID int32
+}
+
+func newFakeSCIMDB() *fakeSCIMDB {
+	return &fakeSCIMDB{
+		users:   map[int32]*types.User{},
+		emails:  map[int32][]*db.UserEmail{},
+		orgs:    map[int32]*types.Org{},
+		members: map[int32]map[int32]bool{},
+	}
+}
+
+func (f *fakeSCIMDB) server() *scimServer {
+	return &scimServer{
+		Users:      fakeSCIMUsers{f},
+		UserEmails: fakeSCIMUserEmails{f},
+		Orgs:       fakeSCIMOrgs{f},
+		OrgMembers: fakeSCIMOrgMembers{f},
+	}
+}
+
+func (f *fakeSCIMDB) verifiedEmails(userID int32) []string {
+	var emails []string
+	for _, e := range f.emails[userID] {
+		if e.VerifiedAt != nil {
+			emails = append(emails, e.Email)
+		}
+	}
+	return emails
+}
+
+func (f *fakeSCIMDB) memberIDs(orgID int32) []int32 {
+	var ids []int32
+	for id := range f.members[orgID] {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
+
+type fakeSCIMUsers struct{ *fakeSCIMDB }
+
+func (f fakeSCIMUsers) Create(ctx context.Context, info db.NewUser) (*types.User, error) {
+	f.nextUserID++
+	user := &types.User{ID: f.nextUserID, Username: info.Username, DisplayName: info.DisplayName, CreatedAt: time.Now(), UpdatedAt: time.Now()}
+	f.users[user.ID] = user
+	if info.Email != "" {
+		now := time.Now()
+		f.emails[user.ID] = []*db.UserEmail{{UserID: user.ID, Email: info.Email, VerifiedAt: &now}}
+	}
+	return user, nil
+}
+
+func (f fakeSCIMUsers) Update(ctx context.Context, id int32, update db.UserUpdate) error {
+	user := f.users[id]
+	if update.Username != "" {
+		user.Username = update.Username
+	}
+	if update.DisplayName != nil {
+		user.DisplayName = *update.DisplayName
+	}
+	return nil
+}
+
+func (f fakeSCIMUsers) Delete(ctx context.Context, id int32) error {
+	if f.users[id].Deactivated {
+		return errors.New("user not found")
+	}
+	delete(f.users, id)
+	return nil
+}
+
+func (f fakeSCIMUsers) HardDelete(ctx context.Context, id int32) error {
+	delete(f.users, id)
+	delete(f.emails, id)
+	return nil
+}
+
+func (f fakeSCIMUsers) Deactivate(ctx context.Context, id int32) error {
+	f.users[id].Deactivated = true
+	for _, members := range f.members {
+		delete(members, id)
+	}
+	return nil
+}
+
+func (f fakeSCIMUsers) Reactivate(ctx context.Context, id int32) error {
+	f.users[id].Deactivated = false
+	return nil
+}
+
+func (f fakeSCIMUsers) List(ctx context.Context, opt *db.UsersListOptions) ([]*types.User, error) {
+	var users []*types.User
+	for _, u := range f.users {
+		if u.Deactivated && !opt.IncludeDeactivated {
+			continue
+		}
+		if opt.Username != "" && u.Username != opt.Username {
+			continue
+		}
+		if opt.UserIDs != nil {
+			found := false
+			for _, id := range opt.UserIDs {
+				found = found || id == u.ID
+			}
+			if !found {
+				continue
+			}
+		}
+		users = append(users, u)
+	}
+	sort.Slice(users, func(i, j int) bool { return users[i].ID < users[j].ID })
+	if opt.LimitOffset != nil {
+		if opt.Offset > len(users) {
+			return nil, nil
+		}
+		users = users[opt.Offset:]
+		if opt.Limit < len(users) {
+			users = users[:opt.Limit]
+		}
+	}
+	return users, nil
+}
+
+func (f fakeSCIMUsers) Count(ctx context.Context, opt *db.UsersListOptions) (int, error) {
+	o := *opt
+	o.LimitOffset = nil
+	users, err := f.List(ctx, &o)
+	return len(users), err
+}
+
+type fakeSCIMUserEmails struct{ *fakeSCIMDB }
+
+func (f fakeSCIMUserEmails) ListByUser(ctx context.Context, opt db.UserEmailsListOptions) ([]*db.UserEmail, error) {
+	return f.emails[opt.UserID], nil
+}
+
+func (f fakeSCIMUserEmails) Add(ctx context.Context, userID int32, email string, verificationCode *string) error {
+	f.emails[userID] = append(f.emails[userID], &db.UserEmail{UserID: userID, Email: email, VerificationCode: verificationCode})
+	return nil
+}
+
+func (f fakeSCIMUserEmails) Remove(ctx context.Context, userID int32, email string) error {
+	emails := f.emails[userID][:0]
+	for _, e := range f.emails[userID] {
+		if e.Email != email {
+			emails = append(emails, e)
+		}
+	}
+	f.emails[userID] = emails
+	return nil
+}
+
+func (f fakeSCIMUserEmails) SetVerified(ctx context.Context, userID int32, email string, verified bool) error {
+	for id, emails := range f.emails {
+		for _, e := range emails {
+			if id != userID && e.Email == email && e.VerifiedAt != nil {
+				return &pq.Error{Constraint: "user_emails_unique_verified_email"}
+			}
+		}
+	}
+	for _, e := range f.emails[userID] {
+		if e.Email == email {
+			now := time.Now()
+			e.VerifiedAt = &now
+			return nil
+		}
+	}
+	return errors.New("user email not found")
+}
+
+type fakeSCIMOrgs struct{ *fakeSCIMDB }
+
+func (f fakeSCIMOrgs) Create(ctx context.Context, name string, displayName *string) (*types.Org, error) {
+	f.nextOrgID++
+	org := &types.Org{ID: f.nextOrgID, Name: name, DisplayName: displayName}
+	f.orgs[org.ID] = org
+	f.members[org.ID] = map[int32]bool{}
+	return org, nil
+}
+
+func (f fakeSCIMOrgs) GetByID(ctx context.Context, id int32) (*types.Org, error) {
+	if org, ok := f.orgs[id]; ok {
+		return org, nil
+	}
+	return nil, &db.OrgNotFoundError{Message: fmt.Sprintf("id %d", id)}
+}
+
+func (f fakeSCIMOrgs) GetByName(ctx context.Context, name string) (*types.Org, error) {
+	for _, org := range f.orgs {
+		if org.Name == name {
+			return org, nil
+		}
+	}
+	return nil, &db.OrgNotFoundError{Message: fmt.Sprintf("name %s", name)}
+}
+
+func (f fakeSCIMOrgs) GetByUserID(ctx context.Context, userID int32) ([]*types.Org, error) {
+	var orgs []*types.Org
+	for id, members := range f.members {
+		if members[userID] {
+			orgs = append(orgs, f.orgs[id])
+		}
+	}
+	return orgs, nil
+}
+
+func (f fakeSCIMOrgs) List(ctx context.Context, opt *db.OrgsListOptions) ([]*types.Org, error) {
+	var orgs []*types.Org
+	for _, org := range f.orgs {
+		orgs = append(orgs, org)
+	}
+	sort.Slice(orgs, func(i, j int) bool { return orgs[i].ID < orgs[j].ID })
+	return orgs, nil
+}
+
+func (f fakeSCIMOrgs) Count(ctx context.Context, opt db.OrgsListOptions) (int, error) {
+	return len(f.orgs), nil
+}
+
+func (f fakeSCIMOrgs) Update(ctx context.Context, id int32, displayName *string) (*types.Org, error) {
+	f.orgs[id].DisplayName = displayName
+	return f.orgs[id], nil
+}
+
+func (f fakeSCIMOrgs) Delete(ctx context.Context, id int32) error {
+	delete(f.orgs, id)
+	delete(f.members, id)
+	return nil
+}
+
+type fakeSCIMOrgMembers struct{ *fakeSCIMDB }
+
+func (f fakeSCIMOrgMembers) Create(ctx context.Context, orgID, userID int32) (*types.OrgMembership, error) {
+	if f.members[orgID][userID] {
+		return nil, errors.New("user is already a member of the organization")
+	}
+	f.members[orgID][userID] = true
+	return &types.OrgMembership{OrgID: orgID, UserID: userID}, nil
+}
+
+func (f fakeSCIMOrgMembers) Remove(ctx context.Context, orgID, userID int32) error {
+	delete(f.members[orgID], userID)
+	return nil
+}
+
+func (f fakeSCIMOrgMembers) GetByOrgID(ctx context.Context, orgID int32) ([]*types.OrgMembership, error) {
+	var members []*types.OrgMembership
+	for _, userID := range f.memberIDs(orgID) {
+		members = append(members, &types.OrgMembership{OrgID: orgID, UserID: userID})
+	}
+	return members, nil
+}
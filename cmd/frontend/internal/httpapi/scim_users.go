@@ -0,0 +1,547 @@
+package httpapi
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/lib/pq"
+	"github.com/pkg/errors"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/auth"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
+)
+
+// scimUser is the SCIM representation of a Sourcegraph user.
+type scimUser struct {
+	Schemas     []string        `json:"schemas"`
+	ID          string          `json:"id,omitempty"`
+	UserName    string          `json:"userName"`
+	DisplayName string          `json:"displayName,omitempty"`
+	Name        *scimName       `json:"name,omitempty"`
+	Emails      []scimEmail     `json:"emails,omitempty"`
+	Active      *scimBool       `json:"active,omitempty"`
+	Groups      []scimReference `json:"groups,omitempty"`
+	Meta        *scimMeta       `json:"meta,omitempty"`
+}
+
+type scimName struct {
+	Formatted string `json:"formatted,omitempty"`
+}
+
+type scimEmail struct {
+	Value   string `json:"value"`
+	Primary bool   `json:"primary,omitempty"`
+}
+
+// scimBool is a boolean attribute that may also be given as a string (see parseSCIMBool).
+type scimBool bool
+
+func (b *scimBool) UnmarshalJSON(data []byte) error {
+	v, err := parseSCIMBool(data)
+	*b = scimBool(v)
+	return err
+}
+
+// scimUserState is the state of a user that a SCIM client can change.
+type scimUserState struct {
+	userName    string
+	displayName string
+	emails      []string // if nil, the user's emails are unchanged
+	active      bool
+}
+
+// state returns the desired state of the user described by a POST or PUT request body. The
+// attributes that are absent from the body are taken from the given current state.
+func (u *scimUser) state(current scimUserState) (scimUserState, error) {
+	s := current
+	if u.UserName == "" {
+		return s, scimErrorf(http.StatusBadRequest, "invalidValue", "userName is required")
+	}
+	userName, err := normalizeSCIMUserName(u.UserName)
+	if err != nil {
+		return s, err
+	}
+	s.userName = userName
+	s.displayName = u.DisplayName
+	if s.displayName == "" && u.Name != nil {
+		s.displayName = u.Name.Formatted
+	}
+	if u.Emails != nil {
+		s.emails = scimEmailValues(u.Emails)
+	}
+	if u.Active != nil {
+		s.active = bool(*u.Active)
+	}
+	return s, nil
+}
+
+func normalizeSCIMUserName(userName string) (string, error) {
+	normalized, err := auth.NormalizeUsername(userName)
+	if err != nil {
+		return "", scimErrorf(http.StatusBadRequest, "invalidValue", "invalid userName %q", userName)
+	}
+	return normalized, nil
+}
+
+// scimEmailValues returns the email addresses, with the primary email address first.
+func scimEmailValues(emails []scimEmail) []string {
+	values := make([]string, 0, len(emails))
+	for _, e := range emails {
+		if e.Value == "" {
+			continue
+		}
+		if e.Primary {
+			values = append([]string{e.Value}, values...)
+		} else {
+			values = append(values, e.Value)
+		}
+	}
+	return values
+}
+
+func (s *scimServer) serveUsers(w http.ResponseWriter, r *http.Request) error {
+	switch r.Method {
+	case "GET":
+		return s.listUsers(w, r)
+	case "POST":
+		return s.createUser(w, r)
+	}
+	return scimErrorf(http.StatusMethodNotAllowed, "", "method %s not allowed", r.Method)
+}
+
+func (s *scimServer) serveUser(w http.ResponseWriter, r *http.Request) error {
+	id, err := scimResourceID(r)
+	if err != nil {
+		return err
+	}
+	user, err := s.getUser(r.Context(), id)
+	if err != nil {
+		return err
+	}
+
+	// 🚨 SECURITY: The SCIM token must not be able to take over or lock out site admins
+	// (e.g. by adding a verified email and resetting the password), so they are read-only
+	// here and must be managed in Sourcegraph.
+	if user.SiteAdmin && r.Method != "GET" {
+		return scimErrorf(http.StatusForbidden, "", "site admins can't be modified with SCIM")
+	}
+
+	switch r.Method {
+	case "GET":
+	case "PUT":
+		var body scimUser
+		if err := readSCIM(r, &body); err != nil {
+			return err
+		}
+		current, err := s.userState(r.Context(), user)
+		if err != nil {
+			return err
+		}
+		desired, err := body.state(current)
+		if err != nil {
+			return err
+		}
+		if err := s.updateUser(r.Context(), user, current, desired); err != nil {
+			return err
+		}
+	case "PATCH":
+		var body scimPatchRequest
+		if err := readSCIM(r, &body); err != nil {
+			return err
+		}
+		current, err := s.userState(r.Context(), user)
+		if err != nil {
+			return err
+		}
+		desired, err := patchSCIMUser(current, body.Operations)
+		if err != nil {
+			return err
+		}
+		if err := s.updateUser(r.Context(), user, current, desired); err != nil {
+			return err
+		}
+	case "DELETE":
+		// Deactivated users must be reactivated before they can be deleted.
+		if user.Deactivated {
+			if err := s.Users.Reactivate(r.Context(), user.ID); err != nil {
+				return err
+			}
+		}
+		if err := s.Users.Delete(r.Context(), user.ID); err != nil {
+			return err
+		}
+		w.WriteHeader(http.StatusNoContent)
+		return nil
+	default:
+		return scimErrorf(http.StatusMethodNotAllowed, "", "method %s not allowed", r.Method)
+	}
+
+	if r.Method != "GET" {
+		if user, err = s.getUser(r.Context(), id); err != nil {
+			return err
+		}
+	}
+	res, err := s.toSCIMUser(r.Context(), user)
+	if err != nil {
+		return err
+	}
+	return writeSCIM(w, http.StatusOK, res)
+}
+
+func (s *scimServer) listUsers(w http.ResponseWriter, r *http.Request) error {
+	params, err := parseSCIMListParams(r)
+	if err != nil {
+		return err
+	}
+	opt := db.UsersListOptions{IncludeDeactivated: true}
+	switch strings.ToLower(params.filterAttribute) {
+	case "":
+	case "username":
+		userName, err := auth.NormalizeUsername(params.filterValue)
+		if err != nil {
+			// No user can have an invalid username.
+			return writeSCIM(w, http.StatusOK, &scimListResponse{Schemas: []string{scimSchemaListResponse}, StartIndex: params.startIndex, Resources: []interface{}{}})
+		}
+		opt.Username = userName
+	default:
+		return scimErrorf(http.StatusBadRequest, "invalidFilter", "filtering by %q is not supported", params.filterAttribute)
+	}
+
+	total, err := s.Users.Count(r.Context(), &opt)
+	if err != nil {
+		return err
+	}
+	res := &scimListResponse{
+		Schemas:      []string{scimSchemaListResponse},
+		TotalResults: total,
+		StartIndex:   params.startIndex,
+		Resources:    []interface{}{},
+	}
+	if params.count > 0 {
+		opt.LimitOffset = params.limitOffset()
+		users, err := s.Users.List(r.Context(), &opt)
+		if err != nil {
+			return err
+		}
+		for _, user := range users {
+			u, err := s.toSCIMUser(r.Context(), user)
+			if err != nil {
+				return err
+			}
+			res.Resources = append(res.Resources, u)
+		}
+	}
+	res.ItemsPerPage = len(res.Resources)
+	return writeSCIM(w, http.StatusOK, res)
+}
+
+func (s *scimServer) createUser(w http.ResponseWriter, r *http.Request) (err error) {
+	var body scimUser
+	if err := readSCIM(r, &body); err != nil {
+		return err
+	}
+	desired, err := body.state(scimUserState{active: true})
+	if err != nil {
+		return err
+	}
+
+	newUser := db.NewUser{
+		Username:    desired.userName,
+		DisplayName: desired.displayName,
+		// 🚨 SECURITY: The identity provider is trusted to have verified the user's email
+		// addresses.
+		EmailIsVerified: true,
+	}
+	if len(desired.emails) > 0 {
+		newUser.Email = desired.emails[0]
+	}
+	user, err := s.Users.Create(r.Context(), newUser)
+	if err != nil {
+		if db.IsUsernameExists(err) {
+			return scimErrorf(http.StatusConflict, "uniqueness", "a user with the userName %q already exists", desired.userName)
+		}
+		if db.IsEmailExists(err) {
+			return scimErrorf(http.StatusConflict, "uniqueness", "a user with the email %q already exists", newUser.Email)
+		}
+		return err
+	}
+
+	// The user is created before its other attributes are set, so it must be deleted again if
+	// setting them fails (e.g. because one of the emails belongs to another user). Otherwise a
+	// retry of the request would conflict with the partially created user.
+	defer func() {
+		if err != nil {
+			if err2 := s.Users.HardDelete(r.Context(), user.ID); err2 != nil {
+				err = errors.Wrap(err, err2.Error())
+			}
+		}
+	}()
+
+	current := scimUserState{userName: user.Username, displayName: user.DisplayName, active: true}
+	if newUser.Email != "" {
+		current.emails = []string{newUser.Email}
+	}
+	if err := s.updateUser(r.Context(), user, current, desired); err != nil {
+		return err
+	}
+
+	user, err = s.getUser(r.Context(), user.ID)
+	if err != nil {
+		return err
+	}
+	res, err := s.toSCIMUser(r.Context(), user)
+	if err != nil {
+		return err
+	}
+	w.Header().Set("Location", res.Meta.Location)
+	return writeSCIM(w, http.StatusCreated, res)
+}
+
+// getUser returns the user (which may be deactivated) with the given ID.
+func (s *scimServer) getUser(ctx context.Context, id int32) (*types.User, error) {
+	users, err := s.Users.List(ctx, &db.UsersListOptions{UserIDs: []int32{id}, IncludeDeactivated: true})
+	if err != nil {
+		return nil, err
+	}
+	if len(users) == 0 {
+		return nil, scimErrorf(http.StatusNotFound, "", "user %d not found", id)
+	}
+	return users[0], nil
+}
+
+func (s *scimServer) userState(ctx context.Context, user *types.User) (scimUserState, error) {
+	emails, err := s.UserEmails.ListByUser(ctx, db.UserEmailsListOptions{UserID: user.ID})
+	if err != nil {
+		return scimUserState{}, err
+	}
+	state := scimUserState{
+		userName:    user.Username,
+		displayName: user.DisplayName,
+		emails:      []string{},
+		active:      !user.Deactivated,
+	}
+	for _, e := range emails {
+		state.emails = append(state.emails, e.Email)
+	}
+	return state, nil
+}
+
+// updateUser changes the user from the current to the desired state.
+func (s *scimServer) updateUser(ctx context.Context, user *types.User, current, desired scimUserState) error {
+	// Deactivated users must be reactivated before they can be updated, and must be deactivated
+	// after they are updated (because deactivation revokes their access).
+	if desired.active && !current.active {
+		if err := s.Users.Reactivate(ctx, user.ID); err != nil {
+			return err
+		}
+	}
+
+	var update db.UserUpdate
+	if desired.userName != current.userName {
+		update.Username = desired.userName
+	}
+	if desired.displayName != current.displayName {
+		update.DisplayName = &desired.displayName
+	}
+	if update != (db.UserUpdate{}) {
+		if err := s.Users.Update(ctx, user.ID, update); err != nil {
+			if db.IsUsernameExists(err) {
+				return scimErrorf(http.StatusConflict, "uniqueness", "a user with the userName %q already exists", desired.userName)
+			}
+			return err
+		}
+	}
+
+	if desired.emails != nil {
+		if err := s.setUserEmails(ctx, user.ID, desired.emails); err != nil {
+			return err
+		}
+	}
+
+	if !desired.active && current.active {
+		if err := s.Users.Deactivate(ctx, user.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// setUserEmails makes the given email addresses the user's only (verified) email addresses.
+func (s *scimServer) setUserEmails(ctx context.Context, userID int32, emails []string) error {
+	current, err := s.UserEmails.ListByUser(ctx, db.UserEmailsListOptions{UserID: userID})
+	if err != nil {
+		return err
+	}
+
+	indexOf := func(email string) int {
+		for i, e := range current {
+			// Email addresses are case-insensitive (citext) in the DB.
+			if strings.EqualFold(e.Email, email) {
+				return i
+			}
+		}
+		return -1
+	}
+	keep := make([]bool, len(current))
+	seen := map[string]bool{}
+	for _, email := range emails {
+		if seen[strings.ToLower(email)] {
+			continue
+		}
+		seen[strings.ToLower(email)] = true
+
+		i := indexOf(email)
+		if i >= 0 {
+			keep[i] = true
+			if current[i].VerifiedAt != nil {
+				continue
+			}
+		} else if err := s.UserEmails.Add(ctx, userID, email, nil); err != nil {
+			return err
+		}
+		if err := s.UserEmails.SetVerified(ctx, userID, email, true); err != nil {
+			if i < 0 {
+				if err2 := s.UserEmails.Remove(ctx, userID, email); err2 != nil {
+					return errors.Wrap(err, err2.Error())
+				}
+			}
+			if e, ok := errors.Cause(err).(*pq.Error); ok && e.Constraint == "user_emails_unique_verified_email" {
+				return scimErrorf(http.StatusConflict, "uniqueness", "a user with the email %q already exists", email)
+			}
+			return err
+		}
+	}
+
+	for i, e := range current {
+		if !keep[i] {
+			if err := s.UserEmails.Remove(ctx, userID, e.Email); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (s *scimServer) toSCIMUser(ctx context.Context, user *types.User) (*scimUser, error) {
+	emails, err := s.UserEmails.ListByUser(ctx, db.UserEmailsListOptions{UserID: user.ID})
+	if err != nil {
+		return nil, err
+	}
+	orgs, err := s.Orgs.GetByUserID(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	active := scimBool(!user.Deactivated)
+	u := &scimUser{
+		Schemas:     []string{scimSchemaUser},
+		ID:          strconv.Itoa(int(user.ID)),
+		UserName:    user.Username,
+		DisplayName: user.DisplayName,
+		Active:      &active,
+		Meta: &scimMeta{
+			ResourceType: "User",
+			Created:      user.CreatedAt,
+			LastModified: user.UpdatedAt,
+			Location:     scimLocation("Users", user.ID),
+		},
+	}
+	if user.DisplayName != "" {
+		u.Name = &scimName{Formatted: user.DisplayName}
+	}
+	// The primary email address is the user's first verified email address (see
+	// (*db.userEmails).GetPrimaryEmail).
+	hasPrimary := false
+	for _, e := range emails {
+		primary := !hasPrimary && e.VerifiedAt != nil
+		hasPrimary = hasPrimary || primary
+		u.Emails = append(u.Emails, scimEmail{Value: e.Email, Primary: primary})
+	}
+	for _, org := range orgs {
+		u.Groups = append(u.Groups, scimReference{Value: strconv.Itoa(int(org.ID)), Display: org.Name})
+	}
+	return u, nil
+}
+
+// patchSCIMUser applies the PATCH operations to the user's state.
+func patchSCIMUser(state scimUserState, ops []scimPatchOperation) (scimUserState, error) {
+	for _, op := range ops {
+		switch strings.ToLower(op.Op) {
+		case "add", "replace":
+			attrs, err := op.attributes()
+			if err != nil {
+				return state, err
+			}
+			for path, value := range attrs {
+				if err := patchSCIMUserAttribute(&state, strings.ToLower(op.Op), path, value); err != nil {
+					return state, err
+				}
+			}
+		case "remove":
+			switch strings.ToLower(op.Path) {
+			case "displayname", "name.formatted", "name":
+				state.displayName = ""
+			case "emails":
+				state.emails = []string{}
+			default:
+				return state, scimErrorf(http.StatusBadRequest, "invalidPath", "removing %q is not supported", op.Path)
+			}
+		default:
+			return state, scimErrorf(http.StatusBadRequest, "invalidSyntax", "unsupported operation %q", op.Op)
+		}
+	}
+	return state, nil
+}
+
+// patchSCIMUserAttribute sets an attribute of the user's state. Attributes that cannot be stored
+// (such as "title") are ignored.
+func patchSCIMUserAttribute(state *scimUserState, op, path string, value json.RawMessage) error {
+	invalid := func(err error) error {
+		return scimErrorf(http.StatusBadRequest, "invalidValue", "invalid value for %q: %s", path, err)
+	}
+
+	switch path {
+	case "active":
+		active, err := parseSCIMBool(value)
+		if err != nil {
+			return err
+		}
+		state.active = active
+	case "username":
+		var userName string
+		if err := json.Unmarshal(value, &userName); err != nil {
+			return invalid(err)
+		}
+		normalized, err := normalizeSCIMUserName(userName)
+		if err != nil {
+			return err
+		}
+		state.userName = normalized
+	case "displayname", "name.formatted":
+		if err := json.Unmarshal(value, &state.displayName); err != nil {
+			return invalid(err)
+		}
+	case "name":
+		var name scimName
+		if err := json.Unmarshal(value, &name); err != nil {
+			return invalid(err)
+		}
+		if name.Formatted != "" {
+			state.displayName = name.Formatted
+		}
+	case "emails":
+		var emails []scimEmail
+		if err := json.Unmarshal(value, &emails); err != nil {
+			return invalid(err)
+		}
+		if op == "add" {
+			state.emails = append(state.emails, scimEmailValues(emails)...)
+		} else {
+			state.emails = scimEmailValues(emails)
+		}
+	}
+	return nil
+}
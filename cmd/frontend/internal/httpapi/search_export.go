@@ -0,0 +1,68 @@
+package httpapi
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/inconshreveable/log15"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/authz"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/backend"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
+)
+
+// serveSearchExportDownload writes the file of the completed search export
+// with the ID in the URL.
+func serveSearchExportDownload(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		http.Error(w, "invalid search export id", http.StatusBadRequest)
+		return
+	}
+
+	e, err := db.SearchExports.GetByID(r.Context(), id)
+	if err == db.ErrSearchExportNotFound {
+		http.Error(w, "search export not found", http.StatusNotFound)
+		return
+	} else if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// 🚨 SECURITY: Exports contain results from any repository, so access
+	// tokens restricted to certain repositories may not download them.
+	if err := authz.CheckTokenAllRepos(r.Context()); err != nil {
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
+
+	// 🚨 SECURITY: Only the user who created the export and site admins may
+	// download it. Other users can't tell whether it exists.
+	if err := backend.CheckSiteAdminOrSameUser(r.Context(), e.UserID); err != nil {
+		http.Error(w, "search export not found", http.StatusNotFound)
+		return
+	}
+
+	if e.State != db.SearchExportStateCompleted {
+		http.Error(w, fmt.Sprintf("search export is %s, not COMPLETED", e.State), http.StatusConflict)
+		return
+	}
+
+	contentType, ext := "text/csv; charset=utf-8", "csv"
+	if e.Format == db.SearchExportFormatJSONL {
+		contentType, ext = "application/x-ndjson", "jsonl"
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="search-export-%d.%s"`, e.ID, ext))
+
+	err = db.SearchExports.ReadChunks(r.Context(), e.ID, func(data []byte) error {
+		_, err := w.Write(data)
+		return err
+	})
+	if err != nil {
+		// The response status was sent with the first chunk, so the client
+		// can only notice a truncated file.
+		log15.Error("httpapi: writing search export failed", "id", e.ID, "error", err)
+	}
+}
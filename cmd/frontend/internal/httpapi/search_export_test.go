@@ -0,0 +1,98 @@
+package httpapi
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/authz"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
+	"github.com/sourcegraph/sourcegraph/internal/actor"
+)
+
+func TestServeSearchExportDownload(t *testing.T) {
+	db.Mocks.SearchExports.GetByID = func(ctx context.Context, id int64) (*db.SearchExport, error) {
+		switch id {
+		case 1:
+			return &db.SearchExport{ID: 1, UserID: 1, Format: db.SearchExportFormatJSONL, State: db.SearchExportStateCompleted}, nil
+		case 2:
+			return &db.SearchExport{ID: 2, UserID: 1, Format: db.SearchExportFormatCSV, State: db.SearchExportStateProcessing}, nil
+		}
+		return nil, db.ErrSearchExportNotFound
+	}
+	db.Mocks.SearchExports.ReadChunks = func(ctx context.Context, id int64, f func([]byte) error) error {
+		for _, chunk := range []string{`{"path":"a"}` + "\n", `{"path":"b"}` + "\n"} {
+			if err := f([]byte(chunk)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	db.Mocks.Users.GetByCurrentAuthUser = func(ctx context.Context) (*types.User, error) {
+		return &types.User{ID: actor.FromContext(ctx).UID}, nil
+	}
+	db.Mocks.Users.GetByID = func(ctx context.Context, id int32) (*types.User, error) {
+		return &types.User{ID: id, Username: "u"}, nil
+	}
+	defer func() { db.Mocks = db.MockStores{} }()
+
+	serve := func(uid int32, id string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", "/search/export/"+id, nil)
+		req = req.WithContext(actor.WithActor(req.Context(), &actor.Actor{UID: uid}))
+		req = mux.SetURLVars(req, map[string]string{"id": id})
+		rec := httptest.NewRecorder()
+		serveSearchExportDownload(rec, req)
+		return rec
+	}
+
+	t.Run("completed", func(t *testing.T) {
+		rec := serve(1, "1")
+		if rec.Code != http.StatusOK {
+			t.Fatalf("have status %d, want %d", rec.Code, http.StatusOK)
+		}
+		if have, want := rec.Header().Get("Content-Disposition"), `attachment; filename="search-export-1.jsonl"`; have != want {
+			t.Errorf("have Content-Disposition %q, want %q", have, want)
+		}
+		if have, want := rec.Body.String(), "{\"path\":\"a\"}\n{\"path\":\"b\"}\n"; have != want {
+			t.Errorf("have body %q, want %q", have, want)
+		}
+	})
+
+	// 🚨 SECURITY: Exports may contain results from any repository.
+	t.Run("token restricted to repositories", func(t *testing.T) {
+		restrictions, err := authz.NewTokenRestrictions([]string{authz.ScopeSearchRead}, []string{"^github\\.com/foo/"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		req := httptest.NewRequest("GET", "/search/export/1", nil)
+		req = req.WithContext(authz.WithTokenRestrictions(actor.WithActor(req.Context(), &actor.Actor{UID: 1}), restrictions))
+		req = mux.SetURLVars(req, map[string]string{"id": "1"})
+		rec := httptest.NewRecorder()
+		serveSearchExportDownload(rec, req)
+		if rec.Code != http.StatusForbidden {
+			t.Errorf("have status %d, want %d", rec.Code, http.StatusForbidden)
+		}
+	})
+
+	tests := []struct {
+		name string
+		uid  int32
+		id   string
+		want int
+	}{
+		{name: "processing", uid: 1, id: "2", want: http.StatusConflict},
+		{name: "not found", uid: 1, id: "3", want: http.StatusNotFound},
+		// 🚨 SECURITY: Other users may not download the export.
+		{name: "other user", uid: 2, id: "1", want: http.StatusNotFound},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if rec := serve(tc.uid, tc.id); rec.Code != tc.want {
+				t.Errorf("have status %d, want %d", rec.Code, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,193 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/inconshreveable/log15"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/graphqlbackend"
+	"github.com/sourcegraph/sourcegraph/internal/api"
+)
+
+// serveSearchStream runs the search query in the "q" URL parameter and writes
+// results as server-sent events as soon as they are found. The optional "v"
+// and "t" parameters are the version and pattern type, like the arguments of
+// the GraphQL search field.
+//
+// The events are:
+//
+//   - "matches": a JSON array of new results (see streamMatch)
+//   - "progress": a JSON object of repositories searched, cloning, etc. (see streamProgress)
+//   - "alert": a JSON object with the title and description of a search alert
+//   - "error": a JSON object with a message, if the search failed
+//   - "done": sent last, a JSON object with the overall result count
+func serveSearchStream(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "http flushing not supported", http.StatusInternalServerError)
+		return
+	}
+
+	args := &graphqlbackend.SearchArgs{
+		Query:   r.URL.Query().Get("q"),
+		Version: r.URL.Query().Get("v"),
+	}
+	if args.Version == "" {
+		args.Version = "V2"
+	}
+	if t := r.URL.Query().Get("t"); t != "" {
+		args.PatternType = &t
+	}
+
+	search, err := graphqlbackend.NewSearchImplementer(args)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+
+	stream := &eventStreamWriter{w: w, flusher: flusher}
+	results, err := graphqlbackend.StreamSearch(r.Context(), search, stream)
+	if err != nil {
+		stream.event("error", streamError{Message: err.Error()})
+		stream.event("done", streamDone{})
+		return
+	}
+
+	if alert := results.Alert(); alert != nil {
+		stream.event("alert", streamAlert{Title: alert.Title(), Description: alert.Description()})
+	}
+	stream.event("done", streamDone{
+		ResultCount: results.ResultCount(),
+		LimitHit:    results.LimitHit(),
+	})
+}
+
+// eventStreamWriter writes server-sent events. It implements
+// graphqlbackend.SearchStream.
+type eventStreamWriter struct {
+	mu      sync.Mutex
+	w       http.ResponseWriter
+	flusher http.Flusher
+}
+
+func (s *eventStreamWriter) Send(ev graphqlbackend.SearchEvent) {
+	if len(ev.Results) > 0 {
+		matches := make([]streamMatch, 0, len(ev.Results))
+		for _, result := range ev.Results {
+			if m, ok := toStreamMatch(result); ok {
+				matches = append(matches, m)
+			}
+		}
+		s.event("matches", matches)
+	}
+
+	p := ev.Progress
+	if len(p.Searched)+len(p.Cloning)+len(p.Missing)+len(p.Timedout) > 0 || p.LimitHit {
+		s.event("progress", streamProgress{
+			Searched: p.Searched,
+			Indexed:  p.Indexed,
+			Cloning:  p.Cloning,
+			Missing:  p.Missing,
+			Timedout: p.Timedout,
+			LimitHit: p.LimitHit,
+		})
+	}
+}
+
+func (s *eventStreamWriter) event(name string, v interface{}) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		log15.Error("search stream: failed to encode event", "event", name, "error", err)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	// The only reasonable error is the client going away, which we can't
+	// report.
+	_, _ = fmt.Fprintf(s.w, "event: %s\ndata: %s\n\n", name, data)
+	s.flusher.Flush()
+}
+
+// streamMatch is a single search result of a "matches" event.
+type streamMatch struct {
+	Type        string            `json:"type"`
+	Repository  string            `json:"repository"`
+	Commit      string            `json:"commit,omitempty"`
+	Path        string            `json:"path,omitempty"`
+	URL         string            `json:"url,omitempty"`
+	Message     string            `json:"message,omitempty"`
+	LineMatches []streamLineMatch `json:"lineMatches,omitempty"`
+}
+
+type streamLineMatch struct {
+	Preview          string    `json:"preview"`
+	LineNumber       int32     `json:"lineNumber"`
+	OffsetAndLengths [][]int32 `json:"offsetAndLengths"`
+}
+
+type streamProgress struct {
+	Searched []api.RepoName `json:"searched,omitempty"`
+	Indexed  []api.RepoName `json:"indexed,omitempty"`
+	Cloning  []api.RepoName `json:"cloning,omitempty"`
+	Missing  []api.RepoName `json:"missing,omitempty"`
+	Timedout []api.RepoName `json:"timedout,omitempty"`
+	LimitHit bool           `json:"limitHit,omitempty"`
+}
+
+type streamAlert struct {
+	Title       string  `json:"title"`
+	Description *string `json:"description,omitempty"`
+}
+
+type streamError struct {
+	Message string `json:"message"`
+}
+
+type streamDone struct {
+	ResultCount int32 `json:"resultCount"`
+	LimitHit    bool  `json:"limitHit"`
+}
+
+// toStreamMatch converts result to its streamed form. Result types which
+// can't be streamed (codemod results) return false.
+func toStreamMatch(result graphqlbackend.SearchResultResolver) (streamMatch, bool) {
+	if fm, ok := result.ToFileMatch(); ok {
+		m := streamMatch{
+			Type:       "file",
+			Repository: string(fm.Repo.Name),
+			Commit:     string(fm.CommitID),
+			Path:       fm.JPath,
+		}
+		for _, lm := range fm.LineMatches() {
+			m.LineMatches = append(m.LineMatches, streamLineMatch{
+				Preview:          lm.Preview(),
+				LineNumber:       lm.LineNumber(),
+				OffsetAndLengths: lm.OffsetAndLengths(),
+			})
+		}
+		return m, true
+	}
+	if repo, ok := result.ToRepository(); ok {
+		return streamMatch{Type: "repo", Repository: repo.Name()}, true
+	}
+	if c, ok := result.ToCommitSearchResult(); ok {
+		m := streamMatch{
+			Type:       "commit",
+			Repository: c.Commit().Repository().Name(),
+			Commit:     string(c.Commit().OID()),
+			URL:        c.URL(),
+		}
+		if p := c.MessagePreview(); p != nil {
+			m.Message = p.Value()
+		}
+		return m, true
+	}
+	return streamMatch{}, false
+}
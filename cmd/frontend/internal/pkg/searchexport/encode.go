@@ -0,0 +1,89 @@
+package searchexport
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/graphqlbackend"
+)
+
+// row is a single line of the exported file: a matching line of a file, or a
+// file whose path matched if it has no matching lines.
+type row struct {
+	Repository string `json:"repository"`
+	Commit     string `json:"commit"`
+	Path       string `json:"path"`
+	Line       int32  `json:"line,omitempty"` // 1-based, 0 for path matches
+	Preview    string `json:"preview,omitempty"`
+}
+
+var csvHeader = []string{"repository", "commit", "path", "line", "preview"}
+
+// toRows returns the rows of the given search results. Only file matches
+// can be exported, since paginated search only returns those.
+func toRows(results []graphqlbackend.SearchResultResolver) []row {
+	var rows []row
+	for _, result := range results {
+		fm, ok := result.ToFileMatch()
+		if !ok {
+			continue
+		}
+
+		r := row{
+			Repository: string(fm.Repo.Name),
+			Commit:     string(fm.CommitID),
+			Path:       fm.JPath,
+		}
+		lms := fm.LineMatches()
+		if len(lms) == 0 {
+			rows = append(rows, r)
+			continue
+		}
+		for _, lm := range lms {
+			r.Line = lm.LineNumber() + 1
+			r.Preview = lm.Preview()
+			rows = append(rows, r)
+		}
+	}
+	return rows
+}
+
+// encode encodes rows in the given format. The first chunk of a CSV file
+// starts with a header.
+func encode(format string, rows []row, first bool) ([]byte, error) {
+	var buf bytes.Buffer
+	switch format {
+	case db.SearchExportFormatCSV:
+		w := csv.NewWriter(&buf)
+		if first {
+			_ = w.Write(csvHeader)
+		}
+		for _, r := range rows {
+			line := ""
+			if r.Line > 0 {
+				line = strconv.Itoa(int(r.Line))
+			}
+			_ = w.Write([]string{r.Repository, r.Commit, r.Path, line, r.Preview})
+		}
+		w.Flush()
+		if err := w.Error(); err != nil {
+			return nil, err
+		}
+
+	case db.SearchExportFormatJSONL:
+		enc := json.NewEncoder(&buf)
+		for _, r := range rows {
+			if err := enc.Encode(r); err != nil {
+				return nil, err
+			}
+		}
+
+	default:
+		return nil, fmt.Errorf("unsupported search export format %q", format)
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,164 @@
+// Package searchexport implements asynchronous search exports, which write
+// all results of a search query to a CSV or JSON lines file that users
+// download once the export has completed.
+package searchexport
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/inconshreveable/log15"
+	"github.com/pkg/errors"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/graphqlbackend"
+	"github.com/sourcegraph/sourcegraph/internal/actor"
+)
+
+const (
+	// pageSize is the number of results requested per paginated search, and
+	// so roughly the number of results per chunk of an exported file.
+	pageSize = 1000
+
+	// stalledAfter is how long a processing export may go without a new
+	// chunk before another worker resumes it. It is longer than the timeout
+	// of a paginated search request.
+	stalledAfter = 5 * time.Minute
+
+	pollInterval    = 5 * time.Second
+	cleanupInterval = time.Minute
+)
+
+// StartWorker should be invoked only after the DB has been initialized. It
+// starts the background worker that processes queued search exports and
+// deletes expired ones. Workers of multiple frontends process different
+// exports concurrently.
+//
+// It should be invoked in a separate goroutine.
+func StartWorker() {
+	ctx := context.Background()
+
+	var lastCleanup time.Time
+	for {
+		if time.Since(lastCleanup) > cleanupInterval {
+			cleanup(ctx)
+			lastCleanup = time.Now()
+		}
+
+		e, err := db.SearchExports.Dequeue(ctx)
+		if err != nil {
+			log15.Error("searchexport: dequeuing search export failed", "error", err)
+		}
+		if e == nil {
+			time.Sleep(pollInterval)
+			continue
+		}
+
+		log15.Debug("searchexport: processing search export", "id", e.ID, "query", e.Query)
+		process(ctx, e)
+	}
+}
+
+func cleanup(ctx context.Context) {
+	if n, err := db.SearchExports.ResetStalled(ctx, stalledAfter); err != nil {
+		log15.Error("searchexport: resetting stalled search exports failed", "error", err)
+	} else if n > 0 {
+		log15.Info("searchexport: resumed stalled search exports", "count", n)
+	}
+
+	if _, err := db.SearchExports.DeleteExpired(ctx); err != nil {
+		log15.Error("searchexport: deleting expired search exports failed", "error", err)
+	}
+}
+
+// process exports the search results of e and records the outcome.
+func process(ctx context.Context, e *db.SearchExport) {
+	err := export(ctx, e, searchPage)
+	switch {
+	case err == db.ErrSearchExportNotProcessing:
+		// The export was canceled.
+		return
+	case err != nil:
+		log15.Warn("searchexport: search export failed", "id", e.ID, "error", err)
+		err = db.SearchExports.Fail(ctx, e.ID, err.Error())
+	default:
+		err = db.SearchExports.Complete(ctx, e.ID)
+	}
+	if err != nil && err != db.ErrSearchExportNotProcessing {
+		log15.Error("searchexport: recording outcome of search export failed", "id", e.ID, "error", err)
+	}
+}
+
+// page is a page of search results and the cursor of the next page, or nil if
+// it is the last page.
+type page struct {
+	results []graphqlbackend.SearchResultResolver
+	next    *string
+}
+
+// searchPage runs a paginated search and returns the page after the cursor.
+func searchPage(ctx context.Context, e *db.SearchExport, cursor *string) (*page, error) {
+	first := int32(pageSize)
+	search, err := graphqlbackend.NewSearchImplementer(&graphqlbackend.SearchArgs{
+		Version:     e.Version,
+		PatternType: e.PatternType,
+		Query:       e.Query,
+		After:       cursor,
+		First:       &first,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	results, err := search.Results(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if alert := results.Alert(); alert != nil && len(results.Results()) == 0 {
+		msg := alert.Title()
+		if d := alert.Description(); d != nil {
+			msg = fmt.Sprintf("%s: %s", msg, *d)
+		}
+		return nil, errors.New(msg)
+	}
+
+	p := &page{results: results.Results()}
+	if pi := results.PageInfo(); pi.HasNextPage() {
+		p.next = pi.EndCursor()
+	}
+	return p, nil
+}
+
+// export appends the results of e after its cursor to its file, one chunk per
+// page of results from the given search function. It returns
+// db.ErrSearchExportNotProcessing when the export is canceled.
+//
+// 🚨 SECURITY: The search runs as the user of the export, so that it only
+// includes repositories the user has access to.
+func export(ctx context.Context, e *db.SearchExport, search func(context.Context, *db.SearchExport, *string) (*page, error)) error {
+	ctx = actor.WithActor(ctx, &actor.Actor{UID: e.UserID})
+
+	cursor, first := e.Cursor, e.ChunkCount == 0
+	for {
+		p, err := search(ctx, e, cursor)
+		if err != nil {
+			return err
+		}
+
+		rows := toRows(p.results)
+		data, err := encode(e.Format, rows, first)
+		if err != nil {
+			return err
+		}
+
+		if err := db.SearchExports.AppendChunk(ctx, e.ID, data, int32(len(rows)), p.next); err != nil {
+			return err
+		}
+
+		if p.next == nil {
+			return nil
+		}
+		cursor, first = p.next, false
+	}
+}
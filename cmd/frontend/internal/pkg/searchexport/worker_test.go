@@ -0,0 +1,171 @@
+package searchexport
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/graphqlbackend"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
+	"github.com/sourcegraph/sourcegraph/internal/actor"
+	"github.com/sourcegraph/sourcegraph/internal/api"
+)
+
+// fileMatch returns a file match with the given line matches, which are
+// unmarshaled because their type is unexported.
+func fileMatch(t *testing.T, repo, path string, lines map[int32]string) *graphqlbackend.FileMatchResolver {
+	t.Helper()
+
+	type lineMatch struct {
+		Preview    string
+		LineNumber int32
+	}
+	var lms []lineMatch
+	for n := int32(0); n < 100; n++ {
+		if p, ok := lines[n]; ok {
+			lms = append(lms, lineMatch{Preview: p, LineNumber: n})
+		}
+	}
+	data, err := json.Marshal(map[string]interface{}{"Path": path, "LineMatches": lms})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fm := &graphqlbackend.FileMatchResolver{}
+	if err := json.Unmarshal(data, fm); err != nil {
+		t.Fatal(err)
+	}
+	fm.Repo = &types.Repo{Name: api.RepoName("github.com/sourcegraph/" + repo)}
+	fm.CommitID = "deadbeef"
+	return fm
+}
+
+func TestExport(t *testing.T) {
+	pages := map[string]*page{
+		"": {
+			results: []graphqlbackend.SearchResultResolver{
+				fileMatch(t, "a", "main.go", map[int32]string{0: "package main", 9: `fmt.Println("a, b")`}),
+			},
+			next: strPtr("page2"),
+		},
+		"page2": {
+			results: []graphqlbackend.SearchResultResolver{
+				fileMatch(t, "b", "README.md", nil),
+			},
+		},
+	}
+	search := func(ctx context.Context, e *db.SearchExport, cursor *string) (*page, error) {
+		if a := actor.FromContext(ctx); a.UID != e.UserID {
+			t.Errorf("search runs as user %d, want %d", a.UID, e.UserID)
+		}
+		key := ""
+		if cursor != nil {
+			key = *cursor
+		}
+		return pages[key], nil
+	}
+
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{
+			format: db.SearchExportFormatCSV,
+			want: `repository,commit,path,line,preview
+github.com/sourcegraph/a,deadbeef,main.go,1,package main
+github.com/sourcegraph/a,deadbeef,main.go,10,"fmt.Println(""a, b"")"
+github.com/sourcegraph/b,deadbeef,README.md,,
+`,
+		},
+		{
+			format: db.SearchExportFormatJSONL,
+			want: `{"repository":"github.com/sourcegraph/a","commit":"deadbeef","path":"main.go","line":1,"preview":"package main"}
+{"repository":"github.com/sourcegraph/a","commit":"deadbeef","path":"main.go","line":10,"preview":"fmt.Println(\"a, b\")"}
+{"repository":"github.com/sourcegraph/b","commit":"deadbeef","path":"README.md"}
+`,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.format, func(t *testing.T) {
+			var (
+				file    strings.Builder
+				results int32
+				cursors []*string
+			)
+			db.Mocks.SearchExports.AppendChunk = func(ctx context.Context, id int64, data []byte, n int32, cursor *string) error {
+				file.Write(data)
+				results += n
+				cursors = append(cursors, cursor)
+				return nil
+			}
+			defer func() { db.Mocks.SearchExports = db.MockSearchExports{} }()
+
+			e := &db.SearchExport{ID: 1, UserID: 2, Format: tc.format}
+			if err := export(context.Background(), e, search); err != nil {
+				t.Fatal(err)
+			}
+
+			if diff := cmp.Diff(tc.want, file.String()); diff != "" {
+				t.Errorf("unexpected file (-want +got):\n%s", diff)
+			}
+			if results != 3 {
+				t.Errorf("have %d results, want 3", results)
+			}
+			if diff := cmp.Diff([]*string{strPtr("page2"), nil}, cursors); diff != "" {
+				t.Errorf("unexpected cursors (-want +got):\n%s", diff)
+			}
+		})
+	}
+
+	t.Run("resume", func(t *testing.T) {
+		var file strings.Builder
+		db.Mocks.SearchExports.AppendChunk = func(ctx context.Context, id int64, data []byte, n int32, cursor *string) error {
+			file.Write(data)
+			return nil
+		}
+		defer func() { db.Mocks.SearchExports = db.MockSearchExports{} }()
+
+		// A resumed CSV export has a header already.
+		e := &db.SearchExport{ID: 1, UserID: 2, Format: db.SearchExportFormatCSV, Cursor: strPtr("page2"), ChunkCount: 1}
+		if err := export(context.Background(), e, search); err != nil {
+			t.Fatal(err)
+		}
+		if have, want := file.String(), "github.com/sourcegraph/b,deadbeef,README.md,,\n"; have != want {
+			t.Errorf("have file %q, want %q", have, want)
+		}
+	})
+
+	t.Run("canceled", func(t *testing.T) {
+		var chunks int
+		db.Mocks.SearchExports.AppendChunk = func(ctx context.Context, id int64, data []byte, n int32, cursor *string) error {
+			chunks++
+			return db.ErrSearchExportNotProcessing
+		}
+		defer func() { db.Mocks.SearchExports = db.MockSearchExports{} }()
+
+		e := &db.SearchExport{ID: 1, UserID: 2, Format: db.SearchExportFormatCSV}
+		if err := export(context.Background(), e, search); err != db.ErrSearchExportNotProcessing {
+			t.Errorf("have error %v, want %v", err, db.ErrSearchExportNotProcessing)
+		}
+		if chunks != 1 {
+			t.Errorf("have %d chunks appended, want 1", chunks)
+		}
+	})
+
+	t.Run("search error", func(t *testing.T) {
+		failing := func(context.Context, *db.SearchExport, *string) (*page, error) {
+			return nil, errors.New("boom")
+		}
+		e := &db.SearchExport{ID: 1, UserID: 2, Format: db.SearchExportFormatCSV}
+		if err := export(context.Background(), e, failing); err == nil || err.Error() != "boom" {
+			t.Errorf("have error %v, want boom", err)
+		}
+	})
+}
+
+func strPtr(s string) *string { return &s }
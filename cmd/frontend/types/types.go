@@ -85,6 +85,7 @@ type User struct {
 	SiteAdmin   bool
 	BuiltinAuth bool
 	Tags        []string
+	Deactivated bool // only set for users listed with UsersListOptions.IncludeDeactivated
 }
 
 type Org struct {
@@ -59,6 +59,7 @@ var reposRecloned = prometheus.NewCounter(prometheus.CounterOpts{
 // 2. Remove stale lock files.
 // 3. Remove inactive repos on sourcegraph.com
 // 4. Reclone repos after a while. (simulate git gc)
+// 5. Update replicas that are behind their primary.
 func (s *Server) cleanupRepos() {
 	bCtx, bCancel := s.serverContext()
 	defer bCancel()
@@ -230,6 +231,8 @@ func (s *Server) cleanupRepos() {
 	if err := s.freeUpSpace(b); err != nil {
 		log15.Error("cleanup: error freeing up space", "error", err)
 	}
+
+	s.syncReplicas()
 }
 
 // DiskSizer gets information about disk size and free space.
@@ -0,0 +1,660 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"os/exec"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/inconshreveable/log15"
+	"github.com/pkg/errors"
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/conf"
+	"github.com/sourcegraph/sourcegraph/internal/gitserver/protocol"
+	"github.com/sourcegraph/sourcegraph/internal/pathmatch"
+)
+
+// The commit index of a repository lets type:commit and type:diff searches
+// of the default branch avoid running `git log` over the whole history for
+// every query. It lives in the repository's GIT_DIR and consists of:
+//
+//   - sg_commitindex_state: the JSON encoded commitIndexState.
+//   - sg_commitindex-<head>: one record per non-merge commit reachable from
+//     HEAD, oldest first. A record is a JSON encoded
+//     protocol.CommitSearchResult (without refs) followed by its length as a
+//     big-endian uint32, so that the file can be read newest first.
+//
+// The index is updated incrementally in the background after each fetch (see
+// updateCommitIndexAsync). Records are only ever appended up to the size
+// recorded in the state file, so readers never see partially written records.
+const (
+	commitIndexStateFile  = "sg_commitindex_state"
+	commitIndexFilePrefix = "sg_commitindex-"
+
+	// commitIndexVersion must be incremented whenever the format of the
+	// index changes. Indexes with another version are rebuilt.
+	commitIndexVersion = 1
+
+	// maxCommitIndexDiffSize is the maximum size of a single commit's diff
+	// in the index. Larger diffs are truncated at a file boundary.
+	maxCommitIndexDiffSize = 1 << 20
+)
+
+type commitIndexState struct {
+	Version int
+	// File is the name of the index file in GIT_DIR.
+	File string
+	// Head is the commit the index was last updated to.
+	Head string
+	// Size is the number of bytes of File which contain complete records.
+	Size int64
+}
+
+func readCommitIndexState(dir GitDir) (*commitIndexState, error) {
+	b, err := ioutil.ReadFile(dir.Path(commitIndexStateFile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var state commitIndexState
+	if err := json.Unmarshal(b, &state); err != nil {
+		return nil, err
+	}
+	if state.Version != commitIndexVersion || strings.ContainsAny(state.File, `/\`) {
+		return nil, nil
+	}
+	return &state, nil
+}
+
+func writeCommitIndexState(dir GitDir, state *commitIndexState) error {
+	b, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	_, err = updateFileIfDifferent(dir.Path(commitIndexStateFile), b)
+	return err
+}
+
+// commitIndexUpdates tracks the background commit index updates of a Server,
+// so that the index of each repository is only updated by one goroutine at a
+// time.
+type commitIndexUpdates struct {
+	mu      sync.Mutex
+	running map[GitDir]bool // the repositories whose index is being updated
+	pending map[GitDir]bool // the repositories whose index must be updated again when done
+}
+
+// updateCommitIndexAsync updates the commit index of the repository in dir in
+// the background, so that building the index of a large repository does not
+// delay its clone or update. If the index is already being updated, it is
+// updated again afterwards. It is a no-op if the commit index is disabled.
+func (s *Server) updateCommitIndexAsync(repo api.RepoName, dir GitDir) {
+	if !conf.CommitIndexEnabled() {
+		return
+	}
+
+	u := &s.commitIndexUpdates
+	u.mu.Lock()
+	if u.running[dir] {
+		u.pending[dir] = true
+		u.mu.Unlock()
+		return
+	}
+	if u.running == nil {
+		u.running = map[GitDir]bool{}
+		u.pending = map[GitDir]bool{}
+	}
+	u.running[dir] = true
+	u.mu.Unlock()
+
+	ctx, cancel := s.serverContext()
+	go func() {
+		defer cancel()
+		for {
+			ctx, cancel := context.WithTimeout(ctx, longGitCommandTimeout)
+			err := updateCommitIndex(ctx, dir)
+			cancel()
+			if err != nil {
+				log15.Warn("Failed to update commit index", "repo", repo, "error", err)
+			}
+
+			u.mu.Lock()
+			if !u.pending[dir] {
+				delete(u.running, dir)
+				u.mu.Unlock()
+				return
+			}
+			delete(u.pending, dir)
+			u.mu.Unlock()
+		}
+	}()
+}
+
+// updateCommitIndex brings the commit index of the repository in dir up to
+// date with HEAD. It is a no-op if the commit index is disabled. Callers must
+// not update the index of the same repository concurrently (see
+// updateCommitIndexAsync).
+func updateCommitIndex(ctx context.Context, dir GitDir) error {
+	if !conf.CommitIndexEnabled() {
+		return nil
+	}
+
+	cmd := exec.CommandContext(ctx, "git", "rev-parse", "--verify", "--quiet", "HEAD^{commit}")
+	cmd.Dir = string(dir)
+	out, err := cmd.Output()
+	if err != nil {
+		// The repository is empty, there is nothing to index.
+		return nil
+	}
+	head := string(bytes.TrimSpace(out))
+
+	state, err := readCommitIndexState(dir)
+	if err != nil {
+		return errors.Wrap(err, "reading commit index state")
+	}
+	if state != nil {
+		// The index file is missing if the repository was cloned again
+		// while its index was being updated.
+		if _, err := os.Stat(dir.Path(state.File)); err != nil {
+			state = nil
+		} else if state.Head == head {
+			return nil
+		}
+	}
+
+	if state != nil && isAncestor(ctx, dir, state.Head, head) {
+		f, err := os.OpenFile(dir.Path(state.File), os.O_WRONLY, 0600)
+		if err == nil {
+			// Drop anything written after the last successful update.
+			if err = f.Truncate(state.Size); err == nil {
+				var n int64
+				n, err = writeCommitIndexRecords(ctx, dir, f, state.Size, state.Head+".."+head)
+				if err == nil {
+					err = f.Sync()
+				}
+				state.Size += n
+			}
+			if closeErr := f.Close(); err == nil {
+				err = closeErr
+			}
+			if err == nil {
+				state.Head = head
+				return writeCommitIndexState(dir, state)
+			}
+		}
+		log15.Warn("failed to update commit index incrementally, rebuilding it", "dir", dir, "error", err)
+	}
+
+	// Build a new index from scratch. It gets a new name so that concurrent
+	// readers of the old index are not affected.
+	newState := &commitIndexState{
+		Version: commitIndexVersion,
+		File:    commitIndexFilePrefix + head,
+		Head:    head,
+	}
+	f, err := os.OpenFile(dir.Path(newState.File), os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	newState.Size, err = writeCommitIndexRecords(ctx, dir, f, 0, head)
+	if err == nil {
+		err = f.Sync()
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = writeCommitIndexState(dir, newState)
+	}
+	if err != nil {
+		os.Remove(dir.Path(newState.File))
+		return err
+	}
+
+	if state != nil && state.File != newState.File {
+		os.Remove(dir.Path(state.File))
+	}
+	return nil
+}
+
+// isAncestor returns true if commit a is an ancestor of commit b.
+func isAncestor(ctx context.Context, dir GitDir, a, b string) bool {
+	cmd := exec.CommandContext(ctx, "git", "merge-base", "--is-ancestor", a, b)
+	cmd.Dir = string(dir)
+	return cmd.Run() == nil
+}
+
+// commitIndexLogFormat separates commits with "\x1e\x00" and the fields of a
+// commit with "\x00". The patch follows the last field.
+const commitIndexLogFormat = "--format=format:%x1e%x00%H%x00%aN%x00%aE%x00%at%x00%cN%x00%cE%x00%ct%x00%P%x00%B%x00"
+
+// writeCommitIndexRecords writes a record for each non-merge commit in
+// revRange to w at offset, oldest first, and returns the number of bytes
+// written.
+func writeCommitIndexRecords(ctx context.Context, dir GitDir, w io.WriterAt, offset int64, revRange string) (int64, error) {
+	cmd := exec.CommandContext(ctx, "git", "log", "--no-merges", "--reverse", "--patch", "--no-prefix", "--unified=0", "--no-color", "--no-ext-diff", commitIndexLogFormat, revRange, "--")
+	cmd.Dir = string(dir)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return 0, err
+	}
+	if err := cmd.Start(); err != nil {
+		return 0, err
+	}
+
+	var (
+		n        int64
+		writeErr error
+		lenBuf   [4]byte
+	)
+	scanner := bufio.NewScanner(stdout)
+	scanner.Buffer(make([]byte, 0, 64*1024), 256*1024*1024)
+	scanner.Split(splitCommitIndexLog)
+	for scanner.Scan() && writeErr == nil {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var c *protocol.CommitSearchResult
+		c, writeErr = parseCommitIndexLogEntry(scanner.Bytes())
+		if writeErr != nil {
+			break
+		}
+		var b []byte
+		b, writeErr = json.Marshal(c)
+		if writeErr != nil {
+			break
+		}
+		binary.BigEndian.PutUint32(lenBuf[:], uint32(len(b)))
+		b = append(b, lenBuf[:]...)
+		if _, writeErr = w.WriteAt(b, offset+n); writeErr == nil {
+			n += int64(len(b))
+		}
+	}
+	if writeErr == nil {
+		writeErr = scanner.Err()
+	}
+	if writeErr != nil {
+		// Make git exit instead of blocking on a full pipe.
+		_, _ = io.Copy(ioutil.Discard, stdout)
+	}
+	if err := cmd.Wait(); err != nil {
+		return n, errors.Wrapf(err, "git log failed: %s", stderr.String())
+	}
+	return n, writeErr
+}
+
+// splitCommitIndexLog is a bufio.SplitFunc which splits the output of `git
+// log` with commitIndexLogFormat into commits.
+func splitCommitIndexLog(data []byte, atEOF bool) (advance int, token []byte, err error) {
+	sep := []byte("\x1e\x00")
+	if i := bytes.Index(data, sep); i >= 0 {
+		return i + len(sep), data[:i], nil
+	}
+	if atEOF && len(data) > 0 {
+		return len(data), data, nil
+	}
+	return 0, nil, nil
+}
+
+func parseCommitIndexLogEntry(data []byte) (*protocol.CommitSearchResult, error) {
+	parts := bytes.SplitN(data, []byte{0}, 10)
+	if len(parts) != 10 {
+		return nil, fmt.Errorf("invalid git log entry: %q", data)
+	}
+	parseTime := func(b []byte) (time.Time, error) {
+		sec, err := strconv.ParseInt(string(b), 10, 64)
+		if err != nil {
+			return time.Time{}, err
+		}
+		return time.Unix(sec, 0).UTC(), nil
+	}
+	authorDate, err := parseTime(parts[3])
+	if err != nil {
+		return nil, err
+	}
+	committerDate, err := parseTime(parts[6])
+	if err != nil {
+		return nil, err
+	}
+	var parents []api.CommitID
+	for _, p := range strings.Fields(string(parts[7])) {
+		parents = append(parents, api.CommitID(p))
+	}
+	return &protocol.CommitSearchResult{
+		ID:        api.CommitID(parts[0]),
+		Author:    protocol.CommitSignature{Name: string(parts[1]), Email: string(parts[2]), Date: authorDate},
+		Committer: protocol.CommitSignature{Name: string(parts[4]), Email: string(parts[5]), Date: committerDate},
+		Message:   strings.TrimSpace(string(parts[8])),
+		Parents:   parents,
+		RawDiff:   truncateDiff(strings.TrimLeft(string(parts[9]), "\n")),
+	}, nil
+}
+
+// truncateDiff truncates rawDiff to at most maxCommitIndexDiffSize bytes,
+// dropping whole files.
+func truncateDiff(rawDiff string) string {
+	if len(rawDiff) <= maxCommitIndexDiffSize {
+		return rawDiff
+	}
+	i := strings.LastIndex(rawDiff[:maxCommitIndexDiffSize], "\ndiff ")
+	if i < 0 {
+		return ""
+	}
+	return rawDiff[:i+1]
+}
+
+func (s *Server) handleCommitSearch(w http.ResponseWriter, r *http.Request) {
+	var req protocol.CommitSearchRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	resp, err := s.commitSearch(r.Context(), &req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+// commitSearch searches the commit index of req.Repo. The response is not
+// Indexed if the index is disabled, missing or out of date.
+func (s *Server) commitSearch(ctx context.Context, req *protocol.CommitSearchRequest) (*protocol.CommitSearchResponse, error) {
+	resp := &protocol.CommitSearchResponse{}
+	if !conf.CommitIndexEnabled() {
+		return resp, nil
+	}
+
+	dir := s.dir(protocol.NormalizeRepo(req.Repo))
+	state, err := readCommitIndexState(dir)
+	if err != nil || state == nil {
+		return resp, nil
+	}
+	if head, err := quickRevParseHead(dir); err != nil || head != state.Head {
+		return resp, nil
+	}
+	f, err := os.Open(dir.Path(state.File))
+	if err != nil {
+		// The index was replaced after we read the state.
+		return resp, nil
+	}
+	defer f.Close()
+
+	m, err := newCommitMatcher(ctx, dir, req)
+	if err != nil {
+		return nil, err
+	}
+	refs, headRef, err := commitRefs(ctx, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	resp.Indexed = true
+	resp.Complete = true
+	var lenBuf [4]byte
+	for pos := state.Size; pos > 0 && (req.Limit <= 0 || len(resp.Results) < req.Limit); {
+		if ctx.Err() != nil {
+			resp.Complete = false
+			break
+		}
+
+		if _, err := f.ReadAt(lenBuf[:], pos-4); err != nil {
+			return nil, err
+		}
+		n := int64(binary.BigEndian.Uint32(lenBuf[:]))
+		pos -= 4 + n
+		if pos < 0 {
+			return nil, errors.New("corrupt commit index")
+		}
+		b := make([]byte, n)
+		if _, err := f.ReadAt(b, pos); err != nil {
+			return nil, err
+		}
+		var c protocol.CommitSearchResult
+		if err := json.Unmarshal(b, &c); err != nil {
+			return nil, err
+		}
+
+		if !m.match(&c) {
+			continue
+		}
+		c.Refs = refs[c.ID]
+		if headRef != "" {
+			c.SourceRefs = []string{headRef}
+		}
+		resp.Results = append(resp.Results, &c)
+	}
+	return resp, nil
+}
+
+// commitRefs returns the names of the refs pointing to each commit, and the
+// ref HEAD points to. Like `git log --decorate`, the ref HEAD points to is
+// omitted from the former.
+func commitRefs(ctx context.Context, dir GitDir) (refs map[api.CommitID][]string, headRef string, err error) {
+	cmd := exec.CommandContext(ctx, "git", "symbolic-ref", "--quiet", "HEAD")
+	cmd.Dir = string(dir)
+	if out, err := cmd.Output(); err == nil {
+		headRef = string(bytes.TrimSpace(out))
+	}
+
+	cmd = exec.CommandContext(ctx, "git", "for-each-ref", "--format=%(objectname) %(*objectname) %(refname)")
+	cmd.Dir = string(dir)
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, "", errors.Wrap(err, "git for-each-ref")
+	}
+	refs = map[api.CommitID][]string{}
+	for _, line := range strings.Split(string(out), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+		// Annotated tags have the peeled commit as the second field.
+		commit, name := fields[0], fields[len(fields)-1]
+		if len(fields) == 3 {
+			commit = fields[1]
+		}
+		if name == headRef {
+			continue
+		}
+		refs[api.CommitID(commit)] = append(refs[api.CommitID(commit)], name)
+	}
+	for _, names := range refs {
+		sort.Strings(names)
+	}
+	return refs, headRef, nil
+}
+
+// commitMatcher implements the filters of a protocol.CommitSearchRequest.
+type commitMatcher struct {
+	message, author, committer []*regexp.Regexp
+	invert                     bool
+
+	// minDate and maxDate are the bounds of the committer date, if non-zero.
+	minDate, maxDate time.Time
+
+	diff        *regexp.Regexp
+	paths       pathmatch.PathMatcher
+	filterPaths bool
+}
+
+func newCommitMatcher(ctx context.Context, dir GitDir, req *protocol.CommitSearchRequest) (*commitMatcher, error) {
+	m := &commitMatcher{invert: req.InvertPatterns}
+
+	compile := func(patterns []string, caseSensitive bool) ([]*regexp.Regexp, error) {
+		res := make([]*regexp.Regexp, 0, len(patterns))
+		for _, p := range patterns {
+			flags := "(?m)"
+			if !caseSensitive {
+				flags = "(?im)"
+			}
+			re, err := regexp.Compile(flags + p)
+			if err != nil {
+				return nil, err
+			}
+			res = append(res, re)
+		}
+		return res, nil
+	}
+	var err error
+	if m.message, err = compile(req.MessagePatterns, req.PatternsAreCaseSensitive); err != nil {
+		return nil, err
+	}
+	if m.author, err = compile(req.AuthorPatterns, req.PatternsAreCaseSensitive); err != nil {
+		return nil, err
+	}
+	if m.committer, err = compile(req.CommitterPatterns, req.PatternsAreCaseSensitive); err != nil {
+		return nil, err
+	}
+
+	if req.DiffPattern != "" {
+		p := req.DiffPattern
+		if !req.DiffPatternIsRegExp {
+			p = regexp.QuoteMeta(p)
+		}
+		diffPatterns, err := compile([]string{p}, req.DiffPatternIsCaseSensitive)
+		if err != nil {
+			return nil, err
+		}
+		m.diff = diffPatterns[0]
+	}
+
+	m.filterPaths = len(req.IncludePatterns) > 0 || req.ExcludePattern != ""
+	m.paths, err = pathmatch.CompilePathPatterns(req.IncludePatterns, req.ExcludePattern, pathmatch.CompileOptions{
+		RegExp:        req.PathPatternsAreRegExps,
+		CaseSensitive: req.DiffPatternIsCaseSensitive,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Let git interpret the dates, so that we accept exactly the same values
+	// as `git log --since/--until`.
+	if len(req.Before)+len(req.After) > 0 {
+		args := []string{"rev-parse"}
+		for _, s := range req.Before {
+			args = append(args, "--until="+s)
+		}
+		for _, s := range req.After {
+			args = append(args, "--since="+s)
+		}
+		cmd := exec.CommandContext(ctx, "git", args...)
+		cmd.Dir = string(dir)
+		out, err := cmd.Output()
+		if err != nil {
+			return nil, errors.Wrap(err, "parsing before:/after: dates")
+		}
+		for _, f := range strings.Fields(string(out)) {
+			var (
+				t   *time.Time
+				max bool
+			)
+			switch {
+			case strings.HasPrefix(f, "--max-age="):
+				t, f = &m.minDate, strings.TrimPrefix(f, "--max-age=")
+			case strings.HasPrefix(f, "--min-age="):
+				t, f, max = &m.maxDate, strings.TrimPrefix(f, "--min-age="), true
+			default:
+				continue
+			}
+			sec, err := strconv.ParseInt(f, 10, 64)
+			if err != nil {
+				return nil, err
+			}
+			d := time.Unix(sec, 0)
+			if t.IsZero() || (max && d.Before(*t)) || (!max && d.After(*t)) {
+				*t = d
+			}
+		}
+	}
+
+	return m, nil
+}
+
+func (m *commitMatcher) match(c *protocol.CommitSearchResult) bool {
+	if !m.minDate.IsZero() && c.Committer.Date.Before(m.minDate) {
+		return false
+	}
+	if !m.maxDate.IsZero() && c.Committer.Date.After(m.maxDate) {
+		return false
+	}
+
+	if len(m.message)+len(m.author)+len(m.committer) > 0 {
+		all := func(res []*regexp.Regexp, s string) bool {
+			for _, re := range res {
+				if !re.MatchString(s) {
+					return false
+				}
+			}
+			return true
+		}
+		ok := all(m.message, c.Message) &&
+			all(m.author, c.Author.Name+" <"+c.Author.Email+">") &&
+			all(m.committer, c.Committer.Name+" <"+c.Committer.Email+">")
+		if ok == m.invert {
+			return false
+		}
+	}
+
+	if m.diff == nil && !m.filterPaths {
+		return true
+	}
+	return m.matchDiff(c.RawDiff)
+}
+
+// matchDiff returns true if rawDiff changes a file matching the path filters
+// and, if there is a diff pattern, one of the lines it adds or removes in
+// such a file matches it.
+func (m *commitMatcher) matchDiff(rawDiff string) bool {
+	var inFile, inHunk bool
+	for _, line := range strings.Split(rawDiff, "\n") {
+		switch {
+		case strings.HasPrefix(line, "diff "):
+			inFile, inHunk = false, false
+		case !inHunk && strings.HasPrefix(line, "--- "):
+			inFile = inFile || m.matchPath(strings.TrimPrefix(line, "--- "))
+		case !inHunk && strings.HasPrefix(line, "+++ "):
+			inFile = inFile || m.matchPath(strings.TrimPrefix(line, "+++ "))
+		case strings.HasPrefix(line, "@@ "):
+			inHunk = true
+			if inFile && m.diff == nil {
+				return true
+			}
+		case inFile && inHunk && (strings.HasPrefix(line, "+") || strings.HasPrefix(line, "-")):
+			if m.diff.MatchString(line[1:]) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (m *commitMatcher) matchPath(name string) bool {
+	if name == "/dev/null" {
+		return false
+	}
+	return m.paths.MatchPath(name)
+}
@@ -0,0 +1,191 @@
+package server
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/conf"
+	"github.com/sourcegraph/sourcegraph/internal/gitserver/protocol"
+	"github.com/sourcegraph/sourcegraph/schema"
+)
+
+func TestCommitIndex(t *testing.T) {
+	conf.Mock(&conf.Unified{SiteConfiguration: schema.SiteConfiguration{
+		ExperimentalFeatures: &schema.ExperimentalFeatures{CommitIndex: "enabled"},
+	}})
+	defer conf.Mock(nil)
+
+	root, cleanup := tmpDir(t)
+	defer cleanup()
+	repoDir := filepath.Join(root, "repo")
+	dir := GitDir(filepath.Join(repoDir, ".git"))
+	s := &Server{ReposDir: root}
+	ctx := context.Background()
+
+	cmd := func(name string, arg ...string) string {
+		t.Helper()
+		c := exec.Command(name, arg...)
+		c.Dir = repoDir
+		c.Env = []string{
+			"GIT_COMMITTER_NAME=c",
+			"GIT_COMMITTER_EMAIL=c@c.com",
+			"GIT_COMMITTER_DATE=2020-01-02T00:00:00Z",
+			"GIT_AUTHOR_NAME=a",
+			"GIT_AUTHOR_EMAIL=a@a.com",
+			"GIT_AUTHOR_DATE=2020-01-02T00:00:00Z",
+		}
+		b, err := c.CombinedOutput()
+		if err != nil {
+			t.Fatalf("%s %s failed: %s: %s", name, strings.Join(arg, " "), err, b)
+		}
+		return strings.TrimSpace(string(b))
+	}
+	commit := func(file, content, message, date string) string {
+		t.Helper()
+		cmd("sh", "-c", "echo "+content+" > "+file)
+		cmd("git", "add", file)
+		cmd("git", "commit", "-m", message, "--date", date)
+		return cmd("git", "rev-parse", "HEAD")
+	}
+
+	if err := os.MkdirAll(repoDir, 0700); err != nil {
+		t.Fatal(err)
+	}
+	cmd("git", "init", ".")
+	cmd("git", "checkout", "-b", "main")
+	c1 := commit("a.go", "foo", "add a", "2020-01-01T00:00:00Z")
+	c2 := commit("b.txt", "bar", "add b", "2020-01-02T00:00:00Z")
+
+	if err := updateCommitIndex(ctx, dir); err != nil {
+		t.Fatal(err)
+	}
+
+	search := func(req protocol.CommitSearchRequest) []string {
+		t.Helper()
+		req.Repo = "repo"
+		resp, err := s.commitSearch(ctx, &req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !resp.Indexed || !resp.Complete {
+			t.Fatalf("got Indexed=%v Complete=%v, want both", resp.Indexed, resp.Complete)
+		}
+		var ids []string
+		for _, r := range resp.Results {
+			ids = append(ids, string(r.ID))
+		}
+		return ids
+	}
+
+	if got, want := search(protocol.CommitSearchRequest{}), []string{c2, c1}; !reflect.DeepEqual(got, want) {
+		t.Errorf("all commits: got %v, want %v", got, want)
+	}
+
+	// Commits added by a later fetch are appended to the index.
+	c3 := commit("a.go", "baz", "change a", "2020-01-03T00:00:00Z")
+	if resp, _ := s.commitSearch(ctx, &protocol.CommitSearchRequest{Repo: "repo"}); resp.Indexed {
+		t.Error("stale index must not be used")
+	}
+	if err := updateCommitIndex(ctx, dir); err != nil {
+		t.Fatal(err)
+	}
+	state, err := readCommitIndexState(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if state.File != commitIndexFilePrefix+c2 {
+		t.Errorf("index was rebuilt instead of updated: %s", state.File)
+	}
+
+	tests := []struct {
+		name string
+		req  protocol.CommitSearchRequest
+		want []string
+	}{
+		{"all", protocol.CommitSearchRequest{}, []string{c3, c2, c1}},
+		{"limit", protocol.CommitSearchRequest{Limit: 1}, []string{c3}},
+		{"message", protocol.CommitSearchRequest{MessagePatterns: []string{"ADD"}}, []string{c2, c1}},
+		{"message case sensitive", protocol.CommitSearchRequest{MessagePatterns: []string{"ADD"}, PatternsAreCaseSensitive: true}, nil},
+		{"message inverted", protocol.CommitSearchRequest{MessagePatterns: []string{"add"}, InvertPatterns: true}, []string{c3}},
+		{"all patterns match", protocol.CommitSearchRequest{MessagePatterns: []string{"add", "b$"}}, []string{c2}},
+		{"author", protocol.CommitSearchRequest{AuthorPatterns: []string{"a@a.com"}}, []string{c3, c2, c1}},
+		{"committer", protocol.CommitSearchRequest{CommitterPatterns: []string{"^x"}}, nil},
+		{"diff", protocol.CommitSearchRequest{DiffPattern: "ba"}, []string{c3, c2}},
+		{"diff removed line", protocol.CommitSearchRequest{DiffPattern: "foo"}, []string{c3, c1}},
+		{"diff literal", protocol.CommitSearchRequest{DiffPattern: "b.r"}, nil},
+		{"diff regexp", protocol.CommitSearchRequest{DiffPattern: "b.r", DiffPatternIsRegExp: true}, []string{c2}},
+		{"path", protocol.CommitSearchRequest{IncludePatterns: []string{`\.go$`}, PathPatternsAreRegExps: true}, []string{c3, c1}},
+		{"path and diff", protocol.CommitSearchRequest{DiffPattern: "ba", IncludePatterns: []string{`\.go$`}, PathPatternsAreRegExps: true}, []string{c3}},
+		{"exclude path", protocol.CommitSearchRequest{ExcludePattern: `\.go$`, PathPatternsAreRegExps: true}, []string{c2}},
+		{"after", protocol.CommitSearchRequest{After: []string{"2020-01-02T12:00:00Z"}}, nil},
+		{"before", protocol.CommitSearchRequest{Before: []string{"2020-01-03T00:00:00Z"}}, []string{c3, c2, c1}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := search(test.req); !reflect.DeepEqual(got, test.want) {
+				t.Errorf("got %v, want %v", got, test.want)
+			}
+		})
+	}
+
+	// Refs and source refs are reported like `git log --decorate --source`.
+	cmd("git", "tag", "-a", "v1", "-m", "v1", c2)
+	resp, err := s.commitSearch(ctx, &protocol.CommitSearchRequest{Repo: "repo", Limit: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := resp.Results[1].Refs, []string{"refs/tags/v1"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got refs %v, want %v", got, want)
+	}
+	if got := resp.Results[0].Refs; len(got) != 0 {
+		t.Errorf("got refs %v for HEAD, want none", got)
+	}
+	if got, want := resp.Results[0].SourceRefs, []string{"refs/heads/main"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got source refs %v, want %v", got, want)
+	}
+	if got := resp.Results[0]; got.Author.Name != "a" || got.Committer.Email != "c@c.com" || got.Message != "change a" || !reflect.DeepEqual(got.Parents, []api.CommitID{api.CommitID(c2)}) {
+		t.Errorf("unexpected commit %+v", got)
+	}
+
+	// Rewritten history rebuilds the index.
+	cmd("git", "reset", "--hard", c1)
+	c4 := commit("c.txt", "qux", "add c", "2020-01-04T00:00:00Z")
+	if err := updateCommitIndex(ctx, dir); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := search(protocol.CommitSearchRequest{}), []string{c4, c1}; !reflect.DeepEqual(got, want) {
+		t.Errorf("after rebuild: got %v, want %v", got, want)
+	}
+
+	// A missing index file rebuilds the index, e.g. when the repository was
+	// cloned again while its index was being updated.
+	state, err = readCommitIndexState(dir)
+	if err != nil || state == nil {
+		t.Fatalf("got state %v, error %v", state, err)
+	}
+	if err := os.Remove(dir.Path(state.File)); err != nil {
+		t.Fatal(err)
+	}
+	if err := updateCommitIndex(ctx, dir); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := search(protocol.CommitSearchRequest{}), []string{c4, c1}; !reflect.DeepEqual(got, want) {
+		t.Errorf("after missing index file: got %v, want %v", got, want)
+	}
+
+	// Updates after fetches run in the background.
+	c5 := commit("d.txt", "quux", "add d", "2020-01-05T00:00:00Z")
+	s.ctx, s.cancel = context.WithCancel(context.Background())
+	defer s.cancel()
+	s.updateCommitIndexAsync("repo", dir)
+	s.wg.Wait()
+	if got, want := search(protocol.CommitSearchRequest{}), []string{c5, c4, c1}; !reflect.DeepEqual(got, want) {
+		t.Errorf("after background update: got %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,89 @@
+package server
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/sourcegraph/sourcegraph/internal/gitserver/protocol"
+)
+
+// crossRepoScratchDir creates a temporary repository in which git commands
+// can use the objects of the repository at dir as well as base.Commit, a
+// commit of another repository. This is used to compare the commits of two
+// repositories, such as a fork and its upstream.
+//
+// 🚨 SECURITY: The base commit is never written to the repository at dir,
+// because its objects would then be served to everybody who can read that
+// repository, including users who cannot read the base repository.
+//
+// The temporary repository has no refs, so commands must refer to commits by
+// their IDs. The returned function removes the temporary repository.
+func (s *Server) crossRepoScratchDir(ctx context.Context, dir GitDir, base *protocol.CrossRepoBase) (_ GitDir, cleanup func(), err error) {
+	if !isAbsoluteRevision(string(base.Commit)) {
+		return "", nil, errors.New("cross-repo: base commit must be a 40-character commit ID")
+	}
+
+	tmp, err := s.tempDir("cross-repo-")
+	if err != nil {
+		return "", nil, errors.Wrap(err, "cross-repo: creating temporary repository")
+	}
+	cleanup = func() { _ = os.RemoveAll(tmp) }
+	defer func() {
+		if err != nil {
+			cleanup()
+		}
+	}()
+
+	scratch := GitDir(tmp)
+	cmd := exec.CommandContext(ctx, "git", "init", "--bare", "--quiet", ".")
+	cmd.Dir = tmp
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return "", nil, errors.Errorf("cross-repo: creating temporary repository failed: %s: %s", err, output)
+	}
+
+	// Use the objects of both repositories in place if this gitserver has
+	// cloned the base repository, and fetch the base commit into the
+	// temporary repository from the base repository's remote otherwise.
+	alternates := []string{dir.Path("objects")}
+	baseDir := s.dir(base.Repo)
+	if repoCloned(baseDir) {
+		alternates = append(alternates, baseDir.Path("objects"))
+	}
+	if err := ioutil.WriteFile(scratch.Path("objects", "info", "alternates"), []byte(strings.Join(alternates, "\n")+"\n"), 0600); err != nil {
+		return "", nil, errors.Wrap(err, "cross-repo: writing alternates")
+	}
+
+	if !commitExists(ctx, scratch, string(base.Commit)) {
+		info, err := gitserverClient.RepoInfo(ctx, base.Repo)
+		if err != nil {
+			return "", nil, errors.Wrapf(err, "cross-repo: getting remote URL of %q", base.Repo)
+		}
+		source, ok := info.Results[base.Repo]
+		if !ok || !source.Cloned || source.URL == "" {
+			return "", nil, errors.Errorf("cross-repo: repo %q is not cloned", base.Repo)
+		}
+
+		cmd := exec.CommandContext(ctx, "git", "fetch", "--no-tags", source.URL, string(base.Commit))
+		cmd.Dir = tmp
+		if output, err := runWith(ctx, cmd, true, nil); err != nil {
+			redactor := newURLRedactor(source.URL)
+			return "", nil, errors.Errorf("cross-repo: fetching %s from %q failed: %s: %s", base.Commit, base.Repo, redactor.redact(err.Error()), redactor.redact(string(output)))
+		}
+		if !commitExists(ctx, scratch, string(base.Commit)) {
+			return "", nil, errors.Errorf("cross-repo: commit %s not found in %q", base.Commit, base.Repo)
+		}
+	}
+
+	return scratch, cleanup, nil
+}
+
+// commitExists returns whether the repository at dir contains the commit.
+func commitExists(ctx context.Context, dir GitDir, commit string) bool {
+	cmd := exec.CommandContext(ctx, "git", "cat-file", "-e", commit+"^{commit}")
+	cmd.Dir = string(dir)
+	return cmd.Run() == nil
+}
@@ -0,0 +1,78 @@
+package server
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/gitserver/protocol"
+)
+
+func TestCrossRepoScratchDir(t *testing.T) {
+	root, cleanup := tmpDir(t)
+	defer cleanup()
+	s := &Server{ReposDir: root}
+	ctx := context.Background()
+
+	cmd := func(dir, name string, arg ...string) string {
+		t.Helper()
+		c := exec.Command(name, arg...)
+		c.Dir = filepath.Join(root, dir)
+		c.Env = append(os.Environ(),
+			"GIT_COMMITTER_NAME=c",
+			"GIT_COMMITTER_EMAIL=c@c.com",
+			"GIT_AUTHOR_NAME=a",
+			"GIT_AUTHOR_EMAIL=a@a.com",
+		)
+		b, err := c.CombinedOutput()
+		if err != nil {
+			t.Fatalf("%s %s failed: %s: %s", name, strings.Join(arg, " "), err, b)
+		}
+		return strings.TrimSpace(string(b))
+	}
+
+	if err := os.MkdirAll(filepath.Join(root, "upstream"), 0700); err != nil {
+		t.Fatal(err)
+	}
+	cmd("upstream", "git", "init", ".")
+	cmd("upstream", "git", "commit", "--allow-empty", "-m", "base")
+	cmd("", "git", "clone", "upstream", "fork")
+	cmd("fork", "git", "commit", "--allow-empty", "-m", "fork")
+	forkCommit := cmd("fork", "git", "rev-parse", "HEAD")
+	cmd("upstream", "git", "commit", "--allow-empty", "-m", "upstream")
+	upstreamCommit := cmd("upstream", "git", "rev-parse", "HEAD")
+
+	fork := s.dir("fork")
+	scratch, cleanupScratch, err := s.crossRepoScratchDir(ctx, fork, &protocol.CrossRepoBase{Repo: "upstream", Commit: api.CommitID(upstreamCommit)})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Commands in the scratch repository can use the commits of both
+	// repositories.
+	rel, err := filepath.Rel(root, string(scratch))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if log := cmd(rel, "git", "log", "--format=%s", upstreamCommit+".."+forkCommit); log != "fork" {
+		t.Errorf("got log %q, want fork commit", log)
+	}
+
+	// 🚨 SECURITY: The upstream commit must not be written to the fork.
+	if commitExists(ctx, fork, upstreamCommit) {
+		t.Error("upstream commit was written to the fork")
+	}
+
+	cleanupScratch()
+	if _, err := os.Stat(string(scratch)); !os.IsNotExist(err) {
+		t.Errorf("scratch repository was not removed: %v", err)
+	}
+
+	if _, _, err := s.crossRepoScratchDir(ctx, fork, &protocol.CrossRepoBase{Repo: "upstream", Commit: "HEAD"}); err == nil {
+		t.Error("expected error for base commit that is not a commit ID")
+	}
+}
@@ -12,6 +12,11 @@ func (s *Server) handleListGitolite(w http.ResponseWriter, r *http.Request) {
 	defaultGitolite.listRepos(r.Context(), r.URL.Query().Get("gitolite"), w)
 }
 
+func (s *Server) handleGitoliteAccess(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	defaultGitolite.readableRepos(r.Context(), q.Get("gitolite"), q.Get("user"), w)
+}
+
 var defaultGitolite = gitoliteFetcher{client: gitoliteClient{}}
 
 type gitoliteFetcher struct {
@@ -20,6 +25,7 @@ type gitoliteFetcher struct {
 
 type iGitoliteClient interface {
 	ListRepos(ctx context.Context, host string) ([]*gitolite.Repo, error)
+	ReadableRepos(ctx context.Context, host, user string, repos []string) ([]string, error)
 }
 
 // listRepos lists the repos of a Gitolite server reachable at the address in gitoliteHost
@@ -42,8 +48,46 @@ func (g gitoliteFetcher) listRepos(ctx context.Context, gitoliteHost string, w h
 	}
 }
 
+// readableRepos lists the names of the repos of a Gitolite server reachable at the
+// address in gitoliteHost that the given Gitolite user can read
+func (g gitoliteFetcher) readableRepos(ctx context.Context, gitoliteHost, user string, w http.ResponseWriter) {
+	if gitoliteHost == "" || user == "" {
+		http.Error(w, "gitolite and user are required", http.StatusBadRequest)
+		return
+	}
+
+	repos, err := g.client.ListRepos(ctx, gitoliteHost)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	names := make([]string, 0, len(repos))
+	for _, r := range repos {
+		names = append(names, r.Name)
+	}
+
+	readable, err := g.client.ReadableRepos(ctx, gitoliteHost, user, names)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if readable == nil {
+		readable = []string{}
+	}
+
+	if err = json.NewEncoder(w).Encode(readable); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
 type gitoliteClient struct{}
 
 func (c gitoliteClient) ListRepos(ctx context.Context, host string) ([]*gitolite.Repo, error) {
 	return gitolite.NewClient(host).ListRepos(ctx)
 }
+
+func (c gitoliteClient) ReadableRepos(ctx context.Context, host, user string, repos []string) ([]string, error) {
+	return gitolite.NewClient(host).ReadableRepos(ctx, user, repos)
+}
@@ -63,10 +63,91 @@ func Test_Gitolite_listRepos(t *testing.T) {
 	}
 }
 
+func Test_Gitolite_readableRepos(t *testing.T) {
+	listRepos := map[string][]*gitolite.Repo{
+		"git@gitolite.example.com": {
+			{Name: "public", URL: "git@gitolite.example.com:public"},
+			{Name: "secret", URL: "git@gitolite.example.com:secret"},
+		},
+	}
+	access := map[string][]string{
+		"alice": {"public", "secret"},
+		"bob":   {"public"},
+	}
+
+	tests := []struct {
+		gitoliteHost    string
+		user            string
+		expResponseCode int
+		expResponseBody string
+	}{
+		{
+			gitoliteHost:    "git@gitolite.example.com",
+			user:            "alice",
+			expResponseCode: 200,
+			expResponseBody: `["public","secret"]` + "\n",
+		},
+		{
+			gitoliteHost:    "git@gitolite.example.com",
+			user:            "bob",
+			expResponseCode: 200,
+			expResponseBody: `["public"]` + "\n",
+		},
+		{
+			gitoliteHost:    "git@gitolite.example.com",
+			user:            "eve",
+			expResponseCode: 200,
+			expResponseBody: `[]` + "\n",
+		},
+		{
+			gitoliteHost:    "git@gitolite.example.com",
+			user:            "",
+			expResponseCode: 400,
+			expResponseBody: "gitolite and user are required\n",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.user, func(t *testing.T) {
+			g := gitoliteFetcher{
+				client: stubGitoliteClient{
+					ListRepos_: func(ctx context.Context, host string) ([]*gitolite.Repo, error) {
+						return listRepos[host], nil
+					},
+					ReadableRepos_: func(ctx context.Context, host, user string, repos []string) ([]string, error) {
+						if diff := cmp.Diff([]string{"public", "secret"}, repos); diff != "" {
+							t.Errorf("unexpected repos diff:\n%s", diff)
+						}
+						return access[user], nil
+					},
+				},
+			}
+			w := httptest.NewRecorder()
+			g.readableRepos(context.Background(), test.gitoliteHost, test.user, w)
+			resp := w.Result()
+			respBody, err := ioutil.ReadAll(resp.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(test.expResponseBody, string(respBody)); diff != "" {
+				t.Errorf("unexpected response body diff:\n%s", diff)
+			}
+			if diff := cmp.Diff(test.expResponseCode, resp.StatusCode); diff != "" {
+				t.Errorf("unexpected response code diff:\n%s", diff)
+			}
+		})
+	}
+}
+
 type stubGitoliteClient struct {
-	ListRepos_ func(ctx context.Context, host string) ([]*gitolite.Repo, error)
+	ListRepos_     func(ctx context.Context, host string) ([]*gitolite.Repo, error)
+	ReadableRepos_ func(ctx context.Context, host, user string, repos []string) ([]string, error)
 }
 
 func (c stubGitoliteClient) ListRepos(ctx context.Context, host string) ([]*gitolite.Repo, error) {
 	return c.ListRepos_(ctx, host)
 }
+
+func (c stubGitoliteClient) ReadableRepos(ctx context.Context, host, user string, repos []string) ([]string, error) {
+	return c.ReadableRepos_(ctx, host, user, repos)
+}
@@ -0,0 +1,141 @@
+package server
+
+import (
+	"context"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/inconshreveable/log15"
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/gitserver"
+)
+
+// replicaSyncInterval is how often replicas are compared with their primary.
+const replicaSyncInterval = 10 * time.Minute
+
+// gitserverClient is the client used to find the replicas of a repository and
+// to query their primary. It is a variable so tests can replace it.
+var gitserverClient = gitserver.DefaultClient
+
+// syncReplicas updates the repositories this gitserver holds a replica of
+// whose primary gitserver fetched them more recently. Replicas receive the
+// same repo-update requests as their primary, so this only catches up
+// replicas that missed updates, e.g. because they were unavailable.
+func (s *Server) syncReplicas() {
+	if (s.Hostname == "" && s.Addr == "") || time.Since(s.lastReplicaSync) < replicaSyncInterval {
+		return
+	}
+	s.lastReplicaSync = time.Now()
+
+	ctx, cancel := s.serverContext()
+	defer cancel()
+
+	addrs := gitserverClient.Addrs(ctx)
+	if len(addrs) == 0 {
+		return
+	}
+	if !s.hasAddr(addrs) {
+		if gitserverClient.ReplicationFactor != nil && gitserverClient.ReplicationFactor() > 1 {
+			log15.Warn("cleanup: not syncing replicas, because no gitserver address matches this gitserver (set SRC_GITSERVER_ADDR to its address in SRC_GIT_SERVERS)", "hostname", s.Hostname, "addr", s.Addr, "addrs", addrs)
+		}
+		return
+	}
+
+	gitDirs, err := s.findGitDirs()
+	if err != nil {
+		log15.Error("cleanup: error finding replicas", "error", err)
+		return
+	}
+
+	var repos []api.RepoName
+	for _, dir := range gitDirs {
+		repo := s.name(dir)
+		if s.isSecondaryReplica(gitserverClient.ReplicaAddrsForRepo(ctx, repo)) {
+			repos = append(repos, repo)
+		}
+	}
+	if len(repos) == 0 {
+		return
+	}
+
+	// RepoInfo may return partial results along with an error, so the repos
+	// it did return are still synced.
+	info, err := gitserverClient.RepoInfo(ctx, repos...)
+	if err != nil {
+		log15.Warn("cleanup: error getting replica info from primary", "error", err)
+	}
+	if info == nil {
+		return
+	}
+
+	for _, repo := range repos {
+		primary, ok := info.Results[repo]
+		if !ok || !primary.Cloned || primary.LastFetched == nil {
+			continue
+		}
+
+		dir := s.dir(repo)
+		if lastFetched, err := repoLastFetched(dir); err == nil && !primary.LastFetched.After(lastFetched) {
+			continue
+		}
+
+		if err := s.syncReplica(ctx, repo, dir); err != nil {
+			log15.Error("cleanup: error updating replica", "repo", repo, "error", err)
+		}
+	}
+}
+
+// syncReplica fetches the given repository from its remote.
+func (s *Server) syncReplica(ctx context.Context, repo api.RepoName, dir GitDir) error {
+	ctx, cancel := context.WithTimeout(ctx, longGitCommandTimeout)
+	defer cancel()
+
+	remoteURL, err := repoRemoteURL(ctx, dir)
+	if err != nil {
+		return err
+	}
+
+	log15.Info("updating stale replica", "repo", repo)
+	return s.doRepoUpdate(ctx, repo, remoteURL)
+}
+
+// isSecondaryReplica reports whether this gitserver is one of the given
+// replicas, but not the primary.
+func (s *Server) isSecondaryReplica(replicas []string) bool {
+	if len(replicas) < 2 || s.isAddr(replicas[0]) {
+		return false
+	}
+	return s.hasAddr(replicas[1:])
+}
+
+// hasAddr reports whether any of the given gitserver addresses is this
+// gitserver's address.
+func (s *Server) hasAddr(addrs []string) bool {
+	for _, addr := range addrs {
+		if s.isAddr(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// isAddr reports whether the gitserver address addr is this gitserver's
+// address: s.Addr if it is set, or else an address whose host is s.Hostname.
+func (s *Server) isAddr(addr string) bool {
+	if s.Addr != "" {
+		return addr == s.Addr
+	}
+	return addrHasHostname(addr, s.Hostname)
+}
+
+// addrHasHostname reports whether the gitserver address addr (such as
+// "gitserver-1.gitserver:3178") refers to the host with the given hostname
+// (such as "gitserver-1").
+func addrHasHostname(addr, hostname string) bool {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		host = addr
+	}
+	return host == hostname || strings.HasPrefix(host, hostname+".")
+}
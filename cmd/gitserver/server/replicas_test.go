@@ -0,0 +1,47 @@
+package server
+
+import "testing"
+
+func TestIsSecondaryReplica(t *testing.T) {
+	s := &Server{Hostname: "gitserver-1"}
+
+	tests := []struct {
+		replicas []string
+		want     bool
+	}{
+		{[]string{"gitserver-1.gitserver:3178"}, false},
+		{[]string{"gitserver-1.gitserver:3178", "gitserver-2.gitserver:3178"}, false},
+		{[]string{"gitserver-0.gitserver:3178", "gitserver-1.gitserver:3178"}, true},
+		{[]string{"gitserver-0:3178", "gitserver-1:3178"}, true},
+		{[]string{"gitserver-0.gitserver:3178", "gitserver-10.gitserver:3178"}, false},
+		{[]string{"gitserver-0.gitserver:3178", "gitserver-2.gitserver:3178"}, false},
+	}
+	for _, test := range tests {
+		if got := s.isSecondaryReplica(test.replicas); got != test.want {
+			t.Errorf("isSecondaryReplica(%v) = %v, want %v", test.replicas, got, test.want)
+		}
+	}
+}
+
+func TestIsSecondaryReplica_addr(t *testing.T) {
+	// The addresses don't contain the hostname, e.g. with docker-compose.
+	s := &Server{Hostname: "4f2a1c9e7b3d", Addr: "10.0.0.2:3178"}
+
+	tests := []struct {
+		replicas []string
+		want     bool
+	}{
+		{[]string{"10.0.0.2:3178", "10.0.0.3:3178"}, false},
+		{[]string{"10.0.0.1:3178", "10.0.0.2:3178"}, true},
+		{[]string{"10.0.0.1:3178", "10.0.0.20:3178"}, false},
+	}
+	for _, test := range tests {
+		if got := s.isSecondaryReplica(test.replicas); got != test.want {
+			t.Errorf("isSecondaryReplica(%v) = %v, want %v", test.replicas, got, test.want)
+		}
+	}
+
+	if (&Server{Hostname: "4f2a1c9e7b3d"}).hasAddr([]string{"10.0.0.1:3178", "10.0.0.2:3178"}) {
+		t.Error("want no address to match the hostname")
+	}
+}
@@ -116,6 +116,22 @@ type Server struct {
 	// DiskSizer tells how much disk is free and how large the disk is.
 	DiskSizer DiskSizer
 
+	// Hostname is the hostname of this gitserver. When repositories are
+	// replicated, it identifies the repositories this gitserver holds a
+	// replica of, which the Janitor job keeps in sync with their primary.
+	Hostname string
+
+	// Addr is the address of this gitserver in the list of gitserver
+	// addresses. If set, it is used instead of Hostname to identify this
+	// gitserver, e.g. when the addresses are IP addresses.
+	Addr string
+
+	// lastReplicaSync is when the Janitor job last synced replicas.
+	lastReplicaSync time.Time
+
+	// commitIndexUpdates tracks the background updates of commit indexes.
+	commitIndexUpdates commitIndexUpdates
+
 	// skipCloneForTests is set by tests to avoid clones.
 	skipCloneForTests bool
 
@@ -227,6 +243,7 @@ func (s *Server) Handler() http.Handler {
 	mux.HandleFunc("/exec", s.handleExec)
 	mux.HandleFunc("/list", s.handleList)
 	mux.HandleFunc("/list-gitolite", s.handleListGitolite)
+	mux.HandleFunc("/gitolite-access", s.handleGitoliteAccess)
 	mux.HandleFunc("/is-repo-cloneable", s.handleIsRepoCloneable)
 	mux.HandleFunc("/is-repo-cloned", s.handleIsRepoCloned)
 	mux.HandleFunc("/repos", s.handleRepoInfo)
@@ -235,6 +252,7 @@ func (s *Server) Handler() http.Handler {
 	mux.HandleFunc("/repo-update", s.handleRepoUpdate)
 	mux.HandleFunc("/getGitolitePhabricatorMetadata", s.handleGetGitolitePhabricatorMetadata)
 	mux.HandleFunc("/create-commit-from-patch", s.handleCreateCommitFromPatch)
+	mux.HandleFunc("/commit-search", s.handleCommitSearch)
 	mux.HandleFunc("/ping", func(w http.ResponseWriter, _ *http.Request) {
 		w.WriteHeader(http.StatusOK)
 	})
@@ -643,11 +661,25 @@ func (s *Server) exec(w http.ResponseWriter, r *http.Request, req *protocol.Exec
 		return
 	}
 
-	didUpdate := s.ensureRevision(ctx, req.Repo, req.URL, req.EnsureRevision, dir)
-	if didUpdate {
-		ensureRevisionStatus = "fetched"
-	} else {
+	cmdDir := dir
+	if req.CrossRepoBase != nil {
+		scratch, cleanup, err := s.crossRepoScratchDir(ctx, dir, req.CrossRepoBase)
+		if err != nil {
+			status = "cross-repo-failed"
+			execErr = err
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		defer cleanup()
+		cmdDir = scratch
 		ensureRevisionStatus = "noop"
+	} else {
+		didUpdate := s.ensureRevision(ctx, req.Repo, req.URL, req.EnsureRevision, dir)
+		if didUpdate {
+			ensureRevisionStatus = "fetched"
+		} else {
+			ensureRevisionStatus = "noop"
+		}
 	}
 
 	w.Header().Set("Trailer", "X-Exec-Error")
@@ -658,7 +690,7 @@ func (s *Server) exec(w http.ResponseWriter, r *http.Request, req *protocol.Exec
 	// Special-case `git rev-parse HEAD` requests. These are invoked by search queries for every repo in scope.
 	// For searches over large repo sets (> 1k), this leads to too many child process execs, which can lead
 	// to a persistent failure mode where every exec takes > 10s, which is disastrous for gitserver performance.
-	if len(req.Args) == 2 && req.Args[0] == "rev-parse" && req.Args[1] == "HEAD" {
+	if len(req.Args) == 2 && req.Args[0] == "rev-parse" && req.Args[1] == "HEAD" && req.CrossRepoBase == nil {
 		if resolved, err := quickRevParseHead(dir); err == nil && isAbsoluteRevision(resolved) {
 			_, _ = w.Write([]byte(resolved))
 			w.Header().Set("X-Exec-Error", "")
@@ -674,7 +706,7 @@ func (s *Server) exec(w http.ResponseWriter, r *http.Request, req *protocol.Exec
 
 	cmdStart = time.Now()
 	cmd := exec.CommandContext(ctx, "git", req.Args...)
-	cmd.Dir = string(dir)
+	cmd.Dir = string(cmdDir)
 	cmd.Stdout = stdoutW
 	cmd.Stderr = stderrW
 
@@ -685,7 +717,11 @@ func (s *Server) exec(w http.ResponseWriter, r *http.Request, req *protocol.Exec
 	stderrN = stderrW.n
 
 	stderr := stderrBuf.String()
-	checkMaybeCorruptRepo(req.Repo, dir, stderr)
+	if req.CrossRepoBase == nil {
+		// Errors in a cross-repository command may be caused by the base
+		// repository.
+		checkMaybeCorruptRepo(req.Repo, dir, stderr)
+	}
 
 	// write trailer
 	w.Header().Set("X-Exec-Error", errorString(execErr))
@@ -859,6 +895,8 @@ func (s *Server) cloneRepo(ctx context.Context, repo api.RepoName, url string, o
 		log15.Info("repo cloned", "repo", repo)
 		repoClonedCounter.Inc()
 
+		s.updateCommitIndexAsync(repo, dir)
+
 		return nil
 	}
 
@@ -1377,6 +1415,8 @@ func (s *Server) doRepoUpdate2(repo api.RepoName, url string) error {
 		log15.Error("Failed to set HEAD", "repo", repo, "error", err, "output", string(output))
 		return errors.Wrap(err, "Failed to set HEAD")
 	}
+
+	s.updateCommitIndexAsync(repo, dir)
 	return nil
 }
 
@@ -10,6 +10,12 @@ import (
 var (
 	rawBundleManagerURL = env.Get("PRECISE_CODE_INTEL_BUNDLE_MANAGER_URL", "", "HTTP address for internal LSIF bundle manager server.")
 	rawJanitorInterval  = env.Get("PRECISE_CODE_INTEL_JANITOR_INTERVAL", "1m", "Interval between cleanup runs.")
+	rawIndexerInterval  = env.Get("PRECISE_CODE_INTEL_INDEXER_INTERVAL", "10s", "Interval between moniker reference indexing runs.")
+
+	rawCommitGraphUpdaterInterval = env.Get("PRECISE_CODE_INTEL_COMMIT_GRAPH_UPDATER_INTERVAL", "10s", "Interval between runs updating the commit graphs of repositories with LSIF uploads.")
+
+	rawAutoIndexingSchedulerInterval = env.Get("PRECISE_CODE_INTEL_AUTO_INDEXING_SCHEDULER_INTERVAL", "1m", "Interval between runs scheduling index jobs for repositories configured for auto-indexing.")
+	rawAutoIndexingWorkerInterval    = env.Get("PRECISE_CODE_INTEL_AUTO_INDEXING_WORKER_INTERVAL", "10s", "Interval between polls of the index job queue when it is empty.")
 )
 
 // mustGet returns the non-empty version of the given raw value fatally logs on failure.
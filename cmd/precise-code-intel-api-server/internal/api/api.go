@@ -23,6 +23,11 @@ type CodeIntelAPI interface {
 	// This may include references from other dumps and repositories.
 	References(ctx context.Context, repositoryID int, commit string, limit int, cursor Cursor) ([]ResolvedLocation, Cursor, bool, error)
 
+	// EstimateReferenceCount returns an estimate of the total number of references to the symbol described
+	// by the given initial cursor. The estimate is computed from the moniker reference index and includes
+	// references from other repositories.
+	EstimateReferenceCount(ctx context.Context, cursor Cursor) (int, error)
+
 	// Hover returns the hover text and range for the symbol at the given position.
 	Hover(ctx context.Context, file string, line, character, uploadID int) (string, bundles.Range, bool, error)
 }
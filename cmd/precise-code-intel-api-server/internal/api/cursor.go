@@ -19,15 +19,16 @@ type Cursor struct {
 	Character              int                   // same-dump
 	Monikers               []bundles.MonikerData // same-dump/definition-monikers
 	SkipResults            int                   // same-dump/definition-monikers
-	Identifier             string                // same-repo/remote-repo
-	Scheme                 string                // same-repo/remote-repo
-	Name                   string                // same-repo/remote-repo
-	Version                string                // same-repo/remote-repo
-	DumpIDs                []int                 // same-repo/remote-repo
+	Identifier             string                // same-repo/remote-repo-indexed/remote-repo
+	Scheme                 string                // same-repo/remote-repo-indexed/remote-repo
+	Name                   string                // same-repo/remote-repo-indexed/remote-repo
+	Version                string                // same-repo/remote-repo-indexed/remote-repo
+	DumpIDs                []int                 // same-repo/remote-repo-indexed/remote-repo
 	TotalDumpsWhenBatching int                   // same-repo/remote-repo
 	SkipDumpsWhenBatching  int                   // same-repo/remote-repo
-	SkipDumpsInBatch       int                   // same-repo/remote-repo
-	SkipResultsInDump      int                   // same-repo/remote-repo
+	SkipDumpsInBatch       int                   // same-repo/remote-repo-indexed/remote-repo
+	SkipResultsInDump      int                   // same-repo/remote-repo-indexed/remote-repo
+	AfterDumpID            int                   // remote-repo-indexed
 }
 
 // EncodeCursor returns an encoding of the given cursor suitable for a URL.
@@ -128,6 +128,30 @@ func setMockDBPackageReferencePager(t *testing.T, mockDB *mocks.MockDB, expected
 	})
 }
 
+func setMockDBMonikerReferenceDumpIDs(t *testing.T, mockDB *mocks.MockDB, expectedScheme, expectedIdentifier, expectedName, expectedVersion string, expectedRepositoryID, expectedLimit int, batches map[int][]int) {
+	mockDB.MonikerReferenceDumpIDsFunc.SetDefaultHook(func(ctx context.Context, scheme, identifier, name, version string, repositoryID, afterDumpID, limit int) ([]int, error) {
+		if scheme != expectedScheme {
+			t.Errorf("unexpected scheme for MonikerReferenceDumpIDs. want=%s have=%s", expectedScheme, scheme)
+		}
+		if identifier != expectedIdentifier {
+			t.Errorf("unexpected identifier for MonikerReferenceDumpIDs. want=%s have=%s", expectedIdentifier, identifier)
+		}
+		if name != expectedName {
+			t.Errorf("unexpected name for MonikerReferenceDumpIDs. want=%s have=%s", expectedName, name)
+		}
+		if version != expectedVersion {
+			t.Errorf("unexpected version for MonikerReferenceDumpIDs. want=%s have=%s", expectedVersion, version)
+		}
+		if repositoryID != expectedRepositoryID {
+			t.Errorf("unexpected repository id for MonikerReferenceDumpIDs. want=%d have=%d", expectedRepositoryID, repositoryID)
+		}
+		if limit != expectedLimit {
+			t.Errorf("unexpected limit for MonikerReferenceDumpIDs. want=%d have=%d", expectedLimit, limit)
+		}
+		return batches[afterDumpID], nil
+	})
+}
+
 func setMockReferencePagerPageFromOffset(t *testing.T, mockReferencePager *mocks.MockReferencePager, expectedOffset int, references []db.Reference) {
 	mockReferencePager.PageFromOffsetFunc.SetDefaultHook(func(offset int) ([]db.Reference, error) {
 		if offset != expectedOffset {
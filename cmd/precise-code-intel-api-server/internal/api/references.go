@@ -8,6 +8,10 @@ import (
 	"github.com/sourcegraph/sourcegraph/internal/codeintel/db"
 )
 
+// DefaultReferencesRemoteDumpLimit is the number of remote dumps whose references are
+// resolved in a single batch.
+const DefaultReferencesRemoteDumpLimit = 20
+
 // References returns the list of source locations that reference the symbol at the given position.
 // This may include references from other dumps and repositories.
 func (api *codeIntelAPI) References(ctx context.Context, repositoryID int, commit string, limit int, cursor Cursor) ([]ResolvedLocation, Cursor, bool, error) {
@@ -16,12 +20,35 @@ func (api *codeIntelAPI) References(ctx context.Context, repositoryID int, commi
 		bundleManagerClient: api.bundleManagerClient,
 		repositoryID:        repositoryID,
 		commit:              commit,
+		remoteDumpLimit:     DefaultReferencesRemoteDumpLimit,
 		limit:               limit,
 	}
 
 	return rpr.resolvePage(ctx, cursor)
 }
 
+// EstimateReferenceCount returns an estimate of the total number of references to the symbol described
+// by the given initial cursor. The estimate is computed from the moniker reference index and includes
+// references from other repositories.
+func (api *codeIntelAPI) EstimateReferenceCount(ctx context.Context, cursor Cursor) (int, error) {
+	// Use the same moniker that drives the same-repo and remote-repo phases
+	// (see handleDefinitionMonikersCursor).
+	for _, moniker := range cursor.Monikers {
+		if moniker.PackageInformationID == "" {
+			continue
+		}
+
+		packageInformation, err := api.bundleManagerClient.BundleClient(cursor.DumpID).PackageInformation(ctx, cursor.Path, moniker.PackageInformationID)
+		if err != nil {
+			return 0, err
+		}
+
+		return api.db.MonikerReferenceCount(ctx, moniker.Scheme, moniker.Identifier, packageInformation.Name, packageInformation.Version)
+	}
+
+	return 0, nil
+}
+
 type ReferencePageResolver struct {
 	db                  db.DB
 	bundleManagerClient bundles.BundleManagerClient
@@ -56,6 +83,7 @@ func (s *ReferencePageResolver) dispatchCursorHandler(ctx context.Context, curso
 		"same-dump":           s.handleSameDumpCursor,
 		"definition-monikers": s.handleDefinitionMonikersCursor,
 		"same-repo":           s.handleSameRepoCursor,
+		"remote-repo-indexed": s.handleRemoteRepoIndexedCursor,
 		"remote-repo":         s.handleRemoteRepoCursor,
 	}
 
@@ -211,7 +239,7 @@ func (s *ReferencePageResolver) handleSameRepoCursor(ctx context.Context, cursor
 
 	newCursor = Cursor{
 		DumpID:                 cursor.DumpID,
-		Phase:                  "remote-repo",
+		Phase:                  "remote-repo-indexed",
 		Scheme:                 cursor.Scheme,
 		Identifier:             cursor.Identifier,
 		Name:                   cursor.Name,
@@ -225,6 +253,87 @@ func (s *ReferencePageResolver) handleSameRepoCursor(ctx context.Context, cursor
 	return locations, newCursor, true, nil
 }
 
+// handleRemoteRepoIndexedCursor resolves references from remote dumps found via the moniker reference index.
+// Batches of dumps are fetched in order of their identifier, so the cursor remains stable when dumps are
+// added or removed between pages. Once the index is exhausted, the remaining remote dumps (those that have
+// not yet been indexed) are searched in the remote-repo phase.
+func (s *ReferencePageResolver) handleRemoteRepoIndexedCursor(ctx context.Context, cursor Cursor) ([]ResolvedLocation, Cursor, bool, error) {
+	if len(cursor.DumpIDs) == 0 {
+		dumpIDs, err := s.db.MonikerReferenceDumpIDs(ctx, cursor.Scheme, cursor.Identifier, cursor.Name, cursor.Version, s.repositoryID, cursor.AfterDumpID, s.remoteDumpLimit)
+		if err != nil {
+			return nil, Cursor{}, false, err
+		}
+
+		if len(dumpIDs) == 0 {
+			newCursor := Cursor{
+				DumpID:                 cursor.DumpID,
+				Phase:                  "remote-repo",
+				Scheme:                 cursor.Scheme,
+				Identifier:             cursor.Identifier,
+				Name:                   cursor.Name,
+				Version:                cursor.Version,
+				DumpIDs:                nil,
+				TotalDumpsWhenBatching: 0,
+				SkipDumpsWhenBatching:  0,
+				SkipDumpsInBatch:       0,
+				SkipResultsInDump:      0,
+			}
+			return nil, newCursor, true, nil
+		}
+
+		cursor.DumpIDs = dumpIDs
+		cursor.SkipDumpsInBatch = 0
+		cursor.SkipResultsInDump = 0
+	}
+
+	for i, batchDumpID := range cursor.DumpIDs {
+		if i < cursor.SkipDumpsInBatch {
+			continue
+		}
+
+		dump, exists, err := s.db.GetDumpByID(ctx, batchDumpID)
+		if err != nil {
+			return nil, Cursor{}, false, err
+		}
+		if !exists {
+			continue
+		}
+
+		results, count, err := s.bundleManagerClient.BundleClient(batchDumpID).MonikerResults(ctx, "reference", cursor.Scheme, cursor.Identifier, cursor.SkipResultsInDump, s.limit)
+		if err != nil {
+			return nil, Cursor{}, false, err
+		}
+		if len(results) == 0 {
+			continue
+		}
+		resolvedLocations := resolveLocationsWithDump(dump, results)
+
+		newCursor := cursor
+		if newResultOffset := cursor.SkipResultsInDump + len(results); newResultOffset < count {
+			newCursor.SkipResultsInDump = newResultOffset
+		} else if i+1 < len(cursor.DumpIDs) {
+			newCursor.SkipDumpsInBatch = i + 1
+			newCursor.SkipResultsInDump = 0
+		} else {
+			newCursor = nextMonikerReferenceBatch(cursor)
+		}
+
+		return resolvedLocations, newCursor, true, nil
+	}
+
+	return nil, nextMonikerReferenceBatch(cursor), true, nil
+}
+
+// nextMonikerReferenceBatch returns a cursor for the batch of indexed dumps following the current batch.
+func nextMonikerReferenceBatch(cursor Cursor) Cursor {
+	newCursor := cursor
+	newCursor.AfterDumpID = cursor.DumpIDs[len(cursor.DumpIDs)-1]
+	newCursor.DumpIDs = nil
+	newCursor.SkipDumpsInBatch = 0
+	newCursor.SkipResultsInDump = 0
+	return newCursor
+}
+
 func (s *ReferencePageResolver) handleRemoteRepoCursor(ctx context.Context, cursor Cursor) ([]ResolvedLocation, Cursor, bool, error) {
 	return s.resolveLocationsViaReferencePager(ctx, cursor, func(ctx context.Context) (int, db.ReferencePager, error) {
 		return s.db.PackageReferencePager(ctx, cursor.Scheme, cursor.Name, cursor.Version, s.repositoryID, s.remoteDumpLimit)
@@ -352,7 +352,7 @@ func TestHandleSameRepoCursor(t *testing.T) {
 		}
 
 		expectedNewCursor := Cursor{
-			Phase:      "remote-repo",
+			Phase:      "remote-repo-indexed",
 			DumpID:     42,
 			Scheme:     "gomod",
 			Identifier: "bar",
@@ -444,6 +444,125 @@ func TestHandleSameRepoCursorMultipleDumpBatches(t *testing.T) {
 //
 //
 
+func TestHandleRemoteRepoIndexedCursor(t *testing.T) {
+	mockDB := mocks.NewMockDB()
+	mockBundleManagerClient := mocks.NewMockBundleManagerClient()
+	mockBundleClient1 := mocks.NewMockBundleClient()
+	mockBundleClient2 := mocks.NewMockBundleClient()
+	mockBundleClient3 := mocks.NewMockBundleClient()
+
+	setMockDBGetDumpByID(t, mockDB, map[int]db.Dump{42: testDump1, 50: testDump2, 51: testDump3, 52: testDump4})
+	setMockBundleManagerClientBundleClient(t, mockBundleManagerClient, map[int]bundles.BundleClient{50: mockBundleClient1, 51: mockBundleClient2, 52: mockBundleClient3})
+	setMockDBMonikerReferenceDumpIDs(t, mockDB, "gomod", "bar", "leftpad", "0.1.0", 100, 2, map[int][]int{
+		0:  {50, 51},
+		51: {52},
+	})
+	setMockBundleClientMonikerResults(t, mockBundleClient1, "reference", "gomod", "bar", 0, 5, []bundles.Location{
+		{DumpID: 50, Path: "foo.go", Range: testRange1},
+		{DumpID: 50, Path: "bar.go", Range: testRange2},
+	}, 2)
+	setMockBundleClientMonikerResults(t, mockBundleClient2, "reference", "gomod", "bar", 0, 3, []bundles.Location{
+		{DumpID: 51, Path: "baz.go", Range: testRange3},
+		{DumpID: 51, Path: "bonk.go", Range: testRange4},
+	}, 2)
+	setMockBundleClientMonikerResults(t, mockBundleClient3, "reference", "gomod", "bar", 0, 1, []bundles.Location{
+		{DumpID: 52, Path: "quux.go", Range: testRange5},
+	}, 1)
+
+	rpr := &ReferencePageResolver{
+		db:                  mockDB,
+		bundleManagerClient: mockBundleManagerClient,
+		repositoryID:        100,
+		commit:              testCommit,
+		remoteDumpLimit:     2,
+		limit:               5,
+	}
+
+	references, newCursor, hasNewCursor, err := rpr.resolvePage(context.Background(), Cursor{
+		Phase:      "remote-repo-indexed",
+		DumpID:     42,
+		Scheme:     "gomod",
+		Identifier: "bar",
+		Name:       "leftpad",
+		Version:    "0.1.0",
+	})
+	if err != nil {
+		t.Fatalf("expected error getting references: %s", err)
+	}
+
+	expectedReferences := []ResolvedLocation{
+		{Dump: testDump2, Path: "sub2/foo.go", Range: testRange1},
+		{Dump: testDump2, Path: "sub2/bar.go", Range: testRange2},
+		{Dump: testDump3, Path: "sub3/baz.go", Range: testRange3},
+		{Dump: testDump3, Path: "sub3/bonk.go", Range: testRange4},
+		{Dump: testDump4, Path: "sub4/quux.go", Range: testRange5},
+	}
+	if diff := cmp.Diff(expectedReferences, references); diff != "" {
+		t.Errorf("unexpected references (-want +got):\n%s", diff)
+	}
+
+	expectedNewCursor := Cursor{
+		Phase:       "remote-repo-indexed",
+		DumpID:      42,
+		Scheme:      "gomod",
+		Identifier:  "bar",
+		Name:        "leftpad",
+		Version:     "0.1.0",
+		AfterDumpID: 52,
+	}
+	if !hasNewCursor {
+		t.Errorf("expected new cursor")
+	} else if diff := cmp.Diff(expectedNewCursor, newCursor); diff != "" {
+		t.Errorf("unexpected new cursor (-want +got):\n%s", diff)
+	}
+}
+
+func TestHandleRemoteRepoIndexedCursorExhausted(t *testing.T) {
+	mockDB := mocks.NewMockDB()
+	mockBundleManagerClient := mocks.NewMockBundleManagerClient()
+
+	setMockDBMonikerReferenceDumpIDs(t, mockDB, "gomod", "bar", "leftpad", "0.1.0", 100, 2, nil)
+
+	rpr := &ReferencePageResolver{
+		db:                  mockDB,
+		bundleManagerClient: mockBundleManagerClient,
+		repositoryID:        100,
+		commit:              testCommit,
+		remoteDumpLimit:     2,
+		limit:               5,
+	}
+
+	references, newCursor, hasNewCursor, err := rpr.dispatchCursorHandler(context.Background(), Cursor{
+		Phase:       "remote-repo-indexed",
+		DumpID:      42,
+		Scheme:      "gomod",
+		Identifier:  "bar",
+		Name:        "leftpad",
+		Version:     "0.1.0",
+		AfterDumpID: 52,
+	})
+	if err != nil {
+		t.Fatalf("expected error getting references: %s", err)
+	}
+	if len(references) != 0 {
+		t.Errorf("unexpected references: %v", references)
+	}
+
+	expectedNewCursor := Cursor{
+		Phase:      "remote-repo",
+		DumpID:     42,
+		Scheme:     "gomod",
+		Identifier: "bar",
+		Name:       "leftpad",
+		Version:    "0.1.0",
+	}
+	if !hasNewCursor {
+		t.Errorf("expected new cursor")
+	} else if diff := cmp.Diff(expectedNewCursor, newCursor); diff != "" {
+		t.Errorf("unexpected new cursor (-want +got):\n%s", diff)
+	}
+}
+
 func TestHandleRemoteRepoCursor(t *testing.T) {
 	mockDB := mocks.NewMockDB()
 	mockBundleManagerClient := mocks.NewMockBundleManagerClient()
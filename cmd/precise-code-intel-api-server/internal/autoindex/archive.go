@@ -0,0 +1,110 @@
+package autoindex
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/gitserver"
+)
+
+// fetchArchive returns a tar archive of the given repository at the given commit.
+func fetchArchive(ctx context.Context, name api.RepoName, commit string) (io.ReadCloser, error) {
+	return gitserver.DefaultClient.Archive(ctx, gitserver.Repo{Name: name}, gitserver.ArchiveOptions{
+		Treeish: commit,
+		Format:  "tar",
+	})
+}
+
+// untar extracts the regular files and directories of the given tar archive into the
+// given directory. Entries that would be written outside of the directory are rejected.
+func untar(r io.Reader, dir string) error {
+	tr := tar.NewReader(r)
+	for {
+		header, err := tr.Next()
+		if err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+
+		target := filepath.Join(dir, filepath.FromSlash(header.Name))
+		if target != dir && !strings.HasPrefix(target, dir+string(os.PathSeparator)) {
+			return fmt.Errorf("illegal path in archive: %s", header.Name)
+		}
+
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, os.ModePerm); err != nil {
+				return err
+			}
+
+		case tar.TypeReg:
+			if err := writeFile(target, tr, os.FileMode(header.Mode)&os.ModePerm); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// writeFile writes the content of the given reader to a new file at the given path.
+func writeFile(path string, r io.Reader, mode os.FileMode) (err error) {
+	if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, mode)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+	}()
+
+	_, err = io.Copy(f, r)
+	return err
+}
+
+// gzipFile writes a gzipped copy of the given file to a new temporary file. The returned
+// file is positioned at its beginning and must be closed and removed by the caller.
+func gzipFile(path string) (_ *os.File, err error) {
+	in, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer in.Close()
+
+	f, err := ioutil.TempFile("", "index-")
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err != nil {
+			f.Close()
+			os.Remove(f.Name())
+		}
+	}()
+
+	gzipWriter := gzip.NewWriter(f)
+	if _, err := io.Copy(gzipWriter, in); err != nil {
+		return nil, err
+	}
+	if err := gzipWriter.Close(); err != nil {
+		return nil, err
+	}
+	if _, err := f.Seek(0, 0); err != nil {
+		return nil, err
+	}
+
+	return f, nil
+}
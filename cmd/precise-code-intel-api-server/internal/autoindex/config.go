@@ -0,0 +1,57 @@
+package autoindex
+
+import (
+	"regexp"
+
+	"github.com/inconshreveable/log15"
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/conf"
+	"github.com/sourcegraph/sourcegraph/schema"
+)
+
+// DefaultMaxAttempts is the number of times an index job is attempted when the
+// site configuration does not set codeIntelAutoIndexing.maxAttempts.
+const DefaultMaxAttempts = 3
+
+// currentConfig returns the auto-indexing configuration, or nil if auto-indexing
+// is disabled.
+func currentConfig() *schema.CodeIntelAutoIndexing {
+	c := conf.Get().CodeIntelAutoIndexing
+	if c == nil || !c.Enabled {
+		return nil
+	}
+	return c
+}
+
+// maxAttempts returns the number of times an index job is attempted.
+func maxAttempts(c *schema.CodeIntelAutoIndexing) int {
+	if c.MaxAttempts > 0 {
+		return c.MaxAttempts
+	}
+	return DefaultMaxAttempts
+}
+
+// repositoryConfig returns the first repository configuration that matches the
+// given repository, or nil if the repository is not indexed automatically. Invalid
+// configurations, including those without an image, are skipped.
+func repositoryConfig(c *schema.CodeIntelAutoIndexing, name api.RepoName) *schema.CodeIntelAutoIndexingRepository {
+	for _, rc := range c.Repositories {
+		pattern, err := regexp.Compile(rc.Repository)
+		if err != nil {
+			log15.Warn("Invalid repository pattern in codeIntelAutoIndexing", "repository", rc.Repository, "error", err)
+			continue
+		}
+		if !pattern.MatchString(string(name)) {
+			continue
+		}
+		// 🚨 SECURITY: Indexers run inside an untrusted checkout of the repository, so they
+		// are only ever run in a container.
+		if rc.Image == "" {
+			log15.Warn("Ignoring codeIntelAutoIndexing repository without an image", "repository", rc.Repository)
+			continue
+		}
+		return rc
+	}
+
+	return nil
+}
@@ -0,0 +1,115 @@
+package autoindex
+
+import (
+	"bytes"
+	"context"
+	"time"
+
+	"github.com/inconshreveable/log15"
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/codeintel/db"
+	"github.com/sourcegraph/sourcegraph/internal/gitserver"
+)
+
+// Scheduler records an index job for the tip of the default branch of each repository
+// that is configured for auto-indexing. Since there is at most one index job per
+// repository and commit, a new job is recorded only once the repository is updated.
+type Scheduler struct {
+	db                db.DB
+	schedulerInterval time.Duration
+
+	// These are replaced in tests.
+	listRepos   func(ctx context.Context) ([]api.RepoName, error)
+	getRepo     func(ctx context.Context, name api.RepoName) (*api.Repo, error)
+	resolveHead func(ctx context.Context, name api.RepoName) (string, error)
+}
+
+type SchedulerOpts struct {
+	DB                db.DB
+	SchedulerInterval time.Duration
+}
+
+func NewScheduler(opts SchedulerOpts) *Scheduler {
+	return &Scheduler{
+		db:                opts.DB,
+		schedulerInterval: opts.SchedulerInterval,
+		listRepos:         api.InternalClient.ReposListEnabled,
+		getRepo:           api.InternalClient.ReposGetByName,
+		resolveHead:       resolveHead,
+	}
+}
+
+func (s *Scheduler) Start() {
+	for {
+		if err := s.step(); err != nil {
+			log15.Error("Failed to schedule index jobs", "error", err)
+		}
+
+		time.Sleep(s.schedulerInterval)
+	}
+}
+
+// step records an index job for the current tip of each repository configured for
+// auto-indexing that has none yet.
+func (s *Scheduler) step() error {
+	c := currentConfig()
+	if c == nil {
+		return nil
+	}
+
+	ctx := context.Background()
+
+	names, err := s.listRepos(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, name := range names {
+		if repositoryConfig(c, name) == nil {
+			continue
+		}
+
+		// A repository that cannot be scheduled (e.g. it is not cloned yet) must not
+		// block the repositories that follow it, so failures are logged and skipped.
+		if err := s.schedule(ctx, name); err != nil {
+			log15.Warn("Failed to schedule index job", "repository", name, "error", err)
+		}
+	}
+
+	return nil
+}
+
+// schedule records an index job for the current tip of the given repository.
+func (s *Scheduler) schedule(ctx context.Context, name api.RepoName) error {
+	repo, err := s.getRepo(ctx, name)
+	if err != nil {
+		return err
+	}
+
+	commit, err := s.resolveHead(ctx, name)
+	if err != nil {
+		return err
+	}
+
+	id, inserted, err := s.db.InsertIndex(ctx, int(repo.ID), string(name), commit)
+	if err != nil {
+		return err
+	}
+
+	if inserted {
+		log15.Debug("Scheduled index job", "indexID", id, "repository", name, "commit", commit)
+	}
+
+	return nil
+}
+
+// resolveHead returns the commit at the tip of the default branch of the given repository.
+func resolveHead(ctx context.Context, name api.RepoName) (string, error) {
+	cmd := gitserver.DefaultClient.Command("git", "rev-parse", "HEAD")
+	cmd.Repo = gitserver.Repo{Name: name}
+	out, err := cmd.CombinedOutput(ctx)
+	if err != nil {
+		return "", err
+	}
+	return string(bytes.TrimSpace(out)), nil
+}
@@ -0,0 +1,118 @@
+package autoindex
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/sourcegraph/sourcegraph/cmd/precise-code-intel-api-server/internal/mocks"
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/conf"
+	"github.com/sourcegraph/sourcegraph/schema"
+)
+
+func setAutoIndexingConfig(c *schema.CodeIntelAutoIndexing) {
+	conf.Mock(&conf.Unified{SiteConfiguration: schema.SiteConfiguration{CodeIntelAutoIndexing: c}})
+}
+
+func TestSchedulerStep(t *testing.T) {
+	setAutoIndexingConfig(&schema.CodeIntelAutoIndexing{
+		Enabled: true,
+		Repositories: []*schema.CodeIntelAutoIndexingRepository{
+			{Repository: "^github.com/foo/", Image: "sourcegraph/lsif-go", Command: []string{"lsif-go"}},
+		},
+	})
+	defer conf.Mock(nil)
+
+	mockDB := mocks.NewMockDB()
+	mockDB.InsertIndexFunc.SetDefaultReturn(1, true, nil)
+
+	scheduler := NewScheduler(SchedulerOpts{DB: mockDB})
+	scheduler.listRepos = func(ctx context.Context) ([]api.RepoName, error) {
+		return []api.RepoName{"github.com/foo/a", "github.com/bar/b", "github.com/foo/c", "github.com/foo/d"}, nil
+	}
+	scheduler.getRepo = func(ctx context.Context, name api.RepoName) (*api.Repo, error) {
+		ids := map[api.RepoName]api.RepoID{"github.com/foo/a": 1, "github.com/foo/c": 3, "github.com/foo/d": 4}
+		return &api.Repo{ID: ids[name], Name: name}, nil
+	}
+	scheduler.resolveHead = func(ctx context.Context, name api.RepoName) (string, error) {
+		if name == "github.com/foo/c" {
+			return "", errors.New("repository not cloned")
+		}
+		return "deadbeef" + string(name[len(name)-1:]), nil
+	}
+
+	if err := scheduler.step(); err != nil {
+		t.Fatalf("unexpected error scheduling index jobs: %s", err)
+	}
+
+	type insertion struct {
+		RepositoryID   int
+		RepositoryName string
+		Commit         string
+	}
+	var insertions []insertion
+	for _, call := range mockDB.InsertIndexFunc.History() {
+		insertions = append(insertions, insertion{call.Arg1, call.Arg2, call.Arg3})
+	}
+
+	// Unmatched and failing repositories are skipped
+	expected := []insertion{
+		{1, "github.com/foo/a", "deadbeefa"},
+		{4, "github.com/foo/d", "deadbeefd"},
+	}
+	if diff := cmp.Diff(expected, insertions); diff != "" {
+		t.Errorf("unexpected index jobs (-want +got):\n%s", diff)
+	}
+}
+
+func TestSchedulerStepDisabled(t *testing.T) {
+	setAutoIndexingConfig(&schema.CodeIntelAutoIndexing{
+		Enabled: false,
+		Repositories: []*schema.CodeIntelAutoIndexingRepository{
+			{Repository: ".*", Image: "sourcegraph/lsif-go", Command: []string{"lsif-go"}},
+		},
+	})
+	defer conf.Mock(nil)
+
+	scheduler := NewScheduler(SchedulerOpts{DB: mocks.NewMockDB()})
+	scheduler.listRepos = func(ctx context.Context) ([]api.RepoName, error) {
+		t.Fatal("unexpected call to listRepos")
+		return nil, nil
+	}
+
+	if err := scheduler.step(); err != nil {
+		t.Fatalf("unexpected error scheduling index jobs: %s", err)
+	}
+}
+
+func TestRepositoryConfig(t *testing.T) {
+	c := &schema.CodeIntelAutoIndexing{
+		Repositories: []*schema.CodeIntelAutoIndexingRepository{
+			{Repository: "[", Image: "sourcegraph/lsif-go", Command: []string{"invalid"}},
+			{Repository: "^github.com/foo/bar$", Command: []string{"no-image"}},
+			{Repository: "^github.com/foo/bar$", Image: "sourcegraph/lsif-go", Command: []string{"first"}},
+			{Repository: "^github.com/foo/", Image: "sourcegraph/lsif-go", Command: []string{"second"}},
+		},
+	}
+
+	testCases := []struct {
+		name     api.RepoName
+		expected string
+	}{
+		{"github.com/foo/bar", "first"},
+		{"github.com/foo/baz", "second"},
+		{"github.com/baz/bar", ""},
+	}
+
+	for _, testCase := range testCases {
+		var command string
+		if rc := repositoryConfig(c, testCase.name); rc != nil {
+			command = rc.Command[0]
+		}
+		if command != testCase.expected {
+			t.Errorf("unexpected configuration for %s. want=%q have=%q", testCase.name, testCase.expected, command)
+		}
+	}
+}
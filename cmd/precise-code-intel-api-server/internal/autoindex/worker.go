@@ -0,0 +1,240 @@
+package autoindex
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/inconshreveable/log15"
+	"github.com/pkg/errors"
+	"github.com/sourcegraph/sourcegraph/cmd/precise-code-intel-api-server/internal/server"
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	bundles "github.com/sourcegraph/sourcegraph/internal/codeintel/bundles/client"
+	"github.com/sourcegraph/sourcegraph/internal/codeintel/db"
+	"github.com/sourcegraph/sourcegraph/schema"
+)
+
+const (
+	// baseRetryDelay is the delay before the second attempt of a failed index job. The
+	// delay doubles with each subsequent failure up to maxRetryDelay.
+	baseRetryDelay = time.Minute
+	maxRetryDelay  = time.Hour
+
+	// maxOutputLength is the number of trailing bytes of the output of a failed index
+	// command that are recorded in the failure summary of the index job.
+	maxOutputLength = 4096
+)
+
+// Worker processes queued index jobs. It runs the configured indexer over an archive of
+// the repository at the index job's commit and enqueues the resulting LSIF dump as an
+// upload, exactly as if it had been uploaded by src-cli.
+type Worker struct {
+	db                  db.DB
+	bundleManagerClient bundles.BundleManagerClient
+	workerInterval      time.Duration
+
+	// These are replaced in tests.
+	fetchArchive func(ctx context.Context, name api.RepoName, commit string) (io.ReadCloser, error)
+	runCommand   func(ctx context.Context, dir, root string, rc *schema.CodeIntelAutoIndexingRepository) ([]byte, error)
+	now          func() time.Time
+}
+
+type WorkerOpts struct {
+	DB                  db.DB
+	BundleManagerClient bundles.BundleManagerClient
+	WorkerInterval      time.Duration
+}
+
+func NewWorker(opts WorkerOpts) *Worker {
+	return &Worker{
+		db:                  opts.DB,
+		bundleManagerClient: opts.BundleManagerClient,
+		workerInterval:      opts.WorkerInterval,
+		fetchArchive:        fetchArchive,
+		runCommand:          runCommand,
+		now:                 time.Now,
+	}
+}
+
+func (w *Worker) Start() {
+	for {
+		processed, err := w.step()
+		if err != nil {
+			log15.Error("Failed to process index job", "error", err)
+		}
+
+		// Drain the queue without delay while there is work and the database is reachable
+		if !processed || err != nil {
+			time.Sleep(w.workerInterval)
+		}
+	}
+}
+
+// step dequeues and processes a single index job. This method returns a flag indicating
+// whether an index job was dequeued. A failure of the index job itself is recorded on the
+// index job and is not returned.
+func (w *Worker) step() (bool, error) {
+	c := currentConfig()
+	if c == nil {
+		return false, nil
+	}
+
+	ctx := context.Background()
+
+	index, ok, err := w.db.DequeueIndex(ctx, w.now())
+	if err != nil || !ok {
+		return false, err
+	}
+
+	log15.Info("Dequeued index job", "indexID", index.ID, "repository", index.RepositoryName, "commit", index.Commit)
+
+	// Resets of stalled index jobs count as failed attempts as well
+	if index.NumFailures >= maxAttempts(c) {
+		summary := fmt.Sprintf("index job failed %d times", index.NumFailures)
+		if index.FailureSummary != nil {
+			summary += ": " + *index.FailureSummary
+		}
+		return true, w.db.MarkIndexErrored(ctx, index.ID, summary)
+	}
+
+	rc := repositoryConfig(c, api.RepoName(index.RepositoryName))
+	if rc == nil {
+		return true, w.db.MarkIndexErrored(ctx, index.ID, "repository is not configured for auto-indexing")
+	}
+
+	uploadID, err := w.process(ctx, index, rc)
+	if err != nil {
+		return true, w.recordFailure(ctx, c, index, err)
+	}
+
+	log15.Info("Completed index job", "indexID", index.ID, "uploadID", uploadID)
+	return true, w.db.MarkIndexComplete(ctx, index.ID, uploadID)
+}
+
+// recordFailure requeues the given index job with an exponential backoff or, once the index
+// job has been attempted the configured number of times, marks it as errored.
+func (w *Worker) recordFailure(ctx context.Context, c *schema.CodeIntelAutoIndexing, index db.Index, err error) error {
+	attempts := index.NumFailures + 1
+	log15.Warn("Failed to process index job", "indexID", index.ID, "attempt", attempts, "error", err)
+
+	if attempts >= maxAttempts(c) {
+		return w.db.MarkIndexErrored(ctx, index.ID, err.Error())
+	}
+
+	return w.db.RequeueIndex(ctx, index.ID, err.Error(), w.now().Add(retryDelay(attempts)))
+}
+
+// retryDelay returns the delay before the next attempt of an index job that has failed the
+// given number of times.
+func retryDelay(failures int) time.Duration {
+	delay := baseRetryDelay
+	for i := 1; i < failures && delay < maxRetryDelay; i++ {
+		delay *= 2
+	}
+	if delay > maxRetryDelay {
+		delay = maxRetryDelay
+	}
+	return delay
+}
+
+// process indexes the repository at the commit of the given index job and enqueues the
+// resulting dump as an upload. This method returns the identifier of the upload.
+func (w *Worker) process(ctx context.Context, index db.Index, rc *schema.CodeIntelAutoIndexingRepository) (int, error) {
+	// An index job processing for longer is reset by the janitor
+	ctx, cancel := context.WithTimeout(ctx, db.StalledIndexMaxAge)
+	defer cancel()
+
+	dir, err := ioutil.TempDir("", "index-")
+	if err != nil {
+		return 0, err
+	}
+	defer os.RemoveAll(dir)
+
+	archive, err := w.fetchArchive(ctx, api.RepoName(index.RepositoryName), index.Commit)
+	if err != nil {
+		return 0, errors.Wrap(err, "fetching repository archive")
+	}
+	err = untar(archive, dir)
+	archive.Close()
+	if err != nil {
+		return 0, errors.Wrap(err, "extracting repository archive")
+	}
+
+	root := cleanRoot(rc.Root)
+	if output, err := w.runCommand(ctx, dir, root, rc); err != nil {
+		return 0, fmt.Errorf("running index command: %s\n%s", err, tail(output, maxOutputLength))
+	}
+
+	f, err := gzipFile(filepath.Join(dir, filepath.FromSlash(root), "dump.lsif"))
+	if err != nil {
+		return 0, errors.Wrap(err, "reading dump.lsif")
+	}
+	defer os.Remove(f.Name())
+	defer f.Close()
+
+	indexerName, err := server.ReadIndexerNameFromFile(f)
+	if err != nil {
+		return 0, errors.Wrap(err, "reading indexer name from dump.lsif")
+	}
+
+	if root != "" {
+		root += "/"
+	}
+
+	id, closer, err := w.db.Enqueue(
+		ctx,
+		index.Commit,
+		root,
+		"{}",
+		index.RepositoryID,
+		indexerName,
+	)
+	if err != nil {
+		return 0, err
+	}
+	if err := closer.CloseTx(w.bundleManagerClient.SendUpload(ctx, id, f)); err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
+
+// runCommand runs the index command of the given repository configuration in a Docker
+// container, in the root directory of the extracted repository.
+func runCommand(ctx context.Context, dir, root string, rc *schema.CodeIntelAutoIndexingRepository) ([]byte, error) {
+	// 🚨 SECURITY: Never run the indexer on the host, where the untrusted checkout could
+	// execute arbitrary code (e.g. via build scripts the indexer runs).
+	if rc.Image == "" {
+		return nil, errors.New("no image configured for the index command")
+	}
+
+	args := append([]string{
+		"run", "--rm",
+		"-v", dir + ":/data",
+		"-w", path.Join("/data", root),
+		rc.Image,
+	}, rc.Command...)
+	return exec.CommandContext(ctx, "docker", args...).CombinedOutput()
+}
+
+// cleanRoot returns the given root as a slash-separated path relative to the repository
+// root, without leading or trailing slashes. Roots outside of the repository are clamped
+// to the repository root.
+func cleanRoot(root string) string {
+	return strings.TrimPrefix(path.Clean("/"+root), "/")
+}
+
+// tail returns the last n bytes of the given output.
+func tail(output []byte, n int) string {
+	if len(output) > n {
+		output = output[len(output)-n:]
+	}
+	return string(output)
+}
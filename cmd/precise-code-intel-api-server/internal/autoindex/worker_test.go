@@ -0,0 +1,243 @@
+package autoindex
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/sourcegraph/sourcegraph/cmd/precise-code-intel-api-server/internal/mocks"
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/codeintel/db"
+	"github.com/sourcegraph/sourcegraph/internal/conf"
+	"github.com/sourcegraph/sourcegraph/schema"
+)
+
+const testDump = `{"id":1,"type":"vertex","label":"metaData","toolInfo":{"name":"lsif-go"}}
+{"id":2,"type":"vertex","label":"document"}
+`
+
+type mockTxCloser struct{ err error }
+
+func (c *mockTxCloser) CloseTx(err error) error {
+	c.err = err
+	return err
+}
+
+func testArchive(t *testing.T, files map[string]string) io.ReadCloser {
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	for name, content := range files {
+		if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(content)), Typeflag: tar.TypeReg}); err != nil {
+			t.Fatalf("unexpected error writing archive: %s", err)
+		}
+		if _, err := tw.Write([]byte(content)); err != nil {
+			t.Fatalf("unexpected error writing archive: %s", err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatalf("unexpected error writing archive: %s", err)
+	}
+	return ioutil.NopCloser(&buf)
+}
+
+func newTestWorker(t *testing.T, mockDB *mocks.MockDB, mockBundleManagerClient *mocks.MockBundleManagerClient, now time.Time) *Worker {
+	worker := NewWorker(WorkerOpts{DB: mockDB, BundleManagerClient: mockBundleManagerClient})
+	worker.now = func() time.Time { return now }
+	worker.fetchArchive = func(ctx context.Context, name api.RepoName, commit string) (io.ReadCloser, error) {
+		if name != "github.com/foo/bar" || commit != "deadbeef" {
+			t.Errorf("unexpected archive request for %s@%s", name, commit)
+		}
+		return testArchive(t, map[string]string{"cmd/app/main.go": "package main\n"}), nil
+	}
+	return worker
+}
+
+func TestWorkerStep(t *testing.T) {
+	setAutoIndexingConfig(&schema.CodeIntelAutoIndexing{
+		Enabled: true,
+		Repositories: []*schema.CodeIntelAutoIndexingRepository{
+			{Repository: "^github.com/foo/bar$", Root: "cmd/app/", Image: "sourcegraph/lsif-go", Command: []string{"lsif-go"}},
+		},
+	})
+	defer conf.Mock(nil)
+
+	now := time.Unix(1587396557, 0)
+	mockDB := mocks.NewMockDB()
+	mockBundleManagerClient := mocks.NewMockBundleManagerClient()
+	mockDB.DequeueIndexFunc.SetDefaultReturn(db.Index{ID: 7, Commit: "deadbeef", RepositoryID: 42, RepositoryName: "github.com/foo/bar"}, true, nil)
+	closer := &mockTxCloser{}
+	mockDB.EnqueueFunc.SetDefaultReturn(100, closer, nil)
+
+	var payload []byte
+	mockBundleManagerClient.SendUploadFunc.SetDefaultHook(func(ctx context.Context, bundleID int, r io.Reader) error {
+		gzipReader, err := gzip.NewReader(r)
+		if err != nil {
+			return err
+		}
+		payload, err = ioutil.ReadAll(gzipReader)
+		return err
+	})
+
+	worker := newTestWorker(t, mockDB, mockBundleManagerClient, now)
+	worker.runCommand = func(ctx context.Context, dir, root string, rc *schema.CodeIntelAutoIndexingRepository) ([]byte, error) {
+		if root != "cmd/app" {
+			t.Errorf("unexpected root. want=%q have=%q", "cmd/app", root)
+		}
+		if _, err := os.Stat(filepath.Join(dir, root, "main.go")); err != nil {
+			t.Errorf("repository was not extracted: %s", err)
+		}
+		return nil, ioutil.WriteFile(filepath.Join(dir, root, "dump.lsif"), []byte(testDump), 0644)
+	}
+
+	processed, err := worker.step()
+	if err != nil {
+		t.Fatalf("unexpected error processing index job: %s", err)
+	}
+	if !processed {
+		t.Fatalf("expected index job to be processed")
+	}
+
+	if len(mockDB.EnqueueFunc.History()) != 1 {
+		t.Fatalf("unexpected number of Enqueue calls. want=%d have=%d", 1, len(mockDB.EnqueueFunc.History()))
+	}
+	call := mockDB.EnqueueFunc.History()[0]
+	if call.Arg1 != "deadbeef" || call.Arg2 != "cmd/app/" || call.Arg4 != 42 || call.Arg5 != "lsif-go" {
+		t.Errorf("unexpected Enqueue arguments: commit=%q root=%q repositoryID=%d indexerName=%q", call.Arg1, call.Arg2, call.Arg4, call.Arg5)
+	}
+	if diff := cmp.Diff(testDump, string(payload)); diff != "" {
+		t.Errorf("unexpected payload (-want +got):\n%s", diff)
+	}
+	if closer.err != nil {
+		t.Errorf("unexpected error closing transaction: %s", closer.err)
+	}
+
+	if len(mockDB.MarkIndexCompleteFunc.History()) != 1 {
+		t.Fatalf("unexpected number of MarkIndexComplete calls. want=%d have=%d", 1, len(mockDB.MarkIndexCompleteFunc.History()))
+	}
+	if call := mockDB.MarkIndexCompleteFunc.History()[0]; call.Arg1 != 7 || call.Arg2 != 100 {
+		t.Errorf("unexpected MarkIndexComplete arguments: id=%d uploadID=%d", call.Arg1, call.Arg2)
+	}
+}
+
+func TestWorkerStepFailure(t *testing.T) {
+	setAutoIndexingConfig(&schema.CodeIntelAutoIndexing{
+		Enabled:     true,
+		MaxAttempts: 3,
+		Repositories: []*schema.CodeIntelAutoIndexingRepository{
+			{Repository: "^github.com/foo/bar$", Image: "sourcegraph/lsif-go", Command: []string{"lsif-go"}},
+		},
+	})
+	defer conf.Mock(nil)
+
+	now := time.Unix(1587396557, 0)
+
+	for numFailures, requeued := range []bool{true, true, false} {
+		mockDB := mocks.NewMockDB()
+		mockDB.DequeueIndexFunc.SetDefaultReturn(db.Index{ID: 7, Commit: "deadbeef", RepositoryName: "github.com/foo/bar", NumFailures: numFailures}, true, nil)
+
+		worker := newTestWorker(t, mockDB, mocks.NewMockBundleManagerClient(), now)
+		worker.runCommand = func(ctx context.Context, dir, root string, rc *schema.CodeIntelAutoIndexingRepository) ([]byte, error) {
+			return []byte("no go.mod found"), errors.New("exit status 1")
+		}
+
+		if _, err := worker.step(); err != nil {
+			t.Fatalf("unexpected error processing index job: %s", err)
+		}
+		if len(mockDB.EnqueueFunc.History()) != 0 {
+			t.Errorf("unexpected upload of failed index job")
+		}
+
+		if requeued {
+			if len(mockDB.RequeueIndexFunc.History()) != 1 {
+				t.Fatalf("expected index job to be requeued after %d failures", numFailures+1)
+			}
+			call := mockDB.RequeueIndexFunc.History()[0]
+			if !strings.Contains(call.Arg2, "no go.mod found") {
+				t.Errorf("failure summary does not contain the command output: %q", call.Arg2)
+			}
+			if expected := now.Add(retryDelay(numFailures + 1)); !call.Arg3.Equal(expected) {
+				t.Errorf("unexpected process after. want=%s have=%s", expected, call.Arg3)
+			}
+		} else if len(mockDB.MarkIndexErroredFunc.History()) != 1 {
+			t.Fatalf("expected index job to be marked as errored after %d failures", numFailures+1)
+		}
+	}
+}
+
+func TestWorkerStepUnconfiguredRepository(t *testing.T) {
+	setAutoIndexingConfig(&schema.CodeIntelAutoIndexing{Enabled: true})
+	defer conf.Mock(nil)
+
+	mockDB := mocks.NewMockDB()
+	mockDB.DequeueIndexFunc.SetDefaultReturn(db.Index{ID: 7, Commit: "deadbeef", RepositoryName: "github.com/foo/bar"}, true, nil)
+
+	worker := newTestWorker(t, mockDB, mocks.NewMockBundleManagerClient(), time.Now())
+	if _, err := worker.step(); err != nil {
+		t.Fatalf("unexpected error processing index job: %s", err)
+	}
+	if len(mockDB.MarkIndexErroredFunc.History()) != 1 {
+		t.Fatalf("expected index job to be marked as errored")
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	expected := []time.Duration{time.Minute, 2 * time.Minute, 4 * time.Minute, 8 * time.Minute, 16 * time.Minute, 32 * time.Minute, time.Hour, time.Hour}
+	for i, delay := range expected {
+		if have := retryDelay(i + 1); have != delay {
+			t.Errorf("unexpected delay after %d failures. want=%s have=%s", i+1, delay, have)
+		}
+	}
+}
+
+func TestCleanRoot(t *testing.T) {
+	testCases := map[string]string{
+		"":          "",
+		"/":         "",
+		"cmd/app/":  "cmd/app",
+		"/cmd/app":  "cmd/app",
+		"../../etc": "etc",
+	}
+	for root, expected := range testCases {
+		if have := cleanRoot(root); have != expected {
+			t.Errorf("unexpected root for %q. want=%q have=%q", root, expected, have)
+		}
+	}
+}
+
+func TestRunCommandRequiresImage(t *testing.T) {
+	dir, err := ioutil.TempDir("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	rc := &schema.CodeIntelAutoIndexingRepository{Command: []string{"touch", "pwned"}}
+	if _, err := runCommand(context.Background(), dir, "", rc); err == nil {
+		t.Fatal("expected error running command without an image")
+	}
+	if _, err := os.Stat(filepath.Join(dir, "pwned")); !os.IsNotExist(err) {
+		t.Errorf("command ran on the host: %v", err)
+	}
+}
+
+func TestUntarRejectsPathTraversal(t *testing.T) {
+	dir, err := ioutil.TempDir("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	if err := untar(testArchive(t, map[string]string{"../escape": "x"}), dir); err == nil {
+		t.Fatalf("expected error extracting archive with path outside of target directory")
+	}
+}
@@ -0,0 +1,160 @@
+package commits
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"time"
+
+	"github.com/inconshreveable/log15"
+	frontenddb "github.com/sourcegraph/sourcegraph/cmd/frontend/db"
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/codeintel/commitgraph"
+	"github.com/sourcegraph/sourcegraph/internal/codeintel/db"
+	"github.com/sourcegraph/sourcegraph/internal/gitserver"
+)
+
+// Updater keeps the commit graph of each repository with processed uploads in sync with
+// gitserver. The commit graph of a repository is recalculated when the tip of its default
+// branch moves or when the set of its processed uploads changes.
+type Updater struct {
+	db              db.DB
+	updaterInterval time.Duration
+
+	// These are replaced in tests.
+	getRepoName func(ctx context.Context, repositoryID int) (api.RepoName, error)
+	resolveHead func(ctx context.Context, name api.RepoName) (string, error)
+	gitLog      func(ctx context.Context, name api.RepoName, args ...string) ([]string, error)
+}
+
+type UpdaterOpts struct {
+	DB              db.DB
+	UpdaterInterval time.Duration
+}
+
+func NewUpdater(opts UpdaterOpts) *Updater {
+	return &Updater{
+		db:              opts.DB,
+		updaterInterval: opts.UpdaterInterval,
+		getRepoName:     getRepoName,
+		resolveHead:     resolveHead,
+		gitLog:          gitLog,
+	}
+}
+
+func (u *Updater) Start() {
+	for {
+		if err := u.step(); err != nil {
+			log15.Error("Failed to update commit graphs", "error", err)
+		}
+
+		time.Sleep(u.updaterInterval)
+	}
+}
+
+// step updates the commit graph of each repository whose commit graph is out of date.
+func (u *Updater) step() error {
+	ctx := context.Background()
+
+	states, err := u.db.GetCommitGraphStates(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, state := range states {
+		// A repository that cannot be updated (e.g. it is not cloned yet) must not
+		// block the repositories that follow it, so failures are logged and skipped.
+		if err := u.update(ctx, state); err != nil {
+			log15.Warn("Failed to update commit graph", "repositoryID", state.RepositoryID, "error", err)
+		}
+	}
+
+	return nil
+}
+
+// update fetches the commits of the given repository added since the commit graph was last
+// calculated and recalculates the commit graph at the current tip of the default branch.
+func (u *Updater) update(ctx context.Context, state db.CommitGraphState) error {
+	name, err := u.getRepoName(ctx, state.RepositoryID)
+	if err != nil {
+		return err
+	}
+
+	tipCommit, err := u.resolveHead(ctx, name)
+	if err != nil {
+		return err
+	}
+
+	if tipCommit == state.TipCommit && !state.Stale {
+		return nil
+	}
+
+	graph, err := u.fetchCommits(ctx, name, state.TipCommit)
+	if err != nil {
+		return err
+	}
+
+	if err := u.db.UpdateCommits(ctx, state.RepositoryID, graph); err != nil {
+		return err
+	}
+
+	if err := u.db.UpdateCommitGraph(ctx, state.RepositoryID, tipCommit); err != nil {
+		return err
+	}
+
+	log15.Debug("Updated commit graph", "repositoryID", state.RepositoryID, "tipCommit", tipCommit, "newCommits", len(graph))
+	return nil
+}
+
+// fetchCommits returns the commits of the given repository that are not reachable from the
+// given previous tip of the default branch. All commits are returned if there is no previous
+// tip or if it is no longer known to gitserver (e.g. after a force push).
+func (u *Updater) fetchCommits(ctx context.Context, name api.RepoName, previousTipCommit string) (commitgraph.Graph, error) {
+	if previousTipCommit != "" {
+		lines, err := u.gitLog(ctx, name, "--all", "^"+previousTipCommit)
+		if err == nil {
+			return commitgraph.ParseGraph(lines), nil
+		}
+
+		log15.Warn("Failed to fetch new commits, fetching all commits", "repository", name, "error", err)
+	}
+
+	lines, err := u.gitLog(ctx, name, "--all")
+	if err != nil {
+		return nil, err
+	}
+
+	return commitgraph.ParseGraph(lines), nil
+}
+
+// getRepoName returns the name of the repository with the given identifier.
+func getRepoName(ctx context.Context, repositoryID int) (api.RepoName, error) {
+	repo, err := frontenddb.Repos.Get(ctx, api.RepoID(repositoryID))
+	if err != nil {
+		return "", err
+	}
+	return repo.Name, nil
+}
+
+// resolveHead returns the commit at the tip of the default branch of the given repository.
+func resolveHead(ctx context.Context, name api.RepoName) (string, error) {
+	cmd := gitserver.DefaultClient.Command("git", "rev-parse", "HEAD")
+	cmd.Repo = gitserver.Repo{Name: name}
+	out, err := cmd.CombinedOutput(ctx)
+	if err != nil {
+		return "", err
+	}
+	return string(bytes.TrimSpace(out)), nil
+}
+
+// gitLog returns the lines of the output of `git log --pretty="%H %P"` invoked with the given
+// additional arguments in the given repository.
+func gitLog(ctx context.Context, name api.RepoName, args ...string) ([]string, error) {
+	cmd := gitserver.DefaultClient.Command("git", append([]string{"log", "--pretty=%H %P"}, args...)...)
+	cmd.Repo = gitserver.Repo{Name: name}
+	out, err := cmd.Output(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return strings.Split(strings.TrimSpace(string(out)), "\n"), nil
+}
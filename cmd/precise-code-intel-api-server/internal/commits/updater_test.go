@@ -0,0 +1,110 @@
+package commits
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/sourcegraph/sourcegraph/cmd/precise-code-intel-api-server/internal/mocks"
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/codeintel/commitgraph"
+	"github.com/sourcegraph/sourcegraph/internal/codeintel/db"
+)
+
+func newTestUpdater(mockDB *mocks.MockDB, heads map[api.RepoName]string, logs map[string][]string) *Updater {
+	updater := NewUpdater(UpdaterOpts{DB: mockDB})
+	updater.getRepoName = func(ctx context.Context, repositoryID int) (api.RepoName, error) {
+		return api.RepoName(fmt.Sprintf("github.com/foo/r%d", repositoryID)), nil
+	}
+	updater.resolveHead = func(ctx context.Context, name api.RepoName) (string, error) {
+		if head, ok := heads[name]; ok {
+			return head, nil
+		}
+		return "", errors.New("repository not cloned")
+	}
+	updater.gitLog = func(ctx context.Context, name api.RepoName, args ...string) ([]string, error) {
+		if lines, ok := logs[string(name)+" "+strings.Join(args, " ")]; ok {
+			return lines, nil
+		}
+		return nil, errors.New("bad object")
+	}
+	return updater
+}
+
+func TestUpdaterStep(t *testing.T) {
+	mockDB := mocks.NewMockDB()
+	mockDB.GetCommitGraphStatesFunc.SetDefaultReturn([]db.CommitGraphState{
+		{RepositoryID: 1, TipCommit: "", Stale: true},         // never calculated
+		{RepositoryID: 2, TipCommit: "c2", Stale: false},      // up to date
+		{RepositoryID: 3, TipCommit: "c2", Stale: false},      // tip moved
+		{RepositoryID: 4, TipCommit: "c1", Stale: true},       // not cloned
+		{RepositoryID: 5, TipCommit: "c1", Stale: true},       // new upload
+		{RepositoryID: 6, TipCommit: "deleted", Stale: false}, // force pushed
+	}, nil)
+
+	heads := map[api.RepoName]string{
+		"github.com/foo/r1": "c2",
+		"github.com/foo/r2": "c2",
+		"github.com/foo/r3": "c3",
+		"github.com/foo/r5": "c1",
+		"github.com/foo/r6": "c2",
+	}
+	logs := map[string][]string{
+		"github.com/foo/r1 --all":     {"c2 c1", "c1"},
+		"github.com/foo/r3 --all ^c2": {"c3 c2"},
+		"github.com/foo/r5 --all ^c1": {"b1 c1"},
+		"github.com/foo/r6 --all":     {"c2 c1", "c1"},
+	}
+
+	updater := newTestUpdater(mockDB, heads, logs)
+	if err := updater.step(); err != nil {
+		t.Fatalf("unexpected error updating commit graphs: %s", err)
+	}
+
+	expectedCommits := map[int]commitgraph.Graph{
+		1: {"c2": {"c1"}, "c1": nil},
+		3: {"c3": {"c2"}},
+		5: {"b1": {"c1"}},
+		6: {"c2": {"c1"}, "c1": nil},
+	}
+	commits := map[int]commitgraph.Graph{}
+	for _, call := range mockDB.UpdateCommitsFunc.History() {
+		commits[call.Arg1] = call.Arg2
+	}
+	if diff := cmp.Diff(expectedCommits, commits); diff != "" {
+		t.Errorf("unexpected commits (-want +got):\n%s", diff)
+	}
+
+	expectedTips := map[int]string{1: "c2", 3: "c3", 5: "c1", 6: "c2"}
+	tips := map[int]string{}
+	for _, call := range mockDB.UpdateCommitGraphFunc.History() {
+		tips[call.Arg1] = call.Arg2
+	}
+	if diff := cmp.Diff(expectedTips, tips); diff != "" {
+		t.Errorf("unexpected tip commits (-want +got):\n%s", diff)
+	}
+}
+
+func TestUpdaterStepUpdateCommitsError(t *testing.T) {
+	mockDB := mocks.NewMockDB()
+	mockDB.GetCommitGraphStatesFunc.SetDefaultReturn([]db.CommitGraphState{
+		{RepositoryID: 1, TipCommit: "", Stale: true},
+	}, nil)
+	mockDB.UpdateCommitsFunc.SetDefaultReturn(errors.New("database unavailable"))
+
+	heads := map[api.RepoName]string{"github.com/foo/r1": "c1"}
+	logs := map[string][]string{"github.com/foo/r1 --all": {"c1"}}
+
+	updater := newTestUpdater(mockDB, heads, logs)
+	if err := updater.step(); err != nil {
+		t.Fatalf("unexpected error updating commit graphs: %s", err)
+	}
+
+	// The commit graph is not recalculated over an incomplete set of commits
+	if calls := len(mockDB.UpdateCommitGraphFunc.History()); calls != 0 {
+		t.Errorf("unexpected number of commit graph updates. want=%d have=%d", 0, calls)
+	}
+}
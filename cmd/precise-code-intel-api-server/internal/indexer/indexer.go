@@ -0,0 +1,89 @@
+package indexer
+
+import (
+	"context"
+	"time"
+
+	"github.com/inconshreveable/log15"
+	bundles "github.com/sourcegraph/sourcegraph/internal/codeintel/bundles/client"
+	"github.com/sourcegraph/sourcegraph/internal/codeintel/db"
+)
+
+// BatchSize is the maximum number of dumps indexed in a single step.
+const BatchSize = 50
+
+// Indexer maintains the moniker reference index, which maps the monikers of symbols
+// to the dumps that reference them. Dumps are indexed periodically once their upload
+// has been processed. Until a dump is indexed, its references are found by opening
+// its bundle during reference requests.
+type Indexer struct {
+	db                  db.DB
+	bundleManagerClient bundles.BundleManagerClient
+	indexerInterval     time.Duration
+}
+
+type IndexerOpts struct {
+	DB                  db.DB
+	BundleManagerClient bundles.BundleManagerClient
+	IndexerInterval     time.Duration
+}
+
+func NewIndexer(opts IndexerOpts) *Indexer {
+	return &Indexer{
+		db:                  opts.DB,
+		bundleManagerClient: opts.BundleManagerClient,
+		indexerInterval:     opts.IndexerInterval,
+	}
+}
+
+func (i *Indexer) Start() {
+	for {
+		if err := i.step(); err != nil {
+			log15.Error("Failed to index moniker references", "error", err)
+		}
+
+		time.Sleep(i.indexerInterval)
+	}
+}
+
+// step indexes the moniker references of a batch of unindexed dumps.
+func (i *Indexer) step() error {
+	ctx := context.Background()
+
+	ids, err := i.db.GetUnindexedDumpIDs(ctx, BatchSize)
+	if err != nil {
+		return err
+	}
+
+	for _, id := range ids {
+		// A dump that cannot be indexed (e.g. its bundle was removed) must not
+		// block the dumps that follow it, so failures are logged and skipped.
+		if err := i.index(ctx, id); err != nil {
+			log15.Error("Failed to index moniker references of dump", "dumpID", id, "error", err)
+			continue
+		}
+
+		log15.Debug("Indexed moniker references", "dumpID", id)
+	}
+
+	return nil
+}
+
+// index writes the moniker references of the given dump to the moniker reference index.
+func (i *Indexer) index(ctx context.Context, dumpID int) error {
+	counts, err := i.bundleManagerClient.BundleClient(dumpID).MonikerCounts(ctx, "reference")
+	if err != nil {
+		return err
+	}
+
+	references := make([]db.MonikerReference, 0, len(counts))
+	for _, count := range counts {
+		references = append(references, db.MonikerReference{
+			Scheme:     count.Scheme,
+			Identifier: count.Identifier,
+			Count:      count.Count,
+		})
+	}
+
+	return i.db.IndexMonikerReferences(ctx, dumpID, references)
+}
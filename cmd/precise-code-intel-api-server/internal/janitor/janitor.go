@@ -37,9 +37,11 @@ func (j *Janitor) Start() {
 
 // Run performs a best-effort cleanup. See the following methods for more specifics.
 //   - resetStalled
+//   - resetStalledIndexes
 func (j *Janitor) step() error {
 	cleanupFns := []func() error{
 		j.resetStalled,
+		j.resetStalledIndexes,
 	}
 
 	for _, fn := range cleanupFns {
@@ -66,3 +68,20 @@ func (j *Janitor) resetStalled() error {
 
 	return nil
 }
+
+// resetStalledIndexes moves all index jobs that have been in the PROCESSING state for a while
+// back to QUEUED. The auto-indexing worker responsible for each updated index job has likely
+// died. The reset counts as a failed attempt, so an index job that repeatedly stalls its
+// worker is eventually marked as errored.
+func (j *Janitor) resetStalledIndexes() error {
+	ids, err := j.db.ResetStalledIndexes(context.Background(), time.Now())
+	if err != nil {
+		return err
+	}
+
+	for _, id := range ids {
+		log15.Debug("Reset stalled index job", "indexID", id)
+	}
+
+	return nil
+}
@@ -22,6 +22,9 @@ type MockBundleClient struct {
 	// HoverFunc is an instance of a mock function object controlling the
 	// behavior of the method Hover.
 	HoverFunc *BundleClientHoverFunc
+	// MonikerCountsFunc is an instance of a mock function object
+	// controlling the behavior of the method MonikerCounts.
+	MonikerCountsFunc *BundleClientMonikerCountsFunc
 	// MonikerResultsFunc is an instance of a mock function object
 	// controlling the behavior of the method MonikerResults.
 	MonikerResultsFunc *BundleClientMonikerResultsFunc
@@ -55,6 +58,11 @@ func NewMockBundleClient() *MockBundleClient {
 				return "", client.Range{}, false, nil
 			},
 		},
+		MonikerCountsFunc: &BundleClientMonikerCountsFunc{
+			defaultHook: func(context.Context, string) ([]client.MonikerCount, error) {
+				return nil, nil
+			},
+		},
 		MonikerResultsFunc: &BundleClientMonikerResultsFunc{
 			defaultHook: func(context.Context, string, string, string, int, int) ([]client.Location, int, error) {
 				return nil, 0, nil
@@ -92,6 +100,9 @@ func NewMockBundleClientFrom(i client.BundleClient) *MockBundleClient {
 		HoverFunc: &BundleClientHoverFunc{
 			defaultHook: i.Hover,
 		},
+		MonikerCountsFunc: &BundleClientMonikerCountsFunc{
+			defaultHook: i.MonikerCounts,
+		},
 		MonikerResultsFunc: &BundleClientMonikerResultsFunc{
 			defaultHook: i.MonikerResults,
 		},
@@ -450,6 +461,115 @@ func (c BundleClientHoverFuncCall) Results() []interface{} {
 	return []interface{}{c.Result0, c.Result1, c.Result2, c.Result3}
 }
 
+// BundleClientMonikerCountsFunc describes the behavior when the MonikerCounts
+// method of the parent MockBundleClient instance is invoked.
+type BundleClientMonikerCountsFunc struct {
+	defaultHook func(context.Context, string) ([]client.MonikerCount, error)
+	hooks       []func(context.Context, string) ([]client.MonikerCount, error)
+	history     []BundleClientMonikerCountsFuncCall
+	mutex       sync.Mutex
+}
+
+// MonikerCounts delegates to the next hook function in the queue and stores
+// the parameter and result values of this invocation.
+func (m *MockBundleClient) MonikerCounts(v0 context.Context, v1 string) ([]client.MonikerCount, error) {
+	r0, r1 := m.MonikerCountsFunc.nextHook()(v0, v1)
+	m.MonikerCountsFunc.appendCall(BundleClientMonikerCountsFuncCall{v0, v1, r0, r1})
+	return r0, r1
+}
+
+// SetDefaultHook sets function that is called when the MonikerCounts method
+// of the parent MockBundleClient instance is invoked and the hook queue is
+// empty.
+func (f *BundleClientMonikerCountsFunc) SetDefaultHook(hook func(context.Context, string) ([]client.MonikerCount, error)) {
+	f.defaultHook = hook
+}
+
+// PushHook adds a function to the end of hook queue. Each invocation of the
+// MonikerCounts method of the parent MockBundleClient instance inovkes the
+// hook at the front of the queue and discards it. After the queue is empty,
+// the default hook function is invoked for any future action.
+func (f *BundleClientMonikerCountsFunc) PushHook(hook func(context.Context, string) ([]client.MonikerCount, error)) {
+	f.mutex.Lock()
+	f.hooks = append(f.hooks, hook)
+	f.mutex.Unlock()
+}
+
+// SetDefaultReturn calls SetDefaultDefaultHook with a function that returns
+// the given values.
+func (f *BundleClientMonikerCountsFunc) SetDefaultReturn(r0 []client.MonikerCount, r1 error) {
+	f.SetDefaultHook(func(context.Context, string) ([]client.MonikerCount, error) {
+		return r0, r1
+	})
+}
+
+// PushReturn calls PushDefaultHook with a function that returns the given
+// values.
+func (f *BundleClientMonikerCountsFunc) PushReturn(r0 []client.MonikerCount, r1 error) {
+	f.PushHook(func(context.Context, string) ([]client.MonikerCount, error) {
+		return r0, r1
+	})
+}
+
+func (f *BundleClientMonikerCountsFunc) nextHook() func(context.Context, string) ([]client.MonikerCount, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if len(f.hooks) == 0 {
+		return f.defaultHook
+	}
+
+	hook := f.hooks[0]
+	f.hooks = f.hooks[1:]
+	return hook
+}
+
+func (f *BundleClientMonikerCountsFunc) appendCall(r0 BundleClientMonikerCountsFuncCall) {
+	f.mutex.Lock()
+	f.history = append(f.history, r0)
+	f.mutex.Unlock()
+}
+
+// History returns a sequence of BundleClientMonikerCountsFuncCall objects
+// describing the invocations of this function.
+func (f *BundleClientMonikerCountsFunc) History() []BundleClientMonikerCountsFuncCall {
+	f.mutex.Lock()
+	history := make([]BundleClientMonikerCountsFuncCall, len(f.history))
+	copy(history, f.history)
+	f.mutex.Unlock()
+
+	return history
+}
+
+// BundleClientMonikerCountsFuncCall is an object that describes an invocation
+// of method MonikerCounts on an instance of MockBundleClient.
+type BundleClientMonikerCountsFuncCall struct {
+	// Arg0 is the value of the 1st argument passed to this method
+	// invocation.
+	Arg0 context.Context
+	// Arg1 is the value of the 2nd argument passed to this method
+	// invocation.
+	Arg1 string
+	// Result0 is the value of the 1st result returned from this method
+	// invocation.
+	Result0 []client.MonikerCount
+	// Result1 is the value of the 2nd result returned from this method
+	// invocation.
+	Result1 error
+}
+
+// Args returns an interface slice containing the arguments of this
+// invocation.
+func (c BundleClientMonikerCountsFuncCall) Args() []interface{} {
+	return []interface{}{c.Arg0, c.Arg1}
+}
+
+// Results returns an interface slice containing the results of this
+// invocation.
+func (c BundleClientMonikerCountsFuncCall) Results() []interface{} {
+	return []interface{}{c.Result0, c.Result1}
+}
+
 // BundleClientMonikerResultsFunc describes the behavior when the
 // MonikerResults method of the parent MockBundleClient instance is invoked.
 type BundleClientMonikerResultsFunc struct {
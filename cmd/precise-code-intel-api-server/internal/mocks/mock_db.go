@@ -4,6 +4,7 @@ package mocks
 
 import (
 	"context"
+	"github.com/sourcegraph/sourcegraph/internal/codeintel/commitgraph"
 	db "github.com/sourcegraph/sourcegraph/internal/codeintel/db"
 	"sync"
 	"time"
@@ -19,36 +20,81 @@ type MockDB struct {
 	// DeleteUploadByIDFunc is an instance of a mock function object
 	// controlling the behavior of the method DeleteUploadByID.
 	DeleteUploadByIDFunc *DBDeleteUploadByIDFunc
+	// DequeueIndexFunc is an instance of a mock function object controlling
+	// the behavior of the method DequeueIndex.
+	DequeueIndexFunc *DBDequeueIndexFunc
 	// EnqueueFunc is an instance of a mock function object controlling the
 	// behavior of the method Enqueue.
 	EnqueueFunc *DBEnqueueFunc
 	// FindClosestDumpsFunc is an instance of a mock function object
 	// controlling the behavior of the method FindClosestDumps.
 	FindClosestDumpsFunc *DBFindClosestDumpsFunc
+	// GetCommitGraphStatesFunc is an instance of a mock function object
+	// controlling the behavior of the method GetCommitGraphStates.
+	GetCommitGraphStatesFunc *DBGetCommitGraphStatesFunc
 	// GetDumpByIDFunc is an instance of a mock function object controlling
 	// the behavior of the method GetDumpByID.
 	GetDumpByIDFunc *DBGetDumpByIDFunc
+	// GetIndexByIDFunc is an instance of a mock function object controlling
+	// the behavior of the method GetIndexByID.
+	GetIndexByIDFunc *DBGetIndexByIDFunc
+	// GetIndexesFunc is an instance of a mock function object controlling
+	// the behavior of the method GetIndexes.
+	GetIndexesFunc *DBGetIndexesFunc
 	// GetPackageFunc is an instance of a mock function object controlling
 	// the behavior of the method GetPackage.
 	GetPackageFunc *DBGetPackageFunc
 	// GetStatesFunc is an instance of a mock function object controlling
 	// the behavior of the method GetStates.
 	GetStatesFunc *DBGetStatesFunc
+	// GetUnindexedDumpIDsFunc is an instance of a mock function object
+	// controlling the behavior of the method GetUnindexedDumpIDs.
+	GetUnindexedDumpIDsFunc *DBGetUnindexedDumpIDsFunc
 	// GetUploadByIDFunc is an instance of a mock function object
 	// controlling the behavior of the method GetUploadByID.
 	GetUploadByIDFunc *DBGetUploadByIDFunc
 	// GetUploadsByRepoFunc is an instance of a mock function object
 	// controlling the behavior of the method GetUploadsByRepo.
 	GetUploadsByRepoFunc *DBGetUploadsByRepoFunc
+	// IndexMonikerReferencesFunc is an instance of a mock function object
+	// controlling the behavior of the method IndexMonikerReferences.
+	IndexMonikerReferencesFunc *DBIndexMonikerReferencesFunc
+	// InsertIndexFunc is an instance of a mock function object controlling
+	// the behavior of the method InsertIndex.
+	InsertIndexFunc *DBInsertIndexFunc
+	// MarkIndexCompleteFunc is an instance of a mock function object
+	// controlling the behavior of the method MarkIndexComplete.
+	MarkIndexCompleteFunc *DBMarkIndexCompleteFunc
+	// MarkIndexErroredFunc is an instance of a mock function object
+	// controlling the behavior of the method MarkIndexErrored.
+	MarkIndexErroredFunc *DBMarkIndexErroredFunc
+	// MonikerReferenceCountFunc is an instance of a mock function object
+	// controlling the behavior of the method MonikerReferenceCount.
+	MonikerReferenceCountFunc *DBMonikerReferenceCountFunc
+	// MonikerReferenceDumpIDsFunc is an instance of a mock function object
+	// controlling the behavior of the method MonikerReferenceDumpIDs.
+	MonikerReferenceDumpIDsFunc *DBMonikerReferenceDumpIDsFunc
 	// PackageReferencePagerFunc is an instance of a mock function object
 	// controlling the behavior of the method PackageReferencePager.
 	PackageReferencePagerFunc *DBPackageReferencePagerFunc
+	// RequeueIndexFunc is an instance of a mock function object controlling
+	// the behavior of the method RequeueIndex.
+	RequeueIndexFunc *DBRequeueIndexFunc
 	// ResetStalledFunc is an instance of a mock function object controlling
 	// the behavior of the method ResetStalled.
 	ResetStalledFunc *DBResetStalledFunc
+	// ResetStalledIndexesFunc is an instance of a mock function object
+	// controlling the behavior of the method ResetStalledIndexes.
+	ResetStalledIndexesFunc *DBResetStalledIndexesFunc
 	// SameRepoPagerFunc is an instance of a mock function object
 	// controlling the behavior of the method SameRepoPager.
 	SameRepoPagerFunc *DBSameRepoPagerFunc
+	// UpdateCommitGraphFunc is an instance of a mock function object
+	// controlling the behavior of the method UpdateCommitGraph.
+	UpdateCommitGraphFunc *DBUpdateCommitGraphFunc
+	// UpdateCommitsFunc is an instance of a mock function object
+	// controlling the behavior of the method UpdateCommits.
+	UpdateCommitsFunc *DBUpdateCommitsFunc
 }
 
 // NewMockDB creates a new mock of the DB interface. All methods return zero
@@ -61,10 +107,15 @@ func NewMockDB() *MockDB {
 			},
 		},
 		DeleteUploadByIDFunc: &DBDeleteUploadByIDFunc{
-			defaultHook: func(context.Context, int, func(repositoryID int) (string, error)) (bool, error) {
+			defaultHook: func(context.Context, int) (bool, error) {
 				return false, nil
 			},
 		},
+		DequeueIndexFunc: &DBDequeueIndexFunc{
+			defaultHook: func(context.Context, time.Time) (db.Index, bool, error) {
+				return db.Index{}, false, nil
+			},
+		},
 		EnqueueFunc: &DBEnqueueFunc{
 			defaultHook: func(context.Context, string, string, string, int, string) (int, db.TxCloser, error) {
 				return 0, nil, nil
@@ -75,11 +126,26 @@ func NewMockDB() *MockDB {
 				return nil, nil
 			},
 		},
+		GetCommitGraphStatesFunc: &DBGetCommitGraphStatesFunc{
+			defaultHook: func(context.Context) ([]db.CommitGraphState, error) {
+				return nil, nil
+			},
+		},
 		GetDumpByIDFunc: &DBGetDumpByIDFunc{
 			defaultHook: func(context.Context, int) (db.Dump, bool, error) {
 				return db.Dump{}, false, nil
 			},
 		},
+		GetIndexByIDFunc: &DBGetIndexByIDFunc{
+			defaultHook: func(context.Context, int) (db.Index, bool, error) {
+				return db.Index{}, false, nil
+			},
+		},
+		GetIndexesFunc: &DBGetIndexesFunc{
+			defaultHook: func(context.Context, int, string, int, int) ([]db.Index, int, error) {
+				return nil, 0, nil
+			},
+		},
 		GetPackageFunc: &DBGetPackageFunc{
 			defaultHook: func(context.Context, string, string, string) (db.Dump, bool, error) {
 				return db.Dump{}, false, nil
@@ -90,6 +156,11 @@ func NewMockDB() *MockDB {
 				return nil, nil
 			},
 		},
+		GetUnindexedDumpIDsFunc: &DBGetUnindexedDumpIDsFunc{
+			defaultHook: func(context.Context, int) ([]int, error) {
+				return nil, nil
+			},
+		},
 		GetUploadByIDFunc: &DBGetUploadByIDFunc{
 			defaultHook: func(context.Context, int) (db.Upload, bool, error) {
 				return db.Upload{}, false, nil
@@ -100,21 +171,71 @@ func NewMockDB() *MockDB {
 				return nil, 0, nil
 			},
 		},
+		IndexMonikerReferencesFunc: &DBIndexMonikerReferencesFunc{
+			defaultHook: func(context.Context, int, []db.MonikerReference) error {
+				return nil
+			},
+		},
+		InsertIndexFunc: &DBInsertIndexFunc{
+			defaultHook: func(context.Context, int, string, string) (int, bool, error) {
+				return 0, false, nil
+			},
+		},
+		MarkIndexCompleteFunc: &DBMarkIndexCompleteFunc{
+			defaultHook: func(context.Context, int, int) error {
+				return nil
+			},
+		},
+		MarkIndexErroredFunc: &DBMarkIndexErroredFunc{
+			defaultHook: func(context.Context, int, string) error {
+				return nil
+			},
+		},
+		MonikerReferenceCountFunc: &DBMonikerReferenceCountFunc{
+			defaultHook: func(context.Context, string, string, string, string) (int, error) {
+				return 0, nil
+			},
+		},
+		MonikerReferenceDumpIDsFunc: &DBMonikerReferenceDumpIDsFunc{
+			defaultHook: func(context.Context, string, string, string, string, int, int, int) ([]int, error) {
+				return nil, nil
+			},
+		},
 		PackageReferencePagerFunc: &DBPackageReferencePagerFunc{
 			defaultHook: func(context.Context, string, string, string, int, int) (int, db.ReferencePager, error) {
 				return 0, nil, nil
 			},
 		},
+		RequeueIndexFunc: &DBRequeueIndexFunc{
+			defaultHook: func(context.Context, int, string, time.Time) error {
+				return nil
+			},
+		},
 		ResetStalledFunc: &DBResetStalledFunc{
 			defaultHook: func(context.Context, time.Time) ([]int, error) {
 				return nil, nil
 			},
 		},
+		ResetStalledIndexesFunc: &DBResetStalledIndexesFunc{
+			defaultHook: func(context.Context, time.Time) ([]int, error) {
+				return nil, nil
+			},
+		},
 		SameRepoPagerFunc: &DBSameRepoPagerFunc{
 			defaultHook: func(context.Context, int, string, string, string, string, int) (int, db.ReferencePager, error) {
 				return 0, nil, nil
 			},
 		},
+		UpdateCommitGraphFunc: &DBUpdateCommitGraphFunc{
+			defaultHook: func(context.Context, int, string) error {
+				return nil
+			},
+		},
+		UpdateCommitsFunc: &DBUpdateCommitsFunc{
+			defaultHook: func(context.Context, int, commitgraph.Graph) error {
+				return nil
+			},
+		},
 	}
 }
 
@@ -128,36 +249,81 @@ func NewMockDBFrom(i db.DB) *MockDB {
 		DeleteUploadByIDFunc: &DBDeleteUploadByIDFunc{
 			defaultHook: i.DeleteUploadByID,
 		},
+		DequeueIndexFunc: &DBDequeueIndexFunc{
+			defaultHook: i.DequeueIndex,
+		},
 		EnqueueFunc: &DBEnqueueFunc{
 			defaultHook: i.Enqueue,
 		},
 		FindClosestDumpsFunc: &DBFindClosestDumpsFunc{
 			defaultHook: i.FindClosestDumps,
 		},
+		GetCommitGraphStatesFunc: &DBGetCommitGraphStatesFunc{
+			defaultHook: i.GetCommitGraphStates,
+		},
 		GetDumpByIDFunc: &DBGetDumpByIDFunc{
 			defaultHook: i.GetDumpByID,
 		},
+		GetIndexByIDFunc: &DBGetIndexByIDFunc{
+			defaultHook: i.GetIndexByID,
+		},
+		GetIndexesFunc: &DBGetIndexesFunc{
+			defaultHook: i.GetIndexes,
+		},
 		GetPackageFunc: &DBGetPackageFunc{
 			defaultHook: i.GetPackage,
 		},
 		GetStatesFunc: &DBGetStatesFunc{
 			defaultHook: i.GetStates,
 		},
+		GetUnindexedDumpIDsFunc: &DBGetUnindexedDumpIDsFunc{
+			defaultHook: i.GetUnindexedDumpIDs,
+		},
 		GetUploadByIDFunc: &DBGetUploadByIDFunc{
 			defaultHook: i.GetUploadByID,
 		},
 		GetUploadsByRepoFunc: &DBGetUploadsByRepoFunc{
 			defaultHook: i.GetUploadsByRepo,
 		},
+		IndexMonikerReferencesFunc: &DBIndexMonikerReferencesFunc{
+			defaultHook: i.IndexMonikerReferences,
+		},
+		InsertIndexFunc: &DBInsertIndexFunc{
+			defaultHook: i.InsertIndex,
+		},
+		MarkIndexCompleteFunc: &DBMarkIndexCompleteFunc{
+			defaultHook: i.MarkIndexComplete,
+		},
+		MarkIndexErroredFunc: &DBMarkIndexErroredFunc{
+			defaultHook: i.MarkIndexErrored,
+		},
+		MonikerReferenceCountFunc: &DBMonikerReferenceCountFunc{
+			defaultHook: i.MonikerReferenceCount,
+		},
+		MonikerReferenceDumpIDsFunc: &DBMonikerReferenceDumpIDsFunc{
+			defaultHook: i.MonikerReferenceDumpIDs,
+		},
 		PackageReferencePagerFunc: &DBPackageReferencePagerFunc{
 			defaultHook: i.PackageReferencePager,
 		},
+		RequeueIndexFunc: &DBRequeueIndexFunc{
+			defaultHook: i.RequeueIndex,
+		},
 		ResetStalledFunc: &DBResetStalledFunc{
 			defaultHook: i.ResetStalled,
 		},
+		ResetStalledIndexesFunc: &DBResetStalledIndexesFunc{
+			defaultHook: i.ResetStalledIndexes,
+		},
 		SameRepoPagerFunc: &DBSameRepoPagerFunc{
 			defaultHook: i.SameRepoPager,
 		},
+		UpdateCommitGraphFunc: &DBUpdateCommitGraphFunc{
+			defaultHook: i.UpdateCommitGraph,
+		},
+		UpdateCommitsFunc: &DBUpdateCommitsFunc{
+			defaultHook: i.UpdateCommits,
+		},
 	}
 }
 
@@ -273,24 +439,24 @@ func (c DBDeleteOldestDumpFuncCall) Results() []interface{} {
 // DBDeleteUploadByIDFunc describes the behavior when the DeleteUploadByID
 // method of the parent MockDB instance is invoked.
 type DBDeleteUploadByIDFunc struct {
-	defaultHook func(context.Context, int, func(repositoryID int) (string, error)) (bool, error)
-	hooks       []func(context.Context, int, func(repositoryID int) (string, error)) (bool, error)
+	defaultHook func(context.Context, int) (bool, error)
+	hooks       []func(context.Context, int) (bool, error)
 	history     []DBDeleteUploadByIDFuncCall
 	mutex       sync.Mutex
 }
 
 // DeleteUploadByID delegates to the next hook function in the queue and
 // stores the parameter and result values of this invocation.
-func (m *MockDB) DeleteUploadByID(v0 context.Context, v1 int, v2 func(repositoryID int) (string, error)) (bool, error) {
-	r0, r1 := m.DeleteUploadByIDFunc.nextHook()(v0, v1, v2)
-	m.DeleteUploadByIDFunc.appendCall(DBDeleteUploadByIDFuncCall{v0, v1, v2, r0, r1})
+func (m *MockDB) DeleteUploadByID(v0 context.Context, v1 int) (bool, error) {
+	r0, r1 := m.DeleteUploadByIDFunc.nextHook()(v0, v1)
+	m.DeleteUploadByIDFunc.appendCall(DBDeleteUploadByIDFuncCall{v0, v1, r0, r1})
 	return r0, r1
 }
 
 // SetDefaultHook sets function that is called when the DeleteUploadByID
 // method of the parent MockDB instance is invoked and the hook queue is
 // empty.
-func (f *DBDeleteUploadByIDFunc) SetDefaultHook(hook func(context.Context, int, func(repositoryID int) (string, error)) (bool, error)) {
+func (f *DBDeleteUploadByIDFunc) SetDefaultHook(hook func(context.Context, int) (bool, error)) {
 	f.defaultHook = hook
 }
 
@@ -298,7 +464,7 @@ func (f *DBDeleteUploadByIDFunc) SetDefaultHook(hook func(context.Context, int,
 // DeleteUploadByID method of the parent MockDB instance inovkes the hook at
 // the front of the queue and discards it. After the queue is empty, the
 // default hook function is invoked for any future action.
-func (f *DBDeleteUploadByIDFunc) PushHook(hook func(context.Context, int, func(repositoryID int) (string, error)) (bool, error)) {
+func (f *DBDeleteUploadByIDFunc) PushHook(hook func(context.Context, int) (bool, error)) {
 	f.mutex.Lock()
 	f.hooks = append(f.hooks, hook)
 	f.mutex.Unlock()
@@ -307,7 +473,7 @@ func (f *DBDeleteUploadByIDFunc) PushHook(hook func(context.Context, int, func(r
 // SetDefaultReturn calls SetDefaultDefaultHook with a function that returns
 // the given values.
 func (f *DBDeleteUploadByIDFunc) SetDefaultReturn(r0 bool, r1 error) {
-	f.SetDefaultHook(func(context.Context, int, func(repositoryID int) (string, error)) (bool, error) {
+	f.SetDefaultHook(func(context.Context, int) (bool, error) {
 		return r0, r1
 	})
 }
@@ -315,12 +481,12 @@ func (f *DBDeleteUploadByIDFunc) SetDefaultReturn(r0 bool, r1 error) {
 // PushReturn calls PushDefaultHook with a function that returns the given
 // values.
 func (f *DBDeleteUploadByIDFunc) PushReturn(r0 bool, r1 error) {
-	f.PushHook(func(context.Context, int, func(repositoryID int) (string, error)) (bool, error) {
+	f.PushHook(func(context.Context, int) (bool, error) {
 		return r0, r1
 	})
 }
 
-func (f *DBDeleteUploadByIDFunc) nextHook() func(context.Context, int, func(repositoryID int) (string, error)) (bool, error) {
+func (f *DBDeleteUploadByIDFunc) nextHook() func(context.Context, int) (bool, error) {
 	f.mutex.Lock()
 	defer f.mutex.Unlock()
 
@@ -359,9 +525,6 @@ type DBDeleteUploadByIDFuncCall struct {
 	// Arg1 is the value of the 2nd argument passed to this method
 	// invocation.
 	Arg1 int
-	// Arg2 is the value of the 3rd argument passed to this method
-	// invocation.
-	Arg2 func(repositoryID int) (string, error)
 	// Result0 is the value of the 1st result returned from this method
 	// invocation.
 	Result0 bool
@@ -373,7 +536,7 @@ type DBDeleteUploadByIDFuncCall struct {
 // Args returns an interface slice containing the arguments of this
 // invocation.
 func (c DBDeleteUploadByIDFuncCall) Args() []interface{} {
-	return []interface{}{c.Arg0, c.Arg1, c.Arg2}
+	return []interface{}{c.Arg0, c.Arg1}
 }
 
 // Results returns an interface slice containing the results of this
@@ -382,6 +545,117 @@ func (c DBDeleteUploadByIDFuncCall) Results() []interface{} {
 	return []interface{}{c.Result0, c.Result1}
 }
 
+// DBDequeueIndexFunc describes the behavior when the DequeueIndex method of
+// the parent MockDB instance is invoked.
+type DBDequeueIndexFunc struct {
+	defaultHook func(context.Context, time.Time) (db.Index, bool, error)
+	hooks       []func(context.Context, time.Time) (db.Index, bool, error)
+	history     []DBDequeueIndexFuncCall
+	mutex       sync.Mutex
+}
+
+// DequeueIndex delegates to the next hook function in the queue and stores
+// the parameter and result values of this invocation.
+func (m *MockDB) DequeueIndex(v0 context.Context, v1 time.Time) (db.Index, bool, error) {
+	r0, r1, r2 := m.DequeueIndexFunc.nextHook()(v0, v1)
+	m.DequeueIndexFunc.appendCall(DBDequeueIndexFuncCall{v0, v1, r0, r1, r2})
+	return r0, r1, r2
+}
+
+// SetDefaultHook sets function that is called when the DequeueIndex method
+// of the parent MockDB instance is invoked and the hook queue is empty.
+func (f *DBDequeueIndexFunc) SetDefaultHook(hook func(context.Context, time.Time) (db.Index, bool, error)) {
+	f.defaultHook = hook
+}
+
+// PushHook adds a function to the end of hook queue. Each invocation of the
+// DequeueIndex method of the parent MockDB instance inovkes the hook at the
+// front of the queue and discards it. After the queue is empty, the default
+// hook function is invoked for any future action.
+func (f *DBDequeueIndexFunc) PushHook(hook func(context.Context, time.Time) (db.Index, bool, error)) {
+	f.mutex.Lock()
+	f.hooks = append(f.hooks, hook)
+	f.mutex.Unlock()
+}
+
+// SetDefaultReturn calls SetDefaultDefaultHook with a function that returns
+// the given values.
+func (f *DBDequeueIndexFunc) SetDefaultReturn(r0 db.Index, r1 bool, r2 error) {
+	f.SetDefaultHook(func(context.Context, time.Time) (db.Index, bool, error) {
+		return r0, r1, r2
+	})
+}
+
+// PushReturn calls PushDefaultHook with a function that returns the given
+// values.
+func (f *DBDequeueIndexFunc) PushReturn(r0 db.Index, r1 bool, r2 error) {
+	f.PushHook(func(context.Context, time.Time) (db.Index, bool, error) {
+		return r0, r1, r2
+	})
+}
+
+func (f *DBDequeueIndexFunc) nextHook() func(context.Context, time.Time) (db.Index, bool, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if len(f.hooks) == 0 {
+		return f.defaultHook
+	}
+
+	hook := f.hooks[0]
+	f.hooks = f.hooks[1:]
+	return hook
+}
+
+func (f *DBDequeueIndexFunc) appendCall(r0 DBDequeueIndexFuncCall) {
+	f.mutex.Lock()
+	f.history = append(f.history, r0)
+	f.mutex.Unlock()
+}
+
+// History returns a sequence of DBDequeueIndexFuncCall objects describing
+// the invocations of this function.
+func (f *DBDequeueIndexFunc) History() []DBDequeueIndexFuncCall {
+	f.mutex.Lock()
+	history := make([]DBDequeueIndexFuncCall, len(f.history))
+	copy(history, f.history)
+	f.mutex.Unlock()
+
+	return history
+}
+
+// DBDequeueIndexFuncCall is an object that describes an invocation of
+// method DequeueIndex on an instance of MockDB.
+type DBDequeueIndexFuncCall struct {
+	// Arg0 is the value of the 1st argument passed to this method
+	// invocation.
+	Arg0 context.Context
+	// Arg1 is the value of the 2nd argument passed to this method
+	// invocation.
+	Arg1 time.Time
+	// Result0 is the value of the 1st result returned from this method
+	// invocation.
+	Result0 db.Index
+	// Result1 is the value of the 2nd result returned from this method
+	// invocation.
+	Result1 bool
+	// Result2 is the value of the 3rd result returned from this method
+	// invocation.
+	Result2 error
+}
+
+// Args returns an interface slice containing the arguments of this
+// invocation.
+func (c DBDequeueIndexFuncCall) Args() []interface{} {
+	return []interface{}{c.Arg0, c.Arg1}
+}
+
+// Results returns an interface slice containing the results of this
+// invocation.
+func (c DBDequeueIndexFuncCall) Results() []interface{} {
+	return []interface{}{c.Result0, c.Result1, c.Result2}
+}
+
 // DBEnqueueFunc describes the behavior when the Enqueue method of the
 // parent MockDB instance is invoked.
 type DBEnqueueFunc struct {
@@ -620,6 +894,112 @@ func (c DBFindClosestDumpsFuncCall) Results() []interface{} {
 	return []interface{}{c.Result0, c.Result1}
 }
 
+// DBGetCommitGraphStatesFunc describes the behavior when the
+// GetCommitGraphStates method of the parent MockDB instance is invoked.
+type DBGetCommitGraphStatesFunc struct {
+	defaultHook func(context.Context) ([]db.CommitGraphState, error)
+	hooks       []func(context.Context) ([]db.CommitGraphState, error)
+	history     []DBGetCommitGraphStatesFuncCall
+	mutex       sync.Mutex
+}
+
+// GetCommitGraphStates delegates to the next hook function in the queue and
+// stores the parameter and result values of this invocation.
+func (m *MockDB) GetCommitGraphStates(v0 context.Context) ([]db.CommitGraphState, error) {
+	r0, r1 := m.GetCommitGraphStatesFunc.nextHook()(v0)
+	m.GetCommitGraphStatesFunc.appendCall(DBGetCommitGraphStatesFuncCall{v0, r0, r1})
+	return r0, r1
+}
+
+// SetDefaultHook sets function that is called when the GetCommitGraphStates
+// method of the parent MockDB instance is invoked and the hook queue is
+// empty.
+func (f *DBGetCommitGraphStatesFunc) SetDefaultHook(hook func(context.Context) ([]db.CommitGraphState, error)) {
+	f.defaultHook = hook
+}
+
+// PushHook adds a function to the end of hook queue. Each invocation of the
+// GetCommitGraphStates method of the parent MockDB instance inovkes the
+// hook at the front of the queue and discards it. After the queue is empty,
+// the default hook function is invoked for any future action.
+func (f *DBGetCommitGraphStatesFunc) PushHook(hook func(context.Context) ([]db.CommitGraphState, error)) {
+	f.mutex.Lock()
+	f.hooks = append(f.hooks, hook)
+	f.mutex.Unlock()
+}
+
+// SetDefaultReturn calls SetDefaultDefaultHook with a function that returns
+// the given values.
+func (f *DBGetCommitGraphStatesFunc) SetDefaultReturn(r0 []db.CommitGraphState, r1 error) {
+	f.SetDefaultHook(func(context.Context) ([]db.CommitGraphState, error) {
+		return r0, r1
+	})
+}
+
+// PushReturn calls PushDefaultHook with a function that returns the given
+// values.
+func (f *DBGetCommitGraphStatesFunc) PushReturn(r0 []db.CommitGraphState, r1 error) {
+	f.PushHook(func(context.Context) ([]db.CommitGraphState, error) {
+		return r0, r1
+	})
+}
+
+func (f *DBGetCommitGraphStatesFunc) nextHook() func(context.Context) ([]db.CommitGraphState, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if len(f.hooks) == 0 {
+		return f.defaultHook
+	}
+
+	hook := f.hooks[0]
+	f.hooks = f.hooks[1:]
+	return hook
+}
+
+func (f *DBGetCommitGraphStatesFunc) appendCall(r0 DBGetCommitGraphStatesFuncCall) {
+	f.mutex.Lock()
+	f.history = append(f.history, r0)
+	f.mutex.Unlock()
+}
+
+// History returns a sequence of DBGetCommitGraphStatesFuncCall objects
+// describing the invocations of this function.
+func (f *DBGetCommitGraphStatesFunc) History() []DBGetCommitGraphStatesFuncCall {
+	f.mutex.Lock()
+	history := make([]DBGetCommitGraphStatesFuncCall, len(f.history))
+	copy(history, f.history)
+	f.mutex.Unlock()
+
+	return history
+}
+
+// DBGetCommitGraphStatesFuncCall is an object that describes an invocation
+// of method GetCommitGraphStates on an instance of MockDB.
+type DBGetCommitGraphStatesFuncCall struct {
+	// Arg0 is the value of the 1st argument passed to this method
+	// invocation.
+	Arg0 context.Context
+	// Result0 is the value of the 1st result returned from this method
+	// invocation.
+	Result0 []db.CommitGraphState
+	// Result1 is the value of the 2nd result returned from this method
+	// invocation.
+	Result1 error
+}
+
+// Args returns an interface slice containing the arguments of this
+// invocation.
+func (c DBGetCommitGraphStatesFuncCall) Args() []interface{} {
+	return []interface{}{c.Arg0}
+}
+
+// Results returns an interface slice containing the results of this
+// invocation.
+func (c DBGetCommitGraphStatesFuncCall) Results() []interface{} {
+	return []interface{}{c.Result0, c.Result1}
+}
+
 // DBGetDumpByIDFunc describes the behavior when the GetDumpByID method of
 // the parent MockDB instance is invoked.
 type DBGetDumpByIDFunc struct {
@@ -731,34 +1111,34 @@ func (c DBGetDumpByIDFuncCall) Results() []interface{} {
 	return []interface{}{c.Result0, c.Result1, c.Result2}
 }
 
-// DBGetPackageFunc describes the behavior when the GetPackage method of the
-// parent MockDB instance is invoked.
-type DBGetPackageFunc struct {
-	defaultHook func(context.Context, string, string, string) (db.Dump, bool, error)
-	hooks       []func(context.Context, string, string, string) (db.Dump, bool, error)
-	history     []DBGetPackageFuncCall
+// DBGetIndexByIDFunc describes the behavior when the GetIndexByID method of
+// the parent MockDB instance is invoked.
+type DBGetIndexByIDFunc struct {
+	defaultHook func(context.Context, int) (db.Index, bool, error)
+	hooks       []func(context.Context, int) (db.Index, bool, error)
+	history     []DBGetIndexByIDFuncCall
 	mutex       sync.Mutex
 }
 
-// GetPackage delegates to the next hook function in the queue and stores
+// GetIndexByID delegates to the next hook function in the queue and stores
 // the parameter and result values of this invocation.
-func (m *MockDB) GetPackage(v0 context.Context, v1 string, v2 string, v3 string) (db.Dump, bool, error) {
-	r0, r1, r2 := m.GetPackageFunc.nextHook()(v0, v1, v2, v3)
-	m.GetPackageFunc.appendCall(DBGetPackageFuncCall{v0, v1, v2, v3, r0, r1, r2})
+func (m *MockDB) GetIndexByID(v0 context.Context, v1 int) (db.Index, bool, error) {
+	r0, r1, r2 := m.GetIndexByIDFunc.nextHook()(v0, v1)
+	m.GetIndexByIDFunc.appendCall(DBGetIndexByIDFuncCall{v0, v1, r0, r1, r2})
 	return r0, r1, r2
 }
 
-// SetDefaultHook sets function that is called when the GetPackage method of
-// the parent MockDB instance is invoked and the hook queue is empty.
-func (f *DBGetPackageFunc) SetDefaultHook(hook func(context.Context, string, string, string) (db.Dump, bool, error)) {
+// SetDefaultHook sets function that is called when the GetIndexByID method
+// of the parent MockDB instance is invoked and the hook queue is empty.
+func (f *DBGetIndexByIDFunc) SetDefaultHook(hook func(context.Context, int) (db.Index, bool, error)) {
 	f.defaultHook = hook
 }
 
 // PushHook adds a function to the end of hook queue. Each invocation of the
-// GetPackage method of the parent MockDB instance inovkes the hook at the
+// GetIndexByID method of the parent MockDB instance inovkes the hook at the
 // front of the queue and discards it. After the queue is empty, the default
 // hook function is invoked for any future action.
-func (f *DBGetPackageFunc) PushHook(hook func(context.Context, string, string, string) (db.Dump, bool, error)) {
+func (f *DBGetIndexByIDFunc) PushHook(hook func(context.Context, int) (db.Index, bool, error)) {
 	f.mutex.Lock()
 	f.hooks = append(f.hooks, hook)
 	f.mutex.Unlock()
@@ -766,21 +1146,21 @@ func (f *DBGetPackageFunc) PushHook(hook func(context.Context, string, string, s
 
 // SetDefaultReturn calls SetDefaultDefaultHook with a function that returns
 // the given values.
-func (f *DBGetPackageFunc) SetDefaultReturn(r0 db.Dump, r1 bool, r2 error) {
-	f.SetDefaultHook(func(context.Context, string, string, string) (db.Dump, bool, error) {
+func (f *DBGetIndexByIDFunc) SetDefaultReturn(r0 db.Index, r1 bool, r2 error) {
+	f.SetDefaultHook(func(context.Context, int) (db.Index, bool, error) {
 		return r0, r1, r2
 	})
 }
 
 // PushReturn calls PushDefaultHook with a function that returns the given
 // values.
-func (f *DBGetPackageFunc) PushReturn(r0 db.Dump, r1 bool, r2 error) {
-	f.PushHook(func(context.Context, string, string, string) (db.Dump, bool, error) {
+func (f *DBGetIndexByIDFunc) PushReturn(r0 db.Index, r1 bool, r2 error) {
+	f.PushHook(func(context.Context, int) (db.Index, bool, error) {
 		return r0, r1, r2
 	})
 }
 
-func (f *DBGetPackageFunc) nextHook() func(context.Context, string, string, string) (db.Dump, bool, error) {
+func (f *DBGetIndexByIDFunc) nextHook() func(context.Context, int) (db.Index, bool, error) {
 	f.mutex.Lock()
 	defer f.mutex.Unlock()
 
@@ -793,41 +1173,272 @@ func (f *DBGetPackageFunc) nextHook() func(context.Context, string, string, stri
 	return hook
 }
 
-func (f *DBGetPackageFunc) appendCall(r0 DBGetPackageFuncCall) {
+func (f *DBGetIndexByIDFunc) appendCall(r0 DBGetIndexByIDFuncCall) {
 	f.mutex.Lock()
 	f.history = append(f.history, r0)
 	f.mutex.Unlock()
 }
 
-// History returns a sequence of DBGetPackageFuncCall objects describing the
-// invocations of this function.
-func (f *DBGetPackageFunc) History() []DBGetPackageFuncCall {
+// History returns a sequence of DBGetIndexByIDFuncCall objects describing
+// the invocations of this function.
+func (f *DBGetIndexByIDFunc) History() []DBGetIndexByIDFuncCall {
 	f.mutex.Lock()
-	history := make([]DBGetPackageFuncCall, len(f.history))
+	history := make([]DBGetIndexByIDFuncCall, len(f.history))
 	copy(history, f.history)
 	f.mutex.Unlock()
 
 	return history
 }
 
-// DBGetPackageFuncCall is an object that describes an invocation of method
-// GetPackage on an instance of MockDB.
-type DBGetPackageFuncCall struct {
+// DBGetIndexByIDFuncCall is an object that describes an invocation of
+// method GetIndexByID on an instance of MockDB.
+type DBGetIndexByIDFuncCall struct {
 	// Arg0 is the value of the 1st argument passed to this method
 	// invocation.
 	Arg0 context.Context
 	// Arg1 is the value of the 2nd argument passed to this method
 	// invocation.
-	Arg1 string
-	// Arg2 is the value of the 3rd argument passed to this method
-	// invocation.
-	Arg2 string
-	// Arg3 is the value of the 4th argument passed to this method
-	// invocation.
-	Arg3 string
+	Arg1 int
 	// Result0 is the value of the 1st result returned from this method
 	// invocation.
-	Result0 db.Dump
+	Result0 db.Index
+	// Result1 is the value of the 2nd result returned from this method
+	// invocation.
+	Result1 bool
+	// Result2 is the value of the 3rd result returned from this method
+	// invocation.
+	Result2 error
+}
+
+// Args returns an interface slice containing the arguments of this
+// invocation.
+func (c DBGetIndexByIDFuncCall) Args() []interface{} {
+	return []interface{}{c.Arg0, c.Arg1}
+}
+
+// Results returns an interface slice containing the results of this
+// invocation.
+func (c DBGetIndexByIDFuncCall) Results() []interface{} {
+	return []interface{}{c.Result0, c.Result1, c.Result2}
+}
+
+// DBGetIndexesFunc describes the behavior when the GetIndexes method of the
+// parent MockDB instance is invoked.
+type DBGetIndexesFunc struct {
+	defaultHook func(context.Context, int, string, int, int) ([]db.Index, int, error)
+	hooks       []func(context.Context, int, string, int, int) ([]db.Index, int, error)
+	history     []DBGetIndexesFuncCall
+	mutex       sync.Mutex
+}
+
+// GetIndexes delegates to the next hook function in the queue and stores
+// the parameter and result values of this invocation.
+func (m *MockDB) GetIndexes(v0 context.Context, v1 int, v2 string, v3 int, v4 int) ([]db.Index, int, error) {
+	r0, r1, r2 := m.GetIndexesFunc.nextHook()(v0, v1, v2, v3, v4)
+	m.GetIndexesFunc.appendCall(DBGetIndexesFuncCall{v0, v1, v2, v3, v4, r0, r1, r2})
+	return r0, r1, r2
+}
+
+// SetDefaultHook sets function that is called when the GetIndexes method of
+// the parent MockDB instance is invoked and the hook queue is empty.
+func (f *DBGetIndexesFunc) SetDefaultHook(hook func(context.Context, int, string, int, int) ([]db.Index, int, error)) {
+	f.defaultHook = hook
+}
+
+// PushHook adds a function to the end of hook queue. Each invocation of the
+// GetIndexes method of the parent MockDB instance inovkes the hook at the
+// front of the queue and discards it. After the queue is empty, the default
+// hook function is invoked for any future action.
+func (f *DBGetIndexesFunc) PushHook(hook func(context.Context, int, string, int, int) ([]db.Index, int, error)) {
+	f.mutex.Lock()
+	f.hooks = append(f.hooks, hook)
+	f.mutex.Unlock()
+}
+
+// SetDefaultReturn calls SetDefaultDefaultHook with a function that returns
+// the given values.
+func (f *DBGetIndexesFunc) SetDefaultReturn(r0 []db.Index, r1 int, r2 error) {
+	f.SetDefaultHook(func(context.Context, int, string, int, int) ([]db.Index, int, error) {
+		return r0, r1, r2
+	})
+}
+
+// PushReturn calls PushDefaultHook with a function that returns the given
+// values.
+func (f *DBGetIndexesFunc) PushReturn(r0 []db.Index, r1 int, r2 error) {
+	f.PushHook(func(context.Context, int, string, int, int) ([]db.Index, int, error) {
+		return r0, r1, r2
+	})
+}
+
+func (f *DBGetIndexesFunc) nextHook() func(context.Context, int, string, int, int) ([]db.Index, int, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if len(f.hooks) == 0 {
+		return f.defaultHook
+	}
+
+	hook := f.hooks[0]
+	f.hooks = f.hooks[1:]
+	return hook
+}
+
+func (f *DBGetIndexesFunc) appendCall(r0 DBGetIndexesFuncCall) {
+	f.mutex.Lock()
+	f.history = append(f.history, r0)
+	f.mutex.Unlock()
+}
+
+// History returns a sequence of DBGetIndexesFuncCall objects describing the
+// invocations of this function.
+func (f *DBGetIndexesFunc) History() []DBGetIndexesFuncCall {
+	f.mutex.Lock()
+	history := make([]DBGetIndexesFuncCall, len(f.history))
+	copy(history, f.history)
+	f.mutex.Unlock()
+
+	return history
+}
+
+// DBGetIndexesFuncCall is an object that describes an invocation of method
+// GetIndexes on an instance of MockDB.
+type DBGetIndexesFuncCall struct {
+	// Arg0 is the value of the 1st argument passed to this method
+	// invocation.
+	Arg0 context.Context
+	// Arg1 is the value of the 2nd argument passed to this method
+	// invocation.
+	Arg1 int
+	// Arg2 is the value of the 3rd argument passed to this method
+	// invocation.
+	Arg2 string
+	// Arg3 is the value of the 4th argument passed to this method
+	// invocation.
+	Arg3 int
+	// Arg4 is the value of the 5th argument passed to this method
+	// invocation.
+	Arg4 int
+	// Result0 is the value of the 1st result returned from this method
+	// invocation.
+	Result0 []db.Index
+	// Result1 is the value of the 2nd result returned from this method
+	// invocation.
+	Result1 int
+	// Result2 is the value of the 3rd result returned from this method
+	// invocation.
+	Result2 error
+}
+
+// Args returns an interface slice containing the arguments of this
+// invocation.
+func (c DBGetIndexesFuncCall) Args() []interface{} {
+	return []interface{}{c.Arg0, c.Arg1, c.Arg2, c.Arg3, c.Arg4}
+}
+
+// Results returns an interface slice containing the results of this
+// invocation.
+func (c DBGetIndexesFuncCall) Results() []interface{} {
+	return []interface{}{c.Result0, c.Result1, c.Result2}
+}
+
+// DBGetPackageFunc describes the behavior when the GetPackage method of the
+// parent MockDB instance is invoked.
+type DBGetPackageFunc struct {
+	defaultHook func(context.Context, string, string, string) (db.Dump, bool, error)
+	hooks       []func(context.Context, string, string, string) (db.Dump, bool, error)
+	history     []DBGetPackageFuncCall
+	mutex       sync.Mutex
+}
+
+// GetPackage delegates to the next hook function in the queue and stores
+// the parameter and result values of this invocation.
+func (m *MockDB) GetPackage(v0 context.Context, v1 string, v2 string, v3 string) (db.Dump, bool, error) {
+	r0, r1, r2 := m.GetPackageFunc.nextHook()(v0, v1, v2, v3)
+	m.GetPackageFunc.appendCall(DBGetPackageFuncCall{v0, v1, v2, v3, r0, r1, r2})
+	return r0, r1, r2
+}
+
+// SetDefaultHook sets function that is called when the GetPackage method of
+// the parent MockDB instance is invoked and the hook queue is empty.
+func (f *DBGetPackageFunc) SetDefaultHook(hook func(context.Context, string, string, string) (db.Dump, bool, error)) {
+	f.defaultHook = hook
+}
+
+// PushHook adds a function to the end of hook queue. Each invocation of the
+// GetPackage method of the parent MockDB instance inovkes the hook at the
+// front of the queue and discards it. After the queue is empty, the default
+// hook function is invoked for any future action.
+func (f *DBGetPackageFunc) PushHook(hook func(context.Context, string, string, string) (db.Dump, bool, error)) {
+	f.mutex.Lock()
+	f.hooks = append(f.hooks, hook)
+	f.mutex.Unlock()
+}
+
+// SetDefaultReturn calls SetDefaultDefaultHook with a function that returns
+// the given values.
+func (f *DBGetPackageFunc) SetDefaultReturn(r0 db.Dump, r1 bool, r2 error) {
+	f.SetDefaultHook(func(context.Context, string, string, string) (db.Dump, bool, error) {
+		return r0, r1, r2
+	})
+}
+
+// PushReturn calls PushDefaultHook with a function that returns the given
+// values.
+func (f *DBGetPackageFunc) PushReturn(r0 db.Dump, r1 bool, r2 error) {
+	f.PushHook(func(context.Context, string, string, string) (db.Dump, bool, error) {
+		return r0, r1, r2
+	})
+}
+
+func (f *DBGetPackageFunc) nextHook() func(context.Context, string, string, string) (db.Dump, bool, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if len(f.hooks) == 0 {
+		return f.defaultHook
+	}
+
+	hook := f.hooks[0]
+	f.hooks = f.hooks[1:]
+	return hook
+}
+
+func (f *DBGetPackageFunc) appendCall(r0 DBGetPackageFuncCall) {
+	f.mutex.Lock()
+	f.history = append(f.history, r0)
+	f.mutex.Unlock()
+}
+
+// History returns a sequence of DBGetPackageFuncCall objects describing the
+// invocations of this function.
+func (f *DBGetPackageFunc) History() []DBGetPackageFuncCall {
+	f.mutex.Lock()
+	history := make([]DBGetPackageFuncCall, len(f.history))
+	copy(history, f.history)
+	f.mutex.Unlock()
+
+	return history
+}
+
+// DBGetPackageFuncCall is an object that describes an invocation of method
+// GetPackage on an instance of MockDB.
+type DBGetPackageFuncCall struct {
+	// Arg0 is the value of the 1st argument passed to this method
+	// invocation.
+	Arg0 context.Context
+	// Arg1 is the value of the 2nd argument passed to this method
+	// invocation.
+	Arg1 string
+	// Arg2 is the value of the 3rd argument passed to this method
+	// invocation.
+	Arg2 string
+	// Arg3 is the value of the 4th argument passed to this method
+	// invocation.
+	Arg3 string
+	// Result0 is the value of the 1st result returned from this method
+	// invocation.
+	Result0 db.Dump
 	// Result1 is the value of the 2nd result returned from this method
 	// invocation.
 	Result1 bool
@@ -956,34 +1567,35 @@ func (c DBGetStatesFuncCall) Results() []interface{} {
 	return []interface{}{c.Result0, c.Result1}
 }
 
-// DBGetUploadByIDFunc describes the behavior when the GetUploadByID method
-// of the parent MockDB instance is invoked.
-type DBGetUploadByIDFunc struct {
-	defaultHook func(context.Context, int) (db.Upload, bool, error)
-	hooks       []func(context.Context, int) (db.Upload, bool, error)
-	history     []DBGetUploadByIDFuncCall
+// DBGetUnindexedDumpIDsFunc describes the behavior when the
+// GetUnindexedDumpIDs method of the parent MockDB instance is invoked.
+type DBGetUnindexedDumpIDsFunc struct {
+	defaultHook func(context.Context, int) ([]int, error)
+	hooks       []func(context.Context, int) ([]int, error)
+	history     []DBGetUnindexedDumpIDsFuncCall
 	mutex       sync.Mutex
 }
 
-// GetUploadByID delegates to the next hook function in the queue and stores
-// the parameter and result values of this invocation.
-func (m *MockDB) GetUploadByID(v0 context.Context, v1 int) (db.Upload, bool, error) {
-	r0, r1, r2 := m.GetUploadByIDFunc.nextHook()(v0, v1)
-	m.GetUploadByIDFunc.appendCall(DBGetUploadByIDFuncCall{v0, v1, r0, r1, r2})
-	return r0, r1, r2
+// GetUnindexedDumpIDs delegates to the next hook function in the queue and
+// stores the parameter and result values of this invocation.
+func (m *MockDB) GetUnindexedDumpIDs(v0 context.Context, v1 int) ([]int, error) {
+	r0, r1 := m.GetUnindexedDumpIDsFunc.nextHook()(v0, v1)
+	m.GetUnindexedDumpIDsFunc.appendCall(DBGetUnindexedDumpIDsFuncCall{v0, v1, r0, r1})
+	return r0, r1
 }
 
-// SetDefaultHook sets function that is called when the GetUploadByID method
-// of the parent MockDB instance is invoked and the hook queue is empty.
-func (f *DBGetUploadByIDFunc) SetDefaultHook(hook func(context.Context, int) (db.Upload, bool, error)) {
+// SetDefaultHook sets function that is called when the GetUnindexedDumpIDs
+// method of the parent MockDB instance is invoked and the hook queue is
+// empty.
+func (f *DBGetUnindexedDumpIDsFunc) SetDefaultHook(hook func(context.Context, int) ([]int, error)) {
 	f.defaultHook = hook
 }
 
 // PushHook adds a function to the end of hook queue. Each invocation of the
-// GetUploadByID method of the parent MockDB instance inovkes the hook at
-// the front of the queue and discards it. After the queue is empty, the
+// GetUnindexedDumpIDs method of the parent MockDB instance inovkes the hook
+// at the front of the queue and discards it. After the queue is empty, the
 // default hook function is invoked for any future action.
-func (f *DBGetUploadByIDFunc) PushHook(hook func(context.Context, int) (db.Upload, bool, error)) {
+func (f *DBGetUnindexedDumpIDsFunc) PushHook(hook func(context.Context, int) ([]int, error)) {
 	f.mutex.Lock()
 	f.hooks = append(f.hooks, hook)
 	f.mutex.Unlock()
@@ -991,21 +1603,21 @@ func (f *DBGetUploadByIDFunc) PushHook(hook func(context.Context, int) (db.Uploa
 
 // SetDefaultReturn calls SetDefaultDefaultHook with a function that returns
 // the given values.
-func (f *DBGetUploadByIDFunc) SetDefaultReturn(r0 db.Upload, r1 bool, r2 error) {
-	f.SetDefaultHook(func(context.Context, int) (db.Upload, bool, error) {
-		return r0, r1, r2
+func (f *DBGetUnindexedDumpIDsFunc) SetDefaultReturn(r0 []int, r1 error) {
+	f.SetDefaultHook(func(context.Context, int) ([]int, error) {
+		return r0, r1
 	})
 }
 
 // PushReturn calls PushDefaultHook with a function that returns the given
 // values.
-func (f *DBGetUploadByIDFunc) PushReturn(r0 db.Upload, r1 bool, r2 error) {
-	f.PushHook(func(context.Context, int) (db.Upload, bool, error) {
-		return r0, r1, r2
+func (f *DBGetUnindexedDumpIDsFunc) PushReturn(r0 []int, r1 error) {
+	f.PushHook(func(context.Context, int) ([]int, error) {
+		return r0, r1
 	})
 }
 
-func (f *DBGetUploadByIDFunc) nextHook() func(context.Context, int) (db.Upload, bool, error) {
+func (f *DBGetUnindexedDumpIDsFunc) nextHook() func(context.Context, int) ([]int, error) {
 	f.mutex.Lock()
 	defer f.mutex.Unlock()
 
@@ -1018,26 +1630,26 @@ func (f *DBGetUploadByIDFunc) nextHook() func(context.Context, int) (db.Upload,
 	return hook
 }
 
-func (f *DBGetUploadByIDFunc) appendCall(r0 DBGetUploadByIDFuncCall) {
+func (f *DBGetUnindexedDumpIDsFunc) appendCall(r0 DBGetUnindexedDumpIDsFuncCall) {
 	f.mutex.Lock()
 	f.history = append(f.history, r0)
 	f.mutex.Unlock()
 }
 
-// History returns a sequence of DBGetUploadByIDFuncCall objects describing
-// the invocations of this function.
-func (f *DBGetUploadByIDFunc) History() []DBGetUploadByIDFuncCall {
+// History returns a sequence of DBGetUnindexedDumpIDsFuncCall objects
+// describing the invocations of this function.
+func (f *DBGetUnindexedDumpIDsFunc) History() []DBGetUnindexedDumpIDsFuncCall {
 	f.mutex.Lock()
-	history := make([]DBGetUploadByIDFuncCall, len(f.history))
+	history := make([]DBGetUnindexedDumpIDsFuncCall, len(f.history))
 	copy(history, f.history)
 	f.mutex.Unlock()
 
 	return history
 }
 
-// DBGetUploadByIDFuncCall is an object that describes an invocation of
-// method GetUploadByID on an instance of MockDB.
-type DBGetUploadByIDFuncCall struct {
+// DBGetUnindexedDumpIDsFuncCall is an object that describes an invocation
+// of method GetUnindexedDumpIDs on an instance of MockDB.
+type DBGetUnindexedDumpIDsFuncCall struct {
 	// Arg0 is the value of the 1st argument passed to this method
 	// invocation.
 	Arg0 context.Context
@@ -1046,56 +1658,1323 @@ type DBGetUploadByIDFuncCall struct {
 	Arg1 int
 	// Result0 is the value of the 1st result returned from this method
 	// invocation.
-	Result0 db.Upload
+	Result0 []int
 	// Result1 is the value of the 2nd result returned from this method
 	// invocation.
-	Result1 bool
-	// Result2 is the value of the 3rd result returned from this method
-	// invocation.
-	Result2 error
+	Result1 error
 }
 
 // Args returns an interface slice containing the arguments of this
 // invocation.
-func (c DBGetUploadByIDFuncCall) Args() []interface{} {
+func (c DBGetUnindexedDumpIDsFuncCall) Args() []interface{} {
+	return []interface{}{c.Arg0, c.Arg1}
+}
+
+// Results returns an interface slice containing the results of this
+// invocation.
+func (c DBGetUnindexedDumpIDsFuncCall) Results() []interface{} {
+	return []interface{}{c.Result0, c.Result1}
+}
+
+// DBGetUploadByIDFunc describes the behavior when the GetUploadByID method
+// of the parent MockDB instance is invoked.
+type DBGetUploadByIDFunc struct {
+	defaultHook func(context.Context, int) (db.Upload, bool, error)
+	hooks       []func(context.Context, int) (db.Upload, bool, error)
+	history     []DBGetUploadByIDFuncCall
+	mutex       sync.Mutex
+}
+
+// GetUploadByID delegates to the next hook function in the queue and stores
+// the parameter and result values of this invocation.
+func (m *MockDB) GetUploadByID(v0 context.Context, v1 int) (db.Upload, bool, error) {
+	r0, r1, r2 := m.GetUploadByIDFunc.nextHook()(v0, v1)
+	m.GetUploadByIDFunc.appendCall(DBGetUploadByIDFuncCall{v0, v1, r0, r1, r2})
+	return r0, r1, r2
+}
+
+// SetDefaultHook sets function that is called when the GetUploadByID method
+// of the parent MockDB instance is invoked and the hook queue is empty.
+func (f *DBGetUploadByIDFunc) SetDefaultHook(hook func(context.Context, int) (db.Upload, bool, error)) {
+	f.defaultHook = hook
+}
+
+// PushHook adds a function to the end of hook queue. Each invocation of the
+// GetUploadByID method of the parent MockDB instance inovkes the hook at
+// the front of the queue and discards it. After the queue is empty, the
+// default hook function is invoked for any future action.
+func (f *DBGetUploadByIDFunc) PushHook(hook func(context.Context, int) (db.Upload, bool, error)) {
+	f.mutex.Lock()
+	f.hooks = append(f.hooks, hook)
+	f.mutex.Unlock()
+}
+
+// SetDefaultReturn calls SetDefaultDefaultHook with a function that returns
+// the given values.
+func (f *DBGetUploadByIDFunc) SetDefaultReturn(r0 db.Upload, r1 bool, r2 error) {
+	f.SetDefaultHook(func(context.Context, int) (db.Upload, bool, error) {
+		return r0, r1, r2
+	})
+}
+
+// PushReturn calls PushDefaultHook with a function that returns the given
+// values.
+func (f *DBGetUploadByIDFunc) PushReturn(r0 db.Upload, r1 bool, r2 error) {
+	f.PushHook(func(context.Context, int) (db.Upload, bool, error) {
+		return r0, r1, r2
+	})
+}
+
+func (f *DBGetUploadByIDFunc) nextHook() func(context.Context, int) (db.Upload, bool, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if len(f.hooks) == 0 {
+		return f.defaultHook
+	}
+
+	hook := f.hooks[0]
+	f.hooks = f.hooks[1:]
+	return hook
+}
+
+func (f *DBGetUploadByIDFunc) appendCall(r0 DBGetUploadByIDFuncCall) {
+	f.mutex.Lock()
+	f.history = append(f.history, r0)
+	f.mutex.Unlock()
+}
+
+// History returns a sequence of DBGetUploadByIDFuncCall objects describing
+// the invocations of this function.
+func (f *DBGetUploadByIDFunc) History() []DBGetUploadByIDFuncCall {
+	f.mutex.Lock()
+	history := make([]DBGetUploadByIDFuncCall, len(f.history))
+	copy(history, f.history)
+	f.mutex.Unlock()
+
+	return history
+}
+
+// DBGetUploadByIDFuncCall is an object that describes an invocation of
+// method GetUploadByID on an instance of MockDB.
+type DBGetUploadByIDFuncCall struct {
+	// Arg0 is the value of the 1st argument passed to this method
+	// invocation.
+	Arg0 context.Context
+	// Arg1 is the value of the 2nd argument passed to this method
+	// invocation.
+	Arg1 int
+	// Result0 is the value of the 1st result returned from this method
+	// invocation.
+	Result0 db.Upload
+	// Result1 is the value of the 2nd result returned from this method
+	// invocation.
+	Result1 bool
+	// Result2 is the value of the 3rd result returned from this method
+	// invocation.
+	Result2 error
+}
+
+// Args returns an interface slice containing the arguments of this
+// invocation.
+func (c DBGetUploadByIDFuncCall) Args() []interface{} {
+	return []interface{}{c.Arg0, c.Arg1}
+}
+
+// Results returns an interface slice containing the results of this
+// invocation.
+func (c DBGetUploadByIDFuncCall) Results() []interface{} {
+	return []interface{}{c.Result0, c.Result1, c.Result2}
+}
+
+// DBGetUploadsByRepoFunc describes the behavior when the GetUploadsByRepo
+// method of the parent MockDB instance is invoked.
+type DBGetUploadsByRepoFunc struct {
+	defaultHook func(context.Context, int, string, string, bool, int, int) ([]db.Upload, int, error)
+	hooks       []func(context.Context, int, string, string, bool, int, int) ([]db.Upload, int, error)
+	history     []DBGetUploadsByRepoFuncCall
+	mutex       sync.Mutex
+}
+
+// GetUploadsByRepo delegates to the next hook function in the queue and
+// stores the parameter and result values of this invocation.
+func (m *MockDB) GetUploadsByRepo(v0 context.Context, v1 int, v2 string, v3 string, v4 bool, v5 int, v6 int) ([]db.Upload, int, error) {
+	r0, r1, r2 := m.GetUploadsByRepoFunc.nextHook()(v0, v1, v2, v3, v4, v5, v6)
+	m.GetUploadsByRepoFunc.appendCall(DBGetUploadsByRepoFuncCall{v0, v1, v2, v3, v4, v5, v6, r0, r1, r2})
+	return r0, r1, r2
+}
+
+// SetDefaultHook sets function that is called when the GetUploadsByRepo
+// method of the parent MockDB instance is invoked and the hook queue is
+// empty.
+func (f *DBGetUploadsByRepoFunc) SetDefaultHook(hook func(context.Context, int, string, string, bool, int, int) ([]db.Upload, int, error)) {
+	f.defaultHook = hook
+}
+
+// PushHook adds a function to the end of hook queue. Each invocation of the
+// GetUploadsByRepo method of the parent MockDB instance inovkes the hook at
+// the front of the queue and discards it. After the queue is empty, the
+// default hook function is invoked for any future action.
+func (f *DBGetUploadsByRepoFunc) PushHook(hook func(context.Context, int, string, string, bool, int, int) ([]db.Upload, int, error)) {
+	f.mutex.Lock()
+	f.hooks = append(f.hooks, hook)
+	f.mutex.Unlock()
+}
+
+// SetDefaultReturn calls SetDefaultDefaultHook with a function that returns
+// the given values.
+func (f *DBGetUploadsByRepoFunc) SetDefaultReturn(r0 []db.Upload, r1 int, r2 error) {
+	f.SetDefaultHook(func(context.Context, int, string, string, bool, int, int) ([]db.Upload, int, error) {
+		return r0, r1, r2
+	})
+}
+
+// PushReturn calls PushDefaultHook with a function that returns the given
+// values.
+func (f *DBGetUploadsByRepoFunc) PushReturn(r0 []db.Upload, r1 int, r2 error) {
+	f.PushHook(func(context.Context, int, string, string, bool, int, int) ([]db.Upload, int, error) {
+		return r0, r1, r2
+	})
+}
+
+func (f *DBGetUploadsByRepoFunc) nextHook() func(context.Context, int, string, string, bool, int, int) ([]db.Upload, int, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if len(f.hooks) == 0 {
+		return f.defaultHook
+	}
+
+	hook := f.hooks[0]
+	f.hooks = f.hooks[1:]
+	return hook
+}
+
+func (f *DBGetUploadsByRepoFunc) appendCall(r0 DBGetUploadsByRepoFuncCall) {
+	f.mutex.Lock()
+	f.history = append(f.history, r0)
+	f.mutex.Unlock()
+}
+
+// History returns a sequence of DBGetUploadsByRepoFuncCall objects
+// describing the invocations of this function.
+func (f *DBGetUploadsByRepoFunc) History() []DBGetUploadsByRepoFuncCall {
+	f.mutex.Lock()
+	history := make([]DBGetUploadsByRepoFuncCall, len(f.history))
+	copy(history, f.history)
+	f.mutex.Unlock()
+
+	return history
+}
+
+// DBGetUploadsByRepoFuncCall is an object that describes an invocation of
+// method GetUploadsByRepo on an instance of MockDB.
+type DBGetUploadsByRepoFuncCall struct {
+	// Arg0 is the value of the 1st argument passed to this method
+	// invocation.
+	Arg0 context.Context
+	// Arg1 is the value of the 2nd argument passed to this method
+	// invocation.
+	Arg1 int
+	// Arg2 is the value of the 3rd argument passed to this method
+	// invocation.
+	Arg2 string
+	// Arg3 is the value of the 4th argument passed to this method
+	// invocation.
+	Arg3 string
+	// Arg4 is the value of the 5th argument passed to this method
+	// invocation.
+	Arg4 bool
+	// Arg5 is the value of the 6th argument passed to this method
+	// invocation.
+	Arg5 int
+	// Arg6 is the value of the 7th argument passed to this method
+	// invocation.
+	Arg6 int
+	// Result0 is the value of the 1st result returned from this method
+	// invocation.
+	Result0 []db.Upload
+	// Result1 is the value of the 2nd result returned from this method
+	// invocation.
+	Result1 int
+	// Result2 is the value of the 3rd result returned from this method
+	// invocation.
+	Result2 error
+}
+
+// Args returns an interface slice containing the arguments of this
+// invocation.
+func (c DBGetUploadsByRepoFuncCall) Args() []interface{} {
+	return []interface{}{c.Arg0, c.Arg1, c.Arg2, c.Arg3, c.Arg4, c.Arg5, c.Arg6}
+}
+
+// Results returns an interface slice containing the results of this
+// invocation.
+func (c DBGetUploadsByRepoFuncCall) Results() []interface{} {
+	return []interface{}{c.Result0, c.Result1, c.Result2}
+}
+
+// DBIndexMonikerReferencesFunc describes the behavior when the
+// IndexMonikerReferences method of the parent MockDB instance is invoked.
+type DBIndexMonikerReferencesFunc struct {
+	defaultHook func(context.Context, int, []db.MonikerReference) error
+	hooks       []func(context.Context, int, []db.MonikerReference) error
+	history     []DBIndexMonikerReferencesFuncCall
+	mutex       sync.Mutex
+}
+
+// IndexMonikerReferences delegates to the next hook function in the queue
+// and stores the parameter and result values of this invocation.
+func (m *MockDB) IndexMonikerReferences(v0 context.Context, v1 int, v2 []db.MonikerReference) error {
+	r0 := m.IndexMonikerReferencesFunc.nextHook()(v0, v1, v2)
+	m.IndexMonikerReferencesFunc.appendCall(DBIndexMonikerReferencesFuncCall{v0, v1, v2, r0})
+	return r0
+}
+
+// SetDefaultHook sets function that is called when the
+// IndexMonikerReferences method of the parent MockDB instance is invoked
+// and the hook queue is empty.
+func (f *DBIndexMonikerReferencesFunc) SetDefaultHook(hook func(context.Context, int, []db.MonikerReference) error) {
+	f.defaultHook = hook
+}
+
+// PushHook adds a function to the end of hook queue. Each invocation of the
+// IndexMonikerReferences method of the parent MockDB instance inovkes the
+// hook at the front of the queue and discards it. After the queue is empty,
+// the default hook function is invoked for any future action.
+func (f *DBIndexMonikerReferencesFunc) PushHook(hook func(context.Context, int, []db.MonikerReference) error) {
+	f.mutex.Lock()
+	f.hooks = append(f.hooks, hook)
+	f.mutex.Unlock()
+}
+
+// SetDefaultReturn calls SetDefaultDefaultHook with a function that returns
+// the given values.
+func (f *DBIndexMonikerReferencesFunc) SetDefaultReturn(r0 error) {
+	f.SetDefaultHook(func(context.Context, int, []db.MonikerReference) error {
+		return r0
+	})
+}
+
+// PushReturn calls PushDefaultHook with a function that returns the given
+// values.
+func (f *DBIndexMonikerReferencesFunc) PushReturn(r0 error) {
+	f.PushHook(func(context.Context, int, []db.MonikerReference) error {
+		return r0
+	})
+}
+
+func (f *DBIndexMonikerReferencesFunc) nextHook() func(context.Context, int, []db.MonikerReference) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if len(f.hooks) == 0 {
+		return f.defaultHook
+	}
+
+	hook := f.hooks[0]
+	f.hooks = f.hooks[1:]
+	return hook
+}
+
+func (f *DBIndexMonikerReferencesFunc) appendCall(r0 DBIndexMonikerReferencesFuncCall) {
+	f.mutex.Lock()
+	f.history = append(f.history, r0)
+	f.mutex.Unlock()
+}
+
+// History returns a sequence of DBIndexMonikerReferencesFuncCall objects
+// describing the invocations of this function.
+func (f *DBIndexMonikerReferencesFunc) History() []DBIndexMonikerReferencesFuncCall {
+	f.mutex.Lock()
+	history := make([]DBIndexMonikerReferencesFuncCall, len(f.history))
+	copy(history, f.history)
+	f.mutex.Unlock()
+
+	return history
+}
+
+// DBIndexMonikerReferencesFuncCall is an object that describes an
+// invocation of method IndexMonikerReferences on an instance of MockDB.
+type DBIndexMonikerReferencesFuncCall struct {
+	// Arg0 is the value of the 1st argument passed to this method
+	// invocation.
+	Arg0 context.Context
+	// Arg1 is the value of the 2nd argument passed to this method
+	// invocation.
+	Arg1 int
+	// Arg2 is the value of the 3rd argument passed to this method
+	// invocation.
+	Arg2 []db.MonikerReference
+	// Result0 is the value of the 1st result returned from this method
+	// invocation.
+	Result0 error
+}
+
+// Args returns an interface slice containing the arguments of this
+// invocation.
+func (c DBIndexMonikerReferencesFuncCall) Args() []interface{} {
+	return []interface{}{c.Arg0, c.Arg1, c.Arg2}
+}
+
+// Results returns an interface slice containing the results of this
+// invocation.
+func (c DBIndexMonikerReferencesFuncCall) Results() []interface{} {
+	return []interface{}{c.Result0}
+}
+
+// DBInsertIndexFunc describes the behavior when the InsertIndex method of
+// the parent MockDB instance is invoked.
+type DBInsertIndexFunc struct {
+	defaultHook func(context.Context, int, string, string) (int, bool, error)
+	hooks       []func(context.Context, int, string, string) (int, bool, error)
+	history     []DBInsertIndexFuncCall
+	mutex       sync.Mutex
+}
+
+// InsertIndex delegates to the next hook function in the queue and stores
+// the parameter and result values of this invocation.
+func (m *MockDB) InsertIndex(v0 context.Context, v1 int, v2 string, v3 string) (int, bool, error) {
+	r0, r1, r2 := m.InsertIndexFunc.nextHook()(v0, v1, v2, v3)
+	m.InsertIndexFunc.appendCall(DBInsertIndexFuncCall{v0, v1, v2, v3, r0, r1, r2})
+	return r0, r1, r2
+}
+
+// SetDefaultHook sets function that is called when the InsertIndex method
+// of the parent MockDB instance is invoked and the hook queue is empty.
+func (f *DBInsertIndexFunc) SetDefaultHook(hook func(context.Context, int, string, string) (int, bool, error)) {
+	f.defaultHook = hook
+}
+
+// PushHook adds a function to the end of hook queue. Each invocation of the
+// InsertIndex method of the parent MockDB instance inovkes the hook at the
+// front of the queue and discards it. After the queue is empty, the default
+// hook function is invoked for any future action.
+func (f *DBInsertIndexFunc) PushHook(hook func(context.Context, int, string, string) (int, bool, error)) {
+	f.mutex.Lock()
+	f.hooks = append(f.hooks, hook)
+	f.mutex.Unlock()
+}
+
+// SetDefaultReturn calls SetDefaultDefaultHook with a function that returns
+// the given values.
+func (f *DBInsertIndexFunc) SetDefaultReturn(r0 int, r1 bool, r2 error) {
+	f.SetDefaultHook(func(context.Context, int, string, string) (int, bool, error) {
+		return r0, r1, r2
+	})
+}
+
+// PushReturn calls PushDefaultHook with a function that returns the given
+// values.
+func (f *DBInsertIndexFunc) PushReturn(r0 int, r1 bool, r2 error) {
+	f.PushHook(func(context.Context, int, string, string) (int, bool, error) {
+		return r0, r1, r2
+	})
+}
+
+func (f *DBInsertIndexFunc) nextHook() func(context.Context, int, string, string) (int, bool, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if len(f.hooks) == 0 {
+		return f.defaultHook
+	}
+
+	hook := f.hooks[0]
+	f.hooks = f.hooks[1:]
+	return hook
+}
+
+func (f *DBInsertIndexFunc) appendCall(r0 DBInsertIndexFuncCall) {
+	f.mutex.Lock()
+	f.history = append(f.history, r0)
+	f.mutex.Unlock()
+}
+
+// History returns a sequence of DBInsertIndexFuncCall objects describing
+// the invocations of this function.
+func (f *DBInsertIndexFunc) History() []DBInsertIndexFuncCall {
+	f.mutex.Lock()
+	history := make([]DBInsertIndexFuncCall, len(f.history))
+	copy(history, f.history)
+	f.mutex.Unlock()
+
+	return history
+}
+
+// DBInsertIndexFuncCall is an object that describes an invocation of method
+// InsertIndex on an instance of MockDB.
+type DBInsertIndexFuncCall struct {
+	// Arg0 is the value of the 1st argument passed to this method
+	// invocation.
+	Arg0 context.Context
+	// Arg1 is the value of the 2nd argument passed to this method
+	// invocation.
+	Arg1 int
+	// Arg2 is the value of the 3rd argument passed to this method
+	// invocation.
+	Arg2 string
+	// Arg3 is the value of the 4th argument passed to this method
+	// invocation.
+	Arg3 string
+	// Result0 is the value of the 1st result returned from this method
+	// invocation.
+	Result0 int
+	// Result1 is the value of the 2nd result returned from this method
+	// invocation.
+	Result1 bool
+	// Result2 is the value of the 3rd result returned from this method
+	// invocation.
+	Result2 error
+}
+
+// Args returns an interface slice containing the arguments of this
+// invocation.
+func (c DBInsertIndexFuncCall) Args() []interface{} {
+	return []interface{}{c.Arg0, c.Arg1, c.Arg2, c.Arg3}
+}
+
+// Results returns an interface slice containing the results of this
+// invocation.
+func (c DBInsertIndexFuncCall) Results() []interface{} {
+	return []interface{}{c.Result0, c.Result1, c.Result2}
+}
+
+// DBMarkIndexCompleteFunc describes the behavior when the MarkIndexComplete
+// method of the parent MockDB instance is invoked.
+type DBMarkIndexCompleteFunc struct {
+	defaultHook func(context.Context, int, int) error
+	hooks       []func(context.Context, int, int) error
+	history     []DBMarkIndexCompleteFuncCall
+	mutex       sync.Mutex
+}
+
+// MarkIndexComplete delegates to the next hook function in the queue and
+// stores the parameter and result values of this invocation.
+func (m *MockDB) MarkIndexComplete(v0 context.Context, v1 int, v2 int) error {
+	r0 := m.MarkIndexCompleteFunc.nextHook()(v0, v1, v2)
+	m.MarkIndexCompleteFunc.appendCall(DBMarkIndexCompleteFuncCall{v0, v1, v2, r0})
+	return r0
+}
+
+// SetDefaultHook sets function that is called when the MarkIndexComplete
+// method of the parent MockDB instance is invoked and the hook queue is
+// empty.
+func (f *DBMarkIndexCompleteFunc) SetDefaultHook(hook func(context.Context, int, int) error) {
+	f.defaultHook = hook
+}
+
+// PushHook adds a function to the end of hook queue. Each invocation of the
+// MarkIndexComplete method of the parent MockDB instance inovkes the hook
+// at the front of the queue and discards it. After the queue is empty, the
+// default hook function is invoked for any future action.
+func (f *DBMarkIndexCompleteFunc) PushHook(hook func(context.Context, int, int) error) {
+	f.mutex.Lock()
+	f.hooks = append(f.hooks, hook)
+	f.mutex.Unlock()
+}
+
+// SetDefaultReturn calls SetDefaultDefaultHook with a function that returns
+// the given values.
+func (f *DBMarkIndexCompleteFunc) SetDefaultReturn(r0 error) {
+	f.SetDefaultHook(func(context.Context, int, int) error {
+		return r0
+	})
+}
+
+// PushReturn calls PushDefaultHook with a function that returns the given
+// values.
+func (f *DBMarkIndexCompleteFunc) PushReturn(r0 error) {
+	f.PushHook(func(context.Context, int, int) error {
+		return r0
+	})
+}
+
+func (f *DBMarkIndexCompleteFunc) nextHook() func(context.Context, int, int) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if len(f.hooks) == 0 {
+		return f.defaultHook
+	}
+
+	hook := f.hooks[0]
+	f.hooks = f.hooks[1:]
+	return hook
+}
+
+func (f *DBMarkIndexCompleteFunc) appendCall(r0 DBMarkIndexCompleteFuncCall) {
+	f.mutex.Lock()
+	f.history = append(f.history, r0)
+	f.mutex.Unlock()
+}
+
+// History returns a sequence of DBMarkIndexCompleteFuncCall objects
+// describing the invocations of this function.
+func (f *DBMarkIndexCompleteFunc) History() []DBMarkIndexCompleteFuncCall {
+	f.mutex.Lock()
+	history := make([]DBMarkIndexCompleteFuncCall, len(f.history))
+	copy(history, f.history)
+	f.mutex.Unlock()
+
+	return history
+}
+
+// DBMarkIndexCompleteFuncCall is an object that describes an invocation of
+// method MarkIndexComplete on an instance of MockDB.
+type DBMarkIndexCompleteFuncCall struct {
+	// Arg0 is the value of the 1st argument passed to this method
+	// invocation.
+	Arg0 context.Context
+	// Arg1 is the value of the 2nd argument passed to this method
+	// invocation.
+	Arg1 int
+	// Arg2 is the value of the 3rd argument passed to this method
+	// invocation.
+	Arg2 int
+	// Result0 is the value of the 1st result returned from this method
+	// invocation.
+	Result0 error
+}
+
+// Args returns an interface slice containing the arguments of this
+// invocation.
+func (c DBMarkIndexCompleteFuncCall) Args() []interface{} {
+	return []interface{}{c.Arg0, c.Arg1, c.Arg2}
+}
+
+// Results returns an interface slice containing the results of this
+// invocation.
+func (c DBMarkIndexCompleteFuncCall) Results() []interface{} {
+	return []interface{}{c.Result0}
+}
+
+// DBMarkIndexErroredFunc describes the behavior when the MarkIndexErrored
+// method of the parent MockDB instance is invoked.
+type DBMarkIndexErroredFunc struct {
+	defaultHook func(context.Context, int, string) error
+	hooks       []func(context.Context, int, string) error
+	history     []DBMarkIndexErroredFuncCall
+	mutex       sync.Mutex
+}
+
+// MarkIndexErrored delegates to the next hook function in the queue and
+// stores the parameter and result values of this invocation.
+func (m *MockDB) MarkIndexErrored(v0 context.Context, v1 int, v2 string) error {
+	r0 := m.MarkIndexErroredFunc.nextHook()(v0, v1, v2)
+	m.MarkIndexErroredFunc.appendCall(DBMarkIndexErroredFuncCall{v0, v1, v2, r0})
+	return r0
+}
+
+// SetDefaultHook sets function that is called when the MarkIndexErrored
+// method of the parent MockDB instance is invoked and the hook queue is
+// empty.
+func (f *DBMarkIndexErroredFunc) SetDefaultHook(hook func(context.Context, int, string) error) {
+	f.defaultHook = hook
+}
+
+// PushHook adds a function to the end of hook queue. Each invocation of the
+// MarkIndexErrored method of the parent MockDB instance inovkes the hook at
+// the front of the queue and discards it. After the queue is empty, the
+// default hook function is invoked for any future action.
+func (f *DBMarkIndexErroredFunc) PushHook(hook func(context.Context, int, string) error) {
+	f.mutex.Lock()
+	f.hooks = append(f.hooks, hook)
+	f.mutex.Unlock()
+}
+
+// SetDefaultReturn calls SetDefaultDefaultHook with a function that returns
+// the given values.
+func (f *DBMarkIndexErroredFunc) SetDefaultReturn(r0 error) {
+	f.SetDefaultHook(func(context.Context, int, string) error {
+		return r0
+	})
+}
+
+// PushReturn calls PushDefaultHook with a function that returns the given
+// values.
+func (f *DBMarkIndexErroredFunc) PushReturn(r0 error) {
+	f.PushHook(func(context.Context, int, string) error {
+		return r0
+	})
+}
+
+func (f *DBMarkIndexErroredFunc) nextHook() func(context.Context, int, string) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if len(f.hooks) == 0 {
+		return f.defaultHook
+	}
+
+	hook := f.hooks[0]
+	f.hooks = f.hooks[1:]
+	return hook
+}
+
+func (f *DBMarkIndexErroredFunc) appendCall(r0 DBMarkIndexErroredFuncCall) {
+	f.mutex.Lock()
+	f.history = append(f.history, r0)
+	f.mutex.Unlock()
+}
+
+// History returns a sequence of DBMarkIndexErroredFuncCall objects
+// describing the invocations of this function.
+func (f *DBMarkIndexErroredFunc) History() []DBMarkIndexErroredFuncCall {
+	f.mutex.Lock()
+	history := make([]DBMarkIndexErroredFuncCall, len(f.history))
+	copy(history, f.history)
+	f.mutex.Unlock()
+
+	return history
+}
+
+// DBMarkIndexErroredFuncCall is an object that describes an invocation of
+// method MarkIndexErrored on an instance of MockDB.
+type DBMarkIndexErroredFuncCall struct {
+	// Arg0 is the value of the 1st argument passed to this method
+	// invocation.
+	Arg0 context.Context
+	// Arg1 is the value of the 2nd argument passed to this method
+	// invocation.
+	Arg1 int
+	// Arg2 is the value of the 3rd argument passed to this method
+	// invocation.
+	Arg2 string
+	// Result0 is the value of the 1st result returned from this method
+	// invocation.
+	Result0 error
+}
+
+// Args returns an interface slice containing the arguments of this
+// invocation.
+func (c DBMarkIndexErroredFuncCall) Args() []interface{} {
+	return []interface{}{c.Arg0, c.Arg1, c.Arg2}
+}
+
+// Results returns an interface slice containing the results of this
+// invocation.
+func (c DBMarkIndexErroredFuncCall) Results() []interface{} {
+	return []interface{}{c.Result0}
+}
+
+// DBMonikerReferenceCountFunc describes the behavior when the
+// MonikerReferenceCount method of the parent MockDB instance is invoked.
+type DBMonikerReferenceCountFunc struct {
+	defaultHook func(context.Context, string, string, string, string) (int, error)
+	hooks       []func(context.Context, string, string, string, string) (int, error)
+	history     []DBMonikerReferenceCountFuncCall
+	mutex       sync.Mutex
+}
+
+// MonikerReferenceCount delegates to the next hook function in the queue
+// and stores the parameter and result values of this invocation.
+func (m *MockDB) MonikerReferenceCount(v0 context.Context, v1 string, v2 string, v3 string, v4 string) (int, error) {
+	r0, r1 := m.MonikerReferenceCountFunc.nextHook()(v0, v1, v2, v3, v4)
+	m.MonikerReferenceCountFunc.appendCall(DBMonikerReferenceCountFuncCall{v0, v1, v2, v3, v4, r0, r1})
+	return r0, r1
+}
+
+// SetDefaultHook sets function that is called when the
+// MonikerReferenceCount method of the parent MockDB instance is invoked and
+// the hook queue is empty.
+func (f *DBMonikerReferenceCountFunc) SetDefaultHook(hook func(context.Context, string, string, string, string) (int, error)) {
+	f.defaultHook = hook
+}
+
+// PushHook adds a function to the end of hook queue. Each invocation of the
+// MonikerReferenceCount method of the parent MockDB instance inovkes the
+// hook at the front of the queue and discards it. After the queue is empty,
+// the default hook function is invoked for any future action.
+func (f *DBMonikerReferenceCountFunc) PushHook(hook func(context.Context, string, string, string, string) (int, error)) {
+	f.mutex.Lock()
+	f.hooks = append(f.hooks, hook)
+	f.mutex.Unlock()
+}
+
+// SetDefaultReturn calls SetDefaultDefaultHook with a function that returns
+// the given values.
+func (f *DBMonikerReferenceCountFunc) SetDefaultReturn(r0 int, r1 error) {
+	f.SetDefaultHook(func(context.Context, string, string, string, string) (int, error) {
+		return r0, r1
+	})
+}
+
+// PushReturn calls PushDefaultHook with a function that returns the given
+// values.
+func (f *DBMonikerReferenceCountFunc) PushReturn(r0 int, r1 error) {
+	f.PushHook(func(context.Context, string, string, string, string) (int, error) {
+		return r0, r1
+	})
+}
+
+func (f *DBMonikerReferenceCountFunc) nextHook() func(context.Context, string, string, string, string) (int, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if len(f.hooks) == 0 {
+		return f.defaultHook
+	}
+
+	hook := f.hooks[0]
+	f.hooks = f.hooks[1:]
+	return hook
+}
+
+func (f *DBMonikerReferenceCountFunc) appendCall(r0 DBMonikerReferenceCountFuncCall) {
+	f.mutex.Lock()
+	f.history = append(f.history, r0)
+	f.mutex.Unlock()
+}
+
+// History returns a sequence of DBMonikerReferenceCountFuncCall objects
+// describing the invocations of this function.
+func (f *DBMonikerReferenceCountFunc) History() []DBMonikerReferenceCountFuncCall {
+	f.mutex.Lock()
+	history := make([]DBMonikerReferenceCountFuncCall, len(f.history))
+	copy(history, f.history)
+	f.mutex.Unlock()
+
+	return history
+}
+
+// DBMonikerReferenceCountFuncCall is an object that describes an invocation
+// of method MonikerReferenceCount on an instance of MockDB.
+type DBMonikerReferenceCountFuncCall struct {
+	// Arg0 is the value of the 1st argument passed to this method
+	// invocation.
+	Arg0 context.Context
+	// Arg1 is the value of the 2nd argument passed to this method
+	// invocation.
+	Arg1 string
+	// Arg2 is the value of the 3rd argument passed to this method
+	// invocation.
+	Arg2 string
+	// Arg3 is the value of the 4th argument passed to this method
+	// invocation.
+	Arg3 string
+	// Arg4 is the value of the 5th argument passed to this method
+	// invocation.
+	Arg4 string
+	// Result0 is the value of the 1st result returned from this method
+	// invocation.
+	Result0 int
+	// Result1 is the value of the 2nd result returned from this method
+	// invocation.
+	Result1 error
+}
+
+// Args returns an interface slice containing the arguments of this
+// invocation.
+func (c DBMonikerReferenceCountFuncCall) Args() []interface{} {
+	return []interface{}{c.Arg0, c.Arg1, c.Arg2, c.Arg3, c.Arg4}
+}
+
+// Results returns an interface slice containing the results of this
+// invocation.
+func (c DBMonikerReferenceCountFuncCall) Results() []interface{} {
+	return []interface{}{c.Result0, c.Result1}
+}
+
+// DBMonikerReferenceDumpIDsFunc describes the behavior when the
+// MonikerReferenceDumpIDs method of the parent MockDB instance is invoked.
+type DBMonikerReferenceDumpIDsFunc struct {
+	defaultHook func(context.Context, string, string, string, string, int, int, int) ([]int, error)
+	hooks       []func(context.Context, string, string, string, string, int, int, int) ([]int, error)
+	history     []DBMonikerReferenceDumpIDsFuncCall
+	mutex       sync.Mutex
+}
+
+// MonikerReferenceDumpIDs delegates to the next hook function in the queue
+// and stores the parameter and result values of this invocation.
+func (m *MockDB) MonikerReferenceDumpIDs(v0 context.Context, v1 string, v2 string, v3 string, v4 string, v5 int, v6 int, v7 int) ([]int, error) {
+	r0, r1 := m.MonikerReferenceDumpIDsFunc.nextHook()(v0, v1, v2, v3, v4, v5, v6, v7)
+	m.MonikerReferenceDumpIDsFunc.appendCall(DBMonikerReferenceDumpIDsFuncCall{v0, v1, v2, v3, v4, v5, v6, v7, r0, r1})
+	return r0, r1
+}
+
+// SetDefaultHook sets function that is called when the
+// MonikerReferenceDumpIDs method of the parent MockDB instance is invoked
+// and the hook queue is empty.
+func (f *DBMonikerReferenceDumpIDsFunc) SetDefaultHook(hook func(context.Context, string, string, string, string, int, int, int) ([]int, error)) {
+	f.defaultHook = hook
+}
+
+// PushHook adds a function to the end of hook queue. Each invocation of the
+// MonikerReferenceDumpIDs method of the parent MockDB instance inovkes the
+// hook at the front of the queue and discards it. After the queue is empty,
+// the default hook function is invoked for any future action.
+func (f *DBMonikerReferenceDumpIDsFunc) PushHook(hook func(context.Context, string, string, string, string, int, int, int) ([]int, error)) {
+	f.mutex.Lock()
+	f.hooks = append(f.hooks, hook)
+	f.mutex.Unlock()
+}
+
+// SetDefaultReturn calls SetDefaultDefaultHook with a function that returns
+// the given values.
+func (f *DBMonikerReferenceDumpIDsFunc) SetDefaultReturn(r0 []int, r1 error) {
+	f.SetDefaultHook(func(context.Context, string, string, string, string, int, int, int) ([]int, error) {
+		return r0, r1
+	})
+}
+
+// PushReturn calls PushDefaultHook with a function that returns the given
+// values.
+func (f *DBMonikerReferenceDumpIDsFunc) PushReturn(r0 []int, r1 error) {
+	f.PushHook(func(context.Context, string, string, string, string, int, int, int) ([]int, error) {
+		return r0, r1
+	})
+}
+
+func (f *DBMonikerReferenceDumpIDsFunc) nextHook() func(context.Context, string, string, string, string, int, int, int) ([]int, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if len(f.hooks) == 0 {
+		return f.defaultHook
+	}
+
+	hook := f.hooks[0]
+	f.hooks = f.hooks[1:]
+	return hook
+}
+
+func (f *DBMonikerReferenceDumpIDsFunc) appendCall(r0 DBMonikerReferenceDumpIDsFuncCall) {
+	f.mutex.Lock()
+	f.history = append(f.history, r0)
+	f.mutex.Unlock()
+}
+
+// History returns a sequence of DBMonikerReferenceDumpIDsFuncCall objects
+// describing the invocations of this function.
+func (f *DBMonikerReferenceDumpIDsFunc) History() []DBMonikerReferenceDumpIDsFuncCall {
+	f.mutex.Lock()
+	history := make([]DBMonikerReferenceDumpIDsFuncCall, len(f.history))
+	copy(history, f.history)
+	f.mutex.Unlock()
+
+	return history
+}
+
+// DBMonikerReferenceDumpIDsFuncCall is an object that describes an
+// invocation of method MonikerReferenceDumpIDs on an instance of MockDB.
+type DBMonikerReferenceDumpIDsFuncCall struct {
+	// Arg0 is the value of the 1st argument passed to this method
+	// invocation.
+	Arg0 context.Context
+	// Arg1 is the value of the 2nd argument passed to this method
+	// invocation.
+	Arg1 string
+	// Arg2 is the value of the 3rd argument passed to this method
+	// invocation.
+	Arg2 string
+	// Arg3 is the value of the 4th argument passed to this method
+	// invocation.
+	Arg3 string
+	// Arg4 is the value of the 5th argument passed to this method
+	// invocation.
+	Arg4 string
+	// Arg5 is the value of the 6th argument passed to this method
+	// invocation.
+	Arg5 int
+	// Arg6 is the value of the 7th argument passed to this method
+	// invocation.
+	Arg6 int
+	// Arg7 is the value of the 8th argument passed to this method
+	// invocation.
+	Arg7 int
+	// Result0 is the value of the 1st result returned from this method
+	// invocation.
+	Result0 []int
+	// Result1 is the value of the 2nd result returned from this method
+	// invocation.
+	Result1 error
+}
+
+// Args returns an interface slice containing the arguments of this
+// invocation.
+func (c DBMonikerReferenceDumpIDsFuncCall) Args() []interface{} {
+	return []interface{}{c.Arg0, c.Arg1, c.Arg2, c.Arg3, c.Arg4, c.Arg5, c.Arg6, c.Arg7}
+}
+
+// Results returns an interface slice containing the results of this
+// invocation.
+func (c DBMonikerReferenceDumpIDsFuncCall) Results() []interface{} {
+	return []interface{}{c.Result0, c.Result1}
+}
+
+// DBPackageReferencePagerFunc describes the behavior when the
+// PackageReferencePager method of the parent MockDB instance is invoked.
+type DBPackageReferencePagerFunc struct {
+	defaultHook func(context.Context, string, string, string, int, int) (int, db.ReferencePager, error)
+	hooks       []func(context.Context, string, string, string, int, int) (int, db.ReferencePager, error)
+	history     []DBPackageReferencePagerFuncCall
+	mutex       sync.Mutex
+}
+
+// PackageReferencePager delegates to the next hook function in the queue
+// and stores the parameter and result values of this invocation.
+func (m *MockDB) PackageReferencePager(v0 context.Context, v1 string, v2 string, v3 string, v4 int, v5 int) (int, db.ReferencePager, error) {
+	r0, r1, r2 := m.PackageReferencePagerFunc.nextHook()(v0, v1, v2, v3, v4, v5)
+	m.PackageReferencePagerFunc.appendCall(DBPackageReferencePagerFuncCall{v0, v1, v2, v3, v4, v5, r0, r1, r2})
+	return r0, r1, r2
+}
+
+// SetDefaultHook sets function that is called when the
+// PackageReferencePager method of the parent MockDB instance is invoked and
+// the hook queue is empty.
+func (f *DBPackageReferencePagerFunc) SetDefaultHook(hook func(context.Context, string, string, string, int, int) (int, db.ReferencePager, error)) {
+	f.defaultHook = hook
+}
+
+// PushHook adds a function to the end of hook queue. Each invocation of the
+// PackageReferencePager method of the parent MockDB instance inovkes the
+// hook at the front of the queue and discards it. After the queue is empty,
+// the default hook function is invoked for any future action.
+func (f *DBPackageReferencePagerFunc) PushHook(hook func(context.Context, string, string, string, int, int) (int, db.ReferencePager, error)) {
+	f.mutex.Lock()
+	f.hooks = append(f.hooks, hook)
+	f.mutex.Unlock()
+}
+
+// SetDefaultReturn calls SetDefaultDefaultHook with a function that returns
+// the given values.
+func (f *DBPackageReferencePagerFunc) SetDefaultReturn(r0 int, r1 db.ReferencePager, r2 error) {
+	f.SetDefaultHook(func(context.Context, string, string, string, int, int) (int, db.ReferencePager, error) {
+		return r0, r1, r2
+	})
+}
+
+// PushReturn calls PushDefaultHook with a function that returns the given
+// values.
+func (f *DBPackageReferencePagerFunc) PushReturn(r0 int, r1 db.ReferencePager, r2 error) {
+	f.PushHook(func(context.Context, string, string, string, int, int) (int, db.ReferencePager, error) {
+		return r0, r1, r2
+	})
+}
+
+func (f *DBPackageReferencePagerFunc) nextHook() func(context.Context, string, string, string, int, int) (int, db.ReferencePager, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if len(f.hooks) == 0 {
+		return f.defaultHook
+	}
+
+	hook := f.hooks[0]
+	f.hooks = f.hooks[1:]
+	return hook
+}
+
+func (f *DBPackageReferencePagerFunc) appendCall(r0 DBPackageReferencePagerFuncCall) {
+	f.mutex.Lock()
+	f.history = append(f.history, r0)
+	f.mutex.Unlock()
+}
+
+// History returns a sequence of DBPackageReferencePagerFuncCall objects
+// describing the invocations of this function.
+func (f *DBPackageReferencePagerFunc) History() []DBPackageReferencePagerFuncCall {
+	f.mutex.Lock()
+	history := make([]DBPackageReferencePagerFuncCall, len(f.history))
+	copy(history, f.history)
+	f.mutex.Unlock()
+
+	return history
+}
+
+// DBPackageReferencePagerFuncCall is an object that describes an invocation
+// of method PackageReferencePager on an instance of MockDB.
+type DBPackageReferencePagerFuncCall struct {
+	// Arg0 is the value of the 1st argument passed to this method
+	// invocation.
+	Arg0 context.Context
+	// Arg1 is the value of the 2nd argument passed to this method
+	// invocation.
+	Arg1 string
+	// Arg2 is the value of the 3rd argument passed to this method
+	// invocation.
+	Arg2 string
+	// Arg3 is the value of the 4th argument passed to this method
+	// invocation.
+	Arg3 string
+	// Arg4 is the value of the 5th argument passed to this method
+	// invocation.
+	Arg4 int
+	// Arg5 is the value of the 6th argument passed to this method
+	// invocation.
+	Arg5 int
+	// Result0 is the value of the 1st result returned from this method
+	// invocation.
+	Result0 int
+	// Result1 is the value of the 2nd result returned from this method
+	// invocation.
+	Result1 db.ReferencePager
+	// Result2 is the value of the 3rd result returned from this method
+	// invocation.
+	Result2 error
+}
+
+// Args returns an interface slice containing the arguments of this
+// invocation.
+func (c DBPackageReferencePagerFuncCall) Args() []interface{} {
+	return []interface{}{c.Arg0, c.Arg1, c.Arg2, c.Arg3, c.Arg4, c.Arg5}
+}
+
+// Results returns an interface slice containing the results of this
+// invocation.
+func (c DBPackageReferencePagerFuncCall) Results() []interface{} {
+	return []interface{}{c.Result0, c.Result1, c.Result2}
+}
+
+// DBRequeueIndexFunc describes the behavior when the RequeueIndex method of
+// the parent MockDB instance is invoked.
+type DBRequeueIndexFunc struct {
+	defaultHook func(context.Context, int, string, time.Time) error
+	hooks       []func(context.Context, int, string, time.Time) error
+	history     []DBRequeueIndexFuncCall
+	mutex       sync.Mutex
+}
+
+// RequeueIndex delegates to the next hook function in the queue and stores
+// the parameter and result values of this invocation.
+func (m *MockDB) RequeueIndex(v0 context.Context, v1 int, v2 string, v3 time.Time) error {
+	r0 := m.RequeueIndexFunc.nextHook()(v0, v1, v2, v3)
+	m.RequeueIndexFunc.appendCall(DBRequeueIndexFuncCall{v0, v1, v2, v3, r0})
+	return r0
+}
+
+// SetDefaultHook sets function that is called when the RequeueIndex method
+// of the parent MockDB instance is invoked and the hook queue is empty.
+func (f *DBRequeueIndexFunc) SetDefaultHook(hook func(context.Context, int, string, time.Time) error) {
+	f.defaultHook = hook
+}
+
+// PushHook adds a function to the end of hook queue. Each invocation of the
+// RequeueIndex method of the parent MockDB instance inovkes the hook at the
+// front of the queue and discards it. After the queue is empty, the default
+// hook function is invoked for any future action.
+func (f *DBRequeueIndexFunc) PushHook(hook func(context.Context, int, string, time.Time) error) {
+	f.mutex.Lock()
+	f.hooks = append(f.hooks, hook)
+	f.mutex.Unlock()
+}
+
+// SetDefaultReturn calls SetDefaultDefaultHook with a function that returns
+// the given values.
+func (f *DBRequeueIndexFunc) SetDefaultReturn(r0 error) {
+	f.SetDefaultHook(func(context.Context, int, string, time.Time) error {
+		return r0
+	})
+}
+
+// PushReturn calls PushDefaultHook with a function that returns the given
+// values.
+func (f *DBRequeueIndexFunc) PushReturn(r0 error) {
+	f.PushHook(func(context.Context, int, string, time.Time) error {
+		return r0
+	})
+}
+
+func (f *DBRequeueIndexFunc) nextHook() func(context.Context, int, string, time.Time) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if len(f.hooks) == 0 {
+		return f.defaultHook
+	}
+
+	hook := f.hooks[0]
+	f.hooks = f.hooks[1:]
+	return hook
+}
+
+func (f *DBRequeueIndexFunc) appendCall(r0 DBRequeueIndexFuncCall) {
+	f.mutex.Lock()
+	f.history = append(f.history, r0)
+	f.mutex.Unlock()
+}
+
+// History returns a sequence of DBRequeueIndexFuncCall objects describing
+// the invocations of this function.
+func (f *DBRequeueIndexFunc) History() []DBRequeueIndexFuncCall {
+	f.mutex.Lock()
+	history := make([]DBRequeueIndexFuncCall, len(f.history))
+	copy(history, f.history)
+	f.mutex.Unlock()
+
+	return history
+}
+
+// DBRequeueIndexFuncCall is an object that describes an invocation of
+// method RequeueIndex on an instance of MockDB.
+type DBRequeueIndexFuncCall struct {
+	// Arg0 is the value of the 1st argument passed to this method
+	// invocation.
+	Arg0 context.Context
+	// Arg1 is the value of the 2nd argument passed to this method
+	// invocation.
+	Arg1 int
+	// Arg2 is the value of the 3rd argument passed to this method
+	// invocation.
+	Arg2 string
+	// Arg3 is the value of the 4th argument passed to this method
+	// invocation.
+	Arg3 time.Time
+	// Result0 is the value of the 1st result returned from this method
+	// invocation.
+	Result0 error
+}
+
+// Args returns an interface slice containing the arguments of this
+// invocation.
+func (c DBRequeueIndexFuncCall) Args() []interface{} {
+	return []interface{}{c.Arg0, c.Arg1, c.Arg2, c.Arg3}
+}
+
+// Results returns an interface slice containing the results of this
+// invocation.
+func (c DBRequeueIndexFuncCall) Results() []interface{} {
+	return []interface{}{c.Result0}
+}
+
+// DBResetStalledFunc describes the behavior when the ResetStalled method of
+// the parent MockDB instance is invoked.
+type DBResetStalledFunc struct {
+	defaultHook func(context.Context, time.Time) ([]int, error)
+	hooks       []func(context.Context, time.Time) ([]int, error)
+	history     []DBResetStalledFuncCall
+	mutex       sync.Mutex
+}
+
+// ResetStalled delegates to the next hook function in the queue and stores
+// the parameter and result values of this invocation.
+func (m *MockDB) ResetStalled(v0 context.Context, v1 time.Time) ([]int, error) {
+	r0, r1 := m.ResetStalledFunc.nextHook()(v0, v1)
+	m.ResetStalledFunc.appendCall(DBResetStalledFuncCall{v0, v1, r0, r1})
+	return r0, r1
+}
+
+// SetDefaultHook sets function that is called when the ResetStalled method
+// of the parent MockDB instance is invoked and the hook queue is empty.
+func (f *DBResetStalledFunc) SetDefaultHook(hook func(context.Context, time.Time) ([]int, error)) {
+	f.defaultHook = hook
+}
+
+// PushHook adds a function to the end of hook queue. Each invocation of the
+// ResetStalled method of the parent MockDB instance inovkes the hook at the
+// front of the queue and discards it. After the queue is empty, the default
+// hook function is invoked for any future action.
+func (f *DBResetStalledFunc) PushHook(hook func(context.Context, time.Time) ([]int, error)) {
+	f.mutex.Lock()
+	f.hooks = append(f.hooks, hook)
+	f.mutex.Unlock()
+}
+
+// SetDefaultReturn calls SetDefaultDefaultHook with a function that returns
+// the given values.
+func (f *DBResetStalledFunc) SetDefaultReturn(r0 []int, r1 error) {
+	f.SetDefaultHook(func(context.Context, time.Time) ([]int, error) {
+		return r0, r1
+	})
+}
+
+// PushReturn calls PushDefaultHook with a function that returns the given
+// values.
+func (f *DBResetStalledFunc) PushReturn(r0 []int, r1 error) {
+	f.PushHook(func(context.Context, time.Time) ([]int, error) {
+		return r0, r1
+	})
+}
+
+func (f *DBResetStalledFunc) nextHook() func(context.Context, time.Time) ([]int, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if len(f.hooks) == 0 {
+		return f.defaultHook
+	}
+
+	hook := f.hooks[0]
+	f.hooks = f.hooks[1:]
+	return hook
+}
+
+func (f *DBResetStalledFunc) appendCall(r0 DBResetStalledFuncCall) {
+	f.mutex.Lock()
+	f.history = append(f.history, r0)
+	f.mutex.Unlock()
+}
+
+// History returns a sequence of DBResetStalledFuncCall objects describing
+// the invocations of this function.
+func (f *DBResetStalledFunc) History() []DBResetStalledFuncCall {
+	f.mutex.Lock()
+	history := make([]DBResetStalledFuncCall, len(f.history))
+	copy(history, f.history)
+	f.mutex.Unlock()
+
+	return history
+}
+
+// DBResetStalledFuncCall is an object that describes an invocation of
+// method ResetStalled on an instance of MockDB.
+type DBResetStalledFuncCall struct {
+	// Arg0 is the value of the 1st argument passed to this method
+	// invocation.
+	Arg0 context.Context
+	// Arg1 is the value of the 2nd argument passed to this method
+	// invocation.
+	Arg1 time.Time
+	// Result0 is the value of the 1st result returned from this method
+	// invocation.
+	Result0 []int
+	// Result1 is the value of the 2nd result returned from this method
+	// invocation.
+	Result1 error
+}
+
+// Args returns an interface slice containing the arguments of this
+// invocation.
+func (c DBResetStalledFuncCall) Args() []interface{} {
 	return []interface{}{c.Arg0, c.Arg1}
 }
 
 // Results returns an interface slice containing the results of this
 // invocation.
-func (c DBGetUploadByIDFuncCall) Results() []interface{} {
-	return []interface{}{c.Result0, c.Result1, c.Result2}
+func (c DBResetStalledFuncCall) Results() []interface{} {
+	return []interface{}{c.Result0, c.Result1}
 }
 
-// DBGetUploadsByRepoFunc describes the behavior when the GetUploadsByRepo
-// method of the parent MockDB instance is invoked.
-type DBGetUploadsByRepoFunc struct {
-	defaultHook func(context.Context, int, string, string, bool, int, int) ([]db.Upload, int, error)
-	hooks       []func(context.Context, int, string, string, bool, int, int) ([]db.Upload, int, error)
-	history     []DBGetUploadsByRepoFuncCall
+// DBResetStalledIndexesFunc describes the behavior when the
+// ResetStalledIndexes method of the parent MockDB instance is invoked.
+type DBResetStalledIndexesFunc struct {
+	defaultHook func(context.Context, time.Time) ([]int, error)
+	hooks       []func(context.Context, time.Time) ([]int, error)
+	history     []DBResetStalledIndexesFuncCall
 	mutex       sync.Mutex
 }
 
-// GetUploadsByRepo delegates to the next hook function in the queue and
+// ResetStalledIndexes delegates to the next hook function in the queue and
 // stores the parameter and result values of this invocation.
-func (m *MockDB) GetUploadsByRepo(v0 context.Context, v1 int, v2 string, v3 string, v4 bool, v5 int, v6 int) ([]db.Upload, int, error) {
-	r0, r1, r2 := m.GetUploadsByRepoFunc.nextHook()(v0, v1, v2, v3, v4, v5, v6)
-	m.GetUploadsByRepoFunc.appendCall(DBGetUploadsByRepoFuncCall{v0, v1, v2, v3, v4, v5, v6, r0, r1, r2})
-	return r0, r1, r2
+func (m *MockDB) ResetStalledIndexes(v0 context.Context, v1 time.Time) ([]int, error) {
+	r0, r1 := m.ResetStalledIndexesFunc.nextHook()(v0, v1)
+	m.ResetStalledIndexesFunc.appendCall(DBResetStalledIndexesFuncCall{v0, v1, r0, r1})
+	return r0, r1
 }
 
-// SetDefaultHook sets function that is called when the GetUploadsByRepo
+// SetDefaultHook sets function that is called when the ResetStalledIndexes
 // method of the parent MockDB instance is invoked and the hook queue is
 // empty.
-func (f *DBGetUploadsByRepoFunc) SetDefaultHook(hook func(context.Context, int, string, string, bool, int, int) ([]db.Upload, int, error)) {
+func (f *DBResetStalledIndexesFunc) SetDefaultHook(hook func(context.Context, time.Time) ([]int, error)) {
 	f.defaultHook = hook
 }
 
 // PushHook adds a function to the end of hook queue. Each invocation of the
-// GetUploadsByRepo method of the parent MockDB instance inovkes the hook at
-// the front of the queue and discards it. After the queue is empty, the
+// ResetStalledIndexes method of the parent MockDB instance inovkes the hook
+// at the front of the queue and discards it. After the queue is empty, the
 // default hook function is invoked for any future action.
-func (f *DBGetUploadsByRepoFunc) PushHook(hook func(context.Context, int, string, string, bool, int, int) ([]db.Upload, int, error)) {
+func (f *DBResetStalledIndexesFunc) PushHook(hook func(context.Context, time.Time) ([]int, error)) {
 	f.mutex.Lock()
 	f.hooks = append(f.hooks, hook)
 	f.mutex.Unlock()
@@ -1103,21 +2982,21 @@ func (f *DBGetUploadsByRepoFunc) PushHook(hook func(context.Context, int, string
 
 // SetDefaultReturn calls SetDefaultDefaultHook with a function that returns
 // the given values.
-func (f *DBGetUploadsByRepoFunc) SetDefaultReturn(r0 []db.Upload, r1 int, r2 error) {
-	f.SetDefaultHook(func(context.Context, int, string, string, bool, int, int) ([]db.Upload, int, error) {
-		return r0, r1, r2
+func (f *DBResetStalledIndexesFunc) SetDefaultReturn(r0 []int, r1 error) {
+	f.SetDefaultHook(func(context.Context, time.Time) ([]int, error) {
+		return r0, r1
 	})
 }
 
 // PushReturn calls PushDefaultHook with a function that returns the given
 // values.
-func (f *DBGetUploadsByRepoFunc) PushReturn(r0 []db.Upload, r1 int, r2 error) {
-	f.PushHook(func(context.Context, int, string, string, bool, int, int) ([]db.Upload, int, error) {
-		return r0, r1, r2
+func (f *DBResetStalledIndexesFunc) PushReturn(r0 []int, r1 error) {
+	f.PushHook(func(context.Context, time.Time) ([]int, error) {
+		return r0, r1
 	})
 }
 
-func (f *DBGetUploadsByRepoFunc) nextHook() func(context.Context, int, string, string, bool, int, int) ([]db.Upload, int, error) {
+func (f *DBResetStalledIndexesFunc) nextHook() func(context.Context, time.Time) ([]int, error) {
 	f.mutex.Lock()
 	defer f.mutex.Unlock()
 
@@ -1130,99 +3009,80 @@ func (f *DBGetUploadsByRepoFunc) nextHook() func(context.Context, int, string, s
 	return hook
 }
 
-func (f *DBGetUploadsByRepoFunc) appendCall(r0 DBGetUploadsByRepoFuncCall) {
+func (f *DBResetStalledIndexesFunc) appendCall(r0 DBResetStalledIndexesFuncCall) {
 	f.mutex.Lock()
 	f.history = append(f.history, r0)
 	f.mutex.Unlock()
 }
 
-// History returns a sequence of DBGetUploadsByRepoFuncCall objects
+// History returns a sequence of DBResetStalledIndexesFuncCall objects
 // describing the invocations of this function.
-func (f *DBGetUploadsByRepoFunc) History() []DBGetUploadsByRepoFuncCall {
+func (f *DBResetStalledIndexesFunc) History() []DBResetStalledIndexesFuncCall {
 	f.mutex.Lock()
-	history := make([]DBGetUploadsByRepoFuncCall, len(f.history))
+	history := make([]DBResetStalledIndexesFuncCall, len(f.history))
 	copy(history, f.history)
 	f.mutex.Unlock()
 
 	return history
 }
 
-// DBGetUploadsByRepoFuncCall is an object that describes an invocation of
-// method GetUploadsByRepo on an instance of MockDB.
-type DBGetUploadsByRepoFuncCall struct {
+// DBResetStalledIndexesFuncCall is an object that describes an invocation
+// of method ResetStalledIndexes on an instance of MockDB.
+type DBResetStalledIndexesFuncCall struct {
 	// Arg0 is the value of the 1st argument passed to this method
 	// invocation.
 	Arg0 context.Context
 	// Arg1 is the value of the 2nd argument passed to this method
 	// invocation.
-	Arg1 int
-	// Arg2 is the value of the 3rd argument passed to this method
-	// invocation.
-	Arg2 string
-	// Arg3 is the value of the 4th argument passed to this method
-	// invocation.
-	Arg3 string
-	// Arg4 is the value of the 5th argument passed to this method
-	// invocation.
-	Arg4 bool
-	// Arg5 is the value of the 6th argument passed to this method
-	// invocation.
-	Arg5 int
-	// Arg6 is the value of the 7th argument passed to this method
-	// invocation.
-	Arg6 int
+	Arg1 time.Time
 	// Result0 is the value of the 1st result returned from this method
 	// invocation.
-	Result0 []db.Upload
+	Result0 []int
 	// Result1 is the value of the 2nd result returned from this method
 	// invocation.
-	Result1 int
-	// Result2 is the value of the 3rd result returned from this method
-	// invocation.
-	Result2 error
+	Result1 error
 }
 
 // Args returns an interface slice containing the arguments of this
 // invocation.
-func (c DBGetUploadsByRepoFuncCall) Args() []interface{} {
-	return []interface{}{c.Arg0, c.Arg1, c.Arg2, c.Arg3, c.Arg4, c.Arg5, c.Arg6}
+func (c DBResetStalledIndexesFuncCall) Args() []interface{} {
+	return []interface{}{c.Arg0, c.Arg1}
 }
 
 // Results returns an interface slice containing the results of this
 // invocation.
-func (c DBGetUploadsByRepoFuncCall) Results() []interface{} {
-	return []interface{}{c.Result0, c.Result1, c.Result2}
+func (c DBResetStalledIndexesFuncCall) Results() []interface{} {
+	return []interface{}{c.Result0, c.Result1}
 }
 
-// DBPackageReferencePagerFunc describes the behavior when the
-// PackageReferencePager method of the parent MockDB instance is invoked.
-type DBPackageReferencePagerFunc struct {
-	defaultHook func(context.Context, string, string, string, int, int) (int, db.ReferencePager, error)
-	hooks       []func(context.Context, string, string, string, int, int) (int, db.ReferencePager, error)
-	history     []DBPackageReferencePagerFuncCall
+// DBSameRepoPagerFunc describes the behavior when the SameRepoPager method
+// of the parent MockDB instance is invoked.
+type DBSameRepoPagerFunc struct {
+	defaultHook func(context.Context, int, string, string, string, string, int) (int, db.ReferencePager, error)
+	hooks       []func(context.Context, int, string, string, string, string, int) (int, db.ReferencePager, error)
+	history     []DBSameRepoPagerFuncCall
 	mutex       sync.Mutex
 }
 
-// PackageReferencePager delegates to the next hook function in the queue
-// and stores the parameter and result values of this invocation.
-func (m *MockDB) PackageReferencePager(v0 context.Context, v1 string, v2 string, v3 string, v4 int, v5 int) (int, db.ReferencePager, error) {
-	r0, r1, r2 := m.PackageReferencePagerFunc.nextHook()(v0, v1, v2, v3, v4, v5)
-	m.PackageReferencePagerFunc.appendCall(DBPackageReferencePagerFuncCall{v0, v1, v2, v3, v4, v5, r0, r1, r2})
+// SameRepoPager delegates to the next hook function in the queue and stores
+// the parameter and result values of this invocation.
+func (m *MockDB) SameRepoPager(v0 context.Context, v1 int, v2 string, v3 string, v4 string, v5 string, v6 int) (int, db.ReferencePager, error) {
+	r0, r1, r2 := m.SameRepoPagerFunc.nextHook()(v0, v1, v2, v3, v4, v5, v6)
+	m.SameRepoPagerFunc.appendCall(DBSameRepoPagerFuncCall{v0, v1, v2, v3, v4, v5, v6, r0, r1, r2})
 	return r0, r1, r2
 }
 
-// SetDefaultHook sets function that is called when the
-// PackageReferencePager method of the parent MockDB instance is invoked and
-// the hook queue is empty.
-func (f *DBPackageReferencePagerFunc) SetDefaultHook(hook func(context.Context, string, string, string, int, int) (int, db.ReferencePager, error)) {
+// SetDefaultHook sets function that is called when the SameRepoPager method
+// of the parent MockDB instance is invoked and the hook queue is empty.
+func (f *DBSameRepoPagerFunc) SetDefaultHook(hook func(context.Context, int, string, string, string, string, int) (int, db.ReferencePager, error)) {
 	f.defaultHook = hook
 }
 
 // PushHook adds a function to the end of hook queue. Each invocation of the
-// PackageReferencePager method of the parent MockDB instance inovkes the
-// hook at the front of the queue and discards it. After the queue is empty,
-// the default hook function is invoked for any future action.
-func (f *DBPackageReferencePagerFunc) PushHook(hook func(context.Context, string, string, string, int, int) (int, db.ReferencePager, error)) {
+// SameRepoPager method of the parent MockDB instance inovkes the hook at
+// the front of the queue and discards it. After the queue is empty, the
+// default hook function is invoked for any future action.
+func (f *DBSameRepoPagerFunc) PushHook(hook func(context.Context, int, string, string, string, string, int) (int, db.ReferencePager, error)) {
 	f.mutex.Lock()
 	f.hooks = append(f.hooks, hook)
 	f.mutex.Unlock()
@@ -1230,21 +3090,21 @@ func (f *DBPackageReferencePagerFunc) PushHook(hook func(context.Context, string
 
 // SetDefaultReturn calls SetDefaultDefaultHook with a function that returns
 // the given values.
-func (f *DBPackageReferencePagerFunc) SetDefaultReturn(r0 int, r1 db.ReferencePager, r2 error) {
-	f.SetDefaultHook(func(context.Context, string, string, string, int, int) (int, db.ReferencePager, error) {
+func (f *DBSameRepoPagerFunc) SetDefaultReturn(r0 int, r1 db.ReferencePager, r2 error) {
+	f.SetDefaultHook(func(context.Context, int, string, string, string, string, int) (int, db.ReferencePager, error) {
 		return r0, r1, r2
 	})
 }
 
 // PushReturn calls PushDefaultHook with a function that returns the given
 // values.
-func (f *DBPackageReferencePagerFunc) PushReturn(r0 int, r1 db.ReferencePager, r2 error) {
-	f.PushHook(func(context.Context, string, string, string, int, int) (int, db.ReferencePager, error) {
+func (f *DBSameRepoPagerFunc) PushReturn(r0 int, r1 db.ReferencePager, r2 error) {
+	f.PushHook(func(context.Context, int, string, string, string, string, int) (int, db.ReferencePager, error) {
 		return r0, r1, r2
 	})
 }
 
-func (f *DBPackageReferencePagerFunc) nextHook() func(context.Context, string, string, string, int, int) (int, db.ReferencePager, error) {
+func (f *DBSameRepoPagerFunc) nextHook() func(context.Context, int, string, string, string, string, int) (int, db.ReferencePager, error) {
 	f.mutex.Lock()
 	defer f.mutex.Unlock()
 
@@ -1257,32 +3117,32 @@ func (f *DBPackageReferencePagerFunc) nextHook() func(context.Context, string, s
 	return hook
 }
 
-func (f *DBPackageReferencePagerFunc) appendCall(r0 DBPackageReferencePagerFuncCall) {
+func (f *DBSameRepoPagerFunc) appendCall(r0 DBSameRepoPagerFuncCall) {
 	f.mutex.Lock()
 	f.history = append(f.history, r0)
 	f.mutex.Unlock()
 }
 
-// History returns a sequence of DBPackageReferencePagerFuncCall objects
-// describing the invocations of this function.
-func (f *DBPackageReferencePagerFunc) History() []DBPackageReferencePagerFuncCall {
+// History returns a sequence of DBSameRepoPagerFuncCall objects describing
+// the invocations of this function.
+func (f *DBSameRepoPagerFunc) History() []DBSameRepoPagerFuncCall {
 	f.mutex.Lock()
-	history := make([]DBPackageReferencePagerFuncCall, len(f.history))
+	history := make([]DBSameRepoPagerFuncCall, len(f.history))
 	copy(history, f.history)
 	f.mutex.Unlock()
 
 	return history
 }
 
-// DBPackageReferencePagerFuncCall is an object that describes an invocation
-// of method PackageReferencePager on an instance of MockDB.
-type DBPackageReferencePagerFuncCall struct {
+// DBSameRepoPagerFuncCall is an object that describes an invocation of
+// method SameRepoPager on an instance of MockDB.
+type DBSameRepoPagerFuncCall struct {
 	// Arg0 is the value of the 1st argument passed to this method
 	// invocation.
 	Arg0 context.Context
 	// Arg1 is the value of the 2nd argument passed to this method
 	// invocation.
-	Arg1 string
+	Arg1 int
 	// Arg2 is the value of the 3rd argument passed to this method
 	// invocation.
 	Arg2 string
@@ -1291,10 +3151,13 @@ type DBPackageReferencePagerFuncCall struct {
 	Arg3 string
 	// Arg4 is the value of the 5th argument passed to this method
 	// invocation.
-	Arg4 int
+	Arg4 string
 	// Arg5 is the value of the 6th argument passed to this method
 	// invocation.
-	Arg5 int
+	Arg5 string
+	// Arg6 is the value of the 7th argument passed to this method
+	// invocation.
+	Arg6 int
 	// Result0 is the value of the 1st result returned from this method
 	// invocation.
 	Result0 int
@@ -1308,44 +3171,45 @@ type DBPackageReferencePagerFuncCall struct {
 
 // Args returns an interface slice containing the arguments of this
 // invocation.
-func (c DBPackageReferencePagerFuncCall) Args() []interface{} {
-	return []interface{}{c.Arg0, c.Arg1, c.Arg2, c.Arg3, c.Arg4, c.Arg5}
+func (c DBSameRepoPagerFuncCall) Args() []interface{} {
+	return []interface{}{c.Arg0, c.Arg1, c.Arg2, c.Arg3, c.Arg4, c.Arg5, c.Arg6}
 }
 
 // Results returns an interface slice containing the results of this
 // invocation.
-func (c DBPackageReferencePagerFuncCall) Results() []interface{} {
+func (c DBSameRepoPagerFuncCall) Results() []interface{} {
 	return []interface{}{c.Result0, c.Result1, c.Result2}
 }
 
-// DBResetStalledFunc describes the behavior when the ResetStalled method of
-// the parent MockDB instance is invoked.
-type DBResetStalledFunc struct {
-	defaultHook func(context.Context, time.Time) ([]int, error)
-	hooks       []func(context.Context, time.Time) ([]int, error)
-	history     []DBResetStalledFuncCall
+// DBUpdateCommitGraphFunc describes the behavior when the UpdateCommitGraph
+// method of the parent MockDB instance is invoked.
+type DBUpdateCommitGraphFunc struct {
+	defaultHook func(context.Context, int, string) error
+	hooks       []func(context.Context, int, string) error
+	history     []DBUpdateCommitGraphFuncCall
 	mutex       sync.Mutex
 }
 
-// ResetStalled delegates to the next hook function in the queue and stores
-// the parameter and result values of this invocation.
-func (m *MockDB) ResetStalled(v0 context.Context, v1 time.Time) ([]int, error) {
-	r0, r1 := m.ResetStalledFunc.nextHook()(v0, v1)
-	m.ResetStalledFunc.appendCall(DBResetStalledFuncCall{v0, v1, r0, r1})
-	return r0, r1
+// UpdateCommitGraph delegates to the next hook function in the queue and
+// stores the parameter and result values of this invocation.
+func (m *MockDB) UpdateCommitGraph(v0 context.Context, v1 int, v2 string) error {
+	r0 := m.UpdateCommitGraphFunc.nextHook()(v0, v1, v2)
+	m.UpdateCommitGraphFunc.appendCall(DBUpdateCommitGraphFuncCall{v0, v1, v2, r0})
+	return r0
 }
 
-// SetDefaultHook sets function that is called when the ResetStalled method
-// of the parent MockDB instance is invoked and the hook queue is empty.
-func (f *DBResetStalledFunc) SetDefaultHook(hook func(context.Context, time.Time) ([]int, error)) {
+// SetDefaultHook sets function that is called when the UpdateCommitGraph
+// method of the parent MockDB instance is invoked and the hook queue is
+// empty.
+func (f *DBUpdateCommitGraphFunc) SetDefaultHook(hook func(context.Context, int, string) error) {
 	f.defaultHook = hook
 }
 
 // PushHook adds a function to the end of hook queue. Each invocation of the
-// ResetStalled method of the parent MockDB instance inovkes the hook at the
-// front of the queue and discards it. After the queue is empty, the default
-// hook function is invoked for any future action.
-func (f *DBResetStalledFunc) PushHook(hook func(context.Context, time.Time) ([]int, error)) {
+// UpdateCommitGraph method of the parent MockDB instance inovkes the hook
+// at the front of the queue and discards it. After the queue is empty, the
+// default hook function is invoked for any future action.
+func (f *DBUpdateCommitGraphFunc) PushHook(hook func(context.Context, int, string) error) {
 	f.mutex.Lock()
 	f.hooks = append(f.hooks, hook)
 	f.mutex.Unlock()
@@ -1353,21 +3217,21 @@ func (f *DBResetStalledFunc) PushHook(hook func(context.Context, time.Time) ([]i
 
 // SetDefaultReturn calls SetDefaultDefaultHook with a function that returns
 // the given values.
-func (f *DBResetStalledFunc) SetDefaultReturn(r0 []int, r1 error) {
-	f.SetDefaultHook(func(context.Context, time.Time) ([]int, error) {
-		return r0, r1
+func (f *DBUpdateCommitGraphFunc) SetDefaultReturn(r0 error) {
+	f.SetDefaultHook(func(context.Context, int, string) error {
+		return r0
 	})
 }
 
 // PushReturn calls PushDefaultHook with a function that returns the given
 // values.
-func (f *DBResetStalledFunc) PushReturn(r0 []int, r1 error) {
-	f.PushHook(func(context.Context, time.Time) ([]int, error) {
-		return r0, r1
+func (f *DBUpdateCommitGraphFunc) PushReturn(r0 error) {
+	f.PushHook(func(context.Context, int, string) error {
+		return r0
 	})
 }
 
-func (f *DBResetStalledFunc) nextHook() func(context.Context, time.Time) ([]int, error) {
+func (f *DBUpdateCommitGraphFunc) nextHook() func(context.Context, int, string) error {
 	f.mutex.Lock()
 	defer f.mutex.Unlock()
 
@@ -1380,80 +3244,80 @@ func (f *DBResetStalledFunc) nextHook() func(context.Context, time.Time) ([]int,
 	return hook
 }
 
-func (f *DBResetStalledFunc) appendCall(r0 DBResetStalledFuncCall) {
+func (f *DBUpdateCommitGraphFunc) appendCall(r0 DBUpdateCommitGraphFuncCall) {
 	f.mutex.Lock()
 	f.history = append(f.history, r0)
 	f.mutex.Unlock()
 }
 
-// History returns a sequence of DBResetStalledFuncCall objects describing
-// the invocations of this function.
-func (f *DBResetStalledFunc) History() []DBResetStalledFuncCall {
+// History returns a sequence of DBUpdateCommitGraphFuncCall objects
+// describing the invocations of this function.
+func (f *DBUpdateCommitGraphFunc) History() []DBUpdateCommitGraphFuncCall {
 	f.mutex.Lock()
-	history := make([]DBResetStalledFuncCall, len(f.history))
+	history := make([]DBUpdateCommitGraphFuncCall, len(f.history))
 	copy(history, f.history)
 	f.mutex.Unlock()
 
 	return history
 }
 
-// DBResetStalledFuncCall is an object that describes an invocation of
-// method ResetStalled on an instance of MockDB.
-type DBResetStalledFuncCall struct {
+// DBUpdateCommitGraphFuncCall is an object that describes an invocation of
+// method UpdateCommitGraph on an instance of MockDB.
+type DBUpdateCommitGraphFuncCall struct {
 	// Arg0 is the value of the 1st argument passed to this method
 	// invocation.
 	Arg0 context.Context
 	// Arg1 is the value of the 2nd argument passed to this method
 	// invocation.
-	Arg1 time.Time
-	// Result0 is the value of the 1st result returned from this method
+	Arg1 int
+	// Arg2 is the value of the 3rd argument passed to this method
 	// invocation.
-	Result0 []int
-	// Result1 is the value of the 2nd result returned from this method
+	Arg2 string
+	// Result0 is the value of the 1st result returned from this method
 	// invocation.
-	Result1 error
+	Result0 error
 }
 
 // Args returns an interface slice containing the arguments of this
 // invocation.
-func (c DBResetStalledFuncCall) Args() []interface{} {
-	return []interface{}{c.Arg0, c.Arg1}
+func (c DBUpdateCommitGraphFuncCall) Args() []interface{} {
+	return []interface{}{c.Arg0, c.Arg1, c.Arg2}
 }
 
 // Results returns an interface slice containing the results of this
 // invocation.
-func (c DBResetStalledFuncCall) Results() []interface{} {
-	return []interface{}{c.Result0, c.Result1}
+func (c DBUpdateCommitGraphFuncCall) Results() []interface{} {
+	return []interface{}{c.Result0}
 }
 
-// DBSameRepoPagerFunc describes the behavior when the SameRepoPager method
+// DBUpdateCommitsFunc describes the behavior when the UpdateCommits method
 // of the parent MockDB instance is invoked.
-type DBSameRepoPagerFunc struct {
-	defaultHook func(context.Context, int, string, string, string, string, int) (int, db.ReferencePager, error)
-	hooks       []func(context.Context, int, string, string, string, string, int) (int, db.ReferencePager, error)
-	history     []DBSameRepoPagerFuncCall
+type DBUpdateCommitsFunc struct {
+	defaultHook func(context.Context, int, commitgraph.Graph) error
+	hooks       []func(context.Context, int, commitgraph.Graph) error
+	history     []DBUpdateCommitsFuncCall
 	mutex       sync.Mutex
 }
 
-// SameRepoPager delegates to the next hook function in the queue and stores
+// UpdateCommits delegates to the next hook function in the queue and stores
 // the parameter and result values of this invocation.
-func (m *MockDB) SameRepoPager(v0 context.Context, v1 int, v2 string, v3 string, v4 string, v5 string, v6 int) (int, db.ReferencePager, error) {
-	r0, r1, r2 := m.SameRepoPagerFunc.nextHook()(v0, v1, v2, v3, v4, v5, v6)
-	m.SameRepoPagerFunc.appendCall(DBSameRepoPagerFuncCall{v0, v1, v2, v3, v4, v5, v6, r0, r1, r2})
-	return r0, r1, r2
+func (m *MockDB) UpdateCommits(v0 context.Context, v1 int, v2 commitgraph.Graph) error {
+	r0 := m.UpdateCommitsFunc.nextHook()(v0, v1, v2)
+	m.UpdateCommitsFunc.appendCall(DBUpdateCommitsFuncCall{v0, v1, v2, r0})
+	return r0
 }
 
-// SetDefaultHook sets function that is called when the SameRepoPager method
+// SetDefaultHook sets function that is called when the UpdateCommits method
 // of the parent MockDB instance is invoked and the hook queue is empty.
-func (f *DBSameRepoPagerFunc) SetDefaultHook(hook func(context.Context, int, string, string, string, string, int) (int, db.ReferencePager, error)) {
+func (f *DBUpdateCommitsFunc) SetDefaultHook(hook func(context.Context, int, commitgraph.Graph) error) {
 	f.defaultHook = hook
 }
 
 // PushHook adds a function to the end of hook queue. Each invocation of the
-// SameRepoPager method of the parent MockDB instance inovkes the hook at
+// UpdateCommits method of the parent MockDB instance inovkes the hook at
 // the front of the queue and discards it. After the queue is empty, the
 // default hook function is invoked for any future action.
-func (f *DBSameRepoPagerFunc) PushHook(hook func(context.Context, int, string, string, string, string, int) (int, db.ReferencePager, error)) {
+func (f *DBUpdateCommitsFunc) PushHook(hook func(context.Context, int, commitgraph.Graph) error) {
 	f.mutex.Lock()
 	f.hooks = append(f.hooks, hook)
 	f.mutex.Unlock()
@@ -1461,21 +3325,21 @@ func (f *DBSameRepoPagerFunc) PushHook(hook func(context.Context, int, string, s
 
 // SetDefaultReturn calls SetDefaultDefaultHook with a function that returns
 // the given values.
-func (f *DBSameRepoPagerFunc) SetDefaultReturn(r0 int, r1 db.ReferencePager, r2 error) {
-	f.SetDefaultHook(func(context.Context, int, string, string, string, string, int) (int, db.ReferencePager, error) {
-		return r0, r1, r2
+func (f *DBUpdateCommitsFunc) SetDefaultReturn(r0 error) {
+	f.SetDefaultHook(func(context.Context, int, commitgraph.Graph) error {
+		return r0
 	})
 }
 
 // PushReturn calls PushDefaultHook with a function that returns the given
 // values.
-func (f *DBSameRepoPagerFunc) PushReturn(r0 int, r1 db.ReferencePager, r2 error) {
-	f.PushHook(func(context.Context, int, string, string, string, string, int) (int, db.ReferencePager, error) {
-		return r0, r1, r2
+func (f *DBUpdateCommitsFunc) PushReturn(r0 error) {
+	f.PushHook(func(context.Context, int, commitgraph.Graph) error {
+		return r0
 	})
 }
 
-func (f *DBSameRepoPagerFunc) nextHook() func(context.Context, int, string, string, string, string, int) (int, db.ReferencePager, error) {
+func (f *DBUpdateCommitsFunc) nextHook() func(context.Context, int, commitgraph.Graph) error {
 	f.mutex.Lock()
 	defer f.mutex.Unlock()
 
@@ -1488,26 +3352,26 @@ func (f *DBSameRepoPagerFunc) nextHook() func(context.Context, int, string, stri
 	return hook
 }
 
-func (f *DBSameRepoPagerFunc) appendCall(r0 DBSameRepoPagerFuncCall) {
+func (f *DBUpdateCommitsFunc) appendCall(r0 DBUpdateCommitsFuncCall) {
 	f.mutex.Lock()
 	f.history = append(f.history, r0)
 	f.mutex.Unlock()
 }
 
-// History returns a sequence of DBSameRepoPagerFuncCall objects describing
+// History returns a sequence of DBUpdateCommitsFuncCall objects describing
 // the invocations of this function.
-func (f *DBSameRepoPagerFunc) History() []DBSameRepoPagerFuncCall {
+func (f *DBUpdateCommitsFunc) History() []DBUpdateCommitsFuncCall {
 	f.mutex.Lock()
-	history := make([]DBSameRepoPagerFuncCall, len(f.history))
+	history := make([]DBUpdateCommitsFuncCall, len(f.history))
 	copy(history, f.history)
 	f.mutex.Unlock()
 
 	return history
 }
 
-// DBSameRepoPagerFuncCall is an object that describes an invocation of
-// method SameRepoPager on an instance of MockDB.
-type DBSameRepoPagerFuncCall struct {
+// DBUpdateCommitsFuncCall is an object that describes an invocation of
+// method UpdateCommits on an instance of MockDB.
+type DBUpdateCommitsFuncCall struct {
 	// Arg0 is the value of the 1st argument passed to this method
 	// invocation.
 	Arg0 context.Context
@@ -1516,38 +3380,20 @@ type DBSameRepoPagerFuncCall struct {
 	Arg1 int
 	// Arg2 is the value of the 3rd argument passed to this method
 	// invocation.
-	Arg2 string
-	// Arg3 is the value of the 4th argument passed to this method
-	// invocation.
-	Arg3 string
-	// Arg4 is the value of the 5th argument passed to this method
-	// invocation.
-	Arg4 string
-	// Arg5 is the value of the 6th argument passed to this method
-	// invocation.
-	Arg5 string
-	// Arg6 is the value of the 7th argument passed to this method
-	// invocation.
-	Arg6 int
+	Arg2 commitgraph.Graph
 	// Result0 is the value of the 1st result returned from this method
 	// invocation.
-	Result0 int
-	// Result1 is the value of the 2nd result returned from this method
-	// invocation.
-	Result1 db.ReferencePager
-	// Result2 is the value of the 3rd result returned from this method
-	// invocation.
-	Result2 error
+	Result0 error
 }
 
 // Args returns an interface slice containing the arguments of this
 // invocation.
-func (c DBSameRepoPagerFuncCall) Args() []interface{} {
-	return []interface{}{c.Arg0, c.Arg1, c.Arg2, c.Arg3, c.Arg4, c.Arg5, c.Arg6}
+func (c DBUpdateCommitsFuncCall) Args() []interface{} {
+	return []interface{}{c.Arg0, c.Arg1, c.Arg2}
 }
 
 // Results returns an interface slice containing the results of this
 // invocation.
-func (c DBSameRepoPagerFuncCall) Results() []interface{} {
-	return []interface{}{c.Result0, c.Result1, c.Result2}
+func (c DBUpdateCommitsFuncCall) Results() []interface{} {
+	return []interface{}{c.Result0}
 }
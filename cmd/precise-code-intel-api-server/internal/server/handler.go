@@ -14,6 +14,7 @@ import (
 )
 
 const DefaultUploadPageSize = 50
+const DefaultIndexPageSize = 50
 
 func (s *Server) handler() http.Handler {
 	mux := mux.NewRouter()
@@ -27,6 +28,9 @@ func (s *Server) handler() http.Handler {
 	mux.Path("/hover").Methods("GET").HandlerFunc(s.handleHover)
 	mux.Path("/uploads").Methods("POST").HandlerFunc(s.handleUploads)
 	mux.Path("/prune").Methods("POST").HandlerFunc(s.handlePrune)
+	mux.Path("/indexes/{id:[0-9]+}").Methods("GET").HandlerFunc(s.handleGetIndexByID)
+	mux.Path("/indexes/repository/{id:[0-9]+}").Methods("GET").HandlerFunc(s.handleGetIndexesByRepo)
+	mux.Path("/indexes").Methods("GET").HandlerFunc(s.handleGetIndexes)
 	mux.HandleFunc("/healthz", func(w http.ResponseWriter, _ *http.Request) {
 		w.WriteHeader(http.StatusOK)
 	})
@@ -51,7 +55,7 @@ func (s *Server) handleGetUploadByID(w http.ResponseWriter, r *http.Request) {
 
 // DELETE /uploads/{id:[0-9]+}
 func (s *Server) handleDeleteUploadByID(w http.ResponseWriter, r *http.Request) {
-	exists, err := s.db.DeleteUploadByID(r.Context(), int(idFromRequest(r)), getTipCommit)
+	exists, err := s.db.DeleteUploadByID(r.Context(), int(idFromRequest(r)))
 	if err != nil {
 		log15.Error("Failed to delete upload", "error", err)
 		http.Error(w, fmt.Sprintf("failed to delete upload: %s", err.Error()), http.StatusInternalServerError)
@@ -96,6 +100,55 @@ func (s *Server) handleGetUploadsByRepo(w http.ResponseWriter, r *http.Request)
 	writeJSON(w, map[string]interface{}{"uploads": uploads, "totalCount": totalCount})
 }
 
+// GET /indexes/{id:[0-9]+}
+func (s *Server) handleGetIndexByID(w http.ResponseWriter, r *http.Request) {
+	index, exists, err := s.db.GetIndexByID(r.Context(), int(idFromRequest(r)))
+	if err != nil {
+		log15.Error("Failed to retrieve index job", "error", err)
+		http.Error(w, fmt.Sprintf("failed to retrieve index job: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+	if !exists {
+		http.Error(w, "index job not found", http.StatusNotFound)
+		return
+	}
+
+	writeJSON(w, index)
+}
+
+// GET /indexes/repository/{id:[0-9]+}
+func (s *Server) handleGetIndexesByRepo(w http.ResponseWriter, r *http.Request) {
+	s.writeIndexes(w, r, int(idFromRequest(r)))
+}
+
+// GET /indexes
+func (s *Server) handleGetIndexes(w http.ResponseWriter, r *http.Request) {
+	s.writeIndexes(w, r, 0)
+}
+
+// writeIndexes writes a page of the index jobs of the given repository, or of all repositories
+// if the repository identifier is zero.
+func (s *Server) writeIndexes(w http.ResponseWriter, r *http.Request, repositoryID int) {
+	limit := getQueryIntDefault(r, "limit", DefaultIndexPageSize)
+	offset := getQueryInt(r, "offset")
+
+	indexes, totalCount, err := s.db.GetIndexes(r.Context(), repositoryID, getQuery(r, "state"), limit, offset)
+	if err != nil {
+		log15.Error("Failed to list index jobs", "error", err)
+		http.Error(w, fmt.Sprintf("failed to list index jobs: %s", err.Error()), http.StatusInternalServerError)
+		return
+	}
+
+	if offset+len(indexes) < totalCount {
+		w.Header().Set("Link", makeNextLink(r.URL, map[string]interface{}{
+			"limit":  limit,
+			"offset": offset + len(indexes),
+		}))
+	}
+
+	writeJSON(w, map[string]interface{}{"indexes": indexes, "totalCount": totalCount})
+}
+
 // POST /upload
 func (s *Server) handleEnqueue(w http.ResponseWriter, r *http.Request) {
 	f, err := ioutil.TempFile("", "upload-")
@@ -115,7 +168,7 @@ func (s *Server) handleEnqueue(w http.ResponseWriter, r *http.Request) {
 
 	indexerName := getQuery(r, "indexerName")
 	if indexerName == "" {
-		if indexerName, err = readIndexerNameFromFile(f); err != nil {
+		if indexerName, err = ReadIndexerNameFromFile(f); err != nil {
 			log15.Error("Failed to read indexer name from upload", "error", err)
 			http.Error(w, fmt.Sprintf("failed to read indexer name from upload: %s", err.Error()), http.StatusInternalServerError)
 			return
@@ -238,7 +291,21 @@ func (s *Server) handleReferences(w http.ResponseWriter, r *http.Request) {
 		}))
 	}
 
-	writeJSON(w, map[string]interface{}{"locations": outers})
+	payload := map[string]interface{}{"locations": outers}
+
+	// Only the first page carries the estimate, as it does not change between pages
+	if getQuery(r, "rawCursor") == "" {
+		totalCount, err := s.api.EstimateReferenceCount(r.Context(), cursor)
+		if err != nil {
+			log15.Error("Failed to estimate reference count", "error", err)
+			http.Error(w, fmt.Sprintf("failed to estimate reference count: %s", err.Error()), http.StatusInternalServerError)
+			return
+		}
+
+		payload["totalCount"] = totalCount
+	}
+
+	writeJSON(w, payload)
 }
 
 // GET /hover
@@ -20,12 +20,12 @@ type toolInfo struct {
 
 var ErrInvalidMetaDataVertex = errors.New("invalid metadata vertex")
 
-// readIndexerNameFromFile returns the name of the tool that generated
+// ReadIndexerNameFromFile returns the name of the tool that generated
 // the given index file. This function reads only the first line of the
 // file, where the metadata vertex is assumed to be in all valid dumps.
 // This function also resets the offset of the file to the beginning of
 // the file before and after reading.
-func readIndexerNameFromFile(f *os.File) (string, error) {
+func ReadIndexerNameFromFile(f *os.File) (string, error) {
 	_, err1 := f.Seek(0, 0)
 	name, err2 := readIndexerName(f)
 	_, err3 := f.Seek(0, 0)
@@ -41,7 +41,7 @@ func TestReadIndexerNameFromFile(t *testing.T) {
 
 	_, _ = io.Copy(tempFile, generateTestIndex(testMetaDataVertex))
 
-	name, err := readIndexerNameFromFile(tempFile)
+	name, err := ReadIndexerNameFromFile(tempFile)
 	if err != nil {
 		t.Fatalf("unexpected error reading indexer name: %s", err)
 	}
@@ -6,11 +6,15 @@ import (
 	"os/signal"
 	"syscall"
 
+	"github.com/sourcegraph/sourcegraph/cmd/precise-code-intel-api-server/internal/autoindex"
+	"github.com/sourcegraph/sourcegraph/cmd/precise-code-intel-api-server/internal/commits"
+	"github.com/sourcegraph/sourcegraph/cmd/precise-code-intel-api-server/internal/indexer"
 	"github.com/sourcegraph/sourcegraph/cmd/precise-code-intel-api-server/internal/janitor"
 	"github.com/sourcegraph/sourcegraph/cmd/precise-code-intel-api-server/internal/server"
 	bundles "github.com/sourcegraph/sourcegraph/internal/codeintel/bundles/client"
 	"github.com/sourcegraph/sourcegraph/internal/codeintel/db"
 	"github.com/sourcegraph/sourcegraph/internal/conf"
+	"github.com/sourcegraph/sourcegraph/internal/db/dbconn"
 	"github.com/sourcegraph/sourcegraph/internal/debugserver"
 	"github.com/sourcegraph/sourcegraph/internal/env"
 	"github.com/sourcegraph/sourcegraph/internal/tracer"
@@ -23,7 +27,13 @@ func main() {
 
 	var (
 		janitorInterval  = mustParseInterval(rawJanitorInterval, "PRECISE_CODE_INTEL_JANITOR_INTERVAL")
+		indexerInterval  = mustParseInterval(rawIndexerInterval, "PRECISE_CODE_INTEL_INDEXER_INTERVAL")
 		bundleManagerURL = mustGet(rawBundleManagerURL, "PRECISE_CODE_INTEL_BUNDLE_MANAGER_URL")
+
+		commitGraphUpdaterInterval = mustParseInterval(rawCommitGraphUpdaterInterval, "PRECISE_CODE_INTEL_COMMIT_GRAPH_UPDATER_INTERVAL")
+
+		autoIndexingSchedulerInterval = mustParseInterval(rawAutoIndexingSchedulerInterval, "PRECISE_CODE_INTEL_AUTO_INDEXING_SCHEDULER_INTERVAL")
+		autoIndexingWorkerInterval    = mustParseInterval(rawAutoIndexingWorkerInterval, "PRECISE_CODE_INTEL_AUTO_INDEXING_WORKER_INTERVAL")
 	)
 
 	db := mustInitializeDatabase()
@@ -33,11 +43,13 @@ func main() {
 		host = "127.0.0.1"
 	}
 
+	bundleManagerClient := bundles.New(bundleManagerURL)
+
 	serverInst := server.New(server.ServerOpts{
 		Host:                host,
 		Port:                3186,
 		DB:                  db,
-		BundleManagerClient: bundles.New(bundleManagerURL),
+		BundleManagerClient: bundleManagerClient,
 	})
 
 	janitorInst := janitor.NewJanitor(janitor.JanitorOpts{
@@ -45,8 +57,34 @@ func main() {
 		JanitorInterval: janitorInterval,
 	})
 
+	indexerInst := indexer.NewIndexer(indexer.IndexerOpts{
+		DB:                  db,
+		BundleManagerClient: bundleManagerClient,
+		IndexerInterval:     indexerInterval,
+	})
+
+	updaterInst := commits.NewUpdater(commits.UpdaterOpts{
+		DB:              db,
+		UpdaterInterval: commitGraphUpdaterInterval,
+	})
+
+	schedulerInst := autoindex.NewScheduler(autoindex.SchedulerOpts{
+		DB:                db,
+		SchedulerInterval: autoIndexingSchedulerInterval,
+	})
+
+	workerInst := autoindex.NewWorker(autoindex.WorkerOpts{
+		DB:                  db,
+		BundleManagerClient: bundleManagerClient,
+		WorkerInterval:      autoIndexingWorkerInterval,
+	})
+
 	go serverInst.Start()
 	go janitorInst.Start()
+	go indexerInst.Start()
+	go updaterInst.Start()
+	go schedulerInst.Start()
+	go workerInst.Start()
 	go debugserver.Start()
 	waitForSignal()
 }
@@ -64,6 +102,11 @@ func mustInitializeDatabase() db.DB {
 		log.Fatalf("failed to initialize db store: %s", err)
 	}
 
+	// Repository names are read from the frontend's tables
+	if err := dbconn.ConnectToDB(postgresDSN); err != nil {
+		log.Fatalf("failed to connect to frontend database: %s", err)
+	}
+
 	return db
 }
 
@@ -27,6 +27,7 @@ func (s *Server) handler() http.Handler {
 	mux.Path("/dbs/{id:[0-9]+}/hover").Methods("GET").HandlerFunc(s.handleHover)
 	mux.Path("/dbs/{id:[0-9]+}/monikersByPosition").Methods("GET").HandlerFunc(s.handleMonikersByPosition)
 	mux.Path("/dbs/{id:[0-9]+}/monikerResults").Methods("GET").HandlerFunc(s.handleMonikerResults)
+	mux.Path("/dbs/{id:[0-9]+}/monikerCounts").Methods("GET").HandlerFunc(s.handleMonikerCounts)
 	mux.Path("/dbs/{id:[0-9]+}/packageInformation").Methods("GET").HandlerFunc(s.handlePackageInformation)
 	mux.HandleFunc("/healthz", func(w http.ResponseWriter, _ *http.Request) {
 		w.WriteHeader(http.StatusOK)
@@ -99,14 +100,9 @@ func (s *Server) handleMonikersByPosition(w http.ResponseWriter, r *http.Request
 // GET /dbs/{id:[0-9]+}/monikerResults
 func (s *Server) handleMonikerResults(w http.ResponseWriter, r *http.Request) {
 	s.dbQuery(w, r, func(db *database.Database) (interface{}, error) {
-		var tableName string
-		switch getQuery(r, "modelType") {
-		case "definition":
-			tableName = "definitions"
-		case "reference":
-			tableName = "references"
-		default:
-			return nil, errors.New("illegal tableName supplied")
+		tableName, err := tableNameFromModelType(getQuery(r, "modelType"))
+		if err != nil {
+			return nil, err
 		}
 
 		locations, count, err := db.MonikerResults(
@@ -124,6 +120,18 @@ func (s *Server) handleMonikerResults(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// GET /dbs/{id:[0-9]+}/monikerCounts
+func (s *Server) handleMonikerCounts(w http.ResponseWriter, r *http.Request) {
+	s.dbQuery(w, r, func(db *database.Database) (interface{}, error) {
+		tableName, err := tableNameFromModelType(getQuery(r, "modelType"))
+		if err != nil {
+			return nil, err
+		}
+
+		return db.MonikerCounts(tableName)
+	})
+}
+
 // GET /dbs/{id:[0-9]+}/packageInformation
 func (s *Server) handlePackageInformation(w http.ResponseWriter, r *http.Request) {
 	s.dbQuery(w, r, func(db *database.Database) (interface{}, error) {
@@ -136,6 +144,19 @@ func (s *Server) handlePackageInformation(w http.ResponseWriter, r *http.Request
 	})
 }
 
+// tableNameFromModelType returns the name of the bundle table that holds monikers
+// of the given model type.
+func tableNameFromModelType(modelType string) (string, error) {
+	switch modelType {
+	case "definition":
+		return "definitions", nil
+	case "reference":
+		return "references", nil
+	}
+
+	return "", errors.New("illegal tableName supplied")
+}
+
 // doUpload writes the HTTP request body to the path determined by the given
 // makeFilename function.
 func (s *Server) doUpload(w http.ResponseWriter, r *http.Request, makeFilename func(bundleDir string, id int64) string) {
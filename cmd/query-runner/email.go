@@ -23,10 +23,15 @@ func canSendEmail(ctx context.Context) error {
 	return nil
 }
 
-func (n *notifier) emailNotify(ctx context.Context) {
+// emailNotifier emails notifications to recipients.
+type emailNotifier struct{}
+
+func (emailNotifier) notifyResults(ctx context.Context, n *notification) error {
+	if !n.recipients.any(func(r *recipient) bool { return r.email }) {
+		return nil
+	}
 	if err := canSendEmail(ctx); err != nil {
-		log15.Error("Failed to send email notification for saved search.", "error", err)
-		return
+		return errors.Wrap(err, "sending email notification")
 	}
 
 	// Send tx emails asynchronously.
@@ -35,6 +40,10 @@ func (n *notifier) emailNotify(ctx context.Context) {
 		defer cancel()
 
 		for _, recipient := range n.recipients {
+			if !recipient.email {
+				continue
+			}
+
 			ownership := "the" // example: "new search results have been found for {{.Ownership}} saved search"
 			if n.spec.Subject.User != nil && *n.spec.Subject.User == recipient.spec.userID {
 				ownership = "your"
@@ -54,6 +63,7 @@ func (n *notifier) emailNotify(ctx context.Context) {
 				ApproximateResultCount string
 				Ownership              string
 				PluralResults          string
+				Matches                []*match
 			}{
 				URL:                    searchURL(n.newQuery, utmSourceEmail),
 				Description:            n.query.Description,
@@ -61,11 +71,22 @@ func (n *notifier) emailNotify(ctx context.Context) {
 				ApproximateResultCount: n.results.Data.Search.Results.ApproximateResultCount,
 				Ownership:              ownership,
 				PluralResults:          plural,
+				Matches:                n.shownMatches(),
 			}); err != nil {
 				log15.Error("Failed to send email notification for new saved search results.", "userID", recipient.spec.userID, "error", err)
 			}
 		}
 	}()
+	return nil
+}
+
+func (emailNotifier) notifyTest(ctx context.Context, query api.SavedQuerySpecAndConfig, recipients recipients) error {
+	for _, recipient := range recipients {
+		if err := emailNotifySubscribeUnsubscribe(ctx, recipient, query, notifySubscribedTemplate); err != nil {
+			return fmt.Errorf("error sending email notifications to %s: %s", recipient.spec, err)
+		}
+	}
+	return nil
 }
 
 var newSearchResultsEmailTemplates = txemail.MustValidate(txtypes.Templates{
@@ -75,6 +96,11 @@ var newSearchResultsEmailTemplates = txemail.MustValidate(txtypes.Templates{
 
   "{{.Description}}"
 
+{{range .Matches}}
+{{.Repository}}@{{.AbbreviatedCommit}} {{.Subject}}
+{{.URL}}
+{{range .Lines}}    {{.}}
+{{end}}{{end}}
 View the new result{{.PluralResults}} on Sourcegraph: {{.URL}}
 `,
 	HTML: `
@@ -82,6 +108,14 @@ View the new result{{.PluralResults}} on Sourcegraph: {{.URL}}
 
 <p style="padding-left: 16px">&quot;{{.Description}}&quot;</p>
 
+{{range .Matches}}
+<p>
+  <a href="{{.URL}}">{{.Repository}}@{{.AbbreviatedCommit}}</a> {{.Subject}}
+  {{if .Lines}}<pre style="padding-left: 16px">{{range .Lines}}{{.}}
+{{end}}</pre>{{end}}
+</p>
+{{end}}
+
 <p><a href="{{.URL}}">View the new result{{.PluralResults}} on Sourcegraph</a></p>
 `,
 })
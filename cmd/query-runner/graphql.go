@@ -6,10 +6,13 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
+	"net/http"
 	"net/url"
 	"runtime"
+	"strconv"
 	"time"
 
+	"github.com/sourcegraph/sourcegraph/internal/actor"
 	"github.com/sourcegraph/sourcegraph/internal/api"
 
 	"golang.org/x/net/context/ctxhttp"
@@ -81,6 +84,7 @@ const gqlSearchQuery = `query Search(
 						}
 						oid
 						abbreviatedOID
+						url
 						author {
 							person {
 								displayName
@@ -122,7 +126,11 @@ type gqlSearchResponse struct {
 	Errors []interface{}
 }
 
-func search(ctx context.Context, query string) (*gqlSearchResponse, error) {
+// search runs the search query. If uid is nonzero, the search is run as that
+// user, so that only results in repositories the user can access are returned.
+// Otherwise it is run as an internal actor, which bypasses repository
+// permissions.
+func search(ctx context.Context, query string, uid int32) (*gqlSearchResponse, error) {
 	var buf bytes.Buffer
 	err := json.NewEncoder(&buf).Encode(graphQLQuery{
 		Query:     gqlSearchQuery,
@@ -137,7 +145,16 @@ func search(ctx context.Context, query string) (*gqlSearchResponse, error) {
 		return nil, errors.Wrap(err, "constructing frontend URL")
 	}
 
-	resp, err := ctxhttp.Post(ctx, nil, url, "application/json", &buf)
+	req, err := http.NewRequest("POST", url, &buf)
+	if err != nil {
+		return nil, errors.Wrap(err, "NewRequest")
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if uid != 0 {
+		req.Header.Set(actor.UIDHeader, strconv.FormatInt(int64(uid), 10))
+	}
+
+	resp, err := ctxhttp.Do(ctx, nil, req)
 	if err != nil {
 		return nil, errors.Wrap(err, "Post")
 	}
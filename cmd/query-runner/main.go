@@ -138,7 +138,7 @@ func (e *executorT) run(ctx context.Context) error {
 // runQuery runs the given query if an appropriate amount of time has elapsed
 // since it last ran.
 func (e *executorT) runQuery(ctx context.Context, spec api.SavedQueryIDSpec, query api.ConfigSavedQuery) error {
-	if !query.Notify && !query.NotifySlack {
+	if !hasNotifications(query) {
 		// No need to run this query because there will be nobody to notify.
 		return nil
 	}
@@ -197,7 +197,16 @@ func (e *executorT) runQuery(ctx context.Context, spec api.SavedQueryIDSpec, que
 	// fails in order to avoid e.g. failed saved queries from executing
 	// constantly and potentially causing harm to the system. We'll retry at
 	// our normal interval, regardless of errors.
-	v, execDuration, searchErr := performSearch(ctx, newQuery)
+	//
+	// 🚨 SECURITY: Searches owned by a user are run as that user, so that
+	// notifications only include results from repositories the user can
+	// access. Org searches can only be run as an internal actor, so their
+	// notifications do not include the matches (see notify).
+	var searchAs int32
+	if spec.Subject.User != nil {
+		searchAs = *spec.Subject.User
+	}
+	v, execDuration, searchErr := performSearch(ctx, newQuery, searchAs)
 	if err := api.InternalClient.SavedQueriesSetInfo(ctx, &api.SavedQueryInfo{
 		Query:        query.Query,
 		LastExecuted: time.Now(),
@@ -222,12 +231,12 @@ func (e *executorT) runQuery(ctx context.Context, spec api.SavedQueryIDSpec, que
 	return nil
 }
 
-func performSearch(ctx context.Context, query string) (v *gqlSearchResponse, execDuration time.Duration, err error) {
+func performSearch(ctx context.Context, query string, uid int32) (v *gqlSearchResponse, execDuration time.Duration, err error) {
 	attempts := 0
 	for {
 		// Query for search results.
 		start := time.Now()
-		v, err := search(ctx, query)
+		v, err := search(ctx, query, uid)
 		execDuration := time.Since(start)
 		if err != nil {
 			return nil, execDuration, errors.Wrap(err, "search")
@@ -275,48 +284,33 @@ func latestResultTime(prevInfo *api.SavedQueryInfo, v *gqlSearchResponse, search
 
 var externalURL *url.URL
 
-// notify handles sending notifications for new search results.
-func notify(ctx context.Context, spec api.SavedQueryIDSpec, query api.ConfigSavedQuery, newQuery string, results *gqlSearchResponse) error {
-	if len(results.Data.Search.Results.Results) == 0 {
-		return nil
-	}
-	log15.Info("sending notifications", "new_results", len(results.Data.Search.Results.Results), "description", query.Description)
+const (
+	utmSourceEmail   = "saved-search-email"
+	utmSourceSlack   = "saved-search-slack"
+	utmSourceTeams   = "saved-search-teams"
+	utmSourceWebhook = "saved-search-webhook"
+)
 
-	// Determine which users to notify.
-	recipients, err := getNotificationRecipients(ctx, spec, query)
-	if err != nil {
-		return err
+func searchURL(query, utmSource string) string {
+	// Construct URL to the search query.
+	searchURL := absoluteURL("search")
+	if searchURL == "" {
+		return ""
 	}
-
-	// Send slack notifications.
-	n := &notifier{
-		spec:       spec,
-		query:      query,
-		newQuery:   newQuery,
-		results:    results,
-		recipients: recipients,
+	u, err := url.Parse(searchURL)
+	if err != nil {
+		return ""
 	}
-
-	// Send Slack and email notifications.
-	n.slackNotify(ctx)
-	n.emailNotify(ctx)
-	return nil
-}
-
-type notifier struct {
-	spec       api.SavedQueryIDSpec
-	query      api.ConfigSavedQuery
-	newQuery   string
-	results    *gqlSearchResponse
-	recipients recipients
+	q := u.Query()
+	q.Set("q", query)
+	q.Set("utm_source", utmSource)
+	u.RawQuery = q.Encode()
+	return u.String()
 }
 
-const (
-	utmSourceEmail = "saved-search-email"
-	utmSourceSlack = "saved-search-slack"
-)
-
-func searchURL(query, utmSource string) string {
+// absoluteURL resolves the given URL path of the Sourcegraph web app against
+// the external URL. It returns "" if the external URL is unknown.
+func absoluteURL(path string) string {
 	if externalURL == nil {
 		// Determine the external URL.
 		externalURLStr, err := api.InternalClient.ExternalURL(context.Background())
@@ -331,13 +325,12 @@ func searchURL(query, utmSource string) string {
 		}
 	}
 
-	// Construct URL to the search query.
-	u := externalURL.ResolveReference(&url.URL{Path: "search"})
-	q := u.Query()
-	q.Set("q", query)
-	q.Set("utm_source", utmSource)
-	u.RawQuery = q.Encode()
-	return u.String()
+	u, err := url.Parse(path)
+	if err != nil {
+		log15.Error("failed to parse URL path", "path", path, "error", err)
+		return ""
+	}
+	return externalURL.ResolveReference(u).String()
 }
 
 func logEvent(userID int32, eventName, eventType string) {
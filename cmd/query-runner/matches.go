@@ -0,0 +1,126 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/inconshreveable/log15"
+)
+
+// maxMatchLines is the maximum number of matched lines included per match.
+const maxMatchLines = 10
+
+// A match is a new result of a saved search, in the form included in
+// notifications.
+type match struct {
+	Repository string `json:"repository"`
+	Commit     string `json:"commit"`
+	URL        string `json:"url"`
+	Author     string `json:"author"`
+	Date       string `json:"date"`
+	Subject    string `json:"subject"` // the first line of the commit message
+
+	// Lines are the lines of the commit's diff or message that match the
+	// query, depending on whether the query is a diff or a commit search.
+	Lines []string `json:"lines"`
+}
+
+// AbbreviatedCommit returns the abbreviated commit ID of the match.
+func (m *match) AbbreviatedCommit() string {
+	if len(m.Commit) > 7 {
+		return m.Commit[:7]
+	}
+	return m.Commit
+}
+
+// gqlCommitSearchResult is the shape of a CommitSearchResult in the response
+// to gqlSearchQuery.
+type gqlCommitSearchResult struct {
+	Typename       string `json:"__typename"`
+	MessagePreview *gqlHighlightedString
+	DiffPreview    *gqlHighlightedString
+	Commit         struct {
+		Repository struct {
+			Name string
+		}
+		OID    string
+		URL    string
+		Author struct {
+			Person struct {
+				DisplayName string
+			}
+			Date string
+		}
+		Message string
+	}
+}
+
+type gqlHighlightedString struct {
+	Value      string
+	Highlights []struct {
+		Line int // 1-based
+	}
+}
+
+// extractMatches returns the matches for the given search results. Results
+// that are not commit search results are skipped, since saved searches only
+// notify about commit and diff searches.
+func extractMatches(results []interface{}) []*match {
+	matches := make([]*match, 0, len(results))
+	for _, result := range results {
+		// Round trip the result through JSON to avoid asserting the types of
+		// the nested maps.
+		b, err := json.Marshal(result)
+		if err != nil {
+			log15.Error("failed to marshal search result", "error", err)
+			continue
+		}
+		var r gqlCommitSearchResult
+		if err := json.Unmarshal(b, &r); err != nil {
+			log15.Error("failed to unmarshal search result", "error", err)
+			continue
+		}
+		if r.Typename != "CommitSearchResult" {
+			continue
+		}
+
+		m := &match{
+			Repository: r.Commit.Repository.Name,
+			Commit:     r.Commit.OID,
+			URL:        r.Commit.URL,
+			Author:     r.Commit.Author.Person.DisplayName,
+			Date:       r.Commit.Author.Date,
+			Subject:    strings.SplitN(r.Commit.Message, "\n", 2)[0],
+		}
+		// Diff searches highlight the diff, and commit searches the message.
+		preview := r.DiffPreview
+		if preview == nil {
+			preview = r.MessagePreview
+		}
+		if preview != nil {
+			m.Lines = preview.highlightedLines(maxMatchLines)
+		}
+		matches = append(matches, m)
+	}
+	return matches
+}
+
+// highlightedLines returns up to max distinct lines of s that contain
+// highlights, in the order of the highlights.
+func (s *gqlHighlightedString) highlightedLines(max int) []string {
+	lines := strings.Split(s.Value, "\n")
+	var highlighted []string
+	seen := map[int]bool{}
+	for _, h := range s.Highlights {
+		// Highlights on lines that were removed from the preview have line -1.
+		if h.Line < 1 || h.Line > len(lines) || seen[h.Line] {
+			continue
+		}
+		seen[h.Line] = true
+		highlighted = append(highlighted, lines[h.Line-1])
+		if len(highlighted) == max {
+			break
+		}
+	}
+	return highlighted
+}
@@ -87,6 +87,16 @@ func (rs *recipients) add(r recipient) {
 	*rs = append(*rs, &r)
 }
 
+// any reports whether f is true for any of the recipients.
+func (rs recipients) any(f func(*recipient) bool) bool {
+	for _, r := range rs {
+		if f(r) {
+			return true
+		}
+	}
+	return false
+}
+
 // get returns the recipient with the given spec, if any, or else nil.
 func (rs recipients) get(s recipientSpec) *recipient {
 	for _, r := range rs {
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/inconshreveable/log15"
+	"github.com/pkg/errors"
+	"golang.org/x/net/context/ctxhttp"
+
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/httpcli"
+)
+
+// A notifier sends notifications for a saved search over one channel, such as
+// email or Slack. Each notifier decides from the saved search's configuration
+// and recipients whether it has anybody to notify.
+//
+// To support a new kind of notification, implement notifier and add it to
+// notifiers.
+type notifier interface {
+	// notifyResults notifies about new results of a saved search.
+	notifyResults(ctx context.Context, n *notification) error
+
+	// notifyTest sends a test notification for a saved search, so that users
+	// can check that notifications reach them.
+	notifyTest(ctx context.Context, query api.SavedQuerySpecAndConfig, recipients recipients) error
+}
+
+// notifiers are the notifiers used to send saved search notifications.
+var notifiers = []notifier{
+	emailNotifier{},
+	slackNotifier{},
+	webhookNotifier{},
+	teamsNotifier{},
+}
+
+// maxNotificationMatches is the maximum number of matches shown in
+// notifications meant to be read by people, such as emails. Webhook
+// notifications include all matches.
+const maxNotificationMatches = 10
+
+// notification describes new results of a saved search.
+type notification struct {
+	spec       api.SavedQueryIDSpec
+	query      api.ConfigSavedQuery
+	newQuery   string // the query that found the new results
+	results    *gqlSearchResponse
+	matches    []*match // the new results
+	recipients recipients
+}
+
+// notify handles sending notifications for new search results.
+func notify(ctx context.Context, spec api.SavedQueryIDSpec, query api.ConfigSavedQuery, newQuery string, results *gqlSearchResponse) error {
+	if len(results.Data.Search.Results.Results) == 0 {
+		return nil
+	}
+	log15.Info("sending notifications", "new_results", len(results.Data.Search.Results.Results), "description", query.Description)
+
+	// Determine which users to notify.
+	recipients, err := getNotificationRecipients(ctx, spec, query)
+	if err != nil {
+		return err
+	}
+
+	n := &notification{
+		spec:       spec,
+		query:      query,
+		newQuery:   newQuery,
+		results:    results,
+		matches:    []*match{},
+		recipients: recipients,
+	}
+	// 🚨 SECURITY: Only searches owned by a user are run with the user's
+	// repository permissions (see runQuery). The results of org searches may
+	// come from repositories that some members, or the receivers of webhooks,
+	// cannot access, so their notifications only link to the search.
+	if spec.Subject.User != nil {
+		n.matches = extractMatches(results.Data.Search.Results.Results)
+	}
+	for _, m := range n.matches {
+		if m.URL != "" {
+			m.URL = absoluteURL(m.URL)
+		}
+	}
+	for _, notifier := range notifiers {
+		if err := notifier.notifyResults(ctx, n); err != nil {
+			log15.Error("Failed to send saved search notification.", "description", query.Description, "error", err)
+		}
+	}
+	return nil
+}
+
+// shownMatches returns the matches to show in notifications meant to be read
+// by people.
+func (n *notification) shownMatches() []*match {
+	if len(n.matches) > maxNotificationMatches {
+		return n.matches[:maxNotificationMatches]
+	}
+	return n.matches
+}
+
+// hasNotifications reports whether anybody is notified about new results of
+// the saved search.
+func hasNotifications(query api.ConfigSavedQuery) bool {
+	return query.Notify || query.NotifySlack || isSet(query.WebhookURL) || isSet(query.TeamsWebhookURL)
+}
+
+// isSet reports whether the optional setting s is set.
+func isSet(s *string) bool {
+	return s != nil && *s != ""
+}
+
+// webhookClient is the HTTP client for webhook requests. Webhook URLs are
+// provided by users, so it must not be able to reach internal services.
+var webhookClient = func() *http.Client {
+	cli := &http.Client{}
+	if err := httpcli.PublicAddressesOnlyOpt(cli); err != nil {
+		panic(err)
+	}
+	return cli
+}()
+
+// postJSON POSTs the JSON encoding of payload to the URL u, and returns an
+// error if the response does not have a 2xx status code. Webhook URLs often
+// contain secrets, so u is not included in errors.
+func postJSON(ctx context.Context, u string, payload interface{}) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return errors.Wrap(err, "marshal JSON")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+
+	resp, err := ctxhttp.Post(ctx, webhookClient, u, "application/json", bytes.NewReader(body))
+	if err != nil {
+		if urlErr, ok := err.(*url.Error); ok {
+			err = urlErr.Err
+		}
+		return errors.Wrap(err, "webhook request")
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		respBody, _ := ioutil.ReadAll(resp.Body)
+		return fmt.Errorf("webhook request failed with %d %s", resp.StatusCode, bytes.TrimSpace(respBody))
+	}
+	return nil
+}
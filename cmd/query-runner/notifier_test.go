@@ -0,0 +1,295 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/sourcegraph/sourcegraph/internal/api"
+)
+
+func TestExtractMatches(t *testing.T) {
+	var results []interface{}
+	if err := json.Unmarshal([]byte(`[
+		{
+			"__typename": "CommitSearchResult",
+			"diffPreview": {
+				"value": "main.go main.go\n@@ -1,2 +1,2 @@\n-password = \"\"\n+password = \"hunter2\"\n context",
+				"highlights": [
+					{"line": 4, "character": 12, "length": 7},
+					{"line": 4, "character": 0, "length": 8},
+					{"line": -1, "character": 0, "length": 8}
+				]
+			},
+			"commit": {
+				"repository": {"name": "github.com/foo/bar"},
+				"oid": "2b5b7c12b5f8ac5a2fa7fdbf8cf0ec2185cd4d76",
+				"url": "/github.com/foo/bar/-/commit/2b5b7c12b5f8ac5a2fa7fdbf8cf0ec2185cd4d76",
+				"author": {"person": {"displayName": "Alice"}, "date": "2020-03-04 05:06:07 +0000 UTC"},
+				"message": "Add password\n\nOops."
+			}
+		},
+		{
+			"__typename": "CommitSearchResult",
+			"messagePreview": {
+				"value": "Fix leak\n\nRemove the hunter2 password.",
+				"highlights": [{"line": 3, "character": 11, "length": 7}]
+			},
+			"commit": {
+				"repository": {"name": "github.com/foo/baz"},
+				"oid": "d2ac2a0b",
+				"url": "/github.com/foo/baz/-/commit/d2ac2a0b",
+				"author": {"person": {"displayName": "Bob"}, "date": "2020-03-04 06:06:07 +0000 UTC"},
+				"message": "Fix leak\n\nRemove the hunter2 password."
+			}
+		},
+		{
+			"__typename": "FileMatch",
+			"resource": "git://github.com/foo/bar#main.go"
+		}
+	]`), &results); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []*match{
+		{
+			Repository: "github.com/foo/bar",
+			Commit:     "2b5b7c12b5f8ac5a2fa7fdbf8cf0ec2185cd4d76",
+			URL:        "/github.com/foo/bar/-/commit/2b5b7c12b5f8ac5a2fa7fdbf8cf0ec2185cd4d76",
+			Author:     "Alice",
+			Date:       "2020-03-04 05:06:07 +0000 UTC",
+			Subject:    "Add password",
+			Lines:      []string{`+password = "hunter2"`},
+		},
+		{
+			Repository: "github.com/foo/baz",
+			Commit:     "d2ac2a0b",
+			URL:        "/github.com/foo/baz/-/commit/d2ac2a0b",
+			Author:     "Bob",
+			Date:       "2020-03-04 06:06:07 +0000 UTC",
+			Subject:    "Fix leak",
+			Lines:      []string{"Remove the hunter2 password."},
+		},
+	}
+	if got := extractMatches(results); !reflect.DeepEqual(got, want) {
+		gotJSON, _ := json.MarshalIndent(got, "", "  ")
+		t.Errorf("got %s", gotJSON)
+	}
+}
+
+func TestWebhookNotifier(t *testing.T) {
+	var got webhookPayload
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if ct := r.Header.Get("Content-Type"); ct != "application/json" {
+			t.Errorf("got Content-Type %q, want application/json", ct)
+		}
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Error(err)
+		}
+	}))
+	defer srv.Close()
+
+	externalURL = &url.URL{Scheme: "https", Host: "sourcegraph.example.com"}
+	defer func() { externalURL = nil }()
+
+	defer allowLoopbackWebhooks()()
+
+	n := testNotification()
+	n.query.WebhookURL = &srv.URL
+	if err := (webhookNotifier{}).notifyResults(context.Background(), n); err != nil {
+		t.Fatal(err)
+	}
+
+	want := webhookPayload{
+		Event:                  "results",
+		Description:            "leaked passwords",
+		Query:                  "type:diff hunter2",
+		URL:                    "https://sourcegraph.example.com/search?q=type%3Adiff+hunter2+after%3A%222020-03-04T05%3A06%3A07Z%22&utm_source=saved-search-webhook",
+		ApproximateResultCount: "1",
+		Matches:                n.matches,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+
+	// Saved searches without a webhook URL are not notified.
+	n.query.WebhookURL = nil
+	if err := (webhookNotifier{}).notifyResults(context.Background(), n); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestWebhookNotifier_error(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "nope", http.StatusBadRequest)
+	}))
+	defer srv.Close()
+
+	externalURL = &url.URL{Scheme: "https", Host: "sourcegraph.example.com"}
+	defer func() { externalURL = nil }()
+
+	defer allowLoopbackWebhooks()()
+
+	n := testNotification()
+	secretURL := srv.URL + "/secret-token"
+	n.query.WebhookURL = &secretURL
+	err := (webhookNotifier{}).notifyResults(context.Background(), n)
+	if err == nil {
+		t.Fatal("want error for failed request")
+	}
+	if strings.Contains(err.Error(), "secret-token") {
+		t.Errorf("error %q contains the webhook URL", err)
+	}
+}
+
+func TestWebhookNotifier_internalAddress(t *testing.T) {
+	var called bool
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	}))
+	defer srv.Close()
+
+	// 🚨 SECURITY: Webhooks must not reach internal services, such as this
+	// server on a loopback address.
+	n := testNotification()
+	n.query.WebhookURL = &srv.URL
+	if err := (webhookNotifier{}).notifyResults(context.Background(), n); err == nil {
+		t.Error("want error for webhook URL with a loopback address")
+	}
+	if called {
+		t.Error("webhook with a loopback address was called")
+	}
+}
+
+func TestNotify_orgMatches(t *testing.T) {
+	var got webhookPayload
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Error(err)
+		}
+	}))
+	defer srv.Close()
+	defer allowLoopbackWebhooks()()
+
+	externalURL = &url.URL{Scheme: "https", Host: "sourcegraph.example.com"}
+	defer func() { externalURL = nil }()
+	defer func(old []notifier) { notifiers = old }(notifiers)
+	notifiers = []notifier{webhookNotifier{}}
+
+	var results []interface{}
+	if err := json.Unmarshal([]byte(`[{
+		"__typename": "CommitSearchResult",
+		"commit": {"repository": {"name": "github.com/foo/private"}, "oid": "d2ac2a0b", "message": "Secret"}
+	}]`), &results); err != nil {
+		t.Fatal(err)
+	}
+	v := &gqlSearchResponse{}
+	v.Data.Search.Results.Results = results
+
+	// 🚨 SECURITY: Searches owned by orgs are run as an internal actor, so
+	// their notifications must not include the matches.
+	orgID := int32(1)
+	spec := api.SavedQueryIDSpec{Subject: api.SettingsSubject{Org: &orgID}}
+	query := api.ConfigSavedQuery{Query: "type:commit secret", WebhookURL: &srv.URL}
+	if err := notify(context.Background(), spec, query, query.Query, v); err != nil {
+		t.Fatal(err)
+	}
+	if got.Event != "results" || len(got.Matches) != 0 {
+		t.Errorf("got event %q with %d matches, want results event without matches", got.Event, len(got.Matches))
+	}
+}
+
+func TestTeamsNotifier(t *testing.T) {
+	var got map[string]interface{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		if err := json.Unmarshal(body, &got); err != nil {
+			t.Error(err)
+		}
+		_, _ = w.Write([]byte("1"))
+	}))
+	defer srv.Close()
+
+	externalURL = &url.URL{Scheme: "https", Host: "sourcegraph.example.com"}
+	defer func() { externalURL = nil }()
+
+	defer allowLoopbackWebhooks()()
+
+	n := testNotification()
+	n.query.TeamsWebhookURL = &srv.URL
+	if err := (teamsNotifier{}).notifyResults(context.Background(), n); err != nil {
+		t.Fatal(err)
+	}
+
+	if got["@type"] != "MessageCard" {
+		t.Errorf("got @type %v, want MessageCard", got["@type"])
+	}
+	if want := `1 new result for saved search "leaked passwords"`; got["title"] != want {
+		t.Errorf("got title %v, want %q", got["title"], want)
+	}
+	sections, _ := got["sections"].([]interface{})
+	if len(sections) != 1 {
+		t.Fatalf("got %d sections, want 1", len(sections))
+	}
+	want := map[string]interface{}{
+		"activityTitle":    "[github.com/foo/bar@2b5b7c1](https://sourcegraph.example.com/github.com/foo/bar/-/commit/2b5b7c1)",
+		"activitySubtitle": "Alice: Add password",
+		"text":             "```\n+password = \"hunter2\"\n```",
+	}
+	if !reflect.DeepEqual(sections[0], want) {
+		t.Errorf("got section %v, want %v", sections[0], want)
+	}
+}
+
+func TestSlackMatch(t *testing.T) {
+	var b strings.Builder
+	writeSlackMatch(&b, &match{
+		Repository: "github.com/foo/bar",
+		Commit:     "2b5b7c12b5f8ac5a2fa7fdbf8cf0ec2185cd4d76",
+		URL:        "https://sourcegraph.example.com/github.com/foo/bar/-/commit/2b5b7c1",
+		Subject:    "Compare a < b",
+		Lines:      []string{"+if a < b && c {"},
+	})
+	want := "• <https://sourcegraph.example.com/github.com/foo/bar/-/commit/2b5b7c1|github.com/foo/bar@2b5b7c1> Compare a &lt; b\n```+if a &lt; b &amp;&amp; c {\n```"
+	if got := b.String(); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+// allowLoopbackWebhooks lets webhook requests reach test servers, which listen
+// on loopback addresses. It returns a function that restores webhookClient.
+func allowLoopbackWebhooks() func() {
+	old := webhookClient
+	webhookClient = http.DefaultClient
+	return func() { webhookClient = old }
+}
+
+// testNotification returns a notification of one new result of a diff search.
+func testNotification() *notification {
+	results := &gqlSearchResponse{}
+	results.Data.Search.Results.ApproximateResultCount = "1"
+
+	return &notification{
+		query: api.ConfigSavedQuery{
+			Description: "leaked passwords",
+			Query:       "type:diff hunter2",
+		},
+		newQuery: `type:diff hunter2 after:"2020-03-04T05:06:07Z"`,
+		results:  results,
+		matches: []*match{{
+			Repository: "github.com/foo/bar",
+			Commit:     "2b5b7c12b5f8ac5a2fa7fdbf8cf0ec2185cd4d76",
+			URL:        "https://sourcegraph.example.com/github.com/foo/bar/-/commit/2b5b7c1",
+			Author:     "Alice",
+			Date:       "2020-03-04 05:06:07 +0000 UTC",
+			Subject:    "Add password",
+			Lines:      []string{`+password = "hunter2"`},
+		}},
+	}
+}
@@ -3,6 +3,7 @@ package main
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	"github.com/inconshreveable/log15"
 
@@ -10,27 +11,74 @@ import (
 	"github.com/sourcegraph/sourcegraph/internal/slack"
 )
 
-func (n *notifier) slackNotify(ctx context.Context) {
+// slackNotifier posts notifications to the Slack webhook of the saved search.
+type slackNotifier struct{}
+
+func (slackNotifier) notifyResults(ctx context.Context, n *notification) error {
 	plural := ""
 	if n.results.Data.Search.Results.ApproximateResultCount != "1" {
 		plural = "s"
 	}
 
-	text := fmt.Sprintf(`*%s* new result%s found for saved search <%s|"%s">`,
+	var text strings.Builder
+	fmt.Fprintf(&text, `*%s* new result%s found for saved search <%s|"%s">`,
 		n.results.Data.Search.Results.ApproximateResultCount,
 		plural,
 		searchURL(n.newQuery, utmSourceSlack),
-		n.query.Description,
+		slackEscape(n.query.Description),
 	)
+	for _, m := range n.shownMatches() {
+		text.WriteString("\n")
+		writeSlackMatch(&text, m)
+	}
+
 	for _, recipient := range n.recipients {
-		if err := slackNotify(ctx, recipient, text, n.query.SlackWebhookURL); err != nil {
-			log15.Error("Failed to post Slack notification message.", "recipient", recipient, "text", text, "error", err)
+		if err := slackNotify(ctx, recipient, text.String(), n.query.SlackWebhookURL); err != nil {
+			log15.Error("Failed to post Slack notification message.", "recipient", recipient, "text", text.String(), "error", err)
 		}
 	}
 	// TODO(Dan): find all users in the recipient list and log events for all of them
 	logEvent(0, "SavedSearchSlackNotificationSent", "results")
+	return nil
+}
+
+func (slackNotifier) notifyTest(ctx context.Context, query api.SavedQuerySpecAndConfig, recipients recipients) error {
+	testNotificationAlert := fmt.Sprintf(`It worked! This is a test notification for the Sourcegraph saved search <%s|"%s">.`, searchURL(query.Config.Query, utmSourceSlack), query.Config.Description)
+	for _, recipient := range recipients {
+		if err := slackNotify(ctx, recipient, testNotificationAlert, query.Config.SlackWebhookURL); err != nil {
+			return fmt.Errorf("error sending slack notifications to %s: %s", recipient.spec, err)
+		}
+	}
+	return nil
 }
 
+// writeSlackMatch writes a link to the commit of m, followed by the matched
+// lines in a code block.
+func writeSlackMatch(w *strings.Builder, m *match) {
+	commit := m.Repository + "@" + m.AbbreviatedCommit()
+	if m.URL != "" {
+		fmt.Fprintf(w, "• <%s|%s> %s", m.URL, slackEscape(commit), slackEscape(m.Subject))
+	} else {
+		fmt.Fprintf(w, "• %s %s", slackEscape(commit), slackEscape(m.Subject))
+	}
+	if len(m.Lines) > 0 {
+		w.WriteString("\n```")
+		for _, line := range m.Lines {
+			w.WriteString(slackEscape(line))
+			w.WriteString("\n")
+		}
+		w.WriteString("```")
+	}
+}
+
+// slackEscape escapes the characters that Slack treats as control
+// characters in message text.
+func slackEscape(s string) string {
+	return slackEscaper.Replace(s)
+}
+
+var slackEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
 func slackNotifySubscribed(ctx context.Context, recipient *recipient, query api.SavedQuerySpecAndConfig) error {
 	text := fmt.Sprintf(`Slack notifications enabled for the saved search <%s|"%s">. Notifications will be sent here when new results are available.`,
 		searchURL(query.Config.Query, utmSourceSlack),
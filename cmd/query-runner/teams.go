@@ -0,0 +1,128 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/sourcegraph/sourcegraph/internal/api"
+)
+
+// teamsNotifier posts new results of saved searches to the Microsoft Teams
+// channel of the saved search's Teams incoming webhook.
+type teamsNotifier struct{}
+
+// teamsMessageCard is a Microsoft Teams message in the (legacy) actionable
+// message card format accepted by incoming webhooks. See
+// https://docs.microsoft.com/en-us/outlook/actionable-messages/message-card-reference.
+type teamsMessageCard struct {
+	Type            string                `json:"@type"`
+	Context         string                `json:"@context"`
+	Summary         string                `json:"summary"`
+	ThemeColor      string                `json:"themeColor,omitempty"`
+	Title           string                `json:"title"`
+	Text            string                `json:"text,omitempty"`
+	Sections        []*teamsSection       `json:"sections,omitempty"`
+	PotentialAction []*teamsOpenURIAction `json:"potentialAction,omitempty"`
+}
+
+type teamsSection struct {
+	ActivityTitle    string `json:"activityTitle"`
+	ActivitySubtitle string `json:"activitySubtitle,omitempty"`
+	Text             string `json:"text,omitempty"`
+}
+
+type teamsOpenURIAction struct {
+	Type    string         `json:"@type"`
+	Name    string         `json:"name"`
+	Targets []*teamsTarget `json:"targets"`
+}
+
+type teamsTarget struct {
+	OS  string `json:"os"`
+	URI string `json:"uri"`
+}
+
+func newTeamsMessageCard(title, text, url string) *teamsMessageCard {
+	return &teamsMessageCard{
+		Type:       "MessageCard",
+		Context:    "https://schema.org/extensions",
+		Summary:    title,
+		ThemeColor: "0078d7",
+		Title:      title,
+		Text:       text,
+		PotentialAction: []*teamsOpenURIAction{{
+			Type:    "OpenUri",
+			Name:    "View on Sourcegraph",
+			Targets: []*teamsTarget{{OS: "default", URI: url}},
+		}},
+	}
+}
+
+func (teamsNotifier) notifyResults(ctx context.Context, n *notification) error {
+	if !isSet(n.query.TeamsWebhookURL) {
+		return nil
+	}
+
+	count := n.results.Data.Search.Results.ApproximateResultCount
+	plural := ""
+	if count != "1" {
+		plural = "s"
+	}
+	card := newTeamsMessageCard(
+		fmt.Sprintf(`%s new result%s for saved search "%s"`, count, plural, n.query.Description),
+		"",
+		searchURL(n.newQuery, utmSourceTeams),
+	)
+	for _, m := range n.shownMatches() {
+		card.Sections = append(card.Sections, teamsMatchSection(m))
+	}
+
+	if err := postJSON(ctx, *n.query.TeamsWebhookURL, card); err != nil {
+		return errors.Wrap(err, "sending Microsoft Teams notification")
+	}
+	logEvent(0, "SavedSearchTeamsNotificationSent", "results")
+	return nil
+}
+
+func (teamsNotifier) notifyTest(ctx context.Context, query api.SavedQuerySpecAndConfig, _ recipients) error {
+	if !isSet(query.Config.TeamsWebhookURL) {
+		return nil
+	}
+
+	card := newTeamsMessageCard(
+		fmt.Sprintf(`Test notification for saved search "%s"`, query.Config.Description),
+		"It worked! New results of this Sourcegraph saved search will be posted here.",
+		searchURL(query.Config.Query, utmSourceTeams),
+	)
+	return errors.Wrap(postJSON(ctx, *query.Config.TeamsWebhookURL, card), "sending Microsoft Teams test notification")
+}
+
+// teamsMatchSection returns the message card section that shows m. Section
+// texts are Markdown.
+func teamsMatchSection(m *match) *teamsSection {
+	title := fmt.Sprintf("%s@%s", m.Repository, m.AbbreviatedCommit())
+	if m.URL != "" {
+		title = fmt.Sprintf("[%s](%s)", title, m.URL)
+	}
+
+	var text strings.Builder
+	if len(m.Lines) > 0 {
+		// Teams renders Markdown code blocks, which keep the matched lines
+		// from being interpreted as Markdown.
+		text.WriteString("```\n")
+		for _, line := range m.Lines {
+			text.WriteString(strings.ReplaceAll(line, "```", "` ` `"))
+			text.WriteString("\n")
+		}
+		text.WriteString("```")
+	}
+
+	return &teamsSection{
+		ActivityTitle:    title,
+		ActivitySubtitle: fmt.Sprintf("%s: %s", m.Author, m.Subject),
+		Text:             text.String(),
+	}
+}
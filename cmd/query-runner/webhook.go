@@ -0,0 +1,64 @@
+package main
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+
+	"github.com/sourcegraph/sourcegraph/internal/api"
+)
+
+// webhookNotifier POSTs new results of saved searches as JSON to the
+// saved search's webhook URL, for consumption by other tools.
+type webhookNotifier struct{}
+
+// webhookPayload is the JSON body of a webhook notification.
+type webhookPayload struct {
+	// Event is "results" for notifications of new results, and "test" for test
+	// notifications.
+	Event string `json:"event"`
+
+	Description string `json:"description"` // the saved search's description
+	Query       string `json:"query"`       // the saved search's query
+
+	// URL is the URL of the search for the new results on Sourcegraph.
+	URL string `json:"url"`
+
+	ApproximateResultCount string   `json:"approximateResultCount,omitempty"`
+	Matches                []*match `json:"matches"`
+}
+
+func (webhookNotifier) notifyResults(ctx context.Context, n *notification) error {
+	if !isSet(n.query.WebhookURL) {
+		return nil
+	}
+
+	err := postJSON(ctx, *n.query.WebhookURL, &webhookPayload{
+		Event:                  "results",
+		Description:            n.query.Description,
+		Query:                  n.query.Query,
+		URL:                    searchURL(n.newQuery, utmSourceWebhook),
+		ApproximateResultCount: n.results.Data.Search.Results.ApproximateResultCount,
+		Matches:                n.matches,
+	})
+	if err != nil {
+		return errors.Wrap(err, "sending webhook notification")
+	}
+	logEvent(0, "SavedSearchWebhookNotificationSent", "results")
+	return nil
+}
+
+func (webhookNotifier) notifyTest(ctx context.Context, query api.SavedQuerySpecAndConfig, _ recipients) error {
+	if !isSet(query.Config.WebhookURL) {
+		return nil
+	}
+
+	err := postJSON(ctx, *query.Config.WebhookURL, &webhookPayload{
+		Event:       "test",
+		Description: query.Config.Description,
+		Query:       query.Config.Query,
+		URL:         searchURL(query.Config.Query, utmSourceWebhook),
+		Matches:     []*match{},
+	})
+	return errors.Wrap(err, "sending webhook test notification")
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/inconshreveable/log15"
 	"github.com/pkg/errors"
 	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/campaigns"
 	"github.com/sourcegraph/sourcegraph/internal/conf/reposource"
 	"github.com/sourcegraph/sourcegraph/internal/extsvc"
 	"github.com/sourcegraph/sourcegraph/internal/extsvc/bitbucketserver"
@@ -159,6 +160,41 @@ func (s BitbucketServerSource) CloseChangeset(ctx context.Context, c *Changeset)
 	return nil
 }
 
+// bitbucketServerMergeStrategies maps merge methods to the IDs of the
+// equivalent Bitbucket Server merge strategies.
+var bitbucketServerMergeStrategies = map[campaigns.MergeMethod]string{
+	campaigns.MergeMethodMerge:  "no-ff",
+	campaigns.MergeMethodSquash: "squash",
+	campaigns.MergeMethodRebase: "rebase-no-ff",
+}
+
+// MergeChangeset merges the pull request of the given *Changeset with the
+// given method and updates the Metadata column in the *campaigns.Changeset to
+// the merged pull request.
+func (s BitbucketServerSource) MergeChangeset(ctx context.Context, c *Changeset, method campaigns.MergeMethod) error {
+	pr, ok := c.Changeset.Metadata.(*bitbucketserver.PullRequest)
+	if !ok {
+		return errors.New("Changeset is not a Bitbucket Server pull request")
+	}
+
+	strategyID, ok := bitbucketServerMergeStrategies[method]
+	if !ok {
+		return errors.Errorf("unsupported merge method %q", method)
+	}
+
+	if err := s.rateLimiter.Wait(ctx); err != nil {
+		return errors.Wrap(err, "waiting for rate limiter")
+	}
+	err := s.client.MergePullRequest(ctx, pr, strategyID)
+	if err != nil {
+		return err
+	}
+
+	c.Changeset.Metadata = pr
+
+	return nil
+}
+
 // LoadChangesets loads the latest state of the given Changesets from the codehost.
 func (s BitbucketServerSource) LoadChangesets(ctx context.Context, cs ...*Changeset) error {
 	var notFound []*Changeset
@@ -11,12 +11,15 @@ import (
 
 	"github.com/inconshreveable/log15"
 	"github.com/pkg/errors"
+	"github.com/sourcegraph/sourcegraph/internal/campaigns"
 	"github.com/sourcegraph/sourcegraph/internal/conf/reposource"
 	"github.com/sourcegraph/sourcegraph/internal/extsvc"
 	"github.com/sourcegraph/sourcegraph/internal/extsvc/gitlab"
 	"github.com/sourcegraph/sourcegraph/internal/httpcli"
 	"github.com/sourcegraph/sourcegraph/internal/jsonc"
+	"github.com/sourcegraph/sourcegraph/internal/vcs/git"
 	"github.com/sourcegraph/sourcegraph/schema"
+	"golang.org/x/time/rate"
 )
 
 // A GitLabSource yields repositories from a single GitLab connection configured
@@ -28,18 +31,24 @@ type GitLabSource struct {
 	baseURL             *url.URL // URL with path /api/v4 (no trailing slash)
 	nameTransformations reposource.NameTransformations
 	client              *gitlab.Client
+
+	// rateLimiter should be used to limit requests made to the external service
+	rateLimiter *rate.Limiter
 }
 
 // NewGitLabSource returns a new GitLabSource from the given external service.
-func NewGitLabSource(svc *ExternalService, cf *httpcli.Factory) (*GitLabSource, error) {
+func NewGitLabSource(svc *ExternalService, cf *httpcli.Factory, rl *rate.Limiter) (*GitLabSource, error) {
 	var c schema.GitLabConnection
 	if err := jsonc.Unmarshal(svc.Config, &c); err != nil {
 		return nil, fmt.Errorf("external service id=%d config error: %s", svc.ID, err)
 	}
-	return newGitLabSource(svc, &c, cf)
+	if rl == nil {
+		rl = rate.NewLimiter(rate.Inf, 0)
+	}
+	return newGitLabSource(svc, &c, cf, rl)
 }
 
-func newGitLabSource(svc *ExternalService, c *schema.GitLabConnection, cf *httpcli.Factory) (*GitLabSource, error) {
+func newGitLabSource(svc *ExternalService, c *schema.GitLabConnection, cf *httpcli.Factory, rl *rate.Limiter) (*GitLabSource, error) {
 	baseURL, err := url.Parse(c.Url)
 	if err != nil {
 		return nil, err
@@ -83,6 +92,7 @@ func newGitLabSource(svc *ExternalService, c *schema.GitLabConnection, cf *httpc
 		baseURL:             baseURL,
 		nameTransformations: nts,
 		client:              gitlab.NewClientProvider(baseURL, cli).GetPATClient(c.Token, ""),
+		rateLimiter:         rl,
 	}, nil
 }
 
@@ -111,6 +121,166 @@ func (s GitLabSource) ExternalServices() ExternalServices {
 	return ExternalServices{s.svc}
 }
 
+var _ ChangesetSource = GitLabSource{}
+
+// CreateChangeset creates a GitLab merge request. If it already exists,
+// *Changeset will be populated and the return value will be true.
+func (s GitLabSource) CreateChangeset(ctx context.Context, c *Changeset) (bool, error) {
+	var exists bool
+	project := c.Repo.Metadata.(*gitlab.Project)
+	source := git.AbbreviateRef(c.HeadRef)
+	target := git.AbbreviateRef(c.BaseRef)
+
+	if err := s.rateLimiter.Wait(ctx); err != nil {
+		return false, errors.Wrap(err, "waiting for rate limiter")
+	}
+
+	mr, err := s.client.CreateMergeRequest(ctx, project, gitlab.CreateMergeRequestOpts{
+		SourceBranch: source,
+		TargetBranch: target,
+		Title:        c.Title,
+		Description:  c.Body,
+	})
+	if err != nil {
+		if err != gitlab.ErrMergeRequestAlreadyExists {
+			return exists, err
+		}
+
+		if err := s.rateLimiter.Wait(ctx); err != nil {
+			return false, errors.Wrap(err, "waiting for rate limiter")
+		}
+		mr, err = s.client.GetOpenMergeRequestByRefs(ctx, project, source, target)
+		if err != nil {
+			return exists, errors.Wrap(err, "fetching existing MR")
+		}
+		exists = true
+	}
+
+	if err := s.setMergeRequest(ctx, c, project, mr); err != nil {
+		return false, err
+	}
+
+	return exists, nil
+}
+
+// CloseChangeset closes the GitLab merge request of the given *Changeset and
+// updates its metadata to the closed merge request.
+func (s GitLabSource) CloseChangeset(ctx context.Context, c *Changeset) error {
+	mr, ok := c.Changeset.Metadata.(*gitlab.MergeRequest)
+	if !ok {
+		return errors.New("Changeset is not a GitLab merge request")
+	}
+	project := c.Repo.Metadata.(*gitlab.Project)
+
+	if err := s.rateLimiter.Wait(ctx); err != nil {
+		return errors.Wrap(err, "waiting for rate limiter")
+	}
+	updated, err := s.client.UpdateMergeRequest(ctx, project, mr, gitlab.UpdateMergeRequestOpts{
+		StateEvent: "close",
+	})
+	if err != nil {
+		return err
+	}
+
+	return s.setMergeRequest(ctx, c, project, updated)
+}
+
+// MergeChangeset is not supported for GitLab merge requests yet.
+func (s GitLabSource) MergeChangeset(ctx context.Context, c *Changeset, method campaigns.MergeMethod) error {
+	return errors.New("merging GitLab merge requests is not supported")
+}
+
+// LoadChangesets loads the latest state of the given Changesets from GitLab.
+func (s GitLabSource) LoadChangesets(ctx context.Context, cs ...*Changeset) error {
+	var notFound []*Changeset
+
+	for _, c := range cs {
+		project := c.Repo.Metadata.(*gitlab.Project)
+		iid, err := strconv.Atoi(c.ExternalID)
+		if err != nil {
+			return errors.Wrap(err, "parsing changeset external id")
+		}
+
+		if err := s.rateLimiter.Wait(ctx); err != nil {
+			return errors.Wrap(err, "waiting for rate limiter")
+		}
+		mr, err := s.client.GetMergeRequest(ctx, project, iid)
+		if err != nil {
+			if gitlab.IsNotFound(err) {
+				notFound = append(notFound, c)
+				continue
+			}
+			return err
+		}
+
+		if err := s.setMergeRequest(ctx, c, project, mr); err != nil {
+			return err
+		}
+	}
+
+	if len(notFound) > 0 {
+		return ChangesetsNotFoundError{Changesets: notFound}
+	}
+
+	return nil
+}
+
+// UpdateChangeset updates the GitLab merge request of the given *Changeset.
+func (s GitLabSource) UpdateChangeset(ctx context.Context, c *Changeset) error {
+	mr, ok := c.Changeset.Metadata.(*gitlab.MergeRequest)
+	if !ok {
+		return errors.New("Changeset is not a GitLab merge request")
+	}
+	project := c.Repo.Metadata.(*gitlab.Project)
+
+	if err := s.rateLimiter.Wait(ctx); err != nil {
+		return errors.Wrap(err, "waiting for rate limiter")
+	}
+	updated, err := s.client.UpdateMergeRequest(ctx, project, mr, gitlab.UpdateMergeRequestOpts{
+		Title:        c.Title,
+		Description:  c.Body,
+		TargetBranch: git.AbbreviateRef(c.BaseRef),
+	})
+	if err != nil {
+		return err
+	}
+
+	return s.setMergeRequest(ctx, c, project, updated)
+}
+
+// setMergeRequest loads the notes and pipelines of the given merge request
+// and sets it as the metadata of the *Changeset.
+func (s GitLabSource) setMergeRequest(ctx context.Context, c *Changeset, project *gitlab.Project, mr *gitlab.MergeRequest) error {
+	if err := s.loadMergeRequestData(ctx, project, mr); err != nil {
+		return err
+	}
+	if err := c.SetMetadata(mr); err != nil {
+		return errors.Wrap(err, "setting changeset metadata")
+	}
+	return nil
+}
+
+func (s GitLabSource) loadMergeRequestData(ctx context.Context, project *gitlab.Project, mr *gitlab.MergeRequest) error {
+	// We make 2 API calls, so wait until the rate limiter allows them.
+	if err := s.rateLimiter.WaitN(ctx, 2); err != nil {
+		return errors.Wrap(err, "waiting for rate limiter")
+	}
+
+	notes, err := s.client.GetMergeRequestNotes(ctx, project, mr.IID)
+	if err != nil {
+		return errors.Wrap(err, "loading mr notes")
+	}
+	mr.Notes = notes
+
+	pipelines, err := s.client.GetMergeRequestPipelines(ctx, project, mr.IID)
+	if err != nil {
+		return errors.Wrap(err, "loading mr pipelines")
+	}
+	mr.Pipelines = pipelines
+
+	return nil
+}
+
 func (s GitLabSource) makeRepo(proj *gitlab.Project) *Repo {
 	urn := s.svc.URN()
 	return &Repo{
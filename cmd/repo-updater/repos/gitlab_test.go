@@ -12,6 +12,7 @@ import (
 	"github.com/google/go-cmp/cmp"
 	"github.com/inconshreveable/log15"
 	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/campaigns"
 	"github.com/sourcegraph/sourcegraph/internal/extsvc/gitlab"
 	"github.com/sourcegraph/sourcegraph/internal/rcache"
 	"github.com/sourcegraph/sourcegraph/internal/testutil"
@@ -140,7 +141,7 @@ func TestGitLabSource_GetRepo(t *testing.T) {
 				}),
 			}
 
-			gitlabSrc, err := NewGitLabSource(svc, cf)
+			gitlabSrc, err := NewGitLabSource(svc, cf, nil)
 			if err != nil {
 				t.Fatal(err)
 			}
@@ -198,7 +199,7 @@ func TestGitLabSource_makeRepo(t *testing.T) {
 			lg := log15.New()
 			lg.SetHandler(log15.DiscardHandler())
 
-			s, err := newGitLabSource(&svc, test.schmea, nil)
+			s, err := newGitLabSource(&svc, test.schmea, nil, nil)
 			if err != nil {
 				t.Fatal(err)
 			}
@@ -212,3 +213,151 @@ func TestGitLabSource_makeRepo(t *testing.T) {
 		})
 	}
 }
+
+func TestGitLabSource_ChangesetSource(t *testing.T) {
+	ctx := context.Background()
+	project := &gitlab.Project{ProjectCommon: gitlab.ProjectCommon{ID: 1}}
+	mr := &gitlab.MergeRequest{
+		IID:          2,
+		Title:        "title",
+		State:        gitlab.MergeRequestStateOpened,
+		SourceBranch: "feature",
+		TargetBranch: "master",
+	}
+	notes := []*gitlab.Note{{ID: 3, System: true, Body: "approved this merge request"}}
+	pipelines := []*gitlab.Pipeline{{ID: 4, Status: gitlab.PipelineStatusSuccess}}
+
+	gitlab.MockGetMergeRequestNotes = func(c *gitlab.Client, ctx context.Context, p *gitlab.Project, iid int) ([]*gitlab.Note, error) {
+		return notes, nil
+	}
+	gitlab.MockGetMergeRequestPipelines = func(c *gitlab.Client, ctx context.Context, p *gitlab.Project, iid int) ([]*gitlab.Pipeline, error) {
+		return pipelines, nil
+	}
+	defer func() {
+		gitlab.MockCreateMergeRequest = nil
+		gitlab.MockGetOpenMergeRequestByRefs = nil
+		gitlab.MockGetMergeRequest = nil
+		gitlab.MockUpdateMergeRequest = nil
+		gitlab.MockGetMergeRequestNotes = nil
+		gitlab.MockGetMergeRequestPipelines = nil
+	}()
+
+	svc := &ExternalService{
+		Kind:   "GITLAB",
+		Config: marshalJSON(t, &schema.GitLabConnection{Url: "https://gitlab.com", Token: "secret"}),
+	}
+	src, err := NewGitLabSource(svc, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	newChangeset := func() *Changeset {
+		return &Changeset{
+			Title:     "title",
+			Body:      "body",
+			HeadRef:   "refs/heads/feature",
+			BaseRef:   "refs/heads/master",
+			Repo:      &Repo{Metadata: project},
+			Changeset: &campaigns.Changeset{ExternalID: "2"},
+		}
+	}
+	assertMetadata := func(t *testing.T, c *Changeset, want *gitlab.MergeRequest) {
+		t.Helper()
+		have, ok := c.Changeset.Metadata.(*gitlab.MergeRequest)
+		if !ok {
+			t.Fatalf("got metadata %T, want *gitlab.MergeRequest", c.Changeset.Metadata)
+		}
+		if have != want || !reflect.DeepEqual(have.Notes, notes) || !reflect.DeepEqual(have.Pipelines, pipelines) {
+			t.Errorf("got metadata %+v, want %+v with notes and pipelines", have, want)
+		}
+		if c.Changeset.ExternalServiceType != gitlab.ServiceType || c.Changeset.ExternalBranch != "feature" {
+			t.Errorf("unexpected changeset %+v", c.Changeset)
+		}
+	}
+
+	t.Run("CreateChangeset", func(t *testing.T) {
+		gitlab.MockCreateMergeRequest = func(c *gitlab.Client, ctx context.Context, p *gitlab.Project, opts gitlab.CreateMergeRequestOpts) (*gitlab.MergeRequest, error) {
+			want := gitlab.CreateMergeRequestOpts{SourceBranch: "feature", TargetBranch: "master", Title: "title", Description: "body"}
+			if diff := cmp.Diff(want, opts); diff != "" {
+				t.Error(diff)
+			}
+			return mr, nil
+		}
+
+		c := newChangeset()
+		exists, err := src.CreateChangeset(ctx, c)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if exists {
+			t.Error("got exists, want created")
+		}
+		assertMetadata(t, c, mr)
+	})
+
+	t.Run("CreateChangeset exists", func(t *testing.T) {
+		gitlab.MockCreateMergeRequest = func(*gitlab.Client, context.Context, *gitlab.Project, gitlab.CreateMergeRequestOpts) (*gitlab.MergeRequest, error) {
+			return nil, gitlab.ErrMergeRequestAlreadyExists
+		}
+		gitlab.MockGetOpenMergeRequestByRefs = func(c *gitlab.Client, ctx context.Context, p *gitlab.Project, source, target string) (*gitlab.MergeRequest, error) {
+			if source != "feature" || target != "master" {
+				t.Errorf("got refs %q and %q", source, target)
+			}
+			return mr, nil
+		}
+
+		c := newChangeset()
+		exists, err := src.CreateChangeset(ctx, c)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !exists {
+			t.Error("got created, want exists")
+		}
+		assertMetadata(t, c, mr)
+	})
+
+	t.Run("LoadChangesets", func(t *testing.T) {
+		gitlab.MockGetMergeRequest = func(c *gitlab.Client, ctx context.Context, p *gitlab.Project, iid int) (*gitlab.MergeRequest, error) {
+			if iid == 2 {
+				return mr, nil
+			}
+			return nil, gitlab.ErrNotFound
+		}
+
+		found, missing := newChangeset(), newChangeset()
+		missing.Changeset.ExternalID = "5"
+		err := src.LoadChangesets(ctx, found, missing)
+		if have, want := fmt.Sprint(err), fmt.Sprint(ChangesetsNotFoundError{Changesets: []*Changeset{missing}}); have != want {
+			t.Errorf("got error %q, want %q", have, want)
+		}
+		assertMetadata(t, found, mr)
+	})
+
+	t.Run("CloseChangeset and UpdateChangeset", func(t *testing.T) {
+		var updates []gitlab.UpdateMergeRequestOpts
+		gitlab.MockUpdateMergeRequest = func(c *gitlab.Client, ctx context.Context, p *gitlab.Project, m *gitlab.MergeRequest, opts gitlab.UpdateMergeRequestOpts) (*gitlab.MergeRequest, error) {
+			updates = append(updates, opts)
+			return mr, nil
+		}
+
+		c := newChangeset()
+		if err := c.Changeset.SetMetadata(mr); err != nil {
+			t.Fatal(err)
+		}
+		if err := src.CloseChangeset(ctx, c); err != nil {
+			t.Fatal(err)
+		}
+		if err := src.UpdateChangeset(ctx, c); err != nil {
+			t.Fatal(err)
+		}
+		want := []gitlab.UpdateMergeRequestOpts{
+			{StateEvent: "close"},
+			{Title: "title", Description: "body", TargetBranch: "master"},
+		}
+		if diff := cmp.Diff(want, updates); diff != "" {
+			t.Error(diff)
+		}
+		assertMetadata(t, c, mr)
+	})
+}
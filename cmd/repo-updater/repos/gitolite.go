@@ -107,6 +107,9 @@ func (s GitoliteSource) makeRepo(repo *gitolite.Repo) *Repo {
 		Name:         name,
 		URI:          name,
 		ExternalRepo: gitolite.ExternalRepoSpec(repo, gitolite.ServiceID(s.conn.Host)),
+		// Gitolite has no public repositories, so all of them are private
+		// when repository permissions are enforced.
+		Private: s.conn.Authorization != nil,
 		Sources: map[string]*SourceInfo{
 			urn: {
 				ID:       urn,
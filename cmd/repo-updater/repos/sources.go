@@ -9,6 +9,7 @@ import (
 
 	"github.com/hashicorp/go-multierror"
 	"github.com/pkg/errors"
+	"github.com/sourcegraph/sourcegraph/internal/campaigns"
 	"github.com/sourcegraph/sourcegraph/internal/httpcli"
 	"golang.org/x/time/rate"
 )
@@ -75,7 +76,7 @@ func newSource(svc *ExternalService, cf *httpcli.Factory, rl *rate.Limiter) (Sou
 	case "github":
 		return NewGithubSource(svc, cf, rl)
 	case "gitlab":
-		return NewGitLabSource(svc, cf)
+		return NewGitLabSource(svc, cf, rl)
 	case "bitbucketserver":
 		return NewBitbucketServerSource(svc, cf, rl)
 	case "bitbucketcloud":
@@ -122,6 +123,9 @@ type ChangesetSource interface {
 	CloseChangeset(context.Context, *Changeset) error
 	// UpdateChangeset can update Changesets.
 	UpdateChangeset(context.Context, *Changeset) error
+	// MergeChangeset merges the Changeset on the source with the given
+	// method and updates it.
+	MergeChangeset(context.Context, *Changeset, campaigns.MergeMethod) error
 }
 
 // ChangesetsNotFoundError is returned by LoadChangesets if any of the passed
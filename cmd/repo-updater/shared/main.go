@@ -153,7 +153,7 @@ func Main(enterpriseInit EnterpriseInit) {
 				}
 			case *schema.GitLabConnection:
 				if strings.HasPrefix(c.Url, "https://gitlab.com") && c.Token != "" {
-					server.GitLabDotComSource, err = repos.NewGitLabSource(e, cf)
+					server.GitLabDotComSource, err = repos.NewGitLabSource(e, cf, nil)
 				}
 			}
 
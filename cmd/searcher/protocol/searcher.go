@@ -42,6 +42,11 @@ type Request struct {
 	// The deadline for the search request.
 	// It is parsed with time.Time.UnmarshalText.
 	Deadline string
+
+	// Stream if true will make searcher write matches as soon as they are
+	// found. The response body is a sequence of newline-delimited JSON
+	// encoded StreamEvents instead of a single Response.
+	Stream bool
 }
 
 // GitserverRepo returns the repository information necessary to perform gitserver requests.
@@ -105,6 +110,11 @@ type PatternInfo struct {
 
 	// CombyRule is a rule that constrains matching for structural search. It only applies when IsStructuralPat is true.
 	CombyRule string
+
+	// FilePaths, if non-empty, is the exact list of file paths to search,
+	// e.g. the candidate files the frontend found with indexed search. It
+	// only applies when IsStructuralPat is true.
+	FilePaths []string
 }
 
 func (p *PatternInfo) String() string {
@@ -151,6 +161,9 @@ func (p *PatternInfo) String() string {
 	for _, inc := range p.IncludePatterns {
 		args = append(args, fmt.Sprintf("%s:%q", path, inc))
 	}
+	if len(p.FilePaths) > 0 {
+		args = append(args, fmt.Sprintf("filepaths:%d", len(p.FilePaths)))
+	}
 
 	return fmt.Sprintf("PatternInfo{%s}", strings.Join(args, ","))
 }
@@ -166,6 +179,33 @@ type Response struct {
 	DeadlineHit bool
 }
 
+// StreamEvent is a single value in the response body of a streaming search
+// request (see Request.Stream).
+//
+// Every event but the last only sets Matches. The last event has Done set
+// and describes how the search finished. If searcher fails before sending
+// any event it responds with a non-200 status code, like a non-streaming
+// request would.
+type StreamEvent struct {
+	// Matches are the file matches found since the previous event.
+	Matches []FileMatch `json:",omitempty"`
+
+	// Done is true for the last event in the stream.
+	Done bool `json:",omitempty"`
+
+	// LimitHit is true if the stream may not include all FileMatches
+	// because a match limit was hit. Only set on the last event.
+	LimitHit bool `json:",omitempty"`
+
+	// DeadlineHit is true if the stream may not include all FileMatches
+	// because a deadline was hit. Only set on the last event.
+	DeadlineHit bool `json:",omitempty"`
+
+	// Error is set on the last event if the search failed after matches
+	// were already sent.
+	Error string `json:",omitempty"`
+}
+
 // FileMatch is the struct used by vscode to receive search results
 type FileMatch struct {
 	Path        string
@@ -99,7 +99,22 @@ func (s *Service) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	matches, limitHit, deadlineHit, err := s.search(ctx, &p)
+	var (
+		sw      *streamWriter
+		onMatch func(protocol.FileMatch)
+	)
+	if p.Stream {
+		sw = newStreamWriter(w)
+		onMatch = sw.SendMatch
+	}
+
+	matches, limitHit, deadlineHit, err := s.search(ctx, &p, onMatch)
+	if sw != nil && (err == nil || sw.Started()) {
+		// Once we have started streaming we can no longer set the status
+		// code, so errors are reported in the final event.
+		sw.Done(limitHit, deadlineHit, err)
+		return
+	}
 	if err != nil {
 		code := http.StatusInternalServerError
 		if isBadRequest(err) || ctx.Err() == context.Canceled {
@@ -130,7 +145,9 @@ func (s *Service) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	_ = json.NewEncoder(w).Encode(&resp)
 }
 
-func (s *Service) search(ctx context.Context, p *protocol.Request) (matches []protocol.FileMatch, limitHit, deadlineHit bool, err error) {
+// search searches p. If onMatch is non-nil it is called with every match as
+// soon as it is found, in addition to the match being returned.
+func (s *Service) search(ctx context.Context, p *protocol.Request, onMatch func(protocol.FileMatch)) (matches []protocol.FileMatch, limitHit, deadlineHit bool, err error) {
 	tr := nettrace.New("search", fmt.Sprintf("%s@%s", p.Repo, p.Commit))
 	tr.LazyPrintf("%s", p.Pattern)
 
@@ -151,6 +168,7 @@ func (s *Service) search(ctx context.Context, p *protocol.Request) (matches []pr
 	span.SetTag("patternMatchesContent", p.PatternMatchesContent)
 	span.SetTag("patternMatchesPath", p.PatternMatchesPath)
 	span.SetTag("deadline", p.Deadline)
+	span.SetTag("stream", p.Stream)
 	defer func(start time.Time) {
 		code := "200"
 		// We often have canceled and timed out requests. We do not want to
@@ -228,9 +246,27 @@ func (s *Service) search(ctx context.Context, p *protocol.Request) (matches []pr
 	archiveSize.Observe(float64(bytes))
 
 	if p.IsStructuralPat {
-		matches, limitHit, err = structuralSearch(ctx, zipPath, p.Pattern, p.CombyRule, p.Languages, p.IncludePatterns, p.Repo)
+		filePatterns := p.IncludePatterns
+		if len(p.FilePaths) > 0 {
+			// Only let comby see the candidate files, rather than the whole
+			// archive.
+			var cleanup func()
+			zipPath, cleanup, err = subsetZip(zf, p.FilePaths)
+			if err != nil {
+				return nil, false, false, errors.Wrap(err, "failed to create archive of candidate files")
+			}
+			defer cleanup()
+			filePatterns = p.FilePaths
+		}
+		matches, limitHit, err = structuralSearch(ctx, zipPath, p.Pattern, p.CombyRule, p.Languages, filePatterns, p.Repo)
+		// comby only returns once it has searched the whole archive.
+		if onMatch != nil && err == nil {
+			for _, fm := range matches {
+				onMatch(fm)
+			}
+		}
 	} else {
-		matches, limitHit, err = regexSearch(ctx, rg, zf, p.FileMatchLimit, p.PatternMatchesContent, p.PatternMatchesPath)
+		matches, limitHit, err = regexSearchStream(ctx, rg, zf, p.FileMatchLimit, p.PatternMatchesContent, p.PatternMatchesPath, onMatch)
 	}
 	return matches, limitHit, false, err
 }
@@ -1,8 +1,11 @@
 package search
 
 import (
+	"archive/zip"
 	"context"
 	"fmt"
+	"io/ioutil"
+	"os"
 	"path/filepath"
 	"strings"
 
@@ -11,6 +14,7 @@ import (
 	"github.com/sourcegraph/sourcegraph/cmd/searcher/protocol"
 	"github.com/sourcegraph/sourcegraph/internal/api"
 	"github.com/sourcegraph/sourcegraph/internal/comby"
+	"github.com/sourcegraph/sourcegraph/internal/store"
 )
 
 // The Sourcegraph frontend and interface only allow LineMatches (matches on a
@@ -219,6 +223,50 @@ func structuralSearch(ctx context.Context, zipPath, pattern, rule string, langua
 	return matches, false, err
 }
 
+// subsetZip writes the files of zf whose paths are in paths to a new
+// temporary zip archive, so that structural search only processes those files.
+// Paths that are not in zf are ignored. cleanup removes the archive.
+func subsetZip(zf *store.ZipFile, paths []string) (zipPath string, cleanup func(), err error) {
+	include := make(map[string]bool, len(paths))
+	for _, p := range paths {
+		include[p] = true
+	}
+
+	f, err := ioutil.TempFile("", "structural-search-*.zip")
+	if err != nil {
+		return "", nil, err
+	}
+	cleanup = func() { os.Remove(f.Name()) }
+	defer func() {
+		if err != nil {
+			f.Close()
+			cleanup()
+		}
+	}()
+
+	zw := zip.NewWriter(f)
+	for i := range zf.Files {
+		file := &zf.Files[i]
+		if !include[file.Name] {
+			continue
+		}
+		w, err := zw.CreateHeader(&zip.FileHeader{
+			Name:   file.Name,
+			Method: zip.Store,
+		})
+		if err != nil {
+			return "", nil, err
+		}
+		if _, err := w.Write(zf.DataFor(file)); err != nil {
+			return "", nil, err
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return "", nil, err
+	}
+	return f.Name(), cleanup, f.Close()
+}
+
 var requestTotalStructuralSearch = prometheus.NewCounterVec(prometheus.CounterOpts{
 	Namespace: "searcher",
 	Subsystem: "service",
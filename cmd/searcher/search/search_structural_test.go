@@ -1,8 +1,10 @@
 package search
 
 import (
+	"archive/zip"
 	"context"
 	"encoding/json"
+	"io/ioutil"
 	"os"
 	"reflect"
 	"sort"
@@ -427,3 +429,57 @@ func bar() {
 		}
 	})
 }
+
+func TestSubsetZip(t *testing.T) {
+	zipData, err := testutil.CreateZip(map[string]string{
+		"main.go":     "package main",
+		"lib/lib.go":  "package lib",
+		"README.md":   "# readme",
+		"lib/data.go": "package lib",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	zf, err := testutil.MockZipFile(zipData)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path, cleanup, err := subsetZip(zf, []string{"main.go", "lib/data.go", "missing.go"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cleanup()
+
+	r, err := zip.OpenReader(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	got := map[string]string{}
+	for _, f := range r.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, err := ioutil.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		got[f.Name] = string(b)
+	}
+	want := map[string]string{
+		"main.go":     "package main",
+		"lib/data.go": "package lib",
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatal(diff)
+	}
+
+	cleanup()
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatalf("archive %s was not removed", path)
+	}
+}
@@ -225,6 +225,59 @@ main.go:7:}
 	}
 }
 
+func TestSearch_stream(t *testing.T) {
+	files := map[string]string{
+		"README.md": `# Hello World
+
+Hello world example in go`,
+		"main.go": `package main
+
+import "fmt"
+
+func main() {
+	fmt.Println("Hello world")
+}
+`,
+	}
+
+	store, cleanup, err := newStore(files)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cleanup()
+	ts := httptest.NewServer(&search.Service{Store: store})
+	defer ts.Close()
+
+	req := protocol.Request{
+		Repo:         "foo",
+		URL:          "u",
+		Commit:       "deadbeefdeadbeefdeadbeefdeadbeefdeadbeef",
+		PatternInfo:  protocol.PatternInfo{Pattern: "world", PatternMatchesContent: true},
+		FetchTimeout: "2000ms",
+		Stream:       true,
+	}
+	m, err := doSearch(ts.URL, &req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sort.Sort(sortByPath(m))
+	want := `README.md:1:# Hello World
+README.md:3:Hello world example in go
+main.go:6:	fmt.Println("Hello world")
+`
+	if got := toString(m); got != want {
+		t.Fatalf("unexpected response:\n%s", got)
+	}
+
+	// Failures before the first match must still be reported with a
+	// status code.
+	req.PatternInfo = protocol.PatternInfo{Pattern: "(", IsRegExp: true, PatternMatchesContent: true}
+	_, err = doSearch(ts.URL, &req)
+	if err == nil || !strings.HasPrefix(err.Error(), "non-200 response: code=400 ") {
+		t.Fatalf("expected HTTP 400 response, got %v", err)
+	}
+}
+
 func TestSearch_badrequest(t *testing.T) {
 	cases := []protocol.Request{
 		// Bad regexp
@@ -378,6 +431,9 @@ func doSearch(u string, p *protocol.Request) ([]protocol.FileMatch, error) {
 	if p.PatternMatchesPath {
 		form.Set("PatternMatchesPath", "true")
 	}
+	if p.Stream {
+		form.Set("Stream", "true")
+	}
 	resp, err := http.PostForm(u, form)
 	if err != nil {
 		return nil, err
@@ -391,6 +447,24 @@ func doSearch(u string, p *protocol.Request) ([]protocol.FileMatch, error) {
 		return nil, fmt.Errorf("non-200 response: code=%d body=%s", resp.StatusCode, string(body))
 	}
 
+	if p.Stream {
+		var matches []protocol.FileMatch
+		dec := json.NewDecoder(bytes.NewReader(body))
+		for {
+			var ev protocol.StreamEvent
+			if err := dec.Decode(&ev); err != nil {
+				return nil, fmt.Errorf("stream ended without done event: %v", err)
+			}
+			matches = append(matches, ev.Matches...)
+			if ev.Done {
+				if ev.Error != "" {
+					return nil, errors.New(ev.Error)
+				}
+				return matches, nil
+			}
+		}
+	}
+
 	var r protocol.Response
 	err = json.Unmarshal(body, &r)
 	if err != nil {
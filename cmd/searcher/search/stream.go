@@ -0,0 +1,67 @@
+package search
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync"
+
+	"github.com/sourcegraph/sourcegraph/cmd/searcher/protocol"
+)
+
+// streamWriter writes protocol.StreamEvents to a http.ResponseWriter as
+// newline-delimited JSON. The response header is only written once the first
+// event is sent, so that failures which happen before any match was found
+// can still be reported with a non-200 status code.
+type streamWriter struct {
+	mu      sync.Mutex
+	w       http.ResponseWriter
+	enc     *json.Encoder
+	started bool
+}
+
+func newStreamWriter(w http.ResponseWriter) *streamWriter {
+	return &streamWriter{w: w, enc: json.NewEncoder(w)}
+}
+
+// SendMatch sends fm to the client and flushes it.
+func (s *streamWriter) SendMatch(fm protocol.FileMatch) {
+	s.send(protocol.StreamEvent{Matches: []protocol.FileMatch{fm}})
+}
+
+// Done sends the final event of the stream.
+func (s *streamWriter) Done(limitHit, deadlineHit bool, err error) {
+	ev := protocol.StreamEvent{
+		Done:        true,
+		LimitHit:    limitHit,
+		DeadlineHit: deadlineHit,
+	}
+	if err != nil {
+		ev.Error = err.Error()
+	}
+	s.send(ev)
+}
+
+// Started returns true if any event has been written to the client.
+func (s *streamWriter) Started() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.started
+}
+
+func (s *streamWriter) send(ev protocol.StreamEvent) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.started {
+		s.started = true
+		s.w.Header().Set("Content-Type", "application/x-ndjson")
+		s.w.WriteHeader(http.StatusOK)
+	}
+
+	// Like in ServeHTTP, the only reasonable error is the client going away,
+	// which we can't report.
+	_ = s.enc.Encode(&ev)
+	if f, ok := s.w.(http.Flusher); ok {
+		f.Flush()
+	}
+}
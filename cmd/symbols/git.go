@@ -0,0 +1,131 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/sourcegraph/sourcegraph/cmd/symbols/internal/symbols"
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/gitserver"
+)
+
+func fetchTar(ctx context.Context, repo gitserver.Repo, commit api.CommitID) (io.ReadCloser, error) {
+	return gitserver.DefaultClient.Archive(ctx, repo, gitserver.ArchiveOptions{Treeish: string(commit), Format: "tar"})
+}
+
+func fetchTarPaths(ctx context.Context, repo gitserver.Repo, commit api.CommitID, paths []string) (io.ReadCloser, error) {
+	return gitserver.DefaultClient.Archive(ctx, repo, gitserver.ArchiveOptions{Treeish: string(commit), Format: "tar", Paths: paths})
+}
+
+// ancestors returns up to n first-parent ancestors of commit, nearest first.
+func ancestors(ctx context.Context, repo gitserver.Repo, commit api.CommitID, n int) ([]api.CommitID, error) {
+	if err := checkSpecArgSafety(string(commit)); err != nil {
+		return nil, err
+	}
+
+	cmd := gitserver.DefaultClient.Command("git", "rev-list", "--first-parent", "--max-count="+strconv.Itoa(n+1), string(commit))
+	cmd.Repo = repo
+	out, err := cmd.CombinedOutput(ctx)
+	if err != nil {
+		return nil, errors.Wrapf(err, "git rev-list: %s", bytes.TrimSpace(out))
+	}
+
+	var commits []api.CommitID
+	for _, line := range strings.Fields(string(out)) {
+		if api.CommitID(line) != commit {
+			commits = append(commits, api.CommitID(line))
+		}
+	}
+	return commits, nil
+}
+
+// gitDiff returns the files that changed between base and head.
+func gitDiff(ctx context.Context, repo gitserver.Repo, base, head api.CommitID) (*symbols.Changes, error) {
+	for _, c := range []api.CommitID{base, head} {
+		if err := checkSpecArgSafety(string(c)); err != nil {
+			return nil, err
+		}
+	}
+
+	cmd := gitserver.DefaultClient.Command("git", "diff", "--name-status", "--no-renames", "-z", string(base), string(head), "--")
+	cmd.Repo = repo
+	out, err := cmd.Output(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "git diff")
+	}
+
+	// The output is a sequence of NUL-terminated status and path pairs.
+	fields := strings.Split(strings.TrimSuffix(string(out), "\x00"), "\x00")
+	if len(fields) == 1 && fields[0] == "" {
+		fields = nil
+	}
+	if len(fields)%2 != 0 {
+		return nil, fmt.Errorf("unexpected git diff output %q", out)
+	}
+
+	var changes symbols.Changes
+	for i := 0; i < len(fields); i += 2 {
+		status, path := fields[i], fields[i+1]
+		switch status[0] {
+		case 'A':
+			changes.Added = append(changes.Added, path)
+		case 'D':
+			changes.Deleted = append(changes.Deleted, path)
+		default:
+			// Modifications, type changes and the like.
+			changes.Modified = append(changes.Modified, path)
+		}
+	}
+	return &changes, nil
+}
+
+// resolveRevs resolves revs to commits. If revs is empty, it resolves the
+// heads of all branches.
+func resolveRevs(ctx context.Context, repo gitserver.Repo, revs []string) (map[string]api.CommitID, error) {
+	if len(revs) == 0 {
+		cmd := gitserver.DefaultClient.Command("git", "for-each-ref", "--format=%(objectname) %(refname:short)", "refs/heads/")
+		cmd.Repo = repo
+		out, err := cmd.CombinedOutput(ctx)
+		if err != nil {
+			return nil, errors.Wrapf(err, "git for-each-ref: %s", bytes.TrimSpace(out))
+		}
+
+		commits := make(map[string]api.CommitID)
+		for _, line := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+			if parts := strings.SplitN(line, " ", 2); len(parts) == 2 {
+				commits[parts[1]] = api.CommitID(parts[0])
+			}
+		}
+		return commits, nil
+	}
+
+	commits := make(map[string]api.CommitID, len(revs))
+	for _, rev := range revs {
+		if err := checkSpecArgSafety(rev); err != nil {
+			return nil, err
+		}
+
+		cmd := gitserver.DefaultClient.Command("git", "rev-parse", "--verify", rev+"^{commit}")
+		cmd.Repo = repo
+		out, err := cmd.CombinedOutput(ctx)
+		if err != nil {
+			return nil, errors.Wrapf(err, "resolve revision %q: %s", rev, bytes.TrimSpace(out))
+		}
+		commits[rev] = api.CommitID(bytes.TrimSpace(out))
+	}
+	return commits, nil
+}
+
+// checkSpecArgSafety returns a non-nil err if spec begins with a "-", which
+// could cause it to be interpreted as a git command line argument.
+func checkSpecArgSafety(spec string) error {
+	if strings.HasPrefix(spec, "-") {
+		return fmt.Errorf("invalid git revision spec %q (begins with '-')", spec)
+	}
+	return nil
+}
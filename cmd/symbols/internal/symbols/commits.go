@@ -0,0 +1,108 @@
+package symbols
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/inconshreveable/log15"
+	"github.com/opentracing/opentracing-go/ext"
+	otlog "github.com/opentracing/opentracing-go/log"
+	"github.com/pkg/errors"
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/gitserver"
+	"github.com/sourcegraph/sourcegraph/internal/symbols/protocol"
+	"github.com/sourcegraph/sourcegraph/internal/trace/ot"
+)
+
+// maxSearchCommits is the maximum number of commits searched by a commits
+// search.
+const maxSearchCommits = 100
+
+func (s *Service) handleSearchCommits(w http.ResponseWriter, r *http.Request) {
+	var args protocol.SearchCommitsArgs
+	if err := json.NewDecoder(r.Body).Decode(&args); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	result, err := s.searchCommits(r.Context(), args)
+	if err != nil {
+		if err == context.Canceled && r.Context().Err() == context.Canceled {
+			return // client went away
+		}
+		log15.Error("Symbol commits search failed", "args", args, "error", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if err := json.NewEncoder(w).Encode(result); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+// searchCommits searches for the symbols matching `args` in each of the
+// commits that `args.Revs` resolve to. Commits are indexed as needed, which is
+// cheap when the database of an ancestor is already cached.
+func (s *Service) searchCommits(ctx context.Context, args protocol.SearchCommitsArgs) (result *protocol.SearchCommitsResult, err error) {
+	ctx, cancel := context.WithTimeout(ctx, 60*time.Second)
+	defer cancel()
+
+	span, ctx := ot.StartSpanFromContext(ctx, "searchCommits")
+	span.SetTag("repo", args.Repo)
+	span.SetTag("revs", args.Revs)
+	span.SetTag("query", args.Query)
+	defer func() {
+		if err != nil {
+			ext.Error.Set(span, true)
+			span.LogFields(otlog.Error(err))
+		}
+		span.Finish()
+	}()
+
+	if s.ResolveRevs == nil {
+		return nil, errors.New("searching commits is not supported")
+	}
+
+	commitIDs, err := s.ResolveRevs(ctx, gitserver.Repo{Name: args.Repo}, args.Revs)
+	if err != nil {
+		return nil, err
+	}
+
+	revsByCommit := make(map[api.CommitID][]string, len(commitIDs))
+	for rev, commitID := range commitIDs {
+		revsByCommit[commitID] = append(revsByCommit[commitID], rev)
+	}
+	if len(revsByCommit) > maxSearchCommits {
+		return nil, errors.New("too many commits to search, specify fewer revisions")
+	}
+
+	result = &protocol.SearchCommitsResult{}
+	for commitID, revs := range revsByCommit {
+		searchArgs := args.SearchArgs
+		searchArgs.CommitID = commitID
+		symbols, err := s.searchCommit(ctx, searchArgs)
+		if err != nil {
+			return nil, err
+		}
+		if len(symbols) == 0 {
+			continue
+		}
+
+		sort.Strings(revs)
+		result.Commits = append(result.Commits, protocol.CommitSymbols{
+			CommitID: commitID,
+			Revs:     revs,
+			Symbols:  symbols,
+		})
+	}
+
+	sort.Slice(result.Commits, func(i, j int) bool {
+		return result.Commits[i].Revs[0] < result.Commits[j].Revs[0]
+	})
+	span.SetTag("commits", len(result.Commits))
+	return result, nil
+}
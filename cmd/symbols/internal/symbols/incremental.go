@@ -0,0 +1,195 @@
+package symbols
+
+import (
+	"context"
+	"io"
+	"os"
+
+	"github.com/inconshreveable/log15"
+	"github.com/jmoiron/sqlx"
+	"github.com/keegancsmith/sqlf"
+	"github.com/opentracing/opentracing-go/ext"
+	otlog "github.com/opentracing/opentracing-go/log"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/diskcache"
+	"github.com/sourcegraph/sourcegraph/internal/gitserver"
+	"github.com/sourcegraph/sourcegraph/internal/symbols/protocol"
+	"github.com/sourcegraph/sourcegraph/internal/trace/ot"
+)
+
+const (
+	// maxIncrementalAncestors is the number of ancestors of a commit searched
+	// for a database to index the commit incrementally from.
+	maxIncrementalAncestors = 50
+
+	// maxIncrementalChanges is the maximum number of changed files for which
+	// a commit is indexed incrementally. Larger changes are indexed from
+	// scratch, which is cheaper than fetching many paths individually.
+	maxIncrementalChanges = 1000
+
+	// deleteBatchSize is the number of paths deleted per statement, which
+	// stays below sqlite3's limit of 999 bound parameters.
+	deleteBatchSize = 500
+)
+
+// Changes are the paths of the files that differ between two commits. Renames
+// are reported as a deletion and an addition.
+type Changes struct {
+	Added    []string
+	Modified []string
+	Deleted  []string
+}
+
+// writeSymbolsToNewDB writes the symbols of repo@commit to the blank database
+// file `dbFile`. If the database of a recent ancestor of the commit is in the
+// cache, only the files changed since that ancestor are parsed. Otherwise,
+// all files are parsed.
+func (s *Service) writeSymbolsToNewDB(ctx context.Context, dbFile string, repoName api.RepoName, commitID api.CommitID) error {
+	if s.FetchTarPaths != nil && s.Ancestors != nil && s.GitDiff != nil {
+		ok, err := s.writeSymbolsIncrementally(ctx, dbFile, repoName, commitID)
+		if err == nil && ok {
+			return nil
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err != nil {
+			log15.Warn("Unable to index symbols incrementally, indexing all files", "repo", repoName, "commit", commitID, "error", err)
+			// The database may have been partially written.
+			if err := os.Truncate(dbFile, 0); err != nil {
+				return err
+			}
+		}
+	}
+
+	return s.writeAllSymbolsToNewDB(ctx, dbFile, repoName, commitID)
+}
+
+// writeSymbolsIncrementally copies the database of the nearest ancestor of
+// repo@commit that is in the cache to `dbFile`, and updates it with the
+// symbols of the files that changed since. It returns false if there is no
+// such ancestor or too many files changed.
+func (s *Service) writeSymbolsIncrementally(ctx context.Context, dbFile string, repoName api.RepoName, commitID api.CommitID) (ok bool, err error) {
+	span, ctx := ot.StartSpanFromContext(ctx, "writeSymbolsIncrementally")
+	span.SetTag("repo", string(repoName))
+	span.SetTag("commit", string(commitID))
+	defer func() {
+		if err != nil {
+			ext.Error.Set(span, true)
+			span.LogFields(otlog.Error(err))
+		}
+		span.Finish()
+	}()
+
+	repo := gitserver.Repo{Name: repoName}
+	ancestors, err := s.Ancestors(ctx, repo, commitID, maxIncrementalAncestors)
+	if err != nil {
+		return false, err
+	}
+
+	var base *diskcache.File
+	var baseCommitID api.CommitID
+	for _, ancestor := range ancestors {
+		if f, err := s.cache.OpenIfExists(dbKey(repoName, ancestor)); err == nil {
+			base, baseCommitID = f, ancestor
+			break
+		}
+	}
+	if base == nil {
+		return false, nil
+	}
+	defer base.Close()
+	span.SetTag("base", string(baseCommitID))
+
+	changes, err := s.GitDiff(ctx, repo, baseCommitID, commitID)
+	if err != nil {
+		return false, err
+	}
+	changed := append(append([]string{}, changes.Added...), changes.Modified...)
+	if len(changed)+len(changes.Deleted) > maxIncrementalChanges {
+		return false, nil
+	}
+	span.SetTag("changed", len(changed))
+	span.SetTag("deleted", len(changes.Deleted))
+
+	if err := copyFile(dbFile, base.File); err != nil {
+		return false, err
+	}
+
+	db, err := sqlx.Open("sqlite3_with_pcre", dbFile)
+	if err != nil {
+		return false, err
+	}
+	defer db.Close()
+
+	tx, err := db.Beginx()
+	if err != nil {
+		return false, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	stale := append(append([]string{}, changed...), changes.Deleted...)
+	for len(stale) > 0 {
+		n := deleteBatchSize
+		if n > len(stale) {
+			n = len(stale)
+		}
+		paths := make([]*sqlf.Query, n)
+		for i, p := range stale[:n] {
+			paths[i] = sqlf.Sprintf("%s", p)
+		}
+		q := sqlf.Sprintf("DELETE FROM symbols WHERE path IN (%s)", sqlf.Join(paths, ","))
+		if _, err := tx.Exec(q.Query(sqlf.PostgresBindVar), q.Args()...); err != nil {
+			return false, err
+		}
+		stale = stale[n:]
+	}
+
+	if len(changed) > 0 {
+		insertStatement, err := prepareInsertSymbol(tx)
+		if err != nil {
+			return false, err
+		}
+
+		err = s.parseUncached(ctx, repoName, commitID, changed, func(symbol protocol.Symbol) error {
+			symbolInDBValue := symbolToSymbolInDB(symbol)
+			_, err := insertStatement.Exec(&symbolInDBValue)
+			return err
+		})
+		if err != nil {
+			return false, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, err
+	}
+
+	indexed.WithLabelValues("incremental").Inc()
+	return true, nil
+}
+
+// copyFile overwrites the file at path with the contents of src.
+func copyFile(path string, src io.Reader) error {
+	dst, err := os.OpenFile(path, os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(dst, src); err != nil {
+		dst.Close()
+		return err
+	}
+	return dst.Close()
+}
+
+var indexed = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "symbols",
+	Subsystem: "store",
+	Name:      "indexed",
+	Help:      "The total number of commits indexed, by type (full or incremental).",
+}, []string{"type"})
+
+func init() {
+	prometheus.MustRegister(indexed)
+}
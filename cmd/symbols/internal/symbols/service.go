@@ -22,6 +22,23 @@ type Service struct {
 	// determine if the error is a bad request (eg invalid repo).
 	FetchTar func(context.Context, gitserver.Repo, api.CommitID) (io.ReadCloser, error)
 
+	// FetchTarPaths is like FetchTar, but the archive only contains the given paths. It
+	// is required for incremental indexing.
+	FetchTarPaths func(context.Context, gitserver.Repo, api.CommitID, []string) (io.ReadCloser, error)
+
+	// Ancestors returns up to n first-parent ancestors of the specified commit, nearest
+	// first, excluding the commit itself. It is required for incremental indexing.
+	Ancestors func(ctx context.Context, repo gitserver.Repo, commit api.CommitID, n int) ([]api.CommitID, error)
+
+	// GitDiff returns the files that changed between the base and head commits. It is
+	// required for incremental indexing.
+	GitDiff func(ctx context.Context, repo gitserver.Repo, base, head api.CommitID) (*Changes, error)
+
+	// ResolveRevs resolves the given revisions of a repository to commits. If no
+	// revisions are given, it resolves all branches. It is required to search for the
+	// commits that define a symbol.
+	ResolveRevs func(ctx context.Context, repo gitserver.Repo, revs []string) (map[string]api.CommitID, error)
+
 	// MaxConcurrentFetchTar is the maximum number of concurrent calls allowed
 	// to FetchTar. It defaults to 15.
 	MaxConcurrentFetchTar int
@@ -81,6 +98,7 @@ func (s *Service) Handler() http.Handler {
 	mux := http.NewServeMux()
 
 	mux.HandleFunc("/search", s.handleSearch)
+	mux.HandleFunc("/search-commits", s.handleSearchCommits)
 	mux.HandleFunc("/healthz", s.handleHealthCheck)
 
 	return mux
@@ -4,11 +4,15 @@ import (
 	"archive/tar"
 	"bytes"
 	"context"
+	"fmt"
 	"io"
 	"io/ioutil"
 	"net/http/httptest"
 	"os"
 	"reflect"
+	"sort"
+	"strings"
+	"sync"
 	"testing"
 
 	"github.com/sourcegraph/sourcegraph/cmd/symbols/internal/pkg/ctags"
@@ -20,8 +24,15 @@ import (
 	"github.com/sourcegraph/sourcegraph/internal/symbols/protocol"
 )
 
-func init() {
-	sqliteutil.SetLocalLibpath()
+var registerSqlite3WithPcreOnce sync.Once
+
+// registerSqlite3WithPcre registers the SQLite driver for the tests that need it. It is not done in
+// init, so the other tests still run without libsqlite3-pcre.
+func registerSqlite3WithPcre() {
+	registerSqlite3WithPcreOnce.Do(func() {
+		sqliteutil.SetLocalLibpath()
+		sqliteutil.MustRegisterSqlite3WithPcre()
+	})
 }
 
 func TestIsLiteralEquality(t *testing.T) {
@@ -57,7 +68,7 @@ func TestIsLiteralEquality(t *testing.T) {
 }
 
 func TestService(t *testing.T) {
-	sqliteutil.MustRegisterSqlite3WithPcre()
+	registerSqlite3WithPcre()
 
 	tmpDir, err := ioutil.TempDir("", "")
 	if err != nil {
@@ -143,6 +154,144 @@ func TestService(t *testing.T) {
 	}
 }
 
+func TestService_incremental(t *testing.T) {
+	registerSqlite3WithPcre()
+
+	tmpDir, err := ioutil.TempDir("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { os.RemoveAll(tmpDir) }()
+
+	// Each commit is a child of the previous one.
+	commits := []api.CommitID{"c1", "c2", "c3"}
+	files := map[api.CommitID]map[string]string{
+		"c1": {"a.go": "foo bar", "b.go": "baz", "c.go": "qux"},
+		"c2": {"a.go": "foo bar2", "c.go": "qux", "d.go": "quux"},
+		"c3": {"a.go": "foo bar2", "c.go": "qux", "d.go": "quux"},
+	}
+
+	var fetched []string
+	service := Service{
+		FetchTar: func(ctx context.Context, repo gitserver.Repo, commit api.CommitID) (io.ReadCloser, error) {
+			fetched = append(fetched, "*")
+			return createTar(files[commit])
+		},
+		FetchTarPaths: func(ctx context.Context, repo gitserver.Repo, commit api.CommitID, paths []string) (io.ReadCloser, error) {
+			subset := map[string]string{}
+			for _, p := range paths {
+				fetched = append(fetched, p)
+				subset[p] = files[commit][p]
+			}
+			return createTar(subset)
+		},
+		Ancestors: func(ctx context.Context, repo gitserver.Repo, commit api.CommitID, n int) ([]api.CommitID, error) {
+			var ancestors []api.CommitID
+			for i := len(commits) - 1; i >= 0; i-- {
+				if commits[i] < commit {
+					ancestors = append(ancestors, commits[i])
+				}
+			}
+			return ancestors, nil
+		},
+		GitDiff: func(ctx context.Context, repo gitserver.Repo, base, head api.CommitID) (*Changes, error) {
+			var changes Changes
+			for path, content := range files[head] {
+				if old, ok := files[base][path]; !ok {
+					changes.Added = append(changes.Added, path)
+				} else if old != content {
+					changes.Modified = append(changes.Modified, path)
+				}
+			}
+			for path := range files[base] {
+				if _, ok := files[head][path]; !ok {
+					changes.Deleted = append(changes.Deleted, path)
+				}
+			}
+			sort.Strings(changes.Added)
+			sort.Strings(changes.Modified)
+			return &changes, nil
+		},
+		ResolveRevs: func(ctx context.Context, repo gitserver.Repo, revs []string) (map[string]api.CommitID, error) {
+			return map[string]api.CommitID{"main": "c3", "feature": "c2", "old": "c1"}, nil
+		},
+		NewParser: func() (ctags.Parser, error) {
+			return wordParser{}, nil
+		},
+		Path: tmpDir,
+	}
+
+	if err := service.Start(); err != nil {
+		t.Fatal(err)
+	}
+	server := httptest.NewServer(service.Handler())
+	defer server.Close()
+	client := symbolsclient.Client{URL: server.URL}
+	ctx := context.Background()
+
+	names := func(commit api.CommitID) []string {
+		t.Helper()
+		result, err := client.Search(ctx, search.SymbolsParameters{Repo: "r", CommitID: commit, First: 10})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var names []string
+		for _, s := range result.Symbols {
+			names = append(names, s.Path+":"+s.Name)
+		}
+		sort.Strings(names)
+		return names
+	}
+
+	if got, want := names("c1"), []string{"a.go:bar", "a.go:foo", "b.go:baz", "c.go:qux"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("c1: got %v, want %v", got, want)
+	}
+	if want := []string{"*"}; !reflect.DeepEqual(fetched, want) {
+		t.Errorf("c1: fetched %v, want %v", fetched, want)
+	}
+
+	// c2 is indexed from the symbols of c1, only parsing the changed files.
+	fetched = nil
+	if got, want := names("c2"), []string{"a.go:bar2", "a.go:foo", "c.go:qux", "d.go:quux"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("c2: got %v, want %v", got, want)
+	}
+	if want := []string{"d.go", "a.go"}; !reflect.DeepEqual(fetched, want) {
+		t.Errorf("c2: fetched %v, want %v", fetched, want)
+	}
+
+	// Searching commits finds the revisions that define a symbol.
+	result, err := client.SearchCommits(ctx, protocol.SearchCommitsArgs{
+		SearchArgs: protocol.SearchArgs{Repo: "r", Query: "^ba", First: 10},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, c := range result.Commits {
+		for _, s := range c.Symbols {
+			got = append(got, fmt.Sprintf("%s%v:%s", c.CommitID, c.Revs, s.Name))
+		}
+	}
+	sort.Strings(got)
+	want := []string{"c1[old]:bar", "c1[old]:baz", "c2[feature]:bar2", "c3[main]:bar2"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("search commits: got %v, want %v", got, want)
+	}
+}
+
+// wordParser returns a symbol for each word in a file.
+type wordParser struct{}
+
+func (wordParser) Parse(name string, content []byte) ([]ctags.Entry, error) {
+	var entries []ctags.Entry
+	for _, word := range strings.Fields(string(content)) {
+		entries = append(entries, ctags.Entry{Name: word, Path: name})
+	}
+	return entries, nil
+}
+
+func (wordParser) Close() {}
+
 func createTar(files map[string]string) (io.ReadCloser, error) {
 	buf := new(bytes.Buffer)
 	w := tar.NewWriter(buf)
@@ -10,6 +10,7 @@ import (
 	"github.com/sourcegraph/sourcegraph/enterprise/cmd/frontend/auth/githuboauth"
 	"github.com/sourcegraph/sourcegraph/enterprise/cmd/frontend/auth/gitlaboauth"
 	"github.com/sourcegraph/sourcegraph/enterprise/cmd/frontend/auth/httpheader"
+	"github.com/sourcegraph/sourcegraph/enterprise/cmd/frontend/auth/ldap"
 	"github.com/sourcegraph/sourcegraph/enterprise/cmd/frontend/auth/openidconnect"
 	"github.com/sourcegraph/sourcegraph/enterprise/cmd/frontend/auth/saml"
 	"github.com/sourcegraph/sourcegraph/internal/conf"
@@ -24,6 +25,8 @@ func init() {
 		githuboauth.Middleware,
 		gitlaboauth.Middleware,
 	)
+	// Register LDAP authentication for the username-password sign-in form
+	auth.RegisterPasswordAuthenticators(ldap.PasswordAuthenticator)
 	// Register app-level sign-out handler
 	app.RegisterSSOSignOutHandler(ssoSignOutHandler)
 }
@@ -0,0 +1,39 @@
+package ldap
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pkg/errors"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/auth"
+)
+
+// PasswordAuthenticator authenticates users who sign in with the username-password sign-in form
+// against the LDAP auth providers in site config.
+var PasswordAuthenticator auth.PasswordAuthenticator = passwordAuthenticator{}
+
+type passwordAuthenticator struct{}
+
+// Enabled implements auth.PasswordAuthenticator.
+func (passwordAuthenticator) Enabled() bool {
+	return len(getProviders()) > 0
+}
+
+// Authenticate implements auth.PasswordAuthenticator. The LDAP auth providers are tried in order,
+// and the first one that recognizes the credentials is used.
+func (passwordAuthenticator) Authenticate(ctx context.Context, login, password string) (userID int32, safeErrMsg string, err error) {
+	for _, p := range getProviders() {
+		u, err := p.authenticate(login, password)
+		if err == errInvalidCredentials {
+			continue
+		}
+		if err != nil {
+			return 0, "Unexpected error authenticating with the LDAP server. Ask a site admin to check the logs.", errors.Wrapf(err, "LDAP auth provider %s", p.config.Url)
+		}
+		if !p.allowed(u) {
+			return 0, "You are not a member of an LDAP group that is allowed to sign in to Sourcegraph.", fmt.Errorf("LDAP user %q is not a member of any of the allowGroups", u.DN)
+		}
+		return getOrCreateUser(ctx, p, u)
+	}
+	return 0, "", nil
+}
@@ -0,0 +1,61 @@
+package ldap
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/sourcegraph/sourcegraph/internal/conf"
+	"github.com/sourcegraph/sourcegraph/schema"
+)
+
+// getProviders returns the LDAP auth providers in site config.
+func getProviders() []*provider {
+	var ps []*provider
+	for _, p := range conf.Get().AuthProviders {
+		if p.Ldap != nil {
+			ps = append(ps, &provider{config: *p.Ldap})
+		}
+	}
+	return ps
+}
+
+func init() {
+	conf.ContributeValidator(validateConfig)
+}
+
+func validateConfig(c conf.Unified) (problems conf.Problems) {
+	seen := map[string]int{}
+	for i, p := range c.AuthProviders {
+		if p.Ldap == nil {
+			continue
+		}
+		id := providerConfigID(p.Ldap)
+		if j, ok := seen[id]; ok {
+			problems = append(problems, conf.NewSiteProblem(fmt.Sprintf("LDAP auth provider at index %d is duplicate of index %d, ignoring", i, j)))
+			continue
+		}
+		seen[id] = i
+
+		if p.Ldap.UserSearchFilter != "" && !strings.Contains(p.Ldap.UserSearchFilter, usernamePlaceholder) {
+			problems = append(problems, conf.NewSiteProblem(fmt.Sprintf("LDAP auth provider at index %d: userSearchFilter must contain the %s placeholder", i, usernamePlaceholder)))
+		}
+		if p.Ldap.BindDN != "" && p.Ldap.BindPassword == "" {
+			problems = append(problems, conf.NewSiteProblem(fmt.Sprintf("LDAP auth provider at index %d: bindPassword must be set if bindDN is set", i)))
+		}
+	}
+	return problems
+}
+
+// providerConfigID produces a semi-stable identifier for an LDAP auth provider config object. Its
+// value is never persisted, and it must be deterministic.
+func providerConfigID(pc *schema.LDAPAuthProvider) string {
+	data, err := json.Marshal(pc)
+	if err != nil {
+		panic(err)
+	}
+	b := sha256.Sum256(data)
+	return base64.RawURLEncoding.EncodeToString(b[:16])
+}
@@ -0,0 +1,62 @@
+package ldap
+
+import (
+	"testing"
+
+	"github.com/sourcegraph/sourcegraph/internal/conf"
+	"github.com/sourcegraph/sourcegraph/schema"
+)
+
+func TestValidateCustom(t *testing.T) {
+	tests := map[string]struct {
+		input        conf.Unified
+		wantProblems conf.Problems
+	}{
+		"duplicates": {
+			input: conf.Unified{SiteConfiguration: schema.SiteConfiguration{
+				AuthProviders: []schema.AuthProviders{
+					{Ldap: &schema.LDAPAuthProvider{Type: "ldap", Url: "ldap://x", UserSearchBase: "dc=x"}},
+					{Ldap: &schema.LDAPAuthProvider{Type: "ldap", Url: "ldap://x", UserSearchBase: "dc=x"}},
+				},
+			}},
+			wantProblems: conf.NewSiteProblems("LDAP auth provider at index 1 is duplicate of index 0"),
+		},
+		"userSearchFilter without placeholder": {
+			input: conf.Unified{SiteConfiguration: schema.SiteConfiguration{
+				AuthProviders: []schema.AuthProviders{
+					{Ldap: &schema.LDAPAuthProvider{Type: "ldap", Url: "ldap://x", UserSearchBase: "dc=x", UserSearchFilter: "(uid=alice)"}},
+				},
+			}},
+			wantProblems: conf.NewSiteProblems("userSearchFilter must contain the {username} placeholder"),
+		},
+		"bindDN without bindPassword": {
+			input: conf.Unified{SiteConfiguration: schema.SiteConfiguration{
+				AuthProviders: []schema.AuthProviders{
+					{Ldap: &schema.LDAPAuthProvider{Type: "ldap", Url: "ldap://x", UserSearchBase: "dc=x", BindDN: "cn=sourcegraph,dc=x"}},
+				},
+			}},
+			wantProblems: conf.NewSiteProblems("bindPassword must be set if bindDN is set"),
+		},
+		"valid": {
+			input: conf.Unified{SiteConfiguration: schema.SiteConfiguration{
+				AuthProviders: []schema.AuthProviders{
+					{Ldap: &schema.LDAPAuthProvider{Type: "ldap", Url: "ldap://x", UserSearchBase: "dc=x", UserSearchFilter: "(&(objectClass=person)(sAMAccountName={username}))", BindDN: "cn=sourcegraph,dc=x", BindPassword: "p"}},
+				},
+			}},
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			conf.TestValidator(t, test.input, validateConfig, test.wantProblems)
+		})
+	}
+}
+
+func TestProviderConfigID(t *testing.T) {
+	p := schema.LDAPAuthProvider{Url: "ldap://x"}
+	id1 := providerConfigID(&p)
+	id2 := providerConfigID(&p)
+	if id1 != id2 {
+		t.Errorf("id1 (%q) != id2 (%q)", id1, id2)
+	}
+}
@@ -0,0 +1,24 @@
+package ldap
+
+import (
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/auth/providers"
+	"github.com/sourcegraph/sourcegraph/internal/conf"
+)
+
+// Watch for configuration changes related to the LDAP auth provider.
+func init() {
+	go func() {
+		conf.Watch(func() {
+			ps := getProviders()
+			if len(ps) == 0 {
+				providers.Update("ldap", nil)
+				return
+			}
+			list := make([]providers.Provider, 0, len(ps))
+			for _, p := range ps {
+				list = append(list, p)
+			}
+			providers.Update("ldap", list)
+		})
+	}()
+}
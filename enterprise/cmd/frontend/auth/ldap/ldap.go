@@ -0,0 +1,218 @@
+package ldap
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/go-ldap/ldap/v3"
+	"github.com/pkg/errors"
+	"github.com/sourcegraph/sourcegraph/internal/conf"
+)
+
+// usernamePlaceholder is replaced with the (escaped) login entered on the sign-in form in the
+// userSearchFilter.
+const usernamePlaceholder = "{username}"
+
+const (
+	defaultUserSearchFilter         = "(uid=" + usernamePlaceholder + ")"
+	defaultUsernameAttribute        = "uid"
+	defaultEmailAttribute           = "mail"
+	defaultDisplayNameAttribute     = "cn"
+	defaultGroupMembershipAttribute = "memberOf"
+)
+
+// timeout bounds how long we wait for the LDAP server when connecting and for each request.
+var timeout = 10 * time.Second
+
+// errInvalidCredentials is returned by authenticate if the LDAP server does not recognize the
+// login or the password is incorrect.
+var errInvalidCredentials = errors.New("invalid LDAP credentials")
+
+// directoryUser is the LDAP user entry that a user authenticated as.
+type directoryUser struct {
+	DN          string   `json:"dn"`
+	Username    string   `json:"username"`
+	Email       string   `json:"email,omitempty"`
+	DisplayName string   `json:"displayName,omitempty"`
+	Groups      []string `json:"groups,omitempty"`
+}
+
+// authenticate looks up the user entry for login and binds as it with password. It returns
+// errInvalidCredentials if there is no such entry or the password is incorrect.
+func (p *provider) authenticate(login, password string) (*directoryUser, error) {
+	// 🚨 SECURITY: Most LDAP servers treat a bind with an empty password as an unauthenticated
+	// bind, which succeeds for any DN. Never let that through as a successful sign-in.
+	if login == "" || password == "" {
+		return nil, errInvalidCredentials
+	}
+
+	conn, err := p.dial()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	if p.config.BindDN != "" {
+		if err := conn.Bind(p.config.BindDN, p.config.BindPassword); err != nil {
+			return nil, errors.Wrap(err, "bind as service account")
+		}
+	}
+
+	var (
+		usernameAttr    = valueOrDefault(p.config.UsernameAttribute, defaultUsernameAttribute)
+		emailAttr       = valueOrDefault(p.config.EmailAttribute, defaultEmailAttribute)
+		displayNameAttr = valueOrDefault(p.config.DisplayNameAttribute, defaultDisplayNameAttribute)
+		groupAttr       = valueOrDefault(p.config.GroupMembershipAttribute, defaultGroupMembershipAttribute)
+	)
+	filter := strings.Replace(valueOrDefault(p.config.UserSearchFilter, defaultUserSearchFilter), usernamePlaceholder, ldap.EscapeFilter(login), -1)
+	res, err := conn.Search(ldap.NewSearchRequest(
+		p.config.UserSearchBase,
+		ldap.ScopeWholeSubtree, ldap.NeverDerefAliases,
+		0, int(timeout/time.Second), false,
+		filter,
+		[]string{usernameAttr, emailAttr, displayNameAttr, groupAttr},
+		nil,
+	))
+	if err != nil {
+		return nil, errors.Wrap(err, "search for user entry")
+	}
+	switch len(res.Entries) {
+	case 0:
+		return nil, errInvalidCredentials
+	case 1:
+	default:
+		return nil, fmt.Errorf("%d user entries match the filter %s, expected at most 1", len(res.Entries), filter)
+	}
+	entry := res.Entries[0]
+
+	// 🚨 SECURITY: This is the actual password check.
+	if err := conn.Bind(entry.DN, password); err != nil {
+		if ldap.IsErrorWithCode(err, ldap.LDAPResultInvalidCredentials) {
+			return nil, errInvalidCredentials
+		}
+		return nil, errors.Wrap(err, "bind as user")
+	}
+
+	return &directoryUser{
+		DN:          entry.DN,
+		Username:    firstValue(attributeValues(entry, usernameAttr)),
+		Email:       firstValue(attributeValues(entry, emailAttr)),
+		DisplayName: firstValue(attributeValues(entry, displayNameAttr)),
+		Groups:      attributeValues(entry, groupAttr),
+	}, nil
+}
+
+// dial connects to the LDAP server, upgrading the connection with StartTLS if configured.
+func (p *provider) dial() (*ldap.Conn, error) {
+	u, err := url.Parse(p.config.Url)
+	if err != nil {
+		return nil, errors.Wrap(err, "parse LDAP server URL")
+	}
+	tlsConfig := tlsConfig(u.Hostname())
+
+	conn, err := ldap.DialURL(p.config.Url, ldap.DialWithDialer(&net.Dialer{Timeout: timeout}), ldap.DialWithTLSConfig(tlsConfig))
+	if err != nil {
+		return nil, errors.Wrap(err, "connect to LDAP server")
+	}
+	conn.SetTimeout(timeout)
+
+	if p.config.StartTLS && u.Scheme == "ldap" {
+		if err := conn.StartTLS(tlsConfig); err != nil {
+			conn.Close()
+			return nil, errors.Wrap(err, "StartTLS")
+		}
+	}
+	return conn, nil
+}
+
+// tlsConfig returns the TLS config for connecting to the LDAP server, honoring the site's
+// tls.external settings.
+func tlsConfig(serverName string) *tls.Config {
+	c := &tls.Config{ServerName: serverName}
+	ef := conf.Get().ExperimentalFeatures
+	if ef == nil || ef.TlsExternal == nil {
+		return c
+	}
+	c.InsecureSkipVerify = ef.TlsExternal.InsecureSkipVerify
+	if len(ef.TlsExternal.Certificates) > 0 {
+		pool, err := x509.SystemCertPool() // safe to mutate, a clone is returned
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		for _, cert := range ef.TlsExternal.Certificates {
+			pool.AppendCertsFromPEM([]byte(cert))
+		}
+		c.RootCAs = pool
+	}
+	return c
+}
+
+// allowed reports whether the user is a member of one of the allowGroups, if any are configured.
+func (p *provider) allowed(u *directoryUser) bool {
+	if len(p.config.AllowGroups) == 0 {
+		return true
+	}
+	for _, allowed := range p.config.AllowGroups {
+		for _, group := range u.Groups {
+			if equalDN(allowed, group) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// equalDN reports whether a and b are the same DN, ignoring case and insignificant whitespace.
+func equalDN(a, b string) bool {
+	da, errA := ldap.ParseDN(a)
+	db, errB := ldap.ParseDN(b)
+	if errA != nil || errB != nil {
+		return strings.EqualFold(a, b)
+	}
+	if len(da.RDNs) != len(db.RDNs) {
+		return false
+	}
+	for i := range da.RDNs {
+		if len(da.RDNs[i].Attributes) != len(db.RDNs[i].Attributes) {
+			return false
+		}
+		for j, attr := range da.RDNs[i].Attributes {
+			other := db.RDNs[i].Attributes[j]
+			if !strings.EqualFold(attr.Type, other.Type) || !strings.EqualFold(attr.Value, other.Value) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// attributeValues returns the values of the named attribute of the entry. Unlike
+// (*ldap.Entry).GetAttributeValues, attribute names are matched case-insensitively, as they are in
+// LDAP.
+func attributeValues(e *ldap.Entry, name string) []string {
+	for _, a := range e.Attributes {
+		if strings.EqualFold(a.Name, name) {
+			return a.Values
+		}
+	}
+	return nil
+}
+
+func firstValue(values []string) string {
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
+
+func valueOrDefault(v, defaultValue string) string {
+	if v == "" {
+		return defaultValue
+	}
+	return v
+}
@@ -0,0 +1,490 @@
+package ldap
+
+import (
+	"context"
+	"net"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	ber "github.com/go-asn1-ber/asn1-ber"
+	"github.com/go-ldap/ldap/v3"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/auth"
+	"github.com/sourcegraph/sourcegraph/internal/conf"
+	"github.com/sourcegraph/sourcegraph/schema"
+)
+
+func TestProvider_authenticate(t *testing.T) {
+	defer conf.Mock(nil)
+	conf.Mock(&conf.Unified{})
+
+	srv := newTestServer(t, testDirectory...)
+	defer srv.Close()
+
+	tests := map[string]struct {
+		config   schema.LDAPAuthProvider
+		login    string
+		password string
+		wantUser *directoryUser
+		wantErr  error
+	}{
+		"success": {
+			login:    "alice",
+			password: "alice-password",
+			wantUser: &directoryUser{
+				DN:          "uid=alice,ou=people,dc=example,dc=com",
+				Username:    "alice",
+				Email:       "alice@example.com",
+				DisplayName: "Alice Smith",
+				Groups:      []string{"cn=engineering,ou=groups,dc=example,dc=com"},
+			},
+		},
+		"wrong password": {
+			login:    "alice",
+			password: "bob-password",
+			wantErr:  errInvalidCredentials,
+		},
+		"empty password": {
+			login:    "alice",
+			password: "",
+			wantErr:  errInvalidCredentials,
+		},
+		"unknown user": {
+			login:    "mallory",
+			password: "alice-password",
+			wantErr:  errInvalidCredentials,
+		},
+		"filter injection": {
+			login:    "*",
+			password: "alice-password",
+			wantErr:  errInvalidCredentials,
+		},
+		"custom filter and attributes": {
+			config: schema.LDAPAuthProvider{
+				UserSearchFilter:     "(&(objectClass=person)(sAMAccountName={username}))",
+				UsernameAttribute:    "sAMAccountName",
+				EmailAttribute:       "userPrincipalName",
+				DisplayNameAttribute: "displayName",
+			},
+			login:    "bob.jones",
+			password: "bob-password",
+			wantUser: &directoryUser{
+				DN:          "uid=bob,ou=people,dc=example,dc=com",
+				Username:    "bob.jones",
+				Email:       "bob@corp.example.com",
+				DisplayName: "Bob",
+			},
+		},
+		"service account": {
+			config: schema.LDAPAuthProvider{
+				BindDN:       "cn=sourcegraph,ou=services,dc=example,dc=com",
+				BindPassword: "service-password",
+			},
+			login:    "alice",
+			password: "alice-password",
+			wantUser: &directoryUser{
+				DN:          "uid=alice,ou=people,dc=example,dc=com",
+				Username:    "alice",
+				Email:       "alice@example.com",
+				DisplayName: "Alice Smith",
+				Groups:      []string{"cn=engineering,ou=groups,dc=example,dc=com"},
+			},
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			test.config.Type = providerType
+			test.config.Url = srv.URL()
+			test.config.UserSearchBase = "ou=people,dc=example,dc=com"
+			p := &provider{config: test.config}
+
+			user, err := p.authenticate(test.login, test.password)
+			if err != test.wantErr {
+				t.Fatalf("got err %v, want %v", err, test.wantErr)
+			}
+			if !reflect.DeepEqual(user, test.wantUser) {
+				t.Errorf("got user %+v, want %+v", user, test.wantUser)
+			}
+		})
+	}
+
+	t.Run("wrong service account password", func(t *testing.T) {
+		p := &provider{config: schema.LDAPAuthProvider{
+			Url:            srv.URL(),
+			UserSearchBase: "ou=people,dc=example,dc=com",
+			BindDN:         "cn=sourcegraph,ou=services,dc=example,dc=com",
+			BindPassword:   "wrong",
+		}}
+		// A misconfigured service account is not the user's fault, so it must not be reported as
+		// invalid credentials.
+		if _, err := p.authenticate("alice", "alice-password"); err == nil || err == errInvalidCredentials {
+			t.Errorf("got err %v, want service account bind error", err)
+		}
+	})
+}
+
+func TestProvider_allowed(t *testing.T) {
+	user := &directoryUser{Groups: []string{"cn=engineering,ou=groups,dc=example,dc=com"}}
+	tests := map[string]struct {
+		allowGroups []string
+		want        bool
+	}{
+		"no allow list":             {allowGroups: nil, want: true},
+		"member":                    {allowGroups: []string{"cn=sales,ou=groups,dc=example,dc=com", "cn=engineering,ou=groups,dc=example,dc=com"}, want: true},
+		"member, DN not normalized": {allowGroups: []string{"CN=Engineering, OU=Groups, DC=example, DC=com"}, want: true},
+		"not a member":              {allowGroups: []string{"cn=sales,ou=groups,dc=example,dc=com"}, want: false},
+		"parent DN only":            {allowGroups: []string{"ou=groups,dc=example,dc=com"}, want: false},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			p := &provider{config: schema.LDAPAuthProvider{AllowGroups: test.allowGroups}}
+			if got := p.allowed(user); got != test.want {
+				t.Errorf("got %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestPasswordAuthenticator(t *testing.T) {
+	srv := newTestServer(t, testDirectory...)
+	defer srv.Close()
+
+	mockProviders := func(allowGroups ...string) {
+		conf.Mock(&conf.Unified{SiteConfiguration: schema.SiteConfiguration{
+			AuthProviders: []schema.AuthProviders{{
+				Ldap: &schema.LDAPAuthProvider{
+					Type:           providerType,
+					Url:            srv.URL(),
+					UserSearchBase: "ou=people,dc=example,dc=com",
+					AllowGroups:    allowGroups,
+				},
+			}},
+		}})
+	}
+	defer conf.Mock(nil)
+
+	var gotOp *auth.GetAndSaveUserOp
+	auth.MockGetAndSaveUser = func(ctx context.Context, op auth.GetAndSaveUserOp) (int32, string, error) {
+		gotOp = &op
+		return 123, "", nil
+	}
+	defer func() { auth.MockGetAndSaveUser = nil }()
+
+	t.Run("disabled", func(t *testing.T) {
+		conf.Mock(&conf.Unified{})
+		if PasswordAuthenticator.Enabled() {
+			t.Error("want disabled with no LDAP auth providers")
+		}
+	})
+
+	t.Run("success", func(t *testing.T) {
+		mockProviders()
+		gotOp = nil
+		if !PasswordAuthenticator.Enabled() {
+			t.Fatal("want enabled")
+		}
+		userID, _, err := PasswordAuthenticator.Authenticate(context.Background(), "alice", "alice-password")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if userID != 123 {
+			t.Errorf("got userID %d, want 123", userID)
+		}
+		if gotOp == nil {
+			t.Fatal("GetAndSaveUser was not called")
+		}
+		if want := "alice"; gotOp.UserProps.Username != want {
+			t.Errorf("got username %q, want %q", gotOp.UserProps.Username, want)
+		}
+		if want := "alice@example.com"; gotOp.UserProps.Email != want || !gotOp.UserProps.EmailIsVerified {
+			t.Errorf("got email %q (verified %v), want verified %q", gotOp.UserProps.Email, gotOp.UserProps.EmailIsVerified, want)
+		}
+		if want := "Alice Smith"; gotOp.UserProps.DisplayName != want {
+			t.Errorf("got display name %q, want %q", gotOp.UserProps.DisplayName, want)
+		}
+		if gotOp.ExternalAccount.ServiceType != providerType || gotOp.ExternalAccount.ServiceID != srv.URL() || gotOp.ExternalAccount.AccountID != "uid=alice,ou=people,dc=example,dc=com" {
+			t.Errorf("got external account %+v", gotOp.ExternalAccount)
+		}
+		if !gotOp.CreateIfNotExist {
+			t.Error("want CreateIfNotExist")
+		}
+	})
+
+	t.Run("wrong password", func(t *testing.T) {
+		mockProviders()
+		gotOp = nil
+		userID, _, err := PasswordAuthenticator.Authenticate(context.Background(), "alice", "wrong")
+		if userID != 0 || err != nil {
+			t.Errorf("got userID %d, err %v, want 0 and nil", userID, err)
+		}
+		if gotOp != nil {
+			t.Error("GetAndSaveUser was called")
+		}
+	})
+
+	t.Run("not in allowed group", func(t *testing.T) {
+		mockProviders("cn=sales,ou=groups,dc=example,dc=com")
+		gotOp = nil
+		userID, safeErrMsg, err := PasswordAuthenticator.Authenticate(context.Background(), "alice", "alice-password")
+		if userID != 0 || err == nil || safeErrMsg == "" {
+			t.Errorf("got userID %d, safeErrMsg %q, err %v, want an error", userID, safeErrMsg, err)
+		}
+		if gotOp != nil {
+			t.Error("GetAndSaveUser was called")
+		}
+	})
+
+	t.Run("no email address", func(t *testing.T) {
+		mockProviders()
+		gotOp = nil
+		userID, safeErrMsg, err := PasswordAuthenticator.Authenticate(context.Background(), "carol", "carol-password")
+		if userID != 0 || err == nil || safeErrMsg == "" {
+			t.Errorf("got userID %d, safeErrMsg %q, err %v, want an error", userID, safeErrMsg, err)
+		}
+		if gotOp != nil {
+			t.Error("GetAndSaveUser was called")
+		}
+	})
+}
+
+var testDirectory = []testEntry{
+	{
+		dn:       "cn=sourcegraph,ou=services,dc=example,dc=com",
+		password: "service-password",
+	},
+	{
+		dn:       "uid=alice,ou=people,dc=example,dc=com",
+		password: "alice-password",
+		attrs: map[string][]string{
+			"objectClass": {"person"},
+			"uid":         {"alice"},
+			"mail":        {"alice@example.com"},
+			"cn":          {"Alice Smith"},
+			"memberOf":    {"cn=engineering,ou=groups,dc=example,dc=com"},
+		},
+	},
+	{
+		dn:       "uid=bob,ou=people,dc=example,dc=com",
+		password: "bob-password",
+		attrs: map[string][]string{
+			"objectClass": {"person"},
+			"uid":         {"bob"},
+			// Attribute names are case-insensitive.
+			"samaccountname":    {"bob.jones"},
+			"USERPRINCIPALNAME": {"bob@corp.example.com"},
+			"displayName":       {"Bob"},
+		},
+	},
+	{
+		dn:       "uid=carol,ou=people,dc=example,dc=com",
+		password: "carol-password",
+		attrs: map[string][]string{
+			"objectClass": {"person"},
+			"uid":         {"carol"},
+		},
+	},
+}
+
+// testEntry is an entry in the directory of a testServer.
+type testEntry struct {
+	dn       string
+	password string
+	attrs    map[string][]string
+}
+
+// testServer is an in-process stand-in for an LDAP server. It supports just enough of the protocol
+// for the LDAP auth provider: simple binds, and subtree searches with and, or, not, equality and
+// presence filters.
+type testServer struct {
+	t        *testing.T
+	entries  []testEntry
+	listener net.Listener
+	wg       sync.WaitGroup
+}
+
+func newTestServer(t *testing.T, entries ...testEntry) *testServer {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &testServer{t: t, entries: entries, listener: l}
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			s.wg.Add(1)
+			go func() {
+				defer s.wg.Done()
+				defer conn.Close()
+				s.serve(conn)
+			}()
+		}
+	}()
+	return s
+}
+
+func (s *testServer) URL() string { return "ldap://" + s.listener.Addr().String() }
+
+func (s *testServer) Close() {
+	s.listener.Close()
+	s.wg.Wait()
+}
+
+func (s *testServer) serve(conn net.Conn) {
+	// Connections start out anonymous. Searches are rejected after a failed bind, until the next
+	// successful bind.
+	bound := true
+
+	for {
+		packet, err := ber.ReadPacket(conn)
+		if err != nil {
+			return
+		}
+		if len(packet.Children) < 2 {
+			s.t.Errorf("test LDAP server: malformed message")
+			return
+		}
+		messageID := packet.Children[0].Value.(int64)
+		op := packet.Children[1]
+
+		switch op.Tag {
+		case ldap.ApplicationBindRequest:
+			dn := op.Children[1].Value.(string)
+			password := op.Children[2].Data.String()
+			code := uint16(ldap.LDAPResultInvalidCredentials)
+			if dn == "" && password == "" {
+				code = ldap.LDAPResultSuccess
+			} else if e := s.entry(dn); e != nil && e.password != "" && e.password == password {
+				code = ldap.LDAPResultSuccess
+			}
+			bound = code == ldap.LDAPResultSuccess
+			s.write(conn, messageID, ldapResult(ldap.ApplicationBindResponse, code))
+
+		case ldap.ApplicationSearchRequest:
+			if !bound {
+				s.write(conn, messageID, ldapResult(ldap.ApplicationSearchResultDone, ldap.LDAPResultInsufficientAccessRights))
+				continue
+			}
+			base := op.Children[0].Value.(string)
+			filter := op.Children[6]
+			var attrs []string
+			for _, a := range op.Children[7].Children {
+				attrs = append(attrs, a.Value.(string))
+			}
+			for i := range s.entries {
+				e := &s.entries[i]
+				if !strings.HasSuffix(strings.ToLower(e.dn), ","+strings.ToLower(base)) || !e.matches(filter) {
+					continue
+				}
+				s.write(conn, messageID, e.searchResultEntry(attrs))
+			}
+			s.write(conn, messageID, ldapResult(ldap.ApplicationSearchResultDone, ldap.LDAPResultSuccess))
+
+		case ldap.ApplicationUnbindRequest:
+			return
+
+		default:
+			s.t.Errorf("test LDAP server: unsupported operation %d", op.Tag)
+			return
+		}
+	}
+}
+
+func (s *testServer) entry(dn string) *testEntry {
+	for i := range s.entries {
+		if equalDN(s.entries[i].dn, dn) {
+			return &s.entries[i]
+		}
+	}
+	return nil
+}
+
+func (s *testServer) write(conn net.Conn, messageID int64, op *ber.Packet) {
+	packet := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "LDAP Response")
+	packet.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagInteger, messageID, "MessageID"))
+	packet.AppendChild(op)
+	if _, err := conn.Write(packet.Bytes()); err != nil {
+		s.t.Errorf("test LDAP server: %s", err)
+	}
+}
+
+func ldapResult(tag ber.Tag, code uint16) *ber.Packet {
+	op := ber.Encode(ber.ClassApplication, ber.TypeConstructed, tag, nil, "Result")
+	op.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagEnumerated, int64(code), "resultCode"))
+	op.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, "", "matchedDN"))
+	op.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, "", "diagnosticMessage"))
+	return op
+}
+
+func (e *testEntry) values(name string) []string {
+	for k, v := range e.attrs {
+		if strings.EqualFold(k, name) {
+			return v
+		}
+	}
+	return nil
+}
+
+func (e *testEntry) matches(filter *ber.Packet) bool {
+	switch filter.Tag {
+	case ldap.FilterAnd:
+		for _, f := range filter.Children {
+			if !e.matches(f) {
+				return false
+			}
+		}
+		return true
+	case ldap.FilterOr:
+		for _, f := range filter.Children {
+			if e.matches(f) {
+				return true
+			}
+		}
+		return false
+	case ldap.FilterNot:
+		return !e.matches(filter.Children[0])
+	case ldap.FilterEqualityMatch:
+		value := filter.Children[1].Data.String()
+		for _, v := range e.values(filter.Children[0].Data.String()) {
+			if strings.EqualFold(v, value) {
+				return true
+			}
+		}
+		return false
+	case ldap.FilterPresent:
+		return len(e.values(filter.Data.String())) > 0
+	default:
+		return false
+	}
+}
+
+func (e *testEntry) searchResultEntry(attrs []string) *ber.Packet {
+	op := ber.Encode(ber.ClassApplication, ber.TypeConstructed, ldap.ApplicationSearchResultEntry, nil, "Search Result Entry")
+	op.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, e.dn, "objectName"))
+	attributes := ber.NewSequence("attributes")
+	for name, values := range e.attrs {
+		requested := len(attrs) == 0
+		for _, a := range attrs {
+			requested = requested || strings.EqualFold(a, name)
+		}
+		if !requested {
+			continue
+		}
+		attribute := ber.NewSequence("partialAttribute")
+		attribute.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, name, "type"))
+		vals := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSet, nil, "vals")
+		for _, v := range values {
+			vals.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, v, "value"))
+		}
+		attribute.AppendChild(vals)
+		attributes.AppendChild(attribute)
+	}
+	op.AppendChild(attributes)
+	return op
+}
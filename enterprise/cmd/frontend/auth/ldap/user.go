@@ -0,0 +1,46 @@
+package ldap
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pkg/errors"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/auth"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
+	"github.com/sourcegraph/sourcegraph/internal/extsvc"
+)
+
+// getOrCreateUser returns the ID of the Sourcegraph user associated with the LDAP user entry,
+// creating the user if needed.
+func getOrCreateUser(ctx context.Context, p *provider, u *directoryUser) (userID int32, safeErrMsg string, err error) {
+	if u.Username == "" {
+		return 0, "The LDAP user entry has no username attribute. Ask a site admin to check the usernameAttribute of the LDAP auth provider.", fmt.Errorf("LDAP user entry %q has no username attribute", u.DN)
+	}
+	if u.Email == "" {
+		return 0, "Only users with an email address may authenticate to Sourcegraph.", errors.New("LDAP user entry has no email address")
+	}
+	login, err := auth.NormalizeUsername(u.Username)
+	if err != nil {
+		return 0, fmt.Sprintf("Error normalizing the username %q. See https://docs.sourcegraph.com/admin/auth/#username-normalization.", u.Username), err
+	}
+
+	var data extsvc.AccountData
+	data.SetAccountData(u)
+
+	return auth.GetAndSaveUser(ctx, auth.GetAndSaveUserOp{
+		UserProps: db.NewUser{
+			Username: login,
+			Email:    u.Email,
+			// The LDAP directory is trusted to provide the user's email address.
+			EmailIsVerified: true,
+			DisplayName:     u.DisplayName,
+		},
+		ExternalAccount: extsvc.AccountSpec{
+			ServiceType: providerType,
+			ServiceID:   p.config.Url,
+			AccountID:   u.DN,
+		},
+		ExternalAccountData: data,
+		CreateIfNotExist:    true,
+	})
+}
@@ -2,9 +2,11 @@ package db
 
 import (
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
+	"github.com/sourcegraph/sourcegraph/enterprise/cmd/frontend/internal/authz/bitbucketcloud"
 	"github.com/sourcegraph/sourcegraph/enterprise/cmd/frontend/internal/authz/bitbucketserver"
 	"github.com/sourcegraph/sourcegraph/enterprise/cmd/frontend/internal/authz/github"
 	"github.com/sourcegraph/sourcegraph/enterprise/cmd/frontend/internal/authz/gitlab"
+	"github.com/sourcegraph/sourcegraph/enterprise/cmd/frontend/internal/authz/gitolite"
 	"github.com/sourcegraph/sourcegraph/schema"
 )
 
@@ -21,5 +23,11 @@ func NewExternalServicesStore() *db.ExternalServicesStore {
 		BitbucketServerValidators: []func(*schema.BitbucketServerConnection) error{
 			bitbucketserver.ValidateAuthz,
 		},
+		BitbucketCloudValidators: []func(*schema.BitbucketCloudConnection) error{
+			bitbucketcloud.ValidateAuthz,
+		},
+		GitoliteValidators: []func(*schema.GitoliteConnection) error{
+			gitolite.ValidateAuthz,
+		},
 	}
 }
@@ -0,0 +1,297 @@
+// Package bitbucketcloud contains an authorization provider for Bitbucket Cloud.
+package bitbucketcloud
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	otlog "github.com/opentracing/opentracing-go/log"
+	"github.com/pkg/errors"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/authz"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
+	"github.com/sourcegraph/sourcegraph/internal/extsvc"
+	"github.com/sourcegraph/sourcegraph/internal/extsvc/bitbucketcloud"
+	"github.com/sourcegraph/sourcegraph/internal/trace"
+)
+
+// Provider is an implementation of AuthzProvider that provides repository permissions as
+// determined from the workspace memberships and repository permissions of a Bitbucket
+// Cloud instance API.
+type Provider struct {
+	client     *bitbucketcloud.Client
+	codeHost   *extsvc.CodeHost
+	workspaces []string // Workspaces whose members and repository permissions are synced.
+	pageSize   int      // Page size to use in paginated requests.
+}
+
+var _ authz.Provider = (*Provider)(nil)
+
+// NewProvider returns a new Bitbucket Cloud authorization provider that uses the given
+// bitbucketcloud.Client to read the repository permissions of the given workspaces. The
+// client's user must be an administrator of all of these workspaces. It assumes usernames
+// of Sourcegraph accounts match 1-1 with nicknames of Bitbucket Cloud users.
+func NewProvider(cli *bitbucketcloud.Client, baseURL *url.URL, workspaces []string) *Provider {
+	return &Provider{
+		client:     cli,
+		codeHost:   extsvc.NewCodeHost(baseURL, bitbucketcloud.ServiceType),
+		workspaces: workspaces,
+		pageSize:   100,
+	}
+}
+
+// Validate validates that the Provider has administrator access to the repository
+// permissions of its workspaces with the credentials it was configured with.
+func (p *Provider) Validate() (problems []string) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	for _, ws := range p.workspaces {
+		_, _, err := p.client.WorkspaceRepoPermissions(ctx, &bitbucketcloud.PageToken{Pagelen: 1}, ws, "")
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("workspace %q: %s", ws, err))
+		}
+	}
+
+	return problems
+}
+
+// ServiceID returns the absolute URL that identifies the Bitbucket Cloud instance
+// this provider is configured with.
+func (p *Provider) ServiceID() string { return p.codeHost.ServiceID }
+
+// ServiceType returns the type of this Provider, namely, "bitbucketCloud".
+func (p *Provider) ServiceType() string { return p.codeHost.ServiceType }
+
+// RepoPerms returns the permissions the given external account has in relation to the given
+// set of repos. Public repositories are readable by everyone, private ones only by accounts
+// that have at least read permission on them. Permissions are fetched from the Bitbucket
+// Cloud API on every call, so enabling background permissions syncing is recommended.
+func (p *Provider) RepoPerms(ctx context.Context, acct *extsvc.Account, repos []*types.Repo) (
+	perms []authz.RepoPerms,
+	err error,
+) {
+	tr, ctx := trace.New(ctx, "bitbucketcloud.authz.provider.RepoPerms", "")
+	defer func() {
+		if acct != nil {
+			tr.LogFields(otlog.String("account.id", acct.AccountID))
+		}
+		tr.LogFields(
+			otlog.Int("repos.count", len(repos)),
+			otlog.Int("perms.count", len(perms)),
+		)
+
+		if err != nil {
+			tr.SetError(err)
+		}
+
+		tr.Finish()
+	}()
+
+	readable := make(map[string]bool)
+	if acct != nil && extsvc.IsHostOfAccount(p.codeHost, acct) {
+		ids, err := p.FetchUserPerms(ctx, acct)
+		if err != nil {
+			return nil, err
+		}
+		for _, id := range ids {
+			readable[string(id)] = true
+		}
+	}
+
+	perms = make([]authz.RepoPerms, 0, len(repos))
+	for _, r := range repos {
+		if !extsvc.IsHostOfRepo(p.codeHost, &r.ExternalRepo) {
+			continue
+		}
+		if !r.Private || readable[r.ExternalRepo.ID] {
+			perms = append(perms, authz.RepoPerms{Repo: r, Perms: authz.Read})
+		}
+	}
+
+	return perms, nil
+}
+
+// FetchAccount returns the Bitbucket Cloud user whose nickname matches the username of
+// the given user among the members of the provider's workspaces. It returns nil if there
+// is no such member.
+func (p *Provider) FetchAccount(ctx context.Context, user *types.User, _ []*extsvc.Account) (acct *extsvc.Account, err error) {
+	if user == nil {
+		return nil, nil
+	}
+
+	tr, ctx := trace.New(ctx, "bitbucketcloud.authz.provider.FetchAccount", "")
+	defer func() {
+		tr.LogFields(
+			otlog.String("user.name", user.Username),
+			otlog.Int32("user.id", user.ID),
+		)
+
+		if err != nil {
+			tr.SetError(err)
+		}
+
+		tr.Finish()
+	}()
+
+	bitbucketUser, err := p.member(ctx, user.Username)
+	if err != nil || bitbucketUser == nil {
+		return nil, err
+	}
+
+	accountData, err := json.Marshal(bitbucketUser)
+	if err != nil {
+		return nil, err
+	}
+
+	return &extsvc.Account{
+		UserID: user.ID,
+		AccountSpec: extsvc.AccountSpec{
+			ServiceType: p.codeHost.ServiceType,
+			ServiceID:   p.codeHost.ServiceID,
+			AccountID:   bitbucketUser.UUID,
+		},
+		AccountData: extsvc.AccountData{
+			Data: (*json.RawMessage)(&accountData),
+		},
+	}, nil
+}
+
+// FetchUserPerms returns a list of repository UUIDs that the given account has read
+// access to in the provider's workspaces. The repository UUID has the same value as it
+// would be used as api.ExternalRepoSpec.ID.
+//
+// This method may return partial but valid results in case of error, and it is up to
+// callers to decide whether to discard.
+//
+// API docs: https://developer.atlassian.com/bitbucket/api/2/reference/resource/workspaces/%7Bworkspace%7D/permissions/repositories
+func (p *Provider) FetchUserPerms(ctx context.Context, account *extsvc.Account) ([]extsvc.RepoID, error) {
+	switch {
+	case account == nil:
+		return nil, errors.New("no account provided")
+	case !extsvc.IsHostOfAccount(p.codeHost, account):
+		return nil, fmt.Errorf("not a code host of the account: want %q but have %q",
+			p.codeHost.ServiceID, account.AccountSpec.ServiceID)
+	}
+
+	q := fmt.Sprintf("user.uuid=%q", account.AccountID)
+
+	var ids []extsvc.RepoID
+	for _, ws := range p.workspaces {
+		t := &bitbucketcloud.PageToken{Pagelen: p.pageSize}
+		for {
+			perms, next, err := p.client.WorkspaceRepoPermissions(ctx, t, ws, q)
+			if err != nil {
+				return ids, errors.Wrapf(err, "list repository permissions of workspace %q", ws)
+			}
+
+			for _, perm := range perms {
+				if perm.Repository != nil && perm.User != nil && perm.User.UUID == account.AccountID && canRead(perm.Permission) {
+					ids = append(ids, extsvc.RepoID(perm.Repository.UUID))
+				}
+			}
+
+			if !next.HasMore() {
+				break
+			}
+			t = next
+		}
+	}
+
+	return ids, nil
+}
+
+// FetchRepoPerms returns a list of user UUIDs who have read access to the given repository
+// on Bitbucket Cloud. The user UUID has the same value as it would be used as
+// extsvc.Account.AccountID. The returned list includes both direct access and access
+// inherited from group memberships.
+//
+// This method may return partial but valid results in case of error, and it is up to
+// callers to decide whether to discard.
+//
+// API docs: https://developer.atlassian.com/bitbucket/api/2/reference/resource/workspaces/%7Bworkspace%7D/permissions/repositories/%7Brepo_slug%7D
+func (p *Provider) FetchRepoPerms(ctx context.Context, repo *extsvc.Repository) ([]extsvc.AccountID, error) {
+	switch {
+	case repo == nil:
+		return nil, errors.New("no repo provided")
+	case !extsvc.IsHostOfRepo(p.codeHost, &repo.ExternalRepoSpec):
+		return nil, fmt.Errorf("not a code host of the repo: want %q but have %q",
+			p.codeHost.ServiceID, repo.ServiceID)
+	}
+
+	fullName, err := repoFullName(repo.URI)
+	if err != nil {
+		return nil, err
+	}
+
+	var ids []extsvc.AccountID
+	t := &bitbucketcloud.PageToken{Pagelen: p.pageSize}
+	for {
+		perms, next, err := p.client.RepoPermissions(ctx, t, fullName)
+		if err != nil {
+			return ids, errors.Wrapf(err, "list permissions of repository %q", fullName)
+		}
+
+		for _, perm := range perms {
+			if perm.User != nil && canRead(perm.Permission) {
+				ids = append(ids, extsvc.AccountID(perm.User.UUID))
+			}
+		}
+
+		if !next.HasMore() {
+			break
+		}
+		t = next
+	}
+
+	return ids, nil
+}
+
+// member returns the member of the provider's workspaces with the given nickname, or nil
+// if there is none.
+func (p *Provider) member(ctx context.Context, nickname string) (*bitbucketcloud.User, error) {
+	for _, ws := range p.workspaces {
+		t := &bitbucketcloud.PageToken{Pagelen: p.pageSize}
+		for {
+			users, next, err := p.client.WorkspaceMembers(ctx, t, ws)
+			if err != nil {
+				return nil, errors.Wrapf(err, "list members of workspace %q", ws)
+			}
+
+			for _, u := range users {
+				if u.Nickname == nickname {
+					return u, nil
+				}
+			}
+
+			if !next.HasMore() {
+				break
+			}
+			t = next
+		}
+	}
+
+	return nil, nil
+}
+
+// canRead returns true if the given Bitbucket Cloud permission level includes read access.
+func canRead(permission string) bool {
+	switch permission {
+	case "read", "write", "admin":
+		return true
+	}
+	return false
+}
+
+// repoFullName returns the "workspace/slug" part of a repository URI, which has the form
+// "bitbucket.org/workspace/slug".
+func repoFullName(uri string) (string, error) {
+	parts := strings.Split(strings.Trim(uri, "/"), "/")
+	if len(parts) < 3 {
+		return "", fmt.Errorf("invalid Bitbucket Cloud repository URI %q", uri)
+	}
+	return strings.Join(parts[len(parts)-2:], "/"), nil
+}
@@ -0,0 +1,268 @@
+package bitbucketcloud
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"testing"
+
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/authz"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/extsvc"
+	"github.com/sourcegraph/sourcegraph/internal/extsvc/bitbucketcloud"
+)
+
+const (
+	aliceUUID = "{8f1b0a4e-0000-0000-0000-000000000001}"
+	bobUUID   = "{8f1b0a4e-0000-0000-0000-000000000002}"
+	muxUUID   = "{e1e75436-05e6-4c38-8543-9c36ec26fad1}"
+	langUUID  = "{421b93e9-1f00-4054-8156-4d821d4a768b}"
+)
+
+// newTestProvider returns a Provider for the workspaces "sglocal" and "other"
+// backed by a fake Bitbucket Cloud API. Alice can read sglocal/mux, bob can
+// read sglocal/mux and sglocal/lang, and carol has no access to anything.
+// Results are served in pages of one item to exercise pagination.
+func newTestProvider(t *testing.T) (*Provider, func()) {
+	t.Helper()
+
+	alice := map[string]string{"uuid": aliceUUID, "nickname": "alice"}
+	bob := map[string]string{"uuid": bobUUID, "nickname": "bob"}
+	mux := map[string]string{"uuid": muxUUID, "full_name": "sglocal/mux"}
+	lang := map[string]string{"uuid": langUUID, "full_name": "sglocal/lang"}
+
+	type perm struct {
+		Permission string            `json:"permission"`
+		User       map[string]string `json:"user"`
+		Repository map[string]string `json:"repository"`
+	}
+	perms := []perm{
+		{"admin", alice, mux},
+		{"write", bob, mux},
+		{"read", bob, lang},
+		{"none", alice, lang},
+	}
+
+	var srv *httptest.Server
+	// page writes the item with the index given by the "page" query
+	// parameter, and a link to the next page if there is one.
+	page := func(w http.ResponseWriter, r *http.Request, values []interface{}) {
+		p := 0
+		if s := r.URL.Query().Get("page"); s != "" {
+			p = int(s[0] - '0')
+		}
+		resp := map[string]interface{}{"page": p + 1, "values": values[p : p+1]}
+		if p+1 < len(values) {
+			q := r.URL.Query()
+			q.Set("page", string(rune('0'+p+1)))
+			resp["next"] = srv.URL + r.URL.Path + "?" + q.Encode()
+		}
+		_ = json.NewEncoder(w).Encode(resp)
+	}
+	empty := func(w http.ResponseWriter) {
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"page": 1, "values": []interface{}{}})
+	}
+
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if u, p, _ := r.BasicAuth(); u != "admin" || p != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		switch r.URL.Path {
+		case "/2.0/workspaces/sglocal/members":
+			page(w, r, []interface{}{
+				map[string]interface{}{"user": alice},
+				map[string]interface{}{"user": bob},
+			})
+
+		case "/2.0/workspaces/sglocal/permissions/repositories":
+			var values []interface{}
+			for _, p := range perms {
+				if q := r.URL.Query().Get("q"); q == "" || q == `user.uuid="`+p.User["uuid"]+`"` {
+					values = append(values, p)
+				}
+			}
+			if len(values) == 0 {
+				empty(w)
+				return
+			}
+			page(w, r, values)
+
+		case "/2.0/workspaces/sglocal/permissions/repositories/mux":
+			page(w, r, []interface{}{perms[0], perms[1]})
+
+		case "/2.0/workspaces/other/members", "/2.0/workspaces/other/permissions/repositories":
+			empty(w)
+
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+
+	apiURL, _ := url.Parse(srv.URL)
+	cli := bitbucketcloud.NewClient(apiURL, nil)
+	cli.Username = "admin"
+	cli.AppPassword = "secret"
+
+	p := NewProvider(cli, &url.URL{Scheme: "https", Host: "bitbucket.org"}, []string{"other", "sglocal"})
+	return p, srv.Close
+}
+
+func TestProvider_Validate(t *testing.T) {
+	p, done := newTestProvider(t)
+	defer done()
+
+	if problems := p.Validate(); len(problems) != 0 {
+		t.Fatalf("unexpected problems: %v", problems)
+	}
+
+	p.workspaces = append(p.workspaces, "unknown")
+	if problems := p.Validate(); len(problems) != 1 {
+		t.Fatalf("want 1 problem, got %v", problems)
+	}
+}
+
+func TestProvider_FetchAccount(t *testing.T) {
+	p, done := newTestProvider(t)
+	defer done()
+	ctx := context.Background()
+
+	acct, err := p.FetchAccount(ctx, &types.User{ID: 42, Username: "bob"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := extsvc.AccountSpec{
+		ServiceType: bitbucketcloud.ServiceType,
+		ServiceID:   "https://bitbucket.org/",
+		AccountID:   bobUUID,
+	}
+	if acct == nil || acct.UserID != 42 || acct.AccountSpec != want {
+		t.Fatalf("got account %+v, want %+v", acct, want)
+	}
+
+	acct, err = p.FetchAccount(ctx, &types.User{ID: 43, Username: "carol"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if acct != nil {
+		t.Fatalf("want no account for non-member, got %+v", acct)
+	}
+}
+
+func TestProvider_FetchUserPerms(t *testing.T) {
+	p, done := newTestProvider(t)
+	defer done()
+	ctx := context.Background()
+
+	account := func(uuid string) *extsvc.Account {
+		return &extsvc.Account{AccountSpec: extsvc.AccountSpec{
+			ServiceType: p.ServiceType(),
+			ServiceID:   p.ServiceID(),
+			AccountID:   uuid,
+		}}
+	}
+
+	for _, tc := range []struct {
+		name string
+		uuid string
+		want []extsvc.RepoID
+	}{
+		{name: "alice", uuid: aliceUUID, want: []extsvc.RepoID{muxUUID}},
+		{name: "bob", uuid: bobUUID, want: []extsvc.RepoID{muxUUID, langUUID}},
+		{name: "carol", uuid: "{8f1b0a4e-0000-0000-0000-000000000003}", want: nil},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := p.FetchUserPerms(ctx, account(tc.uuid))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("got %v, want %v", got, tc.want)
+			}
+		})
+	}
+
+	other := account(aliceUUID)
+	other.ServiceID = "https://bitbucket.example.com/"
+	if _, err := p.FetchUserPerms(ctx, other); err == nil {
+		t.Error("want error for account of another code host")
+	}
+}
+
+func TestProvider_FetchRepoPerms(t *testing.T) {
+	p, done := newTestProvider(t)
+	defer done()
+
+	got, err := p.FetchRepoPerms(context.Background(), &extsvc.Repository{
+		URI: "bitbucket.org/sglocal/mux",
+		ExternalRepoSpec: api.ExternalRepoSpec{
+			ID:          muxUUID,
+			ServiceType: p.ServiceType(),
+			ServiceID:   p.ServiceID(),
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []extsvc.AccountID{aliceUUID, bobUUID}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestProvider_RepoPerms(t *testing.T) {
+	p, done := newTestProvider(t)
+	defer done()
+
+	repo := func(id int32, uuid string, private bool) *types.Repo {
+		return &types.Repo{
+			ID:      api.RepoID(id),
+			Private: private,
+			ExternalRepo: api.ExternalRepoSpec{
+				ID:          uuid,
+				ServiceType: p.ServiceType(),
+				ServiceID:   p.ServiceID(),
+			},
+		}
+	}
+	mux := repo(1, muxUUID, true)
+	lang := repo(2, langUUID, true)
+	public := repo(3, "{00000000-0000-0000-0000-000000000003}", false)
+	repos := []*types.Repo{mux, lang, public}
+
+	alice := &extsvc.Account{AccountSpec: extsvc.AccountSpec{
+		ServiceType: p.ServiceType(),
+		ServiceID:   p.ServiceID(),
+		AccountID:   aliceUUID,
+	}}
+
+	for _, tc := range []struct {
+		name string
+		acct *extsvc.Account
+		want []authz.RepoPerms
+	}{
+		{
+			name: "anonymous sees public repos",
+			want: []authz.RepoPerms{{Repo: public, Perms: authz.Read}},
+		},
+		{
+			name: "alice sees mux and public repos",
+			acct: alice,
+			want: []authz.RepoPerms{{Repo: mux, Perms: authz.Read}, {Repo: public, Perms: authz.Read}},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := p.RepoPerms(context.Background(), tc.acct, repos)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("got %+v, want %+v", got, tc.want)
+			}
+		})
+	}
+}
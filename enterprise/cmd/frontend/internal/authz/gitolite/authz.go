@@ -0,0 +1,54 @@
+package gitolite
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/authz"
+	"github.com/sourcegraph/sourcegraph/schema"
+)
+
+// NewAuthzProviders returns the set of Gitolite authz providers derived from the connections.
+// It also returns any validation problems with the config, separating these into "serious problems" and
+// "warnings". "Serious problems" are those that should make Sourcegraph set authz.allowAccessByDefault
+// to false. "Warnings" are all other validation problems.
+func NewAuthzProviders(
+	conns []*schema.GitoliteConnection,
+) (ps []authz.Provider, problems []string, warnings []string) {
+	for _, c := range conns {
+		p, err := newAuthzProvider(c)
+		if err != nil {
+			problems = append(problems, err.Error())
+		} else if p != nil {
+			ps = append(ps, p)
+		}
+	}
+
+	for _, p := range ps {
+		for _, problem := range p.Validate() {
+			warnings = append(warnings, fmt.Sprintf("Gitolite config for %s was invalid: %s", p.ServiceID(), problem))
+		}
+	}
+
+	return ps, problems, warnings
+}
+
+func newAuthzProvider(c *schema.GitoliteConnection) (authz.Provider, error) {
+	if c.Authorization == nil {
+		return nil, nil
+	}
+
+	switch idp := c.Authorization.IdentityProvider; {
+	case idp.Username != nil:
+		return NewProvider(c.Host, c.Authorization.UsernameMapping, c.Authorization.MatchUsernames), nil
+	default:
+		return nil, errors.Errorf("No identityProvider was specified")
+	}
+}
+
+// ValidateAuthz validates the authorization fields of the given Gitolite external
+// service config.
+func ValidateAuthz(c *schema.GitoliteConnection) error {
+	_, err := newAuthzProvider(c)
+	return err
+}
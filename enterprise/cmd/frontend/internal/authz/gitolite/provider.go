@@ -0,0 +1,176 @@
+// Package gitolite contains an authorization provider for Gitolite.
+package gitolite
+
+import (
+	"context"
+	"fmt"
+
+	otlog "github.com/opentracing/opentracing-go/log"
+	"github.com/pkg/errors"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/authz"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
+	"github.com/sourcegraph/sourcegraph/internal/extsvc"
+	"github.com/sourcegraph/sourcegraph/internal/extsvc/gitolite"
+	"github.com/sourcegraph/sourcegraph/internal/gitserver"
+	"github.com/sourcegraph/sourcegraph/internal/trace"
+)
+
+// accessClient lists the Gitolite repositories a Gitolite user can read.
+// It is implemented by *gitserver.Client, which runs the Gitolite `access`
+// command on a gitserver that has the SSH keys of the Gitolite host.
+type accessClient interface {
+	GitoliteAccess(ctx context.Context, gitoliteHost, user string) ([]string, error)
+}
+
+// Provider is an implementation of AuthzProvider that provides repository permissions as
+// determined by the access rules of a Gitolite host.
+type Provider struct {
+	client          accessClient
+	codeHost        *extsvc.CodeHost
+	usernameMapping map[string]string // Sourcegraph username -> Gitolite username
+	matchUsernames  bool              // whether unmapped users are identified by their username
+}
+
+var _ authz.Provider = (*Provider)(nil)
+
+// NewProvider returns a new Gitolite authorization provider for the given Gitolite host.
+// Sourcegraph users are identified by the Gitolite username that usernameMapping maps
+// their username to, or else by the Gitolite username of their existing Gitolite external
+// account, or else (only if matchUsernames is true) by their username.
+func NewProvider(host string, usernameMapping map[string]string, matchUsernames bool) *Provider {
+	return &Provider{
+		client: gitserver.DefaultClient,
+		codeHost: &extsvc.CodeHost{
+			ServiceID:   gitolite.ServiceID(host),
+			ServiceType: gitolite.ServiceType,
+		},
+		usernameMapping: usernameMapping,
+		matchUsernames:  matchUsernames,
+	}
+}
+
+// Validate returns no problems: the Gitolite `access` command can only be checked by
+// running it, which lists all repositories of the Gitolite host.
+func (p *Provider) Validate() (problems []string) {
+	return nil
+}
+
+// ServiceID returns the Gitolite host this provider is configured with.
+func (p *Provider) ServiceID() string { return p.codeHost.ServiceID }
+
+// ServiceType returns the type of this Provider, namely, "gitolite".
+func (p *Provider) ServiceType() string { return p.codeHost.ServiceType }
+
+// RepoPerms returns the permissions the given external account has in relation to the given
+// set of repos. Gitolite has no public repositories, so a repository is only readable by
+// accounts that Gitolite grants read access to. Permissions are read from the Gitolite host
+// on every call, so enabling background permissions syncing is recommended.
+func (p *Provider) RepoPerms(ctx context.Context, acct *extsvc.Account, repos []*types.Repo) (
+	perms []authz.RepoPerms,
+	err error,
+) {
+	tr, ctx := trace.New(ctx, "gitolite.authz.provider.RepoPerms", "")
+	defer func() {
+		if acct != nil {
+			tr.LogFields(otlog.String("account.id", acct.AccountID))
+		}
+		tr.LogFields(
+			otlog.Int("repos.count", len(repos)),
+			otlog.Int("perms.count", len(perms)),
+		)
+
+		if err != nil {
+			tr.SetError(err)
+		}
+
+		tr.Finish()
+	}()
+
+	if acct == nil || !extsvc.IsHostOfAccount(p.codeHost, acct) {
+		return nil, nil
+	}
+
+	ids, err := p.FetchUserPerms(ctx, acct)
+	if err != nil {
+		return nil, err
+	}
+
+	readable := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		readable[string(id)] = true
+	}
+
+	perms = make([]authz.RepoPerms, 0, len(repos))
+	for _, r := range repos {
+		if extsvc.IsHostOfRepo(p.codeHost, &r.ExternalRepo) && readable[r.ExternalRepo.ID] {
+			perms = append(perms, authz.RepoPerms{Repo: r, Perms: authz.Read})
+		}
+	}
+
+	return perms, nil
+}
+
+// FetchAccount returns the Gitolite account of the given user, whose account ID is the
+// Gitolite username of the user. It returns nil if the user can't be identified (see
+// NewProvider).
+func (p *Provider) FetchAccount(ctx context.Context, user *types.User, current []*extsvc.Account) (*extsvc.Account, error) {
+	if user == nil {
+		return nil, nil
+	}
+
+	username := p.usernameMapping[user.Username]
+	if username == "" {
+		for _, acct := range current {
+			if extsvc.IsHostOfAccount(p.codeHost, acct) && acct.AccountID != "" {
+				username = acct.AccountID
+				break
+			}
+		}
+	}
+	if username == "" {
+		// 🚨 SECURITY: Users who can choose their username could otherwise take the
+		// username of any Gitolite user, so this must be enabled explicitly.
+		if !p.matchUsernames {
+			return nil, nil
+		}
+		username = user.Username
+	}
+
+	return &extsvc.Account{
+		UserID: user.ID,
+		AccountSpec: extsvc.AccountSpec{
+			ServiceType: p.codeHost.ServiceType,
+			ServiceID:   p.codeHost.ServiceID,
+			AccountID:   username,
+		},
+	}, nil
+}
+
+// FetchUserPerms returns the names of the Gitolite repositories that the given account
+// can read. The name has the same value as it would be used as api.ExternalRepoSpec.ID.
+func (p *Provider) FetchUserPerms(ctx context.Context, account *extsvc.Account) ([]extsvc.RepoID, error) {
+	switch {
+	case account == nil:
+		return nil, errors.New("no account provided")
+	case !extsvc.IsHostOfAccount(p.codeHost, account):
+		return nil, fmt.Errorf("not a code host of the account: want %q but have %q",
+			p.codeHost.ServiceID, account.AccountSpec.ServiceID)
+	}
+
+	names, err := p.client.GitoliteAccess(ctx, p.codeHost.ServiceID, account.AccountID)
+	if err != nil {
+		return nil, errors.Wrapf(err, "list readable repositories of Gitolite user %q", account.AccountID)
+	}
+
+	ids := make([]extsvc.RepoID, len(names))
+	for i, name := range names {
+		ids[i] = extsvc.RepoID(name)
+	}
+	return ids, nil
+}
+
+// FetchRepoPerms returns authz.ErrUnimplemented: Gitolite can only check the access of
+// given users, and doesn't know which users exist. Permissions are synced per user.
+func (p *Provider) FetchRepoPerms(ctx context.Context, repo *extsvc.Repository) ([]extsvc.AccountID, error) {
+	return nil, authz.ErrUnimplemented
+}
@@ -0,0 +1,211 @@
+package gitolite
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/authz"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/extsvc"
+	"github.com/sourcegraph/sourcegraph/internal/extsvc/gitolite"
+)
+
+const host = "git@gitolite.example.com"
+
+// fakeAccessClient returns the readable repositories of a Gitolite user from
+// a map, and an error for unknown hosts.
+type fakeAccessClient map[string][]string
+
+func (c fakeAccessClient) GitoliteAccess(ctx context.Context, gitoliteHost, user string) ([]string, error) {
+	if gitoliteHost != host {
+		return nil, errors.New("unknown host")
+	}
+	return c[user], nil
+}
+
+func newTestProvider() *Provider {
+	p := NewProvider(host, map[string]string{"alice": "alice.smith"}, true)
+	p.client = fakeAccessClient{
+		"alice.smith": {"public", "secret"},
+		"bob":         {"public"},
+		"carol":       {"secret"},
+	}
+	return p
+}
+
+func account(username string) *extsvc.Account {
+	return &extsvc.Account{
+		AccountSpec: extsvc.AccountSpec{
+			ServiceType: gitolite.ServiceType,
+			ServiceID:   host,
+			AccountID:   username,
+		},
+	}
+}
+
+func TestProvider_FetchAccount(t *testing.T) {
+	p := newTestProvider()
+
+	tests := []struct {
+		name    string
+		user    *types.User
+		current []*extsvc.Account
+		want    string
+	}{
+		{
+			name: "mapped username",
+			user: &types.User{ID: 1, Username: "alice"},
+			// The mapping takes precedence over existing accounts.
+			current: []*extsvc.Account{account("alice")},
+			want:    "alice.smith",
+		},
+		{
+			name:    "existing account",
+			user:    &types.User{ID: 3, Username: "carol-sg"},
+			current: []*extsvc.Account{account("carol")},
+			want:    "carol",
+		},
+		{
+			name: "existing account of another code host",
+			user: &types.User{ID: 3, Username: "carol-sg"},
+			current: []*extsvc.Account{{
+				AccountSpec: extsvc.AccountSpec{
+					ServiceType: gitolite.ServiceType,
+					ServiceID:   "git@other.example.com",
+					AccountID:   "carol",
+				},
+			}},
+			want: "carol-sg",
+		},
+		{
+			name: "same username",
+			user: &types.User{ID: 2, Username: "bob"},
+			want: "bob",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			acct, err := p.FetchAccount(context.Background(), tc.user, tc.current)
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := account(tc.want)
+			want.UserID = tc.user.ID
+			if !reflect.DeepEqual(acct, want) {
+				t.Errorf("have account %+v, want %+v", acct, want)
+			}
+		})
+	}
+
+	if acct, err := p.FetchAccount(context.Background(), nil, nil); acct != nil || err != nil {
+		t.Errorf("have account %+v and error %v for nil user, want nil", acct, err)
+	}
+
+	// Without matchUsernames, only mapped users and users with a Gitolite account are identified.
+	p.matchUsernames = false
+	if acct, err := p.FetchAccount(context.Background(), &types.User{ID: 2, Username: "bob"}, nil); acct != nil || err != nil {
+		t.Errorf("have account %+v and error %v for unmapped user, want nil", acct, err)
+	}
+	if acct, err := p.FetchAccount(context.Background(), &types.User{ID: 3, Username: "carol-sg"}, []*extsvc.Account{account("carol")}); err != nil || acct == nil || acct.AccountID != "carol" {
+		t.Errorf("have account %+v and error %v for user with a Gitolite account, want carol", acct, err)
+	}
+}
+
+func TestProvider_FetchUserPerms(t *testing.T) {
+	p := newTestProvider()
+	ctx := context.Background()
+
+	ids, err := p.FetchUserPerms(ctx, account("alice.smith"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []extsvc.RepoID{"public", "secret"}; !reflect.DeepEqual(ids, want) {
+		t.Errorf("have %v, want %v", ids, want)
+	}
+
+	other := account("alice.smith")
+	other.ServiceID = "git@other.example.com"
+	if _, err := p.FetchUserPerms(ctx, other); err == nil {
+		t.Error("expected error for account of another code host")
+	}
+
+	if _, err := p.FetchUserPerms(ctx, nil); err == nil {
+		t.Error("expected error for nil account")
+	}
+}
+
+func TestProvider_FetchRepoPerms(t *testing.T) {
+	_, err := newTestProvider().FetchRepoPerms(context.Background(), &extsvc.Repository{})
+	if err != authz.ErrUnimplemented {
+		t.Errorf("have error %v, want %v", err, authz.ErrUnimplemented)
+	}
+}
+
+func TestProvider_RepoPerms(t *testing.T) {
+	p := newTestProvider()
+
+	repo := func(id int32, name string, serviceID string) *types.Repo {
+		return &types.Repo{
+			ID:   api.RepoID(id),
+			Name: api.RepoName("gitolite.example.com/" + name),
+			ExternalRepo: api.ExternalRepoSpec{
+				ID:          name,
+				ServiceType: gitolite.ServiceType,
+				ServiceID:   serviceID,
+			},
+			Private: true,
+		}
+	}
+	public := repo(1, "public", host)
+	secret := repo(2, "secret", host)
+	other := repo(3, "public", "git@other.example.com")
+	repos := []*types.Repo{public, secret, other}
+
+	tests := []struct {
+		name string
+		acct *extsvc.Account
+		want []authz.RepoPerms
+	}{
+		{
+			name: "reads everything",
+			acct: account("alice.smith"),
+			want: []authz.RepoPerms{
+				{Repo: public, Perms: authz.Read},
+				{Repo: secret, Perms: authz.Read},
+			},
+		},
+		{
+			name: "reads some",
+			acct: account("bob"),
+			want: []authz.RepoPerms{
+				{Repo: public, Perms: authz.Read},
+			},
+		},
+		{
+			name: "unknown user",
+			acct: account("eve"),
+			want: []authz.RepoPerms{},
+		},
+		{
+			name: "no account",
+			acct: nil,
+			want: nil,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			perms, err := p.RepoPerms(context.Background(), tc.acct, repos)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(perms, tc.want) {
+				t.Errorf("have perms %+v, want %+v", perms, tc.want)
+			}
+		})
+	}
+}
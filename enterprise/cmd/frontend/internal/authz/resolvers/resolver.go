@@ -2,12 +2,16 @@ package resolvers
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/RoaringBitmap/roaring"
 	"github.com/pkg/errors"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/audit"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/authz"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/backend"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
@@ -21,12 +25,16 @@ import (
 )
 
 type Resolver struct {
+	db    dbutil.DB
 	store *edb.PermsStore
+	clock func() time.Time
 }
 
 func NewResolver(db dbutil.DB, clock func() time.Time) graphqlbackend.AuthzResolver {
 	return &Resolver{
+		db:    db,
 		store: edb.NewPermsStore(db, clock),
+		clock: clock,
 	}
 }
 
@@ -98,25 +106,57 @@ func (r *Resolver) SetRepositoryPermissionsForUsers(ctx context.Context, args *g
 		pendingBindIDs = append(pendingBindIDs, id)
 	}
 
-	txs, err := r.store.Transact(ctx)
-	if err != nil {
-		return nil, errors.Wrap(err, "start transaction")
-	}
-	defer txs.Done(&err)
-
 	accounts := &extsvc.Accounts{
 		ServiceType: authz.SourcegraphServiceType,
 		ServiceID:   authz.SourcegraphServiceID,
 		AccountIDs:  pendingBindIDs,
 	}
 
+	type repoPermissions struct {
+		UserIDs        []uint32 `json:"userIDs"`
+		PendingBindIDs []string `json:"pendingBindIDs,omitempty"`
+	}
+	userIDs := p.UserIDs.ToArray()
+	sort.Strings(pendingBindIDs)
+	if err := audit.TransactOn(ctx, r.db, func(tx *sql.Tx) (*audit.Event, error) {
+		oldUserIDs, err := setRepoPermissions(ctx, edb.NewPermsStore(tx, r.clock), p, accounts)
+		if err != nil {
+			return nil, err
+		}
+		return &audit.Event{
+			Action:     db.AuditLogActionSetRepositoryPermissions,
+			TargetType: "Repository",
+			TargetID:   strconv.FormatInt(int64(repoID), 10),
+			Before:     repoPermissions{UserIDs: oldUserIDs},
+			After:      repoPermissions{UserIDs: userIDs, PendingBindIDs: pendingBindIDs},
+		}, nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return &graphqlbackend.EmptyResponse{}, nil
+}
+
+// setRepoPermissions replaces the permissions and pending permissions of the repository using the
+// store txs, which wraps a transaction. It returns the IDs of the users who had permissions before.
+func setRepoPermissions(ctx context.Context, txs *edb.PermsStore, p *authz.RepoPermissions, accounts *extsvc.Accounts) (oldUserIDs []uint32, err error) {
+	old := &authz.RepoPermissions{RepoID: p.RepoID, Perm: p.Perm}
+	oldUserIDs = []uint32{}
+	switch err = txs.LoadRepoPermissions(ctx, old); err {
+	case nil:
+		oldUserIDs = old.UserIDs.ToArray()
+	case authz.ErrPermsNotFound:
+		err = nil
+	default:
+		return nil, errors.Wrap(err, "load repository permissions")
+	}
+
 	if err = txs.SetRepoPermissions(ctx, p); err != nil {
 		return nil, errors.Wrap(err, "set repository permissions")
 	} else if err = txs.SetRepoPendingPermissions(ctx, accounts, p); err != nil {
 		return nil, errors.Wrap(err, "set repository pending permissions")
 	}
-
-	return &graphqlbackend.EmptyResponse{}, nil
+	return oldUserIDs, nil
 }
 
 func (r *Resolver) AuthorizedUserRepositories(ctx context.Context, args *graphqlbackend.AuthorizedRepoArgs) (graphqlbackend.RepositoryConnectionResolver, error) {
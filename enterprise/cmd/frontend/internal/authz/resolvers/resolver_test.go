@@ -69,6 +69,7 @@ func TestResolver_SetRepositoryPermissionsForUsers(t *testing.T) {
 		gqlTests           []*gqltesting.Test
 		expUserIDs         []uint32
 		expAccounts        *extsvc.Accounts
+		expAuditAfter      string
 	}{
 		{
 			name: "set permissions via email",
@@ -108,6 +109,7 @@ func TestResolver_SetRepositoryPermissionsForUsers(t *testing.T) {
 				ServiceID:   authz.SourcegraphServiceID,
 				AccountIDs:  []string{"bob"},
 			},
+			expAuditAfter: `{"userIDs":[1],"pendingBindIDs":["bob"]}`,
 		},
 		{
 			name: "set permissions via username",
@@ -147,6 +149,7 @@ func TestResolver_SetRepositoryPermissionsForUsers(t *testing.T) {
 				ServiceID:   authz.SourcegraphServiceID,
 				AccountIDs:  []string{"bob"},
 			},
+			expAuditAfter: `{"userIDs":[1],"pendingBindIDs":["bob"]}`,
 		},
 	}
 	for _, test := range tests {
@@ -165,8 +168,9 @@ func TestResolver_SetRepositoryPermissionsForUsers(t *testing.T) {
 			db.Mocks.Repos.Get = func(_ context.Context, id api.RepoID) (*types.Repo, error) {
 				return &types.Repo{ID: id}, nil
 			}
-			edb.Mocks.Perms.Transact = func(_ context.Context) (*edb.PermsStore, error) {
-				return &edb.PermsStore{}, nil
+			edb.Mocks.Perms.LoadRepoPermissions = func(_ context.Context, p *authz.RepoPermissions) error {
+				p.UserIDs = roaring.BitmapOf(2)
+				return nil
 			}
 			edb.Mocks.Perms.SetRepoPermissions = func(_ context.Context, p *authz.RepoPermissions) error {
 				ids := p.UserIDs.ToArray()
@@ -181,14 +185,33 @@ func TestResolver_SetRepositoryPermissionsForUsers(t *testing.T) {
 				}
 				return nil
 			}
+			var entry *db.AuditLogEntry
+			db.Mocks.AuditLog.Insert = func(_ context.Context, e *db.AuditLogEntry) error {
+				entry = e
+				return nil
+			}
 			defer func() {
 				db.Mocks.UserEmails = db.MockUserEmails{}
 				db.Mocks.Users = db.MockUsers{}
 				db.Mocks.Repos = db.MockRepos{}
+				db.Mocks.AuditLog = db.MockAuditLog{}
 				edb.Mocks.Perms = edb.MockPerms{}
 			}()
 
 			gqltesting.RunTests(t, test.gqlTests)
+
+			if entry == nil {
+				t.Fatal("no audit log entry written")
+			}
+			if entry.Action != db.AuditLogActionSetRepositoryPermissions || entry.TargetType != "Repository" || entry.TargetID != "1" {
+				t.Errorf("got audit log entry %+v", entry)
+			}
+			if want := `{"userIDs":[2]}`; string(entry.Before) != want {
+				t.Errorf("audit log entry before: want %s but got %s", want, entry.Before)
+			}
+			if string(entry.After) != test.expAuditAfter {
+				t.Errorf("audit log entry after: want %s but got %s", test.expAuditAfter, entry.After)
+			}
 		})
 	}
 }
@@ -86,7 +86,9 @@ func main() {
 
 	go bitbucketServerWebhook.SyncWebhooks(1 * time.Minute)
 
-	shared.Main(githubWebhook, bitbucketServerWebhook)
+	gitlabWebhook := campaigns.NewGitLabWebhook(campaignsStore, repositories, clock)
+
+	shared.Main(githubWebhook, bitbucketServerWebhook, gitlabWebhook)
 }
 
 func initLicensing() {
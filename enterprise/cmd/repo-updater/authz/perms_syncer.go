@@ -252,7 +252,11 @@ func (s *PermsSyncer) syncRepoPerms(ctx context.Context, repoID api.RepoID, noPe
 		URI:              repo.URI,
 		ExternalRepoSpec: repo.ExternalRepo,
 	})
-	if err != nil {
+	if err == authz.ErrUnimplemented {
+		// The provider syncs permissions from the users' side only.
+		log15.Debug("PermsSyncer.syncRepoPerms.unimplemented", "repoID", repo.ID, "serviceID", provider.ServiceID())
+		return nil
+	} else if err != nil {
 		// Process partial results if this is an initial fetch.
 		if !noPerms {
 			return errors.Wrap(err, "fetch repository permissions")
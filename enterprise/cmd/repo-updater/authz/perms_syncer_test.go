@@ -286,6 +286,54 @@ func TestPermsSyncer_syncRepoPerms(t *testing.T) {
 	}
 }
 
+func TestPermsSyncer_syncRepoPerms_unimplemented(t *testing.T) {
+	p := &mockProvider{
+		serviceType: gitlab.ServiceType,
+		serviceID:   "https://gitlab.com/",
+		fetchRepoPerms: func(context.Context, *extsvc.Repository) ([]extsvc.AccountID, error) {
+			return nil, authz.ErrUnimplemented
+		},
+	}
+	authz.SetProviders(false, []authz.Provider{p})
+	defer authz.SetProviders(true, nil)
+
+	// Permissions must not be overwritten with an empty set.
+	edb.Mocks.Perms.SetRepoPermissions = func(context.Context, *authz.RepoPermissions) error {
+		t.Fatal("unexpected call to SetRepoPermissions")
+		return nil
+	}
+	defer func() {
+		edb.Mocks.Perms = edb.MockPerms{}
+	}()
+
+	reposStore := &mockReposStore{
+		listRepos: func(context.Context, repos.StoreListReposArgs) ([]*repos.Repo, error) {
+			return []*repos.Repo{
+				{
+					ID:      1,
+					Private: true,
+					ExternalRepo: api.ExternalRepoSpec{
+						ServiceID: p.ServiceID(),
+					},
+				},
+			}, nil
+		},
+	}
+	clock := func() time.Time {
+		return time.Now().UTC().Truncate(time.Microsecond)
+	}
+	permsStore := edb.NewPermsStore(nil, clock)
+	s := NewPermsSyncer(reposStore, permsStore, clock)
+	s.metrics.syncDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{}, []string{"type", "success"})
+	s.metrics.syncErrors = prometheus.NewCounterVec(prometheus.CounterOpts{}, []string{"type"})
+
+	for _, noPerms := range []bool{true, false} {
+		if err := s.syncRepoPerms(context.Background(), 1, noPerms); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
 func TestPermsSyncer_syncPerms(t *testing.T) {
 	request := &syncRequest{
 		requestMeta: &requestMeta{
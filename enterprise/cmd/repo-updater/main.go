@@ -12,6 +12,7 @@ import (
 	ossAuthz "github.com/sourcegraph/sourcegraph/cmd/frontend/authz"
 	ossDB "github.com/sourcegraph/sourcegraph/cmd/frontend/db"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/globals"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/graphqlbackend"
 	"github.com/sourcegraph/sourcegraph/cmd/repo-updater/repos"
 	"github.com/sourcegraph/sourcegraph/cmd/repo-updater/repoupdater"
 	"github.com/sourcegraph/sourcegraph/cmd/repo-updater/shared"
@@ -62,6 +63,9 @@ func enterpriseInit(
 	sourcer := repos.NewSourcer(cf)
 	go campaigns.RunWorkers(ctx, campaignsStore, clock, gitserver.DefaultClient, sourcer, 5*time.Second)
 
+	replacer := campaigns.NewReplacerClient(graphqlbackend.ReplacerURL, nil)
+	go campaigns.RunPatchJobWorkers(ctx, campaignsStore, clock, replacer, 5*time.Second)
+
 	// Set up expired patch set deletion
 	go func() {
 		for {
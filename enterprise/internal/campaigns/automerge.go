@@ -0,0 +1,160 @@
+package campaigns
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/inconshreveable/log15"
+	"github.com/sourcegraph/sourcegraph/cmd/repo-updater/repos"
+	"github.com/sourcegraph/sourcegraph/internal/campaigns"
+)
+
+// autoMergeReady returns whether the given changeset satisfies the given
+// AutoMergePolicy: it must be open, all of its checks must have passed, no
+// reviewer may have requested changes and it must have at least the required
+// number of approvals. The derived state of the changeset must be up to date.
+func autoMergeReady(c *campaigns.Changeset, es []*campaigns.ChangesetEvent, policy *campaigns.AutoMergePolicy) (bool, error) {
+	if c.ExternalState != campaigns.ChangesetStateOpen ||
+		c.ExternalCheckState != campaigns.ChangesetCheckStatePassed ||
+		c.ExternalReviewState == campaigns.ChangesetReviewStateChangesRequested {
+		return false, nil
+	}
+
+	// Copy so that we can sort without mutating the argument
+	events := make(ChangesetEvents, len(es))
+	copy(events, es)
+	sort.Sort(events)
+
+	approvals, err := ComputeApprovals(c, events)
+	if err != nil {
+		return false, err
+	}
+	return int32(approvals) >= policy.RequiredApprovals, nil
+}
+
+// autoMergeCampaign returns the open Campaign with an AutoMergePolicy that
+// the given changeset belongs to, or nil if there is none.
+func autoMergeCampaign(ctx context.Context, store SyncStore, c *campaigns.Changeset) (*campaigns.Campaign, error) {
+	cs, _, err := store.ListCampaigns(ctx, ListCampaignsOpts{
+		ChangesetID: c.ID,
+		State:       campaigns.CampaignStateOpen,
+		Limit:       len(c.CampaignIDs) + 1,
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, campaign := range cs {
+		if campaign.AutoMergePolicy != nil {
+			return campaign, nil
+		}
+	}
+	return nil, nil
+}
+
+// AutoMergeChangesets merges the given synced changesets that are ready
+// according to the AutoMergePolicy of one of their Campaigns and records the
+// outcome as a ChangesetEvent. A failed merge is not retried until the
+// changeset is updated on the code host.
+func AutoMergeChangesets(ctx context.Context, store SyncStore, clock func() time.Time, bySource []*SourceChangesets) (err error) {
+	var (
+		events []*campaigns.ChangesetEvent
+		cs     []*campaigns.Changeset
+	)
+
+	for _, s := range bySource {
+		for _, c := range s.Changesets {
+			if c.Changeset.ExternalState != campaigns.ChangesetStateOpen {
+				continue
+			}
+
+			campaign, err := autoMergeCampaign(ctx, store, c.Changeset)
+			if err != nil {
+				return err
+			}
+			if campaign == nil {
+				continue
+			}
+
+			ready, err := autoMergeReady(c.Changeset, c.Events(), campaign.AutoMergePolicy)
+			if err != nil {
+				log15.Warn("Computing changeset auto-merge readiness", "changeset", c.Changeset.ID, "err", err)
+				continue
+			}
+			if !ready {
+				continue
+			}
+
+			key := c.Changeset.ExternalUpdatedAt.UTC().Format(time.RFC3339Nano)
+			_, err = store.GetChangesetEvent(ctx, GetChangesetEventOpts{
+				ChangesetID: c.Changeset.ID,
+				Kind:        campaigns.ChangesetEventKindAutoMergeFailed,
+				Key:         key,
+			})
+			if err == nil {
+				// We already failed to merge this version of the changeset.
+				continue
+			}
+			if err != ErrNoResults {
+				return err
+			}
+
+			event := autoMerge(ctx, s, c, campaign, clock())
+			event.Key = key
+
+			events = append(events, c.Events()...)
+			events = append(events, event)
+			cs = append(cs, c.Changeset)
+		}
+	}
+
+	if len(cs) == 0 {
+		return nil
+	}
+
+	tx, err := store.Transact(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Done(&err)
+
+	if err = tx.UpdateChangesets(ctx, cs...); err != nil {
+		return err
+	}
+
+	return tx.UpsertChangesetEvents(ctx, events...)
+}
+
+// autoMerge merges the given changeset with the ChangesetSource of s and
+// returns the ChangesetEvent recording the outcome, including any error.
+func autoMerge(ctx context.Context, s *SourceChangesets, c *repos.Changeset, campaign *campaigns.Campaign, now time.Time) *campaigns.ChangesetEvent {
+	method := campaign.AutoMergePolicy.MergeMethod
+	if method == "" {
+		method = campaigns.MergeMethodMerge
+	}
+
+	meta := &campaigns.AutoMergeEvent{
+		CampaignID: campaign.ID,
+		Method:     method,
+		CreatedAt:  now,
+	}
+	kind := campaigns.ChangesetEventKindAutoMerged
+
+	if err := s.MergeChangeset(ctx, c, method); err != nil {
+		log15.Warn("Auto-merging changeset", "changeset", c.Changeset.ID, "campaign", campaign.ID, "err", err)
+		meta.Error = err.Error()
+		kind = campaigns.ChangesetEventKindAutoMergeFailed
+	} else {
+		// The merged metadata is newer than any of the events.
+		c.Changeset.UpdatedAt = now
+		SetDerivedState(c.Changeset, c.Events())
+	}
+
+	return &campaigns.ChangesetEvent{
+		ChangesetID: c.Changeset.ID,
+		Kind:        kind,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+		Metadata:    meta,
+	}
+}
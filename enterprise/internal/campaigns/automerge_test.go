@@ -0,0 +1,187 @@
+package campaigns
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/sourcegraph/sourcegraph/cmd/repo-updater/repos"
+	cmpgn "github.com/sourcegraph/sourcegraph/internal/campaigns"
+	"github.com/sourcegraph/sourcegraph/internal/extsvc/bitbucketserver"
+	"github.com/sourcegraph/sourcegraph/internal/extsvc/github"
+)
+
+func TestAutoMergeReady(t *testing.T) {
+	now := time.Now().UTC().Truncate(time.Microsecond)
+	daysAgo := func(days int) time.Time { return now.AddDate(0, 0, -days) }
+
+	ghChangeset := func(check cmpgn.ChangesetCheckState, review cmpgn.ChangesetReviewState) *cmpgn.Changeset {
+		return &cmpgn.Changeset{
+			ExternalServiceType: github.ServiceType,
+			ExternalState:       cmpgn.ChangesetStateOpen,
+			ExternalCheckState:  check,
+			ExternalReviewState: review,
+			UpdatedAt:           daysAgo(1),
+			Metadata:            &github.PullRequest{},
+		}
+	}
+	ghReview := func(t time.Time, login, state string) *cmpgn.ChangesetEvent {
+		return &cmpgn.ChangesetEvent{
+			Kind: cmpgn.ChangesetEventKindGitHubReviewed,
+			Metadata: &github.PullRequestReview{
+				UpdatedAt: t,
+				State:     state,
+				Author:    github.Actor{Login: login},
+			},
+		}
+	}
+	bbsChangeset := func(statuses ...string) *cmpgn.Changeset {
+		// Reviewers is a slice of anonymous structs, so we unmarshal them.
+		var reviewers []map[string]string
+		for _, s := range statuses {
+			reviewers = append(reviewers, map[string]string{"status": s})
+		}
+		data, err := json.Marshal(map[string]interface{}{"reviewers": reviewers})
+		if err != nil {
+			t.Fatal(err)
+		}
+		pr := &bitbucketserver.PullRequest{}
+		if err := json.Unmarshal(data, pr); err != nil {
+			t.Fatal(err)
+		}
+		return &cmpgn.Changeset{
+			ExternalServiceType: bitbucketserver.ServiceType,
+			ExternalState:       cmpgn.ChangesetStateOpen,
+			ExternalCheckState:  cmpgn.ChangesetCheckStatePassed,
+			ExternalReviewState: cmpgn.ChangesetReviewStateApproved,
+			UpdatedAt:           now,
+			Metadata:            pr,
+		}
+	}
+
+	tests := []struct {
+		name      string
+		changeset *cmpgn.Changeset
+		events    []*cmpgn.ChangesetEvent
+		approvals int32
+		want      bool
+	}{
+		{
+			name:      "approved by enough distinct reviewers",
+			changeset: ghChangeset(cmpgn.ChangesetCheckStatePassed, cmpgn.ChangesetReviewStateApproved),
+			events: []*cmpgn.ChangesetEvent{
+				ghReview(daysAgo(0), "user1", "APPROVED"),
+				ghReview(daysAgo(2), "user2", "APPROVED"),
+				ghReview(daysAgo(0), "user2", "COMMENTED"),
+			},
+			approvals: 2,
+			want:      true,
+		},
+		{
+			name:      "repeated approvals of the same reviewer",
+			changeset: ghChangeset(cmpgn.ChangesetCheckStatePassed, cmpgn.ChangesetReviewStateApproved),
+			events: []*cmpgn.ChangesetEvent{
+				ghReview(daysAgo(1), "user1", "APPROVED"),
+				ghReview(daysAgo(0), "user1", "APPROVED"),
+			},
+			approvals: 2,
+			want:      false,
+		},
+		{
+			name:      "no approvals required",
+			changeset: ghChangeset(cmpgn.ChangesetCheckStatePassed, cmpgn.ChangesetReviewStatePending),
+			approvals: 0,
+			want:      true,
+		},
+		{
+			name:      "checks pending",
+			changeset: ghChangeset(cmpgn.ChangesetCheckStatePending, cmpgn.ChangesetReviewStateApproved),
+			events: []*cmpgn.ChangesetEvent{
+				ghReview(daysAgo(0), "user1", "APPROVED"),
+			},
+			approvals: 1,
+			want:      false,
+		},
+		{
+			name:      "no checks",
+			changeset: ghChangeset(cmpgn.ChangesetCheckStateUnknown, cmpgn.ChangesetReviewStateApproved),
+			events: []*cmpgn.ChangesetEvent{
+				ghReview(daysAgo(0), "user1", "APPROVED"),
+			},
+			approvals: 1,
+			want:      false,
+		},
+		{
+			name:      "changes requested",
+			changeset: ghChangeset(cmpgn.ChangesetCheckStatePassed, cmpgn.ChangesetReviewStateChangesRequested),
+			events: []*cmpgn.ChangesetEvent{
+				ghReview(daysAgo(0), "user1", "APPROVED"),
+				ghReview(daysAgo(0), "user2", "CHANGES_REQUESTED"),
+			},
+			approvals: 1,
+			want:      false,
+		},
+		{
+			name:      "bitbucket server reviewers",
+			changeset: bbsChangeset("APPROVED", "UNAPPROVED", "APPROVED"),
+			approvals: 2,
+			want:      true,
+		},
+		{
+			name:      "bitbucket server not enough reviewers",
+			changeset: bbsChangeset("APPROVED", "UNAPPROVED"),
+			approvals: 2,
+			want:      false,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			have, err := autoMergeReady(tc.changeset, tc.events, &cmpgn.AutoMergePolicy{RequiredApprovals: tc.approvals})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if have != tc.want {
+				t.Errorf("have ready %t, want %t", have, tc.want)
+			}
+		})
+	}
+}
+
+func TestAutoMerge_Failed(t *testing.T) {
+	now := time.Now().UTC().Truncate(time.Microsecond)
+
+	s := &SourceChangesets{ChangesetSource: fakeChangesetSource{}}
+	c := &repos.Changeset{Changeset: &cmpgn.Changeset{
+		ID:            1,
+		ExternalState: cmpgn.ChangesetStateOpen,
+		Metadata:      &github.PullRequest{},
+	}}
+	campaign := &cmpgn.Campaign{
+		ID:              2,
+		AutoMergePolicy: &cmpgn.AutoMergePolicy{RequiredApprovals: 1},
+	}
+
+	have := autoMerge(context.Background(), s, c, campaign, now)
+	want := &cmpgn.ChangesetEvent{
+		ChangesetID: 1,
+		Kind:        cmpgn.ChangesetEventKindAutoMergeFailed,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+		Metadata: &cmpgn.AutoMergeEvent{
+			CampaignID: 2,
+			Method:     cmpgn.MergeMethodMerge,
+			Error:      fakeNotImplemented.Error(),
+			CreatedAt:  now,
+		},
+	}
+	if diff := cmp.Diff(want, have); diff != "" {
+		t.Fatalf("unexpected event (-want +got):\n%s", diff)
+	}
+
+	if have, want := c.Changeset.ExternalState, cmpgn.ChangesetStateOpen; have != want {
+		t.Errorf("have state %q, want %q", have, want)
+	}
+}
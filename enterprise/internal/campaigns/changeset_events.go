@@ -23,19 +23,40 @@ func (ce ChangesetEvents) Less(i, j int) bool {
 // slice.
 // It should only be called by ComputeChangesetReviewState.
 func (ce ChangesetEvents) reviewState() (cmpgn.ChangesetReviewState, error) {
+	reviewsByAuthor, err := ce.reviewsByAuthor()
+	if err != nil {
+		return "", err
+	}
+	return computeReviewState(reviewsByAuthor), nil
+}
+
+// approvals returns the number of distinct authors whose latest review in the
+// slice is an approval.
+// It should only be called by ComputeApprovals.
+func (ce ChangesetEvents) approvals() (int, error) {
+	reviewsByAuthor, err := ce.reviewsByAuthor()
+	if err != nil {
+		return 0, err
+	}
+	return countApprovals(reviewsByAuthor), nil
+}
+
+// reviewsByAuthor returns the latest approving or change-requesting review
+// state of each review author in the slice.
+func (ce ChangesetEvents) reviewsByAuthor() (map[string]cmpgn.ChangesetReviewState, error) {
 	reviewsByAuthor := map[string]cmpgn.ChangesetReviewState{}
 
 	for _, e := range ce {
 		author, err := e.ReviewAuthor()
 		if err != nil {
-			return "", err
+			return nil, err
 		}
 		if author == "" {
 			continue
 		}
 		s, err := e.ReviewState()
 		if err != nil {
-			return "", err
+			return nil, err
 		}
 
 		switch s {
@@ -47,7 +68,7 @@ func (ce ChangesetEvents) reviewState() (cmpgn.ChangesetReviewState, error) {
 		}
 	}
 
-	return computeReviewState(reviewsByAuthor), nil
+	return reviewsByAuthor, nil
 }
 
 // State returns the  state of the changeset to which the events belong and assumes the events
@@ -0,0 +1,276 @@
+package campaigns
+
+import (
+	"bufio"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/go-multierror"
+	"github.com/inconshreveable/log15"
+	"github.com/opentracing/opentracing-go/log"
+	"github.com/pkg/errors"
+	replacerprotocol "github.com/sourcegraph/sourcegraph/cmd/replacer/protocol"
+	"github.com/sourcegraph/sourcegraph/cmd/repo-updater/repos"
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/campaigns"
+	"github.com/sourcegraph/sourcegraph/internal/httpcli"
+	"github.com/sourcegraph/sourcegraph/internal/trace"
+)
+
+// A Replacer rewrites the files of a repository at a given commit.
+type Replacer interface {
+	// Replace returns the diffs of the files of the repository in req that
+	// are changed by the rewrite in req.
+	Replace(ctx context.Context, req replacerprotocol.Request) ([]ReplacerResult, error)
+}
+
+// A ReplacerResult is the rewrite of a single file, as returned by the
+// replacer service.
+type ReplacerResult struct {
+	URI  string `json:"uri"`
+	Diff string `json:"diff"`
+}
+
+// ReplacerClient is a Replacer that calls the replacer service.
+type ReplacerClient struct {
+	// URL is the URL of the replacer service.
+	URL string
+	// HTTPClient is used to make requests to the replacer service.
+	HTTPClient httpcli.Doer
+}
+
+// NewReplacerClient returns a ReplacerClient for the replacer service at the
+// given URL. If cli is nil, http.DefaultClient is used.
+func NewReplacerClient(replacerURL string, cli httpcli.Doer) *ReplacerClient {
+	if cli == nil {
+		cli = http.DefaultClient
+	}
+	return &ReplacerClient{URL: replacerURL, HTTPClient: cli}
+}
+
+// Replace implements Replacer.
+func (c *ReplacerClient) Replace(ctx context.Context, req replacerprotocol.Request) (results []ReplacerResult, err error) {
+	u, err := url.Parse(c.URL)
+	if err != nil {
+		return nil, err
+	}
+	q := u.Query()
+	q.Set("repo", string(req.Repo))
+	q.Set("commit", string(req.Commit))
+	q.Set("matchtemplate", req.MatchTemplate)
+	q.Set("rewritetemplate", req.RewriteTemplate)
+	q.Set("fileextension", req.FileExtension)
+	q.Set("directoryexclude", req.DirectoryExclude)
+	if req.FetchTimeout != "" {
+		q.Set("fetchtimeout", req.FetchTimeout)
+	}
+	u.RawQuery = q.Encode()
+
+	httpReq, err := http.NewRequest("GET", u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.HTTPClient.Do(httpReq.WithContext(ctx))
+	if err != nil {
+		if ctx.Err() != nil {
+			err = ctx.Err()
+		}
+		return nil, errors.Wrap(err, "replacer request failed")
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, err := ioutil.ReadAll(resp.Body)
+		if err != nil {
+			return nil, err
+		}
+		return nil, errors.Errorf("replacer request failed with status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	// Results are line encoded JSON. A single file can produce a long diff,
+	// so we allow lines of up to 10 * 64K.
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 100), 10*bufio.MaxScanTokenSize)
+	for scanner.Scan() {
+		var r ReplacerResult
+		if err := json.Unmarshal(scanner.Bytes(), &r); err != nil {
+			return nil, errors.Wrap(err, "decoding replacer result")
+		}
+		results = append(results, r)
+	}
+	return results, scanner.Err()
+}
+
+// UnifiedDiff combines the given replacer results into a single unified diff
+// of the repository, without the a/ and b/ filename prefixes. Files are sorted
+// by path, so that the diff of the same rewrite is stable.
+func UnifiedDiff(results []ReplacerResult) (string, error) {
+	sorted := make([]ReplacerResult, len(results))
+	copy(sorted, results)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].URI < sorted[j].URI })
+
+	var b strings.Builder
+	for _, r := range sorted {
+		i := strings.Index(r.Diff, "@@")
+		if i < 0 {
+			return "", errors.Errorf("invalid diff for %q does not contain expected @@", r.URI)
+		}
+		hunks := r.Diff[i:]
+
+		fmt.Fprintf(&b, "--- %s\n+++ %s\n", r.URI, r.URI)
+		b.WriteString(hunks)
+		if !strings.HasSuffix(hunks, "\n") {
+			b.WriteByte('\n')
+		}
+	}
+	// Patches are stored without a trailing newline, which is added back when
+	// they are applied.
+	return strings.TrimSuffix(b.String(), "\n"), nil
+}
+
+// maxPatchJobWorkers defines the maximum number of patch jobs to run in
+// parallel. It shares CAMPAIGNS_MAX_WORKERS with the changeset job workers.
+func maxPatchJobWorkers() int {
+	workerCount, err := strconv.Atoi(maxWorkers)
+	if err != nil {
+		log15.Error("Parsing max worker count failed. Falling back to default.", "default", defaultWorkerCount, "err", err)
+		return defaultWorkerCount
+	}
+	return workerCount
+}
+
+// RunPatchJobWorkers should be executed in a background goroutine and is
+// responsible for finding pending PatchJobs and executing them.
+// ctx should be canceled to terminate the function.
+func RunPatchJobWorkers(ctx context.Context, s *Store, clock func() time.Time, replacer Replacer, backoffDuration time.Duration) {
+	process := func(ctx context.Context, s *Store, job campaigns.PatchJob) error {
+		patchSet, err := s.GetPatchSet(ctx, GetPatchSetOpts{ID: job.PatchSetID})
+		if err != nil {
+			return errors.Wrap(err, "getting patch set")
+		}
+
+		if runErr := ExecPatchJob(ctx, clock, s, replacer, patchSet, &job); runErr != nil {
+			log15.Error("ExecPatchJob", "jobID", job.ID, "err", runErr)
+		}
+		// We don't assign to err here so that we don't roll back the transaction
+		// ExecPatchJob will save the error in the job row
+		return nil
+	}
+	worker := func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			default:
+				didRun, err := s.ProcessPendingPatchJobs(context.Background(), process)
+				if err != nil {
+					log15.Error("Running patch job", "err", err)
+				}
+				// Back off on error or when no jobs available
+				if err != nil || !didRun {
+					time.Sleep(backoffDuration)
+				}
+			}
+		}
+	}
+	for i := 0; i < maxPatchJobWorkers(); i++ {
+		go worker()
+	}
+}
+
+// replacerFetchTimeout is how long the replacer waits for the archive of a
+// repository. Patch jobs run in the background, so we rather wait than fail.
+const replacerFetchTimeout = "2m"
+
+// ExecPatchJob applies the rewrite of the given PatchSet to the repository of
+// the given PatchJob and stores the result as a Patch of the PatchSet. No
+// Patch is created if the rewrite doesn't change any files. The outcome of the
+// job, including any error, is saved in the job.
+func ExecPatchJob(
+	ctx context.Context,
+	clock func() time.Time,
+	store *Store,
+	replacer Replacer,
+	patchSet *campaigns.PatchSet,
+	job *campaigns.PatchJob,
+) (err error) {
+	tr, ctx := trace.New(ctx, "service.ExecPatchJob", fmt.Sprintf("job_id: %d", job.ID))
+	defer func() {
+		tr.SetError(err)
+		tr.Finish()
+	}()
+	tr.LogFields(log.Int64("job_id", job.ID), log.Int64("patch_set_id", patchSet.ID))
+
+	defer func() {
+		if err != nil {
+			job.Error = err.Error()
+		}
+		job.FinishedAt = clock()
+
+		if e := store.UpdatePatchJob(ctx, job); e != nil {
+			if err == nil {
+				err = e
+			} else {
+				err = multierror.Append(err, e)
+			}
+		}
+	}()
+
+	job.StartedAt = clock()
+
+	if patchSet.Rewrite == nil {
+		return errors.Errorf("patch set %d has no rewrite specification", patchSet.ID)
+	}
+
+	reposStore := repos.NewDBStore(store.DB(), sql.TxOptions{})
+	rs, err := reposStore.ListRepos(ctx, repos.StoreListReposArgs{IDs: []api.RepoID{job.RepoID}})
+	if err != nil {
+		return err
+	}
+	if len(rs) != 1 {
+		return errors.Errorf("repo not found: %d", job.RepoID)
+	}
+	repo := rs[0]
+
+	results, err := replacer.Replace(ctx, replacerprotocol.Request{
+		Repo:                 api.RepoName(repo.Name),
+		Commit:               job.Rev,
+		FetchTimeout:         replacerFetchTimeout,
+		RewriteSpecification: *patchSet.Rewrite,
+	})
+	if err != nil {
+		return errors.Wrapf(err, "rewriting repository %q", repo.Name)
+	}
+	if len(results) == 0 {
+		return nil
+	}
+
+	diff, err := UnifiedDiff(results)
+	if err != nil {
+		return errors.Wrapf(err, "rewriting repository %q", repo.Name)
+	}
+
+	patch := &campaigns.Patch{
+		PatchSetID: patchSet.ID,
+		RepoID:     job.RepoID,
+		Rev:        job.Rev,
+		BaseRef:    job.BaseRef,
+		Diff:       diff,
+	}
+	if err = store.CreatePatch(ctx, patch); err != nil {
+		return err
+	}
+
+	job.PatchID = patch.ID
+	return nil
+}
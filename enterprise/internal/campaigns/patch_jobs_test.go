@@ -0,0 +1,116 @@
+package campaigns
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/sourcegraph/sourcegraph/cmd/replacer/protocol"
+)
+
+func TestReplacerClient(t *testing.T) {
+	var query url.Values
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.Query()
+		fmt.Fprintln(w, `{"uri":"a.go","diff":"--- a.go\n+++ a.go\n@@ -1,1 +1,1 @@\n-foo\n+bar"}`)
+		fmt.Fprintln(w, `{"uri":"b/b.go","diff":"--- b/b.go\n+++ b/b.go\n@@ -2,1 +2,1 @@\n-foo()\n+bar()"}`)
+	}))
+	defer ts.Close()
+
+	cli := NewReplacerClient(ts.URL, nil)
+	results, err := cli.Replace(context.Background(), protocol.Request{
+		Repo:   "github.com/sourcegraph/sourcegraph",
+		Commit: "deadbeef",
+		RewriteSpecification: protocol.RewriteSpecification{
+			MatchTemplate:    "foo",
+			RewriteTemplate:  "bar",
+			FileExtension:    ".go",
+			DirectoryExclude: "vendor",
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wantQuery := url.Values{
+		"repo":             {"github.com/sourcegraph/sourcegraph"},
+		"commit":           {"deadbeef"},
+		"matchtemplate":    {"foo"},
+		"rewritetemplate":  {"bar"},
+		"fileextension":    {".go"},
+		"directoryexclude": {"vendor"},
+	}
+	if diff := cmp.Diff(wantQuery, query); diff != "" {
+		t.Errorf("unexpected query (-want +got):\n%s", diff)
+	}
+
+	want := []ReplacerResult{
+		{URI: "a.go", Diff: "--- a.go\n+++ a.go\n@@ -1,1 +1,1 @@\n-foo\n+bar"},
+		{URI: "b/b.go", Diff: "--- b/b.go\n+++ b/b.go\n@@ -2,1 +2,1 @@\n-foo()\n+bar()"},
+	}
+	if diff := cmp.Diff(want, results); diff != "" {
+		t.Errorf("unexpected results (-want +got):\n%s", diff)
+	}
+}
+
+func TestReplacerClient_Error(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "failed to decode form", http.StatusBadRequest)
+	}))
+	defer ts.Close()
+
+	_, err := NewReplacerClient(ts.URL, nil).Replace(context.Background(), protocol.Request{})
+	if have, want := fmt.Sprint(err), "replacer request failed with status 400: failed to decode form"; have != want {
+		t.Errorf("have error %q, want %q", have, want)
+	}
+}
+
+func TestUnifiedDiff(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		results []ReplacerResult
+		want    string
+		wantErr string
+	}{
+		{
+			name: "sorted by path",
+			results: []ReplacerResult{
+				{URI: "b.go", Diff: "--- b.go\n+++ b.go\n@@ -1,1 +1,1 @@\n-b\n+c\n"},
+				{URI: "a.go", Diff: "--- a.go\n+++ a.go\n@@ -1,1 +1,1 @@\n-a\n+b"},
+			},
+			want: "--- a.go\n+++ a.go\n@@ -1,1 +1,1 @@\n-a\n+b\n" +
+				"--- b.go\n+++ b.go\n@@ -1,1 +1,1 @@\n-b\n+c",
+		},
+		{
+			name: "prefixed file names",
+			results: []ReplacerResult{
+				{URI: "dir/a.go", Diff: "--- a/dir/a.go\n+++ b/dir/a.go\n@@ -1,1 +1,1 @@\n-a\n+b"},
+			},
+			want: "--- dir/a.go\n+++ dir/a.go\n@@ -1,1 +1,1 @@\n-a\n+b",
+		},
+		{
+			name: "no hunks",
+			results: []ReplacerResult{
+				{URI: "a.go", Diff: "--- a.go\n+++ a.go\n"},
+			},
+			wantErr: `invalid diff for "a.go" does not contain expected @@`,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			have, err := UnifiedDiff(tc.results)
+			if have, want := fmt.Sprint(err), fmt.Sprint(tc.wantErr); tc.wantErr != "" && have != want {
+				t.Fatalf("have error %q, want %q", have, want)
+			} else if tc.wantErr == "" && err != nil {
+				t.Fatal(err)
+			}
+
+			if diff := cmp.Diff(tc.want, have); diff != "" {
+				t.Errorf("unexpected diff (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -284,6 +284,119 @@ func (r *campaignResolver) Status(ctx context.Context) (graphqlbackend.Backgroun
 	return r.store.GetCampaignStatus(ctx, r.Campaign.ID)
 }
 
+func (r *campaignResolver) Rollout(ctx context.Context) (graphqlbackend.CampaignRolloutResolver, error) {
+	queued, err := r.store.CountChangesetJobs(ctx, ee.CountChangesetJobsOpts{
+		CampaignID: r.Campaign.ID,
+		OnlyQueued: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	queue, err := ee.GetRolloutQueue(ctx, r.store, r.Campaign, r.store.Clock()())
+	if err != nil {
+		return nil, err
+	}
+
+	return &campaignRolloutResolver{
+		policy: r.Campaign.RolloutPolicy,
+		paused: r.Campaign.RolloutPaused,
+		queued: queued,
+		queue:  queue,
+	}, nil
+}
+
+func (r *campaignResolver) AutoMerge() graphqlbackend.CampaignAutoMergeResolver {
+	if r.Campaign.AutoMergePolicy == nil {
+		return nil
+	}
+	return &campaignAutoMergeResolver{policy: r.Campaign.AutoMergePolicy}
+}
+
+var _ graphqlbackend.CampaignAutoMergeResolver = &campaignAutoMergeResolver{}
+
+type campaignAutoMergeResolver struct {
+	policy *campaigns.AutoMergePolicy
+}
+
+func (r *campaignAutoMergeResolver) RequiredApprovals() int32 {
+	return r.policy.RequiredApprovals
+}
+
+func (r *campaignAutoMergeResolver) MergeMethod() string {
+	if r.policy.MergeMethod == "" {
+		return string(campaigns.MergeMethodMerge)
+	}
+	return string(r.policy.MergeMethod)
+}
+
+var _ graphqlbackend.CampaignRolloutResolver = &campaignRolloutResolver{}
+
+type campaignRolloutResolver struct {
+	policy *campaigns.RolloutPolicy
+	paused bool
+	queued int64
+	queue  *ee.RolloutQueue
+}
+
+func (r *campaignRolloutResolver) ChangesetsPerWindow() *int32 {
+	if r.policy == nil {
+		return nil
+	}
+	return &r.policy.Rate
+}
+
+func (r *campaignRolloutResolver) WindowSeconds() *int32 {
+	if r.policy == nil {
+		return nil
+	}
+	seconds := int32(r.policy.Window / time.Second)
+	return &seconds
+}
+
+func (r *campaignRolloutResolver) Batches(ctx context.Context) ([][]*graphqlbackend.RepositoryResolver, error) {
+	if r.policy == nil {
+		return [][]*graphqlbackend.RepositoryResolver{}, nil
+	}
+
+	batches := make([][]*graphqlbackend.RepositoryResolver, 0, len(r.policy.Batches))
+	for _, batch := range r.policy.Batches {
+		repos := make([]*graphqlbackend.RepositoryResolver, 0, len(batch))
+		for _, id := range batch {
+			repo, err := graphqlbackend.RepositoryByIDInt32(ctx, id)
+			if err != nil {
+				return nil, err
+			}
+			repos = append(repos, repo)
+		}
+		batches = append(batches, repos)
+	}
+	return batches, nil
+}
+
+func (r *campaignRolloutResolver) Paused() bool {
+	return r.paused
+}
+
+func (r *campaignRolloutResolver) QueuedCount() int32 {
+	return int32(r.queued)
+}
+
+func (r *campaignRolloutResolver) CurrentBatch() *int32 {
+	if r.policy == nil || r.queue.CurrentBatch >= len(r.policy.Batches) {
+		return nil
+	}
+	current := int32(r.queue.CurrentBatch)
+	return &current
+}
+
+func (r *campaignRolloutResolver) NextReleaseAt() *graphqlbackend.DateTime {
+	if r.queue.NextReleaseAt.IsZero() {
+		return nil
+	}
+	return &graphqlbackend.DateTime{Time: r.queue.NextReleaseAt}
+}
+
 type changesetDiffsConnectionResolver struct {
 	*changesetsConnectionResolver
 }
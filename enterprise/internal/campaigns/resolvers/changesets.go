@@ -113,6 +113,12 @@ func (r *changesetsConnectionResolver) compute(ctx context.Context) ([]*campaign
 			r.err = err
 			return
 		}
+		// 🚨 SECURITY: Access tokens that are restricted to other repositories may not be
+		// used to view these repositories.
+		if err := ee.CheckTokenRepos(ctx, rs); err != nil {
+			r.err = err
+			return
+		}
 
 		r.reposByID = make(map[api.RepoID]*repos.Repo, len(rs))
 		for _, repo := range rs {
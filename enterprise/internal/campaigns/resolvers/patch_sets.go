@@ -11,14 +11,17 @@ import (
 
 	"github.com/graph-gophers/graphql-go"
 	"github.com/graph-gophers/graphql-go/relay"
+	"github.com/pkg/errors"
 	"github.com/sourcegraph/go-diff/diff"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/globals"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/graphqlbackend"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/graphqlbackend/graphqlutil"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
 	"github.com/sourcegraph/sourcegraph/cmd/repo-updater/repos"
 	ee "github.com/sourcegraph/sourcegraph/enterprise/internal/campaigns"
 	"github.com/sourcegraph/sourcegraph/internal/api"
 	"github.com/sourcegraph/sourcegraph/internal/campaigns"
+	"github.com/sourcegraph/sourcegraph/internal/search/query"
 )
 
 const patchSetIDKind = "PatchSet"
@@ -76,6 +79,77 @@ func (r *patchSetResolver) PreviewURL() string {
 	return u.String()
 }
 
+func (r *patchSetResolver) Status(ctx context.Context) (graphqlbackend.BackgroundProcessStatus, error) {
+	return r.store.GetPatchSetStatus(ctx, r.patchSet.ID)
+}
+
+// maxRewriteSearchResults is the number of search results fetched to find
+// the repositories that a patch set rewrite is applied to, unless the query
+// specifies its own count.
+const maxRewriteSearchResults = 10000
+
+// searchPatchJobs returns a PatchJob for each repository with a result for
+// the given search query, at the tip of the repository's default branch.
+// Repositories that are empty or still being cloned are skipped.
+func searchPatchJobs(ctx context.Context, q string) ([]*campaigns.PatchJob, error) {
+	if info, err := query.ParseAndCheck(q); err == nil && len(info.Values(query.FieldCount)) == 0 {
+		q = fmt.Sprintf("%s count:%d", q, maxRewriteSearchResults)
+	}
+
+	search, err := graphqlbackend.NewSearchImplementer(&graphqlbackend.SearchArgs{Version: "V2", Query: q})
+	if err != nil {
+		return nil, err
+	}
+	results, err := search.Results(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var (
+		seen = map[api.RepoID]bool{}
+		jobs []*campaigns.PatchJob
+	)
+	for _, result := range results.Results() {
+		var repo *types.Repo
+		if fm, ok := result.ToFileMatch(); ok {
+			repo = fm.Repo
+		} else if r, ok := result.ToRepository(); ok {
+			repo = r.Type()
+		} else if c, ok := result.ToCommitSearchResult(); ok {
+			repo = c.Commit().Repository().Type()
+		}
+		if repo == nil || seen[repo.ID] {
+			continue
+		}
+		seen[repo.ID] = true
+
+		ref, err := graphqlbackend.NewRepositoryResolver(repo).DefaultBranch(ctx)
+		if err != nil {
+			return nil, errors.Wrapf(err, "resolving default branch of repository %q", repo.Name)
+		}
+		if ref == nil {
+			continue
+		}
+		oid, err := ref.Target().OID(ctx)
+		if err != nil {
+			return nil, errors.Wrapf(err, "resolving default branch of repository %q", repo.Name)
+		}
+
+		jobs = append(jobs, &campaigns.PatchJob{
+			RepoID:  repo.ID,
+			Rev:     api.CommitID(oid),
+			BaseRef: ref.Name(),
+		})
+	}
+
+	if len(jobs) == 0 {
+		if alert := results.Alert(); alert != nil {
+			return nil, errors.Errorf("search query %q: %s", q, alert.Title())
+		}
+		return nil, errors.Errorf("search query %q did not match any repositories", q)
+	}
+	return jobs, nil
+}
+
 type patchesConnectionResolver struct {
 	store *ee.Store
 	opts  ee.ListPatchesOpts
@@ -139,6 +213,12 @@ func (r *patchesConnectionResolver) compute(ctx context.Context) ([]*campaigns.P
 			r.err = err
 			return
 		}
+		// 🚨 SECURITY: Access tokens that are restricted to other repositories may not be
+		// used to view these repositories.
+		if err := ee.CheckTokenRepos(ctx, rs); err != nil {
+			r.err = err
+			return
+		}
 
 		r.reposByID = make(map[api.RepoID]*repos.Repo, len(rs))
 		for _, repo := range rs {
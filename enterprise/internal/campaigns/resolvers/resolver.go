@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"io"
 	"strings"
+	"time"
 
 	"github.com/graph-gophers/graphql-go"
 	"github.com/graph-gophers/graphql-go/relay"
@@ -15,6 +16,7 @@ import (
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/backend"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/graphqlbackend"
+	replacerprotocol "github.com/sourcegraph/sourcegraph/cmd/replacer/protocol"
 	"github.com/sourcegraph/sourcegraph/cmd/repo-updater/repos"
 	ee "github.com/sourcegraph/sourcegraph/enterprise/internal/campaigns"
 	"github.com/sourcegraph/sourcegraph/internal/api"
@@ -191,6 +193,20 @@ func (r *Resolver) AddChangesetsToCampaign(ctx context.Context, args *graphqlbac
 		return nil, errors.Errorf("changesets %v not found", set)
 	}
 
+	// 🚨 SECURITY: Access tokens that are restricted to other repositories may not be used to
+	// add changesets in these repositories.
+	repoIDs := make([]api.RepoID, len(changesets))
+	for i, c := range changesets {
+		repoIDs[i] = c.RepoID
+	}
+	rs, err := repos.NewDBStore(tx.DB(), sql.TxOptions{}).ListRepos(ctx, repos.StoreListReposArgs{IDs: repoIDs})
+	if err != nil {
+		return nil, err
+	}
+	if err = ee.CheckTokenRepos(ctx, rs); err != nil {
+		return nil, err
+	}
+
 	if err = tx.UpdateChangesets(ctx, changesets...); err != nil {
 		return nil, err
 	}
@@ -245,6 +261,17 @@ func (r *Resolver) CreateCampaign(ctx context.Context, args *graphqlbackend.Crea
 		draft = *args.Input.Draft
 	}
 
+	if args.Input.Rollout != nil {
+		campaign.RolloutPolicy, err = unmarshalRolloutPolicy(args.Input.Rollout)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if args.Input.AutoMerge != nil {
+		campaign.AutoMergePolicy = unmarshalAutoMergePolicy(args.Input.AutoMerge)
+	}
+
 	switch relay.UnmarshalKind(args.Input.Namespace) {
 	case "User":
 		err = relay.UnmarshalSpec(args.Input.Namespace, &campaign.NamespaceUserID)
@@ -431,6 +458,12 @@ func (r *Resolver) CreateChangesets(ctx context.Context, args *graphqlbackend.Cr
 		return nil, err
 	}
 
+	// 🚨 SECURITY: Access tokens that are restricted to other repositories may not be used to
+	// create changesets in these repositories.
+	if err = ee.CheckTokenRepos(ctx, rs); err != nil {
+		return nil, err
+	}
+
 	for _, r := range rs {
 		if !campaigns.IsRepoSupported(&r.ExternalRepo) {
 			err = errors.Errorf(
@@ -586,6 +619,52 @@ func (r *Resolver) CreatePatchSetFromPatches(ctx context.Context, args graphqlba
 	return &patchSetResolver{store: r.store, patchSet: patchSet}, nil
 }
 
+func (r *Resolver) CreatePatchSetFromRewrite(ctx context.Context, args graphqlbackend.CreatePatchSetFromRewriteArgs) (graphqlbackend.PatchSetResolver, error) {
+	var err error
+	tr, ctx := trace.New(ctx, "Resolver.CreatePatchSetFromRewrite", fmt.Sprintf("Query: %q", args.Query))
+	defer func() {
+		tr.SetError(err)
+		tr.Finish()
+	}()
+
+	// 🚨 SECURITY: Only site admins may create patch sets for now.
+	if err := backend.CheckCurrentUserIsSiteAdmin(ctx); err != nil {
+		return nil, err
+	}
+
+	user, err := backend.CurrentUser(ctx)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%v", backend.ErrNotAuthenticated)
+	}
+	if user == nil {
+		return nil, backend.ErrNotAuthenticated
+	}
+
+	rewrite := &replacerprotocol.RewriteSpecification{
+		MatchTemplate:   args.Rewrite.MatchTemplate,
+		RewriteTemplate: args.Rewrite.RewriteTemplate,
+	}
+	if args.Rewrite.FileExtension != nil {
+		rewrite.FileExtension = *args.Rewrite.FileExtension
+	}
+	if args.Rewrite.DirectoryExclude != nil {
+		rewrite.DirectoryExclude = *args.Rewrite.DirectoryExclude
+	}
+
+	jobs, err := searchPatchJobs(ctx, args.Query)
+	if err != nil {
+		return nil, err
+	}
+
+	svc := ee.NewService(r.store, gitserver.DefaultClient, r.httpFactory)
+	patchSet, err := svc.CreatePatchSetFromRewrite(ctx, args.Query, rewrite, jobs, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &patchSetResolver{store: r.store, patchSet: patchSet}, nil
+}
+
 func (r *Resolver) CloseCampaign(ctx context.Context, args *graphqlbackend.CloseCampaignArgs) (_ graphqlbackend.CampaignResolver, err error) {
 	tr, ctx := trace.New(ctx, "Resolver.CloseCampaign", fmt.Sprintf("Campaign: %q", args.Campaign))
 	defer func() {
@@ -639,6 +718,136 @@ func (r *Resolver) PublishCampaign(ctx context.Context, args *graphqlbackend.Pub
 	return &campaignResolver{store: r.store, Campaign: campaign}, nil
 }
 
+func (r *Resolver) SetCampaignRollout(ctx context.Context, args *graphqlbackend.SetCampaignRolloutArgs) (_ graphqlbackend.CampaignResolver, err error) {
+	tr, ctx := trace.New(ctx, "Resolver.SetCampaignRollout", fmt.Sprintf("Campaign: %q", args.Campaign))
+	defer func() {
+		tr.SetError(err)
+		tr.Finish()
+	}()
+
+	// 🚨 SECURITY: Only site admins may update campaigns for now
+	if err := backend.CheckCurrentUserIsSiteAdmin(ctx); err != nil {
+		return nil, errors.Wrap(err, "checking if user is admin")
+	}
+
+	campaignID, err := unmarshalCampaignID(args.Campaign)
+	if err != nil {
+		return nil, errors.Wrap(err, "unmarshaling campaign id")
+	}
+
+	var policy *campaigns.RolloutPolicy
+	if args.Rollout != nil {
+		if policy, err = unmarshalRolloutPolicy(args.Rollout); err != nil {
+			return nil, err
+		}
+	}
+
+	svc := ee.NewService(r.store, gitserver.DefaultClient, r.httpFactory)
+	campaign, err := svc.SetCampaignRollout(ctx, campaignID, policy)
+	if err != nil {
+		return nil, errors.Wrap(err, "setting campaign rollout")
+	}
+
+	return &campaignResolver{store: r.store, Campaign: campaign}, nil
+}
+
+func (r *Resolver) PauseCampaignRollout(ctx context.Context, args *graphqlbackend.PauseCampaignRolloutArgs) (graphqlbackend.CampaignResolver, error) {
+	return r.setCampaignRolloutPaused(ctx, args.Campaign, true)
+}
+
+func (r *Resolver) ResumeCampaignRollout(ctx context.Context, args *graphqlbackend.ResumeCampaignRolloutArgs) (graphqlbackend.CampaignResolver, error) {
+	return r.setCampaignRolloutPaused(ctx, args.Campaign, false)
+}
+
+func (r *Resolver) setCampaignRolloutPaused(ctx context.Context, id graphql.ID, paused bool) (_ graphqlbackend.CampaignResolver, err error) {
+	tr, ctx := trace.New(ctx, "Resolver.setCampaignRolloutPaused", fmt.Sprintf("Campaign: %q, Paused: %t", id, paused))
+	defer func() {
+		tr.SetError(err)
+		tr.Finish()
+	}()
+
+	// 🚨 SECURITY: Only site admins may update campaigns for now
+	if err := backend.CheckCurrentUserIsSiteAdmin(ctx); err != nil {
+		return nil, errors.Wrap(err, "checking if user is admin")
+	}
+
+	campaignID, err := unmarshalCampaignID(id)
+	if err != nil {
+		return nil, errors.Wrap(err, "unmarshaling campaign id")
+	}
+
+	svc := ee.NewService(r.store, gitserver.DefaultClient, r.httpFactory)
+	campaign, err := svc.SetCampaignRolloutPaused(ctx, campaignID, paused)
+	if err != nil {
+		return nil, errors.Wrap(err, "updating campaign rollout")
+	}
+
+	return &campaignResolver{store: r.store, Campaign: campaign}, nil
+}
+
+func unmarshalRolloutPolicy(input *graphqlbackend.CampaignRolloutInput) (*campaigns.RolloutPolicy, error) {
+	policy := &campaigns.RolloutPolicy{
+		Rate:   input.ChangesetsPerWindow,
+		Window: time.Duration(input.WindowSeconds) * time.Second,
+	}
+
+	if input.Batches != nil {
+		policy.Batches = make([][]api.RepoID, 0, len(*input.Batches))
+		for _, batch := range *input.Batches {
+			ids := make([]api.RepoID, 0, len(batch))
+			for _, id := range batch {
+				repoID, err := graphqlbackend.UnmarshalRepositoryID(id)
+				if err != nil {
+					return nil, err
+				}
+				ids = append(ids, repoID)
+			}
+			policy.Batches = append(policy.Batches, ids)
+		}
+	}
+
+	return policy, nil
+}
+
+func (r *Resolver) SetCampaignAutoMerge(ctx context.Context, args *graphqlbackend.SetCampaignAutoMergeArgs) (_ graphqlbackend.CampaignResolver, err error) {
+	tr, ctx := trace.New(ctx, "Resolver.SetCampaignAutoMerge", fmt.Sprintf("Campaign: %q", args.Campaign))
+	defer func() {
+		tr.SetError(err)
+		tr.Finish()
+	}()
+
+	// 🚨 SECURITY: Only site admins may update campaigns for now
+	if err := backend.CheckCurrentUserIsSiteAdmin(ctx); err != nil {
+		return nil, errors.Wrap(err, "checking if user is admin")
+	}
+
+	campaignID, err := unmarshalCampaignID(args.Campaign)
+	if err != nil {
+		return nil, errors.Wrap(err, "unmarshaling campaign id")
+	}
+
+	var policy *campaigns.AutoMergePolicy
+	if args.AutoMerge != nil {
+		policy = unmarshalAutoMergePolicy(args.AutoMerge)
+	}
+
+	svc := ee.NewService(r.store, gitserver.DefaultClient, r.httpFactory)
+	campaign, err := svc.SetCampaignAutoMerge(ctx, campaignID, policy)
+	if err != nil {
+		return nil, errors.Wrap(err, "setting campaign auto-merge")
+	}
+
+	return &campaignResolver{store: r.store, Campaign: campaign}, nil
+}
+
+func unmarshalAutoMergePolicy(input *graphqlbackend.CampaignAutoMergeInput) *campaigns.AutoMergePolicy {
+	policy := &campaigns.AutoMergePolicy{RequiredApprovals: input.RequiredApprovals}
+	if input.MergeMethod != nil {
+		policy.MergeMethod = campaigns.MergeMethod(*input.MergeMethod)
+	}
+	return policy
+}
+
 func (r *Resolver) PublishChangeset(ctx context.Context, args *graphqlbackend.PublishChangesetArgs) (_ *graphqlbackend.EmptyResponse, err error) {
 	tr, ctx := trace.New(ctx, "Resolver.PublishChangeset", fmt.Sprintf("Patch: %q", args.Patch))
 	defer func() {
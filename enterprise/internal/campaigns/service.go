@@ -9,7 +9,9 @@ import (
 	"github.com/hashicorp/go-multierror"
 	"github.com/inconshreveable/log15"
 	"github.com/pkg/errors"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/authz"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/backend"
+	replacerprotocol "github.com/sourcegraph/sourcegraph/cmd/replacer/protocol"
 	"github.com/sourcegraph/sourcegraph/cmd/repo-updater/repos"
 	"github.com/sourcegraph/sourcegraph/internal/api"
 	"github.com/sourcegraph/sourcegraph/internal/campaigns"
@@ -62,6 +64,9 @@ func (s *Service) CreatePatchSetFromPatches(ctx context.Context, patches []*camp
 	if err != nil {
 		return nil, err
 	}
+	if err := CheckTokenRepos(ctx, allRepos); err != nil {
+		return nil, err
+	}
 	reposByID := make(map[api.RepoID]*repos.Repo, len(patches))
 	for _, repo := range allRepos {
 		reposByID[repo.ID] = repo
@@ -97,6 +102,122 @@ func (s *Service) CreatePatchSetFromPatches(ctx context.Context, patches []*camp
 	return patchSet, nil
 }
 
+// CreatePatchSetFromRewrite creates a PatchSet whose Patches are computed in
+// the background by applying the given rewrite to each of the given
+// repositories, which were matched by the given search query. Each of the
+// given PatchJobs must have its RepoID, Rev and BaseRef set.
+func (s *Service) CreatePatchSetFromRewrite(ctx context.Context, query string, rewrite *replacerprotocol.RewriteSpecification, jobs []*campaigns.PatchJob, userID int32) (*campaigns.PatchSet, error) {
+	if userID == 0 {
+		return nil, backend.ErrNotAuthenticated
+	}
+	if rewrite == nil || rewrite.MatchTemplate == "" {
+		return nil, ErrRewriteMatchTemplateBlank
+	}
+
+	// Look up all repositories
+	reposStore := repos.NewDBStore(s.store.DB(), sql.TxOptions{})
+	repoIDs := make([]api.RepoID, len(jobs))
+	for i, job := range jobs {
+		repoIDs[i] = job.RepoID
+	}
+	allRepos, err := reposStore.ListRepos(ctx, repos.StoreListReposArgs{IDs: repoIDs})
+	if err != nil {
+		return nil, err
+	}
+	if err := CheckTokenRepos(ctx, allRepos); err != nil {
+		return nil, err
+	}
+	reposByID := make(map[api.RepoID]*repos.Repo, len(jobs))
+	for _, repo := range allRepos {
+		reposByID[repo.ID] = repo
+	}
+
+	tx, err := s.store.Transact(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Done(&err)
+
+	patchSet := &campaigns.PatchSet{UserID: userID, Query: query, Rewrite: rewrite}
+	err = tx.CreatePatchSet(ctx, patchSet)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, job := range jobs {
+		repo := reposByID[job.RepoID]
+		if repo == nil {
+			return nil, fmt.Errorf("repository ID %d not found", job.RepoID)
+		}
+		if !campaigns.IsRepoSupported(&repo.ExternalRepo) {
+			continue
+		}
+
+		job.PatchSetID = patchSet.ID
+		if err := tx.CreatePatchJob(ctx, job); err != nil {
+			return nil, err
+		}
+	}
+
+	return patchSet, nil
+}
+
+// ErrRewriteMatchTemplateBlank is returned by CreatePatchSetFromRewrite if
+// the rewrite has no match template.
+var ErrRewriteMatchTemplateBlank = errors.New("rewrite match template cannot be blank")
+
+// ErrPatchSetProcessing is returned by CreateCampaign or UpdateCampaign if
+// the Patches of the specified patch set are still being computed.
+var ErrPatchSetProcessing = errors.New("cannot use a patch set whose patches are still being computed")
+
+// checkPatchSetFinished returns ErrPatchSetProcessing if the PatchJobs of the
+// PatchSet with the given ID have not all finished.
+func checkPatchSetFinished(ctx context.Context, store *Store, patchSetID int64) error {
+	status, err := store.GetPatchSetStatus(ctx, patchSetID)
+	if err != nil {
+		return err
+	}
+	if status.Processing() {
+		return ErrPatchSetProcessing
+	}
+	return nil
+}
+
+// CheckTokenRepos returns an error if the request is authenticated with an
+// access token that is restricted to repositories other than one of the given
+// repositories.
+func CheckTokenRepos(ctx context.Context, rs []*repos.Repo) error {
+	for _, r := range rs {
+		if err := authz.CheckTokenRepo(ctx, api.RepoName(r.Name)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkPatchSetTokenRepos returns an error if the request is authenticated
+// with an access token that is restricted to repositories other than one of
+// the repositories of the PatchSet's Patches.
+func checkPatchSetTokenRepos(ctx context.Context, store *Store, patchSetID int64) error {
+	if r := authz.TokenRestrictionsFromContext(ctx); r == nil || len(r.RepositoryPatterns) == 0 {
+		return nil
+	}
+
+	patches, _, err := store.ListPatches(ctx, ListPatchesOpts{PatchSetID: patchSetID, Limit: -1})
+	if err != nil {
+		return err
+	}
+	repoIDs := make([]api.RepoID, len(patches))
+	for i, p := range patches {
+		repoIDs[i] = p.RepoID
+	}
+	rs, err := repos.NewDBStore(store.DB(), sql.TxOptions{}).ListRepos(ctx, repos.StoreListReposArgs{IDs: repoIDs})
+	if err != nil {
+		return err
+	}
+	return CheckTokenRepos(ctx, rs)
+}
+
 // CreateCampaign creates the Campaign. When a PatchSetID is set on the
 // Campaign and the Campaign is not created as a draft, it calls
 // CreateChangesetJobs inside the same transaction in which it creates the
@@ -113,6 +234,14 @@ func (s *Service) CreateCampaign(ctx context.Context, c *campaigns.Campaign, dra
 		return ErrCampaignNameBlank
 	}
 
+	if err = validateRolloutPolicy(c.RolloutPolicy); err != nil {
+		return err
+	}
+
+	if err = validateAutoMergePolicy(c.AutoMergePolicy); err != nil {
+		return err
+	}
+
 	tx, err := s.store.Transact(ctx)
 	if err != nil {
 		return err
@@ -128,6 +257,14 @@ func (s *Service) CreateCampaign(ctx context.Context, c *campaigns.Campaign, dra
 			err = ErrPatchSetDuplicate
 			return err
 		}
+
+		if err = checkPatchSetFinished(ctx, tx, c.PatchSetID); err != nil {
+			return err
+		}
+
+		if err = checkPatchSetTokenRepos(ctx, tx, c.PatchSetID); err != nil {
+			return err
+		}
 	}
 
 	c.CreatedAt = s.clock()
@@ -227,6 +364,12 @@ func (s *Service) CloseCampaign(ctx context.Context, id int64, closeChangesets b
 			return nil
 		}
 
+		// Changesets that haven't been released by the rollout of the
+		// campaign yet won't be published anymore.
+		if err = tx.DeleteQueuedChangesetJobs(ctx, id); err != nil {
+			return err
+		}
+
 		campaign.ClosedAt = time.Now().UTC()
 
 		return tx.UpdateCampaign(ctx, campaign)
@@ -539,6 +682,14 @@ func (s *Service) UpdateCampaign(ctx context.Context, args UpdateCampaignArgs) (
 			return nil, nil, ErrPatchSetDuplicate
 		}
 
+		if err = checkPatchSetFinished(ctx, tx, *args.PatchSet); err != nil {
+			return nil, nil, err
+		}
+
+		if err = checkPatchSetTokenRepos(ctx, tx, *args.PatchSet); err != nil {
+			return nil, nil, err
+		}
+
 		campaign.PatchSetID = *args.PatchSet
 		updatePatchSetID = true
 	}
@@ -875,10 +1026,129 @@ func mergeByRepoID(
 	return jobs, nil
 }
 
+// campaignIsProcessing returns whether ChangesetJobs of the given Campaign
+// are being executed or are about to be. ChangesetJobs queued by the rollout
+// of the Campaign don't count.
+// ErrInvalidRolloutPolicy is returned by CreateCampaign or SetCampaignRollout
+// if the given RolloutPolicy is invalid.
+var ErrInvalidRolloutPolicy = errors.New("rollout must release a positive number of changesets per positive time window")
+
+func validateRolloutPolicy(p *campaigns.RolloutPolicy) error {
+	if p == nil {
+		return nil
+	}
+	if p.Rate <= 0 || p.Window <= 0 {
+		return ErrInvalidRolloutPolicy
+	}
+	return nil
+}
+
+// SetCampaignRollout sets the RolloutPolicy of the Campaign with the given ID.
+// If policy is nil, the RolloutPolicy is removed and all queued ChangesetJobs
+// of the Campaign are executed, unless its rollout is paused.
+func (s *Service) SetCampaignRollout(ctx context.Context, id int64, policy *campaigns.RolloutPolicy) (campaign *campaigns.Campaign, err error) {
+	traceTitle := fmt.Sprintf("campaign: %d", id)
+	tr, ctx := trace.New(ctx, "service.SetCampaignRollout", traceTitle)
+	defer func() {
+		tr.SetError(err)
+		tr.Finish()
+	}()
+
+	if err = validateRolloutPolicy(policy); err != nil {
+		return nil, err
+	}
+
+	return s.updateOpenCampaign(ctx, id, func(c *campaigns.Campaign) {
+		c.RolloutPolicy = policy
+	})
+}
+
+// SetCampaignRolloutPaused pauses or resumes the rollout of the Campaign with
+// the given ID. While the rollout is paused, no ChangesetJobs of the Campaign
+// are started.
+func (s *Service) SetCampaignRolloutPaused(ctx context.Context, id int64, paused bool) (campaign *campaigns.Campaign, err error) {
+	traceTitle := fmt.Sprintf("campaign: %d, paused: %t", id, paused)
+	tr, ctx := trace.New(ctx, "service.SetCampaignRolloutPaused", traceTitle)
+	defer func() {
+		tr.SetError(err)
+		tr.Finish()
+	}()
+
+	return s.updateOpenCampaign(ctx, id, func(c *campaigns.Campaign) {
+		c.RolloutPaused = paused
+	})
+}
+
+// ErrInvalidAutoMergePolicy is returned by CreateCampaign or
+// SetCampaignAutoMerge if the given AutoMergePolicy is invalid.
+var ErrInvalidAutoMergePolicy = errors.New("auto-merge must require a non-negative number of approvals and use a valid merge method")
+
+func validateAutoMergePolicy(p *campaigns.AutoMergePolicy) error {
+	if p == nil {
+		return nil
+	}
+	if p.RequiredApprovals < 0 || (p.MergeMethod != "" && !p.MergeMethod.Valid()) {
+		return ErrInvalidAutoMergePolicy
+	}
+	return nil
+}
+
+// SetCampaignAutoMerge sets the AutoMergePolicy of the Campaign with the given
+// ID. If policy is nil, the open changesets of the Campaign are no longer
+// merged automatically.
+func (s *Service) SetCampaignAutoMerge(ctx context.Context, id int64, policy *campaigns.AutoMergePolicy) (campaign *campaigns.Campaign, err error) {
+	traceTitle := fmt.Sprintf("campaign: %d", id)
+	tr, ctx := trace.New(ctx, "service.SetCampaignAutoMerge", traceTitle)
+	defer func() {
+		tr.SetError(err)
+		tr.Finish()
+	}()
+
+	if err = validateAutoMergePolicy(policy); err != nil {
+		return nil, err
+	}
+
+	return s.updateOpenCampaign(ctx, id, func(c *campaigns.Campaign) {
+		c.AutoMergePolicy = policy
+	})
+}
+
+func (s *Service) updateOpenCampaign(ctx context.Context, id int64, update func(*campaigns.Campaign)) (campaign *campaigns.Campaign, err error) {
+	tx, err := s.store.Transact(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Done(&err)
+
+	campaign, err = tx.GetCampaign(ctx, GetCampaignOpts{ID: id})
+	if err != nil {
+		return nil, errors.Wrap(err, "getting campaign")
+	}
+
+	if !campaign.ClosedAt.IsZero() {
+		return nil, ErrUpdateClosedCampaign
+	}
+
+	update(campaign)
+
+	return campaign, tx.UpdateCampaign(ctx, campaign)
+}
+
 func campaignIsProcessing(ctx context.Context, store *Store, campaign int64) (bool, error) {
 	status, err := store.GetCampaignStatus(ctx, campaign)
 	if err != nil {
 		return false, err
 	}
-	return status.Processing(), nil
+	if !status.Processing() {
+		return false, nil
+	}
+
+	queued, err := store.CountChangesetJobs(ctx, CountChangesetJobsOpts{
+		CampaignID: campaign,
+		OnlyQueued: true,
+	})
+	if err != nil {
+		return false, err
+	}
+	return int64(status.Pending) > queued, nil
 }
@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/authz"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/backend"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
@@ -1180,3 +1181,23 @@ type dummyGitserverClient struct {
 func (d *dummyGitserverClient) CreateCommitFromPatch(ctx context.Context, req protocol.CreateCommitFromPatchRequest) (string, error) {
 	return d.response, d.responseErr
 }
+
+func TestCheckTokenRepos(t *testing.T) {
+	rs := []*repos.Repo{{Name: "github.com/sourcegraph/a"}, {Name: "github.com/other/b"}}
+
+	if err := CheckTokenRepos(context.Background(), rs); err != nil {
+		t.Errorf("have error %q without restrictions, want nil", err)
+	}
+
+	r, err := authz.NewTokenRestrictions(nil, []string{"^github.com/sourcegraph/"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := authz.WithTokenRestrictions(context.Background(), r)
+	if err := CheckTokenRepos(ctx, rs[:1]); err != nil {
+		t.Errorf("have error %q for allowed repository, want nil", err)
+	}
+	if err := CheckTokenRepos(ctx, rs); err == nil {
+		t.Error("have no error for restricted repository, want error")
+	}
+}
@@ -11,6 +11,7 @@ import (
 	cmpgn "github.com/sourcegraph/sourcegraph/internal/campaigns"
 	"github.com/sourcegraph/sourcegraph/internal/extsvc/bitbucketserver"
 	"github.com/sourcegraph/sourcegraph/internal/extsvc/github"
+	"github.com/sourcegraph/sourcegraph/internal/extsvc/gitlab"
 )
 
 // SetDerivedState will update the external state fields on the Changeset based
@@ -43,6 +44,9 @@ func ComputeCheckState(c *cmpgn.Changeset, events ChangesetEvents) cmpgn.Changes
 
 	case *bitbucketserver.PullRequest:
 		return computeBitbucketBuildStatus(c.UpdatedAt, m, events)
+
+	case *gitlab.MergeRequest:
+		return computeGitLabCheckState(c.UpdatedAt, m, events)
 	}
 
 	return cmpgn.ChangesetCheckStateUnknown
@@ -51,6 +55,9 @@ func ComputeCheckState(c *cmpgn.Changeset, events ChangesetEvents) cmpgn.Changes
 // ComputeChangesetState computes the overall state for the changeset and its
 // associated events. The events should be presorted.
 func ComputeChangesetState(c *cmpgn.Changeset, events ChangesetEvents) (cmpgn.ChangesetState, error) {
+	if c.ExternalServiceType == gitlab.ServiceType {
+		return computeGitLabChangesetState(c, events)
+	}
 	if len(events) == 0 {
 		return computeSingleChangesetState(c)
 	}
@@ -68,9 +75,9 @@ func ComputeReviewState(c *cmpgn.Changeset, events ChangesetEvents) (cmpgn.Chang
 		return computeSingleChangesetReviewState(c)
 	}
 
-	// GitHub only stores the ReviewState in events, we can't look at the
-	// Changeset.
-	if c.ExternalServiceType == github.ServiceType {
+	// GitHub and GitLab only store the ReviewState in events, we can't look
+	// at the Changeset.
+	if c.ExternalServiceType == github.ServiceType || c.ExternalServiceType == gitlab.ServiceType {
 		return events.reviewState()
 	}
 
@@ -83,6 +90,27 @@ func ComputeReviewState(c *cmpgn.Changeset, events ChangesetEvents) (cmpgn.Chang
 	return events.reviewState()
 }
 
+// ComputeApprovals computes the number of reviewers that currently approve the
+// changeset, based on the changeset and its associated events. The events
+// should be presorted.
+func ComputeApprovals(c *cmpgn.Changeset, events ChangesetEvents) (int, error) {
+	if len(events) == 0 {
+		return computeSingleChangesetApprovals(c), nil
+	}
+
+	// GitHub and GitLab only store reviews in events, we can't look at the
+	// Changeset.
+	if c.ExternalServiceType == github.ServiceType || c.ExternalServiceType == gitlab.ServiceType {
+		return events.approvals()
+	}
+
+	newestEvent := events[len(events)-1]
+	if c.UpdatedAt.After(newestEvent.Timestamp()) {
+		return computeSingleChangesetApprovals(c), nil
+	}
+	return events.approvals()
+}
+
 func computeBitbucketBuildStatus(lastSynced time.Time, pr *bitbucketserver.PullRequest, events []*cmpgn.ChangesetEvent) cmpgn.ChangesetCheckState {
 	var latestCommit bitbucketserver.Commit
 	for _, c := range pr.Commits {
@@ -121,6 +149,86 @@ func computeBitbucketBuildStatus(lastSynced time.Time, pr *bitbucketserver.PullR
 	return combineCheckStates(states)
 }
 
+// computeGitLabChangesetState computes the state of a GitLab merge request.
+// Syncing a merge request doesn't yield events for state changes, so the
+// synced state is only overridden by state events received via webhooks
+// after the last sync.
+func computeGitLabChangesetState(c *cmpgn.Changeset, events ChangesetEvents) (cmpgn.ChangesetState, error) {
+	state, err := computeSingleChangesetState(c)
+	if err != nil || state == cmpgn.ChangesetStateDeleted {
+		return state, err
+	}
+
+	for _, e := range events {
+		if !e.Timestamp().After(c.UpdatedAt) {
+			continue
+		}
+		switch e.Kind {
+		case cmpgn.ChangesetEventKindGitLabClosed:
+			state = cmpgn.ChangesetStateClosed
+		case cmpgn.ChangesetEventKindGitLabMerged:
+			// Merged is a final state. We can ignore everything after.
+			return cmpgn.ChangesetStateMerged, nil
+		case cmpgn.ChangesetEventKindGitLabReopened:
+			state = cmpgn.ChangesetStateOpen
+		}
+	}
+	return state, nil
+}
+
+func computeGitLabCheckState(lastSynced time.Time, mr *gitlab.MergeRequest, events []*cmpgn.ChangesetEvent) cmpgn.ChangesetCheckState {
+	// GitLab runs a single pipeline per push to a merge request, so we only
+	// consider the most recent pipeline. Pipeline IDs are increasing, and a
+	// pipeline received via webhook replaces the synced version of itself
+	// if it's newer.
+	var latest *gitlab.Pipeline
+	consider := func(p *gitlab.Pipeline) {
+		switch {
+		case p == nil:
+		case latest == nil, p.ID > latest.ID:
+			latest = p
+		case p.ID == latest.ID && p.UpdatedAt.After(latest.UpdatedAt):
+			latest = p
+		}
+	}
+
+	consider(mr.HeadPipeline)
+	for _, p := range mr.Pipelines {
+		consider(p)
+	}
+
+	// Add any events we've received since our last sync
+	for _, e := range events {
+		if m, ok := e.Metadata.(*gitlab.Pipeline); ok && m.UpdatedAt.After(lastSynced) {
+			consider(m)
+		}
+	}
+
+	if latest == nil {
+		return cmpgn.ChangesetCheckStateUnknown
+	}
+	return parseGitLabPipelineStatus(latest.Status)
+}
+
+func parseGitLabPipelineStatus(s gitlab.PipelineStatus) cmpgn.ChangesetCheckState {
+	switch s {
+	case gitlab.PipelineStatusFailed, gitlab.PipelineStatusCanceled:
+		return cmpgn.ChangesetCheckStateFailed
+	case gitlab.PipelineStatusCreated,
+		gitlab.PipelineStatusWaitingForResource,
+		gitlab.PipelineStatusPreparing,
+		gitlab.PipelineStatusPending,
+		gitlab.PipelineStatusRunning,
+		gitlab.PipelineStatusManual,
+		gitlab.PipelineStatusScheduled:
+		return cmpgn.ChangesetCheckStatePending
+	case gitlab.PipelineStatusSuccess:
+		return cmpgn.ChangesetCheckStatePassed
+	default:
+		return cmpgn.ChangesetCheckStateUnknown
+	}
+}
+
 func parseBitbucketBuildState(s string) cmpgn.ChangesetCheckState {
 	switch s {
 	case "FAILED":
@@ -306,6 +414,17 @@ func computeSingleChangesetState(c *cmpgn.Changeset) (s cmpgn.ChangesetState, er
 		} else {
 			s = cmpgn.ChangesetState(m.State)
 		}
+	case *gitlab.MergeRequest:
+		switch m.State {
+		case gitlab.MergeRequestStateOpened:
+			s = cmpgn.ChangesetStateOpen
+		case gitlab.MergeRequestStateClosed, gitlab.MergeRequestStateLocked:
+			s = cmpgn.ChangesetStateClosed
+		case gitlab.MergeRequestStateMerged:
+			s = cmpgn.ChangesetStateMerged
+		default:
+			s = cmpgn.ChangesetState(m.State)
+		}
 	default:
 		return "", errors.New("unknown changeset type")
 	}
@@ -331,6 +450,11 @@ func computeSingleChangesetReviewState(c *cmpgn.Changeset) (s cmpgn.ChangesetRev
 		log15.Warn("Changeset.ReviewState() called, but GitHub review state is calculated through ChangesetEvents.ReviewState", "changeset", c)
 		return cmpgn.ChangesetReviewStatePending, nil
 
+	case *gitlab.MergeRequest:
+		// GitLab approvals are only available as events. Without any events
+		// the merge request hasn't been approved.
+		return cmpgn.ChangesetReviewStatePending, nil
+
 	case *bitbucketserver.PullRequest:
 		for _, r := range m.Reviewers {
 			switch r.Status {
@@ -379,6 +503,38 @@ func computeReviewState(statesByAuthor map[string]campaigns.ChangesetReviewState
 	return selectReviewState(states)
 }
 
+// computeSingleChangesetApprovals returns the number of reviewers that
+// approved the changeset according to its metadata. Only Bitbucket Server
+// pull requests contain their reviewers' approvals.
+//
+// This method should NOT be called directly. Use ComputeApprovals instead.
+func computeSingleChangesetApprovals(c *cmpgn.Changeset) int {
+	pr, ok := c.Metadata.(*bitbucketserver.PullRequest)
+	if !ok {
+		return 0
+	}
+
+	approvals := 0
+	for _, r := range pr.Reviewers {
+		if r.Status == "APPROVED" {
+			approvals++
+		}
+	}
+	return approvals
+}
+
+// countApprovals returns the number of approving reviews in a map of reviews
+// per author.
+func countApprovals(statesByAuthor map[string]campaigns.ChangesetReviewState) int {
+	approvals := 0
+	for _, s := range statesByAuthor {
+		if s == campaigns.ChangesetReviewStateApproved {
+			approvals++
+		}
+	}
+	return approvals
+}
+
 func unixMilliToTime(ms int64) time.Time {
 	return time.Unix(0, ms*int64(time.Millisecond))
 }
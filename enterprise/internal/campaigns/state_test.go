@@ -8,6 +8,7 @@ import (
 	cmpgn "github.com/sourcegraph/sourcegraph/internal/campaigns"
 	"github.com/sourcegraph/sourcegraph/internal/extsvc/bitbucketserver"
 	"github.com/sourcegraph/sourcegraph/internal/extsvc/github"
+	"github.com/sourcegraph/sourcegraph/internal/extsvc/gitlab"
 )
 
 func TestComputeGithubCheckState(t *testing.T) {
@@ -258,3 +259,215 @@ func TestComputeBitbucketBuildStatus(t *testing.T) {
 		})
 	}
 }
+
+func TestComputeGitLabCheckState(t *testing.T) {
+	now := time.Now().UTC().Truncate(time.Microsecond)
+	lastSynced := now.Add(-1 * time.Minute)
+
+	pipeline := func(id int, status gitlab.PipelineStatus, updatedAt time.Time) *gitlab.Pipeline {
+		return &gitlab.Pipeline{ID: id, Status: status, CreatedAt: lastSynced, UpdatedAt: updatedAt}
+	}
+	pipelineEvent := func(p *gitlab.Pipeline) *cmpgn.ChangesetEvent {
+		return &cmpgn.ChangesetEvent{
+			Kind:     cmpgn.ChangesetEventKindGitLabPipeline,
+			Metadata: p,
+		}
+	}
+
+	tests := []struct {
+		name   string
+		mr     *gitlab.MergeRequest
+		events []*cmpgn.ChangesetEvent
+		want   cmpgn.ChangesetCheckState
+	}{
+		{
+			name: "no pipelines",
+			mr:   &gitlab.MergeRequest{},
+			want: cmpgn.ChangesetCheckStateUnknown,
+		},
+		{
+			name: "head pipeline",
+			mr:   &gitlab.MergeRequest{HeadPipeline: pipeline(1, gitlab.PipelineStatusRunning, lastSynced)},
+			want: cmpgn.ChangesetCheckStatePending,
+		},
+		{
+			name: "latest synced pipeline",
+			mr: &gitlab.MergeRequest{Pipelines: []*gitlab.Pipeline{
+				pipeline(2, gitlab.PipelineStatusSuccess, lastSynced),
+				pipeline(1, gitlab.PipelineStatusFailed, lastSynced),
+			}},
+			want: cmpgn.ChangesetCheckStatePassed,
+		},
+		{
+			name: "pipeline updated since sync",
+			mr:   &gitlab.MergeRequest{HeadPipeline: pipeline(1, gitlab.PipelineStatusRunning, lastSynced)},
+			events: []*cmpgn.ChangesetEvent{
+				pipelineEvent(pipeline(1, gitlab.PipelineStatusFailed, now)),
+			},
+			want: cmpgn.ChangesetCheckStateFailed,
+		},
+		{
+			name: "new pipeline since sync",
+			mr:   &gitlab.MergeRequest{HeadPipeline: pipeline(1, gitlab.PipelineStatusFailed, lastSynced)},
+			events: []*cmpgn.ChangesetEvent{
+				pipelineEvent(pipeline(2, gitlab.PipelineStatusPending, now)),
+			},
+			want: cmpgn.ChangesetCheckStatePending,
+		},
+		{
+			name: "events before sync are ignored",
+			mr:   &gitlab.MergeRequest{HeadPipeline: pipeline(1, gitlab.PipelineStatusSuccess, lastSynced)},
+			events: []*cmpgn.ChangesetEvent{
+				pipelineEvent(pipeline(1, gitlab.PipelineStatusRunning, lastSynced.Add(-1*time.Minute))),
+			},
+			want: cmpgn.ChangesetCheckStatePassed,
+		},
+		{
+			name: "skipped pipeline",
+			mr:   &gitlab.MergeRequest{HeadPipeline: pipeline(1, gitlab.PipelineStatusSkipped, lastSynced)},
+			want: cmpgn.ChangesetCheckStateUnknown,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			have := computeGitLabCheckState(lastSynced, tc.mr, tc.events)
+			if diff := cmp.Diff(tc.want, have); diff != "" {
+				t.Fatalf(diff)
+			}
+		})
+	}
+}
+
+func TestComputeGitLabState(t *testing.T) {
+	now := time.Now().UTC().Truncate(time.Microsecond)
+	lastSynced := now.Add(-1 * time.Minute)
+
+	changeset := func(state gitlab.MergeRequestState) *cmpgn.Changeset {
+		c := &cmpgn.Changeset{UpdatedAt: lastSynced}
+		if err := c.SetMetadata(&gitlab.MergeRequest{State: state}); err != nil {
+			t.Fatal(err)
+		}
+		return c
+	}
+	common := func(username string, t time.Time) gitlab.MergeRequestEventCommon {
+		return gitlab.MergeRequestEventCommon{User: gitlab.User{Username: username}, CreatedAt: t}
+	}
+	event := func(kind cmpgn.ChangesetEventKind, metadata interface{}) *cmpgn.ChangesetEvent {
+		return &cmpgn.ChangesetEvent{Kind: kind, Metadata: metadata}
+	}
+
+	t.Run("changeset state", func(t *testing.T) {
+		tests := []struct {
+			name      string
+			changeset *cmpgn.Changeset
+			events    ChangesetEvents
+			want      cmpgn.ChangesetState
+		}{
+			{
+				name:      "synced state",
+				changeset: changeset(gitlab.MergeRequestStateLocked),
+				want:      cmpgn.ChangesetStateClosed,
+			},
+			{
+				name:      "closed since sync",
+				changeset: changeset(gitlab.MergeRequestStateOpened),
+				events: ChangesetEvents{
+					event(cmpgn.ChangesetEventKindGitLabClosed, &gitlab.MergeRequestClosedEvent{MergeRequestEventCommon: common("a", now)}),
+				},
+				want: cmpgn.ChangesetStateClosed,
+			},
+			{
+				name:      "closed before sync",
+				changeset: changeset(gitlab.MergeRequestStateOpened),
+				events: ChangesetEvents{
+					event(cmpgn.ChangesetEventKindGitLabClosed, &gitlab.MergeRequestClosedEvent{MergeRequestEventCommon: common("a", lastSynced.Add(-1*time.Minute))}),
+				},
+				want: cmpgn.ChangesetStateOpen,
+			},
+			{
+				name:      "pipeline since sync",
+				changeset: changeset(gitlab.MergeRequestStateClosed),
+				events: ChangesetEvents{
+					event(cmpgn.ChangesetEventKindGitLabPipeline, &gitlab.Pipeline{UpdatedAt: now}),
+				},
+				want: cmpgn.ChangesetStateClosed,
+			},
+			{
+				name:      "merged since sync",
+				changeset: changeset(gitlab.MergeRequestStateOpened),
+				events: ChangesetEvents{
+					event(cmpgn.ChangesetEventKindGitLabMerged, &gitlab.MergeRequestMergedEvent{MergeRequestEventCommon: common("a", now)}),
+					event(cmpgn.ChangesetEventKindGitLabReopened, &gitlab.MergeRequestReopenedEvent{MergeRequestEventCommon: common("a", now.Add(time.Minute))}),
+				},
+				want: cmpgn.ChangesetStateMerged,
+			},
+		}
+
+		for _, tc := range tests {
+			t.Run(tc.name, func(t *testing.T) {
+				have, err := ComputeChangesetState(tc.changeset, tc.events)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if diff := cmp.Diff(tc.want, have); diff != "" {
+					t.Fatalf(diff)
+				}
+			})
+		}
+	})
+
+	t.Run("review state", func(t *testing.T) {
+		approved := func(username string, t time.Time) *cmpgn.ChangesetEvent {
+			return event(cmpgn.ChangesetEventKindGitLabApproved, &gitlab.ReviewApprovedEvent{Author: gitlab.User{Username: username}, CreatedAt: t})
+		}
+		unapproved := func(username string, t time.Time) *cmpgn.ChangesetEvent {
+			return event(cmpgn.ChangesetEventKindGitLabUnapproved, &gitlab.ReviewUnapprovedEvent{Author: gitlab.User{Username: username}, CreatedAt: t})
+		}
+
+		tests := []struct {
+			name   string
+			events ChangesetEvents
+			want   cmpgn.ChangesetReviewState
+		}{
+			{
+				name: "no events",
+				want: cmpgn.ChangesetReviewStatePending,
+			},
+			{
+				name:   "approved before sync",
+				events: ChangesetEvents{approved("a", lastSynced.Add(-1*time.Minute))},
+				want:   cmpgn.ChangesetReviewStateApproved,
+			},
+			{
+				name: "unapproved",
+				events: ChangesetEvents{
+					approved("a", lastSynced.Add(-1*time.Minute)),
+					unapproved("a", now),
+				},
+				want: cmpgn.ChangesetReviewStatePending,
+			},
+			{
+				name: "approved by another user",
+				events: ChangesetEvents{
+					approved("a", lastSynced.Add(-1*time.Minute)),
+					approved("b", now),
+					unapproved("a", now.Add(time.Minute)),
+				},
+				want: cmpgn.ChangesetReviewStateApproved,
+			},
+		}
+
+		for _, tc := range tests {
+			t.Run(tc.name, func(t *testing.T) {
+				have, err := ComputeReviewState(changeset(gitlab.MergeRequestStateOpened), tc.events)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if diff := cmp.Diff(tc.want, have); diff != "" {
+					t.Fatalf(diff)
+				}
+			})
+		}
+	})
+}
@@ -13,12 +13,14 @@ import (
 	"github.com/lib/pq"
 	"github.com/pkg/errors"
 	"github.com/segmentio/fasthash/fnv1"
+	replacerprotocol "github.com/sourcegraph/sourcegraph/cmd/replacer/protocol"
 	"github.com/sourcegraph/sourcegraph/cmd/repo-updater/repos"
 	"github.com/sourcegraph/sourcegraph/internal/api"
 	"github.com/sourcegraph/sourcegraph/internal/campaigns"
 	"github.com/sourcegraph/sourcegraph/internal/db/dbutil"
 	"github.com/sourcegraph/sourcegraph/internal/extsvc/bitbucketserver"
 	"github.com/sourcegraph/sourcegraph/internal/extsvc/github"
+	"github.com/sourcegraph/sourcegraph/internal/extsvc/gitlab"
 )
 
 // Store exposes methods to read and write campaigns domain models
@@ -66,7 +68,8 @@ func (s *Store) Transact(ctx context.Context) (*Store, error) {
 }
 
 // ProcessPendingChangesetJobs attempts to fetch one pending changeset job.
-// A pending job is one that has never been started.
+// A pending job is one that has never been started and isn't queued by the
+// rollout of its campaign.
 // If found, 'process' is called. We guarantee that if process is called it will have exclusive global access to
 // the job. All operations on the job should be done using the supplied store as they will run in a transaction.
 // Returning an error will roll back the transaction.
@@ -101,6 +104,8 @@ UPDATE changeset_jobs j SET started_at = now() WHERE id = (
 	SELECT j.id FROM changeset_jobs j
 	JOIN campaigns c ON c.id = j.campaign_id
 	WHERE j.started_at IS NULL AND c.patch_set_id IS NOT NULL
+	AND NOT c.rollout_paused
+	AND (c.rollout_policy IS NULL OR j.released_at IS NOT NULL)
 	ORDER BY j.id ASC
 	FOR UPDATE SKIP LOCKED LIMIT 1
 )
@@ -110,6 +115,57 @@ RETURNING j.id,
   j.changeset_id,
   j.branch,
   j.error,
+  j.released_at,
+  j.started_at,
+  j.finished_at,
+  j.created_at,
+  j.updated_at
+`
+
+// ProcessPendingPatchJobs attempts to fetch one pending patch job.
+// A pending job is one that has never been started.
+// If found, 'process' is called with the same guarantees as in
+// ProcessPendingChangesetJobs.
+// NOTE: It should not be called from within an existing transaction
+func (s *Store) ProcessPendingPatchJobs(ctx context.Context, process func(ctx context.Context, s *Store, job campaigns.PatchJob) error) (didRun bool, err error) {
+	tx, err := s.Transact(ctx)
+	if err != nil {
+		return false, errors.Wrap(err, "starting transaction")
+	}
+	defer tx.Done(&err)
+	q := sqlf.Sprintf(getPendingPatchJobQuery)
+	var job campaigns.PatchJob
+	_, count, err := tx.query(ctx, q, func(sc scanner) (last, count int64, err error) {
+		err = scanPatchJob(&job, sc)
+		if err != nil {
+			return 0, 0, errors.Wrap(err, "scanning patch job row")
+		}
+		return job.ID, 1, nil
+	})
+	if err != nil {
+		return false, errors.Wrap(err, "querying for pending patch job")
+	}
+	if count == 0 {
+		return false, nil
+	}
+	err = process(ctx, tx, job)
+	return true, err
+}
+
+const getPendingPatchJobQuery = `
+UPDATE patch_jobs j SET started_at = now() WHERE id = (
+	SELECT j.id FROM patch_jobs j
+	WHERE j.started_at IS NULL
+	ORDER BY j.id ASC
+	FOR UPDATE SKIP LOCKED LIMIT 1
+)
+RETURNING j.id,
+  j.patch_set_id,
+  j.repo_id,
+  j.rev,
+  j.base_ref,
+  j.patch_id,
+  j.error,
   j.started_at,
   j.finished_at,
   j.created_at,
@@ -1150,9 +1206,12 @@ INSERT INTO campaigns (
   updated_at,
   changeset_ids,
   patch_set_id,
-  closed_at
+  closed_at,
+  rollout_policy,
+  rollout_paused,
+  auto_merge_policy
 )
-VALUES (%s, %s, %s, %s, %s, %s, %s, %s, %s, %s, %s)
+VALUES (%s, %s, %s, %s, %s, %s, %s, %s, %s, %s, %s, %s, %s, %s)
 RETURNING
   id,
   name,
@@ -1165,7 +1224,10 @@ RETURNING
   updated_at,
   changeset_ids,
   patch_set_id,
-  closed_at
+  closed_at,
+  rollout_policy,
+  rollout_paused,
+  auto_merge_policy
 `
 
 func (s *Store) createCampaignQuery(c *campaigns.Campaign) (*sqlf.Query, error) {
@@ -1174,6 +1236,16 @@ func (s *Store) createCampaignQuery(c *campaigns.Campaign) (*sqlf.Query, error)
 		return nil, err
 	}
 
+	rolloutPolicy, err := rolloutPolicyColumn(c.RolloutPolicy)
+	if err != nil {
+		return nil, err
+	}
+
+	autoMergePolicy, err := autoMergePolicyColumn(c.AutoMergePolicy)
+	if err != nil {
+		return nil, err
+	}
+
 	if c.CreatedAt.IsZero() {
 		c.CreatedAt = s.now()
 	}
@@ -1195,6 +1267,9 @@ func (s *Store) createCampaignQuery(c *campaigns.Campaign) (*sqlf.Query, error)
 		changesetIDs,
 		nullInt64Column(c.PatchSetID),
 		nullTimeColumn(c.ClosedAt),
+		rolloutPolicy,
+		c.RolloutPaused,
+		autoMergePolicy,
 	), nil
 }
 
@@ -1252,8 +1327,11 @@ SET (
   updated_at,
   changeset_ids,
   patch_set_id,
-  closed_at
-) = (%s, %s, %s, %s, %s, %s, %s, %s, %s, %s)
+  closed_at,
+  rollout_policy,
+  rollout_paused,
+  auto_merge_policy
+) = (%s, %s, %s, %s, %s, %s, %s, %s, %s, %s, %s, %s, %s)
 WHERE id = %s
 RETURNING
   id,
@@ -1267,7 +1345,10 @@ RETURNING
   updated_at,
   changeset_ids,
   patch_set_id,
-  closed_at
+  closed_at,
+  rollout_policy,
+  rollout_paused,
+  auto_merge_policy
 `
 
 func (s *Store) updateCampaignQuery(c *campaigns.Campaign) (*sqlf.Query, error) {
@@ -1276,6 +1357,16 @@ func (s *Store) updateCampaignQuery(c *campaigns.Campaign) (*sqlf.Query, error)
 		return nil, err
 	}
 
+	rolloutPolicy, err := rolloutPolicyColumn(c.RolloutPolicy)
+	if err != nil {
+		return nil, err
+	}
+
+	autoMergePolicy, err := autoMergePolicyColumn(c.AutoMergePolicy)
+	if err != nil {
+		return nil, err
+	}
+
 	c.UpdatedAt = s.now()
 
 	return sqlf.Sprintf(
@@ -1290,6 +1381,9 @@ func (s *Store) updateCampaignQuery(c *campaigns.Campaign) (*sqlf.Query, error)
 		changesetIDs,
 		nullInt64Column(c.PatchSetID),
 		nullTimeColumn(c.ClosedAt),
+		rolloutPolicy,
+		c.RolloutPaused,
+		autoMergePolicy,
 		c.ID,
 	), nil
 }
@@ -1401,7 +1495,10 @@ SELECT
   updated_at,
   changeset_ids,
   patch_set_id,
-  closed_at
+  closed_at,
+  rollout_policy,
+  rollout_paused,
+  auto_merge_policy
 FROM campaigns
 WHERE %s
 LIMIT 1
@@ -1432,6 +1529,9 @@ type ListCampaignsOpts struct {
 	Limit       int
 	State       campaigns.CampaignState
 	HasPatchSet *bool
+	// OnlyRollingOut limits the results to Campaigns with an unpaused
+	// RolloutPolicy that have ChangesetJobs left to release.
+	OnlyRollingOut bool
 }
 
 // ListCampaigns lists Campaigns with the given filters.
@@ -1470,7 +1570,10 @@ SELECT
   updated_at,
   changeset_ids,
   patch_set_id,
-  closed_at
+  closed_at,
+  rollout_policy,
+  rollout_paused,
+  auto_merge_policy
 FROM campaigns
 WHERE %s
 ORDER BY id ASC
@@ -1506,6 +1609,10 @@ func listCampaignsQuery(opts *ListCampaignsOpts) *sqlf.Query {
 		}
 	}
 
+	if opts.OnlyRollingOut {
+		preds = append(preds, sqlf.Sprintf(onlyRollingOutQueryFmtstr))
+	}
+
 	return sqlf.Sprintf(
 		listCampaignsQueryFmtstr,
 		sqlf.Join(preds, "\n AND "),
@@ -1513,6 +1620,18 @@ func listCampaignsQuery(opts *ListCampaignsOpts) *sqlf.Query {
 	)
 }
 
+const onlyRollingOutQueryFmtstr = `
+rollout_policy IS NOT NULL
+AND NOT rollout_paused
+AND closed_at IS NULL
+AND EXISTS (
+  SELECT 1 FROM changeset_jobs
+  WHERE changeset_jobs.campaign_id = campaigns.id
+  AND changeset_jobs.released_at IS NULL
+  AND changeset_jobs.started_at IS NULL
+)
+`
+
 // CreatePatchSet creates the given PatchSet.
 func (s *Store) CreatePatchSet(ctx context.Context, c *campaigns.PatchSet) error {
 	q, err := s.createPatchSetQuery(c)
@@ -1531,17 +1650,26 @@ var createPatchSetQueryFmtstr = `
 INSERT INTO patch_sets (
   created_at,
   updated_at,
-  user_id
+  user_id,
+  query,
+  rewrite_specification
 )
-VALUES (%s, %s, %s)
+VALUES (%s, %s, %s, %s, %s)
 RETURNING
   id,
   created_at,
   updated_at,
-  user_id
+  user_id,
+  query,
+  rewrite_specification
 `
 
 func (s *Store) createPatchSetQuery(c *campaigns.PatchSet) (*sqlf.Query, error) {
+	rewrite, err := rewriteSpecificationColumn(c.Rewrite)
+	if err != nil {
+		return nil, err
+	}
+
 	if c.CreatedAt.IsZero() {
 		c.CreatedAt = s.now()
 	}
@@ -1555,6 +1683,8 @@ func (s *Store) createPatchSetQuery(c *campaigns.PatchSet) (*sqlf.Query, error)
 		c.CreatedAt,
 		c.UpdatedAt,
 		c.UserID,
+		c.Query,
+		rewrite,
 	), nil
 }
 
@@ -1576,23 +1706,34 @@ var updatePatchSetQueryFmtstr = `
 UPDATE patch_sets
 SET (
   updated_at,
-  user_id
-) = (%s, %s)
+  user_id,
+  query,
+  rewrite_specification
+) = (%s, %s, %s, %s)
 WHERE id = %s
 RETURNING
   id,
   created_at,
   updated_at,
-  user_id
+  user_id,
+  query,
+  rewrite_specification
 `
 
 func (s *Store) updatePatchSetQuery(c *campaigns.PatchSet) (*sqlf.Query, error) {
+	rewrite, err := rewriteSpecificationColumn(c.Rewrite)
+	if err != nil {
+		return nil, err
+	}
+
 	c.UpdatedAt = s.now()
 
 	return sqlf.Sprintf(
 		updatePatchSetQueryFmtstr,
 		c.UpdatedAt,
 		c.UserID,
+		c.Query,
+		rewrite,
 		c.ID,
 	), nil
 }
@@ -1616,6 +1757,7 @@ DELETE FROM patch_sets WHERE id = %s
 const PatchSetTTL = 1 * time.Hour
 
 // DeleteExpiredPatchSets deletes PatchSets that have not been attached to a Campaign within PatchSetTTL.
+// PatchSets whose PatchJobs are still running are never deleted.
 func (s *Store) DeleteExpiredPatchSets(ctx context.Context) error {
 	expirationTime := s.now().Add(-PatchSetTTL)
 	q := sqlf.Sprintf(deleteExpiredPatchSetsQueryFmtstr, expirationTime)
@@ -1650,6 +1792,16 @@ NOT EXISTS (
   JOIN changesets ON changesets.id = changeset_jobs.changeset_id
   WHERE
     (SELECT COUNT(*) FROM jsonb_object_keys(changesets.campaign_ids)) > 0
+  )
+AND
+NOT EXISTS (
+  SELECT 1
+  FROM
+    patch_jobs
+  WHERE
+    patch_jobs.patch_set_id = patch_sets.id
+  AND
+    patch_jobs.finished_at IS NULL
 );
 `
 
@@ -1698,7 +1850,9 @@ SELECT
   id,
   created_at,
   updated_at,
-  user_id
+  user_id,
+  query,
+  rewrite_specification
 FROM patch_sets
 WHERE %s
 LIMIT 1
@@ -1797,7 +1951,9 @@ SELECT
   id,
   created_at,
   updated_at,
-  user_id
+  user_id,
+  query,
+  rewrite_specification
 FROM patch_sets
 WHERE %s
 ORDER BY id ASC
@@ -2169,12 +2325,13 @@ INSERT INTO changeset_jobs (
   changeset_id,
   branch,
   error,
+  released_at,
   started_at,
   finished_at,
   created_at,
   updated_at
 )
-VALUES (%s, %s, %s, %s, %s, %s, %s, %s, %s)
+VALUES (%s, %s, %s, %s, %s, %s, %s, %s, %s, %s)
 RETURNING
   id,
   campaign_id,
@@ -2182,6 +2339,7 @@ RETURNING
   changeset_id,
   branch,
   error,
+  released_at,
   started_at,
   finished_at,
   created_at,
@@ -2204,6 +2362,7 @@ func (s *Store) createChangesetJobQuery(c *campaigns.ChangesetJob) (*sqlf.Query,
 		nullInt64Column(c.ChangesetID),
 		c.Branch,
 		nullStringColumn(c.Error),
+		nullTimeColumn(c.ReleasedAt),
 		nullTimeColumn(c.StartedAt),
 		nullTimeColumn(c.FinishedAt),
 		c.CreatedAt,
@@ -2233,10 +2392,11 @@ SET (
   changeset_id,
   branch,
   error,
+  released_at,
   started_at,
   finished_at,
   updated_at
-) = (%s, %s, %s, %s, %s, %s, %s, %s)
+) = (%s, %s, %s, %s, %s, %s, %s, %s, %s)
 WHERE id = %s
 RETURNING
   id,
@@ -2245,6 +2405,7 @@ RETURNING
   changeset_id,
   branch,
   error,
+  released_at,
   started_at,
   finished_at,
   created_at,
@@ -2261,6 +2422,7 @@ func (s *Store) updateChangesetJobQuery(c *campaigns.ChangesetJob) (*sqlf.Query,
 		nullInt64Column(c.ChangesetID),
 		c.Branch,
 		nullStringColumn(c.Error),
+		nullTimeColumn(c.ReleasedAt),
 		nullTimeColumn(c.StartedAt),
 		nullTimeColumn(c.FinishedAt),
 		c.UpdatedAt,
@@ -2288,6 +2450,10 @@ DELETE FROM changeset_jobs WHERE id = %s
 // counting code mods.
 type CountChangesetJobsOpts struct {
 	CampaignID int64
+	// OnlyQueued limits the count to ChangesetJobs that haven't been started
+	// and can't be started yet, because the rollout of their Campaign is
+	// paused or hasn't released them.
+	OnlyQueued bool
 }
 
 // CountChangesetJobs returns the number of code mods in the database.
@@ -2301,24 +2467,84 @@ func (s *Store) CountChangesetJobs(ctx context.Context, opts CountChangesetJobsO
 
 var countChangesetJobsQueryFmtstr = `
 -- source: enterprise/internal/campaigns/store.go:CountChangesetJobs
-SELECT COUNT(id)
+SELECT COUNT(changeset_jobs.id)
 FROM changeset_jobs
+%s
 WHERE %s
 `
 
 func countChangesetJobsQuery(opts *CountChangesetJobsOpts) *sqlf.Query {
 	var preds []*sqlf.Query
 	if opts.CampaignID != 0 {
-		preds = append(preds, sqlf.Sprintf("campaign_id = %s", opts.CampaignID))
+		preds = append(preds, sqlf.Sprintf("changeset_jobs.campaign_id = %s", opts.CampaignID))
+	}
+
+	joinClause := sqlf.Sprintf("")
+	if opts.OnlyQueued {
+		joinClause = sqlf.Sprintf("JOIN campaigns ON campaigns.id = changeset_jobs.campaign_id")
+		preds = append(preds, sqlf.Sprintf(onlyQueuedChangesetJobsQueryFmtstr))
 	}
 
 	if len(preds) == 0 {
 		preds = append(preds, sqlf.Sprintf("TRUE"))
 	}
 
-	return sqlf.Sprintf(countChangesetJobsQueryFmtstr, sqlf.Join(preds, "\n AND "))
+	return sqlf.Sprintf(countChangesetJobsQueryFmtstr, joinClause, sqlf.Join(preds, "\n AND "))
 }
 
+const onlyQueuedChangesetJobsQueryFmtstr = `
+changeset_jobs.started_at IS NULL
+AND (
+  campaigns.rollout_paused
+  OR (campaigns.rollout_policy IS NOT NULL AND changeset_jobs.released_at IS NULL)
+)
+`
+
+// ReleaseChangesetJobs sets the ReleasedAt field of the ChangesetJobs with the
+// given IDs that haven't been released yet, which allows them to be executed.
+func (s *Store) ReleaseChangesetJobs(ctx context.Context, releasedAt time.Time, ids ...int64) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	q := sqlf.Sprintf(releaseChangesetJobsQueryFmtstr, releasedAt, pq.Array(ids))
+
+	rows, err := s.db.QueryContext(ctx, q.Query(sqlf.PostgresBindVar), q.Args()...)
+	if err != nil {
+		return err
+	}
+	return rows.Close()
+}
+
+var releaseChangesetJobsQueryFmtstr = `
+-- source: enterprise/internal/campaigns/store.go:ReleaseChangesetJobs
+UPDATE changeset_jobs
+SET released_at = %s
+WHERE id = ANY (%s)
+AND released_at IS NULL
+`
+
+// DeleteQueuedChangesetJobs deletes the ChangesetJobs of the Campaign with the
+// given ID that are queued by its rollout and haven't been started.
+func (s *Store) DeleteQueuedChangesetJobs(ctx context.Context, campaignID int64) error {
+	q := sqlf.Sprintf(deleteQueuedChangesetJobsQueryFmtstr, campaignID, sqlf.Sprintf(onlyQueuedChangesetJobsQueryFmtstr))
+
+	rows, err := s.db.QueryContext(ctx, q.Query(sqlf.PostgresBindVar), q.Args()...)
+	if err != nil {
+		return err
+	}
+	return rows.Close()
+}
+
+var deleteQueuedChangesetJobsQueryFmtstr = `
+-- source: enterprise/internal/campaigns/store.go:DeleteQueuedChangesetJobs
+DELETE FROM changeset_jobs
+USING campaigns
+WHERE campaigns.id = changeset_jobs.campaign_id
+AND changeset_jobs.campaign_id = %s
+AND %s
+`
+
 // GetLatestChangesetJobCreatedAt returns the most recent created_at time for all changeset jobs
 // for a campaign. But only if they have all been created, one for each Patch belonging to the PatchSet attached to the Campaign. If not, it returns a zero time.Time.
 func (s *Store) GetLatestChangesetJobCreatedAt(ctx context.Context, campaignID int64) (time.Time, error) {
@@ -2383,6 +2609,7 @@ SELECT
   changeset_id,
   branch,
   error,
+  released_at,
   started_at,
   finished_at,
   created_at,
@@ -2457,6 +2684,7 @@ SELECT
   changeset_jobs.changeset_id,
   changeset_jobs.branch,
   changeset_jobs.error,
+  changeset_jobs.released_at,
   changeset_jobs.started_at,
   changeset_jobs.finished_at,
   changeset_jobs.created_at,
@@ -2547,6 +2775,282 @@ SET
 WHERE %s
 `
 
+// CreatePatchJob creates the given PatchJob.
+func (s *Store) CreatePatchJob(ctx context.Context, c *campaigns.PatchJob) error {
+	q, err := s.createPatchJobQuery(c)
+	if err != nil {
+		return err
+	}
+
+	return s.exec(ctx, q, func(sc scanner) (last, count int64, err error) {
+		err = scanPatchJob(c, sc)
+		return c.ID, 1, err
+	})
+}
+
+var createPatchJobQueryFmtstr = `
+-- source: enterprise/internal/campaigns/store.go:CreatePatchJob
+INSERT INTO patch_jobs (
+  patch_set_id,
+  repo_id,
+  rev,
+  base_ref,
+  patch_id,
+  error,
+  started_at,
+  finished_at,
+  created_at,
+  updated_at
+)
+VALUES (%s, %s, %s, %s, %s, %s, %s, %s, %s, %s)
+RETURNING
+  id,
+  patch_set_id,
+  repo_id,
+  rev,
+  base_ref,
+  patch_id,
+  error,
+  started_at,
+  finished_at,
+  created_at,
+  updated_at
+`
+
+func (s *Store) createPatchJobQuery(c *campaigns.PatchJob) (*sqlf.Query, error) {
+	if c.CreatedAt.IsZero() {
+		c.CreatedAt = s.now()
+	}
+
+	if c.UpdatedAt.IsZero() {
+		c.UpdatedAt = c.CreatedAt
+	}
+
+	return sqlf.Sprintf(
+		createPatchJobQueryFmtstr,
+		c.PatchSetID,
+		c.RepoID,
+		c.Rev,
+		c.BaseRef,
+		nullInt64Column(c.PatchID),
+		nullStringColumn(c.Error),
+		nullTimeColumn(c.StartedAt),
+		nullTimeColumn(c.FinishedAt),
+		c.CreatedAt,
+		c.UpdatedAt,
+	), nil
+}
+
+// UpdatePatchJob updates the given PatchJob.
+func (s *Store) UpdatePatchJob(ctx context.Context, c *campaigns.PatchJob) error {
+	q, err := s.updatePatchJobQuery(c)
+	if err != nil {
+		return err
+	}
+
+	return s.exec(ctx, q, func(sc scanner) (last, count int64, err error) {
+		err = scanPatchJob(c, sc)
+		return c.ID, 1, err
+	})
+}
+
+var updatePatchJobQueryFmtstr = `
+-- source: enterprise/internal/campaigns/store.go:UpdatePatchJob
+UPDATE patch_jobs
+SET (
+  patch_set_id,
+  repo_id,
+  rev,
+  base_ref,
+  patch_id,
+  error,
+  started_at,
+  finished_at,
+  updated_at
+) = (%s, %s, %s, %s, %s, %s, %s, %s, %s)
+WHERE id = %s
+RETURNING
+  id,
+  patch_set_id,
+  repo_id,
+  rev,
+  base_ref,
+  patch_id,
+  error,
+  started_at,
+  finished_at,
+  created_at,
+  updated_at
+`
+
+func (s *Store) updatePatchJobQuery(c *campaigns.PatchJob) (*sqlf.Query, error) {
+	c.UpdatedAt = s.now()
+
+	return sqlf.Sprintf(
+		updatePatchJobQueryFmtstr,
+		c.PatchSetID,
+		c.RepoID,
+		c.Rev,
+		c.BaseRef,
+		nullInt64Column(c.PatchID),
+		nullStringColumn(c.Error),
+		nullTimeColumn(c.StartedAt),
+		nullTimeColumn(c.FinishedAt),
+		c.UpdatedAt,
+		c.ID,
+	), nil
+}
+
+// GetPatchJobOpts captures the query options needed for getting a PatchJob
+type GetPatchJobOpts struct {
+	ID int64
+}
+
+// GetPatchJob gets a PatchJob matching the given options.
+func (s *Store) GetPatchJob(ctx context.Context, opts GetPatchJobOpts) (*campaigns.PatchJob, error) {
+	q := getPatchJobQuery(&opts)
+
+	var c campaigns.PatchJob
+	err := s.exec(ctx, q, func(sc scanner) (_, _ int64, err error) {
+		return 0, 0, scanPatchJob(&c, sc)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if c.ID == 0 {
+		return nil, ErrNoResults
+	}
+
+	return &c, nil
+}
+
+var getPatchJobsQueryFmtstr = `
+-- source: enterprise/internal/campaigns/store.go:GetPatchJob
+SELECT
+  id,
+  patch_set_id,
+  repo_id,
+  rev,
+  base_ref,
+  patch_id,
+  error,
+  started_at,
+  finished_at,
+  created_at,
+  updated_at
+FROM patch_jobs
+WHERE %s
+LIMIT 1
+`
+
+func getPatchJobQuery(opts *GetPatchJobOpts) *sqlf.Query {
+	var preds []*sqlf.Query
+	if opts.ID != 0 {
+		preds = append(preds, sqlf.Sprintf("id = %s", opts.ID))
+	}
+
+	if len(preds) == 0 {
+		preds = append(preds, sqlf.Sprintf("TRUE"))
+	}
+
+	return sqlf.Sprintf(getPatchJobsQueryFmtstr, sqlf.Join(preds, "\n AND "))
+}
+
+// ListPatchJobsOpts captures the query options needed for
+// listing patch jobs.
+type ListPatchJobsOpts struct {
+	PatchSetID int64
+	Cursor     int64
+	Limit      int
+}
+
+// ListPatchJobs lists PatchJobs with the given filters.
+func (s *Store) ListPatchJobs(ctx context.Context, opts ListPatchJobsOpts) (cs []*campaigns.PatchJob, next int64, err error) {
+	q := listPatchJobsQuery(&opts)
+
+	cs = make([]*campaigns.PatchJob, 0, opts.Limit)
+	_, _, err = s.query(ctx, q, func(sc scanner) (last, count int64, err error) {
+		var c campaigns.PatchJob
+		if err = scanPatchJob(&c, sc); err != nil {
+			return 0, 0, err
+		}
+		cs = append(cs, &c)
+		return c.ID, 1, err
+	})
+
+	if opts.Limit != 0 && len(cs) == opts.Limit {
+		next = cs[len(cs)-1].ID
+		cs = cs[:len(cs)-1]
+	}
+
+	return cs, next, err
+}
+
+var listPatchJobsQueryFmtstr = `
+-- source: enterprise/internal/campaigns/store.go:ListPatchJobs
+SELECT
+  id,
+  patch_set_id,
+  repo_id,
+  rev,
+  base_ref,
+  patch_id,
+  error,
+  started_at,
+  finished_at,
+  created_at,
+  updated_at
+FROM patch_jobs
+WHERE %s
+ORDER BY id ASC
+LIMIT %s
+`
+
+func listPatchJobsQuery(opts *ListPatchJobsOpts) *sqlf.Query {
+	if opts.Limit == 0 {
+		opts.Limit = defaultListLimit
+	}
+	opts.Limit++
+
+	preds := []*sqlf.Query{
+		sqlf.Sprintf("id >= %s", opts.Cursor),
+	}
+
+	if opts.PatchSetID != 0 {
+		preds = append(preds, sqlf.Sprintf("patch_set_id = %s", opts.PatchSetID))
+	}
+
+	return sqlf.Sprintf(
+		listPatchJobsQueryFmtstr,
+		sqlf.Join(preds, "\n AND "),
+		opts.Limit,
+	)
+}
+
+// GetPatchSetStatus gets the campaigns.BackgroundProcessStatus for the
+// PatchJobs of a PatchSet.
+func (s *Store) GetPatchSetStatus(ctx context.Context, id int64) (*campaigns.BackgroundProcessStatus, error) {
+	return s.queryBackgroundProcessStatus(ctx, sqlf.Sprintf(
+		getPatchSetStatusQueryFmtstr,
+		sqlf.Sprintf("patch_set_id = %s", id),
+	))
+}
+
+var getPatchSetStatusQueryFmtstr = `
+-- source: enterprise/internal/campaigns/store.go:GetPatchSetStatus
+SELECT
+  -- canceled is here so that this can be used with scanBackgroundProcessStatus
+  false AS canceled,
+  COUNT(*) AS total,
+  COUNT(*) FILTER (WHERE finished_at IS NULL) AS pending,
+  COUNT(*) FILTER (WHERE finished_at IS NOT NULL) AS completed,
+  array_agg(error) FILTER (WHERE error != '') AS errors
+FROM patch_jobs
+WHERE %s
+LIMIT 1
+`
+
 // GetChangesetExternalIDs allows us to find the external ids for pull requests based on
 // a slice of head refs. We need this in order to match incoming webhooks to pull requests as
 // the only information they provide is the remote branch
@@ -2667,6 +3171,8 @@ func scanChangeset(t *campaigns.Changeset, s scanner) error {
 		t.Metadata = new(github.PullRequest)
 	case bitbucketserver.ServiceType:
 		t.Metadata = new(bitbucketserver.PullRequest)
+	case gitlab.ServiceType:
+		t.Metadata = new(gitlab.MergeRequest)
 	default:
 		return errors.New("unknown external service type")
 	}
@@ -2707,7 +3213,8 @@ func scanChangesetEvent(e *campaigns.ChangesetEvent, s scanner) error {
 }
 
 func scanCampaign(c *campaigns.Campaign, s scanner) error {
-	return s.Scan(
+	var rolloutPolicy, autoMergePolicy []byte
+	err := s.Scan(
 		&c.ID,
 		&c.Name,
 		&dbutil.NullString{S: &c.Description},
@@ -2720,11 +3227,54 @@ func scanCampaign(c *campaigns.Campaign, s scanner) error {
 		&dbutil.JSONInt64Set{Set: &c.ChangesetIDs},
 		&dbutil.NullInt64{N: &c.PatchSetID},
 		&dbutil.NullTime{Time: &c.ClosedAt},
+		&rolloutPolicy,
+		&c.RolloutPaused,
+		&autoMergePolicy,
 	)
+	if err != nil {
+		return err
+	}
+
+	c.RolloutPolicy = nil
+	if rolloutPolicy != nil {
+		c.RolloutPolicy = &campaigns.RolloutPolicy{}
+		if err = json.Unmarshal(rolloutPolicy, c.RolloutPolicy); err != nil {
+			return errors.Wrap(err, "scanCampaign: failed to unmarshal rollout policy")
+		}
+	}
+
+	c.AutoMergePolicy = nil
+	if autoMergePolicy != nil {
+		c.AutoMergePolicy = &campaigns.AutoMergePolicy{}
+		if err = json.Unmarshal(autoMergePolicy, c.AutoMergePolicy); err != nil {
+			return errors.Wrap(err, "scanCampaign: failed to unmarshal auto-merge policy")
+		}
+	}
+	return nil
 }
 
 func scanPatchSet(c *campaigns.PatchSet, s scanner) error {
-	return s.Scan(&c.ID, &c.CreatedAt, &c.UpdatedAt, &c.UserID)
+	var rewrite []byte
+	err := s.Scan(
+		&c.ID,
+		&c.CreatedAt,
+		&c.UpdatedAt,
+		&c.UserID,
+		&c.Query,
+		&rewrite,
+	)
+	if err != nil {
+		return err
+	}
+
+	c.Rewrite = nil
+	if rewrite != nil {
+		c.Rewrite = &replacerprotocol.RewriteSpecification{}
+		if err = json.Unmarshal(rewrite, c.Rewrite); err != nil {
+			return errors.Wrap(err, "scanPatchSet: failed to unmarshal rewrite specification")
+		}
+	}
+	return nil
 }
 
 func scanPatch(c *campaigns.Patch, s scanner) error {
@@ -2748,6 +3298,23 @@ func scanChangesetJob(c *campaigns.ChangesetJob, s scanner) error {
 		&dbutil.NullInt64{N: &c.ChangesetID},
 		&c.Branch,
 		&dbutil.NullString{S: &c.Error},
+		&dbutil.NullTime{Time: &c.ReleasedAt},
+		&dbutil.NullTime{Time: &c.StartedAt},
+		&dbutil.NullTime{Time: &c.FinishedAt},
+		&c.CreatedAt,
+		&c.UpdatedAt,
+	)
+}
+
+func scanPatchJob(c *campaigns.PatchJob, s scanner) error {
+	return s.Scan(
+		&c.ID,
+		&c.PatchSetID,
+		&c.RepoID,
+		&c.Rev,
+		&c.BaseRef,
+		&dbutil.NullInt64{N: &c.PatchID},
+		&dbutil.NullString{S: &c.Error},
 		&dbutil.NullTime{Time: &c.StartedAt},
 		&dbutil.NullTime{Time: &c.FinishedAt},
 		&c.CreatedAt,
@@ -2781,6 +3348,27 @@ func metadataColumn(metadata interface{}) (msg json.RawMessage, err error) {
 	return
 }
 
+func rolloutPolicyColumn(policy *campaigns.RolloutPolicy) ([]byte, error) {
+	if policy == nil {
+		return nil, nil
+	}
+	return json.Marshal(policy)
+}
+
+func autoMergePolicyColumn(policy *campaigns.AutoMergePolicy) ([]byte, error) {
+	if policy == nil {
+		return nil, nil
+	}
+	return json.Marshal(policy)
+}
+
+func rewriteSpecificationColumn(spec *replacerprotocol.RewriteSpecification) ([]byte, error) {
+	if spec == nil {
+		return nil, nil
+	}
+	return json.Marshal(spec)
+}
+
 func jsonSetColumn(ids []int64) ([]byte, error) {
 	set := make(map[int64]*struct{}, len(ids))
 	for _, id := range ids {
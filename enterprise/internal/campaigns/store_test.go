@@ -11,6 +11,7 @@ import (
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/pkg/errors"
+	"github.com/sourcegraph/sourcegraph/cmd/replacer/protocol"
 	"github.com/sourcegraph/sourcegraph/cmd/repo-updater/repos"
 	"github.com/sourcegraph/sourcegraph/internal/api"
 	cmpgn "github.com/sourcegraph/sourcegraph/internal/campaigns"
@@ -88,6 +89,18 @@ func testStore(db *sql.DB) func(*testing.T) {
 						// Don't close the first one
 						c.ClosedAt = time.Time{}
 					}
+					if i == 1 {
+						c.RolloutPolicy = &cmpgn.RolloutPolicy{
+							Rate:    10,
+							Window:  time.Hour,
+							Batches: [][]api.RepoID{{1, 2}, {3}},
+						}
+						c.RolloutPaused = true
+						c.AutoMergePolicy = &cmpgn.AutoMergePolicy{
+							RequiredApprovals: 2,
+							MergeMethod:       cmpgn.MergeMethodSquash,
+						}
+					}
 
 					if i%2 == 0 {
 						c.NamespaceOrgID = 23
@@ -1440,6 +1453,14 @@ func testStore(db *sql.DB) func(*testing.T) {
 			t.Run("Create", func(t *testing.T) {
 				for i := 0; i < cap(patchSets); i++ {
 					c := &cmpgn.PatchSet{UserID: 999}
+					if i == 0 {
+						c.Query = "repo:sourcegraph lang:go"
+						c.Rewrite = &protocol.RewriteSpecification{
+							MatchTemplate:   "fmt.Sprintf(:[args])",
+							RewriteTemplate: "fmt.Errorf(:[args])",
+							FileExtension:   ".go",
+						}
+					}
 
 					want := c.Clone()
 					have := c
@@ -2124,6 +2145,168 @@ func testStore(db *sql.DB) func(*testing.T) {
 			}
 		})
 
+		t.Run("PatchJobs", func(t *testing.T) {
+			patchJobs := make([]*cmpgn.PatchJob, 0, 3)
+
+			t.Run("Create", func(t *testing.T) {
+				for i := 0; i < cap(patchJobs); i++ {
+					c := &cmpgn.PatchJob{
+						PatchSetID: int64(i + 1),
+						RepoID:     repo.ID,
+						Rev:        api.CommitID("deadbeef"),
+						BaseRef:    "refs/heads/master",
+						Error:      "only set on error",
+						StartedAt:  now,
+						FinishedAt: now,
+					}
+
+					want := c.Clone()
+					have := c
+
+					err := s.CreatePatchJob(ctx, have)
+					if err != nil {
+						t.Fatal(err)
+					}
+
+					if have.ID == 0 {
+						t.Fatal("ID should not be zero")
+					}
+
+					want.ID = have.ID
+					want.CreatedAt = now
+					want.UpdatedAt = now
+
+					if diff := cmp.Diff(have, want); diff != "" {
+						t.Fatal(diff)
+					}
+
+					patchJobs = append(patchJobs, c)
+				}
+			})
+
+			t.Run("List", func(t *testing.T) {
+				t.Run("WithPatchSetID", func(t *testing.T) {
+					for _, want := range patchJobs {
+						opts := ListPatchJobsOpts{PatchSetID: want.PatchSetID}
+
+						have, _, err := s.ListPatchJobs(ctx, opts)
+						if err != nil {
+							t.Fatal(err)
+						}
+
+						if diff := cmp.Diff(have, []*cmpgn.PatchJob{want}); diff != "" {
+							t.Fatal(diff)
+						}
+					}
+				})
+
+				t.Run("WithPositiveLimit", func(t *testing.T) {
+					for i := 1; i <= len(patchJobs); i++ {
+						opts := ListPatchJobsOpts{Limit: i}
+
+						have, next, err := s.ListPatchJobs(ctx, opts)
+						if err != nil {
+							t.Fatal(err)
+						}
+
+						do := patchJobs[:i]
+						if len(do) != len(have) {
+							t.Fatalf("have %d patch jobs, want %d", len(have), len(do))
+						}
+
+						if i < len(patchJobs) && next != patchJobs[i].ID {
+							t.Fatalf("have next cursor %d, want %d", next, patchJobs[i].ID)
+						}
+					}
+				})
+			})
+
+			t.Run("Update", func(t *testing.T) {
+				for _, c := range patchJobs {
+					now = now.Add(time.Second)
+					c.StartedAt = now.Add(1 * time.Second)
+					c.FinishedAt = now.Add(1 * time.Second)
+					c.PatchID = 42
+					c.Error = "updated-error"
+
+					want := c
+					want.UpdatedAt = now
+
+					have := c.Clone()
+					if err := s.UpdatePatchJob(ctx, have); err != nil {
+						t.Fatal(err)
+					}
+
+					if diff := cmp.Diff(have, want); diff != "" {
+						t.Fatal(diff)
+					}
+				}
+			})
+
+			t.Run("Get", func(t *testing.T) {
+				t.Run("ByID", func(t *testing.T) {
+					want := patchJobs[0]
+
+					have, err := s.GetPatchJob(ctx, GetPatchJobOpts{ID: want.ID})
+					if err != nil {
+						t.Fatal(err)
+					}
+
+					if diff := cmp.Diff(have, want); diff != "" {
+						t.Fatal(diff)
+					}
+				})
+
+				t.Run("NoResults", func(t *testing.T) {
+					_, have := s.GetPatchJob(ctx, GetPatchJobOpts{ID: 0xdeadbeef})
+					want := ErrNoResults
+
+					if have != want {
+						t.Fatalf("have err %v, want %v", have, want)
+					}
+				})
+			})
+
+			t.Run("BackgroundProcessStatus", func(t *testing.T) {
+				// Patch sets 1-3 are used by the patch jobs above.
+				patchSetID := int64(1000)
+				jobs := []*cmpgn.PatchJob{
+					// not started (pending)
+					{},
+					// completed, no errors
+					{StartedAt: now, FinishedAt: now, PatchID: 23},
+					// completed, error
+					{StartedAt: now, FinishedAt: now, Error: "error1"},
+				}
+				for i, j := range jobs {
+					j.PatchSetID = patchSetID
+					j.RepoID = api.RepoID(1000 + i)
+					j.Rev = "deadbeef"
+					j.BaseRef = "refs/heads/master"
+
+					if err := s.CreatePatchJob(ctx, j); err != nil {
+						t.Fatal(err)
+					}
+				}
+
+				status, err := s.GetPatchSetStatus(ctx, patchSetID)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				want := &cmpgn.BackgroundProcessStatus{
+					ProcessState:  cmpgn.BackgroundProcessStateProcessing,
+					Total:         3,
+					Completed:     2,
+					Pending:       1,
+					ProcessErrors: []string{"error1"},
+				}
+				if diff := cmp.Diff(status, want); diff != "" {
+					t.Fatalf("wrong diff: %s", diff)
+				}
+			})
+		})
+
 		t.Run("ChangesetJobs", func(t *testing.T) {
 			changesetJobs := make([]*cmpgn.ChangesetJob, 0, 3)
 
@@ -2135,6 +2318,7 @@ func testStore(db *sql.DB) func(*testing.T) {
 						ChangesetID: int64(i + 1),
 						Branch:      "test-branch",
 						Error:       "only set on error",
+						ReleasedAt:  now,
 						StartedAt:   now,
 						FinishedAt:  now,
 					}
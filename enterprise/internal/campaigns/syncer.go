@@ -297,6 +297,8 @@ type SyncStore interface {
 	ListChangesets(context.Context, ListChangesetsOpts) ([]*campaigns.Changeset, int64, error)
 	UpdateChangesets(ctx context.Context, cs ...*campaigns.Changeset) error
 	UpsertChangesetEvents(ctx context.Context, cs ...*campaigns.ChangesetEvent) error
+	GetChangesetEvent(context.Context, GetChangesetEventOpts) (*campaigns.ChangesetEvent, error)
+	ListCampaigns(context.Context, ListCampaignsOpts) ([]*campaigns.Campaign, int64, error)
 	Transact(context.Context) (*Store, error)
 }
 
@@ -493,7 +495,8 @@ func (s *ChangesetSyncer) computeSchedule(ctx context.Context) ([]scheduledSync,
 	return ss, nil
 }
 
-// SyncChangeset will sync a single changeset given its id.
+// SyncChangeset will sync a single changeset given its id and merge it if it
+// is ready according to the AutoMergePolicy of one of its campaigns.
 func (s *ChangesetSyncer) SyncChangeset(ctx context.Context, id int64) error {
 	log15.Debug("SyncChangeset", "id", id)
 	cs, err := s.SyncStore.GetChangeset(ctx, GetChangesetOpts{
@@ -502,7 +505,21 @@ func (s *ChangesetSyncer) SyncChangeset(ctx context.Context, id int64) error {
 	if err != nil {
 		return err
 	}
-	return syncChangesets(ctx, s.ReposStore, s.SyncStore, s.HTTPFactory, s.rateLimitRegistry, cs)
+
+	bySource, err := GroupChangesetsBySource(ctx, s.ReposStore, s.HTTPFactory, s.rateLimitRegistry, cs)
+	if err != nil {
+		return err
+	}
+
+	if err = SyncChangesetsWithSources(ctx, s.SyncStore, bySource); err != nil {
+		return err
+	}
+
+	clock := s.clock
+	if clock == nil {
+		clock = time.Now
+	}
+	return AutoMergeChangesets(ctx, s.SyncStore, clock, bySource)
 }
 
 // SyncChangesets refreshes the metadata of the given changesets and
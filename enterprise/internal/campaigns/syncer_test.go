@@ -458,6 +458,8 @@ type MockSyncStore struct {
 	listChangesets        func(context.Context, ListChangesetsOpts) ([]*campaigns.Changeset, int64, error)
 	updateChangesets      func(context.Context, ...*campaigns.Changeset) error
 	upsertChangesetEvents func(context.Context, ...*campaigns.ChangesetEvent) error
+	getChangesetEvent     func(context.Context, GetChangesetEventOpts) (*campaigns.ChangesetEvent, error)
+	listCampaigns         func(context.Context, ListCampaignsOpts) ([]*campaigns.Campaign, int64, error)
 	transact              func(context.Context) (*Store, error)
 }
 
@@ -481,6 +483,14 @@ func (m MockSyncStore) UpsertChangesetEvents(ctx context.Context, cs ...*campaig
 	return m.upsertChangesetEvents(ctx, cs...)
 }
 
+func (m MockSyncStore) GetChangesetEvent(ctx context.Context, opts GetChangesetEventOpts) (*campaigns.ChangesetEvent, error) {
+	return m.getChangesetEvent(ctx, opts)
+}
+
+func (m MockSyncStore) ListCampaigns(ctx context.Context, opts ListCampaignsOpts) ([]*campaigns.Campaign, int64, error) {
+	return m.listCampaigns(ctx, opts)
+}
+
 func (m MockSyncStore) Transact(ctx context.Context) (*Store, error) {
 	return m.transact(ctx)
 }
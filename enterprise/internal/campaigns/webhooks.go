@@ -2,6 +2,7 @@ package campaigns
 
 import (
 	"context"
+	"crypto/subtle"
 	"database/sql"
 	"encoding/json"
 	"fmt"
@@ -24,6 +25,7 @@ import (
 	"github.com/sourcegraph/sourcegraph/internal/extsvc"
 	bbs "github.com/sourcegraph/sourcegraph/internal/extsvc/bitbucketserver"
 	"github.com/sourcegraph/sourcegraph/internal/extsvc/github"
+	"github.com/sourcegraph/sourcegraph/internal/extsvc/gitlab"
 	"github.com/sourcegraph/sourcegraph/schema"
 )
 
@@ -33,7 +35,7 @@ type Webhook struct {
 	Now   func() time.Time
 
 	// ServiceType corresponds to api.ExternalRepoSpec.ServiceType
-	// Example values: bitbucketserver.ServiceType, github.ServiceType
+	// Example values: bitbucketserver.ServiceType, github.ServiceType, gitlab.ServiceType
 	ServiceType string
 }
 
@@ -81,6 +83,8 @@ func extractExternalServiceID(extSvc *repos.ExternalService) (string, error) {
 		serviceID = c.Url
 	case *schema.BitbucketServerConnection:
 		serviceID = c.Url
+	case *schema.GitLabConnection:
+		serviceID = c.Url
 	}
 	if serviceID == "" {
 		return "", errors.New("could not determine service id")
@@ -955,6 +959,118 @@ func (h *BitbucketServerWebhook) convertEvent(theirs interface{}) (prs []PR, our
 	return
 }
 
+// GitLabWebhook receives GitLab project webhook events that are relevant to
+// campaigns, normalizes those events into ChangesetEvents and upserts them
+// to the database.
+type GitLabWebhook struct {
+	*Webhook
+}
+
+func NewGitLabWebhook(store *Store, repos repos.Store, now func() time.Time) *GitLabWebhook {
+	return &GitLabWebhook{&Webhook{store, repos, now, gitlab.ServiceType}}
+}
+
+// ServeHTTP implements the http.Handler interface.
+func (h *GitLabWebhook) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	e, extSvc, hErr := h.parseEvent(r)
+	if hErr != nil {
+		respond(w, hErr.code, hErr)
+		return
+	}
+
+	externalServiceID, err := extractExternalServiceID(extSvc)
+	if err != nil {
+		respond(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	pr, ev := h.convertEvent(e)
+	if pr == (PR{}) || ev == nil {
+		respond(w, http.StatusOK, nil) // Nothing to do
+		return
+	}
+
+	if err := h.upsertChangesetEvent(r.Context(), externalServiceID, pr, ev); err != nil {
+		respond(w, http.StatusInternalServerError, err)
+	}
+}
+
+func (h *GitLabWebhook) parseEvent(r *http.Request) (interface{}, *repos.ExternalService, *httpError) {
+	payload, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return nil, nil, &httpError{http.StatusInternalServerError, err}
+	}
+
+	// 🚨 SECURITY: GitLab sends the secret token of the webhook verbatim, so
+	// we authenticate the request by comparing it to the secrets stored in
+	// the GitLab external services config. If there are no secrets or none
+	// of them matches, we return a 401 to the client.
+	token := r.Header.Get("X-Gitlab-Token")
+	if token == "" {
+		return nil, nil, &httpError{http.StatusUnauthorized, errors.New("missing X-Gitlab-Token header")}
+	}
+
+	args := repos.StoreListExternalServicesArgs{Kinds: []string{"GITLAB"}}
+	es, err := h.Repos.ListExternalServices(r.Context(), args)
+	if err != nil {
+		return nil, nil, &httpError{http.StatusInternalServerError, err}
+	}
+
+	var extSvc *repos.ExternalService
+	for _, e := range es {
+		c, _ := e.Configuration()
+		con, ok := c.(*schema.GitLabConnection)
+		if !ok {
+			continue
+		}
+
+		for _, hook := range con.Webhooks {
+			if hook.Secret != "" && subtle.ConstantTimeCompare([]byte(hook.Secret), []byte(token)) == 1 {
+				extSvc = e
+				break
+			}
+		}
+		if extSvc != nil {
+			break
+		}
+	}
+
+	if extSvc == nil {
+		return nil, nil, &httpError{http.StatusUnauthorized, nil}
+	}
+
+	e, err := gitlab.ParseWebhookEvent(payload)
+	if err != nil {
+		return nil, nil, &httpError{http.StatusBadRequest, err}
+	}
+	return e, extSvc, nil
+}
+
+func (h *GitLabWebhook) convertEvent(theirs interface{}) (pr PR, ours interface{ Key() string }) {
+	log15.Debug("GitLab webhook received", "type", fmt.Sprintf("%T", theirs))
+
+	switch e := theirs.(type) {
+	case *gitlab.MergeRequestWebhookEvent:
+		ev, ok := e.ToEvent().(interface{ Key() string })
+		if !ok {
+			return PR{}, nil
+		}
+		pr = PR{ID: int64(e.ObjectAttributes.IID), RepoExternalID: strconv.Itoa(e.Project.ID)}
+		return pr, ev
+
+	case *gitlab.PipelineWebhookEvent:
+		if e.MergeRequest == nil {
+			// Pipelines that didn't run for a merge request can't change the
+			// state of a changeset.
+			return PR{}, nil
+		}
+		pr = PR{ID: int64(e.MergeRequest.IID), RepoExternalID: strconv.Itoa(e.Project.ID)}
+		return pr, e.Pipeline()
+	}
+
+	return PR{}, nil
+}
+
 type httpError struct {
 	code int
 	err  error
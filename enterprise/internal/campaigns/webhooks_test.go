@@ -25,6 +25,7 @@ import (
 	"github.com/sourcegraph/sourcegraph/cmd/repo-updater/repos"
 	"github.com/sourcegraph/sourcegraph/internal/campaigns"
 	"github.com/sourcegraph/sourcegraph/internal/extsvc/github"
+	"github.com/sourcegraph/sourcegraph/internal/extsvc/gitlab"
 	"github.com/sourcegraph/sourcegraph/internal/httpcli"
 	"github.com/sourcegraph/sourcegraph/internal/httptestutil"
 	"github.com/sourcegraph/sourcegraph/internal/rcache"
@@ -499,3 +500,111 @@ func parseTimestamp(t testing.TB, ts string) time.Time {
 
 	return timestamp
 }
+
+func TestGitLabWebhook(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now().UTC().Truncate(time.Microsecond)
+
+	store := new(repos.FakeStore)
+	err := store.UpsertExternalServices(ctx, &repos.ExternalService{
+		Kind:        "GITLAB",
+		DisplayName: "GitLab",
+		Config: marshalJSON(t, &schema.GitLabConnection{
+			Url:      "https://gitlab.example.com",
+			Token:    "token",
+			Webhooks: []*schema.GitLabWebhook{{Secret: "secret"}},
+		}),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	hook := NewGitLabWebhook(nil, store, func() time.Time { return now })
+
+	t.Run("authentication", func(t *testing.T) {
+		for _, tc := range []struct {
+			name  string
+			token string
+			want  int
+		}{
+			{"missing token", "", http.StatusUnauthorized},
+			{"wrong token", "wrong", http.StatusUnauthorized},
+			// Events of kinds we don't track are accepted and ignored.
+			{"valid token", "secret", http.StatusOK},
+		} {
+			t.Run(tc.name, func(t *testing.T) {
+				req := httptest.NewRequest("POST", "/.api/gitlab-webhooks", strings.NewReader(`{"object_kind": "push"}`))
+				if tc.token != "" {
+					req.Header.Set("X-Gitlab-Token", tc.token)
+				}
+				rec := httptest.NewRecorder()
+				hook.ServeHTTP(rec, req)
+				if rec.Code != tc.want {
+					t.Errorf("got status %d, want %d", rec.Code, tc.want)
+				}
+			})
+		}
+	})
+
+	t.Run("convertEvent", func(t *testing.T) {
+		user := gitlab.User{ID: 1, Username: "alice"}
+		project := gitlab.ProjectCommon{ID: 42, WebURL: "https://gitlab.example.com/a/b"}
+		updatedAt := time.Date(2020, 5, 1, 12, 0, 0, 0, time.UTC)
+
+		mergeRequestEvent := func(action string) *gitlab.MergeRequestWebhookEvent {
+			e := &gitlab.MergeRequestWebhookEvent{ObjectKind: "merge_request", User: user, Project: project}
+			e.ObjectAttributes.IID = 7
+			e.ObjectAttributes.Action = action
+			if err := json.Unmarshal([]byte(`"2020-05-01 12:00:00 UTC"`), &e.ObjectAttributes.UpdatedAt); err != nil {
+				t.Fatal(err)
+			}
+			return e
+		}
+		pipelineEvent := func(withMergeRequest bool) *gitlab.PipelineWebhookEvent {
+			e := &gitlab.PipelineWebhookEvent{ObjectKind: "pipeline", User: user, Project: project}
+			e.ObjectAttributes.ID = 3
+			e.ObjectAttributes.SHA = "deadbeef"
+			e.ObjectAttributes.Status = gitlab.PipelineStatusFailed
+			if withMergeRequest {
+				e.MergeRequest = &struct {
+					ID  int `json:"id"`
+					IID int `json:"iid"`
+				}{ID: 100, IID: 7}
+			}
+			return e
+		}
+		common := gitlab.MergeRequestEventCommon{User: user, CreatedAt: updatedAt}
+		pr := PR{ID: 7, RepoExternalID: "42"}
+
+		for _, tc := range []struct {
+			name   string
+			event  interface{}
+			wantPR PR
+			want   interface{ Key() string }
+		}{
+			{"close", mergeRequestEvent("close"), pr, &gitlab.MergeRequestClosedEvent{MergeRequestEventCommon: common}},
+			{"reopen", mergeRequestEvent("reopen"), pr, &gitlab.MergeRequestReopenedEvent{MergeRequestEventCommon: common}},
+			{"merge", mergeRequestEvent("merge"), pr, &gitlab.MergeRequestMergedEvent{MergeRequestEventCommon: common}},
+			{"approved", mergeRequestEvent("approved"), pr, &gitlab.ReviewApprovedEvent{Author: user, CreatedAt: updatedAt}},
+			{"unapproved", mergeRequestEvent("unapproved"), pr, &gitlab.ReviewUnapprovedEvent{Author: user, CreatedAt: updatedAt}},
+			{"update", mergeRequestEvent("update"), PR{}, nil},
+			{"pipeline", pipelineEvent(true), pr, &gitlab.Pipeline{
+				ID:     3,
+				SHA:    "deadbeef",
+				Status: gitlab.PipelineStatusFailed,
+				WebURL: "https://gitlab.example.com/a/b/pipelines/3",
+			}},
+			{"pipeline without merge request", pipelineEvent(false), PR{}, nil},
+		} {
+			t.Run(tc.name, func(t *testing.T) {
+				havePR, have := hook.convertEvent(tc.event)
+				if diff := cmp.Diff(tc.wantPR, havePR); diff != "" {
+					t.Errorf("pr: %s", diff)
+				}
+				if diff := cmp.Diff(tc.want, have); diff != "" {
+					t.Errorf("event: %s", diff)
+				}
+			})
+		}
+	})
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -71,6 +72,170 @@ func RunWorkers(ctx context.Context, s *Store, clock func() time.Time, gitClient
 	for i := 0; i < workerCount; i++ {
 		go worker()
 	}
+
+	go runRollouts(ctx, s, clock, backoffDuration)
+}
+
+// runRollouts periodically releases the ChangesetJobs of Campaigns with a
+// RolloutPolicy, until ctx is canceled.
+func runRollouts(ctx context.Context, s *Store, clock func() time.Time, interval time.Duration) {
+	for {
+		if err := ReleaseRollouts(ctx, s, clock()); err != nil {
+			log15.Error("Releasing changeset jobs", "err", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(interval):
+		}
+	}
+}
+
+// ReleaseRollouts releases the ChangesetJobs of all Campaigns that are being
+// rolled out, as far as their RolloutPolicy allows at the given time.
+func ReleaseRollouts(ctx context.Context, s *Store, now time.Time) error {
+	opts := ListCampaignsOpts{OnlyRollingOut: true}
+	for {
+		cs, next, err := s.ListCampaigns(ctx, opts)
+		if err != nil {
+			return errors.Wrap(err, "listing campaigns")
+		}
+
+		for _, c := range cs {
+			queue, err := GetRolloutQueue(ctx, s, c, now)
+			if err != nil {
+				return errors.Wrapf(err, "getting rollout queue of campaign %d", c.ID)
+			}
+
+			ids := make([]int64, 0, len(queue.Releasable))
+			for _, j := range queue.Releasable {
+				ids = append(ids, j.ID)
+			}
+			if err = s.ReleaseChangesetJobs(ctx, now, ids...); err != nil {
+				return errors.Wrapf(err, "releasing changeset jobs of campaign %d", c.ID)
+			}
+		}
+
+		if next == 0 {
+			return nil
+		}
+		opts.Cursor = next
+	}
+}
+
+// A RolloutQueue is the state of the rollout of the ChangesetJobs of a
+// Campaign at a point in time.
+type RolloutQueue struct {
+	// Queued are the ChangesetJobs that haven't been released or started
+	// yet, in the order in which they are released.
+	Queued []*campaigns.ChangesetJob
+	// Releasable are the ChangesetJobs at the start of Queued that can be
+	// released now.
+	Releasable []*campaigns.ChangesetJob
+	// CurrentBatch is the index of the batch of the RolloutPolicy whose
+	// ChangesetJobs are being released. It's equal to the number of batches
+	// once only ChangesetJobs of repositories without a batch are left.
+	CurrentBatch int
+	// NextReleaseAt is when the next ChangesetJobs can be released once the
+	// rate of the RolloutPolicy has been exhausted in the current window. It
+	// is zero if ChangesetJobs can be released now, or if releasing them
+	// waits for the current batch to finish.
+	NextReleaseAt time.Time
+}
+
+// GetRolloutQueue loads the ChangesetJobs and Patches of the given Campaign
+// and returns the state of its rollout at the given time. It returns an
+// empty queue if the Campaign doesn't have a RolloutPolicy.
+func GetRolloutQueue(ctx context.Context, s *Store, c *campaigns.Campaign, now time.Time) (*RolloutQueue, error) {
+	if c.RolloutPolicy == nil || c.PatchSetID == 0 {
+		return &RolloutQueue{}, nil
+	}
+
+	jobs, _, err := s.ListChangesetJobs(ctx, ListChangesetJobsOpts{CampaignID: c.ID, Limit: -1})
+	if err != nil {
+		return nil, err
+	}
+
+	patches, _, err := s.ListPatches(ctx, ListPatchesOpts{PatchSetID: c.PatchSetID, Limit: -1})
+	if err != nil {
+		return nil, err
+	}
+
+	repoIDs := make(map[int64]api.RepoID, len(patches))
+	for _, p := range patches {
+		repoIDs[p.ID] = p.RepoID
+	}
+
+	return ComputeRolloutQueue(c.RolloutPolicy, jobs, repoIDs, now), nil
+}
+
+// ComputeRolloutQueue returns the state of the rollout of the given
+// ChangesetJobs under the given RolloutPolicy at the given time. repoIDs maps
+// the PatchIDs of the ChangesetJobs to the IDs of their repositories.
+//
+// ChangesetJobs are released in the order of the batches of their
+// repositories and then by ID. The ChangesetJobs of a batch are only released
+// once all ChangesetJobs of the previous batches have finished, successfully
+// or not. At most policy.Rate ChangesetJobs are released per policy.Window.
+func ComputeRolloutQueue(policy *campaigns.RolloutPolicy, jobs []*campaigns.ChangesetJob, repoIDs map[int64]api.RepoID, now time.Time) *RolloutQueue {
+	q := &RolloutQueue{CurrentBatch: len(policy.Batches)}
+
+	batch := func(j *campaigns.ChangesetJob) int {
+		return policy.BatchIndex(repoIDs[j.PatchID])
+	}
+
+	var releasedInWindow []time.Time
+	for _, j := range jobs {
+		if j.FinishedAt.IsZero() {
+			if b := batch(j); b < q.CurrentBatch {
+				q.CurrentBatch = b
+			}
+		}
+
+		switch {
+		case !j.ReleasedAt.IsZero():
+			if now.Sub(j.ReleasedAt) < policy.Window {
+				releasedInWindow = append(releasedInWindow, j.ReleasedAt)
+			}
+		case j.StartedAt.IsZero():
+			q.Queued = append(q.Queued, j)
+		}
+	}
+
+	sort.SliceStable(q.Queued, func(i, k int) bool {
+		bi, bk := batch(q.Queued[i]), batch(q.Queued[k])
+		if bi != bk {
+			return bi < bk
+		}
+		return q.Queued[i].ID < q.Queued[k].ID
+	})
+
+	budget := len(q.Queued)
+	if policy.Rate > 0 {
+		budget = int(policy.Rate) - len(releasedInWindow)
+	}
+
+	for _, j := range q.Queued {
+		if len(q.Releasable) >= budget || batch(j) > q.CurrentBatch {
+			break
+		}
+		q.Releasable = append(q.Releasable, j)
+	}
+
+	if len(q.Releasable) == 0 && len(q.Queued) > 0 && len(releasedInWindow) > 0 && batch(q.Queued[0]) <= q.CurrentBatch {
+		// The rate is exhausted: the next job can be released once the
+		// earliest release in the window falls out of it.
+		earliest := releasedInWindow[0]
+		for _, t := range releasedInWindow[1:] {
+			if t.Before(earliest) {
+				earliest = t
+			}
+		}
+		q.NextReleaseAt = earliest.Add(policy.Window)
+	}
+
+	return q
 }
 
 // ExecChangesetJob will execute the given ChangesetJob for the given campaign.
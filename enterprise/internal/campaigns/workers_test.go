@@ -11,6 +11,7 @@ import (
 	"github.com/google/go-cmp/cmp/cmpopts"
 	"github.com/pkg/errors"
 	"github.com/sourcegraph/sourcegraph/cmd/repo-updater/repos"
+	"github.com/sourcegraph/sourcegraph/internal/api"
 	cmpgn "github.com/sourcegraph/sourcegraph/internal/campaigns"
 	"github.com/sourcegraph/sourcegraph/internal/db/dbconn"
 	"github.com/sourcegraph/sourcegraph/internal/db/dbtest"
@@ -241,6 +242,9 @@ func (s fakeChangesetSource) LoadChangesets(ctx context.Context, cs ...*repos.Ch
 func (s fakeChangesetSource) CloseChangeset(ctx context.Context, c *repos.Changeset) error {
 	return fakeNotImplemented
 }
+func (s fakeChangesetSource) MergeChangeset(ctx context.Context, c *repos.Changeset, method cmpgn.MergeMethod) error {
+	return fakeNotImplemented
+}
 
 func createGitHubRepo(t *testing.T, ctx context.Context, now time.Time, s *Store) (*repos.Repo, *repos.ExternalService) {
 	t.Helper()
@@ -405,3 +409,124 @@ func assertChangesetEventsInDB(t *testing.T, ctx context.Context, s *Store, chan
 		t.Fatal(diff)
 	}
 }
+
+func TestComputeRolloutQueue(t *testing.T) {
+	now := time.Now().UTC().Truncate(time.Microsecond)
+
+	// Patch i belongs to repository i.
+	repoIDs := map[int64]api.RepoID{1: 1, 2: 2, 3: 3, 4: 4}
+
+	job := func(id int64, released, started, finished time.Time) *cmpgn.ChangesetJob {
+		return &cmpgn.ChangesetJob{ID: id, PatchID: id, ReleasedAt: released, StartedAt: started, FinishedAt: finished}
+	}
+	var zero time.Time
+
+	type want struct {
+		queued, releasable []int64
+		currentBatch       int
+		nextReleaseAt      time.Time
+	}
+
+	tests := []struct {
+		name   string
+		policy *cmpgn.RolloutPolicy
+		jobs   []*cmpgn.ChangesetJob
+		want   want
+	}{
+		{
+			name:   "rate limits releases",
+			policy: &cmpgn.RolloutPolicy{Rate: 2, Window: time.Hour},
+			jobs: []*cmpgn.ChangesetJob{
+				job(1, zero, zero, zero),
+				job(2, zero, zero, zero),
+				job(3, zero, zero, zero),
+			},
+			want: want{queued: []int64{1, 2, 3}, releasable: []int64{1, 2}},
+		},
+		{
+			name:   "releases in window count against rate",
+			policy: &cmpgn.RolloutPolicy{Rate: 2, Window: time.Hour},
+			jobs: []*cmpgn.ChangesetJob{
+				job(1, now.Add(-2*time.Hour), now.Add(-2*time.Hour), now.Add(-2*time.Hour)),
+				job(2, now.Add(-30*time.Minute), now.Add(-30*time.Minute), now.Add(-30*time.Minute)),
+				job(3, zero, zero, zero),
+				job(4, zero, zero, zero),
+			},
+			want: want{queued: []int64{3, 4}, releasable: []int64{3}},
+		},
+		{
+			name:   "rate exhausted",
+			policy: &cmpgn.RolloutPolicy{Rate: 2, Window: time.Hour},
+			jobs: []*cmpgn.ChangesetJob{
+				job(1, now.Add(-30*time.Minute), now.Add(-30*time.Minute), now.Add(-30*time.Minute)),
+				job(2, now.Add(-10*time.Minute), zero, zero),
+				job(3, zero, zero, zero),
+			},
+			want: want{queued: []int64{3}, nextReleaseAt: now.Add(30 * time.Minute)},
+		},
+		{
+			name:   "batches are released in order",
+			policy: &cmpgn.RolloutPolicy{Rate: 10, Window: time.Hour, Batches: [][]api.RepoID{{3}, {1}}},
+			jobs: []*cmpgn.ChangesetJob{
+				job(1, zero, zero, zero),
+				job(2, zero, zero, zero),
+				job(3, zero, zero, zero),
+			},
+			want: want{queued: []int64{3, 1, 2}, releasable: []int64{3}},
+		},
+		{
+			name:   "next batch waits for current batch to finish",
+			policy: &cmpgn.RolloutPolicy{Rate: 10, Window: time.Hour, Batches: [][]api.RepoID{{3}, {1}}},
+			jobs: []*cmpgn.ChangesetJob{
+				job(1, zero, zero, zero),
+				job(2, zero, zero, zero),
+				job(3, now, now, zero),
+			},
+			want: want{queued: []int64{1, 2}},
+		},
+		{
+			name:   "failed jobs finish a batch",
+			policy: &cmpgn.RolloutPolicy{Rate: 10, Window: time.Hour, Batches: [][]api.RepoID{{3}, {1}}},
+			jobs: []*cmpgn.ChangesetJob{
+				job(1, zero, zero, zero),
+				job(2, zero, zero, zero),
+				{ID: 3, PatchID: 3, ReleasedAt: now, StartedAt: now, FinishedAt: now, Error: "failed"},
+			},
+			want: want{queued: []int64{1, 2}, releasable: []int64{1}, currentBatch: 1},
+		},
+		{
+			name:   "repositories without batch are released last",
+			policy: &cmpgn.RolloutPolicy{Rate: 10, Window: time.Hour, Batches: [][]api.RepoID{{3}}},
+			jobs: []*cmpgn.ChangesetJob{
+				job(1, zero, zero, zero),
+				job(2, zero, zero, zero),
+				job(3, now, now, now),
+			},
+			want: want{queued: []int64{1, 2}, releasable: []int64{1, 2}, currentBatch: 1},
+		},
+	}
+
+	ids := func(jobs []*cmpgn.ChangesetJob) []int64 {
+		var ids []int64
+		for _, j := range jobs {
+			ids = append(ids, j.ID)
+		}
+		return ids
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			q := ComputeRolloutQueue(tc.policy, tc.jobs, repoIDs, now)
+
+			have := want{
+				queued:        ids(q.Queued),
+				releasable:    ids(q.Releasable),
+				currentBatch:  q.CurrentBatch,
+				nextReleaseAt: q.NextReleaseAt,
+			}
+			if diff := cmp.Diff(tc.want, have, cmp.AllowUnexported(want{})); diff != "" {
+				t.Errorf("unexpected queue (-want +have):\n%s", diff)
+			}
+		})
+	}
+}
@@ -41,6 +41,11 @@ func (a *Actor) IsAuthenticated() bool {
 	return a != nil && a.UID != 0
 }
 
+// UIDHeader is the HTTP header with which internal services can make requests to the
+// frontend's internal API as the user with the given ID instead of as an internal actor, so
+// that the user's repository permissions apply.
+const UIDHeader = "X-Sourcegraph-Actor-UID"
+
 type key int
 
 const actorKey key = iota
@@ -100,6 +100,8 @@ type ConfigSavedQuery struct {
 	UserID          *int32  `json:"userID"`
 	OrgID           *int32  `json:"orgID"`
 	SlackWebhookURL *string `json:"slackWebhookURL"`
+	WebhookURL      *string `json:"webhookURL"`
+	TeamsWebhookURL *string `json:"teamsWebhookURL"`
 }
 
 func (sq ConfigSavedQuery) Equals(other ConfigSavedQuery) bool {
@@ -10,9 +10,11 @@ import (
 
 	"github.com/inconshreveable/log15"
 	"github.com/pkg/errors"
+	"github.com/sourcegraph/sourcegraph/cmd/replacer/protocol"
 	"github.com/sourcegraph/sourcegraph/internal/api"
 	"github.com/sourcegraph/sourcegraph/internal/extsvc/bitbucketserver"
 	"github.com/sourcegraph/sourcegraph/internal/extsvc/github"
+	"github.com/sourcegraph/sourcegraph/internal/extsvc/gitlab"
 )
 
 // SupportedExternalServices are the external service types currently supported
@@ -22,6 +24,7 @@ import (
 var SupportedExternalServices = map[string]struct{}{
 	github.ServiceType:          {},
 	bitbucketserver.ServiceType: {},
+	gitlab.ServiceType:          {},
 }
 
 // IsRepoSupported returns whether the given ExternalRepoSpec is supported by
@@ -37,12 +40,50 @@ type PatchSet struct {
 
 	UserID int32
 
+	// Query and Rewrite are only set on PatchSets whose Patches are computed
+	// by PatchJobs, in the repositories matched by Query.
+	Query   string
+	Rewrite *protocol.RewriteSpecification
+
 	CreatedAt time.Time
 	UpdatedAt time.Time
 }
 
 // Clone returns a clone of a PatchSet.
 func (c *PatchSet) Clone() *PatchSet {
+	cc := *c
+	if c.Rewrite != nil {
+		rewrite := *c.Rewrite
+		cc.Rewrite = &rewrite
+	}
+	return &cc
+}
+
+// A PatchJob is the computation of the Patch of a PatchSet in a single
+// repository, by applying the PatchSet's rewrite at a resolved revision.
+type PatchJob struct {
+	ID         int64
+	PatchSetID int64
+
+	RepoID  api.RepoID
+	Rev     api.CommitID
+	BaseRef string
+
+	// Only set once the PatchJob has successfully finished and the rewrite
+	// changed at least one file.
+	PatchID int64
+
+	Error string
+
+	StartedAt  time.Time
+	FinishedAt time.Time
+
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// Clone returns a clone of a PatchJob.
+func (c *PatchJob) Clone() *PatchJob {
 	cc := *c
 	return &cc
 }
@@ -83,15 +124,99 @@ type Campaign struct {
 	ChangesetIDs    []int64
 	PatchSetID      int64
 	ClosedAt        time.Time
+	RolloutPolicy   *RolloutPolicy
+	RolloutPaused   bool
+	AutoMergePolicy *AutoMergePolicy
 }
 
 // Clone returns a clone of a Campaign.
 func (c *Campaign) Clone() *Campaign {
 	cc := *c
 	cc.ChangesetIDs = c.ChangesetIDs[:len(c.ChangesetIDs):len(c.ChangesetIDs)]
+	if c.RolloutPolicy != nil {
+		cc.RolloutPolicy = c.RolloutPolicy.Clone()
+	}
+	if c.AutoMergePolicy != nil {
+		p := *c.AutoMergePolicy
+		cc.AutoMergePolicy = &p
+	}
 	return &cc
 }
 
+// A RolloutPolicy defines how the ChangesetJobs of a Campaign are released
+// over time, so that its changesets are published gradually instead of all at
+// once.
+type RolloutPolicy struct {
+	// Rate is the maximum number of ChangesetJobs released per Window. If it
+	// is zero, the number of released ChangesetJobs is not limited.
+	Rate   int32         `json:"rate"`
+	Window time.Duration `json:"window"`
+
+	// Batches are the groups of repositories whose ChangesetJobs are released
+	// in order: the ChangesetJobs of a batch are only released once all
+	// ChangesetJobs of the previous batches have finished. Repositories that
+	// are not in any batch are released after all batches.
+	Batches [][]api.RepoID `json:"batches,omitempty"`
+}
+
+// Clone returns a clone of a RolloutPolicy.
+func (p *RolloutPolicy) Clone() *RolloutPolicy {
+	pp := *p
+	if p.Batches != nil {
+		pp.Batches = make([][]api.RepoID, len(p.Batches))
+		for i, batch := range p.Batches {
+			pp.Batches[i] = append([]api.RepoID(nil), batch...)
+		}
+	}
+	return &pp
+}
+
+// BatchIndex returns the index of the batch that contains the given
+// repository, or len(p.Batches) if it isn't in any batch.
+func (p *RolloutPolicy) BatchIndex(repo api.RepoID) int {
+	for i, batch := range p.Batches {
+		for _, id := range batch {
+			if id == repo {
+				return i
+			}
+		}
+	}
+	return len(p.Batches)
+}
+
+// An AutoMergePolicy defines when the open changesets of a Campaign are merged
+// automatically.
+type AutoMergePolicy struct {
+	// RequiredApprovals is the number of reviewers that must have approved a
+	// changeset.
+	RequiredApprovals int32 `json:"required_approvals"`
+	// MergeMethod is how changesets are merged. If it is empty,
+	// MergeMethodMerge is used.
+	MergeMethod MergeMethod `json:"merge_method,omitempty"`
+}
+
+// MergeMethod defines how a changeset is merged into its base branch.
+type MergeMethod string
+
+// MergeMethod constants.
+const (
+	MergeMethodMerge  MergeMethod = "MERGE"
+	MergeMethodSquash MergeMethod = "SQUASH"
+	MergeMethodRebase MergeMethod = "REBASE"
+)
+
+// Valid returns true if the given MergeMethod is valid.
+func (m MergeMethod) Valid() bool {
+	switch m {
+	case MergeMethodMerge,
+		MergeMethodSquash,
+		MergeMethodRebase:
+		return true
+	default:
+		return false
+	}
+}
+
 // RemoveChangesetID removes the given id from the Campaigns ChangesetIDs slice.
 // If the id is not in ChangesetIDs calling this method doesn't have an effect.
 func (c *Campaign) RemoveChangesetID(id int64) {
@@ -239,6 +364,11 @@ type ChangesetJob struct {
 
 	Error string
 
+	// ReleasedAt is when the ChangesetJob of a Campaign with a RolloutPolicy
+	// was released for execution. ChangesetJobs of Campaigns without a
+	// RolloutPolicy are executed without being released.
+	ReleasedAt time.Time
+
 	StartedAt  time.Time
 	FinishedAt time.Time
 
@@ -305,6 +435,12 @@ func (c *Changeset) SetMetadata(meta interface{}) error {
 		c.ExternalServiceType = bitbucketserver.ServiceType
 		c.ExternalBranch = git.AbbreviateRef(pr.FromRef.ID)
 		c.ExternalUpdatedAt = unixMilliToTime(int64(pr.UpdatedDate))
+	case *gitlab.MergeRequest:
+		c.Metadata = pr
+		c.ExternalID = strconv.Itoa(pr.IID)
+		c.ExternalServiceType = gitlab.ServiceType
+		c.ExternalBranch = pr.SourceBranch
+		c.ExternalUpdatedAt = pr.UpdatedAt
 	default:
 		return errors.New("unknown changeset type")
 	}
@@ -328,6 +464,8 @@ func (c *Changeset) Title() (string, error) {
 		return m.Title, nil
 	case *bitbucketserver.PullRequest:
 		return m.Title, nil
+	case *gitlab.MergeRequest:
+		return m.Title, nil
 	default:
 		return "", errors.New("unknown changeset type")
 	}
@@ -342,6 +480,8 @@ func (c *Changeset) ExternalCreatedAt() time.Time {
 		return m.CreatedAt
 	case *bitbucketserver.PullRequest:
 		return unixMilliToTime(int64(m.CreatedDate))
+	case *gitlab.MergeRequest:
+		return m.CreatedAt
 	default:
 		return time.Time{}
 	}
@@ -354,6 +494,8 @@ func (c *Changeset) Body() (string, error) {
 		return m.Body, nil
 	case *bitbucketserver.PullRequest:
 		return m.Description, nil
+	case *gitlab.MergeRequest:
+		return m.Description, nil
 	default:
 		return "", errors.New("unknown changeset type")
 	}
@@ -387,6 +529,17 @@ func (c *Changeset) state() (s ChangesetState, err error) {
 		} else {
 			s = ChangesetState(m.State)
 		}
+	case *gitlab.MergeRequest:
+		switch m.State {
+		case gitlab.MergeRequestStateOpened:
+			s = ChangesetStateOpen
+		case gitlab.MergeRequestStateClosed, gitlab.MergeRequestStateLocked:
+			s = ChangesetStateClosed
+		case gitlab.MergeRequestStateMerged:
+			s = ChangesetStateMerged
+		default:
+			s = ChangesetState(m.State)
+		}
 	default:
 		return "", errors.New("unknown changeset type")
 	}
@@ -409,6 +562,8 @@ func (c *Changeset) URL() (s string, err error) {
 		}
 		selfLink := m.Links.Self[0]
 		return selfLink.Href, nil
+	case *gitlab.MergeRequest:
+		return m.WebURL, nil
 	default:
 		return "", errors.New("unknown changeset type")
 	}
@@ -461,6 +616,25 @@ func (c *Changeset) Events() (events []*ChangesetEvent) {
 			addEvent(s)
 		}
 
+	case *gitlab.MergeRequest:
+		events = make([]*ChangesetEvent, 0, len(m.Notes)+len(m.Pipelines))
+		addEvent := func(e Keyer) {
+			events = append(events, &ChangesetEvent{
+				ChangesetID: c.ID,
+				Key:         e.Key(),
+				Kind:        ChangesetEventKindFor(e),
+				Metadata:    e,
+			})
+		}
+		for _, n := range m.Notes {
+			if e, ok := n.ToEvent().(Keyer); ok {
+				addEvent(e)
+			}
+		}
+		for _, p := range m.Pipelines {
+			addEvent(p)
+		}
+
 	}
 	return events
 }
@@ -474,6 +648,8 @@ func (c *Changeset) HeadRefOid() (string, error) {
 		return m.HeadRefOid, nil
 	case *bitbucketserver.PullRequest:
 		return "", nil
+	case *gitlab.MergeRequest:
+		return m.DiffRefs.HeadSHA, nil
 	default:
 		return "", errors.New("unknown changeset type")
 	}
@@ -487,6 +663,8 @@ func (c *Changeset) HeadRef() (string, error) {
 		return "refs/heads/" + m.HeadRefName, nil
 	case *bitbucketserver.PullRequest:
 		return m.FromRef.ID, nil
+	case *gitlab.MergeRequest:
+		return "refs/heads/" + m.SourceBranch, nil
 	default:
 		return "", errors.New("unknown changeset type")
 	}
@@ -501,6 +679,8 @@ func (c *Changeset) BaseRefOid() (string, error) {
 		return m.BaseRefOid, nil
 	case *bitbucketserver.PullRequest:
 		return "", nil
+	case *gitlab.MergeRequest:
+		return m.DiffRefs.BaseSHA, nil
 	default:
 		return "", errors.New("unknown changeset type")
 	}
@@ -514,6 +694,8 @@ func (c *Changeset) BaseRef() (string, error) {
 		return "refs/heads/" + m.BaseRefName, nil
 	case *bitbucketserver.PullRequest:
 		return m.ToRef.ID, nil
+	case *gitlab.MergeRequest:
+		return "refs/heads/" + m.TargetBranch, nil
 	default:
 		return "", errors.New("unknown changeset type")
 	}
@@ -585,6 +767,16 @@ func (e *ChangesetEvent) Actor() string {
 		a = e.Actor.Login
 	case *github.LabelEvent:
 		a = e.Actor.Login
+	case *gitlab.ReviewApprovedEvent:
+		a = e.Author.Username
+	case *gitlab.ReviewUnapprovedEvent:
+		a = e.Author.Username
+	case *gitlab.MergeRequestClosedEvent:
+		a = e.User.Username
+	case *gitlab.MergeRequestReopenedEvent:
+		a = e.User.Username
+	case *gitlab.MergeRequestMergedEvent:
+		a = e.User.Username
 	}
 
 	return a
@@ -613,6 +805,20 @@ func (e *ChangesetEvent) ReviewAuthor() (string, error) {
 			return "", errors.New("activity user is blank")
 		}
 		return username, nil
+
+	case *gitlab.ReviewApprovedEvent:
+		username := meta.Author.Username
+		if username == "" {
+			return "", errors.New("approval author is blank")
+		}
+		return username, nil
+
+	case *gitlab.ReviewUnapprovedEvent:
+		username := meta.Author.Username
+		if username == "" {
+			return "", errors.New("unapproval author is blank")
+		}
+		return username, nil
 	default:
 		return "", nil
 	}
@@ -621,7 +827,8 @@ func (e *ChangesetEvent) ReviewAuthor() (string, error) {
 // ReviewState returns the review state of the ChangesetEvent if it is a review event.
 func (e *ChangesetEvent) ReviewState() (ChangesetReviewState, error) {
 	switch e.Kind {
-	case ChangesetEventKindBitbucketServerApproved:
+	case ChangesetEventKindBitbucketServerApproved,
+		ChangesetEventKindGitLabApproved:
 		return ChangesetReviewStateApproved, nil
 
 	// BitbucketServer's "REVIEWED" activity is created when someone clicks
@@ -644,7 +851,8 @@ func (e *ChangesetEvent) ReviewState() (ChangesetReviewState, error) {
 		return s, nil
 
 	case ChangesetEventKindGitHubReviewDismissed,
-		ChangesetEventKindBitbucketServerUnapproved:
+		ChangesetEventKindBitbucketServerUnapproved,
+		ChangesetEventKindGitLabUnapproved:
 		return ChangesetReviewStateDismissed, nil
 
 	default:
@@ -704,6 +912,20 @@ func (e *ChangesetEvent) Timestamp() time.Time {
 		t = unixMilliToTime(int64(e.CreatedDate))
 	case *bitbucketserver.CommitStatus:
 		t = unixMilliToTime(int64(e.Status.DateAdded))
+	case *gitlab.ReviewApprovedEvent:
+		t = e.CreatedAt
+	case *gitlab.ReviewUnapprovedEvent:
+		t = e.CreatedAt
+	case *gitlab.MergeRequestClosedEvent:
+		t = e.CreatedAt
+	case *gitlab.MergeRequestReopenedEvent:
+		t = e.CreatedAt
+	case *gitlab.MergeRequestMergedEvent:
+		t = e.CreatedAt
+	case *gitlab.Pipeline:
+		t = e.UpdatedAt
+	case *AutoMergeEvent:
+		t = e.CreatedAt
 	}
 
 	return t
@@ -1025,6 +1247,24 @@ func (e *ChangesetEvent) Update(o *ChangesetEvent) {
 		}
 		e.CheckRuns = o.CheckRuns
 
+	case *gitlab.Pipeline:
+		o := o.Metadata.(*gitlab.Pipeline)
+		// Pipelines are always received in full, so safe to replace them.
+		*e = *o
+
+	case *gitlab.ReviewApprovedEvent,
+		*gitlab.ReviewUnapprovedEvent,
+		*gitlab.MergeRequestClosedEvent,
+		*gitlab.MergeRequestReopenedEvent,
+		*gitlab.MergeRequestMergedEvent:
+		// These events are immutable and keyed by their timestamp, so there
+		// is nothing to update.
+
+	case *AutoMergeEvent:
+		o := o.Metadata.(*AutoMergeEvent)
+		// Attempts are recorded in full, so safe to replace them.
+		*e = *o
+
 	default:
 		panic(errors.Errorf("unknown changeset event metadata %T", e))
 	}
@@ -1156,6 +1396,18 @@ func ChangesetEventKindFor(e interface{}) ChangesetEventKind {
 		return ChangesetEventKind("bitbucketserver:" + strings.ToLower(string(e.Action)))
 	case *bitbucketserver.CommitStatus:
 		return ChangesetEventKindBitbucketServerCommitStatus
+	case *gitlab.ReviewApprovedEvent:
+		return ChangesetEventKindGitLabApproved
+	case *gitlab.ReviewUnapprovedEvent:
+		return ChangesetEventKindGitLabUnapproved
+	case *gitlab.MergeRequestClosedEvent:
+		return ChangesetEventKindGitLabClosed
+	case *gitlab.MergeRequestReopenedEvent:
+		return ChangesetEventKindGitLabReopened
+	case *gitlab.MergeRequestMergedEvent:
+		return ChangesetEventKindGitLabMerged
+	case *gitlab.Pipeline:
+		return ChangesetEventKindGitLabPipeline
 	default:
 		panic(errors.Errorf("unknown changeset event kind for %T", e))
 	}
@@ -1172,6 +1424,26 @@ func NewChangesetEventMetadata(k ChangesetEventKind) (interface{}, error) {
 		default:
 			return new(bitbucketserver.Activity), nil
 		}
+	case strings.HasPrefix(string(k), "gitlab"):
+		switch k {
+		case ChangesetEventKindGitLabApproved:
+			return new(gitlab.ReviewApprovedEvent), nil
+		case ChangesetEventKindGitLabUnapproved:
+			return new(gitlab.ReviewUnapprovedEvent), nil
+		case ChangesetEventKindGitLabClosed:
+			return new(gitlab.MergeRequestClosedEvent), nil
+		case ChangesetEventKindGitLabReopened:
+			return new(gitlab.MergeRequestReopenedEvent), nil
+		case ChangesetEventKindGitLabMerged:
+			return new(gitlab.MergeRequestMergedEvent), nil
+		case ChangesetEventKindGitLabPipeline:
+			return new(gitlab.Pipeline), nil
+		}
+	case strings.HasPrefix(string(k), "campaigns"):
+		switch k {
+		case ChangesetEventKindAutoMerged, ChangesetEventKindAutoMergeFailed:
+			return new(AutoMergeEvent), nil
+		}
 	case strings.HasPrefix(string(k), "github"):
 		switch k {
 		case ChangesetEventKindGitHubAssigned:
@@ -1252,8 +1524,27 @@ const (
 	ChangesetEventKindBitbucketServerCommented    ChangesetEventKind = "bitbucketserver:commented"
 	ChangesetEventKindBitbucketServerMerged       ChangesetEventKind = "bitbucketserver:merged"
 	ChangesetEventKindBitbucketServerCommitStatus ChangesetEventKind = "bitbucketserver:commit_status"
+
+	ChangesetEventKindGitLabApproved   ChangesetEventKind = "gitlab:approved"
+	ChangesetEventKindGitLabUnapproved ChangesetEventKind = "gitlab:unapproved"
+	ChangesetEventKindGitLabClosed     ChangesetEventKind = "gitlab:closed"
+	ChangesetEventKindGitLabReopened   ChangesetEventKind = "gitlab:reopened"
+	ChangesetEventKindGitLabMerged     ChangesetEventKind = "gitlab:merged"
+	ChangesetEventKindGitLabPipeline   ChangesetEventKind = "gitlab:pipeline"
+
+	ChangesetEventKindAutoMerged      ChangesetEventKind = "campaigns:auto_merged"
+	ChangesetEventKindAutoMergeFailed ChangesetEventKind = "campaigns:auto_merge_failed"
 )
 
+// An AutoMergeEvent records an attempt to merge a Changeset according to the
+// AutoMergePolicy of one of its Campaigns.
+type AutoMergeEvent struct {
+	CampaignID int64       `json:"campaign_id"`
+	Method     MergeMethod `json:"method"`
+	Error      string      `json:"error,omitempty"`
+	CreatedAt  time.Time   `json:"created_at"`
+}
+
 // ChangesetSyncData represents data about the sync status of a changeset
 type ChangesetSyncData struct {
 	ChangesetID int64
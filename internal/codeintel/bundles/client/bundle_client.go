@@ -30,6 +30,9 @@ type BundleClient interface {
 	// MonikerResults retrieves a page of locations attached to a moniker and a total count of such locations.
 	MonikerResults(ctx context.Context, modelType, scheme, identifier string, skip, take int) ([]Location, int, error)
 
+	// MonikerCounts retrieves every moniker of the given model type along with its number of locations.
+	MonikerCounts(ctx context.Context, modelType string) ([]MonikerCount, error)
+
 	// PackageInformation retrieves package information data by its identifier.
 	PackageInformation(ctx context.Context, path, packageInformationID string) (PackageInformationData, error)
 }
@@ -142,6 +145,16 @@ func (c *bundleClientImpl) MonikerResults(ctx context.Context, modelType, scheme
 	return locations, count, err
 }
 
+// MonikerCounts retrieves every moniker of the given model type along with its number of locations.
+func (c *bundleClientImpl) MonikerCounts(ctx context.Context, modelType string) (target []MonikerCount, err error) {
+	args := map[string]interface{}{
+		"modelType": modelType,
+	}
+
+	err = c.request(ctx, "monikerCounts", args, &target)
+	return target, err
+}
+
 // PackageInformation retrieves package information data by its identifier.
 func (c *bundleClientImpl) PackageInformation(ctx context.Context, path, packageInformationID string) (target PackageInformationData, err error) {
 	args := map[string]interface{}{
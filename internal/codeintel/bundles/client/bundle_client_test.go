@@ -245,6 +245,34 @@ func TestMonikerResults(t *testing.T) {
 	}
 }
 
+func TestMonikerCounts(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assertRequest(t, r, "GET", "/dbs/42/monikerCounts", map[string]string{
+			"modelType": "reference",
+		})
+
+		_, _ = w.Write([]byte(`[
+			{"scheme": "gomod", "identifier": "leftpad:pad", "count": 3},
+			{"scheme": "gomod", "identifier": "leftpad:trim", "count": 1}
+		]`))
+	}))
+	defer ts.Close()
+
+	expected := []MonikerCount{
+		{Scheme: "gomod", Identifier: "leftpad:pad", Count: 3},
+		{Scheme: "gomod", Identifier: "leftpad:trim", Count: 1},
+	}
+
+	client := &bundleClientImpl{bundleManagerURL: ts.URL, bundleID: 42}
+	monikerCounts, err := client.MonikerCounts(context.Background(), "reference")
+	if err != nil {
+		t.Fatalf("unexpected error querying moniker counts: %s", err)
+	}
+	if diff := cmp.Diff(expected, monikerCounts); diff != "" {
+		t.Errorf("unexpected moniker counts (-want +got):\n%s", diff)
+	}
+}
+
 func TestPackageInformation(t *testing.T) {
 	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		assertRequest(t, r, "GET", "/dbs/42/packageInformation", map[string]string{
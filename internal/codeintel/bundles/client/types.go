@@ -27,6 +27,13 @@ type MonikerData struct {
 	PackageInformationID string `json:"packageInformationID"`
 }
 
+// MonikerCount is the number of locations within a dump that define or reference a moniker.
+type MonikerCount struct {
+	Scheme     string `json:"scheme"`
+	Identifier string `json:"identifier"`
+	Count      int    `json:"count"`
+}
+
 // PackageInformationData describes a package within a package manager system.
 type PackageInformationData struct {
 	Name    string `json:"name"`
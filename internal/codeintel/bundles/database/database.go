@@ -283,6 +283,26 @@ func (db *Database) MonikerResults(tableName, scheme, identifier string, skip, t
 	return locations, totalCount, nil
 }
 
+// MonikerCount is the number of locations that define or reference a moniker.
+type MonikerCount struct {
+	Scheme     string `json:"scheme"`
+	Identifier string `json:"identifier"`
+	Count      int    `json:"count"`
+}
+
+// MonikerCounts returns every moniker in the given table along with the number of locations
+// that define or reference it. The result is ordered by scheme and identifier.
+func (db *Database) MonikerCounts(tableName string) ([]MonikerCount, error) {
+	query := "SELECT scheme, identifier, COUNT(*) AS count FROM '" + tableName + "' GROUP BY scheme, identifier ORDER BY scheme, identifier"
+
+	var monikerCounts []MonikerCount
+	if err := db.db.Select(&monikerCounts, query); err != nil {
+		return nil, err
+	}
+
+	return monikerCounts, nil
+}
+
 // PackageInformation looks up package information data by identifier.
 func (db *Database) PackageInformation(path string, packageInformationID types.ID) (types.PackageInformationData, bool, error) {
 	documentData, exists, err := db.getDocumentData(path)
@@ -219,6 +219,27 @@ func TestDatabaseMonikerResults(t *testing.T) {
 	}
 }
 
+func TestDatabaseMonikerCounts(t *testing.T) {
+	db := testOpenTestDatabase(t)
+	monikerCounts, err := db.MonikerCounts("references")
+	if err != nil {
+		t.Fatalf("unexpected error %s", err)
+	}
+
+	if len(monikerCounts) != 196 {
+		t.Errorf("unexpected moniker count. want=%d have=%d", 196, len(monikerCounts))
+	}
+
+	expected := []MonikerCount{
+		{Scheme: "gomod", Identifier: "github.com/alecthomas/kingpin", Count: 2},
+		{Scheme: "gomod", Identifier: "github.com/alecthomas/kingpin:BoolVar", Count: 1},
+		{Scheme: "gomod", Identifier: "github.com/alecthomas/kingpin:Default", Count: 2},
+	}
+	if diff := cmp.Diff(expected, monikerCounts[:3]); diff != "" {
+		t.Errorf("unexpected moniker counts (-want +got):\n%s", diff)
+	}
+}
+
 func TestDatabasePackageInformation(t *testing.T) {
 	db := testOpenTestDatabase(t)
 	if actual, exists, err := db.PackageInformation("protocol/protocol.go", types.ID("213")); err != nil {
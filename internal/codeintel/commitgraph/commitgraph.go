@@ -0,0 +1,243 @@
+// Package commitgraph determines which LSIF uploads can answer queries for each commit of a
+// repository. The result is materialized in Postgres so that finding the nearest uploads of a
+// commit does not require traversing the commit graph at query time.
+package commitgraph
+
+import (
+	"sort"
+	"strings"
+)
+
+// Graph maps each commit of a repository to its parent commits. Root commits are present
+// with an empty set of parents.
+type Graph map[string][]string
+
+// ParseGraph parses the output of `git log --pretty="%H %P"` into a commit graph.
+func ParseGraph(lines []string) Graph {
+	graph := Graph{}
+	for _, line := range lines {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+
+		graph[fields[0]] = append(graph[fields[0]], fields[1:]...)
+	}
+
+	return graph
+}
+
+// Upload is a processed upload positioned in the commit graph.
+type Upload struct {
+	ID      int
+	Commit  string
+	Root    string
+	Indexer string
+}
+
+// VisibleUpload is an upload visible from a commit, along with the length of the path
+// between that commit and the upload's commit.
+type VisibleUpload struct {
+	UploadID int
+	Distance int
+}
+
+// CalculateVisibleUploads returns the uploads visible from each commit of the given graph,
+// ordered by distance. An upload is visible from a commit when its commit is an ancestor or
+// a descendant of that commit and no other upload of the same indexer with an overlapping
+// root is closer. Commits without visible uploads are omitted from the result.
+func CalculateVisibleUploads(graph Graph, uploads []Upload) map[string][]VisibleUpload {
+	c := newCalculator(graph, uploads)
+	order := c.topologicalOrder()
+
+	ancestorVisible := c.propagate(order, c.parents)
+	reversed := make([]string, len(order))
+	for i, commit := range order {
+		reversed[len(order)-1-i] = commit
+	}
+	descendantVisible := c.propagate(reversed, c.children)
+
+	visible := make(map[string][]VisibleUpload, len(order))
+	for _, commit := range order {
+		candidates := append(append([]VisibleUpload(nil), ancestorVisible[commit]...), descendantVisible[commit]...)
+		if uploads := c.filter(candidates); len(uploads) > 0 {
+			visible[commit] = uploads
+		}
+	}
+
+	return visible
+}
+
+// CalculateVisibleUploadsFromTip returns the identifiers of the uploads visible from the given
+// tip of the default branch. Unlike CalculateVisibleUploads, only ancestors of the tip are
+// considered, as the tip has no descendants on the default branch.
+func CalculateVisibleUploadsFromTip(graph Graph, uploads []Upload, tipCommit string) []int {
+	c := newCalculator(graph, uploads)
+	ancestorVisible := c.propagate(c.topologicalOrder(), c.parents)
+
+	ids := make([]int, 0, len(ancestorVisible[tipCommit]))
+	for _, upload := range ancestorVisible[tipCommit] {
+		ids = append(ids, upload.UploadID)
+	}
+	sort.Ints(ids)
+
+	return ids
+}
+
+type calculator struct {
+	commits  []string
+	parents  map[string][]string
+	children map[string][]string
+	uploads  map[int]Upload
+	byCommit map[string][]int
+}
+
+func newCalculator(graph Graph, uploads []Upload) *calculator {
+	c := &calculator{
+		parents:  map[string][]string{},
+		children: map[string][]string{},
+		uploads:  map[int]Upload{},
+		byCommit: map[string][]int{},
+	}
+
+	seen := map[string]struct{}{}
+	add := func(commit string) {
+		if _, ok := seen[commit]; !ok {
+			seen[commit] = struct{}{}
+			c.commits = append(c.commits, commit)
+		}
+	}
+
+	for commit, parents := range graph {
+		add(commit)
+		for _, parent := range dedupe(parents) {
+			add(parent)
+			c.parents[commit] = append(c.parents[commit], parent)
+			c.children[parent] = append(c.children[parent], commit)
+		}
+	}
+
+	for _, upload := range uploads {
+		add(upload.Commit)
+		c.uploads[upload.ID] = upload
+		c.byCommit[upload.Commit] = append(c.byCommit[upload.Commit], upload.ID)
+	}
+
+	// Make the traversal order independent of map iteration order
+	sort.Strings(c.commits)
+	return c
+}
+
+// topologicalOrder returns all commits such that each commit follows its parents.
+func (c *calculator) topologicalOrder() []string {
+	inDegree := make(map[string]int, len(c.commits))
+	for _, commit := range c.commits {
+		inDegree[commit] = len(c.parents[commit])
+	}
+
+	var queue []string
+	for _, commit := range c.commits {
+		if inDegree[commit] == 0 {
+			queue = append(queue, commit)
+		}
+	}
+
+	order := make([]string, 0, len(c.commits))
+	for len(queue) > 0 {
+		commit := queue[0]
+		queue = queue[1:]
+		order = append(order, commit)
+
+		for _, child := range c.children[commit] {
+			if inDegree[child]--; inDegree[child] == 0 {
+				queue = append(queue, child)
+			}
+		}
+	}
+
+	return order
+}
+
+// propagate returns the uploads visible from each commit in one direction of the graph. The given
+// order must visit each commit after all of its neighbors, which are returned by the given map.
+func (c *calculator) propagate(order []string, neighbors map[string][]string) map[string][]VisibleUpload {
+	visible := make(map[string][]VisibleUpload, len(order))
+	for _, commit := range order {
+		var candidates []VisibleUpload
+		for _, id := range c.byCommit[commit] {
+			candidates = append(candidates, VisibleUpload{UploadID: id, Distance: 0})
+		}
+		for _, neighbor := range neighbors[commit] {
+			for _, upload := range visible[neighbor] {
+				candidates = append(candidates, VisibleUpload{UploadID: upload.UploadID, Distance: upload.Distance + 1})
+			}
+		}
+
+		visible[commit] = c.filter(candidates)
+	}
+
+	return visible
+}
+
+// filter returns the given candidate uploads ordered by distance, without duplicates and without
+// uploads shadowed by another candidate: one upload shadows another when it has the same indexer,
+// a root overlapping the other's, and a smaller distance. Ties are broken by upload identifier so
+// that a query never returns two uploads that could answer it equally well.
+func (c *calculator) filter(candidates []VisibleUpload) []VisibleUpload {
+	if len(candidates) == 0 {
+		return nil
+	}
+
+	distances := map[int]int{}
+	for _, candidate := range candidates {
+		if distance, ok := distances[candidate.UploadID]; !ok || candidate.Distance < distance {
+			distances[candidate.UploadID] = candidate.Distance
+		}
+	}
+
+	unique := make([]VisibleUpload, 0, len(distances))
+	for id, distance := range distances {
+		unique = append(unique, VisibleUpload{UploadID: id, Distance: distance})
+	}
+	sort.Slice(unique, func(i, j int) bool {
+		if unique[i].Distance != unique[j].Distance {
+			return unique[i].Distance < unique[j].Distance
+		}
+		return unique[i].UploadID < unique[j].UploadID
+	})
+
+	filtered := unique[:0:0]
+outer:
+	for i, candidate := range unique {
+		upload := c.uploads[candidate.UploadID]
+
+		for _, other := range unique[:i] {
+			if shadows(c.uploads[other.UploadID], upload) {
+				continue outer
+			}
+		}
+
+		filtered = append(filtered, candidate)
+	}
+
+	return filtered
+}
+
+// shadows returns true if the given uploads are of the same indexer and one root encloses the other.
+func shadows(a, b Upload) bool {
+	return a.Indexer == b.Indexer && (strings.HasPrefix(a.Root, b.Root) || strings.HasPrefix(b.Root, a.Root))
+}
+
+// dedupe returns the given commits without duplicates, retaining order.
+func dedupe(commits []string) []string {
+	deduped := commits[:0:0]
+	seen := map[string]struct{}{}
+	for _, commit := range commits {
+		if _, ok := seen[commit]; !ok {
+			seen[commit] = struct{}{}
+			deduped = append(deduped, commit)
+		}
+	}
+
+	return deduped
+}
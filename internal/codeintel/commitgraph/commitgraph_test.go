@@ -0,0 +1,259 @@
+package commitgraph
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func makeCommit(i int) string {
+	return fmt.Sprintf("%040d", i)
+}
+
+// visibleIDs returns the identifiers of the uploads visible from the given commit.
+func visibleIDs(visible map[string][]VisibleUpload, commit string) []int {
+	var ids []int
+	for _, upload := range visible[commit] {
+		ids = append(ids, upload.UploadID)
+	}
+	return ids
+}
+
+func TestParseGraph(t *testing.T) {
+	graph := ParseGraph([]string{
+		makeCommit(3) + " " + makeCommit(1) + " " + makeCommit(2),
+		makeCommit(2) + " " + makeCommit(1),
+		makeCommit(1),
+		"",
+	})
+
+	expected := Graph{
+		makeCommit(3): {makeCommit(1), makeCommit(2)},
+		makeCommit(2): {makeCommit(1)},
+		makeCommit(1): nil,
+	}
+	if diff := cmp.Diff(expected, graph); diff != "" {
+		t.Errorf("unexpected graph (-want +got):\n%s", diff)
+	}
+}
+
+func TestCalculateVisibleUploads(t *testing.T) {
+	// This repository has the following commit graph:
+	//
+	// [1] --+--- 2 --------+--5 -- 6 --+-- [7]
+	//       |              |           |
+	//       +-- [3] -- 4 --+           +--- 8
+
+	graph := Graph{
+		makeCommit(1): {},
+		makeCommit(2): {makeCommit(1)},
+		makeCommit(3): {makeCommit(1)},
+		makeCommit(4): {makeCommit(3)},
+		makeCommit(5): {makeCommit(2), makeCommit(4)},
+		makeCommit(6): {makeCommit(5)},
+		makeCommit(7): {makeCommit(6)},
+		makeCommit(8): {makeCommit(6)},
+	}
+	uploads := []Upload{
+		{ID: 1, Commit: makeCommit(1), Indexer: "lsif-go"},
+		{ID: 2, Commit: makeCommit(3), Indexer: "lsif-go"},
+		{ID: 3, Commit: makeCommit(7), Indexer: "lsif-go"},
+	}
+
+	visible := CalculateVisibleUploads(graph, uploads)
+
+	expected := map[string][]VisibleUpload{
+		makeCommit(1): {{UploadID: 1, Distance: 0}},
+		makeCommit(2): {{UploadID: 1, Distance: 1}},
+		makeCommit(3): {{UploadID: 2, Distance: 0}},
+		makeCommit(4): {{UploadID: 2, Distance: 1}},
+		makeCommit(5): {{UploadID: 1, Distance: 2}}, // ties with 2 and 3 are broken by identifier
+		makeCommit(6): {{UploadID: 3, Distance: 1}},
+		makeCommit(7): {{UploadID: 3, Distance: 0}},
+		makeCommit(8): {{UploadID: 1, Distance: 4}}, // ties with 2
+	}
+	if diff := cmp.Diff(expected, visible); diff != "" {
+		t.Errorf("unexpected visible uploads (-want +got):\n%s", diff)
+	}
+}
+
+func TestCalculateVisibleUploadsUnrelatedBranches(t *testing.T) {
+	// This repository has the following commit graph:
+	//
+	// 1 --+-- [2] ---- 3
+	//     |
+	//     +--- 4 --+-- 5 -- 6
+	//              |
+	//              +-- 7 -- 8
+
+	graph := Graph{
+		makeCommit(1): {},
+		makeCommit(2): {makeCommit(1)},
+		makeCommit(3): {makeCommit(2)},
+		makeCommit(4): {makeCommit(1)},
+		makeCommit(5): {makeCommit(4)},
+		makeCommit(6): {makeCommit(5)},
+		makeCommit(7): {makeCommit(4)},
+		makeCommit(8): {makeCommit(7)},
+	}
+
+	visible := CalculateVisibleUploads(graph, []Upload{{ID: 1, Commit: makeCommit(2), Indexer: "lsif-go"}})
+
+	// Siblings of the upload's commit are neither ancestors nor descendants
+	expected := map[string][]VisibleUpload{
+		makeCommit(1): {{UploadID: 1, Distance: 1}},
+		makeCommit(2): {{UploadID: 1, Distance: 0}},
+		makeCommit(3): {{UploadID: 1, Distance: 1}},
+	}
+	if diff := cmp.Diff(expected, visible); diff != "" {
+		t.Errorf("unexpected visible uploads (-want +got):\n%s", diff)
+	}
+}
+
+func TestCalculateVisibleUploadsOverlappingRoots(t *testing.T) {
+	// This repository has the following commit graph:
+	//
+	// 1 -- 2 --+-- 3 --+-- 5 -- 6
+	//          |       |
+	//          +-- 4 --+
+	//
+	// With the following LSIF uploads:
+	//
+	// | ID | Commit | Root    | Indexer |
+	// | -- + ------ + ------- + ------- |
+	// | 1  | 1      | root3/  | lsif-go |
+	// | 2  | 1      | root4/  | lsif-py |
+	// | 3  | 2      | root1/  | lsif-go |
+	// | 4  | 2      | root2/  | lsif-go |
+	// | 5  | 2      |         | lsif-py | (overwrites root4/ at commit 1)
+	// | 6  | 3      | root1/  | lsif-go | (overwrites root1/ at commit 2)
+	// | 7  | 4      |         | lsif-py | (overwrites (root) at commit 2)
+	// | 8  | 5      | root2/  | lsif-go | (overwrites root2/ at commit 2)
+	// | 9  | 6      | root1/  | lsif-go | (overwrites root1/ at commit 2)
+
+	graph := Graph{
+		makeCommit(1): {},
+		makeCommit(2): {makeCommit(1)},
+		makeCommit(3): {makeCommit(2)},
+		makeCommit(4): {makeCommit(2)},
+		makeCommit(5): {makeCommit(3), makeCommit(4)},
+		makeCommit(6): {makeCommit(5)},
+	}
+	uploads := []Upload{
+		{ID: 1, Commit: makeCommit(1), Root: "root3/", Indexer: "lsif-go"},
+		{ID: 2, Commit: makeCommit(1), Root: "root4/", Indexer: "lsif-py"},
+		{ID: 3, Commit: makeCommit(2), Root: "root1/", Indexer: "lsif-go"},
+		{ID: 4, Commit: makeCommit(2), Root: "root2/", Indexer: "lsif-go"},
+		{ID: 5, Commit: makeCommit(2), Root: "", Indexer: "lsif-py"},
+		{ID: 6, Commit: makeCommit(3), Root: "root1/", Indexer: "lsif-go"},
+		{ID: 7, Commit: makeCommit(4), Root: "", Indexer: "lsif-py"},
+		{ID: 8, Commit: makeCommit(5), Root: "root2/", Indexer: "lsif-go"},
+		{ID: 9, Commit: makeCommit(6), Root: "root1/", Indexer: "lsif-go"},
+	}
+
+	visible := CalculateVisibleUploads(graph, uploads)
+
+	expected := map[string][]int{
+		makeCommit(1): {1, 2, 3, 4},
+		makeCommit(2): {3, 4, 5, 1},
+		makeCommit(3): {6, 4, 5, 1},
+		makeCommit(4): {7, 3, 4, 1},
+		makeCommit(5): {8, 6, 7, 1},
+		makeCommit(6): {9, 8, 7, 1},
+	}
+	for commit, ids := range expected {
+		if diff := cmp.Diff(ids, visibleIDs(visible, commit)); diff != "" {
+			t.Errorf("unexpected visible uploads at %s (-want +got):\n%s", commit, diff)
+		}
+	}
+}
+
+func TestCalculateVisibleUploadsLongHistory(t *testing.T) {
+	// This repository has a linear history of 1000 commits with an upload at the root
+	graph := Graph{makeCommit(0): {}}
+	for i := 1; i < 1000; i++ {
+		graph[makeCommit(i)] = []string{makeCommit(i - 1)}
+	}
+
+	visible := CalculateVisibleUploads(graph, []Upload{{ID: 1, Commit: makeCommit(0), Indexer: "lsif-go"}})
+
+	if diff := cmp.Diff([]VisibleUpload{{UploadID: 1, Distance: 999}}, visible[makeCommit(999)]); diff != "" {
+		t.Errorf("unexpected visible uploads (-want +got):\n%s", diff)
+	}
+}
+
+func TestCalculateVisibleUploadsFromTip(t *testing.T) {
+	// This repository has the following commit graph:
+	//
+	// [1] -- 2 -- [3] -- [4] -- [5] -- [6] -- [7]
+
+	graph := Graph{
+		makeCommit(1): {},
+		makeCommit(2): {makeCommit(1)},
+		makeCommit(3): {makeCommit(2)},
+		makeCommit(4): {makeCommit(3)},
+		makeCommit(5): {makeCommit(4)},
+		makeCommit(6): {makeCommit(5)},
+		makeCommit(7): {makeCommit(6)},
+	}
+	uploads := []Upload{
+		{ID: 1, Commit: makeCommit(1), Root: "r1/", Indexer: "lsif-go"},
+		{ID: 2, Commit: makeCommit(3), Root: "r2/", Indexer: "lsif-go"},
+		{ID: 3, Commit: makeCommit(4), Root: "r1/", Indexer: "lsif-go"},
+		{ID: 4, Commit: makeCommit(6), Root: "r3/", Indexer: "lsif-go"},
+		{ID: 5, Commit: makeCommit(7), Root: "r4/", Indexer: "lsif-go"},
+		{ID: 6, Commit: makeCommit(1), Root: "r1/", Indexer: "lsif-tsc"},
+		{ID: 7, Commit: makeCommit(3), Root: "r2/", Indexer: "lsif-tsc"},
+		{ID: 8, Commit: makeCommit(4), Indexer: "lsif-tsc"},
+		{ID: 9, Commit: makeCommit(5), Root: "r3/", Indexer: "lsif-tsc"},
+	}
+
+	if diff := cmp.Diff([]int{2, 3, 4, 9}, CalculateVisibleUploadsFromTip(graph, uploads, makeCommit(6))); diff != "" {
+		t.Errorf("unexpected visible uploads (-want +got):\n%s", diff)
+	}
+}
+
+func TestCalculateVisibleUploadsFromTipBranchingPaths(t *testing.T) {
+	// This repository has the following commit graph:
+	//
+	// 1 --+-- [2] --- 3 ---+
+	//     |                |
+	//     +--- 4 --- [5] --+ -- [8] --+-- [9]
+	//     |                           |
+	//     +-- [6] --- 7 --------------+
+
+	graph := Graph{
+		makeCommit(1): {},
+		makeCommit(2): {makeCommit(1)},
+		makeCommit(3): {makeCommit(2)},
+		makeCommit(4): {makeCommit(1)},
+		makeCommit(5): {makeCommit(4)},
+		makeCommit(8): {makeCommit(5), makeCommit(3)},
+		makeCommit(9): {makeCommit(7), makeCommit(8)},
+		makeCommit(6): {makeCommit(1)},
+		makeCommit(7): {makeCommit(6)},
+	}
+	uploads := []Upload{
+		{ID: 1, Commit: makeCommit(2), Root: "r2/", Indexer: "lsif-go"},
+		{ID: 2, Commit: makeCommit(5), Root: "r2/a/", Indexer: "lsif-go"},
+		{ID: 3, Commit: makeCommit(5), Root: "r2/b/", Indexer: "lsif-go"},
+		{ID: 4, Commit: makeCommit(6), Root: "r1/a/", Indexer: "lsif-go"},
+		{ID: 5, Commit: makeCommit(6), Root: "r1/b/", Indexer: "lsif-go"},
+		{ID: 6, Commit: makeCommit(8), Root: "r1/", Indexer: "lsif-go"},
+		{ID: 7, Commit: makeCommit(9), Root: "r3/", Indexer: "lsif-go"},
+	}
+
+	if diff := cmp.Diff([]int{2, 3, 6, 7}, CalculateVisibleUploadsFromTip(graph, uploads, makeCommit(9))); diff != "" {
+		t.Errorf("unexpected visible uploads (-want +got):\n%s", diff)
+	}
+}
+
+func TestCalculateVisibleUploadsFromTipUnknownCommit(t *testing.T) {
+	graph := Graph{makeCommit(1): {}}
+	uploads := []Upload{{ID: 1, Commit: makeCommit(1), Indexer: "lsif-go"}}
+
+	if ids := CalculateVisibleUploadsFromTip(graph, uploads, makeCommit(2)); len(ids) != 0 {
+		t.Errorf("unexpected visible uploads. want=%v have=%v", []int{}, ids)
+	}
+}
@@ -0,0 +1,202 @@
+package db
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/keegancsmith/sqlf"
+	"github.com/sourcegraph/sourcegraph/internal/codeintel/commitgraph"
+)
+
+// commitGraphBatchSize is the maximum number of rows written by a single insert statement while
+// updating the commit graph of a repository. This keeps the number of query parameters well
+// below the Postgres limit.
+const commitGraphBatchSize = 5000
+
+// CommitGraphState describes the materialized commit graph of a repository with processed uploads.
+type CommitGraphState struct {
+	RepositoryID int
+	// TipCommit is the tip of the default branch when the commit graph was last calculated.
+	// This field is empty if the commit graph of the repository has never been calculated.
+	TipCommit string
+	// Stale is true if the set of processed uploads of the repository changed since the
+	// commit graph was last calculated.
+	Stale bool
+}
+
+// GetCommitGraphStates returns the state of the commit graph of every repository with a processed upload.
+func (db *dbImpl) GetCommitGraphStates(ctx context.Context) ([]CommitGraphState, error) {
+	query := `
+		SELECT
+			u.repository_id,
+			COALESCE(g.tip_commit, ''),
+			g.uploads_fingerprint IS DISTINCT FROM u.fingerprint
+		FROM (
+			SELECT repository_id, md5(string_agg(id::text, ',' ORDER BY id)) AS fingerprint
+			FROM lsif_dumps
+			GROUP BY repository_id
+		) u
+		LEFT JOIN lsif_commit_graphs g ON g.repository_id = u.repository_id
+		ORDER BY u.repository_id
+	`
+
+	return scanCommitGraphStates(db.query(ctx, sqlf.Sprintf(query)))
+}
+
+// UpdateCommits inserts the given parent edges into the commit graph of the given repository. Edges
+// that are already known are ignored.
+func (db *dbImpl) UpdateCommits(ctx context.Context, repositoryID int, graph commitgraph.Graph) (err error) {
+	tw, err := db.beginTx(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		err = closeTx(tw.tx, err)
+	}()
+
+	var values []*sqlf.Query
+	for commit, parents := range graph {
+		if len(parents) == 0 {
+			values = append(values, sqlf.Sprintf("(%s::text, %s::text)", commit, nil))
+		}
+
+		for _, parent := range parents {
+			values = append(values, sqlf.Sprintf("(%s::text, %s::text)", commit, parent))
+		}
+	}
+
+	// Root commits have a null parent, which does not conflict with the unique index
+	query := `
+		INSERT INTO lsif_commits (repository_id, commit, parent_commit)
+		SELECT %s, v.commit, v.parent_commit FROM (VALUES %s) AS v(commit, parent_commit)
+		WHERE NOT EXISTS (
+			SELECT 1 FROM lsif_commits c
+			WHERE c.repository_id = %s AND c.commit = v.commit AND c.parent_commit IS NOT DISTINCT FROM v.parent_commit
+		)
+		ON CONFLICT DO NOTHING
+	`
+
+	for len(values) > 0 {
+		n := commitGraphBatchSize
+		if n > len(values) {
+			n = len(values)
+		}
+
+		if _, err := tw.exec(ctx, sqlf.Sprintf(query, repositoryID, sqlf.Join(values[:n], ", "), repositoryID)); err != nil {
+			return err
+		}
+
+		values = values[n:]
+	}
+
+	return nil
+}
+
+// UpdateCommitGraph recalculates the uploads visible from each known commit of the given repository and the
+// visibility of its uploads at the given tip of the default branch.
+func (db *dbImpl) UpdateCommitGraph(ctx context.Context, repositoryID int, tipCommit string) error {
+	return db.updateCommitGraph(ctx, nil, repositoryID, tipCommit)
+}
+
+// updateCommitGraph recalculates the uploads visible from each known commit of the given repository and the
+// visibility of its uploads at the given tip of the default branch.
+func (db *dbImpl) updateCommitGraph(ctx context.Context, tw *transactionWrapper, repositoryID int, tipCommit string) (err error) {
+	if tw == nil {
+		tw, err = db.beginTx(ctx)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			err = closeTx(tw.tx, err)
+		}()
+	}
+
+	// Lock the commit graph of the repository until the end of the transaction so that
+	// concurrent updates do not interleave their writes
+	lockQuery := `
+		INSERT INTO lsif_commit_graphs (repository_id, tip_commit, uploads_fingerprint)
+		VALUES (%s, %s, '')
+		ON CONFLICT (repository_id) DO UPDATE SET tip_commit = EXCLUDED.tip_commit
+	`
+	if _, err := tw.exec(ctx, sqlf.Sprintf(lockQuery, repositoryID, tipCommit)); err != nil {
+		return err
+	}
+
+	graph, err := scanCommitGraph(tw.query(ctx, sqlf.Sprintf(`SELECT commit, parent_commit FROM lsif_commits WHERE repository_id = %s`, repositoryID)))
+	if err != nil {
+		return err
+	}
+
+	uploads, err := scanCommitGraphUploads(tw.query(ctx, sqlf.Sprintf(`SELECT id, commit, root, indexer FROM lsif_dumps WHERE repository_id = %s`, repositoryID)))
+	if err != nil {
+		return err
+	}
+
+	if _, err := tw.exec(ctx, sqlf.Sprintf(`DELETE FROM lsif_nearest_uploads WHERE repository_id = %s`, repositoryID)); err != nil {
+		return err
+	}
+
+	var values []*sqlf.Query
+	for commit, visibleUploads := range commitgraph.CalculateVisibleUploads(graph, uploads) {
+		for _, upload := range visibleUploads {
+			values = append(values, sqlf.Sprintf("(%s, %s, %s, %s)", repositoryID, commit, upload.UploadID, upload.Distance))
+		}
+	}
+
+	for len(values) > 0 {
+		n := commitGraphBatchSize
+		if n > len(values) {
+			n = len(values)
+		}
+
+		query := `INSERT INTO lsif_nearest_uploads (repository_id, commit, upload_id, distance) VALUES %s`
+		if _, err := tw.exec(ctx, sqlf.Sprintf(query, sqlf.Join(values[:n], ", "))); err != nil {
+			return err
+		}
+
+		values = values[n:]
+	}
+
+	visible := sqlf.Sprintf("false")
+	if ids := commitgraph.CalculateVisibleUploadsFromTip(graph, uploads, tipCommit); len(ids) > 0 {
+		visible = sqlf.Sprintf("d.id IN (%s)", sqlf.Join(intsToQueries(ids), ", "))
+	}
+
+	// Update dump records by:
+	//   (1) unsetting the visibility flag of all previously visible dumps, and
+	//   (2) setting the visibility flag of all currently visible dumps
+	visibilityQuery := `
+		UPDATE lsif_dumps d
+		SET visible_at_tip = %s
+		WHERE d.repository_id = %s AND (%s OR d.visible_at_tip)
+	`
+	if _, err := tw.exec(ctx, sqlf.Sprintf(visibilityQuery, visible, repositoryID, visible)); err != nil {
+		return err
+	}
+
+	fingerprintQuery := `UPDATE lsif_commit_graphs SET uploads_fingerprint = %s, updated_at = now() WHERE repository_id = %s`
+	_, err = tw.exec(ctx, sqlf.Sprintf(fingerprintQuery, uploadsFingerprint(uploads), repositoryID))
+	return err
+}
+
+// uploadsFingerprint returns a value that identifies the given set of uploads. This value matches the
+// fingerprint calculated for the same set of uploads by GetCommitGraphStates.
+func uploadsFingerprint(uploads []commitgraph.Upload) string {
+	ids := make([]int, 0, len(uploads))
+	for _, upload := range uploads {
+		ids = append(ids, upload.ID)
+	}
+	sort.Ints(ids)
+
+	strs := make([]string, 0, len(ids))
+	for _, id := range ids {
+		strs = append(strs, strconv.Itoa(id))
+	}
+
+	sum := md5.Sum([]byte(strings.Join(strs, ",")))
+	return hex.EncodeToString(sum[:])
+}
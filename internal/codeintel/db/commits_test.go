@@ -0,0 +1,91 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/sourcegraph/sourcegraph/internal/codeintel/commitgraph"
+	"github.com/sourcegraph/sourcegraph/internal/db/dbconn"
+	"github.com/sourcegraph/sourcegraph/internal/db/dbtesting"
+)
+
+func TestGetCommitGraphStates(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+	dbtesting.SetupGlobalTestDB(t)
+	db := &dbImpl{db: dbconn.Global}
+
+	insertUploads(t, db.db,
+		Upload{ID: 1, RepositoryID: 50},
+		Upload{ID: 2, RepositoryID: 51},
+		Upload{ID: 3, RepositoryID: 52},
+		Upload{ID: 4, RepositoryID: 53, State: "queued"},
+	)
+
+	if err := db.UpdateCommitGraph(context.Background(), 50, makeCommit(1)); err != nil {
+		t.Fatalf("unexpected error updating commit graph: %s", err)
+	}
+	if err := db.UpdateCommitGraph(context.Background(), 51, makeCommit(2)); err != nil {
+		t.Fatalf("unexpected error updating commit graph: %s", err)
+	}
+
+	// Invalidates the commit graph of repository 51
+	insertUploads(t, db.db, Upload{ID: 5, RepositoryID: 51})
+
+	states, err := db.GetCommitGraphStates(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error getting commit graph states: %s", err)
+	}
+
+	expected := []CommitGraphState{
+		{RepositoryID: 50, TipCommit: makeCommit(1), Stale: false},
+		{RepositoryID: 51, TipCommit: makeCommit(2), Stale: true},
+		{RepositoryID: 52, TipCommit: "", Stale: true},
+	}
+	if diff := cmp.Diff(expected, states); diff != "" {
+		t.Errorf("unexpected commit graph states (-want +got):\n%s", diff)
+	}
+}
+
+func TestUpdateCommits(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+	dbtesting.SetupGlobalTestDB(t)
+	db := &dbImpl{db: dbconn.Global}
+
+	insertCommits(t, db.db, map[string][]string{
+		makeCommit(1): {},
+		makeCommit(2): {makeCommit(1)},
+	})
+
+	// This graph overlaps with the existing commits of the repository
+	graph := commitgraph.Graph{
+		makeCommit(1): {},
+		makeCommit(2): {makeCommit(1)},
+		makeCommit(3): {makeCommit(2)},
+		makeCommit(4): {makeCommit(2)},
+		makeCommit(5): {makeCommit(3), makeCommit(4)},
+	}
+
+	if err := db.UpdateCommits(context.Background(), 50, graph); err != nil {
+		t.Fatalf("unexpected error updating commits: %s", err)
+	}
+
+	var count int
+	if err := db.db.QueryRow(`SELECT COUNT(*) FROM lsif_commits WHERE repository_id = 50`).Scan(&count); err != nil {
+		t.Fatalf("unexpected error counting commits: %s", err)
+	}
+	if count != 6 {
+		t.Errorf("unexpected number of commits. want=%d have=%d", 6, count)
+	}
+
+	insertUploads(t, db.db, Upload{ID: 1, Commit: makeCommit(1)})
+	updateCommitGraph(t, db, makeCommit(5))
+
+	testFindClosestDumps(t, db, []FindClosestDumpsTestCase{
+		{commit: makeCommit(5), file: "file.ts", allOfIDs: []int{1}},
+	})
+}
@@ -6,12 +6,17 @@ import (
 	"time"
 
 	"github.com/keegancsmith/sqlf"
+	"github.com/sourcegraph/sourcegraph/internal/codeintel/commitgraph"
 	"github.com/sourcegraph/sourcegraph/internal/db/dbutil"
 )
 
 // DB is the interface to Postgres that deals with LSIF-specific tables.
 //
+//   - lsif_commit_graphs
 //   - lsif_commits
+//   - lsif_indexes
+//   - lsif_moniker_references
+//   - lsif_nearest_uploads
 //   - lsif_packages
 //   - lsif_references
 //   - lsif_uploads
@@ -30,10 +35,10 @@ type DB interface {
 	// GetStates returns the states for the uploads with the given identifiers.
 	GetStates(ctx context.Context, ids []int) (map[int]string, error)
 
-	// DeleteUploadByID deletes an upload by its identifier. If the upload was visible at the tip of its repository's default branch,
-	// the visibility of all uploads for that repository are recalculated. The given function is expected to return the newest commit
-	// on the default branch when invoked.
-	DeleteUploadByID(ctx context.Context, id int, getTipCommit func(repositoryID int) (string, error)) (bool, error)
+	// DeleteUploadByID deletes an upload by its identifier. If the commit graph of the upload's repository has been calculated, it is
+	// recalculated at the same tip of the default branch so that the deleted upload no longer answers queries and the visibility of
+	// the remaining uploads is updated.
+	DeleteUploadByID(ctx context.Context, id int) (bool, error)
 
 	// ResetStalled moves all unlocked uploads processing for more than `StalledUploadMaxAge` back to the queued state.
 	// This method returns a list of updated upload identifiers.
@@ -43,6 +48,8 @@ type DB interface {
 	GetDumpByID(ctx context.Context, id int) (Dump, bool, error)
 
 	// FindClosestDumps returns the set of dumps that can most accurately answer queries for the given repository, commit, and file.
+	// The dumps are read from the nearest uploads of the commit recorded by the last update of the repository's commit graph, and are
+	// ordered by their distance from the commit.
 	FindClosestDumps(ctx context.Context, repositoryID int, commit, file string) ([]Dump, error)
 
 	// DeleteOldestDump deletes the oldest dump that is not currently visible at the tip of its repository's default branch.
@@ -58,8 +65,67 @@ type DB interface {
 
 	// PackageReferencePager returns a ReferencePager for dumps that belong to a remote repository (distinct from the given repository id)
 	// and reference the package with the given scheme, name, and version. All resulting dumps are visible at the tip of their repository's
-	// default branch.
+	// default branch. Dumps whose moniker references are indexed are excluded, as they are found via MonikerReferenceDumpIDs.
 	PackageReferencePager(ctx context.Context, scheme, name, version string, repositoryID, limit int) (int, ReferencePager, error)
+
+	// GetUnindexedDumpIDs returns the identifiers of up to limit dumps whose moniker references have not yet been written to the
+	// reverse index, oldest first.
+	GetUnindexedDumpIDs(ctx context.Context, limit int) ([]int, error)
+
+	// IndexMonikerReferences replaces the moniker references of the given dump and marks the dump as indexed.
+	IndexMonikerReferences(ctx context.Context, dumpID int, references []MonikerReference) error
+
+	// MonikerReferenceDumpIDs returns the identifiers of up to limit indexed dumps that belong to a remote repository (distinct from
+	// the given repository id), reference the package with the given scheme, name, and version, and reference the symbol with the
+	// given moniker identifier. All resulting dumps are visible at the tip of their repository's default branch. Dumps are ordered
+	// by identifier and only dumps with an identifier greater than afterDumpID are returned.
+	MonikerReferenceDumpIDs(ctx context.Context, scheme, identifier, name, version string, repositoryID, afterDumpID, limit int) ([]int, error)
+
+	// MonikerReferenceCount returns an estimate of the number of references to the symbol with the given moniker identifier from
+	// indexed dumps that reference the package with the given scheme, name, and version and are visible at the tip of their
+	// repository's default branch.
+	MonikerReferenceCount(ctx context.Context, scheme, identifier, name, version string) (int, error)
+
+	// InsertIndex inserts a new index job with a "queued" state for the given repository and commit unless one exists already.
+	// The repository name is recorded so that workers can fetch the repository. This method returns the identifier of the index
+	// job and a flag indicating whether it was inserted.
+	InsertIndex(ctx context.Context, repositoryID int, repositoryName, commit string) (int, bool, error)
+
+	// GetIndexByID returns an index job by its identifier and boolean flag indicating its existence.
+	GetIndexByID(ctx context.Context, id int) (Index, bool, error)
+
+	// GetIndexes returns a list of index jobs and the total count of records matching the given conditions. A repository
+	// identifier of zero matches index jobs of all repositories.
+	GetIndexes(ctx context.Context, repositoryID int, state string, limit, offset int) ([]Index, int, error)
+
+	// DequeueIndex marks the oldest queued index job that may be processed at the given time as processing and returns it,
+	// along with a flag indicating whether there was such an index job.
+	DequeueIndex(ctx context.Context, now time.Time) (Index, bool, error)
+
+	// MarkIndexComplete marks an index job as completed and records the identifier of the upload of its result.
+	MarkIndexComplete(ctx context.Context, id, uploadID int) error
+
+	// RequeueIndex records a failure of an index job and moves it back to the queued state. The index job is not processed
+	// again before processAfter.
+	RequeueIndex(ctx context.Context, id int, failureSummary string, processAfter time.Time) error
+
+	// MarkIndexErrored records a failure of an index job and marks it as errored. The index job is not retried.
+	MarkIndexErrored(ctx context.Context, id int, failureSummary string) error
+
+	// ResetStalledIndexes moves all index jobs processing for more than `StalledIndexMaxAge` back to the queued state,
+	// recording a failure. This method returns a list of updated index job identifiers.
+	ResetStalledIndexes(ctx context.Context, now time.Time) ([]int, error)
+
+	// GetCommitGraphStates returns the state of the commit graph of every repository with a processed upload.
+	GetCommitGraphStates(ctx context.Context) ([]CommitGraphState, error)
+
+	// UpdateCommits inserts the given parent edges into the commit graph of the given repository. Edges
+	// that are already known are ignored.
+	UpdateCommits(ctx context.Context, repositoryID int, graph commitgraph.Graph) error
+
+	// UpdateCommitGraph recalculates the uploads visible from each known commit of the given repository and the
+	// visibility of its uploads at the given tip of the default branch.
+	UpdateCommitGraph(ctx context.Context, repositoryID int, tipCommit string) error
 }
 
 type dbImpl struct {
@@ -88,6 +154,11 @@ func (db *dbImpl) queryRow(ctx context.Context, query *sqlf.Query) *sql.Row {
 	return db.db.QueryRowContext(ctx, query.Query(sqlf.PostgresBindVar), query.Args()...)
 }
 
+// exec performs Exec on the underlying connection.
+func (db *dbImpl) exec(ctx context.Context, query *sqlf.Query) (sql.Result, error) {
+	return db.db.ExecContext(ctx, query.Query(sqlf.PostgresBindVar), query.Args()...)
+}
+
 // beginTx performs BeginTx on the underlying connection and wraps the transaction.
 func (db *dbImpl) beginTx(ctx context.Context) (*transactionWrapper, error) {
 	tx, err := db.db.BeginTx(ctx, nil)
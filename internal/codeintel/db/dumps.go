@@ -54,30 +54,9 @@ func (db *dbImpl) GetDumpByID(ctx context.Context, id int) (Dump, bool, error) {
 }
 
 // FindClosestDumps returns the set of dumps that can most accurately answer queries for the given repository, commit, and file.
+// The dumps are read from the nearest uploads of the commit recorded by the last update of the repository's commit graph, and are
+// ordered by their distance from the commit.
 func (db *dbImpl) FindClosestDumps(ctx context.Context, repositoryID int, commit, file string) ([]Dump, error) {
-	tw, err := db.beginTx(ctx)
-	if err != nil {
-		return nil, err
-	}
-	defer func() {
-		err = closeTx(tw.tx, err)
-	}()
-
-	visibleIDsQuery := `
-		SELECT d.dump_id FROM lineage_with_dumps d
-		WHERE %s LIKE (d.root || '%%%%') AND d.dump_id IN (SELECT * FROM visible_ids)
-		ORDER BY d.n
-	`
-
-	ids, err := scanInts(tw.query(ctx, withBidirectionalLineage(visibleIDsQuery, repositoryID, commit, file)))
-	if err != nil {
-		return nil, err
-	}
-
-	if len(ids) == 0 {
-		return nil, nil
-	}
-
 	query := `
 		SELECT
 			d.id,
@@ -93,32 +72,13 @@ func (db *dbImpl) FindClosestDumps(ctx context.Context, repositoryID int, commit
 			d.tracing_context,
 			d.repository_id,
 			d.indexer
-		FROM lsif_dumps d WHERE id IN (%s)
+		FROM lsif_nearest_uploads n
+		JOIN lsif_dumps d ON d.id = n.upload_id
+		WHERE n.repository_id = %s AND n.commit = %s AND %s LIKE (d.root || '%%%%')
+		ORDER BY n.distance, d.id
 	`
 
-	dumps, err := scanDumps(tw.query(ctx, sqlf.Sprintf(query, sqlf.Join(intsToQueries(ids), ", "))))
-	if err != nil {
-		return nil, err
-	}
-
-	return deduplicateDumps(dumps), nil
-
-}
-
-// deduplicateDumps returns a copy of the given slice of dumps with duplicate identifiers removed.
-// The first dump with a unique identifier is retained.
-func deduplicateDumps(allDumps []Dump) (dumps []Dump) {
-	dumpIDs := map[int]struct{}{}
-	for _, dump := range allDumps {
-		if _, ok := dumpIDs[dump.ID]; ok {
-			continue
-		}
-
-		dumpIDs[dump.ID] = struct{}{}
-		dumps = append(dumps, dump)
-	}
-
-	return dumps
+	return scanDumps(db.query(ctx, sqlf.Sprintf(query, repositoryID, commit, file)))
 }
 
 // DeleteOldestDump deletes the oldest dump that is not currently visible at the tip of its repository's default branch.
@@ -141,28 +101,3 @@ func (db *dbImpl) DeleteOldestDump(ctx context.Context) (int, bool, error) {
 
 	return id, true, nil
 }
-
-// updateDumpsVisibleFromTip recalculates the visible_at_tip flag of all dumps of the given repository.
-func (db *dbImpl) updateDumpsVisibleFromTip(ctx context.Context, tw *transactionWrapper, repositoryID int, tipCommit string) (err error) {
-	if tw == nil {
-		tw, err = db.beginTx(ctx)
-		if err != nil {
-			return err
-		}
-		defer func() {
-			err = closeTx(tw.tx, err)
-		}()
-	}
-
-	// Update dump records by:
-	//   (1) unsetting the visibility flag of all previously visible dumps, and
-	//   (2) setting the visibility flag of all currently visible dumps
-	query := `
-		UPDATE lsif_dumps d
-		SET visible_at_tip = id IN (SELECT * from visible_ids)
-		WHERE d.repository_id = %s AND (d.id IN (SELECT * from visible_ids) OR d.visible_at_tip)
-	`
-
-	_, err = tw.exec(ctx, withAncestorLineage(query, repositoryID, tipCommit, repositoryID))
-	return err
-}
@@ -98,6 +98,7 @@ func TestFindClosestDumps(t *testing.T) {
 		Upload{ID: 2, Commit: makeCommit(3)},
 		Upload{ID: 3, Commit: makeCommit(7)},
 	)
+	updateCommitGraph(t, db, makeCommit(8))
 
 	testFindClosestDumps(t, db, []FindClosestDumpsTestCase{
 		{commit: makeCommit(1), file: "file.ts", anyOfIDs: []int{1}},
@@ -140,6 +141,7 @@ func TestFindClosestDumpsAlternateCommitGraph(t *testing.T) {
 	insertUploads(t, db.db,
 		Upload{ID: 1, Commit: makeCommit(2)},
 	)
+	updateCommitGraph(t, db, makeCommit(8))
 
 	testFindClosestDumps(t, db, []FindClosestDumpsTestCase{
 		{commit: makeCommit(1), allOfIDs: []int{1}},
@@ -173,6 +175,7 @@ func TestFindClosestDumpsDistinctRoots(t *testing.T) {
 		Upload{ID: 1, Commit: makeCommit(2), Root: "root1/"},
 		Upload{ID: 2, Commit: makeCommit(2), Root: "root2/"},
 	)
+	updateCommitGraph(t, db, makeCommit(2))
 
 	testFindClosestDumps(t, db, []FindClosestDumpsTestCase{
 		{commit: makeCommit(1), file: "blah"},
@@ -230,6 +233,7 @@ func TestFindClosestDumpsOverlappingRoots(t *testing.T) {
 		Upload{ID: 8, Commit: makeCommit(5), Root: "root2/"},
 		Upload{ID: 9, Commit: makeCommit(6), Root: "root1/"},
 	)
+	updateCommitGraph(t, db, makeCommit(6))
 
 	testFindClosestDumps(t, db, []FindClosestDumpsTestCase{
 		{commit: makeCommit(4), file: "root1/file.ts", allOfIDs: []int{7, 3}},
@@ -240,7 +244,7 @@ func TestFindClosestDumpsOverlappingRoots(t *testing.T) {
 	})
 }
 
-func TestFindClosestDumpsMaxTraversalLimit(t *testing.T) {
+func TestFindClosestDumpsDistantCommit(t *testing.T) {
 	if testing.Short() {
 		t.Skip()
 	}
@@ -249,36 +253,52 @@ func TestFindClosestDumpsMaxTraversalLimit(t *testing.T) {
 
 	// This repository has the following commit graph (ancestors to the left):
 	//
-	// MAX_TRAVERSAL_LIMIT -- ... -- 2 -- 1 -- 0
+	// 500 -- ... -- 2 -- 1 -- [0]
 	//
-	// At commit `50`, the traversal limit will be reached before visiting commit `0`
-	// because commits are visited in this order:
-	//
-	// | depth | commit |
-	// | ----- | ------ |
-	// | 1     | 50     | (with direction 'A')
-	// | 2     | 50     | (with direction 'D')
-	// | 3     | 51     |
-	// | 4     | 49     |
-	// | 5     | 52     |
-	// | 6     | 48     |
-	// | ...   |        |
-	// | 99    | 99     |
-	// | 100   | 1      | (limit reached)
+	// The commit graph is materialized, so uploads are visible from commits
+	// at any distance.
 
 	commits := map[string][]string{}
-	for i := 0; i < MaxTraversalLimit; i++ {
+	for i := 0; i < 500; i++ {
 		commits[makeCommit(i)] = []string{makeCommit(i + 1)}
 	}
 
 	insertCommits(t, db.db, commits)
 	insertUploads(t, db.db, Upload{ID: 1, Commit: makeCommit(0)})
+	updateCommitGraph(t, db, makeCommit(0))
 
 	testFindClosestDumps(t, db, []FindClosestDumpsTestCase{
 		{commit: makeCommit(0), file: "file.ts", allOfIDs: []int{1}},
 		{commit: makeCommit(1), file: "file.ts", allOfIDs: []int{1}},
-		{commit: makeCommit(MaxTraversalLimit/2 - 1), file: "file.ts", allOfIDs: []int{1}},
-		{commit: makeCommit(MaxTraversalLimit / 2), file: "file.ts"},
+		{commit: makeCommit(250), file: "file.ts", allOfIDs: []int{1}},
+		{commit: makeCommit(500), file: "file.ts", allOfIDs: []int{1}},
+	})
+}
+
+func TestFindClosestDumpsUnknownCommit(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+	dbtesting.SetupGlobalTestDB(t)
+	db := &dbImpl{db: dbconn.Global}
+
+	insertCommits(t, db.db, map[string][]string{
+		makeCommit(1): {},
+		makeCommit(2): {makeCommit(1)},
+	})
+	insertUploads(t, db.db, Upload{ID: 1, Commit: makeCommit(1)})
+
+	// The commit graph has not been calculated yet
+	testFindClosestDumps(t, db, []FindClosestDumpsTestCase{
+		{commit: makeCommit(1), file: "file.ts"},
+	})
+
+	updateCommitGraph(t, db, makeCommit(2))
+
+	testFindClosestDumps(t, db, []FindClosestDumpsTestCase{
+		{commit: makeCommit(1), file: "file.ts", allOfIDs: []int{1}},
+		{commit: makeCommit(2), file: "file.ts", allOfIDs: []int{1}},
+		{commit: makeCommit(3), file: "file.ts"},
 	})
 }
 
@@ -402,7 +422,7 @@ func testPresence(needle int, haystack []int) bool {
 	return false
 }
 
-func TestUpdateDumpsVisibleFromTipOverlappingRootsSameIndexer(t *testing.T) {
+func TestUpdateCommitGraphVisibleFromTipOverlappingRootsSameIndexer(t *testing.T) {
 	if testing.Short() {
 		t.Skip()
 	}
@@ -432,9 +452,9 @@ func TestUpdateDumpsVisibleFromTipOverlappingRootsSameIndexer(t *testing.T) {
 		makeCommit(7): {makeCommit(6)},
 	})
 
-	err := db.updateDumpsVisibleFromTip(context.Background(), nil, 50, makeCommit(6))
+	err := db.UpdateCommitGraph(context.Background(), 50, makeCommit(6))
 	if err != nil {
-		t.Fatalf("unexpected error updating dumps visible from tip: %s", err)
+		t.Fatalf("unexpected error updating commit graph: %s", err)
 	}
 
 	visibilities := getDumpVisibilities(t, db.db)
@@ -445,7 +465,7 @@ func TestUpdateDumpsVisibleFromTipOverlappingRootsSameIndexer(t *testing.T) {
 	}
 }
 
-func TestUpdateDumpsVisibleFromTipOverlappingRoots(t *testing.T) {
+func TestUpdateCommitGraphVisibleFromTipOverlappingRoots(t *testing.T) {
 	if testing.Short() {
 		t.Skip()
 	}
@@ -478,9 +498,9 @@ func TestUpdateDumpsVisibleFromTipOverlappingRoots(t *testing.T) {
 		makeCommit(7): {makeCommit(6)},
 	})
 
-	err := db.updateDumpsVisibleFromTip(context.Background(), nil, 50, makeCommit(6))
+	err := db.UpdateCommitGraph(context.Background(), 50, makeCommit(6))
 	if err != nil {
-		t.Fatalf("unexpected error updating dumps visible from tip: %s", err)
+		t.Fatalf("unexpected error updating commit graph: %s", err)
 	}
 
 	visibilities := getDumpVisibilities(t, db.db)
@@ -490,7 +510,7 @@ func TestUpdateDumpsVisibleFromTipOverlappingRoots(t *testing.T) {
 	}
 }
 
-func TestUpdateDumpsVisibleFromTipBranchingPaths(t *testing.T) {
+func TestUpdateCommitGraphVisibleFromTipBranchingPaths(t *testing.T) {
 	if testing.Short() {
 		t.Skip()
 	}
@@ -527,9 +547,9 @@ func TestUpdateDumpsVisibleFromTipBranchingPaths(t *testing.T) {
 		makeCommit(7): {makeCommit(6)},
 	})
 
-	err := db.updateDumpsVisibleFromTip(context.Background(), nil, 50, makeCommit(9))
+	err := db.UpdateCommitGraph(context.Background(), 50, makeCommit(9))
 	if err != nil {
-		t.Fatalf("unexpected error updating dumps visible from tip: %s", err)
+		t.Fatalf("unexpected error updating commit graph: %s", err)
 	}
 
 	visibilities := getDumpVisibilities(t, db.db)
@@ -539,7 +559,7 @@ func TestUpdateDumpsVisibleFromTipBranchingPaths(t *testing.T) {
 	}
 }
 
-func TestUpdateDumpsVisibleFromTipMaxTraversalLimit(t *testing.T) {
+func TestUpdateCommitGraphVisibleFromTipDistantCommit(t *testing.T) {
 	if testing.Short() {
 		t.Skip()
 	}
@@ -548,43 +568,33 @@ func TestUpdateDumpsVisibleFromTipMaxTraversalLimit(t *testing.T) {
 
 	// This repository has the following commit graph (ancestors to the left):
 	//
-	// (MAX_TRAVERSAL_LIMIT + 1) -- ... -- 2 -- 1 -- 0
+	// 500 -- ... -- [250] -- ... -- 1 -- 0
 
 	commits := map[string][]string{}
-	for i := 0; i < MaxTraversalLimit+1; i++ {
+	for i := 0; i < 500; i++ {
 		commits[makeCommit(i)] = []string{makeCommit(i + 1)}
 	}
 
 	insertCommits(t, db.db, commits)
-	insertUploads(t, db.db, Upload{ID: 1, Commit: fmt.Sprintf("%040d", MaxTraversalLimit)})
-
-	if err := db.updateDumpsVisibleFromTip(context.Background(), nil, 50, makeCommit(MaxTraversalLimit)); err != nil {
-		t.Fatalf("unexpected error updating dumps visible from tip: %s", err)
-	} else {
-		visibilities := getDumpVisibilities(t, db.db)
-		expected := map[int]bool{1: true}
-		if diff := cmp.Diff(expected, visibilities); diff != "" {
-			t.Errorf("unexpected visibility (-want +got):\n%s", diff)
+	insertUploads(t, db.db, Upload{ID: 1, Commit: makeCommit(250)})
+
+	for _, testCase := range []struct {
+		tipCommit string
+		visible   bool
+	}{
+		{makeCommit(250), true},
+		{makeCommit(0), true},
+		{makeCommit(251), false},
+		{makeCommit(500), false},
+	} {
+		if err := db.UpdateCommitGraph(context.Background(), 50, testCase.tipCommit); err != nil {
+			t.Fatalf("unexpected error updating commit graph: %s", err)
 		}
-	}
-
-	if err := db.updateDumpsVisibleFromTip(context.Background(), nil, 50, makeCommit(1)); err != nil {
-		t.Fatalf("unexpected error updating dumps visible from tip: %s", err)
-	} else {
-		visibilities := getDumpVisibilities(t, db.db)
-		expected := map[int]bool{1: true}
-		if diff := cmp.Diff(expected, visibilities); diff != "" {
-			t.Errorf("unexpected visibility (-want +got):\n%s", diff)
-		}
-	}
 
-	if err := db.updateDumpsVisibleFromTip(context.Background(), nil, 50, makeCommit(0)); err != nil {
-		t.Fatalf("unexpected error updating dumps visible from tip: %s", err)
-	} else {
 		visibilities := getDumpVisibilities(t, db.db)
-		expected := map[int]bool{1: false}
+		expected := map[int]bool{1: testCase.visible}
 		if diff := cmp.Diff(expected, visibilities); diff != "" {
-			t.Errorf("unexpected visibility (-want +got):\n%s", diff)
+			t.Errorf("unexpected visibility at %s (-want +got):\n%s", testCase.tipCommit, diff)
 		}
 	}
 }
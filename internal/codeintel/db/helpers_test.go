@@ -149,6 +149,14 @@ func insertCommits(t *testing.T, db *sql.DB, commits map[string][]string) {
 	}
 }
 
+// updateCommitGraph recalculates the nearest uploads of each commit inserted via insertCommits at the
+// given tip commit. Fails the test on error.
+func updateCommitGraph(t *testing.T, db *dbImpl, tipCommit string) {
+	if err := db.UpdateCommitGraph(context.Background(), 50, tipCommit); err != nil {
+		t.Fatalf("unexpected error while updating commit graph: %s", err)
+	}
+}
+
 // getDumpVisibilities returns a map from dump identifiers to its visibility. Fails the test on error.
 func getDumpVisibilities(t *testing.T, db *sql.DB) map[int]bool {
 	visibilities, err := scanVisibilities(db.Query("SELECT id, visible_at_tip FROM lsif_dumps"))
@@ -0,0 +1,209 @@
+package db
+
+import (
+	"context"
+	"time"
+
+	"github.com/keegancsmith/sqlf"
+)
+
+// StalledIndexMaxAge is the maximum allowable duration an index job may be processing. An index
+// job that is processing for longer likely indicates that the worker that dequeued it has died.
+const StalledIndexMaxAge = time.Hour
+
+// Index is a subset of the lsif_indexes table and stores both queued and finished index jobs.
+type Index struct {
+	ID             int        `json:"id"`
+	Commit         string     `json:"commit"`
+	RepositoryID   int        `json:"repositoryId"`
+	RepositoryName string     `json:"repositoryName"`
+	State          string     `json:"state"`
+	FailureSummary *string    `json:"failureSummary"`
+	NumFailures    int        `json:"numFailures"`
+	QueuedAt       time.Time  `json:"queuedAt"`
+	ProcessAfter   *time.Time `json:"processAfter"`
+	StartedAt      *time.Time `json:"startedAt"`
+	FinishedAt     *time.Time `json:"finishedAt"`
+	UploadID       *int       `json:"uploadId"`
+	Rank           *int       `json:"placeInQueue"`
+}
+
+// indexColumns are the columns of an index job, as read by scanIndex. The table must be aliased
+// as i and the queue rank subquery as s.
+var indexColumns = sqlf.Sprintf(`
+	i.id,
+	i.commit,
+	i.repository_id,
+	i.repository_name,
+	i.state,
+	i.failure_summary,
+	i.num_failures,
+	i.queued_at,
+	i.process_after,
+	i.started_at,
+	i.finished_at,
+	i.upload_id,
+	s.rank
+`)
+
+// indexRanks is a subquery that ranks queued index jobs by their position in the queue.
+var indexRanks = sqlf.Sprintf(`
+	SELECT r.id, RANK() OVER (ORDER BY r.queued_at, r.id) as rank
+	FROM lsif_indexes r
+	WHERE r.state = 'queued'
+`)
+
+// InsertIndex inserts a new index job with a "queued" state for the given repository and commit unless one exists already.
+// The repository name is recorded so that workers can fetch the repository. This method returns the identifier of the index
+// job and a flag indicating whether it was inserted.
+func (db *dbImpl) InsertIndex(ctx context.Context, repositoryID int, repositoryName, commit string) (int, bool, error) {
+	query := `
+		INSERT INTO lsif_indexes (repository_id, repository_name, commit)
+		VALUES (%s, %s, %s)
+		ON CONFLICT (repository_id, commit) DO NOTHING
+		RETURNING id
+	`
+
+	id, err := scanInt(db.queryRow(ctx, sqlf.Sprintf(query, repositoryID, repositoryName, commit)))
+	if err == nil {
+		return id, true, nil
+	}
+	if err := ignoreErrNoRows(err); err != nil {
+		return 0, false, err
+	}
+
+	// The index job exists already
+	id, err = scanInt(db.queryRow(ctx, sqlf.Sprintf(`SELECT id FROM lsif_indexes WHERE repository_id = %s AND commit = %s`, repositoryID, commit)))
+	if err != nil {
+		return 0, false, err
+	}
+
+	return id, false, nil
+}
+
+// GetIndexByID returns an index job by its identifier and boolean flag indicating its existence.
+func (db *dbImpl) GetIndexByID(ctx context.Context, id int) (Index, bool, error) {
+	query := `SELECT %s FROM lsif_indexes i LEFT JOIN (%s) s ON i.id = s.id WHERE i.id = %s`
+
+	index, err := scanIndex(db.queryRow(ctx, sqlf.Sprintf(query, indexColumns, indexRanks, id)))
+	if err != nil {
+		return Index{}, false, ignoreErrNoRows(err)
+	}
+
+	return index, true, nil
+}
+
+// GetIndexes returns a list of index jobs and the total count of records matching the given conditions. A repository
+// identifier of zero matches index jobs of all repositories.
+func (db *dbImpl) GetIndexes(ctx context.Context, repositoryID int, state string, limit, offset int) (_ []Index, _ int, err error) {
+	tw, err := db.beginTx(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer func() {
+		err = closeTx(tw.tx, err)
+	}()
+
+	conds := []*sqlf.Query{sqlf.Sprintf("TRUE")}
+	if repositoryID != 0 {
+		conds = append(conds, sqlf.Sprintf("i.repository_id = %s", repositoryID))
+	}
+	if state != "" {
+		conds = append(conds, sqlf.Sprintf("i.state = %s", state))
+	}
+
+	countQuery := `SELECT COUNT(1) FROM lsif_indexes i WHERE %s`
+	count, err := scanInt(tw.queryRow(ctx, sqlf.Sprintf(countQuery, sqlf.Join(conds, " AND "))))
+	if err != nil {
+		return nil, 0, err
+	}
+
+	query := `
+		SELECT %s FROM lsif_indexes i
+		LEFT JOIN (%s) s ON i.id = s.id
+		WHERE %s ORDER BY i.queued_at DESC, i.id DESC LIMIT %d OFFSET %d
+	`
+
+	indexes, err := scanIndexes(tw.query(ctx, sqlf.Sprintf(query, indexColumns, indexRanks, sqlf.Join(conds, " AND "), limit, offset)))
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return indexes, count, nil
+}
+
+// DequeueIndex marks the oldest queued index job that may be processed at the given time as processing and returns it,
+// along with a flag indicating whether there was such an index job.
+func (db *dbImpl) DequeueIndex(ctx context.Context, now time.Time) (Index, bool, error) {
+	query := `
+		UPDATE lsif_indexes SET state = 'processing', started_at = %s
+		WHERE id = (
+			SELECT id FROM lsif_indexes
+			WHERE state = 'queued' AND (process_after IS NULL OR process_after <= %s)
+			ORDER BY queued_at, id
+			FOR UPDATE SKIP LOCKED
+			LIMIT 1
+		)
+		RETURNING id
+	`
+
+	id, err := scanInt(db.queryRow(ctx, sqlf.Sprintf(query, now.UTC(), now.UTC())))
+	if err != nil {
+		return Index{}, false, ignoreErrNoRows(err)
+	}
+
+	// The index job is processing now, so no other worker dequeues it in the meantime
+	return db.GetIndexByID(ctx, id)
+}
+
+// MarkIndexComplete marks an index job as completed and records the identifier of the upload of its result.
+func (db *dbImpl) MarkIndexComplete(ctx context.Context, id, uploadID int) error {
+	query := `UPDATE lsif_indexes SET state = 'completed', finished_at = now(), upload_id = %s WHERE id = %s`
+	_, err := db.exec(ctx, sqlf.Sprintf(query, uploadID, id))
+	return err
+}
+
+// RequeueIndex records a failure of an index job and moves it back to the queued state. The index job is not processed
+// again before processAfter.
+func (db *dbImpl) RequeueIndex(ctx context.Context, id int, failureSummary string, processAfter time.Time) error {
+	query := `
+		UPDATE lsif_indexes
+		SET state = 'queued', failure_summary = %s, num_failures = num_failures + 1, process_after = %s, started_at = null
+		WHERE id = %s
+	`
+	_, err := db.exec(ctx, sqlf.Sprintf(query, failureSummary, processAfter.UTC(), id))
+	return err
+}
+
+// MarkIndexErrored records a failure of an index job and marks it as errored. The index job is not retried.
+func (db *dbImpl) MarkIndexErrored(ctx context.Context, id int, failureSummary string) error {
+	query := `
+		UPDATE lsif_indexes
+		SET state = 'errored', failure_summary = %s, num_failures = num_failures + 1, finished_at = now()
+		WHERE id = %s
+	`
+	_, err := db.exec(ctx, sqlf.Sprintf(query, failureSummary, id))
+	return err
+}
+
+// ResetStalledIndexes moves all index jobs processing for more than `StalledIndexMaxAge` back to the queued state,
+// recording a failure. This method returns a list of updated index job identifiers.
+func (db *dbImpl) ResetStalledIndexes(ctx context.Context, now time.Time) ([]int, error) {
+	query := `
+		UPDATE lsif_indexes i
+		SET state = 'queued', started_at = null, num_failures = num_failures + 1, failure_summary = 'index job stalled'
+		WHERE id = ANY(
+			SELECT id FROM lsif_indexes
+			WHERE state = 'processing' AND %s - started_at > (%s * interval '1 second')
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING i.id
+	`
+
+	ids, err := scanInts(db.query(ctx, sqlf.Sprintf(query, now.UTC(), StalledIndexMaxAge/time.Second)))
+	if err != nil {
+		return nil, err
+	}
+
+	return ids, nil
+}
@@ -0,0 +1,119 @@
+package db
+
+import (
+	"context"
+
+	"github.com/keegancsmith/sqlf"
+)
+
+// MonikerReference is a subset of the lsif_moniker_references table which records the number of
+// times a dump references the symbol with the given moniker scheme and identifier. This table is
+// the reverse index used to find the dumps that reference a particular symbol without having to
+// open the bundle of every dump that imports the symbol's package.
+type MonikerReference struct {
+	Scheme     string
+	Identifier string
+	Count      int
+}
+
+// monikerReferenceBatchSize is the maximum number of moniker references inserted in a single
+// statement. This keeps the number of bind variables well below the limit imposed by Postgres.
+const monikerReferenceBatchSize = 5000
+
+// GetUnindexedDumpIDs returns the identifiers of up to limit dumps whose moniker references have
+// not yet been written to the reverse index, oldest first.
+func (db *dbImpl) GetUnindexedDumpIDs(ctx context.Context, limit int) ([]int, error) {
+	query := `
+		SELECT d.id FROM lsif_dumps d
+		WHERE d.moniker_references_indexed = false
+		ORDER BY d.id LIMIT %d
+	`
+
+	return scanInts(db.query(ctx, sqlf.Sprintf(query, limit)))
+}
+
+// IndexMonikerReferences replaces the moniker references of the given dump and marks the dump as
+// indexed. After this method returns, the references of the dump are found via MonikerReferenceDumpIDs
+// and are no longer returned by PackageReferencePager.
+func (db *dbImpl) IndexMonikerReferences(ctx context.Context, dumpID int, references []MonikerReference) (err error) {
+	tw, err := db.beginTx(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		err = closeTx(tw.tx, err)
+	}()
+
+	if _, err := tw.exec(ctx, sqlf.Sprintf(`DELETE FROM lsif_moniker_references WHERE dump_id = %s`, dumpID)); err != nil {
+		return err
+	}
+
+	for len(references) > 0 {
+		batch := references
+		if len(batch) > monikerReferenceBatchSize {
+			batch = batch[:monikerReferenceBatchSize]
+		}
+		references = references[len(batch):]
+
+		var values []*sqlf.Query
+		for _, reference := range batch {
+			values = append(values, sqlf.Sprintf("(%s, %s, %s, %s)", dumpID, reference.Scheme, reference.Identifier, reference.Count))
+		}
+
+		query := `INSERT INTO lsif_moniker_references (dump_id, scheme, identifier, count) VALUES %s`
+		if _, err := tw.exec(ctx, sqlf.Sprintf(query, sqlf.Join(values, ", "))); err != nil {
+			return err
+		}
+	}
+
+	_, err = tw.exec(ctx, sqlf.Sprintf(`UPDATE lsif_uploads SET moniker_references_indexed = true WHERE id = %s`, dumpID))
+	return err
+}
+
+// MonikerReferenceDumpIDs returns the identifiers of up to limit indexed dumps that belong to a remote repository (distinct
+// from the given repository id), reference the package with the given scheme, name, and version, and reference the symbol
+// with the given moniker identifier. All resulting dumps are visible at the tip of their repository's default branch. Dumps
+// are ordered by identifier and only dumps with an identifier greater than afterDumpID are returned, so a page boundary is
+// stable under concurrent uploads.
+func (db *dbImpl) MonikerReferenceDumpIDs(ctx context.Context, scheme, identifier, name, version string, repositoryID, afterDumpID, limit int) ([]int, error) {
+	query := `
+		SELECT m.dump_id FROM lsif_moniker_references m
+		JOIN lsif_dumps d ON m.dump_id = d.id
+		WHERE %s ORDER BY m.dump_id LIMIT %d
+	`
+
+	conds := append(monikerReferenceConds(scheme, identifier, name, version),
+		sqlf.Sprintf("d.repository_id != %s", repositoryID),
+		sqlf.Sprintf("m.dump_id > %s", afterDumpID),
+	)
+
+	return scanInts(db.query(ctx, sqlf.Sprintf(query, sqlf.Join(conds, " AND "), limit)))
+}
+
+// MonikerReferenceCount returns an estimate of the number of references to the symbol with the given moniker identifier
+// from dumps that reference the package with the given scheme, name, and version and are visible at the tip of their
+// repository's default branch. Only indexed dumps are counted.
+func (db *dbImpl) MonikerReferenceCount(ctx context.Context, scheme, identifier, name, version string) (int, error) {
+	query := `
+		SELECT COALESCE(SUM(m.count), 0) FROM lsif_moniker_references m
+		JOIN lsif_dumps d ON m.dump_id = d.id
+		WHERE %s
+	`
+
+	conds := monikerReferenceConds(scheme, identifier, name, version)
+	return scanInt(db.queryRow(ctx, sqlf.Sprintf(query, sqlf.Join(conds, " AND "))))
+}
+
+// monikerReferenceConds returns the conditions shared by queries over the lsif_moniker_references table (aliased
+// as m) joined with the lsif_dumps view (aliased as d).
+func monikerReferenceConds(scheme, identifier, name, version string) []*sqlf.Query {
+	return []*sqlf.Query{
+		sqlf.Sprintf("m.scheme = %s", scheme),
+		sqlf.Sprintf("m.identifier = %s", identifier),
+		sqlf.Sprintf("d.visible_at_tip = true"),
+		sqlf.Sprintf(
+			"EXISTS (SELECT 1 FROM lsif_references r WHERE r.dump_id = m.dump_id AND r.scheme = %s AND r.name = %s AND r.version = %s)",
+			scheme, name, version,
+		),
+	}
+}
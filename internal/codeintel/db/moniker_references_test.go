@@ -0,0 +1,132 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/sourcegraph/sourcegraph/internal/db/dbconn"
+	"github.com/sourcegraph/sourcegraph/internal/db/dbtesting"
+)
+
+func TestGetUnindexedDumpIDs(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+	dbtesting.SetupGlobalTestDB(t)
+	db := &dbImpl{db: dbconn.Global}
+
+	insertUploads(t, db.db,
+		Upload{ID: 1},
+		Upload{ID: 2},
+		Upload{ID: 3, State: "processing"},
+		Upload{ID: 4},
+		Upload{ID: 5},
+	)
+
+	if err := db.IndexMonikerReferences(context.Background(), 2, nil); err != nil {
+		t.Fatalf("unexpected error indexing moniker references: %s", err)
+	}
+
+	if ids, err := db.GetUnindexedDumpIDs(context.Background(), 2); err != nil {
+		t.Fatalf("unexpected error getting unindexed dumps: %s", err)
+	} else if diff := cmp.Diff([]int{1, 4}, ids); diff != "" {
+		t.Errorf("unexpected dump ids (-want +got):\n%s", diff)
+	}
+}
+
+func TestMonikerReferenceDumpIDs(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+	dbtesting.SetupGlobalTestDB(t)
+	db := &dbImpl{db: dbconn.Global}
+
+	insertUploads(t, db.db,
+		Upload{ID: 1, Commit: makeCommit(1), VisibleAtTip: true},
+		Upload{ID: 2, Commit: makeCommit(2), VisibleAtTip: true, RepositoryID: 51},
+		Upload{ID: 3, Commit: makeCommit(3), VisibleAtTip: true, RepositoryID: 52},
+		Upload{ID: 4, Commit: makeCommit(4), VisibleAtTip: true, RepositoryID: 53},
+		Upload{ID: 5, Commit: makeCommit(5), VisibleAtTip: true, RepositoryID: 54},
+		Upload{ID: 6, Commit: makeCommit(6), VisibleAtTip: false, RepositoryID: 55},
+		Upload{ID: 7, Commit: makeCommit(6), VisibleAtTip: true, RepositoryID: 56},
+	)
+
+	insertReferences(t, db.db,
+		ReferenceModel{Scheme: "gomod", Name: "leftpad", Version: "0.1.0", DumpID: 1, Filter: []byte("f1")},
+		ReferenceModel{Scheme: "gomod", Name: "leftpad", Version: "0.1.0", DumpID: 2, Filter: []byte("f2")},
+		ReferenceModel{Scheme: "gomod", Name: "leftpad", Version: "0.1.0", DumpID: 3, Filter: []byte("f3")},
+		ReferenceModel{Scheme: "gomod", Name: "leftpad", Version: "0.2.0", DumpID: 4, Filter: []byte("f4")},
+		ReferenceModel{Scheme: "gomod", Name: "leftpad", Version: "0.1.0", DumpID: 5, Filter: []byte("f5")},
+		ReferenceModel{Scheme: "gomod", Name: "leftpad", Version: "0.1.0", DumpID: 6, Filter: []byte("f6")},
+		ReferenceModel{Scheme: "gomod", Name: "leftpad", Version: "0.1.0", DumpID: 7, Filter: []byte("f7")},
+	)
+
+	for _, dumpID := range []int{1, 2, 4, 5, 6, 7} {
+		references := []MonikerReference{
+			{Scheme: "gomod", Identifier: "leftpad:pad", Count: dumpID},
+			{Scheme: "gomod", Identifier: "leftpad:trim", Count: 1},
+		}
+		if dumpID == 5 {
+			// does not reference the target identifier
+			references = references[1:]
+		}
+
+		if err := db.IndexMonikerReferences(context.Background(), dumpID, references); err != nil {
+			t.Fatalf("unexpected error indexing moniker references: %s", err)
+		}
+	}
+
+	// dump 3 is not indexed and is paged via PackageReferencePager instead
+	if ids, err := db.MonikerReferenceDumpIDs(context.Background(), "gomod", "leftpad:pad", "leftpad", "0.1.0", 50, 0, 5); err != nil {
+		t.Fatalf("unexpected error getting dump ids: %s", err)
+	} else if diff := cmp.Diff([]int{2, 7}, ids); diff != "" {
+		t.Errorf("unexpected dump ids (-want +got):\n%s", diff)
+	}
+
+	if ids, err := db.MonikerReferenceDumpIDs(context.Background(), "gomod", "leftpad:pad", "leftpad", "0.1.0", 50, 2, 5); err != nil {
+		t.Fatalf("unexpected error getting dump ids: %s", err)
+	} else if diff := cmp.Diff([]int{7}, ids); diff != "" {
+		t.Errorf("unexpected dump ids (-want +got):\n%s", diff)
+	}
+
+	if count, err := db.MonikerReferenceCount(context.Background(), "gomod", "leftpad:pad", "leftpad", "0.1.0"); err != nil {
+		t.Fatalf("unexpected error counting references: %s", err)
+	} else if count != 1+2+7 {
+		t.Errorf("unexpected count. want=%d have=%d", 1+2+7, count)
+	}
+
+	totalCount, pager, err := db.PackageReferencePager(context.Background(), "gomod", "leftpad", "0.1.0", 50, 5)
+	if err != nil {
+		t.Fatalf("unexpected error getting pager: %s", err)
+	}
+	defer func() { _ = pager.CloseTx(nil) }()
+
+	if totalCount != 1 {
+		t.Errorf("unexpected count. want=%d have=%d", 1, totalCount)
+	}
+}
+
+func TestIndexMonikerReferencesReplaces(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+	dbtesting.SetupGlobalTestDB(t)
+	db := &dbImpl{db: dbconn.Global}
+
+	insertUploads(t, db.db, Upload{ID: 1, VisibleAtTip: true, RepositoryID: 51})
+	insertReferences(t, db.db, ReferenceModel{Scheme: "gomod", Name: "leftpad", Version: "0.1.0", DumpID: 1, Filter: []byte("f1")})
+
+	for _, count := range []int{3, 5} {
+		references := []MonikerReference{{Scheme: "gomod", Identifier: "leftpad:pad", Count: count}}
+		if err := db.IndexMonikerReferences(context.Background(), 1, references); err != nil {
+			t.Fatalf("unexpected error indexing moniker references: %s", err)
+		}
+	}
+
+	if count, err := db.MonikerReferenceCount(context.Background(), "gomod", "leftpad:pad", "leftpad", "0.1.0"); err != nil {
+		t.Fatalf("unexpected error counting references: %s", err)
+	} else if count != 5 {
+		t.Errorf("unexpected count. want=%d have=%d", 5, count)
+	}
+}
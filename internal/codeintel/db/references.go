@@ -28,8 +28,8 @@ func (db *dbImpl) SameRepoPager(ctx context.Context, repositoryID int, commit, s
 		}
 	}()
 
-	visibleIDsQuery := `SELECT id FROM visible_ids`
-	visibleIDs, err := scanInts(tw.query(ctx, withBidirectionalLineage(visibleIDsQuery, repositoryID, commit)))
+	visibleIDsQuery := `SELECT upload_id FROM lsif_nearest_uploads WHERE repository_id = %s AND commit = %s`
+	visibleIDs, err := scanInts(tw.query(ctx, sqlf.Sprintf(visibleIDsQuery, repositoryID, commit)))
 	if err != nil {
 		return 0, nil, err
 	}
@@ -66,7 +66,7 @@ func (db *dbImpl) SameRepoPager(ctx context.Context, repositoryID int, commit, s
 
 // PackageReferencePager returns a ReferencePager for dumps that belong to a remote repository (distinct from the given repository id)
 // and reference the package with the given scheme, name, and version. All resulting dumps are visible at the tip of their repository's
-// default branch.
+// default branch. Dumps whose moniker references are indexed are excluded, as they are found via MonikerReferenceDumpIDs.
 func (db *dbImpl) PackageReferencePager(ctx context.Context, scheme, name, version string, repositoryID, limit int) (_ int, _ ReferencePager, err error) {
 	tw, err := db.beginTx(ctx)
 	if err != nil {
@@ -84,6 +84,7 @@ func (db *dbImpl) PackageReferencePager(ctx context.Context, scheme, name, versi
 		sqlf.Sprintf("r.version = %s", version),
 		sqlf.Sprintf("d.repository_id != %s", repositoryID),
 		sqlf.Sprintf("d.visible_at_tip = true"),
+		sqlf.Sprintf("d.moniker_references_indexed = false"),
 	}
 
 	countQuery := `
@@ -38,6 +38,7 @@ func TestSameRepoPager(t *testing.T) {
 		makeCommit(3): {makeCommit(2)},
 		makeCommit(4): {makeCommit(3)},
 	})
+	updateCommitGraph(t, db, makeCommit(4))
 
 	totalCount, pager, err := db.SameRepoPager(context.Background(), 50, makeCommit(1), "gomod", "leftpad", "0.1.0", 5)
 	if err != nil {
@@ -116,6 +117,7 @@ func TestSameRepoPagerMultiplePages(t *testing.T) {
 	insertCommits(t, db.db, map[string][]string{
 		makeCommit(1): {},
 	})
+	updateCommitGraph(t, db, makeCommit(1))
 
 	totalCount, pager, err := db.SameRepoPager(context.Background(), 50, makeCommit(1), "gomod", "leftpad", "0.1.0", 3)
 	if err != nil {
@@ -184,6 +186,7 @@ func TestSameRepoPagerVisibility(t *testing.T) {
 		makeCommit(5): {makeCommit(4)},
 		makeCommit(6): {makeCommit(5)},
 	})
+	updateCommitGraph(t, db, makeCommit(6))
 
 	totalCount, pager, err := db.SameRepoPager(context.Background(), 50, makeCommit(6), "gomod", "leftpad", "0.1.0", 5)
 	if err != nil {
@@ -2,6 +2,8 @@ package db
 
 import (
 	"database/sql"
+
+	"github.com/sourcegraph/sourcegraph/internal/codeintel/commitgraph"
 )
 
 // Scanner is the common interface shared by *sql.Row and *sql.Rows.
@@ -93,6 +95,47 @@ func scanUploads(rows *sql.Rows, err error) ([]Upload, error) {
 	return uploads, nil
 }
 
+// scanIndex populates an Index value from the given scanner.
+func scanIndex(scanner Scanner) (index Index, err error) {
+	err = scanner.Scan(
+		&index.ID,
+		&index.Commit,
+		&index.RepositoryID,
+		&index.RepositoryName,
+		&index.State,
+		&index.FailureSummary,
+		&index.NumFailures,
+		&index.QueuedAt,
+		&index.ProcessAfter,
+		&index.StartedAt,
+		&index.FinishedAt,
+		&index.UploadID,
+		&index.Rank,
+	)
+	return index, err
+}
+
+// scanIndexes reads the given set of index rows and returns a slice of resulting
+// values. This method should be called directly with the return value of `*db.queryRows`.
+func scanIndexes(rows *sql.Rows, err error) ([]Index, error) {
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var indexes []Index
+	for rows.Next() {
+		index, err := scanIndex(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		indexes = append(indexes, index)
+	}
+
+	return indexes, nil
+}
+
 // scanReference populates a Reference value from the given scanner.
 func scanReference(scanner Scanner) (reference Reference, err error) {
 	err = scanner.Scan(&reference.DumpID, &reference.Filter)
@@ -126,6 +169,12 @@ func scanInt(scanner Scanner) (value int, err error) {
 	return value, err
 }
 
+// scanString populates a string value from the given scanner.
+func scanString(scanner Scanner) (value string, err error) {
+	err = scanner.Scan(&value)
+	return value, err
+}
+
 // scanInts reads the given set of `(int)` rows and returns a slice of resulting values.
 // This method should be called directly with the return value of `*db.queryRows`.
 func scanInts(rows *sql.Rows, err error) ([]int, error) {
@@ -201,3 +250,87 @@ func scanVisibilities(rows *sql.Rows, err error) (map[int]bool, error) {
 
 	return visibilities, nil
 }
+
+// scanCommitGraphState populates a CommitGraphState value from the given scanner.
+func scanCommitGraphState(scanner Scanner) (state CommitGraphState, err error) {
+	err = scanner.Scan(&state.RepositoryID, &state.TipCommit, &state.Stale)
+	return state, err
+}
+
+// scanCommitGraphStates reads the given set of commit graph state rows and returns a slice of
+// resulting values. This method should be called directly with the return value of `*db.queryRows`.
+func scanCommitGraphStates(rows *sql.Rows, err error) ([]CommitGraphState, error) {
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var states []CommitGraphState
+	for rows.Next() {
+		state, err := scanCommitGraphState(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		states = append(states, state)
+	}
+
+	return states, nil
+}
+
+// scanCommitGraph reads the given set of `(commit, parent_commit)` rows and returns the commit
+// graph they describe. This method should be called directly with the return value of `*db.queryRows`.
+func scanCommitGraph(rows *sql.Rows, err error) (commitgraph.Graph, error) {
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	graph := commitgraph.Graph{}
+	for rows.Next() {
+		var commit string
+		var parentCommit *string
+		if err := rows.Scan(&commit, &parentCommit); err != nil {
+			return nil, err
+		}
+
+		if parentCommit == nil {
+			if _, ok := graph[commit]; !ok {
+				graph[commit] = nil
+			}
+			continue
+		}
+
+		graph[commit] = append(graph[commit], *parentCommit)
+	}
+
+	return graph, nil
+}
+
+// scanCommitGraphUpload populates a commitgraph.Upload value from the given scanner.
+func scanCommitGraphUpload(scanner Scanner) (upload commitgraph.Upload, err error) {
+	err = scanner.Scan(&upload.ID, &upload.Commit, &upload.Root, &upload.Indexer)
+	return upload, err
+}
+
+// scanCommitGraphUploads reads the given set of `(id, commit, root, indexer)` rows and returns a
+// slice of resulting values. This method should be called directly with the return value of
+// `*db.queryRows`.
+func scanCommitGraphUploads(rows *sql.Rows, err error) ([]commitgraph.Upload, error) {
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var uploads []commitgraph.Upload
+	for rows.Next() {
+		upload, err := scanCommitGraphUpload(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		uploads = append(uploads, upload)
+	}
+
+	return uploads, nil
+}
@@ -2,6 +2,7 @@ package db
 
 import (
 	"context"
+	"database/sql"
 	"time"
 
 	"github.com/keegancsmith/sqlf"
@@ -180,10 +181,10 @@ func (db *dbImpl) GetStates(ctx context.Context, ids []int) (map[int]string, err
 	return scanStates(db.query(ctx, sqlf.Sprintf(query, sqlf.Join(intsToQueries(ids), ", "))))
 }
 
-// DeleteUploadByID deletes an upload by its identifier. If the upload was visible at the tip of its repository's default branch,
-// the visibility of all uploads for that repository are recalculated. The given function is expected to return the newest commit
-// on the default branch when invoked.
-func (db *dbImpl) DeleteUploadByID(ctx context.Context, id int, getTipCommit func(repositoryID int) (string, error)) (_ bool, err error) {
+// DeleteUploadByID deletes an upload by its identifier. If the commit graph of the upload's repository has been calculated, it is
+// recalculated at the same tip of the default branch so that the deleted upload no longer answers queries and the visibility of
+// the remaining uploads is updated.
+func (db *dbImpl) DeleteUploadByID(ctx context.Context, id int) (_ bool, err error) {
 	tw, err := db.beginTx(ctx)
 	if err != nil {
 		return false, err
@@ -195,24 +196,23 @@ func (db *dbImpl) DeleteUploadByID(ctx context.Context, id int, getTipCommit fun
 	query := `
 		DELETE FROM lsif_uploads
 		WHERE id = %s
-		RETURNING repository_id, visible_at_tip
+		RETURNING repository_id
 	`
 
-	repositoryID, visibleAtTip, err := scanVisibility(tw.queryRow(ctx, sqlf.Sprintf(query, id)))
+	repositoryID, err := scanInt(tw.queryRow(ctx, sqlf.Sprintf(query, id)))
 	if err != nil {
 		return false, ignoreErrNoRows(err)
 	}
 
-	if !visibleAtTip {
-		return true, nil
-	}
-
-	tipCommit, err := getTipCommit(repositoryID)
+	tipCommit, err := scanString(tw.queryRow(ctx, sqlf.Sprintf(`SELECT tip_commit FROM lsif_commit_graphs WHERE repository_id = %s`, repositoryID)))
 	if err != nil {
+		if err == sql.ErrNoRows {
+			return true, nil
+		}
 		return false, err
 	}
 
-	if err := db.updateDumpsVisibleFromTip(ctx, tw, repositoryID, tipCommit); err != nil {
+	if err := db.updateCommitGraph(ctx, tw, repositoryID, tipCommit); err != nil {
 		return false, err
 	}
 
@@ -301,18 +301,10 @@ func TestDeleteUploadByID(t *testing.T) {
 		Upload{ID: 1},
 	)
 
-	var called bool
-	getTipCommit := func(repositoryID int) (string, error) {
-		called = true
-		return "", nil
-	}
-
-	if found, err := db.DeleteUploadByID(context.Background(), 1, getTipCommit); err != nil {
+	if found, err := db.DeleteUploadByID(context.Background(), 1); err != nil {
 		t.Fatalf("unexpected error deleting upload: %s", err)
 	} else if !found {
 		t.Fatalf("expected record to exist")
-	} else if called {
-		t.Fatalf("unexpected call to getTipCommit")
 	}
 
 	// Upload no longer exists
@@ -330,11 +322,7 @@ func TestDeleteUploadByIDMissingRow(t *testing.T) {
 	dbtesting.SetupGlobalTestDB(t)
 	db := &dbImpl{db: dbconn.Global}
 
-	getTipCommit := func(repositoryID int) (string, error) {
-		return "", nil
-	}
-
-	if found, err := db.DeleteUploadByID(context.Background(), 1, getTipCommit); err != nil {
+	if found, err := db.DeleteUploadByID(context.Background(), 1); err != nil {
 		t.Fatalf("unexpected error deleting upload: %s", err)
 	} else if found {
 		t.Fatalf("unexpected record")
@@ -361,19 +349,12 @@ func TestDeleteUploadByIDUpdatesVisibility(t *testing.T) {
 		makeCommit(3): {makeCommit(2)},
 		makeCommit(4): {makeCommit(3)},
 	})
+	updateCommitGraph(t, db, makeCommit(4))
 
-	var called bool
-	getTipCommit := func(repositoryID int) (string, error) {
-		called = true
-		return makeCommit(4), nil
-	}
-
-	if found, err := db.DeleteUploadByID(context.Background(), 1, getTipCommit); err != nil {
+	if found, err := db.DeleteUploadByID(context.Background(), 1); err != nil {
 		t.Fatalf("unexpected error deleting upload: %s", err)
 	} else if !found {
 		t.Fatalf("expected record to exist")
-	} else if !called {
-		t.Fatalf("expected call to getTipCommit")
 	}
 
 	expected := map[int]bool{2: true, 3: true, 4: false}
@@ -381,6 +362,11 @@ func TestDeleteUploadByIDUpdatesVisibility(t *testing.T) {
 	if diff := cmp.Diff(expected, visibilities); diff != "" {
 		t.Errorf("unexpected visibility (-want +got):\n%s", diff)
 	}
+
+	// The deleted upload no longer answers queries
+	testFindClosestDumps(t, db, []FindClosestDumpsTestCase{
+		{commit: makeCommit(4), file: "sub1/file.ts", allOfIDs: []int{3}},
+	})
 }
 
 func TestResetStalled(t *testing.T) {
@@ -328,6 +328,16 @@ func AndOrQueryEnabled() bool {
 	return e.AndOrQuery == "enabled"
 }
 
+// CommitIndexEnabled returns true if gitserver should maintain commit
+// indexes and commit searches should use them.
+func CommitIndexEnabled() bool {
+	e := Get().ExperimentalFeatures
+	if e == nil || e.CommitIndex == "" {
+		return false
+	}
+	return e.CommitIndex == "enabled"
+}
+
 func SearchMultipleRevisionsPerRepository() bool {
 	x := ExperimentalFeatures()
 	return x.SearchMultipleRevisionsPerRepository != nil && *x.SearchMultipleRevisionsPerRepository
@@ -17,11 +17,12 @@ import (
 
 // Config contains the contents of a critical/site config along with associated metadata.
 type Config struct {
-	ID        int32     // the unique ID of this config
-	Type      string    // either "critical" or "site"
-	Contents  string    // the raw JSON content (with comments and trailing commas allowed)
-	CreatedAt time.Time // the date when this config was created
-	UpdatedAt time.Time // the date when this config was updated
+	ID           int32     // the unique ID of this config
+	Type         string    // either "critical" or "site"
+	Contents     string    // the raw JSON content (with comments and trailing commas allowed)
+	AuthorUserID *int32    // the ID of the user who saved this config, or nil if unknown (e.g., the default config)
+	CreatedAt    time.Time // the date when this config was created
+	UpdatedAt    time.Time // the date when this config was updated
 }
 
 // SiteConfig contains the contents of a site config along with associated metadata.
@@ -37,20 +38,22 @@ var ErrNewerEdit = errors.New("someone else has already applied a newer edit")
 
 // SiteCreateIfUpToDate saves the given site config "contents" to the database iff the
 // supplied "lastID" is equal to the one that was most recently saved to the database.
+// The new config is attributed to the user with ID "authorUserID", if non-nil. If
+// "tx" is non-nil, the config is saved in that transaction instead of a new one.
 //
 // The site config that was most recently saved to the database is returned.
 // An error is returned if "contents" is invalid JSON.
 //
 // 🚨 SECURITY: This method does NOT verify the user is an admin. The caller is
 // responsible for ensuring this or that the response never makes it to a user.
-func SiteCreateIfUpToDate(ctx context.Context, lastID *int32, contents string) (latest *SiteConfig, err error) {
-	tx, done, err := newTransaction(ctx)
+func SiteCreateIfUpToDate(ctx context.Context, tx *sql.Tx, lastID *int32, authorUserID *int32, contents string) (latest *SiteConfig, err error) {
+	q, done, err := newTransaction(ctx, tx)
 	if err != nil {
 		return nil, err
 	}
 	defer done()
 
-	newLastID, err := addDefault(ctx, tx, typeSite, confdefaults.Default.Site)
+	newLastID, err := addDefault(ctx, q, typeSite, confdefaults.Default.Site)
 	if err != nil {
 		return nil, err
 	}
@@ -58,27 +61,30 @@ func SiteCreateIfUpToDate(ctx context.Context, lastID *int32, contents string) (
 		lastID = newLastID
 	}
 
-	criticalSite, err := createIfUpToDate(ctx, tx, typeSite, lastID, contents)
+	criticalSite, err := createIfUpToDate(ctx, q, typeSite, lastID, authorUserID, contents)
 	return (*SiteConfig)(criticalSite), err
 }
 
 // CriticalCreateIfUpToDate saves the given critical config "contents" to the
 // database iff the supplied "lastID" is equal to the one that was most
-// recently saved to the database (i.e. SiteGetlatest's ID field).
+// recently saved to the database (i.e. SiteGetlatest's ID field). The new
+// config is attributed to the user with ID "authorUserID", if non-nil. If
+// "tx" is non-nil, the config is saved in that transaction instead of a new
+// one.
 //
 // The critical config that was most recently saved to the database is returned.
 // An error is returned if "contents" is invalid JSON.
 //
 // 🚨 SECURITY: This method does NOT verify the user is an admin. The caller is
 // responsible for ensuring this or that the response never makes it to a user.
-func CriticalCreateIfUpToDate(ctx context.Context, lastID *int32, contents string) (latest *CriticalConfig, err error) {
-	tx, done, err := newTransaction(ctx)
+func CriticalCreateIfUpToDate(ctx context.Context, tx *sql.Tx, lastID *int32, authorUserID *int32, contents string) (latest *CriticalConfig, err error) {
+	q, done, err := newTransaction(ctx, tx)
 	if err != nil {
 		return nil, err
 	}
 	defer done()
 
-	newLastID, err := addDefault(ctx, tx, typeCritical, confdefaults.Default.Critical)
+	newLastID, err := addDefault(ctx, q, typeCritical, confdefaults.Default.Critical)
 	if err != nil {
 		return nil, err
 	}
@@ -86,7 +92,7 @@ func CriticalCreateIfUpToDate(ctx context.Context, lastID *int32, contents strin
 		lastID = newLastID
 	}
 
-	criticalSite, err := createIfUpToDate(ctx, tx, typeCritical, lastID, contents)
+	criticalSite, err := createIfUpToDate(ctx, q, typeCritical, lastID, authorUserID, contents)
 	return (*CriticalConfig)(criticalSite), err
 }
 
@@ -96,7 +102,7 @@ func CriticalCreateIfUpToDate(ctx context.Context, lastID *int32, contents strin
 // 🚨 SECURITY: This method does NOT verify the user is an admin. The caller is
 // responsible for ensuring this or that the response never makes it to a user.
 func SiteGetLatest(ctx context.Context) (latest *SiteConfig, err error) {
-	tx, done, err := newTransaction(ctx)
+	tx, done, err := newTransaction(ctx, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -117,7 +123,7 @@ func SiteGetLatest(ctx context.Context) (latest *SiteConfig, err error) {
 // 🚨 SECURITY: This method does NOT verify the user is an admin. The caller is
 // responsible for ensuring this or that the response never makes it to a user.
 func CriticalGetLatest(ctx context.Context) (latest *CriticalConfig, err error) {
-	tx, done, err := newTransaction(ctx)
+	tx, done, err := newTransaction(ctx, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -132,7 +138,79 @@ func CriticalGetLatest(ctx context.Context) (latest *CriticalConfig, err error)
 	return (*CriticalConfig)(critical), err
 }
 
-func newTransaction(ctx context.Context) (tx queryable, done func(), err error) {
+// SiteListOptions specifies the options for listing site config revisions.
+type SiteListOptions struct {
+	BeforeID int32 // only list revisions older than the revision with this ID (0 means all revisions)
+	Limit    int   // the maximum number of revisions to return (0 means no limit)
+	Offset   int   // the number of revisions to skip
+}
+
+// SiteList returns the site config revisions that were saved to the database, most recent first.
+//
+// 🚨 SECURITY: This method does NOT verify the user is an admin. The caller is
+// responsible for ensuring this or that the response never makes it to a user.
+func SiteList(ctx context.Context, opt SiteListOptions) ([]*SiteConfig, error) {
+	conds := []*sqlf.Query{sqlf.Sprintf("type=%s", typeSite)}
+	if opt.BeforeID != 0 {
+		conds = append(conds, sqlf.Sprintf("id<%s", opt.BeforeID))
+	}
+	q := sqlf.Sprintf("SELECT s.id, s.type, s.contents, s.author_user_id, s.created_at, s.updated_at FROM critical_and_site_config s WHERE %s ORDER BY id DESC", sqlf.Join(conds, "AND"))
+	if opt.Limit > 0 {
+		q = sqlf.Sprintf("%s LIMIT %d", q, opt.Limit)
+	}
+	if opt.Offset > 0 {
+		q = sqlf.Sprintf("%s OFFSET %d", q, opt.Offset)
+	}
+	rows, err := dbconn.Global.QueryContext(ctx, q.Query(sqlf.PostgresBindVar), q.Args()...)
+	if err != nil {
+		return nil, err
+	}
+	versions, err := parseQueryRows(ctx, rows)
+	if err != nil {
+		return nil, err
+	}
+	sites := make([]*SiteConfig, len(versions))
+	for i, v := range versions {
+		sites[i] = (*SiteConfig)(v)
+	}
+	return sites, nil
+}
+
+// SiteCount returns the number of site config revisions that were saved to the database.
+func SiteCount(ctx context.Context) (count int, err error) {
+	q := sqlf.Sprintf("SELECT COUNT(*) FROM critical_and_site_config WHERE type=%s", typeSite)
+	err = dbconn.Global.QueryRowContext(ctx, q.Query(sqlf.PostgresBindVar), q.Args()...).Scan(&count)
+	return count, err
+}
+
+// SiteGetByID returns the site config revision with the given ID. This returns nil, nil if there
+// is no such site config revision.
+//
+// 🚨 SECURITY: This method does NOT verify the user is an admin. The caller is
+// responsible for ensuring this or that the response never makes it to a user.
+func SiteGetByID(ctx context.Context, id int32) (*SiteConfig, error) {
+	q := sqlf.Sprintf("SELECT s.id, s.type, s.contents, s.author_user_id, s.created_at, s.updated_at FROM critical_and_site_config s WHERE type=%s AND id=%s", typeSite, id)
+	rows, err := dbconn.Global.QueryContext(ctx, q.Query(sqlf.PostgresBindVar), q.Args()...)
+	if err != nil {
+		return nil, err
+	}
+	versions, err := parseQueryRows(ctx, rows)
+	if err != nil {
+		return nil, err
+	}
+	if len(versions) != 1 {
+		return nil, nil
+	}
+	return (*SiteConfig)(versions[0]), nil
+}
+
+// newTransaction begins a new transaction, unless the given transaction is
+// non-nil. In that case, it is used and done does nothing.
+func newTransaction(ctx context.Context, existing *sql.Tx) (tx queryable, done func(), err error) {
+	if existing != nil {
+		return existing, func() {}, nil
+	}
+
 	rtx, err := dbconn.Global.BeginTx(ctx, nil)
 	if err != nil {
 		return nil, nil, err
@@ -161,21 +239,23 @@ func addDefault(ctx context.Context, tx queryable, configType configType, conten
 	}
 
 	// Create the default.
-	latest, err = createIfUpToDate(ctx, tx, configType, nil, contents)
+	latest, err = createIfUpToDate(ctx, tx, configType, nil, nil, contents)
 	if err != nil {
 		return nil, err
 	}
 	return &latest.ID, nil
 }
 
-func createIfUpToDate(ctx context.Context, tx queryable, configType configType, lastID *int32, contents string) (latest *Config, err error) {
+func createIfUpToDate(ctx context.Context, tx queryable, configType configType, lastID *int32, authorUserID *int32, contents string) (latest *Config, err error) {
 	// Validate JSON syntax before saving.
 	if _, errs := jsonx.Parse(contents, jsonx.ParseOptions{Comments: true, TrailingCommas: true}); len(errs) > 0 {
 		return nil, fmt.Errorf("invalid settings JSON: %v", errs)
 	}
 
 	new := Config{
-		Contents: contents,
+		Type:         string(configType),
+		Contents:     contents,
+		AuthorUserID: authorUserID,
 	}
 
 	latest, err = getLatest(ctx, tx, configType)
@@ -188,8 +268,8 @@ func createIfUpToDate(ctx context.Context, tx queryable, configType configType,
 
 	err = tx.QueryRowContext(
 		ctx,
-		"INSERT INTO critical_and_site_config(type, contents) VALUES($1, $2) RETURNING id, created_at, updated_at",
-		configType, new.Contents,
+		"INSERT INTO critical_and_site_config(type, contents, author_user_id) VALUES($1, $2, $3) RETURNING id, created_at, updated_at",
+		configType, new.Contents, new.AuthorUserID,
 	).Scan(&new.ID, &new.CreatedAt, &new.UpdatedAt)
 	if err != nil {
 		return nil, err
@@ -198,7 +278,7 @@ func createIfUpToDate(ctx context.Context, tx queryable, configType configType,
 }
 
 func getLatest(ctx context.Context, tx queryable, configType configType) (*Config, error) {
-	q := sqlf.Sprintf("SELECT s.id, s.type, s.contents, s.created_at, s.updated_at FROM critical_and_site_config s WHERE type=%s ORDER BY id DESC LIMIT 1", configType)
+	q := sqlf.Sprintf("SELECT s.id, s.type, s.contents, s.author_user_id, s.created_at, s.updated_at FROM critical_and_site_config s WHERE type=%s ORDER BY id DESC LIMIT 1", configType)
 	rows, err := tx.QueryContext(ctx, q.Query(sqlf.PostgresBindVar), q.Args()...)
 	if err != nil {
 		return nil, err
@@ -219,7 +299,7 @@ func parseQueryRows(ctx context.Context, rows *sql.Rows) ([]*Config, error) {
 	defer rows.Close()
 	for rows.Next() {
 		f := Config{}
-		err := rows.Scan(&f.ID, &f.Type, &f.Contents, &f.CreatedAt, &f.UpdatedAt)
+		err := rows.Scan(&f.ID, &f.Type, &f.Contents, &f.AuthorUserID, &f.CreatedAt, &f.UpdatedAt)
 		if err != nil {
 			return nil, err
 		}
@@ -2,6 +2,7 @@ package confdb
 
 import (
 	"context"
+	"reflect"
 	"strings"
 	"testing"
 
@@ -36,7 +37,7 @@ func TestCriticalCreate_RejectInvalidJSON(t *testing.T) {
 
 	malformedJSON := "[This is malformed.}"
 
-	_, err := CriticalCreateIfUpToDate(ctx, nil, malformedJSON)
+	_, err := CriticalCreateIfUpToDate(ctx, nil, nil, nil, malformedJSON)
 
 	if err == nil || !strings.Contains(err.Error(), "invalid settings JSON") {
 		t.Fatalf("expected parse error after creating configuration with malformed JSON, got: %+v", err)
@@ -160,7 +161,7 @@ func TestCriticalCreateIfUpToDate(t *testing.T) {
 			dbtesting.SetupGlobalTestDB(t)
 			ctx := context.Background()
 			for _, p := range test.sequence {
-				output, err := CriticalCreateIfUpToDate(ctx, &p.input.lastID, p.input.contents)
+				output, err := CriticalCreateIfUpToDate(ctx, nil, &p.input.lastID, nil, p.input.contents)
 				if err != nil {
 					if err == p.expected.err {
 						continue
@@ -198,3 +199,69 @@ func TestCriticalCreateIfUpToDate(t *testing.T) {
 		})
 	}
 }
+
+func TestSiteListAndGetByID(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+
+	dbtesting.SetupGlobalTestDB(t)
+	ctx := context.Background()
+
+	latest, err := SiteGetLatest(ctx) // creates the default site config
+	if err != nil {
+		t.Fatal(err)
+	}
+	first, err := SiteCreateIfUpToDate(ctx, nil, &latest.ID, nil, `{"a": 1}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := SiteCreateIfUpToDate(ctx, nil, &first.ID, nil, `{"a": 2}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Critical config revisions are not part of the site config history.
+	if _, err := CriticalGetLatest(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	all, err := SiteList(ctx, SiteListOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ids []int32
+	for _, s := range all {
+		ids = append(ids, s.ID)
+	}
+	if want := []int32{second.ID, first.ID, latest.ID}; !reflect.DeepEqual(ids, want) {
+		t.Errorf("got IDs %v, want %v", ids, want)
+	}
+
+	if count, err := SiteCount(ctx); err != nil {
+		t.Fatal(err)
+	} else if count != 3 {
+		t.Errorf("got count %d, want 3", count)
+	}
+
+	previous, err := SiteList(ctx, SiteListOptions{BeforeID: second.ID, Limit: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(previous) != 1 || previous[0].ID != first.ID {
+		t.Errorf("got previous %+v, want ID %d", previous, first.ID)
+	}
+
+	got, err := SiteGetByID(ctx, first.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got == nil || got.Contents != `{"a": 1}` {
+		t.Errorf("got %+v, want contents %q", got, `{"a": 1}`)
+	}
+
+	if got, err := SiteGetByID(ctx, 12345); err != nil {
+		t.Fatal(err)
+	} else if got != nil {
+		t.Errorf("got %+v, want nil", got)
+	}
+}
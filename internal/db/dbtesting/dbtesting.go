@@ -108,10 +108,33 @@ func emptyDBPreserveSchema(t testing.TB, d *sql.DB) {
 	if testing.Verbose() {
 		t.Logf("Truncating all %d tables", len(tables))
 	}
-	_, err = d.Exec("TRUNCATE " + strings.Join(tables, ", ") + " RESTART IDENTITY")
+
+	tx, err := d.Begin()
 	if err != nil {
 		t.Fatal(err)
 	}
+	defer func() { _ = tx.Rollback() }()
+	// The audit log rejects TRUNCATE, so its trigger is disabled for this transaction only.
+	var hasAuditLog bool
+	if err := tx.QueryRow("SELECT EXISTS (SELECT 1 FROM pg_trigger WHERE tgname='audit_log_append_only_truncate')").Scan(&hasAuditLog); err != nil {
+		t.Fatal(err)
+	}
+	if hasAuditLog {
+		if _, err := tx.Exec("ALTER TABLE audit_log DISABLE TRIGGER audit_log_append_only_truncate"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := tx.Exec("TRUNCATE " + strings.Join(tables, ", ") + " RESTART IDENTITY"); err != nil {
+		t.Fatal(err)
+	}
+	if hasAuditLog {
+		if _, err := tx.Exec("ALTER TABLE audit_log ENABLE TRIGGER audit_log_append_only_truncate"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatal(err)
+	}
 }
 
 // initTest creates a test database, named with the given suffix
@@ -0,0 +1,119 @@
+package bitbucketcloud
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// User is a Bitbucket Cloud user account.
+type User struct {
+	UUID        string `json:"uuid"`
+	AccountID   string `json:"account_id"`
+	Nickname    string `json:"nickname"`
+	DisplayName string `json:"display_name"`
+}
+
+// RepoPermission is the effective permission a user has on a repository in a
+// workspace, i.e. the highest level of permission the user has through direct
+// grants and group memberships.
+type RepoPermission struct {
+	// Permission is one of "read", "write" or "admin".
+	Permission string `json:"permission"`
+	User       *User  `json:"user"`
+	Repository *Repo  `json:"repository"`
+}
+
+// CurrentUser returns the user the client is authenticated as.
+//
+// API docs: https://developer.atlassian.com/bitbucket/api/2/reference/resource/user
+func (c *Client) CurrentUser(ctx context.Context) (*User, error) {
+	req, err := http.NewRequest("GET", "/2.0/user", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var u User
+	if err = c.do(ctx, req, &u); err != nil {
+		return nil, err
+	}
+	return &u, nil
+}
+
+// WorkspaceMembers returns a page of the members of the given workspace.
+// If the argument pageToken.Next is not empty, it will be used directly as the
+// URL to make the request.
+//
+// API docs: https://developer.atlassian.com/bitbucket/api/2/reference/resource/workspaces/%7Bworkspace%7D/members
+func (c *Client) WorkspaceMembers(ctx context.Context, pageToken *PageToken, workspace string) ([]*User, *PageToken, error) {
+	var memberships []struct {
+		User *User `json:"user"`
+	}
+	var next *PageToken
+	var err error
+	if pageToken.HasMore() {
+		next, err = c.reqPage(ctx, pageToken.Next, &memberships)
+	} else {
+		next, err = c.page(ctx, fmt.Sprintf("/2.0/workspaces/%s/members", workspace), nil, pageToken, &memberships)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	users := make([]*User, 0, len(memberships))
+	for _, m := range memberships {
+		if m.User != nil {
+			users = append(users, m.User)
+		}
+	}
+	return users, next, nil
+}
+
+// WorkspaceRepoPermissions returns a page of the repository permissions of all
+// repositories in the given workspace. The optional query q narrows down the
+// results, e.g. `user.uuid="{...}"`. The authenticated user must be an
+// administrator of the workspace.
+//
+// API docs: https://developer.atlassian.com/bitbucket/api/2/reference/resource/workspaces/%7Bworkspace%7D/permissions/repositories
+func (c *Client) WorkspaceRepoPermissions(ctx context.Context, pageToken *PageToken, workspace, q string) ([]*RepoPermission, *PageToken, error) {
+	return c.repoPermissions(ctx, pageToken, fmt.Sprintf("/2.0/workspaces/%s/permissions/repositories", workspace), q)
+}
+
+// RepoPermissions returns a page of the user permissions of the repository
+// with the given full name, e.g. "myteam/myrepo". The authenticated user must
+// be an administrator of the repository's workspace.
+//
+// API docs: https://developer.atlassian.com/bitbucket/api/2/reference/resource/workspaces/%7Bworkspace%7D/permissions/repositories/%7Brepo_slug%7D
+func (c *Client) RepoPermissions(ctx context.Context, pageToken *PageToken, fullName string) ([]*RepoPermission, *PageToken, error) {
+	workspace, slug, err := splitFullName(fullName)
+	if err != nil {
+		return nil, nil, err
+	}
+	return c.repoPermissions(ctx, pageToken, fmt.Sprintf("/2.0/workspaces/%s/permissions/repositories/%s", workspace, slug), "")
+}
+
+func (c *Client) repoPermissions(ctx context.Context, pageToken *PageToken, path, q string) ([]*RepoPermission, *PageToken, error) {
+	var perms []*RepoPermission
+	var next *PageToken
+	var err error
+	if pageToken.HasMore() {
+		next, err = c.reqPage(ctx, pageToken.Next, &perms)
+	} else {
+		var qry url.Values
+		if q != "" {
+			qry = url.Values{"q": {q}}
+		}
+		next, err = c.page(ctx, path, qry, pageToken, &perms)
+	}
+	return perms, next, err
+}
+
+func splitFullName(fullName string) (workspace, slug string, err error) {
+	parts := strings.SplitN(fullName, "/", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", fmt.Errorf("invalid repository full name %q", fullName)
+	}
+	return parts[0], parts[1], nil
+}
@@ -562,6 +562,37 @@ func (c *Client) DeclinePullRequest(ctx context.Context, pr *PullRequest) error
 	return c.send(ctx, "POST", path, qry, nil, pr)
 }
 
+// MergePullRequest merges the given PullRequest with the merge strategy with
+// the given ID, e.g. "no-ff" or "squash", and updates it. If strategyID is
+// empty, the default strategy of the repository is used.
+func (c *Client) MergePullRequest(ctx context.Context, pr *PullRequest, strategyID string) error {
+	if pr.ToRef.Repository.Slug == "" {
+		return errors.New("repository slug empty")
+	}
+
+	if pr.ToRef.Repository.Project.Key == "" {
+		return errors.New("project key empty")
+	}
+
+	path := fmt.Sprintf(
+		"rest/api/1.0/projects/%s/repos/%s/pull-requests/%d/merge",
+		pr.ToRef.Repository.Project.Key,
+		pr.ToRef.Repository.Slug,
+		pr.ID,
+	)
+
+	qry := url.Values{"version": {strconv.Itoa(pr.Version)}}
+
+	var payload interface{}
+	if strategyID != "" {
+		payload = struct {
+			StrategyID string `json:"strategyId"`
+		}{StrategyID: strategyID}
+	}
+
+	return c.send(ctx, "POST", path, qry, payload, pr)
+}
+
 // LoadPullRequestActivities loads the given PullRequest's timeline of activities,
 // returning an error in case of failure.
 func (c *Client) LoadPullRequestActivities(ctx context.Context, pr *PullRequest) (err error) {
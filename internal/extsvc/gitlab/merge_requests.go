@@ -0,0 +1,354 @@
+package gitlab
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/peterhellberg/link"
+	"github.com/pkg/errors"
+)
+
+// MergeRequestState is the state of a GitLab merge request.
+type MergeRequestState string
+
+const (
+	MergeRequestStateOpened MergeRequestState = "opened"
+	MergeRequestStateClosed MergeRequestState = "closed"
+	MergeRequestStateLocked MergeRequestState = "locked"
+	MergeRequestStateMerged MergeRequestState = "merged"
+)
+
+// MergeRequest is a GitLab merge request (equivalent to a GitHub pull request).
+type MergeRequest struct {
+	ID              int               `json:"id"`
+	IID             int               `json:"iid"` // ID of the merge request within its project
+	ProjectID       int               `json:"project_id"`
+	SourceProjectID int               `json:"source_project_id"`
+	Title           string            `json:"title"`
+	Description     string            `json:"description"`
+	State           MergeRequestState `json:"state"`
+	CreatedAt       time.Time         `json:"created_at"`
+	UpdatedAt       time.Time         `json:"updated_at"`
+	MergedAt        *time.Time        `json:"merged_at"`
+	ClosedAt        *time.Time        `json:"closed_at"`
+	SourceBranch    string            `json:"source_branch"`
+	TargetBranch    string            `json:"target_branch"`
+	WebURL          string            `json:"web_url"`
+	WorkInProgress  bool              `json:"work_in_progress"`
+	Author          User              `json:"author"`
+	Labels          []string          `json:"labels"`
+	DiffRefs        DiffRefs          `json:"diff_refs"`
+	HeadPipeline    *Pipeline         `json:"head_pipeline"`
+
+	// Notes and Pipelines are not returned by the merge request endpoints
+	// of the GitLab API. They are populated separately by the caller via
+	// GetMergeRequestNotes and GetMergeRequestPipelines.
+	Notes     []*Note     `json:"notes"`
+	Pipelines []*Pipeline `json:"pipelines"`
+}
+
+// DiffRefs are the commits that make up the diff of a merge request.
+type DiffRefs struct {
+	BaseSHA  string `json:"base_sha"`
+	HeadSHA  string `json:"head_sha"`
+	StartSHA string `json:"start_sha"`
+}
+
+// Note is a comment or system note on a GitLab merge request.
+type Note struct {
+	ID        int       `json:"id"`
+	Body      string    `json:"body"`
+	Author    User      `json:"author"`
+	CreatedAt time.Time `json:"created_at"`
+	System    bool      `json:"system"`
+}
+
+// ToEvent returns the merge request event the note records, or nil if it
+// doesn't record one. GitLab records approvals as system notes.
+func (n *Note) ToEvent() interface{} {
+	if !n.System {
+		return nil
+	}
+	switch strings.TrimSpace(n.Body) {
+	case "approved this merge request":
+		return &ReviewApprovedEvent{Author: n.Author, CreatedAt: n.CreatedAt}
+	case "unapproved this merge request":
+		return &ReviewUnapprovedEvent{Author: n.Author, CreatedAt: n.CreatedAt}
+	}
+	return nil
+}
+
+// PipelineStatus is the status of a GitLab CI pipeline.
+type PipelineStatus string
+
+const (
+	PipelineStatusCreated            PipelineStatus = "created"
+	PipelineStatusWaitingForResource PipelineStatus = "waiting_for_resource"
+	PipelineStatusPreparing          PipelineStatus = "preparing"
+	PipelineStatusPending            PipelineStatus = "pending"
+	PipelineStatusRunning            PipelineStatus = "running"
+	PipelineStatusSuccess            PipelineStatus = "success"
+	PipelineStatusFailed             PipelineStatus = "failed"
+	PipelineStatusCanceled           PipelineStatus = "canceled"
+	PipelineStatusSkipped            PipelineStatus = "skipped"
+	PipelineStatusManual             PipelineStatus = "manual"
+	PipelineStatusScheduled          PipelineStatus = "scheduled"
+)
+
+// Pipeline is a GitLab CI pipeline run for a merge request.
+type Pipeline struct {
+	ID        int            `json:"id"`
+	SHA       string         `json:"sha"`
+	Ref       string         `json:"ref"`
+	Status    PipelineStatus `json:"status"`
+	WebURL    string         `json:"web_url"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+}
+
+// Key is a unique key identifying this pipeline in the context of its merge
+// request.
+func (p *Pipeline) Key() string {
+	return strconv.Itoa(p.ID)
+}
+
+// ReviewApprovedEvent is a user approving a merge request.
+type ReviewApprovedEvent struct {
+	Author    User      `json:"author"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Key is a unique key identifying this event in the context of its merge
+// request.
+func (e *ReviewApprovedEvent) Key() string {
+	return fmt.Sprintf("approved:%d:%d", e.Author.ID, e.CreatedAt.Unix())
+}
+
+// ReviewUnapprovedEvent is a user revoking their approval of a merge request.
+type ReviewUnapprovedEvent struct {
+	Author    User      `json:"author"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Key is a unique key identifying this event in the context of its merge
+// request.
+func (e *ReviewUnapprovedEvent) Key() string {
+	return fmt.Sprintf("unapproved:%d:%d", e.Author.ID, e.CreatedAt.Unix())
+}
+
+// MergeRequestEventCommon contains the fields shared by the merge request
+// state change events received via webhooks.
+type MergeRequestEventCommon struct {
+	User      User      `json:"user"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// MergeRequestClosedEvent is a merge request being closed without merging.
+type MergeRequestClosedEvent struct{ MergeRequestEventCommon }
+
+// Key is a unique key identifying this event in the context of its merge
+// request.
+func (e *MergeRequestClosedEvent) Key() string {
+	return fmt.Sprintf("closed:%d", e.CreatedAt.Unix())
+}
+
+// MergeRequestReopenedEvent is a closed merge request being reopened.
+type MergeRequestReopenedEvent struct{ MergeRequestEventCommon }
+
+// Key is a unique key identifying this event in the context of its merge
+// request.
+func (e *MergeRequestReopenedEvent) Key() string {
+	return fmt.Sprintf("reopened:%d", e.CreatedAt.Unix())
+}
+
+// MergeRequestMergedEvent is a merge request being merged.
+type MergeRequestMergedEvent struct{ MergeRequestEventCommon }
+
+// Key is a unique key identifying this event in the context of its merge
+// request.
+func (e *MergeRequestMergedEvent) Key() string {
+	return fmt.Sprintf("merged:%d", e.CreatedAt.Unix())
+}
+
+// ErrMergeRequestAlreadyExists is returned by CreateMergeRequest when an open
+// merge request for the same source and target branch already exists.
+var ErrMergeRequestAlreadyExists = errors.New("merge request already exists")
+
+// CreateMergeRequestOpts are the options to CreateMergeRequest.
+type CreateMergeRequestOpts struct {
+	SourceBranch string `json:"source_branch"`
+	TargetBranch string `json:"target_branch"`
+	Title        string `json:"title"`
+	Description  string `json:"description,omitempty"`
+}
+
+// CreateMergeRequest creates a merge request in the given project.
+func (c *Client) CreateMergeRequest(ctx context.Context, project *Project, opts CreateMergeRequestOpts) (*MergeRequest, error) {
+	if MockCreateMergeRequest != nil {
+		return MockCreateMergeRequest(c, ctx, project, opts)
+	}
+
+	req, err := newJSONRequest("POST", fmt.Sprintf("projects/%d/merge_requests", project.ID), opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var mr MergeRequest
+	if _, err := c.do(ctx, req, &mr); err != nil {
+		if HTTPErrorCode(err) == http.StatusConflict {
+			return nil, ErrMergeRequestAlreadyExists
+		}
+		return nil, errors.Wrap(err, "creating merge request")
+	}
+	return &mr, nil
+}
+
+// GetMergeRequest gets the merge request with the given IID in the given project.
+func (c *Client) GetMergeRequest(ctx context.Context, project *Project, iid int) (*MergeRequest, error) {
+	if MockGetMergeRequest != nil {
+		return MockGetMergeRequest(c, ctx, project, iid)
+	}
+
+	req, err := http.NewRequest("GET", fmt.Sprintf("projects/%d/merge_requests/%d", project.ID, iid), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var mr MergeRequest
+	if _, err := c.do(ctx, req, &mr); err != nil {
+		return nil, errors.Wrap(err, "getting merge request")
+	}
+	return &mr, nil
+}
+
+// GetOpenMergeRequestByRefs gets the open merge request in the given project
+// from the source branch to the target branch. It returns ErrNotFound if
+// there is none.
+func (c *Client) GetOpenMergeRequestByRefs(ctx context.Context, project *Project, source, target string) (*MergeRequest, error) {
+	if MockGetOpenMergeRequestByRefs != nil {
+		return MockGetOpenMergeRequestByRefs(c, ctx, project, source, target)
+	}
+
+	values := url.Values{
+		"state":         []string{string(MergeRequestStateOpened)},
+		"source_branch": []string{source},
+		"target_branch": []string{target},
+	}
+	req, err := http.NewRequest("GET", fmt.Sprintf("projects/%d/merge_requests?%s", project.ID, values.Encode()), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var mrs []*MergeRequest
+	if _, err := c.do(ctx, req, &mrs); err != nil {
+		return nil, errors.Wrap(err, "listing merge requests")
+	}
+	if len(mrs) == 0 {
+		return nil, ErrNotFound
+	}
+
+	// The list endpoint omits some fields, such as the diff refs and the
+	// head pipeline, so we get the full merge request.
+	return c.GetMergeRequest(ctx, project, mrs[0].IID)
+}
+
+// UpdateMergeRequestOpts are the options to UpdateMergeRequest. Empty fields
+// are left unchanged.
+type UpdateMergeRequestOpts struct {
+	TargetBranch string `json:"target_branch,omitempty"`
+	Title        string `json:"title,omitempty"`
+	Description  string `json:"description,omitempty"`
+	// StateEvent is either "close" or "reopen".
+	StateEvent string `json:"state_event,omitempty"`
+}
+
+// UpdateMergeRequest updates the given merge request.
+func (c *Client) UpdateMergeRequest(ctx context.Context, project *Project, mr *MergeRequest, opts UpdateMergeRequestOpts) (*MergeRequest, error) {
+	if MockUpdateMergeRequest != nil {
+		return MockUpdateMergeRequest(c, ctx, project, mr, opts)
+	}
+
+	req, err := newJSONRequest("PUT", fmt.Sprintf("projects/%d/merge_requests/%d", project.ID, mr.IID), opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var updated MergeRequest
+	if _, err := c.do(ctx, req, &updated); err != nil {
+		return nil, errors.Wrap(err, "updating merge request")
+	}
+	return &updated, nil
+}
+
+// GetMergeRequestNotes gets all notes of the given merge request, oldest first.
+func (c *Client) GetMergeRequestNotes(ctx context.Context, project *Project, iid int) ([]*Note, error) {
+	if MockGetMergeRequestNotes != nil {
+		return MockGetMergeRequestNotes(c, ctx, project, iid)
+	}
+
+	var notes []*Note
+	urlStr := fmt.Sprintf("projects/%d/merge_requests/%d/notes?sort=asc&order_by=created_at&per_page=100", project.ID, iid)
+	err := c.getAllPages(ctx, urlStr, func() interface{} { return &[]*Note{} }, func(page interface{}) {
+		notes = append(notes, *page.(*[]*Note)...)
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "getting merge request notes")
+	}
+	return notes, nil
+}
+
+// GetMergeRequestPipelines gets all pipelines run for the given merge request.
+func (c *Client) GetMergeRequestPipelines(ctx context.Context, project *Project, iid int) ([]*Pipeline, error) {
+	if MockGetMergeRequestPipelines != nil {
+		return MockGetMergeRequestPipelines(c, ctx, project, iid)
+	}
+
+	var pipelines []*Pipeline
+	urlStr := fmt.Sprintf("projects/%d/merge_requests/%d/pipelines?per_page=100", project.ID, iid)
+	err := c.getAllPages(ctx, urlStr, func() interface{} { return &[]*Pipeline{} }, func(page interface{}) {
+		pipelines = append(pipelines, *page.(*[]*Pipeline)...)
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "getting merge request pipelines")
+	}
+	return pipelines, nil
+}
+
+// getAllPages requests urlStr and all following pages. newPage returns a
+// pointer to decode each page into, which is then passed to add.
+func (c *Client) getAllPages(ctx context.Context, urlStr string, newPage func() interface{}, add func(interface{})) error {
+	for urlStr != "" {
+		req, err := http.NewRequest("GET", urlStr, nil)
+		if err != nil {
+			return err
+		}
+		page := newPage()
+		respHeader, err := c.do(ctx, req, page)
+		if err != nil {
+			return err
+		}
+		add(page)
+
+		// Get URL to next page. See https://docs.gitlab.com/ee/api/README.html#pagination-link-header.
+		urlStr = ""
+		if l := link.Parse(respHeader.Get("Link"))["next"]; l != nil {
+			urlStr = l.URI
+		}
+	}
+	return nil
+}
+
+func newJSONRequest(method, urlStr string, body interface{}) (*http.Request, error) {
+	b, err := json.Marshal(body)
+	if err != nil {
+		return nil, errors.Wrap(err, "marshalling request body")
+	}
+	return http.NewRequest(method, urlStr, bytes.NewReader(b))
+}
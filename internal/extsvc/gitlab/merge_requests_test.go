@@ -0,0 +1,97 @@
+package gitlab
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// mockHTTPPages serves the given response bodies in order, linking each page
+// to the next one.
+type mockHTTPPages struct {
+	pages    []string
+	requests []*http.Request
+}
+
+func (s *mockHTTPPages) Do(req *http.Request) (*http.Response, error) {
+	i := len(s.requests)
+	s.requests = append(s.requests, req)
+
+	header := make(http.Header)
+	if i+1 < len(s.pages) {
+		header.Set("Link", `<https://example.com/next>; rel="next"`)
+	}
+	return &http.Response{
+		Request:    req,
+		StatusCode: http.StatusOK,
+		Header:     header,
+		Body:       ioutil.NopCloser(strings.NewReader(s.pages[i])),
+	}, nil
+}
+
+func TestClient_CreateMergeRequest(t *testing.T) {
+	project := &Project{ProjectCommon: ProjectCommon{ID: 1}}
+	opts := CreateMergeRequestOpts{SourceBranch: "feature", TargetBranch: "master", Title: "t"}
+
+	t.Run("created", func(t *testing.T) {
+		mock := mockHTTPResponseBody{responseBody: `{"id": 10, "iid": 2, "project_id": 1, "title": "t", "state": "opened", "source_branch": "feature", "target_branch": "master"}`}
+		c := newTestClient(t)
+		c.httpClient = &mock
+
+		mr, err := c.CreateMergeRequest(context.Background(), project, opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := &MergeRequest{ID: 10, IID: 2, ProjectID: 1, Title: "t", State: MergeRequestStateOpened, SourceBranch: "feature", TargetBranch: "master"}
+		if !reflect.DeepEqual(mr, want) {
+			t.Errorf("got merge request %+v, want %+v", mr, want)
+		}
+	})
+
+	t.Run("already exists", func(t *testing.T) {
+		c := newTestClient(t)
+		c.httpClient = mockHTTPEmptyResponse{statusCode: http.StatusConflict}
+
+		if _, err := c.CreateMergeRequest(context.Background(), project, opts); err != ErrMergeRequestAlreadyExists {
+			t.Errorf("got error %v, want %v", err, ErrMergeRequestAlreadyExists)
+		}
+	})
+}
+
+func TestClient_GetMergeRequestNotes(t *testing.T) {
+	mock := mockHTTPPages{pages: []string{
+		`[{"id": 1, "body": "looks good", "author": {"username": "a"}}]`,
+		`[{"id": 2, "body": "approved this merge request", "system": true, "author": {"username": "a"}, "created_at": "2020-05-01T12:00:00Z"}]`,
+	}}
+	c := newTestClient(t)
+	c.httpClient = &mock
+
+	notes, err := c.GetMergeRequestNotes(context.Background(), &Project{ProjectCommon: ProjectCommon{ID: 1}}, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(mock.requests) != 2 {
+		t.Fatalf("got %d requests, want 2", len(mock.requests))
+	}
+	if have, want := mock.requests[0].URL.Path, "/projects/1/merge_requests/2/notes"; have != want {
+		t.Errorf("got path %q, want %q", have, want)
+	}
+	if len(notes) != 2 {
+		t.Fatalf("got %d notes, want 2", len(notes))
+	}
+
+	if e := notes[0].ToEvent(); e != nil {
+		t.Errorf("got event %+v for user note, want none", e)
+	}
+	want := &ReviewApprovedEvent{
+		Author:    User{Username: "a"},
+		CreatedAt: time.Date(2020, 5, 1, 12, 0, 0, 0, time.UTC),
+	}
+	if e := notes[1].ToEvent(); !reflect.DeepEqual(e, want) {
+		t.Errorf("got event %+v, want %+v", e, want)
+	}
+}
@@ -0,0 +1,150 @@
+package gitlab
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// MergeRequestWebhookEvent is the payload of a GitLab merge request webhook.
+// See https://docs.gitlab.com/ee/user/project/integrations/webhooks.html#merge-request-events.
+type MergeRequestWebhookEvent struct {
+	ObjectKind       string                        `json:"object_kind"`
+	User             User                          `json:"user"`
+	Project          ProjectCommon                 `json:"project"`
+	ObjectAttributes MergeRequestWebhookAttributes `json:"object_attributes"`
+}
+
+// MergeRequestWebhookAttributes are the attributes of the merge request a
+// MergeRequestWebhookEvent is about.
+type MergeRequestWebhookAttributes struct {
+	ID           int               `json:"id"`
+	IID          int               `json:"iid"`
+	State        MergeRequestState `json:"state"`
+	SourceBranch string            `json:"source_branch"`
+	TargetBranch string            `json:"target_branch"`
+	UpdatedAt    webhookTime       `json:"updated_at"`
+	// Action is one of "open", "close", "reopen", "update", "approved",
+	// "unapproved" or "merge".
+	Action string `json:"action"`
+}
+
+// ToEvent returns the merge request event the webhook reports, or nil if it
+// doesn't report one we track.
+func (e *MergeRequestWebhookEvent) ToEvent() interface{} {
+	common := MergeRequestEventCommon{User: e.User, CreatedAt: time.Time(e.ObjectAttributes.UpdatedAt)}
+	switch e.ObjectAttributes.Action {
+	case "close":
+		return &MergeRequestClosedEvent{common}
+	case "reopen":
+		return &MergeRequestReopenedEvent{common}
+	case "merge":
+		return &MergeRequestMergedEvent{common}
+	case "approved":
+		return &ReviewApprovedEvent{Author: e.User, CreatedAt: common.CreatedAt}
+	case "unapproved":
+		return &ReviewUnapprovedEvent{Author: e.User, CreatedAt: common.CreatedAt}
+	}
+	return nil
+}
+
+// PipelineWebhookEvent is the payload of a GitLab pipeline webhook.
+// See https://docs.gitlab.com/ee/user/project/integrations/webhooks.html#pipeline-events.
+type PipelineWebhookEvent struct {
+	ObjectKind       string                    `json:"object_kind"`
+	User             User                      `json:"user"`
+	Project          ProjectCommon             `json:"project"`
+	ObjectAttributes PipelineWebhookAttributes `json:"object_attributes"`
+	// MergeRequest is nil if the pipeline wasn't run for a merge request.
+	MergeRequest *struct {
+		ID  int `json:"id"`
+		IID int `json:"iid"`
+	} `json:"merge_request"`
+}
+
+// PipelineWebhookAttributes are the attributes of the pipeline a
+// PipelineWebhookEvent is about.
+type PipelineWebhookAttributes struct {
+	ID         int            `json:"id"`
+	Ref        string         `json:"ref"`
+	SHA        string         `json:"sha"`
+	Status     PipelineStatus `json:"status"`
+	CreatedAt  webhookTime    `json:"created_at"`
+	FinishedAt *webhookTime   `json:"finished_at"`
+}
+
+// Pipeline returns the pipeline the webhook reports.
+func (e *PipelineWebhookEvent) Pipeline() *Pipeline {
+	p := &Pipeline{
+		ID:        e.ObjectAttributes.ID,
+		SHA:       e.ObjectAttributes.SHA,
+		Ref:       e.ObjectAttributes.Ref,
+		Status:    e.ObjectAttributes.Status,
+		CreatedAt: time.Time(e.ObjectAttributes.CreatedAt),
+		UpdatedAt: time.Time(e.ObjectAttributes.CreatedAt),
+	}
+	if e.ObjectAttributes.FinishedAt != nil {
+		p.UpdatedAt = time.Time(*e.ObjectAttributes.FinishedAt)
+	}
+	if e.Project.WebURL != "" {
+		p.WebURL = fmt.Sprintf("%s/pipelines/%d", strings.TrimSuffix(e.Project.WebURL, "/"), p.ID)
+	}
+	return p
+}
+
+// ParseWebhookEvent parses the payload of a GitLab webhook. It returns a
+// *MergeRequestWebhookEvent or a *PipelineWebhookEvent, or nil for events of
+// other kinds.
+func ParseWebhookEvent(payload []byte) (interface{}, error) {
+	var kind struct {
+		ObjectKind string `json:"object_kind"`
+	}
+	if err := json.Unmarshal(payload, &kind); err != nil {
+		return nil, errors.Wrap(err, "parsing webhook payload")
+	}
+
+	var e interface{}
+	switch kind.ObjectKind {
+	case "merge_request":
+		e = &MergeRequestWebhookEvent{}
+	case "pipeline":
+		e = &PipelineWebhookEvent{}
+	default:
+		return nil, nil
+	}
+	if err := json.Unmarshal(payload, e); err != nil {
+		return nil, errors.Wrapf(err, "parsing %s webhook payload", kind.ObjectKind)
+	}
+	return e, nil
+}
+
+// webhookTime is a timestamp in a webhook payload. Depending on the GitLab
+// version and the kind of event, these are either in RFC 3339 format or in
+// the format "2006-01-02 15:04:05 UTC".
+type webhookTime time.Time
+
+var webhookTimeLayouts = []string{
+	time.RFC3339,
+	"2006-01-02 15:04:05 MST",
+	"2006-01-02 15:04:05 -0700",
+}
+
+func (t *webhookTime) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	if s == "" {
+		return nil
+	}
+	for _, layout := range webhookTimeLayouts {
+		if parsed, err := time.Parse(layout, s); err == nil {
+			*t = webhookTime(parsed.UTC())
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid webhook timestamp %q", s)
+}
@@ -0,0 +1,77 @@
+package gitlab
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseWebhookEvent(t *testing.T) {
+	t.Run("merge request", func(t *testing.T) {
+		e, err := ParseWebhookEvent([]byte(`{
+	"object_kind": "merge_request",
+	"user": {"name": "Alice", "username": "alice"},
+	"project": {"id": 1, "web_url": "https://gitlab.example.com/a/b"},
+	"object_attributes": {"iid": 2, "action": "approved", "updated_at": "2020-05-01 12:00:00 UTC"}
+}`))
+		if err != nil {
+			t.Fatal(err)
+		}
+		mr, ok := e.(*MergeRequestWebhookEvent)
+		if !ok {
+			t.Fatalf("got %T, want *MergeRequestWebhookEvent", e)
+		}
+		if mr.Project.ID != 1 || mr.ObjectAttributes.IID != 2 {
+			t.Errorf("unexpected event %+v", mr)
+		}
+
+		want := &ReviewApprovedEvent{
+			Author:    User{Name: "Alice", Username: "alice"},
+			CreatedAt: time.Date(2020, 5, 1, 12, 0, 0, 0, time.UTC),
+		}
+		if have := mr.ToEvent(); !reflect.DeepEqual(have, want) {
+			t.Errorf("got %+v, want %+v", have, want)
+		}
+	})
+
+	t.Run("pipeline", func(t *testing.T) {
+		e, err := ParseWebhookEvent([]byte(`{
+	"object_kind": "pipeline",
+	"project": {"id": 1, "web_url": "https://gitlab.example.com/a/b"},
+	"object_attributes": {"id": 3, "sha": "deadbeef", "status": "success", "created_at": "2020-05-01T12:00:00Z", "finished_at": "2020-05-01T12:05:00Z"},
+	"merge_request": {"id": 10, "iid": 2}
+}`))
+		if err != nil {
+			t.Fatal(err)
+		}
+		p, ok := e.(*PipelineWebhookEvent)
+		if !ok {
+			t.Fatalf("got %T, want *PipelineWebhookEvent", e)
+		}
+		if p.MergeRequest == nil || p.MergeRequest.IID != 2 {
+			t.Errorf("unexpected merge request %+v", p.MergeRequest)
+		}
+
+		want := &Pipeline{
+			ID:        3,
+			SHA:       "deadbeef",
+			Status:    PipelineStatusSuccess,
+			WebURL:    "https://gitlab.example.com/a/b/pipelines/3",
+			CreatedAt: time.Date(2020, 5, 1, 12, 0, 0, 0, time.UTC),
+			UpdatedAt: time.Date(2020, 5, 1, 12, 5, 0, 0, time.UTC),
+		}
+		if have := p.Pipeline(); !reflect.DeepEqual(have, want) {
+			t.Errorf("got %+v, want %+v", have, want)
+		}
+	})
+
+	t.Run("other", func(t *testing.T) {
+		e, err := ParseWebhookEvent([]byte(`{"object_kind": "push"}`))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if e != nil {
+			t.Errorf("got %+v, want nil", e)
+		}
+	})
+}
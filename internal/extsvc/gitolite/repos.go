@@ -2,8 +2,10 @@ package gitolite
 
 import (
 	"context"
+	"fmt"
 	"net/url"
 	"os/exec"
+	"regexp"
 	"strings"
 
 	"github.com/inconshreveable/log15"
@@ -71,3 +73,44 @@ func decodeRepos(host, gitoliteInfo string) []*Repo {
 
 	return repos
 }
+
+// usernamePattern matches valid Gitolite usernames (Gitolite's USERNAME_PATT).
+var usernamePattern = regexp.MustCompile(`^[0-9a-zA-Z][-0-9a-zA-Z._@+]*$`)
+
+// ReadableRepos returns the names of the given repositories that the given Gitolite user
+// can read. It uses Gitolite's `access` command, which must be enabled for remote use in
+// the COMMANDS section of the Gitolite rc file.
+func (c *Client) ReadableRepos(ctx context.Context, user string, repos []string) ([]string, error) {
+	if !usernamePattern.MatchString(user) {
+		return nil, fmt.Errorf("invalid Gitolite username %q", user)
+	}
+	if len(repos) == 0 {
+		return nil, nil
+	}
+
+	// With "%" as the repository, `access` reads the repositories to check from stdin.
+	cmd := exec.CommandContext(ctx, "ssh", c.Host, "access", "%", user, "R", "any")
+	cmd.Stdin = strings.NewReader(strings.Join(repos, "\n") + "\n")
+	out, err := cmd.Output()
+	if err != nil {
+		log15.Error("checking gitolite access failed", "error", err, "out", string(out))
+		return nil, err
+	}
+	return decodeAccess(string(out)), nil
+}
+
+// decodeAccess returns the repositories that are not denied in the output of
+// `gitolite access % <user> <perm> <ref>`, which prints one line of the form
+// "<repo>\t<user>\t<result>" per repository. The result is the matching ref of
+// the rule granting access or a message starting with "DENIED".
+func decodeAccess(gitoliteAccess string) []string {
+	var repos []string
+	for _, line := range strings.Split(gitoliteAccess, "\n") {
+		fields := strings.SplitN(strings.TrimSpace(line), "\t", 3)
+		if len(fields) < 3 || strings.Contains(fields[2], "DENIED") {
+			continue
+		}
+		repos = append(repos, fields[0])
+	}
+	return repos
+}
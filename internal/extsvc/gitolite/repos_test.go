@@ -60,3 +60,38 @@ func Test_decodeRepos(t *testing.T) {
 		})
 	}
 }
+
+func Test_decodeAccess(t *testing.T) {
+	tests := []struct {
+		name           string
+		gitoliteAccess string
+		expRepos       []string
+	}{
+		{
+			name: "mixed access",
+			gitoliteAccess: "gitolite-admin\talice\tR any gitolite-admin alice DENIED by fallthru\n" +
+				"testing\talice\trefs/.*\n" +
+				"repowith@sign\talice\trefs/heads/master\n",
+			expRepos: []string{"testing", "repowith@sign"},
+		},
+		{
+			name:           "ignores malformed lines",
+			gitoliteAccess: "FATAL: unknown git/gitolite command: 'access'\n",
+			expRepos:       nil,
+		},
+		{
+			name:           "handles empty response",
+			gitoliteAccess: "",
+			expRepos:       nil,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			repos := decodeAccess(test.gitoliteAccess)
+			if diff := cmp.Diff(test.expRepos, repos); diff != "" {
+				t.Error(diff)
+			}
+		})
+	}
+}
@@ -22,6 +22,7 @@ import (
 	"github.com/inconshreveable/log15"
 	"github.com/neelance/parallel"
 	"github.com/opentracing-contrib/go-stdlib/nethttp"
+	"github.com/opentracing/opentracing-go"
 	"github.com/opentracing/opentracing-go/ext"
 	otlog "github.com/opentracing/opentracing-go/log"
 	"github.com/pkg/errors"
@@ -60,6 +61,9 @@ func NewClient(cli httpcli.Doer) *Client {
 		Addrs: func(ctx context.Context) []string {
 			return conf.Get().ServiceConnections.GitServers
 		},
+		ReplicationFactor: func() int {
+			return conf.Get().GitReplicationFactor
+		},
 		HTTPClient:  cli,
 		HTTPLimiter: parallel.NewRun(500),
 		// Use the binary name for UserAgent. This should effectively identify
@@ -82,15 +86,24 @@ type Client struct {
 	// concurrent use. It may return different results at different times.
 	Addrs func(ctx context.Context) []string
 
+	// ReplicationFactor is a function which should return the number of
+	// gitservers each repository is cloned onto. If it is nil, repositories
+	// are not replicated.
+	ReplicationFactor func() int
+
 	// UserAgent is a string identifing who the client is. It will be logged in
 	// the telemetry in gitserver.
 	UserAgent string
+
+	healthMu       sync.Mutex           // protects unhealthyUntil
+	unhealthyUntil map[string]time.Time // gitservers that recently failed to respond
 }
 
 // AddrForRepo returns the gitserver address to use for the given repo name.
+// This is always the repo's primary gitserver. Only reads made by the client
+// itself (including commands) fail over to other replicas (see readOps).
 func (c *Client) AddrForRepo(ctx context.Context, repo api.RepoName) string {
-	repo = protocol.NormalizeRepo(repo) // in case the caller didn't already normalize it
-	return c.addrForKey(ctx, string(repo))
+	return c.ReplicaAddrsForRepo(ctx, repo)[0]
 }
 
 // addrForKey returns the gitserver address to use for the given string key,
@@ -249,6 +262,10 @@ func (c *Cmd) sendExec(ctx context.Context) (_ io.ReadCloser, _ http.Header, err
 		EnsureRevision: c.EnsureRevision,
 		Args:           c.Args[1:],
 	}
+	if c.Repo.CrossRepoBase.Commit != "" {
+		base := c.Repo.CrossRepoBase
+		req.CrossRepoBase = &base
+	}
 	resp, err := c.client.httpPost(ctx, repoName, "exec", req)
 	if err != nil {
 		return nil, nil, err
@@ -303,6 +320,12 @@ type Repo struct {
 	// this field is optional (it will use the last-used Git remote URL). If the repository is not
 	// cloned on the gitserver, the request will fail.
 	URL string
+
+	// CrossRepoBase, if its commit is set, is a commit of another repository
+	// that commands run in this repository can refer to by its ID, e.g. to
+	// compare a fork with its upstream repository. Refs can't be used by such
+	// commands.
+	CrossRepoBase protocol.CrossRepoBase
 }
 
 // Command creates a new Cmd. Command name must be 'git',
@@ -478,6 +501,32 @@ func (c *Client) ListGitolite(ctx context.Context, gitoliteHost string) (list []
 	return list, err
 }
 
+// GitoliteAccess lists the names of the Gitolite repositories that the given
+// Gitolite user can read.
+func (c *Client) GitoliteAccess(ctx context.Context, gitoliteHost, user string) (names []string, err error) {
+	// Like ListGitolite, only a single gitserver calls the shared Gitolite server.
+	addr := c.addrForKey(ctx, gitoliteHost)
+	q := url.Values{"gitolite": {gitoliteHost}, "user": {user}}
+	req, err := http.NewRequest("GET", "http://"+addr+"/gitolite-access?"+q.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.HTTPClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, errors.Errorf("gitolite access: %s: %s", resp.Status, bytes.TrimSpace(body))
+	}
+
+	err = json.NewDecoder(resp.Body).Decode(&names)
+	return names, err
+}
+
 // ListCloned lists all cloned repositories
 func (c *Client) ListCloned(ctx context.Context) ([]string, error) {
 	var (
@@ -561,13 +610,37 @@ func (c *Client) doListOne(ctx context.Context, urlSuffix, addr string) ([]strin
 // Repo updates are not guaranteed to occur. If a repo has been updated
 // recently (within the Since duration specified in the request), the
 // update won't happen.
+//
+// When repositories are replicated, the update is requested from every
+// replica and the response of the primary replica is returned. Failures of
+// the other replicas are logged; they catch up with the primary during
+// cleanup.
 func (c *Client) RequestRepoUpdate(ctx context.Context, repo Repo, since time.Duration) (*protocol.RepoUpdateResponse, error) {
 	req := &protocol.RepoUpdateRequest{
 		Repo:  repo.Name,
 		URL:   repo.URL,
 		Since: since,
 	}
-	resp, err := c.httpPost(ctx, repo.Name, "repo-update", req)
+
+	replicas := c.ReplicaAddrsForRepo(ctx, repo.Name)
+	var wg sync.WaitGroup
+	for _, addr := range replicas[1:] {
+		wg.Add(1)
+		go func(addr string) {
+			defer wg.Done()
+			if _, err := c.requestRepoUpdate(ctx, addr, req); err != nil {
+				log15.Warn("failed to update gitserver replica", "repo", repo.Name, "addr", addr, "error", err)
+			}
+		}(addr)
+	}
+	defer wg.Wait()
+
+	return c.requestRepoUpdate(ctx, replicas[0], req)
+}
+
+// requestRepoUpdate requests a repo update from the gitserver at addr.
+func (c *Client) requestRepoUpdate(ctx context.Context, addr string, req *protocol.RepoUpdateRequest) (*protocol.RepoUpdateResponse, error) {
+	resp, err := c.httpPost(ctx, req.Repo, "http://"+addr+"/repo-update", req)
 	if err != nil {
 		return nil, err
 	}
@@ -806,12 +879,25 @@ func (c *Client) RepoInfo(ctx context.Context, repos ...api.RepoName) (*protocol
 	return &res, err.ErrorOrNil()
 }
 
-// Remove removes the repository clone from gitserver.
+// Remove removes the repository clone from gitserver, including all of its
+// replicas.
 func (c *Client) Remove(ctx context.Context, repo api.RepoName) error {
 	req := &protocol.RepoDeleteRequest{
 		Repo: repo,
 	}
-	resp, err := c.httpPost(ctx, repo, "delete", req)
+
+	var err *multierror.Error
+	for _, addr := range c.ReplicaAddrsForRepo(ctx, repo) {
+		if e := c.remove(ctx, addr, req); e != nil {
+			err = multierror.Append(err, e)
+		}
+	}
+	return err.ErrorOrNil()
+}
+
+// remove removes the repository clone from the gitserver at addr.
+func (c *Client) remove(ctx context.Context, addr string, req *protocol.RepoDeleteRequest) error {
+	resp, err := c.httpPost(ctx, req.Repo, "http://"+addr+"/delete", req)
 	if err != nil {
 		return err
 	}
@@ -829,7 +915,11 @@ func (c *Client) httpPost(ctx context.Context, repo api.RepoName, op string, pay
 }
 
 // do performs a request to a gitserver, sharding based on the given
-// repo name (the repo name is otherwise not used).
+// repo name (the repo name is otherwise not used). op is either the name of
+// a gitserver endpoint or the full URL of a request to a specific gitserver.
+//
+// Requests that only read the repo fail over to the other replicas of the
+// repo if the gitserver is unavailable or has not cloned the repo.
 func (c *Client) do(ctx context.Context, repo api.RepoName, method, op string, payload interface{}) (resp *http.Response, err error) {
 	span, ctx := ot.StartSpanFromContext(ctx, "Client.do")
 	defer func() {
@@ -850,7 +940,50 @@ func (c *Client) do(ctx context.Context, repo api.RepoName, method, op string, p
 	if !strings.HasPrefix(op, "http") {
 		uri = "http://" + c.AddrForRepo(ctx, repo) + "/" + op
 	}
+	u, err := url.Parse(uri)
+	if err != nil {
+		return nil, err
+	}
 
+	// Requests that may change the repo, and requests to a gitserver other
+	// than the repo's primary, only go to the gitserver chosen above. Reads
+	// from the primary fail over to the other replicas, healthy ones first.
+	primary := u.Host
+	addrs := []string{primary}
+	replicaBody := reqBody
+	if readOps[strings.TrimPrefix(u.Path, "/")] && primary == c.AddrForRepo(ctx, repo) {
+		addrs = c.orderedReplicaAddrs(ctx, repo)
+		if replicaBody, err = json.Marshal(replicaPayload(payload)); err != nil {
+			return nil, err
+		}
+	}
+
+	for i, addr := range addrs {
+		u.Host = addr
+		body := reqBody
+		if addr != primary {
+			body = replicaBody
+		}
+		resp, err = c.doOnce(ctx, span, method, u.String(), body)
+		if i == len(addrs)-1 || !shouldFailOver(ctx, resp, err) {
+			break
+		}
+
+		if err != nil {
+			c.markUnhealthy(addr)
+		} else {
+			resp.Body.Close()
+		}
+		span.LogKV("event", "failing over to replica", "addr", addrs[i+1])
+	}
+	if err != nil && ctx.Err() == nil {
+		c.markUnhealthy(u.Host)
+	}
+	return resp, err
+}
+
+// doOnce performs a single request to a gitserver.
+func (c *Client) doOnce(ctx context.Context, span opentracing.Span, method, uri string, reqBody []byte) (*http.Response, error) {
 	req, err := http.NewRequest(method, uri, bytes.NewReader(reqBody))
 	if err != nil {
 		return nil, err
@@ -901,3 +1034,24 @@ func (c *Client) CreateCommitFromPatch(ctx context.Context, req protocol.CreateC
 	}
 	return res.Rev, nil
 }
+
+// CommitSearch searches the commit index of req.Repo. If the response is not
+// Indexed, callers should fall back to searching with `git log`.
+func (c *Client) CommitSearch(ctx context.Context, req *protocol.CommitSearchRequest) (*protocol.CommitSearchResponse, error) {
+	resp, err := c.httpPost(ctx, req.Repo, "commit-search", req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := ioutil.ReadAll(resp.Body)
+		return nil, &url.Error{URL: resp.Request.URL.String(), Op: "CommitSearch", Err: fmt.Errorf("CommitSearch: http status %d: %s", resp.StatusCode, bytes.TrimSpace(body))}
+	}
+
+	var res protocol.CommitSearchResponse
+	if err := json.NewDecoder(resp.Body).Decode(&res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
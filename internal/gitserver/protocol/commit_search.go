@@ -0,0 +1,91 @@
+package protocol
+
+import (
+	"time"
+
+	"github.com/sourcegraph/sourcegraph/internal/api"
+)
+
+// CommitSearchRequest is a request to search the commit index of a
+// repository. The index only contains the non-merge commits reachable from
+// HEAD. The fields have the same semantics as the corresponding `git log`
+// flags used for unindexed commit search.
+type CommitSearchRequest struct {
+	Repo api.RepoName
+
+	// DiffPattern is matched against the added and removed lines of a
+	// commit's diff (like `git log -G`). It is ignored if empty.
+	DiffPattern string
+	// DiffPatternIsRegExp is whether DiffPattern is a regexp (if false,
+	// treated as an exact string).
+	DiffPatternIsRegExp bool
+	// DiffPatternIsCaseSensitive is whether DiffPattern and the path
+	// patterns are matched case sensitively.
+	DiffPatternIsCaseSensitive bool
+
+	// IncludePatterns and ExcludePattern restrict the files a commit must
+	// change (in a way that matches DiffPattern, if set).
+	IncludePatterns        []string
+	ExcludePattern         string
+	PathPatternsAreRegExps bool
+
+	// MessagePatterns, AuthorPatterns and CommitterPatterns are regexps
+	// that must all match (like `git log --all-match --grep/--author/--committer`).
+	MessagePatterns   []string
+	AuthorPatterns    []string
+	CommitterPatterns []string
+	// InvertPatterns makes the above patterns exclude instead of include
+	// matching commits (like `git log --invert-grep`).
+	InvertPatterns bool
+	// PatternsAreCaseSensitive is whether the message, author and
+	// committer patterns are matched case sensitively.
+	PatternsAreCaseSensitive bool
+
+	// Before and After are `git log --until` and `--since` values, such as
+	// "2 weeks ago" or "2020-01-02".
+	Before []string
+	After  []string
+
+	// Limit is the maximum number of commits to return.
+	Limit int
+}
+
+// CommitSearchResponse is the response to a CommitSearchRequest.
+type CommitSearchResponse struct {
+	// Indexed is false if the repository's index does not exist or is not up
+	// to date with HEAD. The client should fall back to `git log` in that
+	// case.
+	Indexed bool
+
+	// Results are the matching commits, newest first.
+	Results []*CommitSearchResult
+
+	// Complete is false if the search stopped early, because its deadline
+	// was hit.
+	Complete bool
+}
+
+// CommitSearchResult is a single commit matched by a CommitSearchRequest.
+type CommitSearchResult struct {
+	ID        api.CommitID
+	Author    CommitSignature
+	Committer CommitSignature
+	Message   string
+	Parents   []api.CommitID
+
+	// Refs is the list of refs which point to this commit.
+	Refs []string
+	// SourceRefs is the list of refs by which this commit was reached.
+	SourceRefs []string
+
+	// RawDiff is the commit's full diff, without a/ and b/ prefixes and
+	// without context lines (`git show --no-prefix --unified=0`).
+	RawDiff string
+}
+
+// CommitSignature is the author or committer of a commit.
+type CommitSignature struct {
+	Name  string
+	Email string
+	Date  time.Time
+}
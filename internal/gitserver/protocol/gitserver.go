@@ -22,6 +22,12 @@ type ExecRequest struct {
 	EnsureRevision string      `json:"ensureRevision"`
 	Args           []string    `json:"args"`
 	Opt            *RemoteOpts `json:"opt"`
+
+	// CrossRepoBase, if set, is a commit of another repository that the
+	// command can refer to. The command is then run in a temporary repository
+	// that contains the objects of both repositories but no refs, and
+	// EnsureRevision is ignored.
+	CrossRepoBase *CrossRepoBase `json:"crossRepoBase,omitempty"`
 }
 
 // RemoteOpts configures interactions with a remote repository.
@@ -222,3 +228,12 @@ type CreateCommitFromPatchError struct {
 func (e *CreateCommitFromPatchError) Error() string {
 	return e.InternalError
 }
+
+// CrossRepoBase is a commit of another repository that a command needs, e.g.
+// to compare a fork with its upstream repository.
+type CrossRepoBase struct {
+	// Repo is the repository that contains Commit.
+	Repo api.RepoName `json:"repo"`
+	// Commit is the absolute ID of the commit.
+	Commit api.CommitID `json:"commit"`
+}
@@ -0,0 +1,175 @@
+package gitserver
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/endpoint"
+	"github.com/sourcegraph/sourcegraph/internal/gitserver/protocol"
+)
+
+// unhealthyReplicaBackoff is how long a gitserver that failed to respond is
+// skipped when choosing a replica to read from.
+const unhealthyReplicaBackoff = 10 * time.Second
+
+// readOps are the gitserver endpoints that only read a repository. Requests
+// to these endpoints fail over to another replica of the repository.
+//
+// Commands may also fetch missing revisions (see
+// protocol.ExecRequest.EnsureRevision), which must only happen on the
+// primary, so "exec" requests are sent to the other replicas without
+// EnsureRevision (see replicaPayload).
+var readOps = map[string]bool{
+	"archive":             true,
+	"exec":                true,
+	"is-repo-cloned":      true,
+	"repos":               true,
+	"repo-clone-progress": true,
+}
+
+// replicaPayload returns the payload of a read request to send to replicas
+// other than the primary.
+func replicaPayload(payload interface{}) interface{} {
+	if req, ok := payload.(*protocol.ExecRequest); ok && req.EnsureRevision != "" {
+		req2 := *req
+		req2.EnsureRevision = ""
+		return &req2
+	}
+	return payload
+}
+
+// ReplicaAddrsForRepo returns the addresses of the gitservers that hold a
+// replica of the given repo. The first address is the repo's primary
+// gitserver, which is the address AddrForRepo returns. The remaining replicas are chosen from a consistent hash of the
+// gitserver addresses, so that adding or removing a gitserver moves few
+// replicas.
+func (c *Client) ReplicaAddrsForRepo(ctx context.Context, repo api.RepoName) []string {
+	repo = protocol.NormalizeRepo(repo) // in case the caller didn't already normalize it
+	addrs := c.Addrs(ctx)
+	if len(addrs) == 0 {
+		panic("unexpected state: no gitserver addresses")
+	}
+	return replicaAddrs(addrs, string(repo), c.replicationFactor())
+}
+
+// replicationFactor returns the number of replicas of each repo.
+func (c *Client) replicationFactor() int {
+	if c.ReplicationFactor == nil {
+		return 1
+	}
+	if n := c.ReplicationFactor(); n > 1 {
+		return n
+	}
+	return 1
+}
+
+// replicaAddrs returns the n addresses in addrs that hold a replica of key,
+// primary first.
+func replicaAddrs(addrs []string, key string, n int) []string {
+	primary := addrForKey(addrs, key)
+	if n > len(addrs) {
+		n = len(addrs)
+	}
+	if n <= 1 {
+		return []string{primary}
+	}
+
+	m := consistentHash(addrs)
+	replicas := []string{primary}
+	exclude := map[string]bool{primary: true}
+	for len(replicas) < n {
+		// Static maps never return an error.
+		addr, _ := m.Get(key, exclude)
+		if addr == "" {
+			break
+		}
+		replicas = append(replicas, addr)
+		exclude[addr] = true
+	}
+	return replicas
+}
+
+// consistentHashCache holds the consistent hash of the most recently seen
+// gitserver addresses, which rarely change.
+var consistentHashCache struct {
+	sync.Mutex
+	key string
+	m   *endpoint.Map
+}
+
+// consistentHash returns a consistent hash of addrs.
+func consistentHash(addrs []string) *endpoint.Map {
+	key := strings.Join(addrs, " ")
+
+	consistentHashCache.Lock()
+	defer consistentHashCache.Unlock()
+	if consistentHashCache.m == nil || consistentHashCache.key != key {
+		consistentHashCache.key = key
+		consistentHashCache.m = endpoint.Static(addrs...)
+	}
+	return consistentHashCache.m
+}
+
+// orderedReplicaAddrs returns the replicas of repo in the order they should be
+// read from: healthy replicas first, primary first among them.
+func (c *Client) orderedReplicaAddrs(ctx context.Context, repo api.RepoName) []string {
+	replicas := c.ReplicaAddrsForRepo(ctx, repo)
+	if len(replicas) == 1 {
+		return replicas
+	}
+
+	ordered := make([]string, 0, len(replicas))
+	var unhealthy []string
+	for _, addr := range replicas {
+		if c.isHealthy(addr) {
+			ordered = append(ordered, addr)
+		} else {
+			unhealthy = append(unhealthy, addr)
+		}
+	}
+	return append(ordered, unhealthy...)
+}
+
+// markUnhealthy records that addr failed to respond. It is read from last
+// until unhealthyReplicaBackoff has passed.
+func (c *Client) markUnhealthy(addr string) {
+	c.healthMu.Lock()
+	defer c.healthMu.Unlock()
+	if c.unhealthyUntil == nil {
+		c.unhealthyUntil = map[string]time.Time{}
+	}
+	c.unhealthyUntil[addr] = time.Now().Add(unhealthyReplicaBackoff)
+}
+
+// isHealthy reports whether addr has not failed to respond recently.
+func (c *Client) isHealthy(addr string) bool {
+	c.healthMu.Lock()
+	defer c.healthMu.Unlock()
+	until, ok := c.unhealthyUntil[addr]
+	if !ok {
+		return true
+	}
+	if time.Now().After(until) {
+		delete(c.unhealthyUntil, addr)
+		return true
+	}
+	return false
+}
+
+// shouldFailOver reports whether a read that received resp and err from one
+// replica should be retried on the next replica. Reads fail over when the
+// replica is unreachable, and when it has not cloned the repository (e.g.
+// because it is a new replica or is rebuilding its disk).
+func shouldFailOver(ctx context.Context, resp *http.Response, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	if err != nil {
+		return true
+	}
+	return resp.StatusCode == http.StatusNotFound
+}
@@ -0,0 +1,190 @@
+package gitserver_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/gitserver"
+	"github.com/sourcegraph/sourcegraph/internal/gitserver/protocol"
+	"github.com/sourcegraph/sourcegraph/internal/httpcli"
+)
+
+func TestClient_ReplicaAddrsForRepo(t *testing.T) {
+	addrs := []string{"gitserver-0", "gitserver-1", "gitserver-2", "gitserver-3"}
+	newClient := func(replicationFactor int) *gitserver.Client {
+		return &gitserver.Client{
+			Addrs:             func(ctx context.Context) []string { return addrs },
+			ReplicationFactor: func() int { return replicationFactor },
+		}
+	}
+
+	for i := 0; i < 20; i++ {
+		repo := api.RepoName(fmt.Sprintf("github.com/foo/bar%d", i))
+		primary := newClient(1).ReplicaAddrsForRepo(context.Background(), repo)
+		if len(primary) != 1 {
+			t.Fatalf("got %d replicas without replication, want 1", len(primary))
+		}
+
+		replicas := newClient(3).ReplicaAddrsForRepo(context.Background(), repo)
+		if len(replicas) != 3 {
+			t.Fatalf("got replicas %v, want 3", replicas)
+		}
+		if replicas[0] != primary[0] {
+			t.Errorf("got primary %s with replication, want %s", replicas[0], primary[0])
+		}
+		seen := map[string]bool{}
+		for _, addr := range replicas {
+			if seen[addr] {
+				t.Errorf("got duplicate replica %s in %v", addr, replicas)
+			}
+			seen[addr] = true
+		}
+
+		if got := newClient(10).ReplicaAddrsForRepo(context.Background(), repo); len(got) != len(addrs) {
+			t.Errorf("got %d replicas, want replication factor capped at %d", len(got), len(addrs))
+		}
+	}
+}
+
+func TestClient_ReadFailover(t *testing.T) {
+	repo := api.RepoName("github.com/foo/bar")
+	addrs := []string{"gitserver-0", "gitserver-1", "gitserver-2"}
+
+	var (
+		mu           sync.Mutex
+		requests     []string
+		execRequests []protocol.ExecRequest
+		down         = map[string]bool{}
+	)
+	cli := &gitserver.Client{
+		Addrs:             func(ctx context.Context) []string { return addrs },
+		ReplicationFactor: func() int { return 2 },
+		HTTPClient: httpcli.DoerFunc(func(r *http.Request) (*http.Response, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			requests = append(requests, r.URL.Host+r.URL.Path)
+			if down[r.URL.Host] {
+				return nil, errors.New("connection refused")
+			}
+			if r.URL.Path == "/exec" {
+				var req protocol.ExecRequest
+				if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+					return nil, err
+				}
+				execRequests = append(execRequests, req)
+			}
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       ioutil.NopCloser(bytes.NewBufferString(`{}`)),
+				Trailer:    http.Header{"X-Exec-Exit-Status": {"0"}},
+			}, nil
+		}),
+	}
+	replicas := cli.ReplicaAddrsForRepo(context.Background(), repo)
+	down[replicas[0]] = true
+
+	cloned, err := cli.IsRepoCloned(context.Background(), repo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !cloned {
+		t.Error("want repo to be cloned on replica")
+	}
+	want := []string{replicas[0] + "/is-repo-cloned", replicas[1] + "/is-repo-cloned"}
+	if diff := cmp.Diff(want, requests); diff != "" {
+		t.Errorf("unexpected requests (-want +got):\n%s", diff)
+	}
+
+	// AddrForRepo always returns the primary, even while it is unhealthy.
+	if addr := cli.AddrForRepo(context.Background(), repo); addr != replicas[0] {
+		t.Errorf("got addr %s for unhealthy primary, want primary %s", addr, replicas[0])
+	}
+
+	// Reads skip the primary while it is unhealthy.
+	requests = nil
+	if _, err := cli.IsRepoCloned(context.Background(), repo); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{replicas[1] + "/is-repo-cloned"}; !cmp.Equal(want, requests) {
+		t.Errorf("got requests %v, want %v", requests, want)
+	}
+
+	// Commands fail over too, but only the primary may fetch missing revisions.
+	requests = nil
+	cmd := cli.Command("git", "rev-parse", "HEAD")
+	cmd.Repo = gitserver.Repo{Name: repo}
+	cmd.EnsureRevision = "HEAD"
+	if _, err := cmd.Output(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{replicas[1] + "/exec"}; !cmp.Equal(want, requests) {
+		t.Errorf("got requests %v, want %v", requests, want)
+	}
+	if len(execRequests) != 1 || execRequests[0].EnsureRevision != "" {
+		t.Errorf("got exec requests %+v, want one without EnsureRevision", execRequests)
+	}
+
+	// Updates are sent to all replicas, and are not failed over.
+	requests = nil
+	if _, err := cli.RequestRepoUpdate(context.Background(), gitserver.Repo{Name: repo}, 0); err == nil {
+		t.Error("want error updating unavailable primary")
+	}
+	if len(requests) != 2 {
+		t.Errorf("got requests %v, want one per replica", requests)
+	}
+}
+
+func TestClient_ReadFailover_notCloned(t *testing.T) {
+	repo := api.RepoName("github.com/foo/bar")
+	addrs := []string{"gitserver-0", "gitserver-1", "gitserver-2"}
+
+	var requests []string
+	var replicas []string
+	cli := &gitserver.Client{
+		Addrs:             func(ctx context.Context) []string { return addrs },
+		ReplicationFactor: func() int { return 2 },
+		HTTPClient: httpcli.DoerFunc(func(r *http.Request) (*http.Response, error) {
+			requests = append(requests, r.URL.Host+r.URL.Path)
+			// The primary has not cloned the repo.
+			if r.URL.Host == replicas[0] {
+				return &http.Response{
+					StatusCode: http.StatusNotFound,
+					Body:       ioutil.NopCloser(bytes.NewBufferString(`{}`)),
+				}, nil
+			}
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       ioutil.NopCloser(bytes.NewBufferString("archive")),
+			}, nil
+		}),
+	}
+	replicas = cli.ReplicaAddrsForRepo(context.Background(), repo)
+
+	rc, err := cli.Archive(context.Background(), gitserver.Repo{Name: repo}, gitserver.ArchiveOptions{Treeish: "HEAD", Format: "tar"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	rc.Close()
+	want := []string{replicas[0] + "/archive", replicas[1] + "/archive"}
+	if diff := cmp.Diff(want, requests); diff != "" {
+		t.Errorf("unexpected requests (-want +got):\n%s", diff)
+	}
+
+	// A missing clone does not mark the primary unhealthy.
+	requests = nil
+	if _, err := cli.Archive(context.Background(), gitserver.Repo{Name: repo}, gitserver.ArchiveOptions{Treeish: "HEAD", Format: "tar"}); err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(want, requests); diff != "" {
+		t.Errorf("unexpected requests (-want +got):\n%s", diff)
+	}
+}
@@ -3,7 +3,9 @@ package httpcli
 import (
 	"crypto/tls"
 	"crypto/x509"
+	"net"
 	"net/http"
+	"syscall"
 	"time"
 
 	"github.com/gregjones/httpcache"
@@ -260,6 +262,84 @@ func NewIdleConnTimeoutOpt(timeout time.Duration) Opt {
 	}
 }
 
+// PublicAddressesOnlyOpt is an Opt that makes an http.Client refuse to connect
+// to loopback, private, link-local and other non-public IP addresses. It is
+// meant for requests to user-provided URLs, such as webhooks, which must not
+// reach internal services.
+//
+// The addresses are checked after DNS resolution, so hostnames that resolve to
+// non-public addresses are refused as well. Proxies are disabled, since they
+// would connect on the client's behalf.
+func PublicAddressesOnlyOpt(cli *http.Client) error {
+	tr, err := getTransportForMutation(cli)
+	if err != nil {
+		return errors.Wrap(err, "httpcli.PublicAddressesOnlyOpt")
+	}
+
+	dialer := &net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip := net.ParseIP(host); ip == nil || !IsPublicIP(ip) {
+				return errors.Errorf("refusing to connect to non-public address %s", host)
+			}
+			return nil
+		},
+	}
+	tr.Proxy = nil
+	tr.DialContext = dialer.DialContext
+
+	return nil
+}
+
+// nonPublicNetworks are the IP networks that are not reachable on the public
+// internet.
+var nonPublicNetworks = func() []*net.IPNet {
+	var networks []*net.IPNet
+	for _, cidr := range []string{
+		"0.0.0.0/8",      // "this" network
+		"10.0.0.0/8",     // private
+		"100.64.0.0/10",  // carrier-grade NAT
+		"127.0.0.0/8",    // loopback
+		"169.254.0.0/16", // link-local, including cloud metadata services
+		"172.16.0.0/12",  // private
+		"192.168.0.0/16", // private
+		"224.0.0.0/4",    // multicast
+		"240.0.0.0/4",    // reserved
+		"::/128",         // unspecified
+		"::1/128",        // loopback
+		"fc00::/7",       // unique local
+		"fe80::/10",      // link-local
+		"ff00::/8",       // multicast
+	} {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			panic(err)
+		}
+		networks = append(networks, network)
+	}
+	return networks
+}()
+
+// IsPublicIP reports whether ip is reachable on the public internet, that is
+// whether it is not a loopback, private, link-local, multicast or reserved
+// address. IPv4-mapped IPv6 addresses are checked as IPv4 addresses.
+func IsPublicIP(ip net.IP) bool {
+	if ip4 := ip.To4(); ip4 != nil {
+		ip = ip4
+	}
+	for _, network := range nonPublicNetworks {
+		if network.Contains(ip) {
+			return false
+		}
+	}
+	return true
+}
+
 // getTransport returns the http.Transport for cli. If Transport is nil, it is
 // set to a copy of the DefaultTransport. If it is the DefaultTransport, it is
 // updated to a copy of the DefaultTransport.
@@ -11,6 +11,7 @@ import (
 	"errors"
 	"fmt"
 	"math/big"
+	"net"
 	"net/http"
 	"net/http/httptest"
 	"reflect"
@@ -262,6 +263,43 @@ func TestNewIdleConnTimeoutOpt(t *testing.T) {
 	}
 }
 
+func TestPublicAddressesOnlyOpt(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+
+	cli := &http.Client{Transport: &http.Transport{}}
+	if err := PublicAddressesOnlyOpt(cli); err != nil {
+		t.Fatal(err)
+	}
+
+	// The test server listens on a loopback address.
+	_, err := cli.Get(srv.URL)
+	if err == nil || !strings.Contains(err.Error(), "refusing to connect to non-public address 127.0.0.1") {
+		t.Fatalf("have error %v, want non-public address error", err)
+	}
+}
+
+func TestIsPublicIP(t *testing.T) {
+	for ip, want := range map[string]bool{
+		"8.8.8.8":          true,
+		"2001:4860::8888":  true,
+		"127.0.0.1":        false,
+		"10.1.2.3":         false,
+		"172.20.0.1":       false,
+		"192.168.1.1":      false,
+		"169.254.169.254":  false,
+		"0.0.0.0":          false,
+		"::1":              false,
+		"fd00::1":          false,
+		"fe80::1":          false,
+		"::ffff:127.0.0.1": false,
+	} {
+		if have := IsPublicIP(net.ParseIP(ip)); have != want {
+			t.Errorf("%s: have %v, want %v", ip, have, want)
+		}
+	}
+}
+
 func newFakeClient(code int, body []byte, err error) Doer {
 	return DoerFunc(func(r *http.Request) (*http.Response, error) {
 		rr := httptest.NewRecorder()
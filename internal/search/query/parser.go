@@ -26,7 +26,10 @@ Parser implements a parser for the following grammar:
 OrTerm     → AndTerm { OR AndTerm }
 AndTerm    → Term { AND Term }
 Term       → (OrTerm) | Parameters
-Parameters → Parameter { " " Parameter }
+Parameters → [ NOT ] Parameter { " " Parameter }
+
+NOT is only recognized before the first search pattern of a term, so that a
+pattern like "does not exist" is not negated.
 */
 
 type Node interface {
@@ -63,6 +66,8 @@ type Operator struct {
 func (node Parameter) String() string {
 	var v string
 	switch {
+	case node.Field == "" && node.Negated:
+		v = "not " + node.Value
 	case node.Field == "":
 		v = node.Value
 	case node.Negated:
@@ -97,6 +102,7 @@ type keyword string
 const (
 	AND    keyword = "and"
 	OR     keyword = "or"
+	NOT    keyword = "not"
 	LPAREN keyword = "("
 	RPAREN keyword = ")"
 	SQUOTE keyword = "'"
@@ -207,6 +213,23 @@ func (p *parser) matchKeyword(keyword keyword) bool {
 	return strings.ToLower(v) == string(keyword)
 }
 
+// matchNot is like matchKeyword for the NOT keyword, except that NOT may also
+// start the input or follow an opening parenthesis.
+func (p *parser) matchNot() bool {
+	if p.pos > 0 && !isSpace(p.buf[p.pos-1:p.pos]) && p.buf[p.pos-1] != '(' {
+		return false
+	}
+	v, err := p.peek(len(string(NOT)))
+	if err != nil {
+		return false
+	}
+	after := p.pos + len(string(NOT))
+	if after+1 > len(p.buf) || !isSpace(p.buf[after:after+1]) {
+		return false
+	}
+	return strings.ToLower(v) == string(NOT)
+}
+
 // skipSpaces advances the input and places the parser position at the next
 // non-space value.
 func (p *parser) skipSpaces() error {
@@ -573,6 +596,16 @@ func containsPattern(node Node) bool {
 	return result
 }
 
+// containsPatterns returns true if any descendent of nodes is a search pattern.
+func containsPatterns(nodes []Node) bool {
+	for _, node := range nodes {
+		if containsPattern(node) {
+			return true
+		}
+	}
+	return false
+}
+
 // returns true if descendent of node contains and/or expressions.
 func containsAndOrExpression(nodes []Node) bool {
 	var result bool
@@ -664,6 +697,17 @@ loop:
 		case p.matchKeyword(AND), p.matchKeyword(OR):
 			// Caller advances.
 			break loop
+		case !containsPatterns(nodes) && p.matchNot():
+			p.pos += len(string(NOT))
+			if err := p.skipSpaces(); err != nil {
+				return nil, err
+			}
+			if p.done() || p.match(LPAREN) || p.match(RPAREN) || p.matchKeyword(AND) || p.matchKeyword(OR) {
+				return nil, &UnsupportedError{Msg: fmt.Sprintf("expected search pattern or field after not at %d", p.pos)}
+			}
+			parameter := p.ParseParameter()
+			parameter.Negated = !parameter.Negated
+			nodes = append(nodes, parameter)
 		default:
 			// First try parse a parameter as a search pattern containing parens.
 			if parameter, ok := p.ParseSearchPatternHeuristic(); ok {
@@ -635,6 +635,36 @@ func Test_Parse(t *testing.T) {
 			WantGrammar:   `(and "repo:foo bar" ":\\")`,
 			WantHeuristic: Same,
 		},
+		{
+			Name:          "Not pattern",
+			Input:         `a and not b`,
+			WantGrammar:   `(and "a" "not b")`,
+			WantHeuristic: Same,
+		},
+		{
+			Name:          "Not pattern after scope",
+			Input:         `repo:foo not b or c`,
+			WantGrammar:   Spec(`(or (and "repo:foo" "not b") "c")`),
+			WantHeuristic: Diff(`(and "repo:foo" (or "not b" "c"))`),
+		},
+		{
+			Name:          "Not field",
+			Input:         `not repo:foo and b`,
+			WantGrammar:   `(and "-repo:foo" "b")`,
+			WantHeuristic: Same,
+		},
+		{
+			Name:          "Not in group",
+			Input:         `(not b) or c`,
+			WantGrammar:   `(or "not b" "c")`,
+			WantHeuristic: Same,
+		},
+		{
+			Name:          "Not inside pattern is literal",
+			Input:         `does not exist or c`,
+			WantGrammar:   `(or (concat "does" "not" "exist") "c")`,
+			WantHeuristic: Same,
+		},
 	}
 	for _, tt := range cases {
 		t.Run(tt.Name, func(t *testing.T) {
@@ -4,6 +4,7 @@ package query
 
 import (
 	"errors"
+	"fmt"
 	"strings"
 
 	"github.com/sourcegraph/sourcegraph/internal/search/query/syntax"
@@ -42,8 +43,13 @@ const (
 	FieldTimeout   = "timeout"
 	FieldReplace   = "replace"
 	FieldCombyRule = "rule"
+	FieldSort      = "sort" // Orders results, see SortRelevance.
 )
 
+// SortRelevance is the value of sort: that orders results by relevance instead
+// of by repository and path.
+const SortRelevance = "relevance"
+
 var (
 	regexpNegatableFieldType = types.FieldType{Literal: types.RegexpType, Quoted: types.RegexpType, Negatable: true}
 	stringFieldType          = types.FieldType{Literal: types.StringType, Quoted: types.StringType}
@@ -80,6 +86,7 @@ var (
 			FieldTimeout:   {Literal: types.StringType, Quoted: types.StringType, Singular: true},
 			FieldReplace:   {Literal: types.StringType, Quoted: types.StringType, Singular: true},
 			FieldCombyRule: {Literal: types.StringType, Quoted: types.StringType, Singular: true},
+			FieldSort:      {Literal: types.StringType, Quoted: types.StringType, Singular: true},
 		},
 		FieldAliases: map[string]string{
 			"r":        FieldRepo,
@@ -166,6 +173,11 @@ func (e *ValidationError) Error() string {
 // Validate validates legal combinations of fields and search patterns of a
 // successfully parsed query.
 func Validate(q QueryInfo, searchType SearchType) error {
+	if sort, _ := q.StringValue(FieldSort); sort != "" {
+		if err := validateSort(sort); err != nil {
+			return err
+		}
+	}
 	if searchType == SearchTypeStructural {
 		if q.Fields()[FieldCase] != nil {
 			return errors.New(`the parameter "case:" is not valid for structural search, matching is always case-sensitive`)
@@ -177,6 +189,13 @@ func Validate(q QueryInfo, searchType SearchType) error {
 	return nil
 }
 
+func validateSort(value string) error {
+	if value != SortRelevance {
+		return fmt.Errorf("invalid value %q for field %q, the only supported value is %q", value, FieldSort, SortRelevance)
+	}
+	return nil
+}
+
 // Process is a top level convenience function for processing a raw string into
 // a validated and type checked query, and the parse tree of the raw string.
 func Process(queryString string, searchType SearchType) (QueryInfo, error) {
@@ -163,6 +163,12 @@ func TestQuery_Validate(t *testing.T) {
 			SearchType: SearchTypeStructural,
 			Want:       "",
 		},
+		{
+			Name:       `Unsupported "sort:" value`,
+			Query:      `sort:stars foo`,
+			SearchType: SearchTypeRegex,
+			Want:       `invalid value "stars" for field "sort", the only supported value is "relevance"`,
+		},
 	}
 	for _, tt := range cases {
 		t.Run(tt.Name, func(t *testing.T) {
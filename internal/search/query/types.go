@@ -214,7 +214,8 @@ func valueToTypedValue(field, value string, quoted bool) []*types.Value {
 		FieldMax,
 		FieldTimeout,
 		FieldReplace,
-		FieldCombyRule:
+		FieldCombyRule,
+		FieldSort:
 		return []*types.Value{{String: &value}}
 	}
 	return []*types.Value{{String: &value}}
@@ -29,6 +29,18 @@ func isPatternExpression(nodes []Node) bool {
 	return result
 }
 
+// containsNegatedPattern returns true if any search pattern in nodes is
+// negated with the NOT keyword.
+func containsNegatedPattern(nodes []Node) bool {
+	var result bool
+	VisitField(nodes, "", func(_ string, negated, _ bool) {
+		if negated {
+			result = true
+		}
+	})
+	return result
+}
+
 // ContainsAndOrKeyword returns true if this query contains or- or and-
 // keywords. It is a temporary signal to determine whether we can fallback to
 // the older existing search functionality.
@@ -117,6 +129,11 @@ func isPureSearchPattern(buf []byte) bool {
 		// "repo:foo", which are not search patterns.
 		return false
 	}
+	if containsNegatedPattern(result) {
+		// The balanced string contains a "not" expression, like
+		// "(not foo)".
+		return false
+	}
 	return true
 }
 
@@ -261,6 +278,9 @@ func validateField(field, value string, negated bool, seen map[string]struct{})
 		FieldReplace,
 		FieldCombyRule:
 		return satisfies(isSingular, isNotNegated)
+	case
+		FieldSort:
+		return satisfies(isSingular, isNotNegated, func() error { return validateSort(value) })
 	default:
 		return isUnrecognizedField()
 	}
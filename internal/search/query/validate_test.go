@@ -49,6 +49,10 @@ func TestAndOrQuery_Validation(t *testing.T) {
 			input: "count:-1",
 			want:  "field count requires a positive number",
 		},
+		{
+			input: "sort:stars",
+			want:  `invalid value "stars" for field "sort", the only supported value is "relevance"`,
+		},
 	}
 	for _, c := range cases {
 		t.Run("validate and/or query", func(t *testing.T) {
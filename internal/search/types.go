@@ -129,6 +129,10 @@ type TextPatternInfo struct {
 	PatternMatchesPath    bool
 
 	Languages []string
+
+	// FilePaths, if non-empty, is the exact list of files to search. It is
+	// set for structural search to the candidate files found by the index.
+	FilePaths []string
 }
 
 func (p *TextPatternInfo) String() string {
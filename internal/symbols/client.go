@@ -70,7 +70,10 @@ func (c *Client) url(key key) (string, error) {
 			c.endpoint = endpoint.New(c.URL)
 		}
 	})
-	return c.endpoint.Get(string(key.repo)+":"+string(key.commitID), nil)
+	// All commits of a repository are served by the same symbols service
+	// instance, so that it can index them incrementally from the symbols of
+	// their ancestors.
+	return c.endpoint.Get(string(key.repo), nil)
 }
 
 // Search performs a symbol search on the symbols service.
@@ -102,6 +105,34 @@ func (c *Client) Search(ctx context.Context, args search.SymbolsParameters) (res
 	return result, err
 }
 
+// SearchCommits returns the commits among args.Revs that define symbols matching the query.
+func (c *Client) SearchCommits(ctx context.Context, args protocol.SearchCommitsArgs) (result *protocol.SearchCommitsResult, err error) {
+	span, ctx := ot.StartSpanFromContext(ctx, "symbols.Client.SearchCommits")
+	defer func() {
+		if err != nil {
+			ext.Error.Set(span, true)
+			span.LogFields(otlog.Error(err))
+		}
+		span.Finish()
+	}()
+	span.SetTag("Repo", string(args.Repo))
+
+	resp, err := c.httpPost(ctx, "search-commits", key{repo: args.Repo}, args)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		// best-effort inclusion of body in error message
+		body, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 200))
+		return nil, errors.Errorf("Symbol.SearchCommits http status %d: %s", resp.StatusCode, string(body))
+	}
+
+	err = json.NewDecoder(resp.Body).Decode(&result)
+	return result, err
+}
+
 func (c *Client) httpPost(ctx context.Context, method string, key key, payload interface{}) (resp *http.Response, err error) {
 	span, ctx := ot.StartSpanFromContext(ctx, "symbols.Client.httpPost")
 	defer func() {
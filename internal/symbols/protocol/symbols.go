@@ -56,3 +56,29 @@ type Symbol struct {
 
 	FileLimited bool
 }
+
+// SearchCommitsArgs are the arguments to find the commits of a repository that
+// define symbols matching a query.
+type SearchCommitsArgs struct {
+	// SearchArgs are the arguments of the search in each commit. Its CommitID
+	// is ignored.
+	SearchArgs
+
+	// Revs are the revisions (such as branch names or commit IDs) to search
+	// in. If empty, the heads of all branches are searched.
+	Revs []string `json:"revs"`
+}
+
+// SearchCommitsResult is the result of a commits search on the symbols service.
+type SearchCommitsResult struct {
+	// Commits are the commits with at least one matching symbol, ordered by
+	// their first revision.
+	Commits []CommitSymbols
+}
+
+// CommitSymbols are the symbols matching a query in a commit.
+type CommitSymbols struct {
+	CommitID api.CommitID
+	Revs     []string // the searched revisions that resolve to CommitID
+	Symbols  []Symbol
+}
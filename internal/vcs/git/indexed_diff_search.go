@@ -0,0 +1,146 @@
+package git
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+
+	"github.com/sourcegraph/sourcegraph/internal/gitserver"
+	"github.com/sourcegraph/sourcegraph/internal/gitserver/protocol"
+	"github.com/sourcegraph/sourcegraph/internal/trace"
+)
+
+// IndexedLogDiffSearchOptions specifies options to IndexedLogDiffSearch.
+type IndexedLogDiffSearchOptions struct {
+	// Query specifies the search query to find in the added and removed lines
+	// of a commit's diff.
+	Query TextSearchOptions
+
+	// Diff is whether the diff should be computed and returned.
+	Diff bool
+
+	// OnlyMatchingHunks makes the diff only include hunks that match the query. If false,
+	// all hunks from files that match the query are included.
+	OnlyMatchingHunks bool
+
+	// Paths specifies the paths to include/exclude.
+	Paths PathOptions
+
+	// MessagePatterns, AuthorPatterns and CommitterPatterns are regexps which
+	// must all match (or, if InvertPatterns, must not all match) the commit.
+	// They behave like `git log --all-match --grep/--author/--committer`.
+	MessagePatterns   []string
+	AuthorPatterns    []string
+	CommitterPatterns []string
+	InvertPatterns    bool
+	// PatternsAreCaseSensitive is whether the message, author and committer
+	// patterns are matched case sensitively.
+	PatternsAreCaseSensitive bool
+
+	// Before and After are `git log --until` and `--since` values.
+	Before []string
+	After  []string
+
+	// Limit is the maximum number of commits to return.
+	Limit int
+}
+
+// IndexedLogDiffSearch is like RawLogDiffSearch, but it searches the commit
+// index of the repository's default branch on gitserver instead of running
+// `git log`. The returned results are the same as those of RawLogDiffSearch
+// for the default branch.
+//
+// If indexed is false, the repository has no up-to-date commit index and the
+// caller should use RawLogDiffSearch instead.
+func IndexedLogDiffSearch(ctx context.Context, repo gitserver.Repo, opt IndexedLogDiffSearchOptions) (results []*LogCommitSearchResult, complete, indexed bool, err error) {
+	if Mocks.IndexedLogDiffSearch != nil {
+		return Mocks.IndexedLogDiffSearch(opt)
+	}
+
+	tr, ctx := trace.New(ctx, "Git: IndexedLogDiffSearch", fmt.Sprintf("%+v", opt))
+	defer func() {
+		tr.LazyPrintf("%d results, complete=%v, indexed=%v, err=%v", len(results), complete, indexed, err)
+		tr.SetError(err)
+		tr.Finish()
+	}()
+
+	if opt.Query.IsCaseSensitive != opt.Paths.IsCaseSensitive {
+		// Keep the same restriction as RawLogDiffSearch.
+		return nil, false, false, fmt.Errorf("invalid options: Query.IsCaseSensitive != Paths.IsCaseSensitive")
+	}
+
+	var query *regexp.Regexp
+	if pattern := opt.Query.Pattern; pattern != "" {
+		if !opt.Query.IsRegExp {
+			pattern = regexp.QuoteMeta(pattern)
+		}
+		if !opt.Query.IsCaseSensitive {
+			pattern = "(?i:" + pattern + ")"
+		}
+		query, err = regexp.Compile(pattern)
+		if err != nil {
+			return nil, false, false, err
+		}
+	}
+	pathMatcher, err := compilePathMatcher(opt.Paths)
+	if err != nil {
+		return nil, false, false, err
+	}
+
+	resp, err := gitserver.DefaultClient.CommitSearch(ctx, &protocol.CommitSearchRequest{
+		Repo:                       repo.Name,
+		DiffPattern:                opt.Query.Pattern,
+		DiffPatternIsRegExp:        opt.Query.IsRegExp,
+		DiffPatternIsCaseSensitive: opt.Query.IsCaseSensitive,
+		IncludePatterns:            opt.Paths.IncludePatterns,
+		ExcludePattern:             opt.Paths.ExcludePattern,
+		PathPatternsAreRegExps:     opt.Paths.IsRegExp,
+		MessagePatterns:            opt.MessagePatterns,
+		AuthorPatterns:             opt.AuthorPatterns,
+		CommitterPatterns:          opt.CommitterPatterns,
+		InvertPatterns:             opt.InvertPatterns,
+		PatternsAreCaseSensitive:   opt.PatternsAreCaseSensitive,
+		Before:                     opt.Before,
+		After:                      opt.After,
+		Limit:                      opt.Limit,
+	})
+	if err != nil {
+		return nil, false, false, err
+	}
+	if !resp.Indexed {
+		return nil, false, false, nil
+	}
+
+	hasPathFilters := opt.Paths.ExcludePattern != "" || len(opt.Paths.IncludePatterns) > 0
+	for _, c := range resp.Results {
+		committer := Signature(c.Committer)
+		result := &LogCommitSearchResult{
+			Commit: Commit{
+				ID:        c.ID,
+				Author:    Signature(c.Author),
+				Committer: &committer,
+				Message:   c.Message,
+				Parents:   c.Parents,
+			},
+			Refs:       c.Refs,
+			SourceRefs: c.SourceRefs,
+		}
+
+		// Like RawLogDiffSearch, only return a diff if it was requested or
+		// needed to filter by path.
+		if opt.Diff || hasPathFilters {
+			rawDiff, highlights, err := filterAndHighlightDiff([]byte(c.RawDiff), query, opt.OnlyMatchingHunks, pathMatcher)
+			if err != nil {
+				return nil, false, true, err
+			}
+			if rawDiff == nil {
+				continue
+			}
+			result.Diff = &Diff{Raw: string(rawDiff)}
+			result.DiffHighlights = highlights
+		}
+
+		results = append(results, result)
+	}
+	return results, resp.Complete, true, nil
+}
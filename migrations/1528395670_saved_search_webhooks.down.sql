@@ -0,0 +1,6 @@
+BEGIN;
+
+ALTER TABLE saved_searches DROP COLUMN webhook_url;
+ALTER TABLE saved_searches DROP COLUMN teams_webhook_url;
+
+COMMIT;
@@ -0,0 +1,6 @@
+BEGIN;
+
+ALTER TABLE saved_searches ADD COLUMN webhook_url TEXT;
+ALTER TABLE saved_searches ADD COLUMN teams_webhook_url TEXT;
+
+COMMIT;
@@ -0,0 +1,13 @@
+BEGIN;
+
+DROP TABLE IF EXISTS lsif_moniker_references;
+
+-- Drop view dependent on column
+DROP VIEW lsif_dumps;
+
+ALTER TABLE lsif_uploads DROP COLUMN IF EXISTS moniker_references_indexed;
+
+-- Recreate view without column
+CREATE VIEW lsif_dumps AS SELECT u.*, u.finished_at as processed_at FROM lsif_uploads u WHERE state = 'completed';
+
+COMMIT;
@@ -0,0 +1,23 @@
+BEGIN;
+
+-- Reverse index of monikers to the dumps that reference them
+CREATE TABLE lsif_moniker_references (
+    dump_id integer NOT NULL REFERENCES lsif_uploads(id) ON DELETE CASCADE,
+    scheme text NOT NULL,
+    identifier text NOT NULL,
+    count integer NOT NULL,
+    PRIMARY KEY (scheme, identifier, dump_id)
+);
+
+CREATE INDEX lsif_moniker_references_dump_id ON lsif_moniker_references(dump_id);
+
+-- Drop view dependent on lsif_uploads
+DROP VIEW lsif_dumps;
+
+-- Track which dumps have been added to the reverse index
+ALTER TABLE lsif_uploads ADD COLUMN moniker_references_indexed boolean NOT NULL DEFAULT false;
+
+-- Recreate view with new column
+CREATE VIEW lsif_dumps AS SELECT u.*, u.finished_at as processed_at FROM lsif_uploads u WHERE state = 'completed';
+
+COMMIT;
@@ -0,0 +1,8 @@
+BEGIN;
+
+DROP TABLE IF EXISTS patch_jobs;
+
+ALTER TABLE patch_sets DROP COLUMN IF EXISTS rewrite_specification;
+ALTER TABLE patch_sets DROP COLUMN IF EXISTS query;
+
+COMMIT;
@@ -0,0 +1,25 @@
+BEGIN;
+
+-- The search query and rewrite a patch set was generated from, if any
+ALTER TABLE patch_sets ADD COLUMN query text NOT NULL DEFAULT '';
+ALTER TABLE patch_sets ADD COLUMN rewrite_specification jsonb;
+
+-- One job per repository whose patch is computed by the replacer
+CREATE TABLE patch_jobs (
+    id bigserial PRIMARY KEY,
+    patch_set_id bigint NOT NULL REFERENCES patch_sets(id) ON DELETE CASCADE DEFERRABLE INITIALLY IMMEDIATE,
+    repo_id bigint NOT NULL REFERENCES repo(id) ON DELETE CASCADE DEFERRABLE INITIALLY IMMEDIATE,
+    rev text NOT NULL,
+    base_ref text NOT NULL,
+    patch_id bigint REFERENCES patches(id) ON DELETE SET NULL DEFERRABLE INITIALLY IMMEDIATE,
+    error text,
+    started_at timestamp with time zone,
+    finished_at timestamp with time zone,
+    created_at timestamp with time zone NOT NULL DEFAULT now(),
+    updated_at timestamp with time zone NOT NULL DEFAULT now(),
+    UNIQUE (patch_set_id, repo_id)
+);
+
+CREATE INDEX patch_jobs_started_at ON patch_jobs(started_at) WHERE started_at IS NULL;
+
+COMMIT;
@@ -0,0 +1,8 @@
+BEGIN;
+
+ALTER TABLE changeset_jobs DROP COLUMN IF EXISTS released_at;
+
+ALTER TABLE campaigns DROP COLUMN IF EXISTS rollout_paused;
+ALTER TABLE campaigns DROP COLUMN IF EXISTS rollout_policy;
+
+COMMIT;
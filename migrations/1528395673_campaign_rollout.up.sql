@@ -0,0 +1,10 @@
+BEGIN;
+
+-- How the changesets of a campaign are published over time
+ALTER TABLE campaigns ADD COLUMN rollout_policy jsonb;
+ALTER TABLE campaigns ADD COLUMN rollout_paused boolean NOT NULL DEFAULT false;
+
+-- When a changeset job of a campaign with a rollout policy may be executed
+ALTER TABLE changeset_jobs ADD COLUMN released_at timestamp with time zone;
+
+COMMIT;
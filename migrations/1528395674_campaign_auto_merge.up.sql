@@ -0,0 +1,6 @@
+BEGIN;
+
+-- When the open changesets of a campaign are merged automatically
+ALTER TABLE campaigns ADD COLUMN auto_merge_policy jsonb;
+
+COMMIT;
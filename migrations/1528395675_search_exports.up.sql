@@ -0,0 +1,34 @@
+BEGIN;
+
+CREATE TABLE search_exports (
+  id bigserial PRIMARY KEY,
+  user_id integer NOT NULL REFERENCES users(id) ON DELETE CASCADE DEFERRABLE,
+  query text NOT NULL,
+  version text NOT NULL,
+  pattern_type text,
+  format text NOT NULL,
+  state text NOT NULL DEFAULT 'QUEUED',
+  error text,
+  -- The pagination cursor of the next page of results to export.
+  cursor text,
+  chunk_count integer NOT NULL DEFAULT 0,
+  result_count integer NOT NULL DEFAULT 0,
+  created_at timestamp with time zone NOT NULL DEFAULT now(),
+  updated_at timestamp with time zone NOT NULL DEFAULT now(),
+  started_at timestamp with time zone,
+  finished_at timestamp with time zone,
+  expires_at timestamp with time zone NOT NULL
+);
+
+CREATE INDEX search_exports_user_id ON search_exports(user_id);
+CREATE INDEX search_exports_state ON search_exports(state);
+
+-- The exported file, in pages of search results.
+CREATE TABLE search_export_chunks (
+  export_id bigint NOT NULL REFERENCES search_exports(id) ON DELETE CASCADE DEFERRABLE,
+  seq integer NOT NULL,
+  data bytea NOT NULL,
+  PRIMARY KEY (export_id, seq)
+);
+
+COMMIT;
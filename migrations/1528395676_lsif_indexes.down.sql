@@ -0,0 +1,6 @@
+BEGIN;
+
+DROP TABLE IF EXISTS lsif_indexes;
+DROP TYPE IF EXISTS lsif_index_state;
+
+COMMIT;
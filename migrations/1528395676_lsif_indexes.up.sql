@@ -0,0 +1,24 @@
+BEGIN;
+
+CREATE TYPE lsif_index_state AS ENUM ('queued', 'processing', 'completed', 'errored');
+
+CREATE TABLE lsif_indexes (
+    id serial PRIMARY KEY,
+    commit text NOT NULL,
+    repository_id integer NOT NULL,
+    repository_name text NOT NULL,
+    state lsif_index_state NOT NULL DEFAULT 'queued',
+    failure_summary text,
+    num_failures integer NOT NULL DEFAULT 0,
+    queued_at timestamp with time zone NOT NULL DEFAULT now(),
+    process_after timestamp with time zone,
+    started_at timestamp with time zone,
+    finished_at timestamp with time zone,
+    upload_id integer REFERENCES lsif_uploads(id) ON DELETE SET NULL,
+    CONSTRAINT lsif_indexes_commit_valid_chars CHECK (commit ~ '^[a-z0-9]{40}$')
+);
+
+CREATE UNIQUE INDEX lsif_indexes_repository_id_commit ON lsif_indexes(repository_id, commit);
+CREATE INDEX lsif_indexes_state ON lsif_indexes(state);
+
+COMMIT;
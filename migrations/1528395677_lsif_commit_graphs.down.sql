@@ -0,0 +1,6 @@
+BEGIN;
+
+DROP TABLE IF EXISTS lsif_nearest_uploads;
+DROP TABLE IF EXISTS lsif_commit_graphs;
+
+COMMIT;
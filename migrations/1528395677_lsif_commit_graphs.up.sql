@@ -0,0 +1,20 @@
+BEGIN;
+
+CREATE TABLE lsif_commit_graphs (
+    repository_id integer PRIMARY KEY,
+    tip_commit text NOT NULL,
+    uploads_fingerprint text NOT NULL,
+    updated_at timestamp with time zone NOT NULL DEFAULT now()
+);
+
+CREATE TABLE lsif_nearest_uploads (
+    repository_id integer NOT NULL,
+    commit text NOT NULL,
+    upload_id integer NOT NULL REFERENCES lsif_uploads(id) ON DELETE CASCADE,
+    distance integer NOT NULL
+);
+
+CREATE INDEX lsif_nearest_uploads_repository_id_commit ON lsif_nearest_uploads(repository_id, commit);
+CREATE INDEX lsif_nearest_uploads_upload_id ON lsif_nearest_uploads(upload_id);
+
+COMMIT;
@@ -0,0 +1,6 @@
+BEGIN;
+
+ALTER TABLE access_tokens ADD COLUMN expires_at timestamp with time zone;
+ALTER TABLE access_tokens ADD COLUMN repository_patterns text[] NOT NULL DEFAULT '{}';
+
+COMMIT;
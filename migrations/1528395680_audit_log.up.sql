@@ -0,0 +1,39 @@
+BEGIN;
+
+-- The audit log of security-relevant actions of site admins. Entries are never updated or deleted.
+-- Each entry's hash covers its contents and the hash of the entry before it, so that changes to
+-- past entries can be detected.
+CREATE TABLE audit_log (
+  id bigserial PRIMARY KEY,
+  -- Not a foreign key, so that entries outlive the users who performed them.
+  actor_user_id integer NOT NULL,
+  actor_ip text NOT NULL,
+  forwarded_for text NOT NULL DEFAULT '',
+  action text NOT NULL,
+  target_type text NOT NULL,
+  target_id text NOT NULL,
+  -- The payloads are stored as json (not jsonb) so that they're returned exactly as they were
+  -- hashed.
+  before json,
+  after json,
+  created_at timestamp with time zone NOT NULL,
+  prev_hash text NOT NULL,
+  hash text NOT NULL
+);
+
+CREATE INDEX audit_log_actor_user_id ON audit_log(actor_user_id);
+CREATE INDEX audit_log_action ON audit_log(action);
+CREATE INDEX audit_log_target ON audit_log(target_type, target_id);
+CREATE INDEX audit_log_created_at ON audit_log(created_at);
+
+CREATE FUNCTION audit_log_append_only() RETURNS trigger
+    LANGUAGE plpgsql
+    AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_log is append-only';
+END;
+$$;
+
+CREATE TRIGGER audit_log_append_only BEFORE UPDATE OR DELETE ON audit_log FOR EACH ROW EXECUTE PROCEDURE audit_log_append_only();
+
+COMMIT;
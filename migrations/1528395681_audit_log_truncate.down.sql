@@ -0,0 +1,5 @@
+BEGIN;
+
+DROP TRIGGER IF EXISTS audit_log_append_only_truncate ON audit_log;
+
+COMMIT;
@@ -0,0 +1,6 @@
+BEGIN;
+
+-- Row-level triggers don't fire on TRUNCATE, so reject it with a statement-level trigger.
+CREATE TRIGGER audit_log_append_only_truncate BEFORE TRUNCATE ON audit_log FOR EACH STATEMENT EXECUTE PROCEDURE audit_log_append_only();
+
+COMMIT;
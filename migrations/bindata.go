@@ -106,6 +106,30 @@
 // 1528395668_campaign_description_nullable.up.sql (143B)
 // 1528395669_add_synced_at_to_perms_tables.down.sql (121B)
 // 1528395669_add_synced_at_to_perms_tables.up.sql (143B)
+// 1528395670_saved_search_webhooks.down.sql (127B)
+// 1528395670_saved_search_webhooks.up.sql (135B)
+// 1528395671_lsif_moniker_references.up.sql (775B)
+// 1528395671_lsif_moniker_references.down.sql (343B)
+// 1528395672_patch_jobs.down.sql (171B)
+// 1528395672_patch_jobs.up.sql (1045B)
+// 1528395673_campaign_rollout.down.sql (200B)
+// 1528395673_campaign_rollout.up.sql (365B)
+// 1528395674_campaign_auto_merge.down.sql (80B)
+// 1528395674_campaign_auto_merge.up.sql (142B)
+// 1528395675_search_exports.up.sql (1100B)
+// 1528395675_search_exports.down.sql (97B)
+// 1528395676_lsif_indexes.down.sql (90B)
+// 1528395676_lsif_indexes.up.sql (842B)
+// 1528395677_lsif_commit_graphs.down.sql (101B)
+// 1528395677_lsif_commit_graphs.up.sql (619B)
+// 1528395678_access_token_restrictions.down.sql (146B)
+// 1528395678_access_token_restrictions.up.sql (178B)
+// 1528395679_config_author.down.sql (92B)
+// 1528395679_config_author.up.sql (129B)
+// 1528395680_audit_log.down.sql (98B)
+// 1528395680_audit_log.up.sql (1325B)
+// 1528395681_audit_log_truncate.down.sql (85B)
+// 1528395681_audit_log_truncate.up.sql (245B)
 
 package migrations
 
@@ -2294,6 +2318,486 @@ func _1528395669_add_synced_at_to_perms_tablesUpSql() (*asset, error) {
 	return a, nil
 }
 
+var __1528395670_saved_search_webhooksDownSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x00\x7f\x00\x80\xff\x42\x45\x47\x49\x4e\x3b\x0a\x0a\x41\x4c\x54\x45\x52\x20\x54\x41\x42\x4c\x45\x20\x73\x61\x76\x65\x64\x5f\x73\x65\x61\x72\x63\x68\x65\x73\x20\x44\x52\x4f\x50\x20\x43\x4f\x4c\x55\x4d\x4e\x20\x77\x65\x62\x68\x6f\x6f\x6b\x5f\x75\x72\x6c\x3b\x0a\x41\x4c\x54\x45\x52\x20\x54\x41\x42\x4c\x45\x20\x73\x61\x76\x65\x64\x5f\x73\x65\x61\x72\x63\x68\x65\x73\x20\x44\x52\x4f\x50\x20\x43\x4f\x4c\x55\x4d\x4e\x20\x74\x65\x61\x6d\x73\x5f\x77\x65\x62\x68\x6f\x6f\x6b\x5f\x75\x72\x6c\x3b\x0a\x0a\x43\x4f\x4d\x4d\x49\x54\x3b\x0a\x03\x00\xce\x43\x1c\xac\x7f\x00\x00\x00")
+
+func _1528395670_saved_search_webhooksDownSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__1528395670_saved_search_webhooksDownSql,
+		"1528395670_saved_search_webhooks.down.sql",
+	)
+}
+
+func _1528395670_saved_search_webhooksDownSql() (*asset, error) {
+	bytes, err := _1528395670_saved_search_webhooksDownSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "1528395670_saved_search_webhooks.down.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0xd7, 0x6f, 0xe9, 0xc9, 0xd5, 0xa5, 0x9, 0x54, 0xb8, 0x73, 0x70, 0xc0, 0x2b, 0x87, 0xc7, 0x36, 0x9f, 0xe0, 0x37, 0x7f, 0xef, 0x1, 0x6e, 0xea, 0x64, 0x4, 0x55, 0x41, 0x1c, 0xf6, 0x29, 0x46}}
+	return a, nil
+}
+
+var __1528395670_saved_search_webhooksUpSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x72\x72\x75\xf7\xf4\xb3\xe6\xe2\x72\xf4\x09\x71\x0d\x52\x08\x71\x74\xf2\x71\x55\x28\x4e\x2c\x4b\x4d\x89\x2f\x4e\x4d\x2c\x4a\xce\x48\x2d\x56\x70\x74\x71\x51\x70\xf6\xf7\x09\xf5\xf5\x53\x28\x4f\x4d\xca\xc8\xcf\xcf\x8e\x2f\x2d\xca\x51\x08\x71\x8d\x08\xb1\x26\x52\x5f\x49\x6a\x62\x6e\x71\x3c\xa6\x6e\x2e\x67\x7f\x5f\x5f\xcf\x10\x6b\x2e\xc0\x00\x47\x66\xf7\x12\x87\x00\x00\x00")
+
+func _1528395670_saved_search_webhooksUpSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__1528395670_saved_search_webhooksUpSql,
+		"1528395670_saved_search_webhooks.up.sql",
+	)
+}
+
+func _1528395670_saved_search_webhooksUpSql() (*asset, error) {
+	bytes, err := _1528395670_saved_search_webhooksUpSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "1528395670_saved_search_webhooks.up.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0x42, 0xfa, 0xcc, 0x18, 0xf0, 0x9b, 0xb3, 0xf2, 0xf0, 0xb0, 0xcc, 0x6a, 0x8e, 0x39, 0xe4, 0x24, 0xb1, 0xb5, 0x38, 0x2b, 0x57, 0x8f, 0x20, 0x76, 0x81, 0x82, 0xa1, 0xab, 0x7d, 0x89, 0x8f, 0x6e}}
+	return a, nil
+}
+
+var __1528395671_lsif_moniker_referencesUpSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x74\x52\xcd\x6e\xdb\x3c\x10\xbc\xf3\x29\xe6\x16\xfb\x83\x93\x17\x30\xbe\x83\x22\xad\x5b\xa3\xfa\x09\x64\xb9\x69\x4e\x82\x22\xae\x2a\x22\x12\x29\x90\x94\x9d\xc7\x2f\x6c\x59\x86\xd3\x34\x47\x72\x76\x67\x76\x76\xf6\x91\xbe\x6d\xd3\xb5\x10\xf7\xf7\xc8\xf9\xc0\xd6\x31\x94\x96\xfc\x0e\xd3\xa0\x37\x5a\xbd\xb1\x75\xf0\x06\xbe\x65\xc8\xb1\x1f\x1c\x7c\x5b\x79\x58\x6e\xd8\xb2\xae\xf9\x04\xf4\x22\xcc\x29\x28\x08\x45\xf0\x18\x13\x3a\xa7\x9a\xf2\xd2\x5b\x5e\x0b\x1d\x16\x02\xc0\x99\xa4\x54\x12\x4a\x7b\xfe\xcd\x16\x69\x56\x20\xdd\xc7\x31\x72\xda\x50\x4e\x69\x48\xbb\x89\x61\x1c\x3a\x53\x49\xb7\x50\x72\x89\x2c\x45\x44\x31\x15\x84\x30\xd8\x85\x41\x44\xab\x33\x97\xab\x5b\xee\x19\x9e\xdf\xfd\x95\x67\x42\x94\x64\xed\x55\xa3\xd8\xfe\x0b\xad\xcd\xa8\xfd\xa7\x09\x26\xec\x29\xdf\x26\x41\xfe\x82\x1f\xf4\x82\xc5\x24\xb0\xba\xa1\x5b\xcd\x06\x96\x62\xb9\x16\xb3\xf1\x6d\x1a\xd1\xaf\xaf\x8c\x97\xb3\xe5\x2c\xfd\xaa\x64\x31\x93\x4e\x49\x44\xd6\x0c\x38\x28\x3e\x42\xf2\xc0\xfa\x24\x0e\xa3\x3f\xac\x45\x44\x79\xf6\x84\x9f\x5b\x7a\x9e\xbe\x4f\x04\x6e\xea\x2e\x6c\x55\xbf\xe1\xd8\xaa\xba\xbd\x44\xd6\x56\x07\xc6\x2b\xb3\x46\x25\x25\xcb\x39\x4f\x7b\x1b\xb8\x08\xe2\x82\xf2\xdb\x08\x2f\x4a\x08\xa2\x08\x61\x16\xef\x93\x14\x9f\x27\x2f\xcf\xd7\xc2\x12\xaf\xc6\x74\x5c\xe9\xeb\x36\x11\xd1\x26\xd8\xc7\x05\x9a\xaa\x73\x3c\x5f\x58\x6d\xb9\xf2\x3c\x79\x3b\x2a\xdf\x42\xf3\x11\xb5\xe9\xc6\x5e\xcf\xbb\xfc\xcb\x13\x82\x1d\x76\x14\x53\x58\x60\x7c\xf8\x6f\x85\xf1\xa1\x51\x5a\xb9\x96\x65\x59\x79\x54\x0e\x83\x35\x35\x3b\x37\xbd\x37\x79\x96\x7c\x9c\x7e\xc4\xf3\x77\xca\x09\xce\x9f\x84\xff\xc7\x5d\x6d\xfa\xa1\x63\xcf\xf2\x6e\x2d\x44\x98\x25\xc9\xb6\x58\x8b\x3f\x03\x00\xd3\x19\x2f\xa1\x07\x03\x00\x00")
+
+func _1528395671_lsif_moniker_referencesUpSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__1528395671_lsif_moniker_referencesUpSql,
+		"1528395671_lsif_moniker_references.up.sql",
+	)
+}
+
+func _1528395671_lsif_moniker_referencesUpSql() (*asset, error) {
+	bytes, err := _1528395671_lsif_moniker_referencesUpSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "1528395671_lsif_moniker_references.up.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0x3e, 0x4c, 0xcf, 0xa2, 0xd3, 0x1d, 0xbf, 0xd7, 0x3e, 0x55, 0x37, 0x34, 0xc9, 0xbf, 0xe6, 0xbe, 0x75, 0x1e, 0x4c, 0x96, 0x68, 0x87, 0x1d, 0xfd, 0x5d, 0x30, 0xd6, 0x1e, 0x6d, 0x62, 0xe4, 0xbf}}
+	return a, nil
+}
+
+var __1528395671_lsif_moniker_referencesDownSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x64\xce\xcf\x4e\xc3\x30\x0c\x06\xf0\x7b\x9e\xc2\xb7\x49\x88\xed\x05\x2a\x0e\x5d\xe7\x41\xa5\x76\x45\x69\x60\xdc\xaa\x2a\x71\xb5\x88\x36\x89\xf2\x87\xf1\xf8\x68\x0b\x48\xc0\x8e\x96\xed\xef\xfb\x6d\xf1\xb1\x3e\x14\x8c\xed\x78\xf7\x0c\xa2\xdc\x36\x08\xf5\x1e\xf0\xad\xee\x45\x0f\x73\xd0\xd3\xb0\x58\xa3\xdf\xc9\x0f\x9e\x26\xf2\x64\x24\x85\x82\xb1\xf5\x1a\x76\xde\x3a\xf8\xd0\x74\x06\x45\x8e\x8c\x22\x13\xc1\x1a\x90\x76\x4e\x8b\xc9\x79\xaf\x35\x1e\x73\x88\x4a\x8b\xbb\xfc\x95\x8d\x40\xfe\xdd\x73\x5d\x24\x37\xdb\x51\x05\xb8\xde\x57\x5d\xf3\xd2\x1e\x7e\x01\x6e\xbb\x07\x6d\x14\x7d\x92\xca\x06\x4e\xd2\xd3\x18\x29\x3b\xce\x3a\x9e\x6c\x8a\x3f\x84\x8a\x63\x29\xf0\x3f\x02\xca\x1e\x7a\x6c\xb0\x12\x90\x36\x77\xf7\x90\x36\x93\x36\x3a\x9c\x48\x0d\x63\x84\x31\x80\xf3\x56\x52\x08\x79\xde\xf3\xae\xfd\x0b\x4d\x70\x7c\x42\x8e\x10\xe2\xa5\xf7\x01\x56\xd2\x2e\x6e\xa6\x48\x6a\x55\x30\x56\x75\x6d\x5b\x8b\x82\x7d\x0d\x00\xbe\x5e\x05\xab\x57\x01\x00\x00")
+
+func _1528395671_lsif_moniker_referencesDownSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__1528395671_lsif_moniker_referencesDownSql,
+		"1528395671_lsif_moniker_references.down.sql",
+	)
+}
+
+func _1528395671_lsif_moniker_referencesDownSql() (*asset, error) {
+	bytes, err := _1528395671_lsif_moniker_referencesDownSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "1528395671_lsif_moniker_references.down.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0x70, 0x74, 0x8a, 0x7d, 0xa4, 0x4e, 0xbb, 0x6e, 0x72, 0x73, 0x15, 0xdc, 0x98, 0x8f, 0x73, 0x3c, 0xd6, 0x88, 0xdc, 0x9a, 0xc6, 0x29, 0xe8, 0x2e, 0x27, 0xdc, 0x28, 0xf7, 0x41, 0x75, 0x4d, 0x3f}}
+	return a, nil
+}
+
+var __1528395672_patch_jobsDownSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x72\x72\x75\xf7\xf4\xb3\xe6\xe2\x72\x09\xf2\x0f\x50\x08\x71\x74\xf2\x71\x55\xf0\x74\x53\x70\x8d\xf0\x0c\x0e\x09\x56\x28\x48\x2c\x49\xce\x88\xcf\xca\x4f\x2a\xb6\xe6\xe2\x72\xf4\x09\x71\x0d\x82\x2a\x81\x48\x14\xa7\x96\x14\x2b\x80\x35\x3a\xfb\xfb\x84\xfa\xfa\x21\xe9\x2c\x4a\x2d\x2f\xca\x2c\x49\x8d\x2f\x2e\x48\x4d\xce\x4c\xcb\x4c\x4e\x2c\xc9\xcc\xcf\xb3\x26\xcd\x8c\xc2\xd2\xd4\xa2\x4a\x6b\x2e\x2e\x67\x7f\x5f\x5f\xcf\x10\x6b\x2e\xc0\x00\xe9\x0c\x52\x91\xab\x00\x00\x00")
+
+func _1528395672_patch_jobsDownSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__1528395672_patch_jobsDownSql,
+		"1528395672_patch_jobs.down.sql",
+	)
+}
+
+func _1528395672_patch_jobsDownSql() (*asset, error) {
+	bytes, err := _1528395672_patch_jobsDownSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "1528395672_patch_jobs.down.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0x59, 0x55, 0x55, 0x4e, 0x30, 0x71, 0xf6, 0x93, 0x23, 0x52, 0x37, 0x41, 0xef, 0x54, 0x32, 0xdd, 0x6, 0x88, 0xa7, 0xce, 0xc0, 0x17, 0x12, 0x7f, 0xb, 0x49, 0xe7, 0x5c, 0x3d, 0xfe, 0x70, 0xb7}}
+	return a, nil
+}
+
+var __1528395672_patch_jobsUpSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\xa4\x92\xcd\x6e\xdb\x30\x10\x84\xef\x7a\x8a\xb9\xc5\x06\x9c\x27\xf0\x49\x91\x36\x2d\x51\xfd\xb4\xb2\x8c\xd6\x27\x81\x92\xd6\x11\x8d\x98\x54\x49\xba\xae\xfb\xf4\x85\x2c\xa7\x51\xd2\x00\x31\x90\x23\xb1\xb3\x3b\xdf\x2c\xf7\x8e\x3e\x89\x6c\x19\x04\xb7\xb7\x28\x3b\x86\x63\x69\x9b\x0e\x3f\x0f\x6c\x4f\x90\xba\x85\xe5\xa3\x55\x9e\x21\xd1\x4b\xdf\x74\x70\xec\x71\x94\x0e\x0f\xac\xd9\x4a\xcf\x2d\xb6\xd6\xec\x17\x50\x5b\x48\x7d\x0a\xc2\xa4\xa4\x02\x65\x78\x97\xd0\xd8\x50\x39\xf6\x0e\x61\x1c\x23\xca\x93\x75\x9a\x5d\x46\x7b\xfe\xed\x91\xe5\x25\xb2\x75\x92\x20\xa6\xfb\x70\x9d\x94\xb8\xb9\x59\x5e\x31\xe1\x82\x54\xb9\x9e\x1b\xb5\x55\x8d\xf4\xca\x68\xec\x9c\xd1\xf5\x18\x24\xd7\x8c\x9d\xa9\xd1\xb3\x85\xe5\xde\x38\xe5\x8d\x3d\xe1\xd8\x19\xc7\x97\x18\xca\xa1\x31\xfb\xfe\x30\x04\xa8\x4f\xf0\x1d\x0f\xca\x47\xd9\xb0\x0d\xa2\x82\xc2\x92\x5e\x20\xec\x4c\xed\x30\x0b\x00\x40\xb5\xa8\xd5\x83\x63\xab\xe4\x23\xbe\x16\x22\x0d\x8b\x0d\xbe\xd0\x66\x71\xae\xfe\x23\xae\x46\x9d\xd2\x93\x98\x05\xdd\x53\x41\x59\x44\xab\x49\xb2\x99\x6a\xe7\xc8\x33\xc4\x94\x50\x49\x88\xc2\x55\x14\xc6\x34\xac\x84\x8a\xe2\x8c\x20\x32\x51\x8a\x30\x49\x36\x10\x69\x4a\xb1\x08\x4b\x1a\xcd\x86\x6c\xef\xf8\x0c\x92\x0f\x39\xfc\x7a\xf9\x55\xa3\x71\x2d\x1d\x57\x96\xb7\x6f\xd5\xc6\x64\xcf\x54\xaf\x43\xb3\x7b\xc5\xb3\xa2\x0b\xf6\x35\x40\x6c\xad\xb1\x67\xdb\xf1\xed\xbc\xb4\x9e\xdb\x4a\x7a\x78\xb5\x67\xe7\xe5\xbe\xc7\x51\xf9\xee\xfc\xc4\x1f\xa3\x79\x14\x6e\x95\x56\xae\xbb\x46\xd9\x58\x96\xef\x8c\xfc\xff\x72\xb5\x39\xce\xe6\x63\xff\xa1\x6f\x3f\xd4\xbf\xce\xc4\xb7\x35\x61\x36\x3d\xa5\xc5\xd3\x5f\xcf\x83\xf9\x32\x78\xba\x50\x91\xc5\xf4\xe3\xb2\xf0\xe1\x42\xab\xc9\x36\xf2\x6c\x52\x98\x3d\x17\xe6\xf8\xfe\x99\x0a\x9a\x2e\x4e\xac\xce\x2c\xcb\x20\x88\xf2\x34\x15\xe5\x32\xf8\x3b\x00\x89\xa3\x6c\x0d\x15\x04\x00\x00")
+
+func _1528395672_patch_jobsUpSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__1528395672_patch_jobsUpSql,
+		"1528395672_patch_jobs.up.sql",
+	)
+}
+
+func _1528395672_patch_jobsUpSql() (*asset, error) {
+	bytes, err := _1528395672_patch_jobsUpSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "1528395672_patch_jobs.up.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0xaf, 0xe9, 0x30, 0x7a, 0x7e, 0xf6, 0x69, 0x1c, 0x27, 0x6f, 0x4f, 0x99, 0x82, 0xe8, 0xcd, 0x7c, 0x8d, 0xed, 0x4e, 0xf4, 0xf2, 0x46, 0x2d, 0xcd, 0x78, 0x78, 0x2b, 0xa6, 0xc3, 0x19, 0xd8, 0x3e}}
+	return a, nil
+}
+
+var __1528395673_campaign_rolloutDownSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x9c\xcb\xb1\x0e\x82\x30\x10\x00\xd0\xfd\xbe\xe2\xfe\xa3\x13\x60\x35\x4d\x5a\x6a\xa0\x26\x6e\xcd\x09\x17\xc4\x54\x4a\xbc\x76\xf0\xef\xdd\x4d\x5c\xfc\x80\xd7\xea\x93\xe9\x15\x40\x63\x83\x1e\x30\x34\xad\xd5\x38\xdd\x69\x5b\x58\xb8\xc4\x47\xbe\x09\x1e\x06\x7f\xc6\xce\xdb\x8b\xeb\xd1\x1c\x51\x5f\xcd\x18\x46\x7c\x71\x62\x12\x9e\x23\x95\x6f\x4e\xcf\x9d\xd6\x65\xfb\x29\x73\x4a\xb9\x96\xb8\x53\x15\x9e\xd5\x7f\x36\xa7\x75\x7a\x2b\x80\xce\x3b\x67\x82\x82\xcf\x00\x9a\xe5\x53\x99\xc8\x00\x00\x00")
+
+func _1528395673_campaign_rolloutDownSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__1528395673_campaign_rolloutDownSql,
+		"1528395673_campaign_rollout.down.sql",
+	)
+}
+
+func _1528395673_campaign_rolloutDownSql() (*asset, error) {
+	bytes, err := _1528395673_campaign_rolloutDownSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "1528395673_campaign_rollout.down.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0xec, 0x1a, 0x9, 0xf5, 0xa, 0x9b, 0xe8, 0xd3, 0x79, 0xac, 0x43, 0x69, 0x73, 0xa4, 0xe8, 0x9e, 0xd6, 0xa1, 0x58, 0xd, 0x45, 0xa8, 0x63, 0xdd, 0x4f, 0x3, 0x9, 0xbf, 0x52, 0xad, 0x7d, 0x6d}}
+	return a, nil
+}
+
+var __1528395673_campaign_rolloutUpSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x94\x90\x41\x6e\x83\x30\x10\x45\xf7\x9c\xe2\x5f\x20\x27\x60\x45\x02\x6d\x23\x19\x90\x2a\x50\x97\x68\x0c\x93\xd8\xc8\x78\x10\x36\x4d\xd3\xd3\x57\x69\x9a\x56\xe9\x2e\xcb\x91\x66\xfe\xbc\xf7\xb7\xc5\xf3\xbe\x4a\x93\x64\xb3\xc1\x8b\x9c\x10\x0d\xa3\x37\xe4\x8f\x1c\x38\x06\xc8\x01\x84\x9e\xa6\x99\xec\xd1\x83\x16\xc6\xbc\x6a\x67\x83\xe1\x01\xf2\xce\x0b\xa2\x9d\x38\xc9\x54\x53\xbc\xa2\xc9\xb6\xaa\xf8\x5d\x0e\xc8\xf2\x1c\xbb\x5a\xb5\x65\x85\x45\x9c\x93\x35\x76\xb3\x38\xdb\x9f\x31\x06\xf1\x3a\x7d\xe0\x8c\xd6\xc0\x03\xb4\x88\x63\xf2\xa8\xea\x06\x55\xab\x14\xf2\xe2\x29\x6b\x55\x83\x03\xb9\xc0\x57\x87\x37\xc3\x1e\xf4\xa7\x80\x51\xf4\x3f\x8b\x93\x8d\x06\x74\x0b\xc7\x0f\xd3\x44\x67\x68\x06\x7f\x70\xbf\x46\x1e\xee\xe1\x6e\x69\xdd\x28\xfa\x9e\x90\x1d\x53\xe0\xa1\xa3\xf8\x5d\x45\x88\x34\xcd\xd7\x0f\x97\x11\x9f\xe2\x2f\x60\xbb\xba\x2c\xf7\x4d\x9a\x7c\x0d\x00\x8d\x97\xc3\x62\x6d\x01\x00\x00")
+
+func _1528395673_campaign_rolloutUpSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__1528395673_campaign_rolloutUpSql,
+		"1528395673_campaign_rollout.up.sql",
+	)
+}
+
+func _1528395673_campaign_rolloutUpSql() (*asset, error) {
+	bytes, err := _1528395673_campaign_rolloutUpSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "1528395673_campaign_rollout.up.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0x2b, 0x6, 0x3b, 0x52, 0x40, 0xbf, 0xcc, 0xb3, 0xb4, 0xed, 0x10, 0x11, 0x1b, 0x5d, 0xdf, 0xe3, 0x62, 0xd0, 0xfa, 0x4, 0x6b, 0x8b, 0xb7, 0x62, 0xc6, 0x38, 0x95, 0xdc, 0xbc, 0x6c, 0x34, 0xb7}}
+	return a, nil
+}
+
+var __1528395674_campaign_auto_mergeDownSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x00\x50\x00\xaf\xff\x42\x45\x47\x49\x4e\x3b\x0a\x0a\x41\x4c\x54\x45\x52\x20\x54\x41\x42\x4c\x45\x20\x63\x61\x6d\x70\x61\x69\x67\x6e\x73\x20\x44\x52\x4f\x50\x20\x43\x4f\x4c\x55\x4d\x4e\x20\x49\x46\x20\x45\x58\x49\x53\x54\x53\x20\x61\x75\x74\x6f\x5f\x6d\x65\x72\x67\x65\x5f\x70\x6f\x6c\x69\x63\x79\x3b\x0a\x0a\x43\x4f\x4d\x4d\x49\x54\x3b\x0a\x03\x00\x60\x1b\x00\x77\x50\x00\x00\x00")
+
+func _1528395674_campaign_auto_mergeDownSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__1528395674_campaign_auto_mergeDownSql,
+		"1528395674_campaign_auto_merge.down.sql",
+	)
+}
+
+func _1528395674_campaign_auto_mergeDownSql() (*asset, error) {
+	bytes, err := _1528395674_campaign_auto_mergeDownSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "1528395674_campaign_auto_merge.down.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0x17, 0xbc, 0xd9, 0xb4, 0x7b, 0xf4, 0x52, 0x46, 0x78, 0x6, 0x49, 0xc1, 0x56, 0x1c, 0x2f, 0x3d, 0xfa, 0x8d, 0x1b, 0x52, 0xae, 0x8e, 0xa, 0xad, 0x94, 0x56, 0xa7, 0x1a, 0x28, 0x4, 0x4, 0xcf}}
+	return a, nil
+}
+
+var __1528395674_campaign_auto_mergeUpSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x3c\xcb\x39\xaa\xc3\x30\x10\x06\xe0\x7e\x4e\xf1\x5f\xc0\x27\x70\xe5\x8d\x87\xc1\x0b\x3c\x1c\x52\x9a\x89\x32\x91\x14\xb4\x61\x29\x85\x6f\x1f\x70\x91\xfe\xfb\xda\xe1\x6f\x5c\x6a\xa2\xaa\xc2\xdd\x48\x40\x31\x82\x98\x24\x40\x19\x0e\x5a\xb2\x94\x8c\xf8\x02\x43\xb1\x4f\x6c\x75\x00\x1f\x02\x2f\x87\x96\x27\xf8\x53\xa2\xe7\x62\x15\x3b\x77\x52\x33\x6d\xc3\x3f\xb6\xa6\x9d\x86\x9f\xce\x68\xfa\x1e\xdd\x3a\xdd\xe6\xe5\xe2\xfb\x55\xf7\x14\x9d\x55\x27\xde\x39\x86\x47\x4d\xd4\xad\xf3\x3c\x6e\x35\x7d\x07\x00\xd1\x99\x97\xe7\x8e\x00\x00\x00")
+
+func _1528395674_campaign_auto_mergeUpSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__1528395674_campaign_auto_mergeUpSql,
+		"1528395674_campaign_auto_merge.up.sql",
+	)
+}
+
+func _1528395674_campaign_auto_mergeUpSql() (*asset, error) {
+	bytes, err := _1528395674_campaign_auto_mergeUpSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "1528395674_campaign_auto_merge.up.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0x34, 0x19, 0x8b, 0x35, 0x50, 0x18, 0x32, 0x88, 0x15, 0xc, 0x3f, 0x65, 0xbb, 0x29, 0x2f, 0x24, 0x97, 0x64, 0xc8, 0xb3, 0x31, 0x61, 0x1f, 0xdb, 0x40, 0xbe, 0x86, 0xc0, 0x86, 0x54, 0x16, 0xd1}}
+	return a, nil
+}
+
+var __1528395675_search_exportsUpSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\xa4\x53\x41\x6f\x9b\x4c\x10\xbd\xf3\x2b\xe6\x16\x23\x39\xd1\x77\xf7\x89\x98\xfd\x2a\xab\x18\xb7\x04\x4b\xcd\x09\x6d\x60\x6c\x56\xb5\x77\xf1\xec\xd0\xd8\xfd\xf5\xd5\x80\x71\x1b\x53\x25\x96\x7a\xe4\xcd\x9b\xf7\x86\xd5\x7b\x8f\xea\xd3\x22\x9d\x05\xc1\x3c\x53\x51\xae\x20\x8f\x1e\x13\x05\x1e\x35\x95\x75\x81\xc7\xc6\x11\x7b\x98\x04\x00\xa6\x82\x17\xb3\xf5\x48\x46\xef\xe0\x4b\xb6\x58\x46\xd9\x33\x7c\x56\xcf\xd3\x00\xa0\xf5\x48\x85\xa9\xc0\x58\xc6\x2d\x12\xa4\xab\x1c\xd2\x75\x92\x40\xa6\xfe\x57\x99\x4a\xe7\xea\xa9\xe3\xf8\x89\xa9\x42\x58\xa5\x10\xab\x44\xe5\x0a\xe6\xd1\xd3\x3c\x8a\x15\xc4\x42\xcb\xc4\x59\xd4\x0e\x2d\xd2\x09\x18\x8f\x7c\x11\x12\xf8\x07\x92\x37\xce\x8e\x07\x8d\x66\x46\xb2\x05\x9f\x1a\xec\xa6\x02\x6e\x1c\xed\x35\x8f\xc9\x9e\x35\xe3\x5b\x58\xec\xa3\x75\x92\xc3\xdd\xd7\xb5\x5a\xab\xf8\x4e\x78\x48\xe4\xe8\xa2\x76\x7f\x0f\x79\x8d\xd0\xe8\xad\xb1\x9a\xe5\x8a\xb2\x25\xef\x08\xdc\x06\xb8\x46\xb0\x62\xd3\xe8\x2d\x0a\x40\xe8\xdb\x1d\x7b\x60\x07\xfd\x03\x3e\x04\x30\xf0\x07\xc1\xb2\x6e\xed\xf7\xa2\x74\xad\xe5\xf1\xab\x0d\xf7\xfc\x27\xcc\x5e\xed\x26\x6a\x49\xa8\x19\xab\x42\xfe\xdb\xec\xd1\xb3\xde\x37\xf0\x6a\xb8\xee\x3e\xe1\xa7\xb3\x38\xde\xb4\xee\x75\x12\x8a\x51\xdb\x54\xff\xb0\xed\x59\xd3\x07\xdb\x62\xb2\x31\xd6\xf8\xfa\x63\x1e\x1e\x1b\x43\xe8\x6f\x3a\x26\x08\x7f\xc7\x77\x91\xc6\xea\xdb\x55\x7c\x8b\x21\x9e\xab\xf4\x6a\x32\x39\x4f\xc2\xd9\xbb\xfb\x7d\x66\xc6\xdb\x1d\x2e\xe6\xe7\x78\xf4\x38\x56\xb0\x31\x3b\x9c\x82\xb1\x12\x18\xf4\x92\x89\x5e\x71\x88\xc6\xc3\x3b\x6d\x2b\xba\x68\xf4\x9d\x3b\x23\x7d\xf5\x8c\xe5\xbf\x16\xeb\xea\xa8\x5b\x1a\xe6\xf1\x30\x8a\x92\xe0\x95\x66\x0d\x2f\x27\x46\xfd\x06\xfe\xa3\xed\x30\xb9\xdc\x34\x05\x8f\x87\xb0\x7f\xfc\xd5\x72\xb9\xc8\x67\xc1\xaf\x01\x00\x53\xf8\x10\x95\x4c\x04\x00\x00")
+
+func _1528395675_search_exportsUpSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__1528395675_search_exportsUpSql,
+		"1528395675_search_exports.up.sql",
+	)
+}
+
+func _1528395675_search_exportsUpSql() (*asset, error) {
+	bytes, err := _1528395675_search_exportsUpSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "1528395675_search_exports.up.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0x49, 0xee, 0xb9, 0xab, 0x96, 0x55, 0x75, 0x5b, 0x10, 0x86, 0x4c, 0x7a, 0x68, 0xef, 0xf4, 0x3a, 0xb9, 0x48, 0x47, 0x35, 0x83, 0xc9, 0x79, 0x72, 0xcf, 0x65, 0x9, 0xb3, 0xe, 0x98, 0xfb, 0x7c}}
+	return a, nil
+}
+
+var __1528395675_search_exportsDownSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x00\x61\x00\x9e\xff\x42\x45\x47\x49\x4e\x3b\x0a\x0a\x44\x52\x4f\x50\x20\x54\x41\x42\x4c\x45\x20\x49\x46\x20\x45\x58\x49\x53\x54\x53\x20\x73\x65\x61\x72\x63\x68\x5f\x65\x78\x70\x6f\x72\x74\x5f\x63\x68\x75\x6e\x6b\x73\x3b\x0a\x44\x52\x4f\x50\x20\x54\x41\x42\x4c\x45\x20\x49\x46\x20\x45\x58\x49\x53\x54\x53\x20\x73\x65\x61\x72\x63\x68\x5f\x65\x78\x70\x6f\x72\x74\x73\x3b\x0a\x0a\x43\x4f\x4d\x4d\x49\x54\x3b\x0a\x03\x00\xa9\x94\x8d\x65\x61\x00\x00\x00")
+
+func _1528395675_search_exportsDownSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__1528395675_search_exportsDownSql,
+		"1528395675_search_exports.down.sql",
+	)
+}
+
+func _1528395675_search_exportsDownSql() (*asset, error) {
+	bytes, err := _1528395675_search_exportsDownSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "1528395675_search_exports.down.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0x1a, 0x95, 0xf0, 0xc0, 0x15, 0x6e, 0x25, 0x99, 0xc, 0x7b, 0xcb, 0x21, 0x7d, 0x59, 0xb2, 0xb, 0x84, 0xe8, 0x8c, 0x29, 0xee, 0xce, 0xec, 0xf, 0x61, 0xfd, 0xb5, 0x69, 0xa6, 0xa9, 0xa2, 0x6b}}
+	return a, nil
+}
+
+var __1528395676_lsif_indexesDownSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x00\x5a\x00\xa5\xff\x42\x45\x47\x49\x4e\x3b\x0a\x0a\x44\x52\x4f\x50\x20\x54\x41\x42\x4c\x45\x20\x49\x46\x20\x45\x58\x49\x53\x54\x53\x20\x6c\x73\x69\x66\x5f\x69\x6e\x64\x65\x78\x65\x73\x3b\x0a\x44\x52\x4f\x50\x20\x54\x59\x50\x45\x20\x49\x46\x20\x45\x58\x49\x53\x54\x53\x20\x6c\x73\x69\x66\x5f\x69\x6e\x64\x65\x78\x5f\x73\x74\x61\x74\x65\x3b\x0a\x0a\x43\x4f\x4d\x4d\x49\x54\x3b\x0a\x03\x00\x92\xd2\xe8\x3c\x5a\x00\x00\x00")
+
+func _1528395676_lsif_indexesDownSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__1528395676_lsif_indexesDownSql,
+		"1528395676_lsif_indexes.down.sql",
+	)
+}
+
+func _1528395676_lsif_indexesDownSql() (*asset, error) {
+	bytes, err := _1528395676_lsif_indexesDownSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "1528395676_lsif_indexes.down.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0x89, 0x7, 0xb1, 0xd1, 0xe0, 0x3, 0xa7, 0x36, 0x3f, 0x10, 0xe0, 0x83, 0x25, 0x1f, 0xcb, 0x8e, 0x3a, 0x52, 0xbe, 0x4d, 0x60, 0x88, 0xcc, 0x95, 0x4d, 0xc2, 0x72, 0xd0, 0x60, 0xe8, 0xfe, 0xb}}
+	return a, nil
+}
+
+var __1528395676_lsif_indexesUpSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x8c\x92\x4f\x8b\xdb\x30\x10\xc5\xef\xfe\x14\x73\x28\xd8\x86\x2c\xe4\xd0\x4b\xc9\xc9\xeb\xcc\xb6\x66\x1d\x65\xeb\xd8\xd0\x50\x5a\x21\xe2\xc9\x46\x60\x59\xae\x24\x77\xff\x94\xf6\xb3\x97\xb5\xb2\xc1\xde\x4d\xcb\x1e\xa5\x79\xfa\xbd\x19\xbd\xb9\xc4\x8f\x19\x5b\x04\x41\x5a\x60\x52\x22\x94\xdb\x1b\x84\xc6\xca\x3d\x97\x6d\x4d\xf7\xdc\x3a\xe1\x08\x92\x0d\x20\xab\x56\x10\x85\x3f\x7a\xea\xa9\x0e\x67\x10\x76\x46\xef\xc8\x5a\xd9\xde\x3e\x9d\x76\x5a\x75\x0d\x39\x5f\x22\x63\xb4\xa1\x3a\x8c\x47\xdc\xe4\x32\x1f\x83\xc9\x42\x14\x00\x00\xc8\x1a\x2c\x19\x29\x1a\xb8\x29\xb2\x55\x52\x6c\xe1\x1a\xb7\xb3\xa1\xb4\xd3\x4a\x49\x07\x8e\xee\x1d\xb0\x75\x09\xac\xca\x73\x5f\x31\xd4\x69\x2b\x9d\x36\x0f\x5c\xd6\x20\x5b\x47\xb7\x64\x5e\x68\x7c\xe7\xaf\x46\x79\x16\xc1\x12\xaf\x92\x2a\x2f\xe1\x34\xd2\xf0\x6a\x2f\x64\xd3\x1b\xe2\xb6\x57\x4a\x98\x87\xc1\xdc\x7b\xb6\xbd\xe2\xc7\xaa\x7d\x65\x79\xa2\xcd\xbd\xd8\x33\xb9\x70\xe0\xa4\x22\xeb\x84\xea\xe0\x4e\xba\xc3\x70\x84\x47\xdd\x9e\x69\xa4\xd5\x77\x51\xec\x9f\x1f\xff\x96\x8b\xbd\x23\xf3\x4f\xc4\x69\x4e\xe3\xfe\xef\xe5\x85\x7b\xd9\x4a\x7b\x78\x8b\xb2\xef\x1a\x2d\xea\xf1\xd7\x16\x78\x85\x05\xb2\x14\x37\x3e\x43\xaf\xb0\x91\xac\x63\x58\x33\x58\x62\x8e\x25\xc2\x06\xc7\x01\xa4\x6b\xb6\x29\x8b\x24\x63\xe5\x24\x77\xee\x63\xe5\x3f\x45\x23\x6b\xbe\x3b\x08\x63\x21\xfd\x84\xe9\x35\x44\xc7\xc0\xff\x40\xf8\xfd\xab\xb8\x78\x9c\x5f\x7c\xf8\xf6\xeb\xfd\xfc\xf7\xbb\x30\x0e\x46\xab\x54\xb1\xec\x73\x85\x90\xb1\x25\x7e\x99\x92\x27\x6b\x71\xf4\x79\x6a\x6f\x2c\x8a\x26\xa2\x19\x78\x55\xbc\x78\xa6\x9f\xc1\xfa\x4d\x7a\xc9\x19\x6e\x87\xb6\xd6\xab\x55\x56\x2e\x82\xbf\x03\x00\x0d\x2b\xc3\x1b\x4a\x03\x00\x00")
+
+func _1528395676_lsif_indexesUpSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__1528395676_lsif_indexesUpSql,
+		"1528395676_lsif_indexes.up.sql",
+	)
+}
+
+func _1528395676_lsif_indexesUpSql() (*asset, error) {
+	bytes, err := _1528395676_lsif_indexesUpSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "1528395676_lsif_indexes.up.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0x27, 0xef, 0xea, 0x89, 0xb0, 0xd, 0xeb, 0x4f, 0x73, 0x9c, 0x7f, 0xc1, 0xb1, 0xed, 0x41, 0x30, 0x56, 0x12, 0x33, 0xd7, 0x2b, 0x2d, 0x49, 0x77, 0x8d, 0x65, 0x1b, 0xab, 0x93, 0xab, 0x7d, 0x24}}
+	return a, nil
+}
+
+var __1528395677_lsif_commit_graphsDownSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x00\x65\x00\x9a\xff\x42\x45\x47\x49\x4e\x3b\x0a\x0a\x44\x52\x4f\x50\x20\x54\x41\x42\x4c\x45\x20\x49\x46\x20\x45\x58\x49\x53\x54\x53\x20\x6c\x73\x69\x66\x5f\x6e\x65\x61\x72\x65\x73\x74\x5f\x75\x70\x6c\x6f\x61\x64\x73\x3b\x0a\x44\x52\x4f\x50\x20\x54\x41\x42\x4c\x45\x20\x49\x46\x20\x45\x58\x49\x53\x54\x53\x20\x6c\x73\x69\x66\x5f\x63\x6f\x6d\x6d\x69\x74\x5f\x67\x72\x61\x70\x68\x73\x3b\x0a\x0a\x43\x4f\x4d\x4d\x49\x54\x3b\x0a\x03\x00\x89\xe1\x40\x43\x65\x00\x00\x00")
+
+func _1528395677_lsif_commit_graphsDownSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__1528395677_lsif_commit_graphsDownSql,
+		"1528395677_lsif_commit_graphs.down.sql",
+	)
+}
+
+func _1528395677_lsif_commit_graphsDownSql() (*asset, error) {
+	bytes, err := _1528395677_lsif_commit_graphsDownSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "1528395677_lsif_commit_graphs.down.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0xe1, 0xfb, 0xe2, 0xbf, 0xcb, 0x77, 0x2, 0xb6, 0xf8, 0x91, 0x9, 0xf8, 0x47, 0x72, 0x6, 0xd7, 0x9c, 0x2b, 0x79, 0x31, 0x2, 0xbb, 0xd0, 0xea, 0x6c, 0x2d, 0x8f, 0xa, 0x5c, 0xea, 0xd8, 0xb9}}
+	return a, nil
+}
+
+var __1528395677_lsif_commit_graphsUpSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x8c\x91\x41\x6e\xc2\x30\x10\x45\xf7\x39\xc5\x2c\x13\x89\x1b\xb0\x0a\xc9\x50\x45\x0d\x4e\x15\x82\x54\x56\x96\x85\x0d\x8c\x44\x6c\xcb\x9e\x8a\xb6\xa7\xaf\x04\x29\x6d\xd4\xd0\x76\x69\xf9\xcd\xff\xf3\xec\x05\x3e\x54\x62\x9e\x24\x45\x8b\x79\x87\xd0\xe5\x8b\x1a\xe1\x14\x69\x2f\x77\xae\xef\x89\xe5\x21\x28\x7f\x8c\x90\x26\x00\x00\xc1\x78\x17\x89\x5d\x78\x93\xa4\x81\x2c\x9b\x83\x09\xf0\xd4\x56\xab\xbc\xdd\xc2\x23\x6e\x67\x17\x8c\xc9\x0f\xe3\xc0\xe6\x95\x41\x34\x1d\x88\x4d\x5d\x5f\x6f\x5f\xfc\xc9\x29\x1d\xe5\x9e\xec\xc1\x04\x1f\xc8\xde\xc1\xb4\x62\xa3\xa5\x62\x60\xea\x4d\x64\xd5\x7b\x38\x13\x1f\x2f\x47\x78\x77\xd6\xdc\x26\xa0\xc4\x65\xbe\xa9\x3b\xb0\xee\x9c\x66\x49\x36\x29\x64\x8d\x0a\x26\xb2\x1c\xfa\x7f\x55\x1a\xaf\xf2\x97\xcb\xd4\x24\xb4\xb8\xc4\x16\x45\x81\xeb\xeb\x7b\x0e\xb5\x29\xe9\x0c\x1a\x01\x25\xd6\xd8\x21\x14\xf9\xba\xc8\x4b\xbc\xa6\x69\x8a\xac\xec\xce\xfc\x08\xfb\x6e\x54\x89\x12\x9f\x27\x8d\xe4\xc8\xe5\xf3\x07\x1a\x31\x09\xa7\x23\x78\x36\x38\x66\xf3\x7f\xd4\x7c\x49\xdf\xcb\xbe\x11\x97\xbd\x9b\xd5\xaa\xea\xe6\xc9\xc7\x00\xa1\xe3\xa3\xc4\x6b\x02\x00\x00")
+
+func _1528395677_lsif_commit_graphsUpSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__1528395677_lsif_commit_graphsUpSql,
+		"1528395677_lsif_commit_graphs.up.sql",
+	)
+}
+
+func _1528395677_lsif_commit_graphsUpSql() (*asset, error) {
+	bytes, err := _1528395677_lsif_commit_graphsUpSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "1528395677_lsif_commit_graphs.up.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0xa7, 0x3f, 0x96, 0xe2, 0x69, 0x86, 0x7a, 0xb9, 0x79, 0xe5, 0x7f, 0x21, 0x98, 0x22, 0x4, 0x61, 0xa3, 0x16, 0x3f, 0xcc, 0xa6, 0xf7, 0xe7, 0x5e, 0xd, 0xda, 0xd6, 0xbf, 0x17, 0x63, 0xd3, 0x1f}}
+	return a, nil
+}
+
+var __1528395678_access_token_restrictionsDownSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x94\xcb\x51\x0a\xc3\x20\x0c\x00\xd0\xff\x9c\x22\xf7\xf0\xab\xed\xdc\x10\xb4\x8e\x36\x83\xfd\x89\x94\x7c\x94\x81\x4a\x92\x8f\xed\xf6\x3b\x43\x0f\xf0\x66\xff\x08\xab\x03\x98\x22\xf9\x0d\x69\x9a\xa3\xc7\x7a\x1c\xac\x5a\xac\x7f\xb8\x29\xde\xb6\xfc\xc4\x25\xc7\x57\x5a\x31\xdc\xd1\xbf\xc3\x4e\x3b\xf2\x77\x9c\xc2\x5a\xaa\xb9\xcb\x56\x78\x74\x3d\xad\xcb\xaf\x8c\x6a\xc6\xd2\xd4\x01\x2c\x39\xa5\x40\x0e\xfe\x03\x00\x28\x2c\x5b\x82\x92\x00\x00\x00")
+
+func _1528395678_access_token_restrictionsDownSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__1528395678_access_token_restrictionsDownSql,
+		"1528395678_access_token_restrictions.down.sql",
+	)
+}
+
+func _1528395678_access_token_restrictionsDownSql() (*asset, error) {
+	bytes, err := _1528395678_access_token_restrictionsDownSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "1528395678_access_token_restrictions.down.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0xed, 0xe3, 0xa7, 0xd, 0x85, 0x20, 0x57, 0x68, 0x98, 0x99, 0xb7, 0xae, 0x9, 0x63, 0xe9, 0x35, 0x50, 0x73, 0x69, 0x6a, 0xa8, 0x10, 0xd4, 0xbd, 0xb, 0xa0, 0xc1, 0x22, 0xbf, 0xa, 0xfa, 0xe1}}
+	return a, nil
+}
+
+var __1528395678_access_token_restrictionsUpSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x8c\xcc\x41\xaa\xc2\x30\x10\x06\xe0\x7d\x4e\xf1\xef\x7a\x88\xac\xd2\x36\xef\x51\x48\x53\x90\x74\x25\x52\x42\x19\x30\x48\x9b\x90\x19\xb0\x2a\xde\x5d\xf0\x04\x2e\xbf\xcd\xd7\xda\xff\xc1\x6b\xa5\x8c\x0b\xf6\x84\x60\x5a\x67\x11\xd7\x95\x98\x17\xc9\x37\xda\x19\xa6\xef\xd1\x4d\x6e\x1e\x3d\xe8\x28\xa9\x12\x2f\x51\x20\x69\x23\x96\xb8\x15\xdc\x93\x5c\xbf\xc4\x33\xef\xa4\x7f\x9b\x2a\x95\xcc\x49\x72\x7d\x2c\x25\x8a\x50\xdd\x19\x42\x87\x9c\x2f\xf0\x53\x80\x9f\x9d\x43\x6f\xff\xcc\xec\x02\x9a\xd7\xbb\xd1\x4a\x75\xd3\x38\x0e\x41\xab\xcf\x00\x20\x58\xde\xe4\xb2\x00\x00\x00")
+
+func _1528395678_access_token_restrictionsUpSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__1528395678_access_token_restrictionsUpSql,
+		"1528395678_access_token_restrictions.up.sql",
+	)
+}
+
+func _1528395678_access_token_restrictionsUpSql() (*asset, error) {
+	bytes, err := _1528395678_access_token_restrictionsUpSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "1528395678_access_token_restrictions.up.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0x32, 0x88, 0xbb, 0xe2, 0x3c, 0xf1, 0x2a, 0xb6, 0x19, 0xd, 0x28, 0x92, 0xf8, 0x7d, 0xfc, 0x69, 0x13, 0xa5, 0xe2, 0x0, 0x77, 0x72, 0x59, 0x2e, 0xc7, 0x16, 0x79, 0x5, 0x63, 0x4b, 0x2f, 0xee}}
+	return a, nil
+}
+
+var __1528395679_config_authorDownSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x00\x5c\x00\xa3\xff\x42\x45\x47\x49\x4e\x3b\x0a\x0a\x41\x4c\x54\x45\x52\x20\x54\x41\x42\x4c\x45\x20\x63\x72\x69\x74\x69\x63\x61\x6c\x5f\x61\x6e\x64\x5f\x73\x69\x74\x65\x5f\x63\x6f\x6e\x66\x69\x67\x20\x44\x52\x4f\x50\x20\x43\x4f\x4c\x55\x4d\x4e\x20\x49\x46\x20\x45\x58\x49\x53\x54\x53\x20\x61\x75\x74\x68\x6f\x72\x5f\x75\x73\x65\x72\x5f\x69\x64\x3b\x0a\x0a\x43\x4f\x4d\x4d\x49\x54\x3b\x0a\x03\x00\x6c\x4d\xa7\xb5\x5c\x00\x00\x00")
+
+func _1528395679_config_authorDownSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__1528395679_config_authorDownSql,
+		"1528395679_config_author.down.sql",
+	)
+}
+
+func _1528395679_config_authorDownSql() (*asset, error) {
+	bytes, err := _1528395679_config_authorDownSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "1528395679_config_author.down.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0x2a, 0x17, 0x7b, 0x60, 0xa7, 0xc0, 0xf4, 0xfc, 0xe7, 0xf0, 0x71, 0xf7, 0xc3, 0x44, 0xa9, 0xf9, 0x90, 0x58, 0x88, 0x3e, 0xa9, 0xb5, 0x83, 0x4e, 0x63, 0xbe, 0x6a, 0x89, 0x45, 0x45, 0x8b, 0x1e}}
+	return a, nil
+}
+
+var __1528395679_config_authorUpSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x00\x81\x00\x7e\xff\x42\x45\x47\x49\x4e\x3b\x0a\x0a\x41\x4c\x54\x45\x52\x20\x54\x41\x42\x4c\x45\x20\x63\x72\x69\x74\x69\x63\x61\x6c\x5f\x61\x6e\x64\x5f\x73\x69\x74\x65\x5f\x63\x6f\x6e\x66\x69\x67\x20\x41\x44\x44\x20\x43\x4f\x4c\x55\x4d\x4e\x20\x61\x75\x74\x68\x6f\x72\x5f\x75\x73\x65\x72\x5f\x69\x64\x20\x69\x6e\x74\x65\x67\x65\x72\x20\x52\x45\x46\x45\x52\x45\x4e\x43\x45\x53\x20\x75\x73\x65\x72\x73\x28\x69\x64\x29\x20\x4f\x4e\x20\x44\x45\x4c\x45\x54\x45\x20\x53\x45\x54\x20\x4e\x55\x4c\x4c\x3b\x0a\x0a\x43\x4f\x4d\x4d\x49\x54\x3b\x0a\x03\x00\xd7\x5a\x9b\xaa\x81\x00\x00\x00")
+
+func _1528395679_config_authorUpSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__1528395679_config_authorUpSql,
+		"1528395679_config_author.up.sql",
+	)
+}
+
+func _1528395679_config_authorUpSql() (*asset, error) {
+	bytes, err := _1528395679_config_authorUpSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "1528395679_config_author.up.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0x93, 0xa3, 0xc7, 0x68, 0x49, 0x1a, 0xff, 0x79, 0xc5, 0xfe, 0x4d, 0x28, 0xd5, 0x43, 0x20, 0x34, 0xe1, 0x68, 0xed, 0x50, 0x11, 0x86, 0xea, 0xcb, 0x70, 0x2c, 0x63, 0x4c, 0xf6, 0x54, 0xee, 0xe2}}
+	return a, nil
+}
+
+var __1528395680_audit_logDownSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x00\x62\x00\x9d\xff\x42\x45\x47\x49\x4e\x3b\x0a\x0a\x44\x52\x4f\x50\x20\x54\x41\x42\x4c\x45\x20\x49\x46\x20\x45\x58\x49\x53\x54\x53\x20\x61\x75\x64\x69\x74\x5f\x6c\x6f\x67\x3b\x0a\x44\x52\x4f\x50\x20\x46\x55\x4e\x43\x54\x49\x4f\x4e\x20\x49\x46\x20\x45\x58\x49\x53\x54\x53\x20\x61\x75\x64\x69\x74\x5f\x6c\x6f\x67\x5f\x61\x70\x70\x65\x6e\x64\x5f\x6f\x6e\x6c\x79\x28\x29\x3b\x0a\x0a\x43\x4f\x4d\x4d\x49\x54\x3b\x0a\x03\x00\xdf\x2f\xe8\xfc\x62\x00\x00\x00")
+
+func _1528395680_audit_logDownSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__1528395680_audit_logDownSql,
+		"1528395680_audit_log.down.sql",
+	)
+}
+
+func _1528395680_audit_logDownSql() (*asset, error) {
+	bytes, err := _1528395680_audit_logDownSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "1528395680_audit_log.down.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0x59, 0x81, 0xea, 0x70, 0x10, 0x5c, 0xc8, 0xeb, 0xe5, 0x63, 0x79, 0xcb, 0x1c, 0xc6, 0x7d, 0x2, 0xe7, 0x7b, 0xb6, 0x13, 0x55, 0xd8, 0xba, 0x52, 0x8, 0x94, 0x44, 0x8b, 0x44, 0x56, 0xc, 0xf0}}
+	return a, nil
+}
+
+var __1528395680_audit_logUpSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x7c\x54\xc1\x6e\xeb\x36\x10\xbc\xeb\x2b\xe6\x10\xc0\x0e\x10\xe7\x07\x7c\x52\x6c\xc6\x35\xea\x48\x81\x22\xa1\x79\x27\x81\x11\xd7\x12\x5b\x99\x54\xc9\xb5\xfd\xd4\xaf\x2f\x28\xbf\xd8\x56\x9d\xf4\x26\x72\x76\x67\x76\x76\x08\x3d\x89\xd5\x3a\x99\x47\xd1\x6c\x86\xbc\x21\xc8\xbd\xd2\x8c\xd6\xd6\xb0\x5b\x78\xaa\xf6\x4e\x73\x3f\x73\xd4\xd2\x41\x1a\x86\xac\x58\x5b\xe3\x07\x50\x33\x41\xaa\x9d\x36\xfe\x11\xc2\xb0\xd3\xe4\x21\x1d\xc1\xd0\x81\x1c\xf6\x9d\x92\x4c\x0a\xd6\x41\x51\x4b\x4c\xea\x31\x68\x08\x59\x35\x20\xc3\xae\x9f\x78\x34\xd2\x37\xa8\xec\x81\x9c\x87\x66\x8f\xca\x1a\x26\xc3\x1e\xd2\x28\x70\x43\xa7\x02\xbb\x1d\xbe\x87\x26\x7c\xd0\xd6\x3a\x82\xe6\x07\x78\x0b\x6e\x24\xa3\x6a\xa4\xa9\xc9\x83\x6d\x10\xe8\xa4\xe7\x41\x20\x8c\x53\x49\x83\x0f\x82\x22\xa6\x6a\x98\x60\x91\x89\x38\x17\xc8\xe3\xa7\x8d\x38\x79\x2d\x83\xd7\x69\x04\x68\x85\x0f\x5d\x7b\x72\x5a\xb6\x78\xcd\xd6\x2f\x71\xf6\x03\xbf\x8b\x1f\x0f\x11\x30\x9b\x21\xb1\x0c\x89\x20\xae\x6b\x83\xbf\xa8\xbf\x0c\xf0\xa9\x66\xf7\xdc\xea\x03\x0d\xd3\xee\x7d\x30\x75\x6c\x2c\x3a\x72\x5b\xeb\x76\x34\x38\xda\x3d\x46\x08\x4b\xb4\xae\x0c\x15\xa5\x56\xd0\x86\xa9\x26\x87\x24\xcd\x91\x14\x9b\xcd\xc3\xb9\x42\x77\x60\xfa\xc9\x23\x64\x6b\xdd\x51\x3a\x45\xaa\xdc\x5a\x37\x86\xb1\x14\xcf\x71\xb1\xc9\x31\x99\xfc\xe2\xd0\xd6\xdc\x32\xb0\x74\x35\x71\xc9\x7d\x47\xdf\x82\x5a\xdd\x42\xbf\x1e\x48\x27\xfb\xd6\x4a\x75\x8a\xda\xb3\x75\xa4\x20\x3d\xfe\xf4\xd6\x60\x6a\x2c\x0f\x5f\x1f\xf7\xe7\xe5\x70\x43\xfd\xc4\x11\x1c\xf1\xde\x19\x52\xa0\x9f\xb2\xe2\xb6\x0f\x4d\x01\xc3\x91\x1c\x9d\x56\x1c\xd2\x0e\x21\xe1\x33\xe5\x40\x15\xa4\xe5\x96\xc9\x9d\x4f\x95\xa3\xf0\xb2\xca\x40\xae\x77\xe4\x59\xee\x3a\x1c\x35\x37\xc3\x11\xff\x58\x43\xa3\xc1\x3b\x47\x87\x32\x70\xdf\x7a\xba\xbd\x8d\xee\xe7\xd1\xe7\x2b\x59\x27\x4b\xf1\x7e\x79\x25\xe5\x38\xb7\x34\xb9\x40\xd3\x11\x74\x3f\xff\x1f\x86\x90\xc9\x7f\x5b\xb5\x35\xdf\xf7\x9c\x22\x19\xf7\x5c\x65\xf8\x70\xc9\xec\x7b\x8e\xab\x95\x8d\x78\x2e\xf7\x57\xb6\x9f\x8b\x64\x91\xaf\xaf\xeb\x4a\xd9\x75\x64\x54\x69\x4d\xdb\x4f\xef\x91\x89\xbc\xc8\x92\x37\xb0\xd3\x75\x4d\x2e\x02\x80\x4d\x9c\xac\x8a\x78\x25\xd0\xb5\x5d\xed\xff\x6e\x87\xcb\xf8\x0d\x77\x77\xd1\xf0\x87\x19\xce\x59\xbc\x7e\x13\x10\xef\x0b\xf1\x3a\x08\x4c\xce\x0a\xd0\x1e\x27\x91\x59\x10\x99\xcc\x23\x91\x2c\xe7\xd1\xdd\xdd\x65\xac\x3c\x5b\xaf\x56\x22\xfb\x7a\x2a\x3c\x89\xe7\x34\x13\x28\x5e\x97\xa1\x36\xcd\xb0\x14\x1b\x91\x8b\x91\x5d\x3c\xa7\x19\x44\xbc\xf8\x0d\x59\xfa\x07\xc4\xbb\x58\x14\xb9\xc0\x6b\x96\x2e\xc4\xb2\xc8\xc4\xd7\xcc\xd3\x61\x33\xe9\xcb\xcb\x3a\x9f\x47\xff\x0e\x00\x91\xaa\xcd\x60\x2d\x05\x00\x00")
+
+func _1528395680_audit_logUpSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__1528395680_audit_logUpSql,
+		"1528395680_audit_log.up.sql",
+	)
+}
+
+func _1528395680_audit_logUpSql() (*asset, error) {
+	bytes, err := _1528395680_audit_logUpSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "1528395680_audit_log.up.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0x85, 0x14, 0x53, 0xfb, 0x78, 0xdb, 0xa2, 0xee, 0xd9, 0xa3, 0xc4, 0xb4, 0x83, 0x38, 0x27, 0xeb, 0xd2, 0x62, 0x87, 0x20, 0x68, 0x85, 0x48, 0x6e, 0x56, 0x57, 0xde, 0xec, 0x8f, 0x7c, 0xea, 0x67}}
+	return a, nil
+}
+
+var __1528395681_audit_log_truncateDownSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x00\x55\x00\xaa\xff\x42\x45\x47\x49\x4e\x3b\x0a\x0a\x44\x52\x4f\x50\x20\x54\x52\x49\x47\x47\x45\x52\x20\x49\x46\x20\x45\x58\x49\x53\x54\x53\x20\x61\x75\x64\x69\x74\x5f\x6c\x6f\x67\x5f\x61\x70\x70\x65\x6e\x64\x5f\x6f\x6e\x6c\x79\x5f\x74\x72\x75\x6e\x63\x61\x74\x65\x20\x4f\x4e\x20\x61\x75\x64\x69\x74\x5f\x6c\x6f\x67\x3b\x0a\x0a\x43\x4f\x4d\x4d\x49\x54\x3b\x0a\x03\x00\x09\x26\x06\xed\x55\x00\x00\x00")
+
+func _1528395681_audit_log_truncateDownSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__1528395681_audit_log_truncateDownSql,
+		"1528395681_audit_log_truncate.down.sql",
+	)
+}
+
+func _1528395681_audit_log_truncateDownSql() (*asset, error) {
+	bytes, err := _1528395681_audit_log_truncateDownSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "1528395681_audit_log_truncate.down.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0x55, 0xa3, 0x34, 0x87, 0x8e, 0x87, 0xae, 0x6c, 0xd1, 0x8a, 0x7, 0x25, 0xae, 0x66, 0x24, 0x6c, 0xc6, 0xc2, 0xf1, 0xdf, 0x33, 0x31, 0xe8, 0xd6, 0x18, 0xa4, 0x0, 0xda, 0xfa, 0xe5, 0xdb, 0x58}}
+	return a, nil
+}
+
+var __1528395681_audit_log_truncateUpSql = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\x6c\xcd\x41\x4e\x84\x30\x14\x87\xf1\x7d\x4f\xf1\xdf\xa9\x89\x78\x01\x56\x4c\x7d\x83\x2c\xa0\xa6\x3e\x12\x77\xa4\x19\x9e\x58\x83\xed\xa4\xbc\x91\x78\x7b\x77\x1a\x93\x39\xc0\xf7\xfd\x0e\xd4\x76\x43\x6d\x4c\x55\xc1\xe7\xbd\x5a\xe5\x4b\x56\x68\x89\xcb\x22\x65\xc3\x9c\xd3\x8d\xe2\x2d\x16\x41\x4e\x60\x3f\x0e\xb6\x61\xba\xc7\x96\x51\xe4\x43\x4e\x8a\xa8\xd8\xa3\xbe\x23\x60\xd3\xa0\xf2\x29\x49\xff\x4f\x1e\x8c\xf5\xd4\x30\x81\x7d\xd7\xb6\xe4\x11\x2e\x73\xd4\x69\xcd\xcb\x14\xce\x67\x49\xf3\x94\xd3\xfa\x3d\x69\xb9\xa4\x53\x50\xc1\x81\x8e\xce\xd3\xaf\x05\x37\xfc\x15\x38\x3a\x0f\x6a\xec\x13\x5e\xb8\x61\xea\x69\x60\xd0\x2b\xd9\x91\x09\xcf\xde\x59\x7a\x1c\x3d\x5d\x07\x6e\xef\x6a\x63\xac\xeb\xfb\x8e\x6b\xf3\x33\x00\x8e\xb8\xf1\xac\xf5\x00\x00\x00")
+
+func _1528395681_audit_log_truncateUpSqlBytes() ([]byte, error) {
+	return bindataRead(
+		__1528395681_audit_log_truncateUpSql,
+		"1528395681_audit_log_truncate.up.sql",
+	)
+}
+
+func _1528395681_audit_log_truncateUpSql() (*asset, error) {
+	bytes, err := _1528395681_audit_log_truncateUpSqlBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	info := bindataFileInfo{name: "1528395681_audit_log_truncate.up.sql", size: 0, mode: os.FileMode(0), modTime: time.Unix(0, 0)}
+	a := &asset{bytes: bytes, info: info, digest: [32]uint8{0x7f, 0x1c, 0x26, 0x79, 0xb1, 0xca, 0x3a, 0xc0, 0x89, 0xff, 0xbc, 0x7e, 0x57, 0xa2, 0x0, 0x8e, 0xd8, 0x54, 0x85, 0xac, 0x6d, 0xa7, 0x9f, 0x6f, 0xf8, 0x67, 0x81, 0xa0, 0xc5, 0xe7, 0x5b, 0xeb}}
+	return a, nil
+}
+
 // Asset loads and returns the asset for the given name.
 // It returns an error if the asset could not be found or
 // could not be loaded.
@@ -2491,6 +2995,30 @@ var _bindata = map[string]func() (*asset, error){
 	"1528395668_campaign_description_nullable.up.sql":                         _1528395668_campaign_description_nullableUpSql,
 	"1528395669_add_synced_at_to_perms_tables.down.sql":                       _1528395669_add_synced_at_to_perms_tablesDownSql,
 	"1528395669_add_synced_at_to_perms_tables.up.sql":                         _1528395669_add_synced_at_to_perms_tablesUpSql,
+	"1528395670_saved_search_webhooks.down.sql":                               _1528395670_saved_search_webhooksDownSql,
+	"1528395670_saved_search_webhooks.up.sql":                                 _1528395670_saved_search_webhooksUpSql,
+	"1528395671_lsif_moniker_references.up.sql":                               _1528395671_lsif_moniker_referencesUpSql,
+	"1528395671_lsif_moniker_references.down.sql":                             _1528395671_lsif_moniker_referencesDownSql,
+	"1528395672_patch_jobs.down.sql":                                          _1528395672_patch_jobsDownSql,
+	"1528395672_patch_jobs.up.sql":                                            _1528395672_patch_jobsUpSql,
+	"1528395673_campaign_rollout.down.sql":                                    _1528395673_campaign_rolloutDownSql,
+	"1528395673_campaign_rollout.up.sql":                                      _1528395673_campaign_rolloutUpSql,
+	"1528395674_campaign_auto_merge.down.sql":                                 _1528395674_campaign_auto_mergeDownSql,
+	"1528395674_campaign_auto_merge.up.sql":                                   _1528395674_campaign_auto_mergeUpSql,
+	"1528395675_search_exports.up.sql":                                        _1528395675_search_exportsUpSql,
+	"1528395675_search_exports.down.sql":                                      _1528395675_search_exportsDownSql,
+	"1528395676_lsif_indexes.down.sql":                                        _1528395676_lsif_indexesDownSql,
+	"1528395676_lsif_indexes.up.sql":                                          _1528395676_lsif_indexesUpSql,
+	"1528395677_lsif_commit_graphs.down.sql":                                  _1528395677_lsif_commit_graphsDownSql,
+	"1528395677_lsif_commit_graphs.up.sql":                                    _1528395677_lsif_commit_graphsUpSql,
+	"1528395678_access_token_restrictions.down.sql":                           _1528395678_access_token_restrictionsDownSql,
+	"1528395678_access_token_restrictions.up.sql":                             _1528395678_access_token_restrictionsUpSql,
+	"1528395679_config_author.down.sql":                                       _1528395679_config_authorDownSql,
+	"1528395679_config_author.up.sql":                                         _1528395679_config_authorUpSql,
+	"1528395680_audit_log.down.sql":                                           _1528395680_audit_logDownSql,
+	"1528395680_audit_log.up.sql":                                             _1528395680_audit_logUpSql,
+	"1528395681_audit_log_truncate.down.sql":                                  _1528395681_audit_log_truncateDownSql,
+	"1528395681_audit_log_truncate.up.sql":                                    _1528395681_audit_log_truncateUpSql,
 }
 
 // AssetDir returns the file names below a certain
@@ -2640,6 +3168,30 @@ var _bintree = &bintree{nil, map[string]*bintree{
 	"1528395668_campaign_description_nullable.up.sql":                         {_1528395668_campaign_description_nullableUpSql, map[string]*bintree{}},
 	"1528395669_add_synced_at_to_perms_tables.down.sql":                       {_1528395669_add_synced_at_to_perms_tablesDownSql, map[string]*bintree{}},
 	"1528395669_add_synced_at_to_perms_tables.up.sql":                         {_1528395669_add_synced_at_to_perms_tablesUpSql, map[string]*bintree{}},
+	"1528395670_saved_search_webhooks.down.sql":                               {_1528395670_saved_search_webhooksDownSql, map[string]*bintree{}},
+	"1528395670_saved_search_webhooks.up.sql":                                 {_1528395670_saved_search_webhooksUpSql, map[string]*bintree{}},
+	"1528395671_lsif_moniker_references.up.sql":                               {_1528395671_lsif_moniker_referencesUpSql, map[string]*bintree{}},
+	"1528395671_lsif_moniker_references.down.sql":                             {_1528395671_lsif_moniker_referencesDownSql, map[string]*bintree{}},
+	"1528395672_patch_jobs.down.sql":                                          {_1528395672_patch_jobsDownSql, map[string]*bintree{}},
+	"1528395672_patch_jobs.up.sql":                                            {_1528395672_patch_jobsUpSql, map[string]*bintree{}},
+	"1528395673_campaign_rollout.down.sql":                                    {_1528395673_campaign_rolloutDownSql, map[string]*bintree{}},
+	"1528395673_campaign_rollout.up.sql":                                      {_1528395673_campaign_rolloutUpSql, map[string]*bintree{}},
+	"1528395674_campaign_auto_merge.down.sql":                                 {_1528395674_campaign_auto_mergeDownSql, map[string]*bintree{}},
+	"1528395674_campaign_auto_merge.up.sql":                                   {_1528395674_campaign_auto_mergeUpSql, map[string]*bintree{}},
+	"1528395675_search_exports.up.sql":                                        {_1528395675_search_exportsUpSql, map[string]*bintree{}},
+	"1528395675_search_exports.down.sql":                                      {_1528395675_search_exportsDownSql, map[string]*bintree{}},
+	"1528395676_lsif_indexes.down.sql":                                        {_1528395676_lsif_indexesDownSql, map[string]*bintree{}},
+	"1528395676_lsif_indexes.up.sql":                                          {_1528395676_lsif_indexesUpSql, map[string]*bintree{}},
+	"1528395677_lsif_commit_graphs.down.sql":                                  {_1528395677_lsif_commit_graphsDownSql, map[string]*bintree{}},
+	"1528395677_lsif_commit_graphs.up.sql":                                    {_1528395677_lsif_commit_graphsUpSql, map[string]*bintree{}},
+	"1528395678_access_token_restrictions.down.sql":                           {_1528395678_access_token_restrictionsDownSql, map[string]*bintree{}},
+	"1528395678_access_token_restrictions.up.sql":                             {_1528395678_access_token_restrictionsUpSql, map[string]*bintree{}},
+	"1528395679_config_author.down.sql":                                       {_1528395679_config_authorDownSql, map[string]*bintree{}},
+	"1528395679_config_author.up.sql":                                         {_1528395679_config_authorUpSql, map[string]*bintree{}},
+	"1528395680_audit_log.down.sql":                                           {_1528395680_audit_logDownSql, map[string]*bintree{}},
+	"1528395680_audit_log.up.sql":                                             {_1528395680_audit_logUpSql, map[string]*bintree{}},
+	"1528395681_audit_log_truncate.down.sql":                                  {_1528395681_audit_log_truncateDownSql, map[string]*bintree{}},
+	"1528395681_audit_log_truncate.up.sql":                                    {_1528395681_audit_log_truncateUpSql, map[string]*bintree{}},
 }}
 
 // RestoreAsset restores an asset under the given directory.
@@ -114,6 +114,45 @@ const BitbucketCloudSchemaJSON = `{
         [{ "name": "myorg/myrepo" }, { "uuid": "{fceb73c7-cef6-4abe-956d-e471281126bc}" }],
         [{ "name": "myorg/myrepo" }, { "name": "myorg/myotherrepo" }, { "pattern": "^topsecretproject/.*" }]
       ]
+    },
+    "authorization": {
+      "title": "BitbucketCloudAuthorization",
+      "description": "If non-null, enforces Bitbucket Cloud repository permissions. Permissions are read from the workspaces listed in \"teams\" and the personal workspace of \"username\", which requires \"username\" to be an administrator of these workspaces.",
+      "type": "object",
+      "additionalProperties": false,
+      "required": ["identityProvider"],
+      "properties": {
+        "identityProvider": {
+          "description": "The source of identity to use when computing permissions. This defines how to compute the Bitbucket Cloud identity to use for a given Sourcegraph user. When 'username' is used, Sourcegraph assumes usernames are identical in Sourcegraph and Bitbucket Cloud accounts and ` + "`" + `auth.enableUsernameChanges` + "`" + ` must be set to false for security reasons.",
+          "title": "BitbucketCloudIdentityProvider",
+          "type": "object",
+          "required": ["type"],
+          "properties": {
+            "type": {
+              "type": "string",
+              "enum": ["username"]
+            }
+          },
+          "oneOf": [{ "$ref": "#/definitions/UsernameIdentity" }],
+          "!go": {
+            "taggedUnionType": true
+          }
+        }
+      }
+    }
+  },
+  "definitions": {
+    "UsernameIdentity": {
+      "title": "BitbucketCloudUsernameIdentity",
+      "type": "object",
+      "additionalProperties": false,
+      "required": ["type"],
+      "properties": {
+        "type": {
+          "type": "string",
+          "const": "username"
+        }
+      }
     }
   }
 }
@@ -75,6 +75,59 @@ const GitoliteSchemaJSON = `{
           "type": "string"
         }
       }
+    },
+    "authorization": {
+      "title": "GitoliteAuthorization",
+      "description": "If non-null, enforces Gitolite repository permissions. Permissions are read with the Gitolite ` + "`" + `access` + "`" + ` command on the host, so the SSH key used to list repositories must be allowed to run it.",
+      "type": "object",
+      "additionalProperties": false,
+      "required": ["identityProvider"],
+      "properties": {
+        "identityProvider": {
+          "description": "The source of identity to use when computing permissions. This defines how to compute the Gitolite username to use for a given Sourcegraph user. When 'username' is used, Sourcegraph users are identified by \"usernameMapping\", by their existing Gitolite account, or (only if \"matchUsernames\" is true) by their Sourcegraph username.",
+          "title": "GitoliteIdentityProvider",
+          "type": "object",
+          "required": ["type"],
+          "properties": {
+            "type": {
+              "type": "string",
+              "enum": ["username"]
+            }
+          },
+          "oneOf": [{ "$ref": "#/definitions/UsernameIdentity" }],
+          "!go": {
+            "taggedUnionType": true
+          }
+        },
+        "usernameMapping": {
+          "description": "A map from Sourcegraph usernames to Gitolite usernames, for users whose usernames differ between Sourcegraph and Gitolite.",
+          "type": "object",
+          "additionalProperties": {
+            "type": "string",
+            "pattern": "^[0-9a-zA-Z][-0-9a-zA-Z._@+]*$"
+          },
+          "examples": [{ "alice": "alice.smith" }]
+        },
+        "matchUsernames": {
+          "description": "Identify Sourcegraph users who are not mapped in \"usernameMapping\" and have no Gitolite account yet by their Sourcegraph username. Only enable this if users cannot choose their Sourcegraph usernames (builtin sign-up is disabled and ` + "`" + `auth.enableUsernameChanges` + "`" + ` is false): otherwise, a user could take the username of a Gitolite user (such as \"admin\") and gain their read access.",
+          "type": "boolean",
+          "default": false
+        }
+      }
+    }
+  },
+  "definitions": {
+    "UsernameIdentity": {
+      "title": "GitoliteUsernameIdentity",
+      "type": "object",
+      "additionalProperties": false,
+      "required": ["type"],
+      "properties": {
+        "type": {
+          "type": "string",
+          "const": "username"
+        }
+      }
     }
   }
 }
@@ -64,6 +64,7 @@ type AuthProviders struct {
 	HttpHeader    *HTTPHeaderAuthProvider
 	Github        *GitHubAuthProvider
 	Gitlab        *GitLabAuthProvider
+	Ldap          *LDAPAuthProvider
 }
 
 func (v AuthProviders) MarshalJSON() ([]byte, error) {
@@ -85,6 +86,9 @@ func (v AuthProviders) MarshalJSON() ([]byte, error) {
 	if v.Gitlab != nil {
 		return json.Marshal(v.Gitlab)
 	}
+	if v.Ldap != nil {
+		return json.Marshal(v.Ldap)
+	}
 	return nil, errors.New("tagged union type must have exactly 1 non-nil field value")
 }
 func (v *AuthProviders) UnmarshalJSON(data []byte) error {
@@ -103,12 +107,26 @@ func (v *AuthProviders) UnmarshalJSON(data []byte) error {
 		return json.Unmarshal(data, &v.Gitlab)
 	case "http-header":
 		return json.Unmarshal(data, &v.HttpHeader)
+	case "ldap":
+		return json.Unmarshal(data, &v.Ldap)
 	case "openidconnect":
 		return json.Unmarshal(data, &v.Openidconnect)
 	case "saml":
 		return json.Unmarshal(data, &v.Saml)
 	}
-	return fmt.Errorf("tagged union type must have a %q property whose value is one of %s", "type", []string{"builtin", "saml", "openidconnect", "http-header", "github", "gitlab"})
+	return fmt.Errorf("tagged union type must have a %q property whose value is one of %s", "type", []string{"builtin", "saml", "openidconnect", "http-header", "github", "gitlab", "ldap"})
+}
+
+// AuthScim description: Settings for the SCIM 2.0 user and group provisioning API at /.api/scim/v2, which enables an identity provider to create, update, deactivate and delete users and to manage organization membership. The API is disabled unless this is set.
+type AuthScim struct {
+	// BearerToken description: The secret token that the identity provider must send in the HTTP Authorization header ("Authorization: Bearer TOKEN") of SCIM requests. Use a long, randomly generated value.
+	BearerToken string `json:"bearerToken"`
+}
+
+// BitbucketCloudAuthorization description: If non-null, enforces Bitbucket Cloud repository permissions. Permissions are read from the workspaces listed in "teams" and the personal workspace of "username", which requires "username" to be an administrator of these workspaces.
+type BitbucketCloudAuthorization struct {
+	// IdentityProvider description: The source of identity to use when computing permissions. This defines how to compute the Bitbucket Cloud identity to use for a given Sourcegraph user. When 'username' is used, Sourcegraph assumes usernames are identical in Sourcegraph and Bitbucket Cloud accounts and `auth.enableUsernameChanges` must be set to false for security reasons.
+	IdentityProvider BitbucketCloudIdentityProvider `json:"identityProvider"`
 }
 
 // BitbucketCloudConnection description: Configuration for a connection to Bitbucket Cloud.
@@ -117,6 +135,8 @@ type BitbucketCloudConnection struct {
 	ApiURL string `json:"apiURL,omitempty"`
 	// AppPassword description: The app password to use when authenticating to the Bitbucket Cloud. Also set the corresponding "username" field.
 	AppPassword string `json:"appPassword"`
+	// Authorization description: If non-null, enforces Bitbucket Cloud repository permissions. Permissions are read from the workspaces listed in "teams" and the personal workspace of "username", which requires "username" to be an administrator of these workspaces.
+	Authorization *BitbucketCloudAuthorization `json:"authorization,omitempty"`
 	// Exclude description: A list of repositories to never mirror from Bitbucket Cloud. Takes precedence over "teams" configuration.
 	//
 	// Supports excluding by name ({"name": "myorg/myrepo"}) or by UUID ({"uuid": "{fceb73c7-cef6-4abe-956d-e471281126bd}"}).
@@ -145,6 +165,31 @@ type BitbucketCloudConnection struct {
 	Username string `json:"username"`
 }
 
+// BitbucketCloudIdentityProvider description: The source of identity to use when computing permissions. This defines how to compute the Bitbucket Cloud identity to use for a given Sourcegraph user. When 'username' is used, Sourcegraph assumes usernames are identical in Sourcegraph and Bitbucket Cloud accounts and `auth.enableUsernameChanges` must be set to false for security reasons.
+type BitbucketCloudIdentityProvider struct {
+	Username *BitbucketCloudUsernameIdentity
+}
+
+func (v BitbucketCloudIdentityProvider) MarshalJSON() ([]byte, error) {
+	if v.Username != nil {
+		return json.Marshal(v.Username)
+	}
+	return nil, errors.New("tagged union type must have exactly 1 non-nil field value")
+}
+func (v *BitbucketCloudIdentityProvider) UnmarshalJSON(data []byte) error {
+	var d struct {
+		DiscriminantProperty string `json:"type"`
+	}
+	if err := json.Unmarshal(data, &d); err != nil {
+		return err
+	}
+	switch d.DiscriminantProperty {
+	case "username":
+		return json.Unmarshal(data, &v.Username)
+	}
+	return fmt.Errorf("tagged union type must have a %q property whose value is one of %s", "type", []string{"username"})
+}
+
 // BitbucketCloudRateLimit description: Rate limit applied when making background API requests to Bitbucket Cloud.
 type BitbucketCloudRateLimit struct {
 	// Enabled description: true if rate limiting is enabled.
@@ -152,6 +197,9 @@ type BitbucketCloudRateLimit struct {
 	// RequestsPerHour description: Requests per hour permitted. This is an average, calculated per second.
 	RequestsPerHour float64 `json:"requestsPerHour"`
 }
+type BitbucketCloudUsernameIdentity struct {
+	Type string `json:"type"`
+}
 
 // BitbucketServerAuthorization description: If non-null, enforces Bitbucket Server repository permissions.
 type BitbucketServerAuthorization struct {
@@ -317,6 +365,26 @@ type CloneURLToRepositoryName struct {
 	To string `json:"to"`
 }
 
+// CodeIntelAutoIndexing description: Configures automatic LSIF indexing of repositories for precise code intelligence (experimental). When enabled, a new index job is recorded whenever the default branch of a repository matching one of `repositories` moves. Workers run the configured indexer on the repository at that commit and upload the resulting LSIF dump.
+type CodeIntelAutoIndexing struct {
+	// Enabled description: Whether repositories are indexed automatically.
+	Enabled bool `json:"enabled"`
+	// MaxAttempts description: The number of times an index job is attempted before it is marked as errored. Failed attempts are retried with an exponential backoff.
+	MaxAttempts int `json:"maxAttempts,omitempty"`
+	// Repositories description: The repositories to index and how to index them. The first entry whose `repository` matches the name of a repository applies.
+	Repositories []*CodeIntelAutoIndexingRepository `json:"repositories,omitempty"`
+}
+type CodeIntelAutoIndexingRepository struct {
+	// Command description: The indexer command and its arguments. It runs in the directory `root` of the repository and must write the LSIF dump to the file dump.lsif in that directory.
+	Command []string `json:"command"`
+	// Image description: The Docker image to run the indexer in, e.g. "sourcegraph/lsif-go". The repository is mounted at /data in the container. Indexers are never run directly on the host, because they run inside an untrusted checkout of the repository.
+	Image string `json:"image"`
+	// Repository description: A regular expression matching the names of the repositories to index, e.g. "^github\.com/myorg/".
+	Repository string `json:"repository"`
+	// Root description: The directory of the repository to index, relative to the root of the repository.
+	Root string `json:"root,omitempty"`
+}
+
 // CustomGitFetchMapping description: Mapping from Git clone URl domain/path to git fetch command. The `domainPath` field contains the Git clone URL domain/path part. The `fetch` field contains the custom git fetch command.
 type CustomGitFetchMapping struct {
 	// DomainPath description: Git clone URL domain/path
@@ -391,6 +459,8 @@ type ExperimentalFeatures struct {
 	Automation string `json:"automation,omitempty"`
 	// BitbucketServerFastPerm description: DEPRECATED: Configure in Bitbucket Server config.
 	BitbucketServerFastPerm string `json:"bitbucketServerFastPerm,omitempty"`
+	// CommitIndex description: Enables the on-disk commit index in gitserver. When enabled, gitserver indexes the commits and diffs on the default branch of every repository after it is updated, and `type:commit` and `type:diff` searches on the default branch use this index instead of running `git log`.
+	CommitIndex string `json:"commitIndex,omitempty"`
 	// CustomGitFetch description: JSON array of configuration that maps from Git clone URL domain/path to custom git fetch command.
 	CustomGitFetch []*CustomGitFetchMapping `json:"customGitFetch,omitempty"`
 	// DebugLog description: Turns on debug logging for specific debugging scenarios.
@@ -589,6 +659,8 @@ type GitLabConnection struct {
 	Token string `json:"token"`
 	// Url description: URL of a GitLab instance, such as https://gitlab.example.com or (for GitLab.com) https://gitlab.com.
 	Url string `json:"url"`
+	// Webhooks description: An array of configurations defining existing GitLab webhooks that send updates back to Sourcegraph.
+	Webhooks []*GitLabWebhook `json:"webhooks,omitempty"`
 }
 type GitLabNameTransformation struct {
 	// Regex description: The regex to match for the occurrences of its replacement.
@@ -610,9 +682,25 @@ type GitLabRateLimit struct {
 	// RequestsPerHour description: Requests per hour permitted. This is an average, calculated per second.
 	RequestsPerHour float64 `json:"requestsPerHour"`
 }
+type GitLabWebhook struct {
+	// Secret description: The secret token used when creating the webhook
+	Secret string `json:"secret"`
+}
+
+// GitoliteAuthorization description: If non-null, enforces Gitolite repository permissions. Permissions are read with the Gitolite `access` command on the host, so the SSH key used to list repositories must be allowed to run it.
+type GitoliteAuthorization struct {
+	// IdentityProvider description: The source of identity to use when computing permissions. This defines how to compute the Gitolite username to use for a given Sourcegraph user. When 'username' is used, Sourcegraph users are identified by "usernameMapping", by their existing Gitolite account, or (only if "matchUsernames" is true) by their Sourcegraph username.
+	IdentityProvider GitoliteIdentityProvider `json:"identityProvider"`
+	// MatchUsernames description: Identify Sourcegraph users who are not mapped in "usernameMapping" and have no Gitolite account yet by their Sourcegraph username. Only enable this if users cannot choose their Sourcegraph usernames (builtin sign-up is disabled and `auth.enableUsernameChanges` is false): otherwise, a user could take the username of a Gitolite user (such as "admin") and gain their read access.
+	MatchUsernames bool `json:"matchUsernames,omitempty"`
+	// UsernameMapping description: A map from Sourcegraph usernames to Gitolite usernames, for users whose usernames differ between Sourcegraph and Gitolite.
+	UsernameMapping map[string]string `json:"usernameMapping,omitempty"`
+}
 
 // GitoliteConnection description: Configuration for a connection to Gitolite.
 type GitoliteConnection struct {
+	// Authorization description: If non-null, enforces Gitolite repository permissions. Permissions are read with the Gitolite `access` command on the host, so the SSH key used to list repositories must be allowed to run it.
+	Authorization *GitoliteAuthorization `json:"authorization,omitempty"`
 	// Blacklist description: Regular expression to filter repositories from auto-discovery, so they will not get cloned automatically.
 	Blacklist string `json:"blacklist,omitempty"`
 	// Exclude description: A list of repositories to never mirror from this Gitolite instance. Supports excluding by exact name ({"name": "foo"}).
@@ -629,6 +717,35 @@ type GitoliteConnection struct {
 	Prefix string `json:"prefix"`
 }
 
+// GitoliteIdentityProvider description: The source of identity to use when computing permissions. This defines how to compute the Gitolite username to use for a given Sourcegraph user. When 'username' is used, Sourcegraph users are identified by "usernameMapping", by their existing Gitolite account, or (only if "matchUsernames" is true) by their Sourcegraph username.
+type GitoliteIdentityProvider struct {
+	Username *GitoliteUsernameIdentity
+}
+
+func (v GitoliteIdentityProvider) MarshalJSON() ([]byte, error) {
+	if v.Username != nil {
+		return json.Marshal(v.Username)
+	}
+	return nil, errors.New("tagged union type must have exactly 1 non-nil field value")
+}
+func (v *GitoliteIdentityProvider) UnmarshalJSON(data []byte) error {
+	var d struct {
+		DiscriminantProperty string `json:"type"`
+	}
+	if err := json.Unmarshal(data, &d); err != nil {
+		return err
+	}
+	switch d.DiscriminantProperty {
+	case "username":
+		return json.Unmarshal(data, &v.Username)
+	}
+	return fmt.Errorf("tagged union type must have a %q property whose value is one of %s", "type", []string{"username"})
+}
+
+type GitoliteUsernameIdentity struct {
+	Type string `json:"type"`
+}
+
 // HTTPHeaderAuthProvider description: Configures the HTTP header authentication provider (which authenticates users by consulting an HTTP request header set by an authentication proxy such as https://github.com/bitly/oauth2_proxy).
 type HTTPHeaderAuthProvider struct {
 	// StripUsernameHeaderPrefix description: The prefix that precedes the username portion of the HTTP header specified in `usernameHeader`. If specified, the prefix will be stripped from the header value and the remainder will be used as the username. For example, if using Google Identity-Aware Proxy (IAP) with Google Sign-In, set this value to `accounts.google.com:`.
@@ -687,6 +804,34 @@ func (v *IdentityProvider) UnmarshalJSON(data []byte) error {
 	return fmt.Errorf("tagged union type must have a %q property whose value is one of %s", "type", []string{"oauth", "username", "external"})
 }
 
+// LDAPAuthProvider description: Configures the LDAP authentication provider, which authenticates users who sign in with the username-password sign-in form by binding to an LDAP server (such as Active Directory) as the user.
+type LDAPAuthProvider struct {
+	// AllowGroups description: Only allow users who are members of at least one of these groups (given by DN) to sign in. Group membership is read from the `groupMembershipAttribute` of the user entry. Leave empty or unset for no group restrictions.
+	AllowGroups []string `json:"allowGroups,omitempty"`
+	// BindDN description: The DN of the service account used to search for the user entries of users who sign in. If not set, searches are performed anonymously.
+	BindDN string `json:"bindDN,omitempty"`
+	// BindPassword description: The password of the service account (`bindDN`).
+	BindPassword string `json:"bindPassword,omitempty"`
+	DisplayName  string `json:"displayName,omitempty"`
+	// DisplayNameAttribute description: The attribute of the user entry that contains the user's display name.
+	DisplayNameAttribute string `json:"displayNameAttribute,omitempty"`
+	// EmailAttribute description: The attribute of the user entry that contains the user's email address. Users without an email address cannot sign in.
+	EmailAttribute string `json:"emailAttribute,omitempty"`
+	// GroupMembershipAttribute description: The attribute of the user entry that lists the DNs of the groups the user is a member of.
+	GroupMembershipAttribute string `json:"groupMembershipAttribute,omitempty"`
+	// StartTLS description: Upgrade the connection to the LDAP server to TLS with StartTLS. Only applies to ldap:// URLs.
+	StartTLS bool   `json:"startTLS,omitempty"`
+	Type     string `json:"type"`
+	// Url description: The URL of the LDAP server. Use the ldaps:// scheme for LDAP over TLS.
+	Url string `json:"url"`
+	// UserSearchBase description: The DN of the subtree that contains the user entries.
+	UserSearchBase string `json:"userSearchBase"`
+	// UserSearchFilter description: The LDAP filter that matches the user entry of a user who signs in. The placeholder {username} is replaced with the (escaped) username entered on the sign-in form.
+	UserSearchFilter string `json:"userSearchFilter,omitempty"`
+	// UsernameAttribute description: The attribute of the user entry that contains the user's username. The username is normalized to become the Sourcegraph username.
+	UsernameAttribute string `json:"usernameAttribute,omitempty"`
+}
+
 // Log description: Configuration for logging and alerting, including to external services.
 type Log struct {
 	// Sentry description: Configuration for Sentry
@@ -943,6 +1088,8 @@ type SiteConfiguration struct {
 	AuthProviders []AuthProviders `json:"auth.providers,omitempty"`
 	// AuthPublic description: WARNING: This option has been removed as of 3.8.
 	AuthPublic bool `json:"auth.public,omitempty"`
+	// AuthScim description: Settings for the SCIM 2.0 user and group provisioning API at /.api/scim/v2, which enables an identity provider to create, update, deactivate and delete users and to manage organization membership. The API is disabled unless this is set.
+	AuthScim *AuthScim `json:"auth.scim,omitempty"`
 	// AuthSessionExpiry description: The duration of a user session, after which it expires and the user is required to re-authenticate. The default is 90 days. There is typically no need to set this, but some users may have specific internal security requirements.
 	//
 	// The string format is that of the Duration type in the Go time package (https://golang.org/pkg/time/#ParseDuration). E.g., "720h", "43200m", "2592000s" all indicate a timespan of 30 days.
@@ -967,6 +1114,8 @@ type SiteConfiguration struct {
 	Branding *Branding `json:"branding,omitempty"`
 	// CampaignsReadAccessEnabled description: Enables read-only access to campaigns for non-site-admin users. This is a setting for the experimental campaigns feature. These will only have an effect when campaigns is enabled with `{"experimentalFeatures": {"automation": "enabled"}}`.
 	CampaignsReadAccessEnabled *bool `json:"campaigns.readAccess.enabled,omitempty"`
+	// CodeIntelAutoIndexing description: Configures automatic LSIF indexing of repositories for precise code intelligence (experimental). When enabled, a new index job is recorded whenever the default branch of a repository matching one of `repositories` moves. Workers run the configured indexer on the repository at that commit and upload the resulting LSIF dump.
+	CodeIntelAutoIndexing *CodeIntelAutoIndexing `json:"codeIntelAutoIndexing,omitempty"`
 	// CorsOrigin description: Required when using any of the native code host integrations for Phabricator, GitLab, or Bitbucket Server. It is a space-separated list of allowed origins for cross-origin HTTP requests which should be the base URL for your Phabricator, GitLab, or Bitbucket Server instance.
 	CorsOrigin string `json:"corsOrigin,omitempty"`
 	// DebugSearchSymbolsParallelism description: (debug) controls the amount of symbol search parallelism. Defaults to 20. It is not recommended to change this outside of debugging scenarios. This option will be removed in a future version.
@@ -999,6 +1148,8 @@ type SiteConfiguration struct {
 	GitCloneURLToRepositoryName []*CloneURLToRepositoryName `json:"git.cloneURLToRepositoryName,omitempty"`
 	// GitMaxConcurrentClones description: Maximum number of git clone processes that will be run concurrently to update repositories.
 	GitMaxConcurrentClones int `json:"gitMaxConcurrentClones,omitempty"`
+	// GitReplicationFactor description: Number of gitservers each repository is cloned onto. Reads of a repository (archives, git commands and clone status checks) fail over to another replica when its gitserver is unavailable or has not cloned the repository yet. The default of 1 disables replication. Values larger than the number of gitservers are capped at the number of gitservers.
+	GitReplicationFactor int `json:"gitReplicationFactor,omitempty"`
 	// GithubClientID description: Client ID for GitHub. (DEPRECATED)
 	GithubClientID string `json:"githubClientID,omitempty"`
 	// GithubClientSecret description: Client secret for GitHub. (DEPRECATED)
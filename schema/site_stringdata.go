@@ -102,6 +102,12 @@ const SiteSchemaJSON = `{
           "enum": ["enabled", "disabled"],
           "default": "disabled"
         },
+        "commitIndex": {
+          "description": "Enables the on-disk commit index in gitserver. When enabled, gitserver indexes the commits and diffs on the default branch of every repository after it is updated, and ` + "`" + `type:commit` + "`" + ` and ` + "`" + `type:diff` + "`" + ` searches on the default branch use this index instead of running ` + "`" + `git log` + "`" + `.",
+          "type": "string",
+          "enum": ["enabled", "disabled"],
+          "default": "disabled"
+        },
         "searchMultipleRevisionsPerRepository": {
           "description": "Enables searching multiple revisions of the same repository (using ` + "`" + `repo:myrepo@branch1:branch2` + "`" + `).",
           "type": "boolean",
@@ -205,6 +211,71 @@ const SiteSchemaJSON = `{
       "default": false,
       "group": "Security"
     },
+    "codeIntelAutoIndexing": {
+      "description": "Configures automatic LSIF indexing of repositories for precise code intelligence (experimental). When enabled, a new index job is recorded whenever the default branch of a repository matching one of ` + "`" + `repositories` + "`" + ` moves. Workers run the configured indexer on the repository at that commit and upload the resulting LSIF dump.",
+      "type": "object",
+      "additionalProperties": false,
+      "required": ["enabled"],
+      "properties": {
+        "enabled": {
+          "description": "Whether repositories are indexed automatically.",
+          "type": "boolean",
+          "default": false
+        },
+        "maxAttempts": {
+          "description": "The number of times an index job is attempted before it is marked as errored. Failed attempts are retried with an exponential backoff.",
+          "type": "integer",
+          "minimum": 1,
+          "default": 3
+        },
+        "repositories": {
+          "description": "The repositories to index and how to index them. The first entry whose ` + "`" + `repository` + "`" + ` matches the name of a repository applies.",
+          "type": "array",
+          "items": {
+            "title": "CodeIntelAutoIndexingRepository",
+            "type": "object",
+            "additionalProperties": false,
+            "required": ["repository", "image", "command"],
+            "properties": {
+              "repository": {
+                "description": "A regular expression matching the names of the repositories to index, e.g. \"^github\\.com/myorg/\".",
+                "type": "string"
+              },
+              "image": {
+                "description": "The Docker image to run the indexer in, e.g. \"sourcegraph/lsif-go\". The repository is mounted at /data in the container. Indexers are never run directly on the host, because they run inside an untrusted checkout of the repository.",
+                "type": "string",
+                "minLength": 1
+              },
+              "command": {
+                "description": "The indexer command and its arguments. It runs in the directory ` + "`" + `root` + "`" + ` of the repository and must write the LSIF dump to the file dump.lsif in that directory.",
+                "type": "array",
+                "items": { "type": "string" },
+                "minItems": 1,
+                "examples": [["lsif-go", "--noProgress"]]
+              },
+              "root": {
+                "description": "The directory of the repository to index, relative to the root of the repository.",
+                "type": "string",
+                "default": ""
+              }
+            }
+          }
+        }
+      },
+      "examples": [
+        {
+          "enabled": true,
+          "repositories": [
+            {
+              "repository": "^github\\.com/myorg/",
+              "image": "sourcegraph/lsif-go",
+              "command": ["lsif-go", "--noProgress"]
+            }
+          ]
+        }
+      ],
+      "group": "Experimental"
+    },
     "disableNonCriticalTelemetry": {
       "description": "Disable aggregated event counts from being sent to Sourcegraph.com via pings.",
       "type": "boolean",
@@ -262,6 +333,13 @@ const SiteSchemaJSON = `{
       "default": 5,
       "group": "External services"
     },
+    "gitReplicationFactor": {
+      "description": "Number of gitservers each repository is cloned onto. Reads of a repository (archives, git commands and clone status checks) fail over to another replica when its gitserver is unavailable or has not cloned the repository yet. The default of 1 disables replication. Values larger than the number of gitservers are capped at the number of gitservers.",
+      "type": "integer",
+      "minimum": 1,
+      "default": 1,
+      "group": "External services"
+    },
     "repoListUpdateInterval": {
       "description": "Interval (in minutes) for checking code hosts (such as GitHub, Gitolite, etc.) for new repositories.",
       "type": "integer",
@@ -309,6 +387,21 @@ const SiteSchemaJSON = `{
       ],
       "group": "Security"
     },
+    "auth.scim": {
+      "description": "Settings for the SCIM 2.0 user and group provisioning API at /.api/scim/v2, which enables an identity provider to create, update, deactivate and delete users and to manage organization membership. The API is disabled unless this is set.",
+      "type": "object",
+      "additionalProperties": false,
+      "required": ["bearerToken"],
+      "properties": {
+        "bearerToken": {
+          "description": "The secret token that the identity provider must send in the HTTP Authorization header (\"Authorization: Bearer TOKEN\") of SCIM requests. Use a long, randomly generated value.",
+          "type": "string",
+          "minLength": 32
+        }
+      },
+      "examples": [{ "bearerToken": "a-long-randomly-generated-secret-token" }],
+      "group": "Security"
+    },
     "permissions.userMapping": {
       "description": "Settings for Sourcegraph permissions, which allow the site admin to explicitly manage repository permissions via the GraphQL API. This setting cannot be enabled if repository permissions for any specific external service are enabled (i.e., when the external service's ` + "`" + `authorization` + "`" + ` field is set).",
       "type": "object",
@@ -651,7 +744,7 @@ const SiteSchemaJSON = `{
         "properties": {
           "type": {
             "type": "string",
-            "enum": ["builtin", "saml", "openidconnect", "http-header", "github", "gitlab"]
+            "enum": ["builtin", "saml", "openidconnect", "http-header", "github", "gitlab", "ldap"]
           }
         },
         "oneOf": [
@@ -660,7 +753,8 @@ const SiteSchemaJSON = `{
           { "$ref": "#/definitions/OpenIDConnectAuthProvider" },
           { "$ref": "#/definitions/HTTPHeaderAuthProvider" },
           { "$ref": "#/definitions/GitHubAuthProvider" },
-          { "$ref": "#/definitions/GitLabAuthProvider" }
+          { "$ref": "#/definitions/GitLabAuthProvider" },
+          { "$ref": "#/definitions/LDAPAuthProvider" }
         ],
         "!go": {
           "taggedUnionType": true
@@ -937,6 +1031,81 @@ const SiteSchemaJSON = `{
         "displayName": { "$ref": "#/definitions/AuthProviderCommon/properties/displayName" }
       }
     },
+    "LDAPAuthProvider": {
+      "description": "Configures the LDAP authentication provider, which authenticates users who sign in with the username-password sign-in form by binding to an LDAP server (such as Active Directory) as the user.",
+      "type": "object",
+      "additionalProperties": false,
+      "required": ["type", "url", "userSearchBase"],
+      "properties": {
+        "type": {
+          "type": "string",
+          "const": "ldap"
+        },
+        "displayName": { "$ref": "#/definitions/AuthProviderCommon/properties/displayName" },
+        "url": {
+          "description": "The URL of the LDAP server. Use the ldaps:// scheme for LDAP over TLS.",
+          "type": "string",
+          "pattern": "^ldaps?://",
+          "examples": ["ldaps://ldap.example.com:636", "ldap://ldap.example.com:389"]
+        },
+        "startTLS": {
+          "description": "Upgrade the connection to the LDAP server to TLS with StartTLS. Only applies to ldap:// URLs.",
+          "type": "boolean",
+          "default": false
+        },
+        "bindDN": {
+          "description": "The DN of the service account used to search for the user entries of users who sign in. If not set, searches are performed anonymously.",
+          "type": "string",
+          "examples": ["cn=sourcegraph,ou=services,dc=example,dc=com"]
+        },
+        "bindPassword": {
+          "description": "The password of the service account (` + "`" + `bindDN` + "`" + `).",
+          "type": "string"
+        },
+        "userSearchBase": {
+          "description": "The DN of the subtree that contains the user entries.",
+          "type": "string",
+          "examples": ["ou=people,dc=example,dc=com"]
+        },
+        "userSearchFilter": {
+          "description": "The LDAP filter that matches the user entry of a user who signs in. The placeholder {username} is replaced with the (escaped) username entered on the sign-in form.",
+          "type": "string",
+          "default": "(uid={username})",
+          "examples": ["(sAMAccountName={username})", "(&(objectClass=person)(|(uid={username})(mail={username})))"]
+        },
+        "usernameAttribute": {
+          "description": "The attribute of the user entry that contains the user's username. The username is normalized to become the Sourcegraph username.",
+          "type": "string",
+          "default": "uid",
+          "examples": ["sAMAccountName"]
+        },
+        "emailAttribute": {
+          "description": "The attribute of the user entry that contains the user's email address. Users without an email address cannot sign in.",
+          "type": "string",
+          "default": "mail"
+        },
+        "displayNameAttribute": {
+          "description": "The attribute of the user entry that contains the user's display name.",
+          "type": "string",
+          "default": "cn",
+          "examples": ["displayName"]
+        },
+        "allowGroups": {
+          "description": "Only allow users who are members of at least one of these groups (given by DN) to sign in. Group membership is read from the ` + "`" + `groupMembershipAttribute` + "`" + ` of the user entry. Leave empty or unset for no group restrictions.",
+          "type": "array",
+          "items": {
+            "type": "string",
+            "minLength": 1
+          },
+          "examples": [["cn=engineering,ou=groups,dc=example,dc=com"]]
+        },
+        "groupMembershipAttribute": {
+          "description": "The attribute of the user entry that lists the DNs of the groups the user is a member of.",
+          "type": "string",
+          "default": "memberOf"
+        }
+      }
+    },
     "AuthProviderCommon": {
       "$comment": "This schema is not used directly. The *AuthProvider schemas refer to its properties directly.",
       "description": "Common properties for authentication providers.",
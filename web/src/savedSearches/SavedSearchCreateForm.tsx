@@ -41,6 +41,8 @@ export class SavedSearchCreateForm extends React.Component<Props, State> {
                                 fields.query,
                                 fields.notify,
                                 fields.notifySlack,
+                                fields.webhookURL || null,
+                                fields.teamsWebhookURL || null,
                                 this.props.namespace.__typename === 'User' ? this.props.namespace.id : null,
                                 this.props.namespace.__typename === 'Org' ? this.props.namespace.id : null
                             ).pipe(
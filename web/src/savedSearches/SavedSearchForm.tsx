@@ -14,6 +14,8 @@ export interface SavedQueryFields {
     notify: boolean
     notifySlack: boolean
     slackWebhookURL: string | null
+    webhookURL: string | null
+    teamsWebhookURL: string | null
 }
 
 interface Props extends RouteComponentProps<{}>, NamespaceProps {
@@ -36,8 +38,15 @@ export class SavedSearchForm extends React.Component<Props, State> {
     constructor(props: Props) {
         super(props)
 
-        const { description = '', query = '', notify = false, notifySlack = false, slackWebhookURL = '' } =
-            props.defaultValues || {}
+        const {
+            description = '',
+            query = '',
+            notify = false,
+            notifySlack = false,
+            slackWebhookURL = '',
+            webhookURL = '',
+            teamsWebhookURL = '',
+        } = props.defaultValues || {}
 
         this.state = {
             values: {
@@ -46,6 +55,8 @@ export class SavedSearchForm extends React.Component<Props, State> {
                 notify,
                 notifySlack,
                 slackWebhookURL,
+                webhookURL,
+                teamsWebhookURL,
             },
         }
     }
@@ -74,7 +85,7 @@ export class SavedSearchForm extends React.Component<Props, State> {
 
     public render(): JSX.Element | null {
         const {
-            values: { query, description, notify, notifySlack, slackWebhookURL },
+            values: { query, description, notify, notifySlack, slackWebhookURL, webhookURL, teamsWebhookURL },
         } = this.state
 
         return (
@@ -147,6 +158,30 @@ export class SavedSearchForm extends React.Component<Props, State> {
                             </label>
                         </div>
                     )}
+                    <div className="saved-search-form__input">
+                        <label className="saved-search-form__label">Webhook notifications:</label>
+                        <input
+                            type="url"
+                            name="Webhook URL"
+                            className="form-control"
+                            placeholder="https://example.com/hooks/saved-search"
+                            value={webhookURL || ''}
+                            onChange={this.createInputChangeHandler('webhookURL')}
+                        />
+                        <label className="small">New results are sent to this URL as JSON in a POST request.</label>
+                    </div>
+                    <div className="saved-search-form__input">
+                        <label className="saved-search-form__label">Microsoft Teams notifications:</label>
+                        <input
+                            type="url"
+                            name="Microsoft Teams webhook URL"
+                            className="form-control"
+                            placeholder="https://outlook.office.com/webhook/..."
+                            value={teamsWebhookURL || ''}
+                            onChange={this.createInputChangeHandler('teamsWebhookURL')}
+                        />
+                        <label className="small">The URL of an incoming webhook connector of a Teams channel.</label>
+                    </div>
                     {this.isUnsupportedNotifyQuery(this.state.values) && (
                         <div className="alert alert-warning mb-3">
                             <strong>Warning:</strong> non-commit searches do not currently support notifications.
@@ -180,7 +215,7 @@ export class SavedSearchForm extends React.Component<Props, State> {
      * Tells if the query is unsupported for sending notifications.
      */
     private isUnsupportedNotifyQuery(v: Omit<SavedQueryFields, 'id'>): boolean {
-        const notifying = v.notify || v.notifySlack
+        const notifying = v.notify || v.notifySlack || !!v.webhookURL || !!v.teamsWebhookURL
         return notifying && !v.query.includes('type:diff') && !v.query.includes('type:commit')
     }
 }
@@ -62,6 +62,8 @@ export class SavedSearchUpdateForm extends React.Component<Props, State> {
                                 input.query,
                                 input.notify,
                                 input.notifySlack,
+                                input.webhookURL || null,
+                                input.teamsWebhookURL || null,
                                 this.props.namespace.__typename === 'User' ? this.props.namespace.id : null,
                                 this.props.namespace.__typename === 'Org' ? this.props.namespace.id : null
                             ).pipe(
@@ -108,6 +110,8 @@ export class SavedSearchUpdateForm extends React.Component<Props, State> {
                             notify: savedSearch.notify,
                             notifySlack: savedSearch.notifySlack,
                             slackWebhookURL: savedSearch.slackWebhookURL,
+                            webhookURL: savedSearch.webhookURL,
+                            teamsWebhookURL: savedSearch.teamsWebhookURL,
                         }}
                         loading={this.state.updatedOrError === LOADING}
                         onSubmit={(fields: Pick<SavedQueryFields, Exclude<keyof SavedQueryFields, 'id'>>): void =>
@@ -239,6 +239,8 @@ const savedSearchFragment = gql`
         userID
         orgID
         slackWebhookURL
+        webhookURL
+        teamsWebhookURL
     }
 `
 
@@ -272,6 +274,8 @@ export function fetchSavedSearch(id: GQL.ID): Observable<GQL.ISavedSearch> {
                         notify
                         notifySlack
                         slackWebhookURL
+                        webhookURL
+                        teamsWebhookURL
                         orgID
                         userID
                     }
@@ -290,6 +294,8 @@ export function createSavedSearch(
     query: string,
     notify: boolean,
     notifySlack: boolean,
+    webhookURL: string | null,
+    teamsWebhookURL: string | null,
     userId: GQL.ID | null,
     orgId: GQL.ID | null
 ): Observable<void> {
@@ -300,6 +306,8 @@ export function createSavedSearch(
                 $query: String!
                 $notifyOwner: Boolean!
                 $notifySlack: Boolean!
+                $webhookURL: String
+                $teamsWebhookURL: String
                 $userID: ID
                 $orgID: ID
             ) {
@@ -308,6 +316,8 @@ export function createSavedSearch(
                     query: $query
                     notifyOwner: $notifyOwner
                     notifySlack: $notifySlack
+                    webhookURL: $webhookURL
+                    teamsWebhookURL: $teamsWebhookURL
                     userID: $userID
                     orgID: $orgID
                 ) {
@@ -321,6 +331,8 @@ export function createSavedSearch(
             query,
             notifyOwner: notify,
             notifySlack,
+            webhookURL,
+            teamsWebhookURL,
             userID: userId,
             orgID: orgId,
         }
@@ -336,6 +348,8 @@ export function updateSavedSearch(
     query: string,
     notify: boolean,
     notifySlack: boolean,
+    webhookURL: string | null,
+    teamsWebhookURL: string | null,
     userId: GQL.ID | null,
     orgId: GQL.ID | null
 ): Observable<void> {
@@ -347,6 +361,8 @@ export function updateSavedSearch(
                 $query: String!
                 $notifyOwner: Boolean!
                 $notifySlack: Boolean!
+                $webhookURL: String
+                $teamsWebhookURL: String
                 $userID: ID
                 $orgID: ID
             ) {
@@ -356,6 +372,8 @@ export function updateSavedSearch(
                     query: $query
                     notifyOwner: $notifyOwner
                     notifySlack: $notifySlack
+                    webhookURL: $webhookURL
+                    teamsWebhookURL: $teamsWebhookURL
                     userID: $userID
                     orgID: $orgID
                 ) {
@@ -370,6 +388,8 @@ export function updateSavedSearch(
             query,
             notifyOwner: notify,
             notifySlack,
+            webhookURL,
+            teamsWebhookURL,
             userID: userId,
             orgID: orgId,
         }